@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/hashid"
+	"github.com/MikeSquared-Agency/MissionControl/manager"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(aiderCmd)
+	aiderCmd.Flags().String("model", "", "Model for aider (default: the persona's from the models config, the offline model, or aider's own)")
+}
+
+var aiderCmd = &cobra.Command{
+	Use:   "aider <task-id>",
+	Short: "Run aider on an implement-stage task",
+	Long: `Runs aider non-interactively on an implement-stage task, in the task's
+zone directory. aider gets the task briefing and the specs in .mission/specs/
+as read-only context and the task's scope paths to edit.
+
+When aider exits, its diff and commits are saved under
+.mission/artifacts/<task-id>/ and a handoff is stored for the task, as
+if the worker had run 'mc handoff'.
+
+Example:
+  mc aider abc123
+  mc aider abc123 --model sonnet`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAider,
+}
+
+func runAider(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	model, _ := cmd.Flags().GetString("model")
+
+	handoff, err := runAiderWorker(missionDir, args[0], model, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Handoff stored for %s (%s)\n", handoff.TaskID, handoff.Status)
+	for _, a := range handoff.Artifacts {
+		fmt.Printf("  %s\n", a)
+	}
+	return nil
+}
+
+// runAiderWorker runs aider on an implement-stage task and stores the
+// resulting handoff. aider's output goes to out. A failed aider run is
+// not an error: it is handed off as blocked.
+func runAiderWorker(missionDir, taskID, model string, out io.Writer) (*Handoff, error) {
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	var task *Task
+	for i := range tasks {
+		if tasks[i].ID == taskID {
+			task = &tasks[i]
+			break
+		}
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	if err := errManualTask(*task); err != nil {
+		return nil, err
+	}
+	if task.Stage != "implement" {
+		return nil, fmt.Errorf("task %s is in stage %q: aider only runs implement-stage tasks", taskID, task.Stage)
+	}
+
+	// Briefing, as 'mc briefing generate' writes it
+	briefing, err := generateBriefing(missionDir, taskID, "")
+	if err != nil {
+		return nil, err
+	}
+	briefingPath := filepath.Join(missionDir, "handoffs", taskID+"-briefing.json")
+	if err := os.MkdirAll(filepath.Dir(briefingPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(briefingPath, briefing, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write briefing: %w", err)
+	}
+	specs, _ := filepath.Glob(filepath.Join(missionDir, "specs", "*.md"))
+
+	projectRoot := filepath.Dir(missionDir)
+	workDir := projectRoot
+	if task.Zone != "" {
+		zoneDir := filepath.Join(projectRoot, task.Zone)
+		if info, err := os.Stat(zoneDir); err == nil && info.IsDir() {
+			workDir = zoneDir
+		}
+	}
+	var files []string
+	for _, p := range task.ScopePaths {
+		files = append(files, filepath.Join(projectRoot, p))
+	}
+
+	projectConfig, err := bridge.LoadProjectConfig(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+	if model == "" {
+		model = projectConfig.WorkerModel(task.Persona, string(manager.AgentTypeAider))
+	}
+
+	rt, err := manager.RuntimeFor(manager.AgentTypeAider)
+	if err != nil {
+		return nil, err
+	}
+	env, envRecord, err := workerEnv(projectConfig, task.Zone)
+	if err != nil {
+		return nil, err
+	}
+	aiderCmd, err := rt.Command(manager.Launch{
+		Task:      aiderMessage(task, briefingPath),
+		Model:     model,
+		Env:       env,
+		ReadFiles: append([]string{briefingPath}, specs...),
+		Files:     files,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to spawn aider: %w", err)
+	}
+	aiderCmd.Dir = workDir
+	aiderCmd.Stdout = out
+	aiderCmd.Stderr = out
+
+	// aider commits as it goes, so the diff is taken against HEAD before
+	// the run
+	base, err := gitOutput(projectRoot, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("aider needs a git repository with at least one commit: %w", err)
+	}
+
+	if err := aiderCmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to spawn aider: %w", err)
+	}
+	workerID := hashid.Generate("worker", taskID, task.Persona, task.Zone)
+	if err := recordAiderWorker(missionDir, workerID, task, aiderCmd.Process.Pid, model, envRecord); err != nil {
+		aiderCmd.Process.Kill()
+		aiderCmd.Wait()
+		return nil, err
+	}
+	runErr := aiderCmd.Wait()
+
+	handoff := Handoff{
+		TaskID:        taskID,
+		WorkerID:      workerID,
+		Status:        "complete",
+		Findings:      []Finding{},
+		Artifacts:     []string{},
+		OpenQuestions: []string{},
+	}
+
+	// Changes outside .mission/, committed or not
+	exclude := ":(exclude).mission"
+	diff, err := gitOutput(projectRoot, "diff", base, "--", ".", exclude)
+	if err != nil {
+		return nil, err
+	}
+	changed, _ := gitOutput(projectRoot, "diff", "--name-only", base, "--", ".", exclude)
+	commits, _ := gitOutput(projectRoot, "log", "--reverse", "--format=%h %s", base+"..HEAD", "--", ".", exclude)
+
+	if diff != "" {
+		artifactDir := filepath.Join(missionDir, "artifacts", taskID)
+		if err := os.MkdirAll(artifactDir, 0755); err != nil {
+			return nil, err
+		}
+		diffPath := filepath.Join(artifactDir, workerID+".diff")
+		if err := os.WriteFile(diffPath, []byte(diff+"\n"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to store diff: %w", err)
+		}
+		handoff.Artifacts = append(handoff.Artifacts, filepath.Join(".mission", "artifacts", taskID, workerID+".diff"))
+		if commits != "" {
+			commitsPath := filepath.Join(artifactDir, workerID+".commits")
+			if err := os.WriteFile(commitsPath, []byte(commits+"\n"), 0644); err != nil {
+				return nil, fmt.Errorf("failed to store commits: %w", err)
+			}
+			handoff.Artifacts = append(handoff.Artifacts, filepath.Join(".mission", "artifacts", taskID, workerID+".commits"))
+		}
+	}
+	handoff.Artifacts = append(handoff.Artifacts, nonEmptyLines(changed)...)
+	for _, c := range nonEmptyLines(commits) {
+		handoff.Findings = append(handoff.Findings, Finding{Type: "commit", Summary: c})
+	}
+
+	switch {
+	case runErr != nil:
+		handoff.Status = "blocked"
+		handoff.Findings = append(handoff.Findings, Finding{Type: "error", Summary: fmt.Sprintf("aider failed: %v", runErr), Severity: "high"})
+		handoff.OpenQuestions = append(handoff.OpenQuestions, "aider did not finish; review its output and re-run or implement by hand")
+	case diff == "":
+		handoff.Status = "blocked"
+		handoff.OpenQuestions = append(handoff.OpenQuestions, "aider made no changes")
+	default:
+		handoff.Findings = append(handoff.Findings, Finding{
+			Type:    "summary",
+			Summary: fmt.Sprintf("aider changed %d file(s) in %d commit(s)", len(nonEmptyLines(changed)), len(nonEmptyLines(commits))),
+		})
+	}
+
+	data, err := json.MarshalIndent(handoff, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	stored, _, err := storeHandoff(missionDir, data)
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// aiderMessage is the instruction aider gets for a task.
+func aiderMessage(task *Task, briefingPath string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Implement task %s: %s\n\n", task.ID, task.Name)
+	fmt.Fprintf(&b, "The task briefing is in %s and the specs are attached read-only. ", filepath.Base(briefingPath))
+	if len(task.ScopePaths) > 0 {
+		fmt.Fprintf(&b, "Only change these files: %s. ", strings.Join(task.ScopePaths, ", "))
+	}
+	b.WriteString("Commit your changes when done.")
+	return b.String()
+}
+
+// recordAiderWorker adds the aider process to workers.json with its model
+// and env profile record.
+func recordAiderWorker(missionDir, workerID string, task *Task, pid int, model string, env map[string]string) error {
+	workersPath := filepath.Join(missionDir, "state", "workers.json")
+	var state WorkersState
+	if err := readJSON(workersPath, &state); err != nil {
+		state = WorkersState{Workers: []Worker{}}
+	}
+	state.Workers = append(state.Workers, Worker{
+		ID:        workerID,
+		Persona:   task.Persona,
+		TaskID:    task.ID,
+		Zone:      task.Zone,
+		Status:    "running",
+		PID:       pid,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+		Runtime:   string(manager.AgentTypeAider),
+		Model:     model,
+		Env:       env,
+	})
+	if err := writeJSON(workersPath, state); err != nil {
+		return fmt.Errorf("failed to update workers state: %w", err)
+	}
+
+	writeAuditLog(missionDir, AuditWorkerSpawned, "cli", map[string]interface{}{
+		"worker_id": workerID,
+		"persona":   task.Persona,
+		"task_id":   task.ID,
+		"zone":      task.Zone,
+		"pid":       pid,
+		"runtime":   string(manager.AgentTypeAider),
+	})
+	return nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// nonEmptyLines splits output into non-empty lines.
+func nonEmptyLines(s string) []string {
+	var out []string
+	for _, l := range strings.Split(s, "\n") {
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
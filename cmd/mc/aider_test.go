@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// installFakeAider puts an aider script on PATH that runs body in the
+// directory mc aider starts it in.
+func installFakeAider(t *testing.T, body string) {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "aider"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestAiderWorker(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+
+	tasks := []Task{{ID: "t1", Name: "Add greeting", Stage: "implement", Persona: "developer", Status: "pending", ScopePaths: []string{"hello.txt"}}}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+	installFakeAider(t, `echo hello > hello.txt && git add hello.txt && git commit -q -m "Add greeting"`)
+
+	var out strings.Builder
+	handoff, err := runAiderWorker(missionDir, "t1", "", &out)
+	if err != nil {
+		t.Fatalf("runAiderWorker: %v\n%s", err, out.String())
+	}
+	if handoff.Status != "complete" {
+		t.Fatalf("status = %q, want complete (findings %+v)", handoff.Status, handoff.Findings)
+	}
+
+	diffPath := filepath.Join(".mission", "artifacts", "t1", handoff.WorkerID+".diff")
+	if !slices.Contains(handoff.Artifacts, diffPath) || !slices.Contains(handoff.Artifacts, "hello.txt") {
+		t.Errorf("artifacts = %v, want the diff and hello.txt", handoff.Artifacts)
+	}
+	diff, err := os.ReadFile(filepath.Join(filepath.Dir(missionDir), diffPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(diff), "+hello") {
+		t.Errorf("diff does not contain the change:\n%s", diff)
+	}
+	if len(handoff.Findings) == 0 || handoff.Findings[0].Type != "commit" || !strings.HasSuffix(handoff.Findings[0].Summary, "Add greeting") {
+		t.Errorf("findings = %+v, want the commit first", handoff.Findings)
+	}
+
+	tasks, _ = loadTasks(missionDir)
+	if tasks[0].Status != "complete" {
+		t.Errorf("task status = %q, want complete", tasks[0].Status)
+	}
+	var workers WorkersState
+	if err := readJSON(filepath.Join(missionDir, "state", "workers.json"), &workers); err != nil {
+		t.Fatal(err)
+	}
+	if len(workers.Workers) != 1 || workers.Workers[0].Runtime != "aider" || workers.Workers[0].Status != "complete" {
+		t.Errorf("workers = %+v, want one complete aider worker", workers.Workers)
+	}
+}
+
+func TestAiderWorkerFailure(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+
+	tasks := []Task{{ID: "t1", Name: "Add greeting", Stage: "implement", Status: "pending"}}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+	installFakeAider(t, "exit 1")
+
+	handoff, err := runAiderWorker(missionDir, "t1", "", &strings.Builder{})
+	if err != nil {
+		t.Fatalf("runAiderWorker: %v", err)
+	}
+	if handoff.Status != "blocked" || len(handoff.OpenQuestions) == 0 {
+		t.Errorf("handoff = %+v, want blocked with an open question", handoff)
+	}
+}
+
+func TestAiderWorkerRequiresImplementStage(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+
+	tasks := []Task{{ID: "t1", Name: "Research", Stage: "discovery", Status: "pending"}}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runAiderWorker(missionDir, "t1", "", &strings.Builder{}); err == nil || !strings.Contains(err.Error(), "implement") {
+		t.Errorf("err = %v, want an implement-stage error", err)
+	}
+}
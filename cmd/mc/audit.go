@@ -8,6 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +20,8 @@ type AuditEntry struct {
 	Timestamp string                 `json:"timestamp"`
 	Action    string                 `json:"action"`
 	Actor     string                 `json:"actor"`
+	User      string                 `json:"user,omitempty"`       // person behind the mutation (MC_USER, else git user)
+	RequestID string                 `json:"request_id,omitempty"` // orchestrator API request that triggered the mutation
 	Details   map[string]interface{} `json:"details,omitempty"`
 }
 
@@ -24,31 +30,56 @@ const (
 	AuditTaskCreated        = "task_created"
 	AuditTaskUpdated        = "task_updated"
 	AuditTaskCompleted      = "task_completed"
+	AuditTaskArchived       = "task_archived"
+	AuditTaskUnarchived     = "task_unarchived"
 	AuditGateApproved       = "gate_approved"
 	AuditGateChecked        = "gate_checked"
+	AuditFindingsOverridden = "gate_findings_overridden"
 	AuditStageAdvanced      = "stage_advanced"
 	AuditStageSet           = "stage_set"
+	AuditStageRolledBack    = "stage_rolled_back"
 	AuditWorkerSpawned      = "worker_spawned"
 	AuditWorkerCompleted    = "worker_completed"
 	AuditWorkerKilled       = "worker_killed"
+	AuditWorkerQueued       = "worker_queued"
+	AuditWorkerDequeued     = "worker_dequeued"
+	AuditWorkerReaped       = "worker_reaped"
+	AuditZoneAdded          = "zone_added"
+	AuditZoneUpdated        = "zone_updated"
+	AuditZoneRemoved        = "zone_removed"
 	AuditCheckpointCreated  = "checkpoint_created"
+	AuditCheckpointPruned   = "checkpoint_pruned"
 	AuditSessionStarted     = "session_started"
 	AuditSessionEnded       = "session_ended"
 	AuditHandoffReceived    = "handoff_received"
+	AuditFindingResolved    = "finding_resolved"
+	AuditSpecStatusChanged  = "spec_status_changed"
+	AuditReleaseTagged      = "release_tagged"
+	AuditSpendOverrideSet   = "spend_override_set"
+	AuditSpendOverrideEnd   = "spend_override_cleared"
 	AuditProjectInitialized = "project_initialized"
+	AuditJSONLRepaired      = "jsonl_repaired"
+	AuditStateSynced        = "state_synced"
+	AuditMatrixOverridden   = "matrix_overridden"
+	AuditExperimentStarted  = "experiment_started"
+	AuditExperimentStopped  = "experiment_stopped"
 )
 
 func init() {
 	rootCmd.AddCommand(auditCmd)
 	auditCmd.AddCommand(auditListCmd)
 	auditCmd.AddCommand(auditFilterCmd)
+	auditCmd.AddCommand(auditRotateCmd)
 
 	auditListCmd.Flags().IntP("last", "n", 20, "Number of entries to show")
 	auditListCmd.Flags().Bool("json", false, "Output raw JSON lines")
 
 	auditFilterCmd.Flags().StringP("action", "a", "", "Filter by action type")
+	auditFilterCmd.Flags().StringP("category", "c", "", "Filter by category (e.g. task, gate, stage)")
 	auditFilterCmd.Flags().String("actor", "", "Filter by actor")
+	auditFilterCmd.Flags().String("user", "", "Filter by user (name or email substring)")
 	auditFilterCmd.Flags().String("since", "", "Show entries since (RFC3339 or duration like 1h, 24h)")
+	auditFilterCmd.Flags().String("until", "", "Show entries until (RFC3339 or duration like 1h, 24h)")
 	auditFilterCmd.Flags().IntP("last", "n", 50, "Max entries to show")
 	auditFilterCmd.Flags().Bool("json", false, "Output raw JSON lines")
 }
@@ -60,17 +91,23 @@ var auditCmd = &cobra.Command{
 
 The audit trail records all significant state mutations:
   task_created, task_updated, task_completed,
-  gate_approved, gate_checked, stage_advanced, stage_set,
+  gate_approved, gate_checked, stage_advanced, stage_set, stage_rolled_back,
   worker_spawned, worker_completed, worker_killed,
-  checkpoint_created, session_started, session_ended,
-  handoff_received, project_initialized
+  checkpoint_created, checkpoint_pruned, session_started, session_ended,
+  handoff_received, finding_resolved, spec_status_changed, release_tagged,
+  spend_override_set, spend_override_cleared, project_initialized
 
 Examples:
   mc audit                           # Show last 20 entries
   mc audit list -n 50                # Show last 50 entries
   mc audit filter -a gate_approved   # Show gate approvals
   mc audit filter --since 1h         # Last hour's activity
-  mc audit filter --actor cli        # Actions by CLI user`,
+  mc audit filter --actor cli        # Actions taken through the CLI
+  mc audit filter --user alice       # Actions by a user
+  mc audit rotate                    # Archive the active log now
+
+The active log is rotated into gzip archives under .mission/audit/ when it
+exceeds the configured size or age (config.json "audit" section).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Default: show last 20
 		return runAuditList(cmd, args)
@@ -89,6 +126,12 @@ var auditFilterCmd = &cobra.Command{
 	RunE:  runAuditFilter,
 }
 
+var auditRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Archive the active audit log into .mission/audit/",
+	RunE:  runAuditRotate,
+}
+
 func runAuditList(cmd *cobra.Command, args []string) error {
 	missionDir, err := findMissionDir()
 	if err != nil {
@@ -124,46 +167,48 @@ func runAuditFilter(cmd *cobra.Command, args []string) error {
 	}
 
 	actionFilter, _ := cmd.Flags().GetString("action")
+	categoryFilter, _ := cmd.Flags().GetString("category")
 	actorFilter, _ := cmd.Flags().GetString("actor")
+	userFilter, _ := cmd.Flags().GetString("user")
 	sinceStr, _ := cmd.Flags().GetString("since")
+	untilStr, _ := cmd.Flags().GetString("until")
 	n, _ := cmd.Flags().GetInt("last")
 	if n <= 0 {
 		n = 20
 	}
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 
-	var sinceTime time.Time
-	if sinceStr != "" {
-		// Try duration first (e.g., "1h", "24h")
-		if d, err := time.ParseDuration(sinceStr); err == nil {
-			sinceTime = time.Now().UTC().Add(-d)
-		} else if t, err := time.Parse(time.RFC3339, sinceStr); err == nil {
-			sinceTime = t
-		} else {
-			return fmt.Errorf("invalid --since value: %s (use duration like 1h or RFC3339)", sinceStr)
-		}
+	sinceTime, err := parseAuditTime(sinceStr)
+	if err != nil {
+		return fmt.Errorf("invalid --since value: %s (use duration like 1h or RFC3339)", sinceStr)
+	}
+	untilTime, err := parseAuditTime(untilStr)
+	if err != nil {
+		return fmt.Errorf("invalid --until value: %s (use duration like 1h or RFC3339)", untilStr)
 	}
 
-	entries, err := readAuditLog(missionDir)
+	res, err := audit.Query(missionDir, audit.Filter{
+		Since:    sinceTime,
+		Until:    untilTime,
+		Action:   actionFilter,
+		Category: categoryFilter,
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to query audit log: %w", err)
 	}
 
-	// Apply filters
+	// Actor and user match by substring, which the index can't narrow
 	var filtered []AuditEntry
-	for _, e := range entries {
-		if actionFilter != "" && e.Action != actionFilter {
+	for _, raw := range res.Entries {
+		var e AuditEntry
+		if json.Unmarshal(raw, &e) != nil {
 			continue
 		}
 		if actorFilter != "" && !strings.Contains(e.Actor, actorFilter) {
 			continue
 		}
-		if !sinceTime.IsZero() {
-			if t, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
-				if t.Before(sinceTime) {
-					continue
-				}
-			}
+		if userFilter != "" && !strings.Contains(e.User, userFilter) {
+			continue
 		}
 		filtered = append(filtered, e)
 	}
@@ -176,6 +221,35 @@ func runAuditFilter(cmd *cobra.Command, args []string) error {
 	return printAuditEntries(filtered, jsonOutput)
 }
 
+func runAuditRotate(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	archive, err := audit.Rotate(missionDir, loadAuditPolicy(missionDir))
+	if err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	if archive == "" {
+		fmt.Println("Audit log is empty, nothing to rotate.")
+		return nil
+	}
+	fmt.Printf("Audit log archived: %s\n", archive)
+	return nil
+}
+
+// parseAuditTime accepts a duration ("1h" = one hour ago) or an RFC3339 timestamp.
+func parseAuditTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
 func printAuditEntries(entries []AuditEntry, jsonOutput bool) error {
 	if len(entries) == 0 {
 		if jsonOutput {
@@ -209,6 +283,13 @@ func printAuditEntries(entries []AuditEntry, jsonOutput bool) error {
 			detailStr = " " + strings.Join(parts, " ")
 		}
 
+		if e.User != "" {
+			detailStr += fmt.Sprintf(" user=%q", e.User)
+		}
+		if e.RequestID != "" {
+			detailStr += " request_id=" + e.RequestID
+		}
+
 		fmt.Printf("[%s] %-24s actor=%-10s%s\n", ts, e.Action, e.Actor, detailStr)
 	}
 
@@ -221,49 +302,62 @@ func writeAuditLog(missionDir string, action string, actor string, details map[s
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Action:    action,
 		Actor:     actor,
-		Details:   details,
+		User:      identity.Current().String(),
+		RequestID: os.Getenv("MC_REQUEST_ID"),
+		Details:   secrets.RedactMap(details),
 	}
 
-	auditPath := filepath.Join(missionDir, "audit.jsonl")
-	data, err := json.Marshal(entry)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "warning: failed to marshal audit entry: %v\n", err)
-		return
+	if _, err := audit.MaybeRotate(missionDir, loadAuditPolicy(missionDir)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to rotate audit log: %v\n", err)
 	}
 
-	f, err := os.OpenFile(auditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "warning: failed to open audit log: %v\n", err)
-		return
-	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
+	if err := jsonl.Append(filepath.Join(missionDir, audit.FileName), jsonl.Options{}, entry); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to write audit entry: %v\n", err)
 	}
-	_, _ = f.WriteString("\n")
 }
 
-// readAuditLog reads all entries from .mission/audit.jsonl
+// readAuditLog reads all entries from the audit archives and .mission/audit.jsonl
 func readAuditLog(missionDir string) ([]AuditEntry, error) {
-	auditPath := filepath.Join(missionDir, "audit.jsonl")
-	data, err := os.ReadFile(auditPath)
+	res, err := audit.Query(missionDir, audit.Filter{})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("failed to read audit log: %w", err)
 	}
 
 	var entries []AuditEntry
-	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
-		if line == "" {
-			continue
-		}
+	for _, raw := range res.Entries {
 		var e AuditEntry
-		if err := json.Unmarshal([]byte(line), &e); err == nil {
+		if err := json.Unmarshal(raw, &e); err == nil {
 			entries = append(entries, e)
 		}
 	}
 
 	return entries, nil
 }
+
+// AuditConfig controls audit log rotation.
+// Stored in .mission/config.json under "audit".
+type AuditConfig struct {
+	MaxSizeMB   int `json:"max_size_mb,omitempty"`  // Rotate above this size (default: 10)
+	MaxAgeDays  int `json:"max_age_days,omitempty"` // Rotate when oldest entry is older (default: 30)
+	MaxArchives int `json:"max_archives,omitempty"` // Archives to keep (default: 20)
+}
+
+// loadAuditPolicy reads the audit rotation policy from .mission/config.json,
+// falling back to defaults for unset fields.
+func loadAuditPolicy(missionDir string) audit.Policy {
+	policy := audit.DefaultPolicy()
+	var cfg Config
+	if err := readJSON(filepath.Join(missionDir, "config.json"), &cfg); err != nil || cfg.Audit == nil {
+		return policy
+	}
+	if cfg.Audit.MaxSizeMB > 0 {
+		policy.MaxBytes = int64(cfg.Audit.MaxSizeMB) << 20
+	}
+	if cfg.Audit.MaxAgeDays > 0 {
+		policy.MaxAge = time.Duration(cfg.Audit.MaxAgeDays) * 24 * time.Hour
+	}
+	if cfg.Audit.MaxArchives > 0 {
+		policy.MaxArchives = cfg.Audit.MaxArchives
+	}
+	return policy
+}
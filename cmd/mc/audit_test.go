@@ -206,3 +206,56 @@ func TestAuditIntegrationWithStageAndGate(t *testing.T) {
 		}
 	}
 }
+
+func TestAuditRotationFromConfig(t *testing.T) {
+	missionDir := filepath.Join(t.TempDir(), ".mission")
+	_ = os.MkdirAll(missionDir, 0755)
+
+	if p := loadAuditPolicy(missionDir); p.MaxBytes != 10<<20 {
+		t.Errorf("Expected default 10MiB limit, got %d", p.MaxBytes)
+	}
+
+	// 1 MB limit; pad the active log past it so the next write rotates
+	_ = os.WriteFile(filepath.Join(missionDir, "config.json"), []byte(`{"audit":{"max_size_mb":1,"max_archives":3}}`), 0644)
+	p := loadAuditPolicy(missionDir)
+	if p.MaxBytes != 1<<20 || p.MaxArchives != 3 {
+		t.Fatalf("Expected config overrides, got %+v", p)
+	}
+
+	padding := `{"timestamp":"2026-01-01T00:00:00Z","action":"task_created","actor":"cli","details":{"pad":"` + strings.Repeat("x", 1<<20) + `"}}` + "\n"
+	_ = os.WriteFile(filepath.Join(missionDir, "audit.jsonl"), []byte(padding), 0644)
+
+	writeAuditLog(missionDir, AuditGateApproved, "cli", map[string]interface{}{"stage": "goal"})
+
+	archives, _ := filepath.Glob(filepath.Join(missionDir, "audit", "*.jsonl.gz"))
+	if len(archives) != 1 {
+		t.Fatalf("Expected 1 archive after rotation, got %d", len(archives))
+	}
+
+	entries, err := readAuditLog(missionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected archived + active entries (2), got %d", len(entries))
+	}
+	if entries[1].Action != AuditGateApproved {
+		t.Errorf("Expected newest entry last, got %s", entries[1].Action)
+	}
+}
+
+func TestAuditRecordsRequestID(t *testing.T) {
+	missionDir := filepath.Join(t.TempDir(), ".mission")
+	_ = os.MkdirAll(missionDir, 0755)
+
+	t.Setenv("MC_REQUEST_ID", "req-123")
+	writeAuditLog(missionDir, AuditGateApproved, "cli", map[string]interface{}{"stage": "verify"})
+
+	entries, err := readAuditLog(missionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].RequestID != "req-123" {
+		t.Errorf("Expected request_id req-123 on entry, got %+v", entries)
+	}
+}
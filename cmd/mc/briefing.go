@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/MikeSquared-Agency/MissionControl/specs"
 	"github.com/spf13/cobra"
 )
 
@@ -128,6 +129,14 @@ func generateBriefing(missionDir string, taskID string, objective string) ([]byt
 	if len(predSummaries) > 0 {
 		briefing["predecessor_summaries"] = predSummaries
 	}
+	if task.Spec != "" {
+		briefing["spec"] = ".mission/specs/" + task.Spec + ".md"
+		if task.SpecHash != "" && specs.Changed(missionDir, task.Spec, task.SpecHash) {
+			warning := fmt.Sprintf("spec %s has changed since this task was created", task.Spec)
+			fmt.Fprintf(os.Stderr, "⚠ %s\n", warning)
+			briefing["spec_warning"] = warning
+		}
+	}
 
 	return json.MarshalIndent(briefing, "", "  ")
 }
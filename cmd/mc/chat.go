@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+	chatCmd.AddCommand(chatExportCmd)
+
+	chatExportCmd.Flags().StringP("session", "s", "", "Only export this session (default: all sessions)")
+	chatExportCmd.Flags().StringP("output", "o", "", "Write to file instead of stdout")
+}
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Work with persisted chat history",
+	Long: `The orchestrator records chat with OpenClaw and the King in
+.mission/chat/history.jsonl, keyed by session.
+
+Examples:
+  mc chat export                       # All sessions as Markdown
+  mc chat export -s webchat -o chat.md # One session to a file`,
+}
+
+var chatExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export chat history as Markdown",
+	RunE:  runChatExport,
+}
+
+func runChatExport(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	session, _ := cmd.Flags().GetString("session")
+	output, _ := cmd.Flags().GetString("output")
+
+	messages, _, err := chat.NewStore(missionDir).History(chat.Query{Session: session})
+	if err != nil {
+		return fmt.Errorf("failed to read chat history: %w", err)
+	}
+	content := secrets.Redact(chat.Markdown(messages))
+
+	if output == "" {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write chat export: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d messages to %s\n", len(messages), output)
+	return nil
+}
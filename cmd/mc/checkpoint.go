@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/briefing"
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +26,7 @@ func init() {
 
 	checkpointCmd.Flags().Int("tokens", 0, "Current token count; only checkpoint if above threshold")
 	checkpointRestartCmd.Flags().String("from", "", "Checkpoint ID to restart from")
+	checkpointRestartCmd.Flags().Int("budget", 0, "Briefing size in tokens (default: briefing_tokens in config.json, else 500)")
 	checkpointAutoCmd.Flags().Int("tokens", 0, "Current token count (required)")
 	checkpointAutoCmd.Flags().String("reason", "pre-compaction", "Reason for the automatic checkpoint")
 }
@@ -73,7 +76,12 @@ var checkpointAutoCmd = &cobra.Command{
 	Short: "Auto-checkpoint for pre-compaction (token-aware)",
 	Long: `Creates a checkpoint when token usage is high, intended for pre-compaction saves.
 If --tokens is provided, the checkpoint is only created when the count exceeds the
-configured threshold (default 150k). Without --tokens, always creates.`,
+configured threshold (default 150k). Without --tokens, always creates.
+
+Auto-checkpoints are tagged "auto". With auto_checkpoint.keep set in
+config.json, the oldest ones beyond that many are deleted; other
+checkpoints are never pruned. The orchestrator runs this command on the
+auto_checkpoint schedule.`,
 	RunE: runCheckpointAuto,
 }
 
@@ -88,6 +96,7 @@ type CheckpointData struct {
 	Decisions []string        `json:"decisions"`
 	Blockers  []string        `json:"blockers"`
 	Summary   string          `json:"summary,omitempty"`
+	Tag       string          `json:"tag,omitempty"` // why it was taken, e.g. "rollback"
 }
 
 // SessionRecord is a line in sessions.jsonl
@@ -166,25 +175,29 @@ func runCheckpointAuto(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	cp, err := createCheckpoint(missionDir, "")
+	summary := "auto-checkpoint: " + reason
+	if tokens > 0 {
+		summary += fmt.Sprintf(" (tokens: %d)", tokens)
+	}
+	cp, err := createTaggedCheckpoint(missionDir, "", autoCheckpointTag, summary)
 	if err != nil {
 		return err
 	}
 
-	// Tag the checkpoint with the reason
-	cp.Summary = fmt.Sprintf("auto-checkpoint: %s (tokens: %d)", reason, tokens)
-
-	// Re-write with summary
-	checkpointsDir := filepath.Join(missionDir, "orchestrator", "checkpoints")
-	cpPath := filepath.Join(checkpointsDir, cp.ID+".json")
-	_ = writeJSON(cpPath, cp)
-
 	writeAuditLog(missionDir, AuditCheckpointCreated, "auto", map[string]interface{}{
 		"checkpoint_id": cp.ID,
 		"reason":        reason,
 		"tokens":        tokens,
 	})
 
+	if pruned, err := pruneAutoCheckpoints(missionDir, loadAutoCheckpointKeep(missionDir)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: pruning auto-checkpoints: %v\n", err)
+	} else if len(pruned) > 0 {
+		writeAuditLog(missionDir, AuditCheckpointPruned, "auto", map[string]interface{}{
+			"checkpoint_ids": pruned,
+		})
+	}
+
 	gitAutoCommit(missionDir, CommitCategoryCheckpoint, fmt.Sprintf("auto-checkpoint %s (%s)", cp.ID, reason))
 
 	output, _ := json.MarshalIndent(cp, "", "  ")
@@ -193,7 +206,84 @@ func runCheckpointAuto(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// autoCheckpointTag marks checkpoints taken by mc checkpoint auto. Only
+// these are pruned by the auto_checkpoint retention.
+const autoCheckpointTag = "auto"
+
+// AutoCheckpointConfig is the automatic checkpoint policy, stored in
+// .mission/config.json under "auto_checkpoint". The orchestrator's
+// scheduler reads the triggers; mc applies the retention.
+type AutoCheckpointConfig struct {
+	IntervalMinutes  int  `json:"interval_minutes,omitempty"`   // checkpoint after this long without one
+	EveryTasks       int  `json:"every_tasks,omitempty"`        // checkpoint after this many tasks complete
+	OnTokenThreshold bool `json:"on_token_threshold,omitempty"` // checkpoint when the King session crosses token_threshold
+	Keep             int  `json:"keep,omitempty"`               // auto-checkpoints to keep (0: all)
+}
+
+// AutoRestartConfig opts into automatic session restarts, stored in
+// .mission/config.json under "auto_restart". The orchestrator runs
+// mc checkpoint restart once the session's health turns red and sends the
+// briefing to the King's OpenClaw chat session.
+type AutoRestartConfig struct {
+	Enabled    bool   `json:"enabled"`
+	SessionKey string `json:"session_key,omitempty"` // OpenClaw chat session to re-brief (default "webchat")
+}
+
+// loadAutoCheckpointKeep returns how many auto-checkpoints to keep, 0 for
+// all.
+func loadAutoCheckpointKeep(missionDir string) int {
+	var cfg Config
+	if err := readJSON(filepath.Join(missionDir, "config.json"), &cfg); err != nil || cfg.AutoCheckpoint == nil {
+		return 0
+	}
+	return cfg.AutoCheckpoint.Keep
+}
+
+// pruneAutoCheckpoints deletes the oldest auto-checkpoints beyond keep and
+// returns their IDs. Checkpoints taken any other way are never pruned.
+func pruneAutoCheckpoints(missionDir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	dir := filepath.Join(missionDir, "orchestrator", "checkpoints")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var auto []CheckpointData
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		var cp CheckpointData
+		if readJSON(filepath.Join(dir, e.Name()), &cp) == nil && cp.Tag == autoCheckpointTag {
+			auto = append(auto, cp)
+		}
+	}
+	if len(auto) <= keep {
+		return nil, nil
+	}
+	sort.Slice(auto, func(i, j int) bool { return auto[i].CreatedAt < auto[j].CreatedAt })
+	var pruned []string
+	for _, cp := range auto[:len(auto)-keep] {
+		if err := os.Remove(filepath.Join(dir, cp.ID+".json")); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, cp.ID)
+	}
+	return pruned, nil
+}
+
 func createCheckpoint(missionDir string, sessionID string) (*CheckpointData, error) {
+	return createTaggedCheckpoint(missionDir, sessionID, "", "")
+}
+
+// createTaggedCheckpoint is createCheckpoint with a tag and summary
+// recorded in the checkpoint.
+func createTaggedCheckpoint(missionDir, sessionID, tag, summary string) (*CheckpointData, error) {
 	// Read current state
 	var stageState StageState
 	if err := readJSON(filepath.Join(missionDir, "state", "stage.json"), &stageState); err != nil {
@@ -240,6 +330,8 @@ func createCheckpoint(missionDir string, sessionID string) (*CheckpointData, err
 		Gates:     gatesState.Gates,
 		Decisions: decisions,
 		Blockers:  blockers,
+		Summary:   summary,
+		Tag:       tag,
 	}
 
 	// Write checkpoint file
@@ -264,6 +356,7 @@ func createCheckpoint(missionDir string, sessionID string) (*CheckpointData, err
 		"stage":         cp.Stage,
 		"session_id":    sessionID,
 	})
+	recordEvent(missionDir, eventstore.CheckpointCreated, eventstore.CheckpointData{ID: cp.ID, Stage: cp.Stage})
 
 	// Auto-commit to git
 	gitAutoCommit(missionDir, CommitCategoryCheckpoint, fmt.Sprintf("checkpoint %s", cp.ID))
@@ -473,8 +566,8 @@ func runCheckpointRestart(cmd *cobra.Command, args []string) error {
 		briefingCP = &fromCP
 	}
 
-	// Compile briefing using mc-core (if available)
-	briefing := compileBriefing(missionDir, briefingCP)
+	budget, _ := cmd.Flags().GetInt("budget")
+	brief := compileBriefing(missionDir, briefingCP, budget)
 
 	writeAuditLog(missionDir, AuditSessionEnded, "cli", map[string]interface{}{
 		"session_id":    oldSessionID,
@@ -514,7 +607,7 @@ func runCheckpointRestart(cmd *cobra.Command, args []string) error {
 		"checkpoint_id": cp.ID,
 		"session_id":    newSessionID,
 		"created_at":    now,
-		"briefing":      briefing,
+		"briefing":      brief,
 	})
 
 	// Git commit the session transition
@@ -525,7 +618,7 @@ func runCheckpointRestart(cmd *cobra.Command, args []string) error {
 		"new_session":   newSessionID,
 		"checkpoint_id": cp.ID,
 		"stage":         cp.Stage,
-		"briefing":      briefing,
+		"briefing":      brief,
 	}
 
 	output, _ := json.MarshalIndent(result, "", "  ")
@@ -534,92 +627,34 @@ func runCheckpointRestart(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func compileBriefing(missionDir string, cp *CheckpointData) string {
-	// Try to use mc-core checkpoint-compile if the binary is available
-	// Write checkpoint to temp file, call mc-core, read output
-	tmpFile := filepath.Join(missionDir, "orchestrator", ".tmp-checkpoint.json")
-	if err := writeJSON(tmpFile, cp); err == nil {
-		defer os.Remove(tmpFile)
-
-		mcCore := exec.Command("mc-core", "checkpoint-compile", tmpFile)
-		if output, err := mcCore.Output(); err == nil {
-			return strings.TrimSpace(string(output))
-		}
-	}
-
-	// Fallback: generate simple briefing in Go
-	return generateFallbackBriefing(cp)
-}
-
-func generateFallbackBriefing(cp *CheckpointData) string {
-	var b strings.Builder
-
-	b.WriteString("# Session Briefing\n\n")
-	b.WriteString(fmt.Sprintf("**Stage:** %s\n", cp.Stage))
-	if cp.SessionID != "" {
-		b.WriteString(fmt.Sprintf("**Previous Session:** %s\n", cp.SessionID))
+// compileBriefing fits cp, the recent King conversation and the
+// mission's recent findings into a briefing of budget tokens
+// (briefing_tokens in config.json if 0).
+func compileBriefing(missionDir string, cp *CheckpointData, budget int) string {
+	if budget <= 0 {
+		budget = briefing.LoadBudget(missionDir)
 	}
-	b.WriteString("\n")
-
-	if len(cp.Decisions) > 0 {
-		b.WriteString("## Decisions\n")
-		for _, d := range cp.Decisions {
-			b.WriteString(fmt.Sprintf("- %s\n", d))
-		}
-		b.WriteString("\n")
+	in := briefing.Input{
+		CheckpointID: cp.ID,
+		SessionID:    cp.SessionID,
+		Stage:        cp.Stage,
+		Gates:        make(map[string]string, len(cp.Gates)),
+		Decisions:    cp.Decisions,
+		Blockers:     cp.Blockers,
+		Conversation: briefing.RecentConversation(missionDir),
+		Findings:     briefing.RecentFindings(missionDir),
 	}
-
-	// Task summary
-	total := len(cp.Tasks)
-	done := 0
-	pending := 0
 	for _, t := range cp.Tasks {
-		switch t.Status {
-		case "complete":
-			done++
-		case "pending":
-			pending++
-		}
-	}
-	b.WriteString("## Tasks\n")
-	b.WriteString(fmt.Sprintf("- Total: %d, Done: %d, Pending: %d\n\n", total, done, pending))
-
-	if len(cp.Blockers) > 0 {
-		b.WriteString("## Blockers\n")
-		for _, bl := range cp.Blockers {
-			b.WriteString(fmt.Sprintf("- %s\n", bl))
-		}
-		b.WriteString("\n")
+		in.Tasks = append(in.Tasks, briefing.Task{ID: t.ID, Name: t.Name, Persona: t.Persona, Status: t.Status})
 	}
-
-	// Gate summary
-	approved := []string{}
-	pending_gates := []string{}
 	for stage, gate := range cp.Gates {
-		if gate.Status == "approved" {
-			approved = append(approved, stage)
-		} else {
-			pending_gates = append(pending_gates, stage)
-		}
-	}
-	sort.Strings(approved)
-	sort.Strings(pending_gates)
-
-	if len(approved) > 0 {
-		b.WriteString(fmt.Sprintf("## Gates Approved\n%s\n\n", strings.Join(approved, ", ")))
+		in.Gates[stage] = gate.Status
 	}
-
-	return b.String()
+	text, _ := briefing.Compile(in, briefing.Options{Budget: budget})
+	return text
 }
 
 func appendSession(missionDir string, record SessionRecord) {
 	sessionsPath := filepath.Join(missionDir, "orchestrator", "sessions.jsonl")
-	data, _ := json.Marshal(record)
-	f, err := os.OpenFile(sessionsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-	_, _ = f.Write(data)
-	_, _ = f.WriteString("\n")
+	_ = jsonl.Append(sessionsPath, jsonl.Options{}, record)
 }
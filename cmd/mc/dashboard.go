@@ -0,0 +1,22 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dashboardDist is the web dashboard build, copied from web/dist by
+// "make build-mc" (build-mc-noweb leaves a placeholder page).
+//
+//go:embed all:dist
+var dashboardDist embed.FS
+
+// dashboardFS returns the build with dist/ stripped, for mc serve to serve
+// at /.
+func dashboardFS() fs.FS {
+	sub, err := fs.Sub(dashboardDist, "dist")
+	if err != nil {
+		panic(err) // dist is embedded, so this can't fail
+	}
+	return sub
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("repair", false, "Set corrupt lines aside so the files parse again")
+	doctorCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the .mission JSONL files for corrupt lines",
+	Long: `Reads every .jsonl file under .mission (tasks, audit log, event stream,
+chat history, sessions) and lists the lines that aren't valid JSON, e.g.
+from a crash mid-write or a bad merge.
+
+With --repair, each affected file is rewritten without its corrupt lines.
+The lines are kept in <file>.corrupt with their line numbers, and the
+repair is audited.
+
+Examples:
+  mc doctor            # Report corrupt lines
+  mc doctor --repair   # Set them aside`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	repair, _ := cmd.Flags().GetBool("repair")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	reports, err := doctorJSONL(missionDir, repair, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else {
+		printDoctorReports(cmd, missionDir, reports, repair)
+	}
+
+	corrupt := 0
+	for _, rep := range reports {
+		corrupt += len(rep.Skipped)
+	}
+	if corrupt > 0 && !repair {
+		return fmt.Errorf("%d corrupt line(s) found; run 'mc doctor --repair' to set them aside", corrupt)
+	}
+	return nil
+}
+
+// doctorJSONL checks, or with repair repairs, every .jsonl file under
+// missionDir, in path order.
+func doctorJSONL(missionDir string, repair bool, now time.Time) ([]jsonl.Report, error) {
+	var paths []string
+	err := filepath.WalkDir(missionDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	reports := []jsonl.Report{}
+	for _, path := range paths {
+		var rep jsonl.Report
+		if repair {
+			rep, err = jsonl.Repair(path, jsonl.Options{Sync: true}, now)
+		} else {
+			rep, err = jsonl.Check(path)
+		}
+		if err != nil {
+			return reports, fmt.Errorf("%s: %w", path, err)
+		}
+		reports = append(reports, rep)
+	}
+
+	if repair {
+		for _, rep := range reports {
+			if len(rep.Skipped) == 0 {
+				continue
+			}
+			rel, _ := filepath.Rel(missionDir, rep.Path)
+			writeAuditLog(missionDir, AuditJSONLRepaired, "cli", map[string]interface{}{
+				"file":   rel,
+				"lines":  len(rep.Skipped),
+				"backup": filepath.Base(rep.Backup),
+			})
+		}
+	}
+	return reports, nil
+}
+
+func printDoctorReports(cmd *cobra.Command, missionDir string, reports []jsonl.Report, repaired bool) {
+	w := cmd.OutOrStdout()
+	clean := true
+	for _, rep := range reports {
+		if len(rep.Skipped) == 0 {
+			continue
+		}
+		clean = false
+		rel, _ := filepath.Rel(missionDir, rep.Path)
+		fmt.Fprintf(w, "%s: %d corrupt line(s)\n", rel, len(rep.Skipped))
+		for _, s := range rep.Skipped {
+			text := s.Text
+			if len(text) > 60 {
+				text = text[:57] + "..."
+			}
+			fmt.Fprintf(w, "  line %d: %s: %s\n", s.Line, s.Err, text)
+		}
+		if repaired {
+			fmt.Fprintf(w, "  set aside in %s\n", filepath.Base(rep.Backup))
+		}
+	}
+	if clean {
+		fmt.Fprintf(w, "%d JSONL file(s) checked, no corrupt lines\n", len(reports))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDoctorRepairsCorruptTasks(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	path := tasksPath(missionDir)
+	os.WriteFile(path, []byte("{\"id\":\"a\",\"name\":\"a\",\"status\":\"pending\"}\n{\"id\":\"b\",\"na\n"), 0644)
+	if _, err := loadTasks(missionDir); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected line 2 reported, got %v", err)
+	}
+
+	doctor := &cobra.Command{Use: "doctor", RunE: runDoctor}
+	doctor.Flags().Bool("repair", false, "")
+	doctor.Flags().Bool("json", false, "")
+	doctor.SetOut(io.Discard)
+	if err := doctor.RunE(doctor, nil); err == nil {
+		t.Fatal("expected mc doctor to fail on a corrupt file")
+	}
+
+	doctor.Flags().Set("repair", "true")
+	if err := doctor.RunE(doctor, nil); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := loadTasks(missionDir)
+	if err != nil || len(tasks) != 1 || tasks[0].ID != "a" {
+		t.Fatalf("expected task a left, got %v, %v", tasks, err)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("expected the corrupt line kept: %v", err)
+	}
+	entries, _ := readAuditLog(missionDir)
+	if len(entries) == 0 || entries[len(entries)-1].Action != AuditJSONLRepaired {
+		t.Errorf("expected the repair audited, got %v", entries)
+	}
+
+	reports, err := doctorJSONL(missionDir, false, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rep := range reports {
+		if len(rep.Skipped) > 0 {
+			t.Errorf("expected clean files after repair, got %+v", rep)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.AddCommand(eventsReplayCmd)
+
+	eventsCmd.Flags().Uint64("since", 0, "Only events after this sequence number")
+	eventsCmd.Flags().StringP("type", "t", "", "Filter by event type (e.g. task_updated)")
+	eventsCmd.Flags().Bool("json", false, "Output raw JSON lines")
+
+	eventsReplayCmd.Flags().String("until", "", "Event sequence number, RFC3339 time, or duration ago (default: all)")
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "List the mutation event stream",
+	Long: `Lists .mission/events/stream.jsonl, the append-only record of every
+mutation mc makes: task_created, task_updated, task_deleted, gate_approved,
+stage_changed, stage_rolled_back and checkpoint_created. Task events carry
+the whole task.
+
+Examples:
+  mc events                       # Every event
+  mc events --since 120           # Events after #120
+  mc events -t stage_changed      # Stage transitions
+  mc events replay --until 120    # Mission state as of event #120`,
+	Args: cobra.NoArgs,
+	RunE: runEvents,
+}
+
+var eventsReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Rebuild mission state from the event stream",
+	Long: `Folds the event stream into the stage, tasks, gates and checkpoints it
+produces and prints them as JSON. With --until, the state is rebuilt as it
+was right after that event or at that time; mc replay writes it out as a
+mission directory instead.
+
+Events are recorded from the first mutation after upgrading, so an older
+mission's replay starts from that point.`,
+	Args: cobra.NoArgs,
+	RunE: runEventsReplay,
+}
+
+func runEvents(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	since, _ := cmd.Flags().GetUint64("since")
+	eventType, _ := cmd.Flags().GetString("type")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	events, _, err := eventstore.Read(missionDir, eventstore.Filter{Since: since, Type: eventType})
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(events) == 0 && !asJSON {
+		fmt.Fprintln(out, "No events recorded")
+		return nil
+	}
+	for _, e := range events {
+		if asJSON {
+			data, _ := json.Marshal(e)
+			fmt.Fprintln(out, string(data))
+			continue
+		}
+		fmt.Fprintf(out, "#%-5d %s %-18s %s\n", e.Seq, e.Time, e.Type, eventSummary(e))
+	}
+	return nil
+}
+
+func runEventsReplay(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	until, _ := cmd.Flags().GetString("until")
+
+	events, _, err := eventstore.Read(missionDir, eventstore.Filter{})
+	if err != nil {
+		return err
+	}
+	cut, err := replayCut(events, until)
+	if err != nil {
+		return err
+	}
+	output, _ := json.MarshalIndent(eventstore.Project(events[:cut]), "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	return nil
+}
+
+// eventSummary is the one-line description of e in mc events.
+func eventSummary(e eventstore.Event) string {
+	var parts []string
+	switch e.Type {
+	case eventstore.TaskCreated, eventstore.TaskUpdated, eventstore.TaskDeleted:
+		var d struct {
+			ID   string `json:"id"`
+			Task *Task  `json:"task"`
+		}
+		json.Unmarshal(e.Data, &d)
+		parts = append(parts, "task="+d.ID)
+		if d.Task != nil {
+			parts = append(parts, "status="+d.Task.Status)
+		}
+	case eventstore.GateApproved:
+		var d eventstore.GateData
+		json.Unmarshal(e.Data, &d)
+		parts = append(parts, "stage="+d.Stage)
+	case eventstore.StageChanged, eventstore.StageRolledBack:
+		var d eventstore.StageData
+		json.Unmarshal(e.Data, &d)
+		parts = append(parts, fmt.Sprintf("%s → %s", d.From, d.To))
+	case eventstore.CheckpointCreated:
+		var d eventstore.CheckpointData
+		json.Unmarshal(e.Data, &d)
+		parts = append(parts, "checkpoint="+d.ID)
+	}
+	if e.User != "" {
+		parts = append(parts, fmt.Sprintf("user=%q", e.User))
+	}
+	return strings.Join(parts, " ")
+}
+
+// recordEvent appends a mutation to the event stream. Like the audit log,
+// a failure is only a warning; it never fails the command.
+func recordEvent(missionDir, eventType string, data interface{}) {
+	e := eventstore.Event{
+		Type:      eventType,
+		User:      identity.Current().String(),
+		RequestID: os.Getenv("MC_REQUEST_ID"),
+	}
+	if err := eventstore.Append(missionDir, e, data); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record %s event: %v\n", eventType, err)
+	}
+}
+
+// recordTaskEvents records the difference between the tasks before and
+// after a save as task_created, task_updated and task_deleted events.
+func recordTaskEvents(missionDir string, before, after []Task) {
+	old := make(map[string][]byte, len(before))
+	for _, t := range before {
+		old[t.ID], _ = json.Marshal(t)
+	}
+	for _, t := range after {
+		data, err := json.Marshal(t)
+		if err != nil {
+			continue
+		}
+		prev, existed := old[t.ID]
+		delete(old, t.ID)
+		switch {
+		case !existed:
+			recordEvent(missionDir, eventstore.TaskCreated, eventstore.TaskData{ID: t.ID, Task: data})
+		case !bytes.Equal(prev, data):
+			recordEvent(missionDir, eventstore.TaskUpdated, eventstore.TaskData{ID: t.ID, Task: data})
+		}
+	}
+	for _, t := range before {
+		if _, gone := old[t.ID]; gone {
+			recordEvent(missionDir, eventstore.TaskDeleted, eventstore.TaskData{ID: t.ID})
+		}
+	}
+}
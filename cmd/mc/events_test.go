@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+)
+
+func TestSaveTasksRecordsEvents(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	saveTasks(missionDir, []Task{{ID: "a", Name: "a", Status: "pending"}, {ID: "b", Name: "b", Status: "pending"}})
+	saveTasks(missionDir, []Task{{ID: "a", Name: "a", Status: "done"}, {ID: "b", Name: "b", Status: "pending"}})
+	saveTasks(missionDir, []Task{{ID: "a", Name: "a", Status: "done"}})
+
+	events, _, err := eventstore.Read(missionDir, eventstore.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	want := []string{"stage_changed", "task_created", "task_created", "task_updated", "task_deleted"}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("events = %v, want %v", types, want)
+		}
+	}
+
+	state := eventstore.Project(events)
+	var task Task
+	if len(state.Tasks) != 1 || json.Unmarshal(state.Tasks[0], &task) != nil || task.Status != "done" {
+		t.Errorf("projected tasks = %s", state.Tasks)
+	}
+	if state.Stage != "discovery" {
+		t.Errorf("projected stage = %q, want discovery", state.Stage)
+	}
+}
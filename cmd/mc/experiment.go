@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/experiment"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(experimentCmd)
+	experimentCmd.AddCommand(experimentStartCmd)
+	experimentCmd.AddCommand(experimentReportCmd)
+	experimentCmd.AddCommand(experimentStopCmd)
+	experimentReportCmd.Flags().Bool("json", false, "Output as JSON")
+	experimentStopCmd.Flags().String("winner", "", "Variant whose prompt becomes the persona's (a, b, or auto for the report's pick)")
+}
+
+var experimentCmd = &cobra.Command{
+	Use:   "experiment",
+	Short: "Run A/B experiments on a persona's prompt",
+	Long: `Registers two prompt variants for a persona. While the experiment runs,
+mc spawn alternates the persona's workers between variant a and variant b
+and records which one each worker got. 'mc experiment report' compares
+the variants by handoff validity, retries, tokens and review findings, and
+'mc experiment stop --winner' makes the better prompt the persona's own.
+
+The report is also served at GET /api/experiments, where token usage is
+known for workers the orchestrator ran.`,
+}
+
+var experimentStartCmd = &cobra.Command{
+	Use:   "start <persona> <prompt-a.md> <prompt-b.md>",
+	Short: "Start alternating a persona's spawns between two prompts",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runExperimentStart,
+}
+
+var experimentReportCmd = &cobra.Command{
+	Use:   "report [persona]",
+	Short: "Compare the variants of each experiment",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runExperimentReport,
+}
+
+var experimentStopCmd = &cobra.Command{
+	Use:   "stop <persona>",
+	Short: "End a persona's experiment, optionally promoting a variant",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExperimentStop,
+}
+
+func runExperimentStart(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	persona := args[0]
+	var prompts [][]byte
+	for _, path := range args[1:] {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt variant: %w", err)
+		}
+		prompts = append(prompts, data)
+	}
+
+	e, err := experiment.Start(missionDir, persona, prompts[0], prompts[1], time.Now())
+	if err != nil {
+		return err
+	}
+	writeAuditLog(missionDir, AuditExperimentStarted, "cli", map[string]interface{}{
+		"experiment": e.ID,
+		"persona":    persona,
+		"variant_a":  args[1],
+		"variant_b":  args[2],
+	})
+	fmt.Fprintf(cmd.OutOrStdout(), "Started experiment %s: %s workers alternate between %s and %s\n",
+		e.ID, persona, e.Variants[0].Prompt, e.Variants[1].Prompt)
+	return nil
+}
+
+func runExperimentReport(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	results, err := experiment.Report(missionDir, nil)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		var filtered []experiment.Result
+		for _, res := range results {
+			if res.Persona == args[0] {
+				filtered = append(filtered, res)
+			}
+		}
+		results = filtered
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(out, "No prompt experiments")
+		return nil
+	}
+	for i, res := range results {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		state := "running"
+		if !res.Running {
+			state = "ended " + res.EndedAt
+			if res.Promoted != "" {
+				state += ", variant " + res.Promoted + " promoted"
+			}
+		}
+		fmt.Fprintf(out, "%s (%s, %s)\n", res.ID, res.Persona, state)
+		fmt.Fprintf(out, "  %-8s %6s %6s %9s %8s %8s %10s\n", "variant", "spawns", "tasks", "validity", "retries", "tokens", "findings")
+		for _, v := range res.Variants {
+			validity := "-"
+			if v.ValidHandoffs+v.InvalidHandoffs > 0 {
+				validity = fmt.Sprintf("%.0f%%", v.HandoffValidity*100)
+			}
+			tokens := "-"
+			if v.Tokens > 0 {
+				tokens = fmt.Sprintf("%d", v.Tokens)
+			}
+			fmt.Fprintf(out, "  %-8s %6d %6d %9s %8d %8s %10s\n", v.Name, v.Spawns, v.Tasks, validity, v.Retries, tokens,
+				fmt.Sprintf("%d (%d blocking)", v.ReviewFindings, v.BlockingFindings))
+		}
+		if res.Winner != "" {
+			fmt.Fprintf(out, "  Winner: %s — %s\n", res.Winner, res.Reason)
+		} else {
+			fmt.Fprintf(out, "  No winner yet: %s\n", res.Reason)
+		}
+	}
+	return nil
+}
+
+func runExperimentStop(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	persona := args[0]
+	winner, _ := cmd.Flags().GetString("winner")
+	winner = strings.ToLower(strings.TrimSpace(winner))
+
+	details := map[string]interface{}{"persona": persona}
+	if winner == "auto" {
+		active, err := experiment.Active(missionDir, persona)
+		if err != nil {
+			return err
+		}
+		if active == nil {
+			return fmt.Errorf("persona %s has no experiment running", persona)
+		}
+		results, err := experiment.Report(missionDir, nil)
+		if err != nil {
+			return err
+		}
+		for _, res := range results {
+			if res.ID != active.ID {
+				continue
+			}
+			if res.Winner == "" {
+				return fmt.Errorf("no winner to promote: %s", res.Reason)
+			}
+			winner = res.Winner
+			details["reason"] = res.Reason
+		}
+	}
+
+	e, err := experiment.Stop(missionDir, persona, winner, time.Now())
+	if err != nil {
+		return err
+	}
+	details["experiment"] = e.ID
+	if winner != "" {
+		details["winner"] = winner
+	}
+	writeAuditLog(missionDir, AuditExperimentStopped, "cli", details)
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Stopped experiment %s\n", e.ID)
+	if winner != "" {
+		fmt.Fprintf(out, "Variant %s is now the %s prompt (.mission/prompts/%s.md)\n", winner, persona, persona)
+	}
+	return nil
+}
+
+// recordExperimentHandoff counts a handoff against the prompt variant its
+// worker was given, if any. It never fails the handoff.
+func recordExperimentHandoff(missionDir, workerID string, valid bool) {
+	if err := experiment.RecordHandoff(missionDir, workerID, valid); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: handoff not recorded for its prompt experiment: %v\n", err)
+	}
+}
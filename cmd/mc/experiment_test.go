@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/experiment"
+	"github.com/spf13/cobra"
+)
+
+func TestExperimentAlternatesSpawns(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	os.WriteFile(a, []byte("Variant A for {{worker_id}}"), 0644)
+	os.WriteFile(b, []byte("Variant B for {{worker_id}}"), 0644)
+	if err := runExperimentStart(&cobra.Command{}, []string{"architect", a, b}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, task := range []string{"Design the API", "Design the schema"} {
+		cmd := newSpawnCmd()
+		cmd.Flags().Set("task-id", []string{"t1", "t2"}[i])
+		if err := cmd.RunE(cmd, []string{"architect", task}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var state WorkersState
+	readJSON(filepath.Join(missionDir, "state", "workers.json"), &state)
+	if len(state.Workers) != 2 {
+		t.Fatalf("expected two workers, got %+v", state.Workers)
+	}
+	for i, want := range []string{"A", "B"} {
+		w := state.Workers[i]
+		prompt, _ := os.ReadFile(filepath.Join(tmp, "mc-worker-"+w.ID+".md"))
+		if !strings.HasPrefix(string(prompt), "Variant "+want+" for "+w.ID) || !strings.HasSuffix(w.Variant, "/"+strings.ToLower(want)) {
+			t.Errorf("worker %d: variant %q, prompt %q", i, w.Variant, prompt)
+		}
+	}
+
+	// A rejected handoff counts against the first worker's variant
+	if _, _, err := storeHandoff(missionDir, []byte(`{"worker_id":"`+state.Workers[0].ID+`","task_id":"t1","status":"done"}`)); err == nil {
+		t.Fatal("expected the handoff rejected")
+	}
+	if _, _, err := storeHandoff(missionDir, []byte(`{"worker_id":"`+state.Workers[1].ID+`","task_id":"t2","status":"complete"}`)); err != nil {
+		t.Fatal(err)
+	}
+	results, err := experiment.Report(missionDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	va, vb := results[0].Variants[0], results[0].Variants[1]
+	if va.InvalidHandoffs != 1 || vb.ValidHandoffs != 1 {
+		t.Errorf("unexpected handoff counts a=%+v b=%+v", va, vb)
+	}
+
+	stop := &cobra.Command{}
+	stop.Flags().String("winner", "auto", "")
+	var out bytes.Buffer
+	stop.SetOut(&out)
+	if err := runExperimentStop(stop, []string{"architect"}); err == nil || !strings.Contains(err.Error(), "needs 3 spawns") {
+		t.Errorf("expected too few spawns to pick a winner, got %v", err)
+	}
+	stop.Flags().Set("winner", "b")
+	if err := runExperimentStop(stop, []string{"architect"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(missionDir, "prompts", "architect.md")); !strings.HasPrefix(string(data), "Variant B") {
+		t.Errorf("expected variant b promoted, got %q", data)
+	}
+}
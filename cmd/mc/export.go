@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/export"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("format", "f", export.FormatCSV, "Output format: csv, parquet")
+	exportCmd.Flags().String("since", "", "Only rows at or after this time (RFC3339 or duration like 720h)")
+	exportCmd.Flags().StringP("output", "o", "", "Write to file instead of stdout")
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <audit|usage>",
+	Short: "Export the audit trail or token usage as CSV or Parquet",
+	Long: `Writes mission data for spreadsheets, BI tools and compliance archives:
+
+  audit  every audit entry, rotated archives included: timestamp, action,
+         category, actor, user, request_id and details (as JSON)
+  usage  the token usage ledger by UTC day: date, tokens and cost_usd
+
+The same exports are served at GET /api/export/audit and
+GET /api/export/usage, with ?format= and ?since=.
+
+Examples:
+  mc export audit > audit.csv
+  mc export usage -f parquet --since 720h -o usage.parquet`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: export.Tables,
+	RunE:      runExport,
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	sinceStr, _ := cmd.Flags().GetString("since")
+	output, _ := cmd.Flags().GetString("output")
+	since, err := parseAuditTime(sinceStr)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	t, err := export.Build(missionDir, args[0], since)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := export.Write(&buf, t, strings.ToLower(format)); err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+
+	if output == "" {
+		_, err := cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d %s rows to %s\n", len(t.Rows), t.Name, output)
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+	"github.com/spf13/cobra"
+)
+
+func TestExport(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	writeAuditLog(missionDir, AuditTaskCreated, "cli", map[string]interface{}{"task_id": "t1"})
+	if err := budget.Record(missionDir, 1500, 0.75, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	newCmd := func() (*cobra.Command, *bytes.Buffer) {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: "export", RunE: runExport}
+		cmd.Flags().StringP("format", "f", "csv", "")
+		cmd.Flags().String("since", "", "")
+		cmd.Flags().StringP("output", "o", "", "")
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		return cmd, &out
+	}
+
+	cmd, out := newCmd()
+	if err := cmd.RunE(cmd, []string{"audit"}); err != nil {
+		t.Fatal(err)
+	}
+	// mc init's entry, then ours
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 ||
+		!strings.HasPrefix(lines[0], "timestamp,action,category") || !strings.Contains(lines[2], `task_created,task,cli,,,"{""task_id"":""t1""}"`) {
+		t.Errorf("unexpected audit CSV:\n%s", out)
+	}
+
+	cmd, _ = newCmd()
+	file := filepath.Join(tmpDir, "usage.parquet")
+	cmd.Flags().Set("format", "parquet")
+	cmd.Flags().Set("since", "24h")
+	cmd.Flags().Set("output", file)
+	if err := cmd.RunE(cmd, []string{"usage"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Errorf("expected a Parquet file, got %q", data)
+	}
+
+	cmd, _ = newCmd()
+	cmd.Flags().Set("format", "xlsx")
+	if err := cmd.RunE(cmd, []string{"usage"}); err == nil || !strings.Contains(err.Error(), "invalid --format") {
+		t.Errorf("expected an unknown format refused, got %v", err)
+	}
+	cmd, _ = newCmd()
+	if err := cmd.RunE(cmd, []string{"workers"}); err == nil {
+		t.Error("expected an unknown table refused")
+	}
+}
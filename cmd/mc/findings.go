@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// findingGateStages are the gates that open critical and high findings
+// from reviewer and security handoffs hold shut.
+var findingGateStages = map[string]bool{"verify": true, "validate": true}
+
+var (
+	blockingSeverities = map[string]bool{"critical": true, "high": true}
+	findingPersonas    = map[string]bool{"reviewer": true, "security": true}
+)
+
+func init() {
+	rootCmd.AddCommand(findingsCmd)
+	findingsCmd.AddCommand(findingsResolveCmd)
+	findingsCmd.Flags().Bool("open", false, "Show only unresolved findings")
+	findingsCmd.Flags().Bool("blocking", false, "Show only the open critical/high reviewer and security findings that hold the verify and validate gates")
+	findingsResolveCmd.Flags().String("note", "", "How the finding was resolved (required)")
+}
+
+var findingsCmd = &cobra.Command{
+	Use:   "findings",
+	Short: "List handoff findings",
+	Long: `Lists the findings workers reported in their handoffs, as <task-id>#<n>.
+
+Open critical and high findings from reviewer and security tasks block
+approval of the verify and validate gates until they are resolved with
+mc findings resolve, or the approval overrides them with
+mc gate approve --override-findings "justification".`,
+	Args: cobra.NoArgs,
+	RunE: runFindings,
+}
+
+var findingsResolveCmd = &cobra.Command{
+	Use:   "resolve <task-id>#<n>",
+	Short: "Mark a finding resolved",
+	Long: `Marks a finding resolved with a note saying how, recorded in the audit log.
+
+Example:
+  mc findings resolve t-12#1 --note "Fixed in a3f9c2e"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFindingsResolve,
+}
+
+// TaskFinding is a finding with where it is stored: its task's findings
+// file and its 1-based position there.
+type TaskFinding struct {
+	TaskID  string `json:"task_id"`
+	Index   int    `json:"index"`
+	Persona string `json:"persona,omitempty"`
+	Finding
+}
+
+// Ref is how commands name the finding.
+func (f TaskFinding) Ref() string {
+	return fmt.Sprintf("%s#%d", f.TaskID, f.Index)
+}
+
+// Blocking reports whether f holds the verify and validate gates.
+func (f TaskFinding) Blocking() bool {
+	return f.Resolved == "" && blockingSeverities[strings.ToLower(f.Severity)] && findingPersonas[f.Persona]
+}
+
+// loadFindings reads every task's findings, by task ID then position.
+func loadFindings(missionDir string) ([]TaskFinding, error) {
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	personas := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		personas[t.ID] = t.Persona
+	}
+	paths, _ := filepath.Glob(filepath.Join(missionDir, "findings", "*.json"))
+	sort.Strings(paths)
+	var all []TaskFinding
+	for _, path := range paths {
+		var fs []Finding
+		if readJSON(path, &fs) != nil {
+			continue // not a handoff findings array
+		}
+		taskID := strings.TrimSuffix(filepath.Base(path), ".json")
+		for i, f := range fs {
+			all = append(all, TaskFinding{TaskID: taskID, Index: i + 1, Persona: personas[taskID], Finding: f})
+		}
+	}
+	return all, nil
+}
+
+// blockingFindings returns the open findings that hold the verify and
+// validate gates.
+func blockingFindings(missionDir string) ([]TaskFinding, error) {
+	all, err := loadFindings(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	var blocking []TaskFinding
+	for _, f := range all {
+		if f.Blocking() {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking, nil
+}
+
+// checkFindingsGate refuses approval of the verify and validate gates
+// while blocking findings are open, unless override justifies it, which is
+// recorded in the audit log.
+func checkFindingsGate(missionDir, stage, override string) error {
+	if !findingGateStages[stage] {
+		return nil
+	}
+	blocking, err := blockingFindings(missionDir)
+	if err != nil {
+		return fmt.Errorf("failed to read findings: %w", err)
+	}
+	if len(blocking) == 0 {
+		return nil
+	}
+	refs := make([]string, len(blocking))
+	for i, f := range blocking {
+		refs[i] = f.Ref()
+	}
+	override = strings.TrimSpace(override)
+	if override == "" {
+		return fmt.Errorf("%d open critical/high finding(s) block the %s gate: %s (resolve them with mc findings resolve, or approve with --override-findings \"justification\")",
+			len(blocking), stage, strings.Join(refs, ", "))
+	}
+	writeAuditLog(missionDir, AuditFindingsOverridden, "cli", map[string]interface{}{
+		"stage":         stage,
+		"justification": override,
+		"findings":      refs,
+	})
+	return nil
+}
+
+func runFindings(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	all, err := loadFindings(missionDir)
+	if err != nil {
+		return err
+	}
+	openOnly, _ := cmd.Flags().GetBool("open")
+	blockingOnly, _ := cmd.Flags().GetBool("blocking")
+	shown := 0
+	for _, f := range all {
+		if (openOnly && f.Resolved != "") || (blockingOnly && !f.Blocking()) {
+			continue
+		}
+		status := "open"
+		if f.Resolved != "" {
+			status = "resolved: " + f.Resolved
+		}
+		severity := f.Severity
+		if severity == "" {
+			severity = "-"
+		}
+		fmt.Printf("%-12s %-8s %-10s %s (%s)\n", f.Ref(), severity, f.Persona, f.Summary, status)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("No findings")
+	}
+	return nil
+}
+
+func runFindingsResolve(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	note, _ := cmd.Flags().GetString("note")
+	return resolveFinding(missionDir, args[0], note)
+}
+
+// resolveFinding marks the finding ref (<task-id>#<n>) resolved with note.
+func resolveFinding(missionDir, ref, note string) error {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return fmt.Errorf("--note is required (say how the finding was resolved)")
+	}
+	taskID, n, ok := strings.Cut(ref, "#")
+	index, err := strconv.Atoi(n)
+	if !ok || err != nil || taskID == "" || strings.ContainsAny(taskID, `/\`) {
+		return fmt.Errorf("invalid finding %q: want <task-id>#<n>", ref)
+	}
+	path := filepath.Join(missionDir, "findings", taskID+".json")
+	var fs []Finding
+	if err := readJSON(path, &fs); err != nil {
+		return fmt.Errorf("no findings for task %s", taskID)
+	}
+	if index < 1 || index > len(fs) {
+		return fmt.Errorf("task %s has %d finding(s), not #%d", taskID, len(fs), index)
+	}
+	f := &fs[index-1]
+	if f.Resolved != "" {
+		return fmt.Errorf("finding %s is already resolved: %s", ref, f.Resolved)
+	}
+	f.Resolved = note
+	data, _ := json.MarshalIndent(fs, "", "  ")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to store findings: %w", err)
+	}
+	writeAuditLog(missionDir, AuditFindingResolved, "cli", map[string]interface{}{
+		"finding":  ref,
+		"severity": f.Severity,
+		"summary":  f.Summary,
+		"note":     note,
+	})
+	fmt.Printf("Finding %s resolved\n", ref)
+	return nil
+}
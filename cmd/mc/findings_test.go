@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindingsBlockVerifyGate(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("mc init failed: %v", err)
+	}
+	missionDir := filepath.Join(tmpDir, ".mission")
+	addTask(t, missionDir, Task{ID: "sec1", Name: "audit", Stage: "verify", Status: "done", Persona: "security"})
+	addTask(t, missionDir, Task{ID: "dev1", Name: "build", Stage: "implement", Status: "done", Persona: "developer"})
+	os.MkdirAll(filepath.Join(missionDir, "findings"), 0755)
+	writeJSON(filepath.Join(missionDir, "findings", "sec1.json"), []Finding{
+		{Type: "vulnerability", Severity: "high", Summary: "SQL injection in login"},
+		{Type: "issue", Severity: "low", Summary: "Verbose errors"},
+	})
+	writeJSON(filepath.Join(missionDir, "findings", "dev1.json"), []Finding{
+		{Type: "error", Severity: "critical", Summary: "Build flaked"},
+	})
+
+	// Only the reviewer/security critical/high finding blocks, and only
+	// verify and validate
+	err := checkFindingsGate(missionDir, "verify", "")
+	if err == nil || !strings.Contains(err.Error(), "sec1#1") || strings.Contains(err.Error(), "dev1") {
+		t.Fatalf("verify gate: err = %v, want sec1#1 blocking", err)
+	}
+	if err := checkFindingsGate(missionDir, "implement", ""); err != nil {
+		t.Errorf("implement gate: %v", err)
+	}
+
+	if err := checkFindingsGate(missionDir, "validate", "Accepted risk, fix scheduled"); err != nil {
+		t.Fatalf("override: %v", err)
+	}
+	entries, _ := readAuditLog(missionDir)
+	if last := entries[len(entries)-1]; last.Action != AuditFindingsOverridden || last.Details["justification"] != "Accepted risk, fix scheduled" {
+		t.Errorf("last audit entry = %+v", last)
+	}
+
+	if err := resolveFinding(missionDir, "sec1#1", ""); err == nil {
+		t.Error("resolving without a note should fail")
+	}
+	if err := resolveFinding(missionDir, "sec1#1", "Parameterised the query"); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if err := resolveFinding(missionDir, "sec1#1", "again"); err == nil {
+		t.Error("resolving twice should fail")
+	}
+	if err := checkFindingsGate(missionDir, "verify", ""); err != nil {
+		t.Errorf("verify gate after resolving: %v", err)
+	}
+}
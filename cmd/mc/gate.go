@@ -9,6 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
 	"github.com/spf13/cobra"
 )
 
@@ -20,7 +23,11 @@ type GateCriterion struct {
 }
 
 type StageGate struct {
-	Criteria []GateCriterion `json:"criteria"`
+	Criteria     []GateCriterion `json:"criteria"`
+	Status       string          `json:"status,omitempty"`
+	ApprovedAt   string          `json:"approved_at,omitempty"`
+	ApprovalNote string          `json:"approval_note,omitempty"`
+	ApprovedBy   string          `json:"approved_by,omitempty"`
 }
 
 type GatesFile struct {
@@ -41,9 +48,12 @@ func loadGates(missionDir string) (GatesFile, error) {
 		// Try legacy format where criteria are plain strings
 		var legacy struct {
 			Gates map[string]struct {
-				Stage    string   `json:"stage"`
-				Status   string   `json:"status"`
-				Criteria []string `json:"criteria"`
+				Stage        string   `json:"stage"`
+				Status       string   `json:"status"`
+				Criteria     []string `json:"criteria"`
+				ApprovedAt   string   `json:"approved_at"`
+				ApprovalNote string   `json:"approval_note"`
+				ApprovedBy   string   `json:"approved_by"`
 			} `json:"gates"`
 		}
 		if err2 := json.Unmarshal(data, &legacy); err2 != nil {
@@ -55,7 +65,13 @@ func loadGates(missionDir string) (GatesFile, error) {
 			for _, c := range sg.Criteria {
 				criteria = append(criteria, GateCriterion{Description: c, Satisfied: false})
 			}
-			gf.Gates[name] = StageGate{Criteria: criteria}
+			gf.Gates[name] = StageGate{
+				Criteria:     criteria,
+				Status:       sg.Status,
+				ApprovedAt:   sg.ApprovedAt,
+				ApprovalNote: sg.ApprovalNote,
+				ApprovedBy:   sg.ApprovedBy,
+			}
 		}
 	}
 	if gf.Gates == nil {
@@ -169,6 +185,7 @@ func init() {
 	gateCmd.AddCommand(gateSatisfyCmd)
 	gateCmd.AddCommand(gateStatusCmd)
 	gateApproveCmd.Flags().String("note", "", "Reason for approving this gate (required)")
+	gateApproveCmd.Flags().String("override-findings", "", "Approve verify/validate despite open critical/high findings, with this justification (logged to the audit trail)")
 	gateSatisfyCmd.Flags().Bool("all", false, "Satisfy all criteria at once")
 }
 
@@ -254,7 +271,7 @@ func runGateCheck(cmd *cobra.Command, args []string) error {
 	// Calculate task summary for this stage
 	var summary TasksSummary
 	for _, task := range tasks {
-		if task.Stage == stage {
+		if task.Stage == stage && task.Status != bridge.TaskStatusArchived {
 			summary.Total++
 			switch task.Status {
 			case "complete":
@@ -278,6 +295,16 @@ func runGateCheck(cmd *cobra.Command, args []string) error {
 		})
 	}
 
+	// Open critical/high reviewer and security findings hold verify and
+	// validate
+	if findingGateStages[stage] {
+		blocking, _ := blockingFindings(missionDir)
+		criteria = append(criteria, CriterionStatus{
+			Name: fmt.Sprintf("No open critical/high findings (%d open)", len(blocking)),
+			Met:  len(blocking) == 0,
+		})
+	}
+
 	// Overall ready check
 	ready := true
 	for _, c := range criteria {
@@ -317,15 +344,16 @@ func runGateApproveWithNote(stage, note string) error {
 		return err
 	}
 
-	return doGateApprove(missionDir, stage, note)
+	return doGateApprove(missionDir, stage, note, "")
 }
 
 func runGateApprove(cmd *cobra.Command, args []string) error {
 	stage := args[0]
 
-	var note string
+	var note, override string
 	if cmd != nil {
 		note, _ = cmd.Flags().GetString("note")
+		override, _ = cmd.Flags().GetString("override-findings")
 	}
 	note = strings.TrimSpace(note)
 	if note == "" {
@@ -341,10 +369,12 @@ func runGateApprove(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return doGateApprove(missionDir, stage, note)
+	return doGateApprove(missionDir, stage, note, override)
 }
 
-func doGateApprove(missionDir, stage, note string) error {
+// doGateApprove approves stage's gate. override justifies approving the
+// verify or validate gate despite blocking findings.
+func doGateApprove(missionDir, stage, note, override string) error {
 	if err := requireV6(missionDir); err != nil {
 		return err
 	}
@@ -389,9 +419,15 @@ func doGateApprove(missionDir, stage, note string) error {
 		return fmt.Errorf("gate for %q is already approved", stage)
 	}
 
+	if err := checkFindingsGate(missionDir, stage, override); err != nil {
+		return err
+	}
+
+	approver := identity.Current().String()
 	gate.Status = "approved"
 	gate.ApprovedAt = time.Now().UTC().Format(time.RFC3339)
 	gate.ApprovalNote = note
+	gate.ApprovedBy = approver
 	gatesState.Gates[stage] = gate
 
 	if err := writeJSON(gatesPath, gatesState); err != nil {
@@ -402,6 +438,7 @@ func doGateApprove(missionDir, stage, note string) error {
 		"stage": stage,
 		"note":  note,
 	})
+	recordEvent(missionDir, eventstore.GateApproved, eventstore.GateData{Stage: stage, ApprovedBy: approver, Note: note})
 
 	// Auto-commit gate approval
 	gitAutoCommit(missionDir, CommitCategoryGate, fmt.Sprintf("approve %s", stage))
@@ -414,7 +451,7 @@ func doGateApprove(missionDir, stage, note string) error {
 	// Transition to next stage — only ONE stage forward
 	nextStage, err := getNextStage(stage)
 	if err != nil {
-		fmt.Printf("Gate approved: %s (final stage)\n", stage)
+		fmt.Printf("Gate approved: %s (final stage)%s\n", stage, approvedBy(approver))
 		return nil
 	}
 
@@ -431,14 +468,23 @@ func doGateApprove(missionDir, stage, note string) error {
 		"from_stage": stage,
 		"to_stage":   nextStage,
 	})
+	recordEvent(missionDir, eventstore.StageChanged, eventstore.StageData{From: stage, To: nextStage})
 
 	gitAutoCommit(missionDir, CommitCategoryStage, fmt.Sprintf("advance %s → %s (gate approved)", stage, nextStage))
 
-	fmt.Printf("Gate approved: %s → %s\n", stage, nextStage)
+	fmt.Printf("Gate approved: %s → %s%s\n", stage, nextStage, approvedBy(approver))
 
 	return nil
 }
 
+// approvedBy formats a gate approver for display.
+func approvedBy(approver string) string {
+	if approver == "" {
+		return ""
+	}
+	return " by " + approver
+}
+
 var gateSatisfyCmd = &cobra.Command{
 	Use:   "satisfy [substring]",
 	Short: "Satisfy a gate criterion by substring match",
@@ -521,6 +567,9 @@ var gateStatusCmd = &cobra.Command{
 			fmt.Printf("  %s %s\n", mark, c.Description)
 		}
 		fmt.Printf("\nStatus: %d/%d criteria met\n", satisfied, total)
+		if sg.Status == "approved" {
+			fmt.Printf("Approved%s at %s\n", approvedBy(sg.ApprovedBy), sg.ApprovedAt)
+		}
 		fmt.Println("────────────────────────────────────────")
 		return nil
 	},
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MikeSquared-Agency/MissionControl/commands"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	gateCmd.AddCommand(gateConfirmCmd)
+}
+
+var gateConfirmCmd = &cobra.Command{
+	Use:   "confirm [request-id]",
+	Short: "Confirm a gate approval the King requested",
+	Long: `Approves the gate a pending King request names, with the King's note and
+in your name, exactly as mc gate approve would. Without a request ID,
+lists the pending requests.
+
+Example:
+  mc gate confirm approve-design`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGateConfirm,
+}
+
+func runGateConfirm(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		pending, err := commands.Pending(missionDir)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Println("No gate approvals waiting for confirmation")
+			return nil
+		}
+		for _, a := range pending {
+			fmt.Printf("%s  %s gate: %s\n", a.ID, a.Stage, a.Note)
+		}
+		return nil
+	}
+	return confirmApproval(missionDir, args[0])
+}
+
+// confirmApproval approves the gate of the pending request id and marks
+// the request confirmed.
+func confirmApproval(missionDir, id string) error {
+	a, err := commands.FindApproval(missionDir, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", id, err)
+	}
+	if a.Status != commands.ApprovalPending {
+		return fmt.Errorf("%s: %w (%s)", id, commands.ErrApprovalResolved, a.Status)
+	}
+	if err := doGateApprove(missionDir, a.Stage, a.Note, ""); err != nil {
+		return err
+	}
+	_, err = commands.ResolveApproval(missionDir, id, commands.ApprovalConfirmed, identity.Current().String())
+	return err
+}
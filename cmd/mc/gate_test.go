@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/commands"
 )
 
 // helper to create a test GatesFile with sample criteria
@@ -354,6 +356,7 @@ func TestGateApprove_WithNote(t *testing.T) {
 	missionDir := filepath.Join(tmpDir, ".mission")
 	addTask(t, missionDir, Task{ID: "t1", Name: "work", Stage: "discovery", Status: "pending", Persona: "dev", CreatedAt: "2026-01-01T00:00:00Z", UpdatedAt: "2026-01-01T00:00:00Z"})
 	completeTask(t, missionDir, "t1")
+	t.Setenv("MC_USER", "Alice <alice@example.com>")
 
 	// Approve with note should succeed
 	err := runGateApproveWithNote("discovery", "Reviewed findings, no issues found")
@@ -371,6 +374,20 @@ func TestGateApprove_WithNote(t *testing.T) {
 	if gate.ApprovalNote != "Reviewed findings, no issues found" {
 		t.Errorf("expected approval note stored, got: %q", gate.ApprovalNote)
 	}
+	if gate.ApprovedBy != "Alice <alice@example.com>" {
+		t.Errorf("expected approver stored, got: %q", gate.ApprovedBy)
+	}
+
+	entries, _ := readAuditLog(missionDir)
+	var approver string
+	for _, e := range entries {
+		if e.Action == AuditGateApproved {
+			approver = e.User
+		}
+	}
+	if approver != "Alice <alice@example.com>" {
+		t.Errorf("expected gate_approved attributed to alice, got: %q", approver)
+	}
 }
 
 func TestGateApprove_EmptyNoteRejected(t *testing.T) {
@@ -487,3 +504,34 @@ func TestGateApprove_ReApprovalRejected(t *testing.T) {
 		t.Fatal("expected error on re-approval")
 	}
 }
+
+func TestGateConfirm(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("mc init failed: %v", err)
+	}
+	missionDir := filepath.Join(tmpDir, ".mission")
+	addTask(t, missionDir, Task{ID: "t1", Name: "work", Stage: "discovery", Status: "pending", Persona: "dev", CreatedAt: "2026-01-01T00:00:00Z", UpdatedAt: "2026-01-01T00:00:00Z"})
+	completeTask(t, missionDir, "t1")
+	t.Setenv("MC_USER", "Alice <alice@example.com>")
+	if _, err := commands.RequestApproval(missionDir, "req-1", "discovery", "Findings reviewed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := confirmApproval(missionDir, "req-1"); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	var gatesState GatesState
+	readJSON(filepath.Join(missionDir, "state", "gates.json"), &gatesState)
+	gate := gatesState.Gates["discovery"]
+	if gate.Status != "approved" || gate.ApprovalNote != "Findings reviewed" || gate.ApprovedBy != "Alice <alice@example.com>" {
+		t.Errorf("gate = %+v", gate)
+	}
+	if err := confirmApproval(missionDir, "req-1"); err == nil {
+		t.Error("confirming twice should fail")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MikeSquared-Agency/MissionControl/api"
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.AddCommand(graphExportCmd)
+	graphExportCmd.Flags().StringP("format", "f", api.GraphFormatMermaid, "Output format: mermaid, dot")
+	graphExportCmd.Flags().StringP("output", "o", "", "Write the diagram to file instead of stdout")
+	graphExportCmd.Flags().StringSliceP("label", "l", nil, "Only include tasks with all of these labels")
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Work with the task dependency graph",
+}
+
+var graphExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the task graph as a Mermaid or Graphviz diagram",
+	Long: `Renders tasks grouped by stage, their dependencies, status colors and
+the critical path as a Mermaid flowchart or a Graphviz digraph, the same as
+GET /api/graph/export.
+
+Examples:
+  mc graph export > tasks.mmd                    # Mermaid, for Markdown docs
+  mc graph export -f dot | dot -Tsvg > tasks.svg # Graphviz
+  mc graph export -l security                    # only tasks labelled security`,
+	RunE: runGraphExport,
+}
+
+func runGraphExport(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	labelFlags, _ := cmd.Flags().GetStringSlice("label")
+	labels, err := bridge.NormalizeLabels(labelFlags)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		return err
+	}
+
+	content, err := api.ExportGraph(api.BuildGraph(tasks, zones).WithLabels(labels), format)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+
+	if output == "" {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write diagram: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Diagram written to %s\n", output)
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGraphExport(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	if err := saveTasks(missionDir, []Task{
+		{ID: "a", Name: "Design", Stage: "design", Status: "done"},
+		{ID: "b", Name: "Build", Stage: "implement", Status: "pending", DependsOn: []string{"a"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "export", RunE: runGraphExport}
+		cmd.Flags().StringP("format", "f", "mermaid", "")
+		cmd.Flags().StringP("output", "o", "", "")
+		return cmd
+	}
+
+	out := filepath.Join(tmpDir, "tasks.dot")
+	cmd := newCmd()
+	cmd.Flags().Set("format", "dot")
+	cmd.Flags().Set("output", out)
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.Contains(string(data), `"a" -> "b";`) || !strings.Contains(string(data), `label="implement";`) {
+		t.Errorf("dot output:\n%s", data)
+	}
+
+	cmd = newCmd()
+	cmd.Flags().Set("format", "svg")
+	if err := cmd.RunE(cmd, nil); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
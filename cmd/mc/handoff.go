@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,30 +12,52 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var useRustValidation bool
+var (
+	useRustValidation  bool
+	handoffInteractive bool
+	handoffTemplateFor string
+	handoffTaskID      string
+	handoffWorkerID    string
+)
 
 func init() {
 	handoffCmd.Flags().BoolVar(&useRustValidation, "rust", false, "Use mc-core (Rust) for validation")
+	handoffCmd.Flags().BoolVarP(&handoffInteractive, "interactive", "i", false, "Prompt for the handoff field by field")
+	handoffCmd.Flags().StringVar(&handoffTemplateFor, "template", "", "Print a handoff skeleton for this persona instead of storing one")
+	handoffCmd.Flags().StringVar(&handoffTaskID, "task", "", "Task ID to fill in (--template) or offer (--interactive)")
+	handoffCmd.Flags().StringVar(&handoffWorkerID, "worker", "", "Worker ID to fill in (--template) or offer (--interactive)")
 	rootCmd.AddCommand(handoffCmd)
 }
 
 var handoffCmd = &cobra.Command{
-	Use:   "handoff <file>",
+	Use:   "handoff [file|-]",
 	Short: "Validate and store a worker handoff",
-	Long: `Validates a handoff JSON file and stores it in .mission/.
+	Long: `Validates a handoff and stores it in .mission/. The handoff is read from
+the file given, from stdin when the file is - or omitted, or asked for
+field by field with --interactive.
 
-The handoff file should contain:
+The handoff should contain:
   - task_id: ID of the task
   - worker_id: ID of the worker
-  - status: "complete" or "blocked"
-  - findings: Array of findings
+  - status: "complete", "blocked" or "in_progress"
+  - findings: Array of findings, each with type, summary and optionally severity
   - artifacts: Array of file paths
   - open_questions: Array of unresolved questions
 
-Example:
-  mc handoff findings.json`,
-	Args: cobra.ExactArgs(1),
+Every schema error is reported at once with its line and column.
+--template prints the skeleton from a persona's prompt to start from.
+
+Examples:
+  mc handoff findings.json
+  cat findings.json | mc handoff
+  mc handoff --interactive --task abc123
+  mc handoff --template developer --task abc123 --worker w1 > findings.json`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runHandoff,
+	// A refused handoff prints just the errors, which the server's handoff
+	// inbox keeps as the rejection report
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 type Handoff struct {
@@ -50,38 +73,92 @@ type Finding struct {
 	Type     string `json:"type"`
 	Summary  string `json:"summary"`
 	Severity string `json:"severity,omitempty"`
+	Resolved string `json:"resolved,omitempty"` // how it was resolved; empty while open
 }
 
 func runHandoff(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
+	var in io.Reader = os.Stdin
+	var out io.Writer = os.Stdout
+	if cmd != nil {
+		in, out = cmd.InOrStdin(), cmd.OutOrStdout()
+	}
+
+	if handoffTemplateFor != "" {
+		missionDir, _ := findMissionDir()
+		skeleton, err := handoffTemplate(missionDir, handoffTemplateFor, handoffTaskID, handoffWorkerID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, skeleton)
+		return nil
+	}
 
 	missionDir, err := findMissionDir()
 	if err != nil {
 		return err
 	}
 
+	var data []byte
+	filePath := ""
+	switch {
+	case handoffInteractive:
+		if len(args) > 0 {
+			return fmt.Errorf("--interactive takes no file")
+		}
+		data, err = promptHandoff(in, out, handoffTaskID, handoffWorkerID)
+	case len(args) == 0 || args[0] == "-":
+		data, err = readHandoffStdin(in)
+	default:
+		filePath = args[0]
+		data, err = os.ReadFile(filePath)
+		if err != nil {
+			err = fmt.Errorf("failed to read handoff file: %w", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
 	// Use Rust validation if requested
 	if useRustValidation {
-		if err := validateHandoffWithRust(filePath); err != nil {
+		if err := validateHandoffWithRust(filePath, data); err != nil {
 			return err
 		}
 	}
 
-	// Read handoff file
-	data, err := os.ReadFile(filePath)
+	handoff, handoffPath, err := storeHandoff(missionDir, data)
 	if err != nil {
-		return fmt.Errorf("failed to read handoff file: %w", err)
+		return err
 	}
 
-	// Parse and validate
+	fmt.Printf("Handoff stored: %s\n", handoffPath)
+	fmt.Printf("Findings updated: %s\n", filepath.Join(missionDir, "findings", handoff.TaskID+".json"))
+
+	return nil
+}
+
+// storeHandoff validates a handoff, stores it and its findings, and
+// updates the task and worker status. It returns the stored path.
+func storeHandoff(missionDir string, data []byte) (*Handoff, string, error) {
+	// Validate against the schema, then parse
+	warnings, err := checkHandoff(data)
+	if err != nil {
+		// A worker under a prompt experiment has the rejection counted
+		// against its variant, if the handoff says who it is
+		var from struct {
+			WorkerID string `json:"worker_id"`
+		}
+		if json.Unmarshal(data, &from) == nil {
+			recordExperimentHandoff(missionDir, from.WorkerID, false)
+		}
+		return nil, "", err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
 	var handoff Handoff
 	if err := json.Unmarshal(data, &handoff); err != nil {
-		return fmt.Errorf("invalid JSON: %w", err)
-	}
-
-	// Validate required fields (basic validation always runs)
-	if err := validateHandoff(&handoff); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+		return nil, "", fmt.Errorf("invalid JSON: %w", err)
 	}
 
 	// Store raw handoff
@@ -90,7 +167,7 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 	handoffPath := filepath.Join(missionDir, "handoffs", handoffFileName)
 
 	if err := os.WriteFile(handoffPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to store handoff: %w", err)
+		return nil, "", fmt.Errorf("failed to store handoff: %w", err)
 	}
 
 	// Store compressed findings (keyed by task)
@@ -108,25 +185,13 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 
 		findingsData, _ := json.MarshalIndent(existingFindings, "", "  ")
 		if err := os.WriteFile(findingsPath, findingsData, 0644); err != nil {
-			return fmt.Errorf("failed to store findings: %w", err)
+			return nil, "", fmt.Errorf("failed to store findings: %w", err)
 		}
 	}
 
 	// Update task status
 	if handoff.TaskID != "" {
-		tasks, loadErr := loadTasks(missionDir)
-		if loadErr == nil {
-			for i := range tasks {
-				if tasks[i].ID == handoff.TaskID {
-					tasks[i].Status = handoff.Status
-					tasks[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-					break
-				}
-			}
-			if saveErr := saveTasks(missionDir, tasks); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to save task status update for %s: %v\n", handoff.TaskID, saveErr)
-			}
-		}
+		setTaskStatus(missionDir, handoff.TaskID, handoff.Status)
 
 		// Create status file for protocol completion detection
 		// This signals to the orchestrator that the task is complete
@@ -154,6 +219,8 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	recordExperimentHandoff(missionDir, handoff.WorkerID, true)
+
 	writeAuditLog(missionDir, AuditHandoffReceived, "worker", map[string]interface{}{
 		"task_id":   handoff.TaskID,
 		"worker_id": handoff.WorkerID,
@@ -171,42 +238,40 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 	// Auto-commit handoff
 	gitAutoCommit(missionDir, CommitCategoryHandoff, fmt.Sprintf("worker %s task %s (%s)", shortID(handoff.WorkerID), shortID(handoff.TaskID), handoff.Status))
 
-	fmt.Printf("Handoff stored: %s\n", handoffPath)
-	fmt.Printf("Findings updated: %s\n", filepath.Join(missionDir, "findings", handoff.TaskID+".json"))
-
-	return nil
-}
-
-func validateHandoff(h *Handoff) error {
-	if h.Status == "" {
-		return fmt.Errorf("status is required")
-	}
-
-	validStatuses := map[string]bool{"complete": true, "blocked": true, "in_progress": true}
-	if !validStatuses[h.Status] {
-		return fmt.Errorf("invalid status: %s (valid: complete, blocked, in_progress)", h.Status)
-	}
-
-	// Findings should have type and summary
-	for i, f := range h.Findings {
-		if f.Type == "" {
-			return fmt.Errorf("finding %d: type is required", i)
-		}
-		if f.Summary == "" {
-			return fmt.Errorf("finding %d: summary is required", i)
+	// A finished worker frees its slot
+	if handoff.Status != "in_progress" {
+		if handoff.WorkerID != "" {
+			trimWorkerLog(missionDir, handoff.WorkerID)
 		}
+		dispatchQueuedWorkers(missionDir)
 	}
 
-	return nil
+	return &handoff, handoffPath, nil
 }
 
-// validateHandoffWithRust uses mc-core for enhanced validation
-func validateHandoffWithRust(filePath string) error {
+// validateHandoffWithRust uses mc-core for enhanced validation. A handoff
+// that didn't come from a file is written to a temporary one for it.
+func validateHandoffWithRust(filePath string, data []byte) error {
 	// Try to find mc-core binary
 	mcCorePath := findMcCore()
 	if mcCorePath == "" {
 		return fmt.Errorf("mc-core binary not found (hint: build with 'cargo build -p mc-core --release')")
 	}
+	if filePath == "" {
+		tmp, err := os.CreateTemp("", "mc-handoff-*.json")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.Write(data)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		filePath = tmp.Name()
+	}
 
 	cmd := exec.Command(mcCorePath, "validate-handoff", filePath)
 	output, err := cmd.CombinedOutput()
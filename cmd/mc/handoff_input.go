@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// readHandoffStdin reads a handoff piped to mc handoff. A terminal on
+// stdin means nothing was piped, which is reported rather than waited on.
+func readHandoffStdin(in io.Reader) ([]byte, error) {
+	if f, ok := in.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return nil, fmt.Errorf("no handoff given: pass a file, pipe JSON on stdin, or use --interactive")
+		}
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read handoff from stdin: %w", err)
+	}
+	return data, nil
+}
+
+// promptHandoff asks for a handoff field by field, asking again while an
+// answer is invalid, and returns it as JSON. taskID and workerID are the
+// defaults offered.
+func promptHandoff(in io.Reader, out io.Writer, taskID, workerID string) ([]byte, error) {
+	p := &prompter{in: bufio.NewReader(in), out: out}
+	h := Handoff{Findings: []Finding{}, Artifacts: []string{}, OpenQuestions: []string{}}
+
+	h.TaskID = p.ask("Task ID", taskID, required)
+	h.WorkerID = p.ask("Worker ID", workerID, required)
+	h.Status = p.ask("Status ("+strings.Join(handoffStatuses, "/")+")", "complete", oneOf(handoffStatuses))
+
+	fmt.Fprintln(out, "Findings (blank type to finish):")
+	for p.err == nil {
+		typ := p.ask("  Type", "", nil)
+		if typ == "" {
+			break
+		}
+		f := Finding{Type: typ}
+		f.Summary = p.ask("  Summary", "", required)
+		f.Severity = p.ask("  Severity ("+strings.Join(severityChoices(), "/")+", blank for none)", "", optional(oneOf(severityChoices())))
+		h.Findings = append(h.Findings, f)
+	}
+	h.Artifacts = p.list("Artifacts (one path per line, blank to finish)")
+	h.OpenQuestions = p.list("Open questions (blank to finish)")
+	if p.err != nil {
+		return nil, p.err
+	}
+	return json.MarshalIndent(h, "", "  ")
+}
+
+// severityChoices are the severities a person may give a finding.
+func severityChoices() []string {
+	return []string{"critical", "high", "medium", "low", "info"}
+}
+
+// prompter reads answers line by line. The first read error (including
+// input ending early) stops every later question.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	err error
+}
+
+func (p *prompter) line() string {
+	if p.err != nil {
+		return ""
+	}
+	s, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || s == "") {
+		if err == io.EOF {
+			err = fmt.Errorf("input ended before the handoff was complete")
+		}
+		p.err = err
+	}
+	return strings.TrimSpace(s)
+}
+
+// ask prompts for one value, offering def, until check accepts it.
+func (p *prompter) ask(label, def string, check func(string) error) string {
+	for p.err == nil {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+		v := p.line()
+		if v == "" {
+			v = def
+		}
+		if p.err != nil {
+			return ""
+		}
+		if check == nil {
+			return v
+		}
+		if err := check(v); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return v
+	}
+	return ""
+}
+
+func (p *prompter) list(label string) []string {
+	fmt.Fprintf(p.out, "%s:\n", label)
+	items := []string{}
+	for p.err == nil {
+		fmt.Fprint(p.out, "  > ")
+		v := p.line()
+		if v == "" {
+			break
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+func required(v string) error {
+	if v == "" {
+		return fmt.Errorf("a value is required")
+	}
+	return nil
+}
+
+func oneOf(choices []string) func(string) error {
+	return func(v string) error {
+		if !hasString(choices, v) {
+			return fmt.Errorf("choose one of: %s", strings.Join(choices, ", "))
+		}
+		return nil
+	}
+}
+
+func optional(check func(string) error) func(string) error {
+	return func(v string) error {
+		if v == "" {
+			return nil
+		}
+		return check(v)
+	}
+}
+
+// handoffTemplate returns the handoff skeleton from a persona's prompt,
+// with the task and worker IDs filled in when given. The project's copy
+// in .mission/prompts wins over the built-in one.
+func handoffTemplate(missionDir, persona, taskID, workerID string) (string, error) {
+	prompt, ok := workerPrompts[persona]
+	if missionDir != "" && !strings.ContainsAny(persona, `/\`) {
+		if data, err := os.ReadFile(filepath.Join(missionDir, "prompts", persona+".md")); err == nil {
+			prompt, ok = string(data), true
+		}
+	}
+	if !ok {
+		var personas []string
+		for name := range workerPrompts {
+			personas = append(personas, name)
+		}
+		sort.Strings(personas)
+		return "", fmt.Errorf("unknown persona %q (known: %s)", persona, strings.Join(personas, ", "))
+	}
+
+	const startMark, endMark = "```json\n", "\n```"
+	start := strings.Index(prompt, startMark)
+	if start < 0 {
+		return "", fmt.Errorf("the %s prompt has no handoff JSON block", persona)
+	}
+	body := prompt[start+len(startMark):]
+	end := strings.Index(body, endMark)
+	if end < 0 {
+		return "", fmt.Errorf("the %s prompt's handoff JSON block is not closed", persona)
+	}
+	skeleton := body[:end]
+	if taskID != "" {
+		skeleton = strings.ReplaceAll(skeleton, "{{task_id}}", taskID)
+	}
+	if workerID != "" {
+		skeleton = strings.ReplaceAll(skeleton, "{{worker_id}}", workerID)
+	}
+	return skeleton + "\n", nil
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/report"
+)
+
+// handoffStatuses are the statuses a handoff may report.
+var handoffStatuses = []string{"complete", "blocked", "in_progress"}
+
+// handoffFields are the top-level fields of a handoff. Others are
+// reported as warnings, since they are most often a misspelling.
+var handoffFields = []string{"task_id", "worker_id", "status", "findings", "artifacts", "open_questions"}
+
+// findingFields are the fields of a finding.
+var findingFields = []string{"type", "summary", "severity", "resolved"}
+
+// handoffProblem is a schema violation at a place in the handoff JSON.
+type handoffProblem struct {
+	Line, Col int
+	Path      string // e.g. findings[1].summary; empty for the document
+	Msg       string
+}
+
+func (p handoffProblem) String() string {
+	where := fmt.Sprintf("line %d, col %d", p.Line, p.Col)
+	if p.Path != "" {
+		where += ": " + p.Path
+	}
+	return where + ": " + p.Msg
+}
+
+// handoffErrors is every schema violation found in a handoff.
+type handoffErrors []handoffProblem
+
+func (e handoffErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, p := range e {
+		lines[i] = p.String()
+	}
+	return "validation failed:\n  - " + strings.Join(lines, "\n  - ")
+}
+
+// checkHandoff validates handoff JSON against the handoff schema. It
+// returns every violation at once, each with its line and column, and
+// warnings for fields the schema doesn't know.
+func checkHandoff(data []byte) (warnings []handoffProblem, err error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line, col := lineCol(data, syntax.Offset-1)
+			return nil, fmt.Errorf("invalid JSON at line %d, col %d: %s", line, col, syntax.Error())
+		}
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	c := &schemaCheck{data: data, locs: jsonLocations(data)}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		c.fail("", "a handoff must be a JSON object")
+		return nil, c.errs
+	}
+	c.unknown("", root, handoffFields)
+	c.str(root, "", "task_id", true)
+	c.str(root, "", "worker_id", true)
+	if status, ok := c.str(root, "", "status", true); ok && status != "" && !hasString(handoffStatuses, status) {
+		c.fail("status", fmt.Sprintf("%q is not a status (valid: %s)", status, strings.Join(handoffStatuses, ", ")))
+	}
+	for i, item := range c.list(root, "", "findings") {
+		path := fmt.Sprintf("findings[%d]", i)
+		f, ok := item.(map[string]interface{})
+		if !ok {
+			c.fail(path, "a finding must be an object with type and summary")
+			continue
+		}
+		c.unknown(path, f, findingFields)
+		c.str(f, path, "type", true)
+		c.str(f, path, "summary", true)
+		if sev, ok := c.str(f, path, "severity", false); ok && sev != "" && !hasString(report.SeverityOrder, strings.ToLower(strings.TrimSpace(sev))) {
+			c.fail(path+".severity", fmt.Sprintf("%q is not a severity (valid: %s)", sev, strings.Join(report.SeverityOrder, ", ")))
+		}
+		c.str(f, path, "resolved", false)
+	}
+	for _, key := range []string{"artifacts", "open_questions"} {
+		for i, item := range c.list(root, "", key) {
+			if _, ok := item.(string); !ok {
+				c.fail(fmt.Sprintf("%s[%d]", key, i), "must be a string")
+			}
+		}
+	}
+
+	if len(c.errs) > 0 {
+		return c.warnings, c.errs
+	}
+	return c.warnings, nil
+}
+
+// schemaCheck collects the violations found in one document.
+type schemaCheck struct {
+	data     []byte
+	locs     map[string]int64
+	errs     handoffErrors
+	warnings []handoffProblem
+}
+
+// at returns a problem located at path, or at the nearest enclosing value
+// that is in the document when path isn't (a missing field).
+func (c *schemaCheck) at(path, msg string) handoffProblem {
+	loc := path
+	for {
+		if off, ok := c.locs[loc]; ok {
+			line, col := lineCol(c.data, off)
+			return handoffProblem{Line: line, Col: col, Path: path, Msg: msg}
+		}
+		if loc == "" {
+			return handoffProblem{Line: 1, Col: 1, Path: path, Msg: msg}
+		}
+		loc = parentPath(loc)
+	}
+}
+
+func (c *schemaCheck) fail(path, msg string) {
+	c.errs = append(c.errs, c.at(path, msg))
+}
+
+// str checks that obj's key is a string and, if required, a non-empty
+// one.
+func (c *schemaCheck) str(obj map[string]interface{}, path, key string, required bool) (string, bool) {
+	path = joinPath(path, key)
+	v, ok := obj[key]
+	if !ok || v == nil {
+		if required {
+			c.fail(path, "is required")
+		}
+		return "", false
+	}
+	s, ok := v.(string)
+	if !ok {
+		c.fail(path, "must be a string")
+		return "", false
+	}
+	if required && strings.TrimSpace(s) == "" {
+		c.fail(path, "must not be empty")
+	}
+	return s, true
+}
+
+// list checks that obj's key, if present, is an array and returns it.
+func (c *schemaCheck) list(obj map[string]interface{}, path, key string) []interface{} {
+	v, ok := obj[key]
+	if !ok || v == nil {
+		return nil
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		c.fail(joinPath(path, key), "must be an array")
+	}
+	return items
+}
+
+func (c *schemaCheck) unknown(path string, obj map[string]interface{}, known []string) {
+	var keys []string
+	for k := range obj {
+		if !hasString(known, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c.warnings = append(c.warnings, c.at(joinPath(path, k), "unknown field (known: "+strings.Join(known, ", ")+")"))
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func hasString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLocations maps the path of every value in valid JSON data to the
+// offset it starts at; an object member maps to the offset of its key.
+func jsonLocations(data []byte) map[string]int64 {
+	locs := map[string]int64{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var walk func(path string) error
+	walk = func(path string) error {
+		locs[path] = skipJSONSpace(data, dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				keyAt := skipJSONSpace(data, dec.InputOffset())
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				member := joinPath(path, fmt.Sprint(key))
+				if err := walk(member); err != nil {
+					return err
+				}
+				locs[member] = keyAt
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	_ = walk("")
+	return locs
+}
+
+// skipJSONSpace moves off past whitespace and the separators the decoder
+// leaves before a value.
+func skipJSONSpace(data []byte, off int64) int64 {
+	for off < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// lineCol turns a byte offset into a 1-based line and column.
+func lineCol(data []byte, off int64) (int, int) {
+	if off < 0 {
+		off = 0
+	}
+	if off > int64(len(data)) {
+		off = int64(len(data))
+	}
+	before := data[:off]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCheckHandoffLocations(t *testing.T) {
+	data := []byte(`{
+  "task_id": "t1",
+  "worker_id": "w1",
+  "status": "done",
+  "findings": [
+    {"type": "discovery", "summary": "ok"},
+    {"type": "issue", "severity": "urgent"}
+  ],
+  "artifacts": ["a.go", 3],
+  "finding": []
+}`)
+	warnings, err := checkHandoff(data)
+	if err == nil {
+		t.Fatal("expected the handoff rejected")
+	}
+	for _, want := range []string{
+		`line 4, col 3: status: "done" is not a status`,
+		"line 7, col 5: findings[1].summary: is required",
+		`line 7, col 23: findings[1].severity: "urgent" is not a severity`,
+		"line 9, col 25: artifacts[1]: must be a string",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+	if len(warnings) != 1 || warnings[0].Path != "finding" || warnings[0].Line != 10 {
+		t.Errorf("expected a warning for the unknown field, got %v", warnings)
+	}
+
+	_, err = checkHandoff([]byte("{\n  \"task_id\": \"t1\",\n  \"status\" \"complete\"\n}"))
+	if err == nil || !strings.Contains(err.Error(), "line 3, col 12") {
+		t.Errorf("expected the syntax error located, got %v", err)
+	}
+}
+
+// runHandoffCmd runs mc handoff with stdin and flags set, resetting the
+// flags afterwards.
+func runHandoffCmd(t *testing.T, stdin string, args []string, set func()) (string, error) {
+	t.Helper()
+	set()
+	defer func() {
+		handoffInteractive, handoffTemplateFor, handoffTaskID, handoffWorkerID = false, "", "", ""
+	}()
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	err := runHandoff(cmd, args)
+	return out.String(), err
+}
+
+func storedHandoffs(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, _ := os.ReadDir(filepath.Join(dir, ".mission", "handoffs"))
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestHandoffFromStdin(t *testing.T) {
+	dir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+
+	if _, err := runHandoffCmd(t, `{"task_id":"t1","worker_id":"w1","status":"complete"}`, nil, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedHandoffs(t, dir); len(got) != 1 {
+		t.Fatalf("expected one stored handoff, got %v", got)
+	}
+	if _, err := runHandoffCmd(t, `{"task_id":"t1"}`, []string{"-"}, func() {}); err == nil || !strings.Contains(err.Error(), "worker_id: is required") {
+		t.Errorf("expected the missing worker reported, got %v", err)
+	}
+}
+
+func TestHandoffInteractive(t *testing.T) {
+	dir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+
+	// Task ID from --task, a worker, a wrong status then the default, one
+	// finding with a bad severity corrected, an artifact, no questions
+	input := strings.Join([]string{"", "w1", "finished", "", "decision", "Use REST", "urgent", "high", "", "api.md", "", ""}, "\n") + "\n"
+	out, err := runHandoffCmd(t, input, nil, func() { handoffInteractive, handoffTaskID = true, "t1" })
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if !strings.Contains(out, "choose one of: complete, blocked, in_progress") {
+		t.Errorf("expected the bad status reprompted, got:\n%s", out)
+	}
+	var findings []Finding
+	if err := readJSON(filepath.Join(dir, ".mission", "findings", "t1.json"), &findings); err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Summary != "Use REST" || findings[0].Severity != "high" {
+		t.Errorf("unexpected findings %+v", findings)
+	}
+
+	// Input ending early stores nothing
+	if _, err := runHandoffCmd(t, "t2\n", nil, func() { handoffInteractive = true }); err == nil {
+		t.Error("expected an error when input ends early")
+	}
+	if got := storedHandoffs(t, dir); len(got) != 1 {
+		t.Errorf("expected only the first handoff stored, got %v", got)
+	}
+}
+
+func TestHandoffTemplate(t *testing.T) {
+	for persona := range workerPrompts {
+		skeleton, err := handoffTemplate("", persona, "t1", "w1")
+		if err != nil {
+			t.Errorf("%s: %v", persona, err)
+			continue
+		}
+		// Severity choices like "high|medium|low" are left to pick from
+		if !json.Valid([]byte(skeleton)) || !strings.Contains(skeleton, `"task_id": "t1"`) {
+			t.Errorf("%s: unexpected skeleton\n%s", persona, skeleton)
+		}
+	}
+
+	out, err := runHandoffCmd(t, "", nil, func() { handoffTemplateFor, handoffTaskID = "developer", "abc" })
+	if err != nil || !strings.Contains(out, `"task_id": "abc"`) || !strings.Contains(out, "{{worker_id}}") {
+		t.Errorf("expected the task filled in and the worker left, got %v:\n%s", err, out)
+	}
+	if _, err := handoffTemplate("", "juggler", "", ""); err == nil || !strings.Contains(err.Error(), "known: analyst") {
+		t.Errorf("expected the personas listed, got %v", err)
+	}
+}
@@ -6,10 +6,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/MikeSquared-Agency/MissionControl/workspace"
 )
 
 // CLI flags for init command
@@ -19,6 +24,9 @@ var (
 	initOpenClaw bool
 	initConfig   string
 	initAutoMode bool
+	initAnalyze  bool
+	initTemplate string
+	initListTmpl bool
 )
 
 func init() {
@@ -29,16 +37,43 @@ func init() {
 	initCmd.Flags().BoolVar(&initOpenClaw, "openclaw", true, "Enable OpenClaw mode")
 	initCmd.Flags().StringVar(&initConfig, "config", "", "Path to JSON config file with workflow matrix")
 	initCmd.Flags().BoolVar(&initAutoMode, "auto-mode", false, "Enable automatic gate approval")
+	initCmd.Flags().BoolVar(&initAnalyze, "analyze", false, "Inspect the repository and propose zones, matrix and a spec skeleton")
+	initCmd.Flags().StringVar(&initTemplate, "template", "", "Project template: webapp, api-service, library, ml, or a user template")
+	initCmd.Flags().BoolVar(&initListTmpl, "list-templates", false, "List available templates and exit")
 }
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a .mission directory",
-	Long:  `Creates the .mission/ directory structure for MissionControl orchestration.`,
-	RunE:  runInit,
+	Long: `Creates the .mission/ directory structure for MissionControl orchestration.
+
+With --analyze, the repository is inspected first (package.json, go.mod,
+docker-compose files, directory layout) and the result seeds config.json:
+zones and their paths, and the stage matrix unless --config supplies one.
+A spec skeleton is written to .mission/specs/overview.md.
+
+With --template, zones, persona selection, the stage matrix, gate criteria
+and example specs come from a template. Builtin templates are webapp,
+api-service, library and ml; templates in
+~/.mission-control/templates/<name>/ (template.json plus specs/*.md) are
+also available and shadow builtins of the same name. When --analyze also
+detects zones, those replace the template's zones.`,
+	RunE: runInit,
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if initListTmpl {
+		return printTemplates()
+	}
+
+	var tmpl *ProjectTemplate
+	if initTemplate != "" {
+		var err error
+		if tmpl, err = loadTemplate(initTemplate); err != nil {
+			return err
+		}
+	}
+
 	// Determine working directory
 	workDir := initPath
 	if workDir == "" {
@@ -67,10 +102,21 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(".mission/ already exists")
 	}
 
+	// Inspect the repository before .mission/ exists
+	var analysis *workspace.Analysis
+	if initAnalyze {
+		var err error
+		analysis, err = workspace.Analyze(workDir)
+		if err != nil {
+			return fmt.Errorf("failed to analyze %s: %w", workDir, err)
+		}
+	}
+
 	// Create directory structure
 	dirs := []string{
 		"state",
 		"specs",
+		"mockups",
 		"findings",
 		"handoffs",
 		"checkpoints",
@@ -104,20 +150,22 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := writeJSON(filepath.Join(missionDir, "state", "gates.json"), GatesState{
-		Gates: map[string]Gate{
-			"discovery":    {Stage: "discovery", Status: "pending", Criteria: []string{"Problem space explored", "Stakeholders identified"}},
-			"goal":         {Stage: "goal", Status: "pending", Criteria: []string{"Goal statement defined", "Success metrics established"}},
-			"requirements": {Stage: "requirements", Status: "pending", Criteria: []string{"Requirements documented", "Acceptance criteria defined"}},
-			"planning":     {Stage: "planning", Status: "pending", Criteria: []string{"Tasks broken down", "Dependencies mapped"}},
-			"design":       {Stage: "design", Status: "pending", Criteria: []string{"Spec document complete", "Technical approach approved"}},
-			"implement":    {Stage: "implement", Status: "pending", Criteria: []string{"All tasks complete", "Code compiles"}},
-			"verify":       {Stage: "verify", Status: "pending", Criteria: []string{"Tests passing", "Review complete"}},
-			"validate":     {Stage: "validate", Status: "pending", Criteria: []string{"Acceptance criteria met", "Stakeholder sign-off"}},
-			"document":     {Stage: "document", Status: "pending", Criteria: []string{"README updated", "API documented"}},
-			"release":      {Stage: "release", Status: "pending", Criteria: []string{"Deployed successfully", "Smoke tests pass"}},
-		},
-	}); err != nil {
+	gates := map[string]Gate{
+		"discovery":    {Stage: "discovery", Status: "pending", Criteria: []string{"Problem space explored", "Stakeholders identified"}},
+		"goal":         {Stage: "goal", Status: "pending", Criteria: []string{"Goal statement defined", "Success metrics established"}},
+		"requirements": {Stage: "requirements", Status: "pending", Criteria: []string{"Requirements documented", "Acceptance criteria defined"}},
+		"planning":     {Stage: "planning", Status: "pending", Criteria: []string{"Tasks broken down", "Dependencies mapped"}},
+		"design":       {Stage: "design", Status: "pending", Criteria: []string{"Spec document complete", "Technical approach approved"}},
+		"implement":    {Stage: "implement", Status: "pending", Criteria: []string{"All tasks complete", "Code compiles"}},
+		"verify":       {Stage: "verify", Status: "pending", Criteria: []string{"Tests passing", "Review complete"}},
+		"validate":     {Stage: "validate", Status: "pending", Criteria: []string{"Acceptance criteria met", "Stakeholder sign-off"}},
+		"document":     {Stage: "document", Status: "pending", Criteria: []string{"README updated", "API documented"}},
+		"release":      {Stage: "release", Status: "pending", Criteria: []string{"Deployed successfully", "Smoke tests pass"}},
+	}
+	if tmpl != nil {
+		tmpl.applyGates(gates)
+	}
+	if err := writeJSON(filepath.Join(missionDir, "state", "gates.json"), GatesState{Gates: gates}); err != nil {
 		return err
 	}
 
@@ -145,8 +193,26 @@ func runInit(cmd *cobra.Command, args []string) error {
 		config.AutoMode = true
 	}
 
+	if tmpl != nil {
+		config.Zones = tmpl.Zones
+	}
+	if analysis != nil && (tmpl == nil || analysis.Detected()) {
+		config.Zones = analysis.ZoneNames()
+		config.ZonePaths = make(map[string][]string)
+		for _, z := range analysis.Zones {
+			if len(z.Paths) > 0 {
+				config.ZonePaths[z.Name] = z.Paths
+			}
+		}
+		config.Matrix = analysis.Matrix
+	}
+	if tmpl != nil {
+		config.Matrix = tmpl.matrix(config.Zones)
+		config.Personas = tmpl.personaConfig()
+	}
+
 	// If matrix provided, include it in config
-	if matrix, ok := matrixConfig["matrix"]; ok {
+	if matrix, ok := matrixConfig["matrix"]; ok && matrix != nil {
 		config.Matrix = matrix
 	}
 
@@ -154,29 +220,42 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Seed zones.json, which 'mc zone' maintains from here on
+	zones := make([]bridge.Zone, 0, len(config.Zones))
+	for i, name := range config.Zones {
+		zones = append(zones, bridge.Zone{
+			Name:  name,
+			Color: bridge.ZoneColors[i%len(bridge.ZoneColors)],
+			Paths: config.ZonePaths[name],
+		})
+	}
+	if err := bridge.SaveZones(zonesPath(missionDir), zones); err != nil {
+		return fmt.Errorf("failed to write zones: %w", err)
+	}
+
+	// Template example specs; the analysis skeleton fills overview.md only
+	// if the template has none.
+	if tmpl != nil {
+		for name, content := range tmpl.Specs {
+			if err := os.WriteFile(filepath.Join(missionDir, "specs", name), content, 0644); err != nil {
+				return fmt.Errorf("failed to write spec %s: %w", name, err)
+			}
+		}
+	}
+	if analysis != nil && (tmpl == nil || tmpl.Specs["overview.md"] == nil) {
+		if err := os.WriteFile(filepath.Join(missionDir, "specs", "overview.md"), []byte(analysis.Spec), 0644); err != nil {
+			return fmt.Errorf("failed to write spec skeleton: %w", err)
+		}
+	}
+
 	// Create CLAUDE.md (OpenClaw prompt)
 	if err := os.WriteFile(filepath.Join(missionDir, "CLAUDE.md"), []byte(openClawPrompt), 0644); err != nil {
 		return fmt.Errorf("failed to write CLAUDE.md: %w", err)
 	}
 
 	// Create worker prompts
-	prompts := map[string]string{
-		"researcher.md":            researcherPrompt,
-		"analyst.md":               analystPrompt,
-		"requirements-engineer.md": requirementsEngineerPrompt,
-		"designer.md":              designerPrompt,
-		"architect.md":             architectPrompt,
-		"developer.md":             developerPrompt,
-		"reviewer.md":              reviewerPrompt,
-		"security.md":              securityPrompt,
-		"tester.md":                testerPrompt,
-		"qa.md":                    qaPrompt,
-		"docs.md":                  docsPrompt,
-		"devops.md":                devopsPrompt,
-		"debugger.md":              debuggerPrompt,
-	}
-
-	for name, content := range prompts {
+	for persona, content := range workerPrompts {
+		name := persona + ".md"
 		path := filepath.Join(missionDir, "prompts", name)
 		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 			return fmt.Errorf("failed to write %s: %w", name, err)
@@ -197,10 +276,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	writeAuditLog(missionDir, AuditProjectInitialized, "cli", map[string]interface{}{
+	auditDetails := map[string]interface{}{
 		"path":     workDir,
 		"openclaw": initOpenClaw,
-	})
+	}
+	if analysis != nil {
+		auditDetails["analyzed"] = true
+		auditDetails["zones"] = config.Zones
+	}
+	if tmpl != nil {
+		auditDetails["template"] = tmpl.Name
+	}
+	writeAuditLog(missionDir, AuditProjectInitialized, "cli", auditDetails)
+	recordEvent(missionDir, eventstore.StageChanged, eventstore.StageData{To: "discovery"})
 
 	fmt.Printf("Initialized .mission/ directory at %s\n", workDir)
 	fmt.Println("")
@@ -209,12 +297,29 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println("  .mission/config.json         # Project settings")
 	fmt.Println("  .mission/state/              # Runtime state")
 	fmt.Println("  .mission/specs/              # Feature specifications")
+	fmt.Println("  .mission/mockups/            # Designer mockups")
 	fmt.Println("  .mission/findings/           # Worker findings")
 	fmt.Println("  .mission/handoffs/           # Raw handoff records")
 	fmt.Println("  .mission/checkpoints/        # State checkpoints")
 	fmt.Println("  .mission/prompts/            # Worker system prompts")
 	fmt.Println("  .mission/orchestrator/       # Orchestrator state")
 	fmt.Println("")
+	if tmpl != nil {
+		fmt.Printf("Template: %s (%s)\n", tmpl.Name, tmpl.Description)
+		fmt.Printf("  zones:  %s\n", strings.Join(config.Zones, ", "))
+		if len(tmpl.Specs) > 0 {
+			var names []string
+			for name := range tmpl.Specs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Printf("  specs:  %s\n", strings.Join(names, ", "))
+		}
+		fmt.Println("")
+	}
+	if analysis != nil {
+		printAnalysis(analysis)
+	}
 	if initOpenClaw {
 		fmt.Println("Next: Run 'claude' in this directory to start OpenClaw")
 	} else {
@@ -224,6 +329,31 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printAnalysis summarises what --analyze detected and proposed.
+func printAnalysis(a *workspace.Analysis) {
+	if !a.Detected() {
+		fmt.Println("Analysis: nothing recognised; using default zones.")
+		fmt.Println("")
+		return
+	}
+	fmt.Println("Analysis:")
+	for _, s := range a.Signals {
+		fmt.Printf("  %-28s %s\n", s.Path, s.Detail)
+	}
+	fmt.Println("")
+	fmt.Println("Zones:")
+	for _, z := range a.Zones {
+		paths := strings.Join(z.Paths, ", ")
+		if paths == "" {
+			paths = "-"
+		}
+		fmt.Printf("  %-10s %s\n", z.Name, paths)
+	}
+	fmt.Println("")
+	fmt.Println("Spec skeleton: .mission/specs/overview.md")
+	fmt.Println("")
+}
+
 func writeJSON(path string, v interface{}) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -238,36 +368,32 @@ func writeJSON(path string, v interface{}) error {
 // State types
 
 type StageState struct {
-	Current   string `json:"current"`
-	UpdatedAt string `json:"updated_at"`
+	Current        string `json:"current"`
+	UpdatedAt      string `json:"updated_at"`
+	RolledBackFrom string `json:"rolled_back_from,omitempty"` // stage left by mc stage rollback
 }
 
-type Task struct {
-	ID         string   `json:"id"`
-	Name       string   `json:"name"`
-	Stage      string   `json:"stage"`
-	Zone       string   `json:"zone"`
-	Persona    string   `json:"persona"`
-	Status     string   `json:"status"` // pending, in_progress, complete, blocked
-	DependsOn  []string `json:"depends_on,omitempty"`
-	ScopePaths []string `json:"scope_paths,omitempty"`
-	WorkerID   string   `json:"worker_id,omitempty"`
-	CreatedAt  string   `json:"created_at"`
-	UpdatedAt  string   `json:"updated_at"`
-}
+// Task is one line of tasks.jsonl, shared with the orchestrator.
+type Task = bridge.Task
 
 type TasksState struct {
 	Tasks []Task `json:"tasks"`
 }
 
 type Worker struct {
-	ID        string `json:"id"`
-	Persona   string `json:"persona"`
-	TaskID    string `json:"task_id"`
-	Zone      string `json:"zone"`
-	Status    string `json:"status"` // running, complete, failed
-	PID       int    `json:"pid"`
-	StartedAt string `json:"started_at"`
+	ID        string            `json:"id"`
+	Persona   string            `json:"persona"`
+	TaskID    string            `json:"task_id"`
+	Zone      string            `json:"zone"`
+	Status    string            `json:"status"` // queued, running, complete, failed
+	PID       int               `json:"pid"`
+	StartedAt string            `json:"started_at"`
+	Runtime   string            `json:"runtime,omitempty"`        // worker CLI; empty = claude-code
+	Model     string            `json:"model,omitempty"`          // empty = the runtime's default
+	Task      string            `json:"task,omitempty"`           // task description, kept to start a queued worker
+	Env       map[string]string `json:"env,omitempty"`            // env profile variables, secrets masked
+	Briefing  string            `json:"briefing,omitempty"`       // briefing file written at spawn
+	Variant   string            `json:"prompt_variant,omitempty"` // <experiment>/<variant> when under a prompt experiment
 }
 
 type WorkersState struct {
@@ -280,6 +406,7 @@ type Gate struct {
 	Criteria     []string `json:"criteria"`
 	ApprovedAt   string   `json:"approved_at,omitempty"`
 	ApprovalNote string   `json:"approval_note,omitempty"`
+	ApprovedBy   string   `json:"approved_by,omitempty"`
 }
 
 type GatesState struct {
@@ -292,15 +419,21 @@ type Team struct {
 }
 
 type Config struct {
-	Version        string            `json:"version"`
-	Audience       string            `json:"audience"` // personal, external
-	Zones          []string          `json:"zones"`
-	OpenClaw       bool              `json:"openclaw"`
-	Matrix         interface{}       `json:"matrix,omitempty"`
-	AutoCommit     *AutoCommitConfig `json:"auto_commit,omitempty"`
-	TokenThreshold int               `json:"token_threshold,omitempty"`
-	Teams          map[string]Team   `json:"teams,omitempty"`
-	AutoMode       bool              `json:"auto_mode,omitempty"`
+	Version        string                   `json:"version"`
+	Audience       string                   `json:"audience"` // personal, external
+	Zones          []string                 `json:"zones"`
+	ZonePaths      map[string][]string      `json:"zone_paths,omitempty"`
+	Personas       map[string]PersonaConfig `json:"personas,omitempty"`
+	OpenClaw       bool                     `json:"openclaw"`
+	Matrix         interface{}              `json:"matrix,omitempty"`
+	AutoCommit     *AutoCommitConfig        `json:"auto_commit,omitempty"`
+	TokenThreshold int                      `json:"token_threshold,omitempty"`
+	BriefingTokens int                      `json:"briefing_tokens,omitempty"`
+	Teams          map[string]Team          `json:"teams,omitempty"`
+	AutoMode       bool                     `json:"auto_mode,omitempty"`
+	Audit          *AuditConfig             `json:"audit,omitempty"`
+	AutoCheckpoint *AutoCheckpointConfig    `json:"auto_checkpoint,omitempty"`
+	AutoRestart    *AutoRestartConfig       `json:"auto_restart,omitempty"`
 }
 
 const defaultTokenThreshold = 150000
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInitAnalyzeSeedsConfigAndSpec(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/shop\n"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, "web"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "web", "package.json"), []byte(`{"dependencies":{"react":"^18"}}`), 0644)
+
+	initPath, initAnalyze = tmpDir, true
+	defer func() { initPath, initAnalyze = "", false }()
+
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("mc init --analyze failed: %v", err)
+	}
+
+	missionDir := filepath.Join(tmpDir, ".mission")
+	var cfg Config
+	if err := readJSON(filepath.Join(missionDir, "config.json"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"frontend", "backend", "shared"}; !reflect.DeepEqual(cfg.Zones, want) {
+		t.Errorf("zones = %v, want %v", cfg.Zones, want)
+	}
+	if want := []string{"web/"}; !reflect.DeepEqual(cfg.ZonePaths["frontend"], want) {
+		t.Errorf("frontend paths = %v, want %v", cfg.ZonePaths["frontend"], want)
+	}
+	if cells, ok := cfg.Matrix.([]interface{}); !ok || len(cells) == 0 {
+		t.Errorf("expected a proposed matrix, got %v", cfg.Matrix)
+	}
+
+	spec, err := os.ReadFile(filepath.Join(missionDir, "specs", "overview.md"))
+	if err != nil {
+		t.Fatalf("spec skeleton not written: %v", err)
+	}
+	if !strings.HasPrefix(string(spec), "# shop") {
+		t.Errorf("unexpected spec heading: %q", strings.SplitN(string(spec), "\n", 2)[0])
+	}
+}
+
+func TestBuiltinTemplatesLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	templates, err := listTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+		if tmpl.Description == "" || len(tmpl.Specs) == 0 {
+			t.Errorf("template %s: expected description and example specs", tmpl.Name)
+		}
+	}
+	if want := []string{"api-service", "library", "ml", "webapp"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("templates = %v, want %v", names, want)
+	}
+	if _, err := loadTemplate("../etc"); err == nil {
+		t.Error("expected invalid template name to be rejected")
+	}
+	if _, err := loadTemplate("nope"); err == nil || !strings.Contains(err.Error(), "webapp") {
+		t.Errorf("expected unknown template error listing templates, got %v", err)
+	}
+}
+
+func TestInitTemplateSeedsMission(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	initPath, initTemplate = tmpDir, "library"
+	defer func() { initPath, initTemplate = "", "" }()
+
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("mc init --template library failed: %v", err)
+	}
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	var cfg Config
+	if err := readJSON(filepath.Join(missionDir, "config.json"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"core", "examples", "shared"}; !reflect.DeepEqual(cfg.Zones, want) {
+		t.Errorf("zones = %v, want %v", cfg.Zones, want)
+	}
+	if cfg.Personas["developer"].Enabled != true || cfg.Personas["designer"].Enabled != false {
+		t.Errorf("unexpected persona selection: %v", cfg.Personas)
+	}
+
+	var gates GatesState
+	if err := readJSON(filepath.Join(missionDir, "state", "gates.json"), &gates); err != nil {
+		t.Fatal(err)
+	}
+	if got := gates.Gates["document"].Criteria; len(got) == 0 || got[0] != "Public API documented" {
+		t.Errorf("document gate criteria = %v", got)
+	}
+	if got := gates.Gates["planning"].Criteria; len(got) != 2 {
+		t.Errorf("planning gate should keep default criteria, got %v", got)
+	}
+
+	for _, name := range []string{"overview.md", "public-api.md"} {
+		if _, err := os.Stat(filepath.Join(missionDir, "specs", name)); err != nil {
+			t.Errorf("expected example spec %s: %v", name, err)
+		}
+	}
+}
+
+func TestUserTemplateShadowsBuiltin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".mission-control", "templates", "webapp")
+	os.MkdirAll(filepath.Join(dir, "specs"), 0755)
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{"description":"ours","zones":["site"],"gates":{"verify":["Lighthouse > 90"]}}`), 0644)
+	os.WriteFile(filepath.Join(dir, "specs", "brand.md"), []byte("# Brand\n"), 0644)
+
+	tmpl, err := loadTemplate("webapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Source != dir || tmpl.Description != "ours" || !reflect.DeepEqual(tmpl.Zones, []string{"site"}) {
+		t.Errorf("expected user template, got %+v", tmpl)
+	}
+	if _, ok := tmpl.Specs["brand.md"]; !ok || len(tmpl.Specs) != 1 {
+		t.Errorf("expected only the user's specs, got %v", tmpl.Specs)
+	}
+	if !tmpl.personaEnabled("security") {
+		t.Error("a template without a persona list should enable every persona")
+	}
+
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{"gates":{"shipping":["x"]}}`), 0644)
+	if _, err := loadTemplate("webapp"); err == nil {
+		t.Error("expected unknown gate stage to be rejected")
+	}
+}
@@ -2,11 +2,9 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"syscall"
-	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
 	"github.com/spf13/cobra"
 )
 
@@ -57,15 +55,9 @@ func runKill(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("worker not found: %s", workerID)
 	}
 
-	// Kill the process
-	sig := syscall.SIGTERM
-	if force {
-		sig = syscall.SIGKILL
-	}
-
-	if err := syscall.Kill(worker.PID, sig); err != nil {
-		// Process might already be dead
-		if err != syscall.ESRCH {
+	// Kill the process; a queued worker has no process yet
+	if worker.Status != "queued" && worker.PID > 0 {
+		if err := stopProcess(worker.PID, force); err != nil {
 			return fmt.Errorf("failed to kill process: %w", err)
 		}
 	}
@@ -89,21 +81,11 @@ func runKill(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Killed worker %s (PID %d)\n", workerID, worker.PID)
 
 	// Also update associated task if exists
-	if worker.TaskID != "" {
-		tasks, loadErr := loadTasks(missionDir)
-		if loadErr == nil {
-			for i := range tasks {
-				if tasks[i].ID == worker.TaskID {
-					tasks[i].Status = "blocked"
-					tasks[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-					break
-				}
-			}
-			if saveErr := saveTasks(missionDir, tasks); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to save task status update for %s: %v\n", worker.TaskID, saveErr)
-			}
-		}
-	}
+	setTaskStatus(missionDir, worker.TaskID, bridge.TaskStatusBlocked)
+
+	// The killed worker's slot may let a queued one start
+	trimWorkerLog(missionDir, workerID)
+	dispatchQueuedWorkers(missionDir)
 
 	return nil
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+	logCmd.Flags().BoolP("follow", "f", false, "Keep printing new entries as they are written")
+	logCmd.Flags().IntP("last", "n", 20, "Number of existing entries to show first")
+	logCmd.Flags().StringP("action", "a", "", "Only show this action")
+	logCmd.Flags().StringP("category", "c", "", "Only show this category (e.g. task, gate, stage)")
+	logCmd.Flags().String("actor", "", "Only show this actor")
+	logCmd.Flags().Bool("json", false, "Output raw JSON lines")
+}
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show or tail the audit log",
+	Long: `Prints the most recent audit entries. With --follow, keeps running and
+prints new entries as they are appended, surviving log rotation.
+
+Examples:
+  mc log                 # Last 20 entries
+  mc log -f              # Tail the audit log
+  mc log -f -c gate      # Tail gate activity only`,
+	RunE: runLog,
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	follow, _ := cmd.Flags().GetBool("follow")
+	n, _ := cmd.Flags().GetInt("last")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	action, _ := cmd.Flags().GetString("action")
+	category, _ := cmd.Flags().GetString("category")
+	actor, _ := cmd.Flags().GetString("actor")
+
+	filter := audit.Filter{Action: action, Category: category, Actor: actor}
+
+	if n > 0 {
+		res, err := audit.Query(missionDir, filter)
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+		raw := res.Entries
+		if len(raw) > n {
+			raw = raw[len(raw)-n:]
+		}
+		var entries []AuditEntry
+		for _, line := range raw {
+			var e AuditEntry
+			if json.Unmarshal(line, &e) == nil {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) > 0 || !follow {
+			if err := printAuditEntries(entries, jsonOutput); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !follow {
+		return nil
+	}
+
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		close(stop)
+	}()
+	defer signal.Stop(sig)
+
+	return audit.Follow(missionDir, 500*time.Millisecond, stop, func(line []byte, e audit.Entry) {
+		if !filter.Match(e) {
+			return
+		}
+		if jsonOutput {
+			fmt.Println(strings.TrimSpace(string(line)))
+			return
+		}
+		_ = printAuditEntries([]AuditEntry{{
+			Timestamp: e.Timestamp,
+			Action:    e.Action,
+			Actor:     e.Actor,
+			RequestID: e.RequestID,
+			Details:   e.Details,
+		}}, false)
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/workspace"
+)
+
+// loadMatrix reads the stage matrix from config.json. A project without
+// one gets an empty matrix, which allows every persona.
+func loadMatrix(missionDir string) (workspace.Matrix, error) {
+	var cfg struct {
+		Matrix workspace.Matrix `json:"matrix"`
+	}
+	if err := readJSON(filepath.Join(missionDir, "config.json"), &cfg); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the matrix from config.json: %w", err)
+	}
+	return cfg.Matrix, nil
+}
+
+// checkMatrix refuses persona for stage in zone when matrix doesn't
+// enable it, unless override justifies it. It reports whether the matrix
+// was overridden, for the caller to record with auditMatrixOverride once
+// the change is made. Manual tasks have no persona to check.
+func checkMatrix(matrix workspace.Matrix, stage, zone, persona, override string) (bool, error) {
+	if persona == bridge.PersonaManual {
+		return false, nil
+	}
+	refusal := matrix.Check(stage, zone, persona)
+	if refusal == nil {
+		return false, nil
+	}
+	if strings.TrimSpace(override) == "" {
+		return false, fmt.Errorf("%v\n       Enable it in the matrix, or use --override-matrix \"justification\"", refusal)
+	}
+	return true, nil
+}
+
+// auditMatrixOverride records a persona let past the matrix, with details
+// of what it was for.
+func auditMatrixOverride(missionDir, stage, zone, persona, override string, details map[string]interface{}) {
+	audit := map[string]interface{}{
+		"stage":         stage,
+		"zone":          zone,
+		"persona":       persona,
+		"justification": strings.TrimSpace(override),
+	}
+	for k, v := range details {
+		audit[k] = v
+	}
+	writeAuditLog(missionDir, AuditMatrixOverridden, "cli", audit)
+}
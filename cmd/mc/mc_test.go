@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -565,6 +566,39 @@ func TestCheckpointRestart(t *testing.T) {
 	}
 }
 
+// TestCompileBriefing tests the restart briefing and its token budget
+func TestCompileBriefing(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	cp := &CheckpointData{
+		ID:        "cp-1",
+		Stage:     "implement",
+		SessionID: "session-1",
+		Tasks: []Task{
+			{ID: "t1", Name: "API", Status: "in_progress"},
+			{ID: "t2", Name: "Migrations", Status: "blocked"},
+		},
+		Gates:     map[string]Gate{"design": {Status: "approved"}},
+		Decisions: []string{"Use Postgres"},
+	}
+	if err := writeJSON(filepath.Join(missionDir, "findings", "t0.json"), []map[string]string{{"type": "risk", "summary": "No backups", "severity": "high"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	brief := compileBriefing(missionDir, cp, 0)
+	for _, want := range []string{"**Stage:** implement", "Gates Approved:** design", "- Use Postgres", "Task t2 Migrations is blocked", "- t1 API (in_progress)", "[high] No backups (t0)"} {
+		if !strings.Contains(brief, want) {
+			t.Errorf("briefing lacks %q:\n%s", want, brief)
+		}
+	}
+
+	if short := compileBriefing(missionDir, cp, 1); strings.Contains(short, "Use Postgres") {
+		t.Errorf("a 1-token budget should leave only the header:\n%s", short)
+	}
+}
+
 // TestHandoffValidationError tests that invalid handoffs are rejected
 func TestHandoffValidationError(t *testing.T) {
 	// Create temp directory with .mission
@@ -810,3 +844,40 @@ func TestF8_AutoCheckpointOnGateApproval(t *testing.T) {
 		t.Errorf("Stage should still be 'goal', got %q (auto-advance bug!)", stage.Current)
 	}
 }
+
+// TestPruneAutoCheckpoints tests that retention only removes the oldest auto-checkpoints
+func TestPruneAutoCheckpoints(t *testing.T) {
+	missionDir := t.TempDir()
+	cpDir := filepath.Join(missionDir, "orchestrator", "checkpoints")
+	if err := os.MkdirAll(cpDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, cp := range []CheckpointData{
+		{ID: "cp-1", CreatedAt: "2026-03-01T10:00:00Z", Tag: autoCheckpointTag},
+		{ID: "cp-2", CreatedAt: "2026-03-01T11:00:00Z"},
+		{ID: "cp-3", CreatedAt: "2026-03-01T12:00:00Z", Tag: autoCheckpointTag},
+		{ID: "cp-4", CreatedAt: "2026-03-01T13:00:00Z", Tag: "rollback"},
+		{ID: "cp-5", CreatedAt: "2026-03-01T14:00:00Z", Tag: autoCheckpointTag},
+	} {
+		if err := writeJSON(filepath.Join(cpDir, cp.ID+".json"), cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pruned, err := pruneAutoCheckpoints(missionDir, 0); err != nil || len(pruned) != 0 {
+		t.Fatalf("keep 0 pruned %v (%v), want nothing", pruned, err)
+	}
+	pruned, err := pruneAutoCheckpoints(missionDir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 2 || pruned[0] != "cp-1" || pruned[1] != "cp-3" {
+		t.Errorf("pruned %v, want [cp-1 cp-3]", pruned)
+	}
+	for id, want := range map[string]bool{"cp-1": false, "cp-2": true, "cp-3": false, "cp-4": true, "cp-5": true} {
+		_, err := os.Stat(filepath.Join(cpDir, id+".json"))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", id, exists, want)
+		}
+	}
+}
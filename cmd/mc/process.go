@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// stopProcess sends a worker SIGTERM, or SIGKILL with force. Windows has
+// no signals, so the process is terminated outright there. A process that
+// has already exited is not an error.
+func stopProcess(pid int, force bool) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		// Windows can't open a handle to a process that is gone
+		return nil
+	}
+	if force || runtime.GOOS == "windows" {
+		err = proc.Kill()
+	} else {
+		err = proc.Signal(syscall.SIGTERM)
+	}
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
+
+// isProcessAlive reports whether pid is running: signal 0 on Unix; on
+// Windows, finding the process opens a handle, which fails once it exits.
+func isProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestStopProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	pid := cmd.Process.Pid
+	if !isProcessAlive(pid) {
+		t.Fatal("running process reported dead")
+	}
+	if err := stopProcess(pid, false); err != nil {
+		t.Fatalf("stopProcess: %v", err)
+	}
+	cmd.Wait()
+	if isProcessAlive(pid) {
+		t.Error("stopped process reported alive")
+	}
+	if err := stopProcess(pid, true); err != nil {
+		t.Errorf("stopping an exited process: %v", err)
+	}
+}
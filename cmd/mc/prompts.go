@@ -74,6 +74,19 @@ mc stage
 mc stage next
 ` + "```" + `
 
+### Orchestration requests
+When mc serve is running, prefer requests to running mutating mc commands yourself: the orchestrator validates them, runs them and keeps an audit trail. Write one JSON file per request to .mission/commands/:
+` + "```" + `json
+{"id": "spawn-login", "command": "spawn", "args": {"persona": "developer", "task": "Implement login form", "zone": "frontend"}}
+` + "```" + `
+Commands: spawn, kill, task_create, task_update, gate_request, stage_advance, handoff. The outcome appears in .mission/commands/acks/<id>.json with status done, failed, rejected or pending.
+
+### Request gate approval
+` + "```" + `json
+{"id": "approve-design", "command": "gate_request", "args": {"stage": "design", "note": "Specs reviewed, no open questions"}}
+` + "```" + `
+This only asks: the user confirms it with one click in the dashboard or with mc gate confirm approve-design.
+
 ## Workflow
 
 1. User describes what they want
@@ -175,6 +188,24 @@ Requirements:
 The orchestrator watches conversation.md and detects when you're done by looking for the ` + "`---END---`" + ` marker. Without this, the system cannot detect when you've finished responding.
 `
 
+// workerPrompts are the built-in worker prompts by persona. mc init writes
+// them to .mission/prompts/<persona>.md.
+var workerPrompts = map[string]string{
+	"researcher":            researcherPrompt,
+	"analyst":               analystPrompt,
+	"requirements-engineer": requirementsEngineerPrompt,
+	"designer":              designerPrompt,
+	"architect":             architectPrompt,
+	"developer":             developerPrompt,
+	"reviewer":              reviewerPrompt,
+	"security":              securityPrompt,
+	"tester":                testerPrompt,
+	"qa":                    qaPrompt,
+	"docs":                  docsPrompt,
+	"devops":                devopsPrompt,
+	"debugger":              debuggerPrompt,
+}
+
 const researcherPrompt = `# Researcher — {{zone}} Zone
 
 You are a Researcher in the Discovery stage.
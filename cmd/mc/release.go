@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/report"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.AddCommand(releaseNotesCmd)
+	releaseNotesCmd.Flags().StringP("format", "f", "md", "Output format: md, json")
+	releaseNotesCmd.Flags().StringP("output", "o", "", "Write notes to file instead of stdout")
+	releaseNotesCmd.Flags().String("since", "", "Previous release tag (default: the last tag)")
+	releaseNotesCmd.Flags().String("tag", "", "Create this annotated git tag with the notes as its message")
+	releaseNotesCmd.Flags().Bool("force", false, "Tag outside the release stage")
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Prepare a release",
+}
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Generate release notes since the last release tag",
+	Long: `Compiles the tasks completed since the last git tag, the specs they
+implement, the decisions they recorded and the gate approvals given in the
+meantime into release notes, tracing each change back to its requirement.
+
+With --tag the notes become the message of a new annotated tag on HEAD.
+Tagging is only allowed in the release stage unless --force is given.
+
+Examples:
+  mc release notes                      # Markdown to stdout
+  mc release notes --since v1.2.0 -f json
+  mc release notes --tag v1.3.0`,
+	Args: cobra.NoArgs,
+	RunE: runReleaseNotes,
+}
+
+func runReleaseNotes(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	since, _ := cmd.Flags().GetString("since")
+	tag, _ := cmd.Flags().GetString("tag")
+	force, _ := cmd.Flags().GetBool("force")
+	if format != "md" && format != "markdown" && format != "json" {
+		return fmt.Errorf("invalid --format %q (use md or json)", format)
+	}
+
+	notes, err := report.BuildReleaseNotes(missionDir, since, tag)
+	if err != nil {
+		return err
+	}
+	projectDir := filepath.Dir(missionDir)
+	if tag != "" {
+		if notes.Stage != "release" && !force {
+			return fmt.Errorf("cannot tag %s in the %s stage; advance to release or use --force", tag, notes.Stage)
+		}
+		if err := checkNewTag(projectDir, tag); err != nil {
+			return err
+		}
+	}
+
+	markdown := secrets.Redact(notes.Markdown())
+	content := markdown
+	if format == "json" {
+		data, err := json.MarshalIndent(notes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal release notes: %w", err)
+		}
+		content = secrets.Redact(string(data)) + "\n"
+	}
+
+	if tag != "" {
+		git := exec.Command("git", "tag", "-a", tag, "-F", "-")
+		git.Dir = projectDir
+		git.Stdin = strings.NewReader(markdown)
+		if out, err := git.CombinedOutput(); err != nil {
+			return fmt.Errorf("git tag failed: %s", strings.TrimSpace(string(out)))
+		}
+		writeAuditLog(missionDir, AuditReleaseTagged, "cli", map[string]interface{}{
+			"tag":          tag,
+			"since":        notes.Since,
+			"tasks":        len(notes.Tasks),
+			"requirements": len(notes.Requirements),
+		})
+		fmt.Fprintf(cmd.ErrOrStderr(), "✓ Tagged %s\n", tag)
+	}
+
+	if output == "" {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Release notes written to %s\n", output)
+	return nil
+}
+
+// checkNewTag fails unless tag is a valid tag name not yet used in dir's
+// repository.
+func checkNewTag(dir, tag string) error {
+	if strings.HasPrefix(tag, "-") || exec.Command("git", "check-ref-format", "refs/tags/"+tag).Run() != nil {
+		return fmt.Errorf("invalid tag name %q", tag)
+	}
+	git := exec.Command("git", "rev-parse", "-q", "--verify", "refs/tags/"+tag)
+	git.Dir = dir
+	if git.Run() == nil {
+		return fmt.Errorf("tag %s already exists", tag)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestReleaseNotesTag(t *testing.T) {
+	tmpDir, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	addTask(t, missionDir, Task{ID: "t1", Name: "Login form", Stage: "implement", Status: "complete", UpdatedAt: "2099-01-01T00:00:00Z"})
+
+	cmd := releaseNotesCmd
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	defer func() {
+		cmd.Flags().Set("tag", "")
+		cmd.Flags().Set("force", "false")
+		cmd.SetOut(nil)
+		cmd.SetErr(nil)
+	}()
+
+	if err := runReleaseNotes(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "`t1` Login form") {
+		t.Errorf("notes missing t1:\n%s", out.String())
+	}
+
+	cmd.Flags().Set("tag", "v1.0.0")
+	if err := runReleaseNotes(cmd, nil); err == nil || !strings.Contains(err.Error(), "discovery stage") {
+		t.Fatalf("expected tagging outside the release stage to fail, got %v", err)
+	}
+
+	cmd.Flags().Set("force", "true")
+	if err := runReleaseNotes(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	git := exec.Command("git", "tag", "-l", "-n99", "v1.0.0")
+	git.Dir = tmpDir
+	tagged, _ := git.Output()
+	if !strings.Contains(string(tagged), "`t1` Login form") {
+		t.Errorf("tag message missing the notes: %s", tagged)
+	}
+	entries, _ := readAuditLog(missionDir)
+	if last := entries[len(entries)-1]; last.Action != AuditReleaseTagged || last.Details["tag"] != "v1.0.0" {
+		t.Errorf("last audit entry = %+v", last)
+	}
+
+	if err := runReleaseNotes(cmd, nil); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an existing tag to be refused, got %v", err)
+	}
+}
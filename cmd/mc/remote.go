@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/api"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/spf13/cobra"
+)
+
+// envServer names the orchestrator mc talks to when --server isn't given.
+const envServer = "MC_SERVER"
+
+var serverFlag string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&serverFlag, "server", "", "Work through the orchestrator at this URL instead of local files (or "+envServer+")")
+	rootCmd.PersistentPreRunE = remotePreRun
+}
+
+// remoteRunner runs a command against a remote orchestrator.
+type remoteRunner func(c *remoteClient, cmd *cobra.Command, args []string) error
+
+// remoteCommands are the commands that work with --server, by command
+// path. Everything else reads or writes .mission directly and is refused.
+var remoteCommands = map[string]remoteRunner{
+	"mc status":       remoteStatus,
+	"mc workers":      remoteWorkers,
+	"mc kill":         remoteKill,
+	"mc task list":    remoteTaskList,
+	"mc task create":  remoteTaskCreate,
+	"mc task update":  remoteTaskUpdate,
+	"mc gate approve": remoteGateApprove,
+	"mc checkpoint":   remoteCheckpoint,
+}
+
+// remotePreRun swaps a command's RunE for its remote version when a
+// server is set. The swap undoes itself when it runs, so the command is
+// left as it was.
+func remotePreRun(cmd *cobra.Command, args []string) error {
+	base := serverFlag
+	if base == "" {
+		base = os.Getenv(envServer)
+	}
+	if base == "" {
+		return nil
+	}
+	path := cmd.CommandPath()
+	if strings.HasPrefix(path, "mc help") || strings.HasPrefix(path, "mc completion") {
+		return nil
+	}
+	run, ok := remoteCommands[path]
+	if !ok {
+		return fmt.Errorf("%s works on local files and is not available with --server", path)
+	}
+	c, err := newRemoteClient(base, projectFlag)
+	if err != nil {
+		return err
+	}
+	local := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		cmd.RunE = local
+		return run(c, cmd, args)
+	}
+	return nil
+}
+
+// remoteClient calls the orchestrator's HTTP API.
+type remoteClient struct {
+	base   string // API root, e.g. http://host:8080/api or .../api/p/{project}
+	client *http.Client
+	user   string
+}
+
+func newRemoteClient(server, project string) (*remoteClient, error) {
+	u, err := url.Parse(strings.TrimRight(server, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid --server %q: expected a URL like http://host:8080", server)
+	}
+	base := u.String() + "/api"
+	if project != "" {
+		base += "/p/" + url.PathEscape(project)
+	}
+	c := &remoteClient{base: base, client: &http.Client{Timeout: 30 * time.Second}}
+	if user := identity.Current(); !user.IsZero() {
+		c.user = user.String()
+	}
+	return c, nil
+}
+
+// do sends body (if any) as JSON to path under the API root and decodes
+// the response into v (if any). API errors come back as their message.
+func (c *remoteClient) do(method, path string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.base+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := os.Getenv(identity.EnvToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.user != "" {
+		req.Header.Set(api.UserHeader, c.user)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach the orchestrator: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var p struct {
+			Message string                 `json:"message"`
+			Details map[string]interface{} `json:"details"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&p) != nil || p.Message == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		if out, ok := p.Details["output"].(string); ok && out != "" {
+			return fmt.Errorf("%s\n%s", p.Message, out)
+		}
+		return fmt.Errorf("%s", p.Message)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// printJSON fetches path and prints the response as indented JSON.
+func (c *remoteClient) printJSON(cmd *cobra.Command, path string) error {
+	var v interface{}
+	if err := c.do(http.MethodGet, path, nil, &v); err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+// runCommand sends a mutation and prints the mc output the server ran.
+func (c *remoteClient) runCommand(cmd *cobra.Command, method, path string, body interface{}) error {
+	var res api.CommandResult
+	if err := c.do(method, path, body, &res); err != nil {
+		return err
+	}
+	if out := strings.TrimRight(res.Output, "\n"); out != "" {
+		fmt.Fprintln(cmd.OutOrStdout(), out)
+	}
+	return nil
+}
+
+// localOnlyFlags refuses flags that have no API counterpart, rather than
+// silently ignoring them.
+func localOnlyFlags(cmd *cobra.Command, names ...string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s is not available with --server", name)
+		}
+	}
+	return nil
+}
+
+func remoteStatus(c *remoteClient, cmd *cobra.Command, args []string) error {
+	return c.printJSON(cmd, "/status")
+}
+
+func remoteWorkers(c *remoteClient, cmd *cobra.Command, args []string) error {
+	return c.printJSON(cmd, "/workers")
+}
+
+func remoteKill(c *remoteClient, cmd *cobra.Command, args []string) error {
+	if err := localOnlyFlags(cmd, "force"); err != nil {
+		return err
+	}
+	return c.runCommand(cmd, http.MethodPost, "/workers/"+url.PathEscape(args[0])+"/kill", nil)
+}
+
+func remoteTaskList(c *remoteClient, cmd *cobra.Command, args []string) error {
+	if err := localOnlyFlags(cmd, "ready"); err != nil {
+		return err
+	}
+	q := url.Values{}
+	for _, name := range []string{"stage", "status", "assignee"} {
+		if v, _ := cmd.Flags().GetString(name); v != "" {
+			q.Set(name, v)
+		}
+	}
+	if labels, _ := cmd.Flags().GetStringSlice("label"); len(labels) > 0 {
+		q.Set("label", strings.Join(labels, ","))
+	}
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		q.Set("include_archived", "true")
+	}
+	path := "/tasks"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	return c.printJSON(cmd, path)
+}
+
+func remoteTaskCreate(c *remoteClient, cmd *cobra.Command, args []string) error {
+	if err := localOnlyFlags(cmd, "force", "scope-paths", "spec"); err != nil {
+		return err
+	}
+	req := api.CreateTaskRequest{Title: args[0]}
+	req.Stage, _ = cmd.Flags().GetString("stage")
+	req.Zone, _ = cmd.Flags().GetString("zone")
+	req.Persona, _ = cmd.Flags().GetString("persona")
+	req.Assignee, _ = cmd.Flags().GetString("assignee")
+	req.DependsOn, _ = cmd.Flags().GetStringSlice("depends-on")
+	req.Estimate, _ = cmd.Flags().GetFloat64("estimate")
+	req.Labels, _ = cmd.Flags().GetStringSlice("label")
+	return c.runCommand(cmd, http.MethodPost, "/tasks", req)
+}
+
+func remoteTaskUpdate(c *remoteClient, cmd *cobra.Command, args []string) error {
+	var req api.UpdateTaskRequest
+	req.Status, _ = cmd.Flags().GetString("status")
+	if cmd.Flags().Changed("estimate") {
+		estimate, _ := cmd.Flags().GetFloat64("estimate")
+		req.Estimate = &estimate
+	}
+	if cmd.Flags().Changed("assignee") {
+		assignee, _ := cmd.Flags().GetString("assignee")
+		req.Assignee = &assignee
+	}
+	req.AddLabels, _ = cmd.Flags().GetStringSlice("add-label")
+	req.RemoveLabels, _ = cmd.Flags().GetStringSlice("remove-label")
+	return c.runCommand(cmd, http.MethodPatch, "/tasks/"+url.PathEscape(args[0]), req)
+}
+
+func remoteGateApprove(c *remoteClient, cmd *cobra.Command, args []string) error {
+	var req api.GateActionRequest
+	req.Note, _ = cmd.Flags().GetString("note")
+	req.OverrideFindings, _ = cmd.Flags().GetString("override-findings")
+	return c.runCommand(cmd, http.MethodPost, "/gates/"+url.PathEscape(args[0])+"/approve", req)
+}
+
+func remoteCheckpoint(c *remoteClient, cmd *cobra.Command, args []string) error {
+	if err := localOnlyFlags(cmd, "tokens"); err != nil {
+		return err
+	}
+	return c.runCommand(cmd, http.MethodPost, "/checkpoints", nil)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRemoteMode(t *testing.T) {
+	type call struct {
+		method, path, query, auth string
+		body                      map[string]interface{}
+	}
+	var calls []call
+	orch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := call{method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, auth: r.Header.Get("Authorization")}
+		json.NewDecoder(r.Body).Decode(&c.body)
+		calls = append(calls, c)
+		switch r.URL.Path {
+		case "/api/tasks":
+			if r.Method == http.MethodPost {
+				io.WriteString(w, `{"success": true, "output": "Created task abc123\n"}`)
+				return
+			}
+			io.WriteString(w, `[{"id": "abc123", "name": "Fix login"}]`)
+		case "/api/gates/design/approve":
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"code": "command_failed", "message": "mc gate approve failed", "details": {"output": "gate criteria not met"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer orch.Close()
+	t.Setenv("MC_API_TOKEN", "secret")
+	t.Setenv("MC_USER", "Dana <dana@example.com>")
+	t.Setenv(envServer, "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{"--server", orch.URL + "/"}, args...))
+		defer func() {
+			serverFlag = ""
+			rootCmd.SetOut(nil)
+			rootCmd.SetErr(nil)
+			rootCmd.SetArgs(nil)
+		}()
+		err := rootCmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("task", "create", "Fix login", "--stage", "implement", "--label", "security")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Created task abc123\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	last := calls[len(calls)-1]
+	if last.method != http.MethodPost || last.body["title"] != "Fix login" || last.body["stage"] != "implement" || last.auth != "Bearer secret" {
+		t.Errorf("unexpected request: %+v", last)
+	}
+
+	out, err = run("task", "list", "--status", "pending", "--all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"id": "abc123"`) {
+		t.Errorf("expected the tasks printed, got %q", out)
+	}
+	if q := calls[len(calls)-1].query; q != "include_archived=true&status=pending" {
+		t.Errorf("unexpected query %q", q)
+	}
+
+	_, err = run("gate", "approve", "design", "--note", "looks good")
+	if err == nil || !strings.Contains(err.Error(), "gate criteria not met") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+	if note := calls[len(calls)-1].body["note"]; note != "looks good" {
+		t.Errorf("expected the note sent, got %v", note)
+	}
+
+	before := len(calls)
+	if _, err := run("task", "list", "--ready"); err == nil {
+		t.Error("expected --ready refused with --server")
+	}
+	if _, err := run("doctor"); err == nil || !strings.Contains(err.Error(), "not available with --server") {
+		t.Errorf("expected a local-only command refused, got %v", err)
+	}
+	if len(calls) != before {
+		t.Errorf("expected no requests for refused commands, got %d", len(calls)-before)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().String("until", "", "Event sequence number (42 or #42), RFC3339 time, or duration ago (2h)")
+	replayCmd.Flags().String("out", "", "Directory to rebuild the mission in (default: a new temporary directory)")
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay --until <event|time>",
+	Short: "Rebuild the mission state as of an event or time",
+	Long: `Replays the event stream up to an event or a point in time, writes the
+resulting stage, tasks and gates to a scratch .mission directory and prints a
+summary. The live mission is not touched; run mc status or mc task list in
+the printed directory to look around.
+
+Examples:
+  mc replay --until 42                       # State right after event #42
+  mc replay --until 2026-03-01T12:00:00Z     # State at noon on March 1st
+  mc replay --until 2h --out /tmp/before     # State two hours ago`,
+	Args: cobra.NoArgs,
+	RunE: runReplay,
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	until, _ := cmd.Flags().GetString("until")
+	outDir, _ := cmd.Flags().GetString("out")
+
+	events, _, err := eventstore.Read(missionDir, eventstore.Filter{})
+	if err != nil {
+		return err
+	}
+	cut, err := replayCut(events, until)
+	if err != nil {
+		return err
+	}
+	state := eventstore.Project(events[:cut])
+
+	if outDir == "" {
+		if outDir, err = os.MkdirTemp("", "mc-replay-*"); err != nil {
+			return err
+		}
+	}
+	replayMission := filepath.Join(outDir, ".mission")
+	if err := writeReplayState(missionDir, replayMission, state); err != nil {
+		return fmt.Errorf("failed to write replayed state: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if cut == 0 {
+		fmt.Fprintf(out, "Replayed 0 of %d events\n", len(events))
+	} else {
+		last := events[cut-1]
+		fmt.Fprintf(out, "Replayed %d of %d events (up to #%d, %s)\n", cut, len(events), last.Seq, last.Time)
+	}
+	fmt.Fprintf(out, "Stage:       %s\n", orNone(state.Stage))
+	fmt.Fprintf(out, "Tasks:       %s\n", replayTaskSummary(state.Tasks))
+	fmt.Fprintf(out, "Gates:       %s\n", replayGateSummary(state.Gates))
+	checkpoints := fmt.Sprint(len(state.Checkpoints))
+	if n := len(state.Checkpoints); n > 0 {
+		checkpoints += " (latest " + state.Checkpoints[n-1] + ")"
+	}
+	fmt.Fprintf(out, "Checkpoints: %s\n", checkpoints)
+	fmt.Fprintf(out, "State written to %s\n", replayMission)
+	return nil
+}
+
+// replayCut returns how many of events to replay to stop at until: an
+// event sequence number, an RFC3339 time or a duration ago. An empty
+// until replays everything.
+func replayCut(events []eventstore.Event, until string) (int, error) {
+	if until == "" {
+		return len(events), nil
+	}
+	if seq, err := strconv.ParseUint(strings.TrimPrefix(until, "#"), 10, 64); err == nil {
+		return sort.Search(len(events), func(i int) bool { return events[i].Seq > seq }), nil
+	}
+	t, err := parseAuditTime(until)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --until %q: want an event number, RFC3339 time or duration", until)
+	}
+	for i, e := range events {
+		at, err := time.Parse(time.RFC3339Nano, e.Time)
+		if err == nil && at.After(t) {
+			return i, nil
+		}
+	}
+	return len(events), nil
+}
+
+// writeReplayState writes state as the state files of a .mission
+// directory at dst, with the live mission's config so mc commands work
+// there.
+func writeReplayState(missionDir, dst string, state *eventstore.State) error {
+	stateDir := filepath.Join(dst, "state")
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return err
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		if err := os.WriteFile(filepath.Join(dst, "config.json"), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSON(filepath.Join(stateDir, "stage.json"), StageState{Current: state.Stage, UpdatedAt: state.StageSince}); err != nil {
+		return err
+	}
+
+	tasks := make([]Task, 0, len(state.Tasks))
+	for _, raw := range state.Tasks {
+		var t Task
+		if json.Unmarshal(raw, &t) == nil {
+			tasks = append(tasks, t)
+		}
+	}
+	if err := writeTasksJSONL(filepath.Join(stateDir, tasksJSONLFile), tasks); err != nil {
+		return err
+	}
+
+	gates := GatesState{Gates: map[string]Gate{}}
+	for stage, g := range state.Gates {
+		gates.Gates[stage] = Gate{
+			Stage:        stage,
+			Status:       g.Status,
+			ApprovedAt:   g.ApprovedAt,
+			ApprovalNote: g.Note,
+			ApprovedBy:   g.ApprovedBy,
+		}
+	}
+	if err := writeJSON(filepath.Join(stateDir, "gates.json"), gates); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(stateDir, "workers.json"), WorkersState{Workers: []Worker{}})
+}
+
+// replayTaskSummary counts replayed tasks by status.
+func replayTaskSummary(raw []json.RawMessage) string {
+	counts := map[string]int{}
+	for _, r := range raw {
+		var t Task
+		if json.Unmarshal(r, &t) == nil {
+			counts[t.Status]++
+		}
+	}
+	if len(raw) == 0 {
+		return "0"
+	}
+	statuses := make([]string, 0, len(counts))
+	for s := range counts {
+		statuses = append(statuses, s)
+	}
+	sort.Strings(statuses)
+	parts := make([]string, len(statuses))
+	for i, s := range statuses {
+		parts[i] = fmt.Sprintf("%s %d", s, counts[s])
+	}
+	return fmt.Sprintf("%d (%s)", len(raw), strings.Join(parts, ", "))
+}
+
+// replayGateSummary lists the approved gates in stage order.
+func replayGateSummary(gates map[string]eventstore.GateState) string {
+	var approved []string
+	for _, stage := range stages {
+		if g, ok := gates[stage]; ok && g.Status == "approved" {
+			approved = append(approved, stage)
+		}
+	}
+	if len(approved) == 0 {
+		return "none approved"
+	}
+	return strings.Join(approved, ", ") + " approved"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
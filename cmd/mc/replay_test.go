@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/spf13/cobra"
+)
+
+func TestReplayUntilEvent(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	// #1 is the initial stage from mc init
+	saveTasks(missionDir, []Task{{ID: "a", Name: "a", Status: "pending"}})                                       // #2
+	saveTasks(missionDir, []Task{{ID: "a", Name: "a", Status: "done"}, {ID: "b", Name: "b", Status: "pending"}}) // #3, #4
+	recordEvent(missionDir, eventstore.StageChanged, eventstore.StageData{From: "discovery", To: "goal"})        // #5
+
+	replay := &cobra.Command{Use: "replay", RunE: runReplay}
+	replay.Flags().String("until", "", "")
+	replay.Flags().String("out", "", "")
+	var out bytes.Buffer
+	replay.SetOut(&out)
+
+	outDir := t.TempDir()
+	replay.Flags().Set("until", "#2")
+	replay.Flags().Set("out", outDir)
+	if err := replay.RunE(replay, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Replayed 2 of 5 events") || !strings.Contains(out.String(), "Stage:       discovery") {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+	tasks, err := loadTasks(filepath.Join(outDir, ".mission"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Status != "pending" {
+		t.Errorf("replayed tasks = %+v", tasks)
+	}
+
+	replay.Flags().Set("until", "not a time")
+	if err := replay.RunE(replay, nil); err == nil {
+		t.Error("Expected error for an invalid --until")
+	}
+}
+
+func TestReplayCutByTime(t *testing.T) {
+	events := []eventstore.Event{
+		{Seq: 1, Time: "2026-03-01T10:00:00Z"},
+		{Seq: 2, Time: "2026-03-01T11:00:00Z"},
+		{Seq: 3, Time: "2026-03-01T12:00:00Z"},
+	}
+	for until, want := range map[string]int{
+		"":                     3,
+		"2":                    2,
+		"#9":                   3,
+		"2026-03-01T11:30:00Z": 2,
+		"2026-03-01T09:00:00Z": 0,
+	} {
+		got, err := replayCut(events, until)
+		if err != nil || got != want {
+			t.Errorf("replayCut(%q) = %d, %v; want %d", until, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/MikeSquared-Agency/MissionControl/report"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringP("format", "f", "md", "Output format: md, html, json")
+	reportCmd.Flags().StringP("output", "o", "", "Write report to file instead of stdout")
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a stakeholder mission report",
+	Long: `Compiles stages, gate approvals with notes, key decisions, findings by
+severity and a milestone timeline into a Markdown or HTML document.
+
+Token spend is only tracked live by the orchestrator; use GET /api/report
+on a running 'mc serve' to include it.
+
+Examples:
+  mc report                          # Markdown to stdout
+  mc report -f html -o report.html   # HTML file for stakeholders`,
+	RunE: runReport,
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+
+	rep, err := report.Build(missionDir, nil)
+	if err != nil {
+		return err
+	}
+
+	var content string
+	switch format {
+	case "md", "markdown":
+		content = rep.Markdown()
+	case "html":
+		content, err = rep.HTML()
+		if err != nil {
+			return fmt.Errorf("failed to render HTML report: %w", err)
+		}
+	case "json":
+		data, err := json.MarshalIndent(rep, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		content = string(data) + "\n"
+	default:
+		return fmt.Errorf("invalid --format %q (use md, html or json)", format)
+	}
+
+	content = secrets.Redact(content)
+
+	if output == "" {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Report written to %s\n", output)
+	return nil
+}
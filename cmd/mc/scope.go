@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
 )
 
 // getStagedFiles returns the list of staged file paths (relative to repo root).
@@ -27,28 +29,7 @@ func getStagedFiles(repoDir string) ([]string, error) {
 // matchesScopePath checks if a file matches a single scope pattern.
 // Three modes: directory prefix (pattern ends with /), glob, or exact match.
 func matchesScopePath(file, pattern string) bool {
-	// Directory prefix
-	if strings.HasSuffix(pattern, "/") {
-		return strings.HasPrefix(file, pattern)
-	}
-
-	// Exact match
-	if file == pattern {
-		return true
-	}
-
-	// Glob
-	if matched, err := filepath.Match(pattern, file); err == nil && matched {
-		return true
-	}
-	// For patterns without /, also try matching just the filename
-	if !strings.Contains(pattern, "/") {
-		if matched, err := filepath.Match(pattern, filepath.Base(file)); err == nil && matched {
-			return true
-		}
-	}
-
-	return false
+	return bridge.MatchPath(file, pattern)
 }
 
 // loadScopeExemptPaths reads .mission/config.json and returns the scope_exempt_paths
@@ -79,7 +60,8 @@ func loadScopeExemptPaths(missionDir string) []string {
 
 // validateScope checks that all staged files fall within a task's scope_paths.
 // Returns a list of error strings for out-of-scope files, or nil if everything is fine.
-// If the task has empty/nil scope_paths, only .mission/ files are allowed.
+// If the task has empty/nil scope_paths, the paths of its zone in zones.json
+// apply, and without those only .mission/ files are allowed.
 // Files matching scope_exempt_paths from .mission/config.json are always allowed.
 func validateScope(missionDir, taskID string, stagedFiles []string) []string {
 	found, err := findTaskByID(missionDir, taskID)
@@ -88,6 +70,18 @@ func validateScope(missionDir, taskID string, stagedFiles []string) []string {
 	}
 	task := &found
 
+	// Without scope paths the task is bounded by its zone
+	scopePaths, scopeDesc := task.ScopePaths, strings.Join(task.ScopePaths, ", ")
+	if len(scopePaths) == 0 {
+		scopeDesc = ".mission/ only"
+		if zones, err := loadZones(missionDir); err == nil {
+			if zone := bridge.FindZone(zones, task.Zone); zone != nil && len(zone.Paths) > 0 {
+				scopePaths = zone.Paths
+				scopeDesc = fmt.Sprintf("zone %s: %s", zone.Name, strings.Join(zone.Paths, ", "))
+			}
+		}
+	}
+
 	exemptPaths := loadScopeExemptPaths(missionDir)
 
 	var outOfScope []string
@@ -108,14 +102,14 @@ func validateScope(missionDir, taskID string, stagedFiles []string) []string {
 			continue
 		}
 
-		// If task has no scope_paths, only .mission/ files are allowed (already handled above)
-		if len(task.ScopePaths) == 0 {
+		// Without scope or zone paths, only .mission/ files are allowed (already handled above)
+		if len(scopePaths) == 0 {
 			outOfScope = append(outOfScope, file)
 			continue
 		}
 
 		matched := false
-		for _, pattern := range task.ScopePaths {
+		for _, pattern := range scopePaths {
 			if matchesScopePath(file, pattern) {
 				matched = true
 				break
@@ -131,10 +125,6 @@ func validateScope(missionDir, taskID string, stagedFiles []string) []string {
 	}
 
 	errs := make([]string, 0, len(outOfScope)+1)
-	scopeDesc := strings.Join(task.ScopePaths, ", ")
-	if len(task.ScopePaths) == 0 {
-		scopeDesc = ".mission/ only"
-	}
 	errs = append(errs, fmt.Sprintf("%d file(s) outside task %s scope:", len(outOfScope), taskID))
 	for _, f := range outOfScope {
 		errs = append(errs, fmt.Sprintf("  - %s (allowed: %s)", f, scopeDesc))
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretRmCmd)
+}
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage encrypted provider keys and webhook secrets",
+	Long: `Stores API keys and webhook secrets encrypted in
+~/.mission-control/secrets.json instead of plaintext config.
+
+The encryption key comes from MC_SECRETS_KEY (base64, 32 bytes), the macOS
+keychain, or ~/.mission-control/secrets.key. Every secret is passed to
+spawned workers as an environment variable of the same name, config values
+written as "secret:NAME" (webhook secrets, openai.apiKey) are read from the
+store, and secret values are masked in logs, exports and the audit log.
+
+Examples:
+  mc secret set GITHUB_TOKEN < token.txt
+  echo -n "$KEY" | mc secret set ANTHROPIC_API_KEY
+  mc secret list
+  mc secret rm SLACK_WEBHOOK`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <NAME>",
+	Short: "Store a secret read from stdin",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := readSecretValue(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		store, err := secrets.Open(secrets.DefaultDir())
+		if err != nil {
+			return fmt.Errorf("failed to open secrets store: %w", err)
+		}
+		if err := store.Set(args[0], value); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Stored secret %s\n", args[0])
+		return nil
+	},
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <NAME>",
+	Short: "Print a secret's value",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.Open(secrets.DefaultDir())
+		if err != nil {
+			return fmt.Errorf("failed to open secrets store: %w", err)
+		}
+		value, err := store.Get(args[0])
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("no secret named %s", args[0])
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+		return nil
+	},
+}
+
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored secret names",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.Open(secrets.DefaultDir())
+		if err != nil {
+			return fmt.Errorf("failed to open secrets store: %w", err)
+		}
+		names, err := store.Names()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No secrets stored. Use 'mc secret set <NAME>' to add one.")
+			return nil
+		}
+		for _, name := range names {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+		}
+		return nil
+	},
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <NAME>",
+	Short: "Delete a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.Open(secrets.DefaultDir())
+		if err != nil {
+			return fmt.Errorf("failed to open secrets store: %w", err)
+		}
+		if err := store.Delete(args[0]); errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("no secret named %s", args[0])
+		} else if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Deleted secret %s\n", args[0])
+		return nil
+	},
+}
+
+// readSecretValue reads the value from r, dropping one trailing newline
+// so `echo` and here-strings work.
+func readSecretValue(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" {
+		return "", fmt.Errorf("empty secret: pipe the value on stdin")
+	}
+	return value, nil
+}
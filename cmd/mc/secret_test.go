@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/spf13/cobra"
+)
+
+// runSecretCmd runs one of the mc secret subcommands with stdin and
+// returns its stdout.
+func runSecretCmd(t *testing.T, c *cobra.Command, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	c.SetIn(strings.NewReader(stdin))
+	c.SetOut(&out)
+	c.SetErr(&bytes.Buffer{})
+	err := c.RunE(c, args)
+	return out.String(), err
+}
+
+func TestSecretCommands(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32)))
+
+	if _, err := runSecretCmd(t, secretSetCmd, "ghp_abcdef123\n", "GITHUB_TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runSecretCmd(t, secretSetCmd, "", "EMPTY"); err == nil {
+		t.Errorf("an empty value was stored")
+	}
+	if out, err := runSecretCmd(t, secretGetCmd, "", "GITHUB_TOKEN"); err != nil || out != "ghp_abcdef123\n" {
+		t.Errorf("get = %q, %v", out, err)
+	}
+	if out, _ := runSecretCmd(t, secretListCmd, ""); out != "GITHUB_TOKEN\n" {
+		t.Errorf("list = %q", out)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(home, ".mission-control", secrets.FileName))
+	if len(data) == 0 || strings.Contains(string(data), "ghp_abcdef123") {
+		t.Errorf("store missing or in plaintext:\n%s", data)
+	}
+
+	// Audit entries mask stored values
+	missionDir := filepath.Join(t.TempDir(), ".mission")
+	os.MkdirAll(missionDir, 0755)
+	writeAuditLog(missionDir, AuditTaskCreated, "cli", map[string]interface{}{"note": "token ghp_abcdef123"})
+	entries, _ := readAuditLog(missionDir)
+	if len(entries) != 1 || entries[0].Details["note"] != "token "+secrets.Mask {
+		t.Errorf("audit entries = %+v, want the value masked", entries)
+	}
+
+	if _, err := runSecretCmd(t, secretRmCmd, "", "GITHUB_TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runSecretCmd(t, secretGetCmd, "", "GITHUB_TOKEN"); err == nil {
+		t.Errorf("deleted secret still readable")
+	}
+}
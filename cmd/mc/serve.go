@@ -4,18 +4,53 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/MikeSquared-Agency/MissionControl/api"
 	"github.com/MikeSquared-Agency/MissionControl/serve"
+	"github.com/MikeSquared-Agency/MissionControl/tracker"
 	"github.com/spf13/cobra"
 )
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the MissionControl orchestrator server",
-	Long:  `Starts the HTTP/WebSocket server that provides the API for the MC dashboard.`,
+	Long: `Starts the HTTP/WebSocket server that provides the API for the MC dashboard.
+
+Examples:
+  mc serve                                   # http://localhost:8080
+  mc serve --listen 127.0.0.1:9000           # Loopback only
+  mc serve --listen unix:/run/mc/mc.sock     # Unix socket for a local reverse proxy
+  mc serve --tls-cert cert.pem --tls-key key.pem
+  mc serve --allow-origin https://mc.example.com --allow-origin 'http://localhost:*'
+  mc serve --read-only                       # Serve state but refuse mutations
+  mc serve --debug                           # Add /debug/pprof/ and /api/debug/goroutines
+  mc serve --store postgres://mc@db/mission  # Team server: state kept in Postgres
+
+The dashboard built into mc is served at / unless --headless is given.
+
+Allowed origins default to $MC_ALLOWED_ORIGINS (comma-separated) or, if
+unset, localhost on any port plus the hosted dashboard.
+
+With --store (or $MC_STORE), mission state, the audit log, chat and usage
+are kept in Postgres: .mission/ is filled from the database at startup
+and every change is written back. Teammates point mc at the server with
+--server.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port, _ := cmd.Flags().GetInt("port")
 		apiOnly, _ := cmd.Flags().GetBool("api-only")
 		headless, _ := cmd.Flags().GetBool("headless")
+		listen, _ := cmd.Flags().GetString("listen")
+		tlsCert, _ := cmd.Flags().GetString("tls-cert")
+		tlsKey, _ := cmd.Flags().GetString("tls-key")
+		allowOrigins, _ := cmd.Flags().GetStringSlice("allow-origin")
+		rateLimit, _ := cmd.Flags().GetFloat64("rate-limit")
+		rateBurst, _ := cmd.Flags().GetInt("rate-burst")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		debug, _ := cmd.Flags().GetBool("debug")
+		staleAfter, _ := cmd.Flags().GetDuration("worker-stale-after")
+		grace, _ := cmd.Flags().GetDuration("worker-grace")
+		maxResponse, _ := cmd.Flags().GetInt64("max-response-size")
+		store, _ := cmd.Flags().GetString("store")
+		storeProject, _ := cmd.Flags().GetString("store-project")
 
 		missionPath, err := findMissionDir()
 		if err != nil {
@@ -29,6 +64,23 @@ var serveCmd = &cobra.Command{
 			MissionDir: missionDir,
 			APIOnly:    apiOnly,
 			Headless:   headless,
+			Dashboard:  dashboardFS(),
+			Listen:     listen,
+			TLSCert:    tlsCert,
+			TLSKey:     tlsKey,
+
+			AllowedOrigins: allowOrigins,
+			RateLimit:      rateLimit,
+			RateBurst:      rateBurst,
+			ReadOnly:       readOnly,
+			Debug:          debug,
+
+			WorkerStaleAfter: staleAfter,
+			WorkerGrace:      grace,
+			MaxResponseBytes: maxResponse,
+
+			Store:        store,
+			StoreProject: storeProject,
 		})
 	},
 }
@@ -38,4 +90,17 @@ func init() {
 	serveCmd.Flags().Int("port", 8080, "Port to listen on")
 	serveCmd.Flags().Bool("api-only", false, "Disable file watcher and process tracker")
 	serveCmd.Flags().Bool("headless", false, "API only, no dashboard")
+	serveCmd.Flags().String("listen", "", "Listen address (host:port or unix:/path); overrides --port")
+	serveCmd.Flags().String("tls-cert", "", "TLS certificate file (PEM); serves HTTPS/WSS with --tls-key")
+	serveCmd.Flags().String("tls-key", "", "TLS private key file (PEM)")
+	serveCmd.Flags().Float64("rate-limit", api.DefaultRateLimit, "Mutating requests per second allowed per client (0 disables)")
+	serveCmd.Flags().Int("rate-burst", api.DefaultRateBurst, "Burst size for --rate-limit")
+	serveCmd.Flags().Bool("debug", false, "Serve pprof at /debug/pprof/ and a goroutine summary at /api/debug/goroutines (Unix socket or admin token only)")
+	serveCmd.Flags().Bool("read-only", false, "Start in read-only mode: refuse mutations with 503 until POST /api/readonly turns it off")
+	serveCmd.Flags().Duration("worker-stale-after", tracker.DefaultStaleness.After, "Mark a worker stale after this long without a heartbeat (negative disables)")
+	serveCmd.Flags().Duration("worker-grace", tracker.DefaultStaleness.Grace, "Deregister a stale worker after this much longer")
+	serveCmd.Flags().Int64("max-response-size", api.DefaultMaxResponseBytes, "Largest findings, briefing or checkpoint file served in one response, in bytes; larger ones are fetched in ranges (negative disables)")
+	serveCmd.Flags().String("store", "", "Postgres URL to keep mission state in (team server mode; default $MC_STORE)")
+	serveCmd.Flags().String("store-project", "", "Name the project's state is stored under (default the project directory's name)")
+	serveCmd.Flags().StringSlice("allow-origin", nil, "Browser origin allowed to use the API and WebSocket (repeatable; '*' for any)")
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/simulate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+	simulateCmd.Flags().Int("max-workers", 0, "Simulate with this many concurrent workers instead of limits.maxWorkers")
+	simulateCmd.Flags().Int("tokens-per-unit", simulate.DefaultTokensPerUnit, "Tokens assumed per unit of task estimate")
+	simulateCmd.Flags().Bool("json", false, "Output the full simulation as JSON")
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Dry-run the remaining tasks and project duration, cost and bottlenecks",
+	Long: `Schedules the unfinished tasks stage by stage, as their dependencies and
+the concurrency limits in config.json and zones.json allow, without
+spawning anything. Reports the projected duration (in estimate units), the
+token spend and cost, and the limits tasks queued behind. The same
+simulation is served at GET /api/simulate.
+
+Examples:
+  mc simulate                    # With the mission's limits
+  mc simulate --max-workers 4    # What if four workers ran at once?
+  mc simulate --json             # Per-task schedule`,
+	Args: cobra.NoArgs,
+	RunE: runSimulate,
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	maxWorkers, _ := cmd.Flags().GetInt("max-workers")
+	perUnit, _ := cmd.Flags().GetInt("tokens-per-unit")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if maxWorkers < 0 || perUnit < 0 {
+		return fmt.Errorf("--max-workers and --tokens-per-unit must not be negative")
+	}
+
+	res, err := simulate.Run(missionDir, simulate.Options{MaxWorkers: maxWorkers, TokensPerUnit: perUnit})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if asJSON {
+		data, _ := json.MarshalIndent(res, "", "  ")
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	if len(res.Tasks) == 0 && len(res.Unscheduled) == 0 {
+		fmt.Fprintln(out, "No unfinished tasks to simulate")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Projected duration: %s units (%d tasks, up to %d at once)\n", formatUnits(res.Duration), len(res.Tasks), res.MaxParallel)
+	fmt.Fprintf(out, "Projected tokens:   %d (~$%.2f)\n", res.Tokens, res.Cost)
+	fmt.Fprintf(out, "Limits:             %s\n", describeLimits(res))
+
+	fmt.Fprintln(out, "\nStages:")
+	for _, st := range res.Stages {
+		fmt.Fprintf(out, "  %-13s %3d tasks  %6s → %-6s  %8d tokens  $%.2f\n",
+			st.Name, st.Tasks, formatUnits(st.Start), formatUnits(st.End), st.Tokens, st.Cost)
+	}
+
+	fmt.Fprintln(out, "\nBottlenecks:")
+	if len(res.Bottlenecks) == 0 {
+		fmt.Fprintln(out, "  none, no task waited for a worker slot")
+	}
+	for _, b := range res.Bottlenecks {
+		fmt.Fprintf(out, "  %-18s limit %d  %d tasks waited %s units\n", b.Resource, b.Limit, b.Tasks, formatUnits(b.Wait))
+	}
+
+	if len(res.Unscheduled) > 0 {
+		fmt.Fprintf(out, "\n⚠ Never scheduled (dependency cycle or later-stage dependency): %s\n", strings.Join(res.Unscheduled, ", "))
+	}
+	return nil
+}
+
+// describeLimits summarises the limits the simulation ran under.
+func describeLimits(res *simulate.Result) string {
+	if res.Limits == nil {
+		return "none"
+	}
+	var parts []string
+	if res.Limits.MaxWorkers > 0 {
+		parts = append(parts, fmt.Sprintf("%d workers", res.Limits.MaxWorkers))
+	}
+	zones := make([]string, 0, len(res.Limits.Zones))
+	for z := range res.Limits.Zones {
+		zones = append(zones, z)
+	}
+	sort.Strings(zones)
+	for _, z := range zones {
+		parts = append(parts, fmt.Sprintf("zone %s %d", z, res.Limits.Zones[z]))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatUnits prints a time in estimate units without trailing zeros.
+func formatUnits(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
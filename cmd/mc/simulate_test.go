@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSimulateReport(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	saveTasks(missionDir, []Task{
+		{ID: "a", Name: "a", Stage: "implement", Zone: "backend", Status: "pending", Estimate: 2},
+		{ID: "b", Name: "b", Stage: "implement", Zone: "backend", Status: "pending", Estimate: 2},
+		{ID: "c", Name: "c", Stage: "verify", Persona: "reviewer", Status: "pending", DependsOn: []string{"a"}},
+	})
+
+	sim := &cobra.Command{Use: "simulate", RunE: runSimulate}
+	sim.Flags().Int("max-workers", 0, "")
+	sim.Flags().Int("tokens-per-unit", 1000, "")
+	sim.Flags().Bool("json", false, "")
+	var out bytes.Buffer
+	sim.SetOut(&out)
+
+	sim.Flags().Set("max-workers", "1")
+	if err := sim.RunE(sim, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Projected duration: 5 units (3 tasks, up to 1 at once)",
+		"Projected tokens:   5000",
+		"Limits:             1 workers",
+		"workers            limit 1  1 tasks waited 2 units",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	sim.Flags().Set("max-workers", "-1")
+	if err := sim.RunE(sim, nil); err == nil {
+		t.Error("Expected error for a negative --max-workers")
+	}
+}
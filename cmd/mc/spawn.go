@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/experiment"
 	"github.com/MikeSquared-Agency/MissionControl/hashid"
+	"github.com/MikeSquared-Agency/MissionControl/manager"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+	"github.com/MikeSquared-Agency/MissionControl/tracker"
 	"github.com/spf13/cobra"
 )
 
@@ -17,16 +22,24 @@ func init() {
 	rootCmd.AddCommand(spawnCmd)
 	spawnCmd.Flags().StringP("zone", "z", "", "Zone to work in")
 	spawnCmd.Flags().String("task-id", "", "Task ID to associate with")
+	spawnCmd.Flags().String("runtime", "", "Worker CLI: claude-code, codex, gemini or aider (default: from .mission/config.json runtimes)")
+	spawnCmd.Flags().String("override-matrix", "", "Spawn though the matrix doesn't enable the persona for the stage and zone, with this justification (logged to the audit trail)")
 }
 
 var spawnCmd = &cobra.Command{
 	Use:   "spawn <persona> <task-description>",
 	Short: "Spawn a worker process",
-	Long: `Spawns a Claude Code worker with the specified persona.
+	Long: `Spawns a worker with the specified persona. Workers run Claude Code
+unless --runtime or the "runtimes" section of .mission/config.json picks
+another CLI for the persona or zone.
+
+The persona must be enabled by the project's matrix for the task's stage
+(the current stage without --task-id) and zone.
 
 Examples:
   mc spawn developer "Implement login form" --zone frontend
-  mc spawn researcher "Research auth solutions" --zone backend`,
+  mc spawn researcher "Research auth solutions" --zone backend
+  mc spawn developer "Fix flaky test" --runtime codex`,
 	Args: cobra.ExactArgs(2),
 	RunE: runSpawn,
 }
@@ -50,60 +63,63 @@ func runSpawn(cmd *cobra.Command, args []string) error {
 	taskDesc := args[1]
 	zone, _ := cmd.Flags().GetString("zone")
 	taskID, _ := cmd.Flags().GetString("task-id")
+	runtime, _ := cmd.Flags().GetString("runtime")
+	overrideMatrix, _ := cmd.Flags().GetString("override-matrix")
 
+	if persona == bridge.PersonaManual {
+		return fmt.Errorf("persona %s is for tasks assigned to people: no worker is spawned", persona)
+	}
 	if !validPersonas[persona] {
 		return fmt.Errorf("invalid persona: %s", persona)
 	}
 
-	missionDir, err := findMissionDir()
-	if err != nil {
+	if err := spend.Check(spend.DefaultDir(), time.Now()); err != nil {
 		return err
 	}
 
-	// Generate worker ID
-	workerID := hashid.Generate("worker", taskID, persona, zone)
-
-	// Create worker prompt from template
-	promptPath := filepath.Join(missionDir, "prompts", persona+".md")
-	promptData, err := os.ReadFile(promptPath)
+	missionDir, err := findMissionDir()
 	if err != nil {
-		return fmt.Errorf("failed to read prompt template: %w", err)
+		return err
 	}
-
-	// Substitute template variables
-	prompt := string(promptData)
-	prompt = strings.ReplaceAll(prompt, "{{zone}}", zone)
-	prompt = strings.ReplaceAll(prompt, "{{task_description}}", taskDesc)
-	prompt = strings.ReplaceAll(prompt, "{{task_id}}", taskID)
-	prompt = strings.ReplaceAll(prompt, "{{worker_id}}", workerID)
-
-	// Write temp prompt file
-	tmpPrompt := filepath.Join(os.TempDir(), fmt.Sprintf("mc-worker-%s.md", workerID))
-	if err := os.WriteFile(tmpPrompt, []byte(prompt), 0644); err != nil {
-		return fmt.Errorf("failed to write temp prompt: %w", err)
+	stage, err := loadCurrentStage(missionDir)
+	if err != nil {
+		return err
 	}
-
-	// Determine working directory
-	workDir := filepath.Dir(missionDir) // Project root
-	if zone != "" {
-		zoneDir := filepath.Join(workDir, zone)
-		if info, err := os.Stat(zoneDir); err == nil && info.IsDir() {
-			workDir = zoneDir
+	matrixZone := zone
+	if taskID != "" {
+		tasks, err := loadTasks(missionDir)
+		if err != nil {
+			return fmt.Errorf("failed to read tasks: %w", err)
+		}
+		if task, ok := buildTaskMap(tasks)[taskID]; ok {
+			if err := errManualTask(task); err != nil {
+				return err
+			}
+			stage = task.Stage
+			if matrixZone == "" {
+				matrixZone = task.Zone
+			}
 		}
 	}
+	matrix, err := loadMatrix(missionDir)
+	if err != nil {
+		return err
+	}
+	overridden, err := checkMatrix(matrix, stage, matrixZone, persona, overrideMatrix)
+	if err != nil {
+		return err
+	}
 
-	// Spawn Claude Code process
-	claudeCmd := exec.Command("claude",
-		"--print", taskDesc,
-	)
-	claudeCmd.Dir = workDir
-	claudeCmd.Env = append(os.Environ(),
-		fmt.Sprintf("CLAUDE_SYSTEM_PROMPT=%s", tmpPrompt),
-	)
-
-	// Start the process
-	if err := claudeCmd.Start(); err != nil {
-		return fmt.Errorf("failed to spawn worker: %w", err)
+	// The project config picks the runtime and the concurrency limits
+	projectConfig, err := bridge.LoadProjectConfig(filepath.Dir(missionDir))
+	if err != nil {
+		return fmt.Errorf("failed to read project config: %w", err)
+	}
+	if runtime == "" {
+		runtime = projectConfig.Runtimes.For(persona, zone)
+	}
+	if _, err := manager.RuntimeFor(manager.AgentType(runtime)); err != nil {
+		return err
 	}
 
 	// Record worker in state
@@ -115,13 +131,45 @@ func runSpawn(cmd *cobra.Command, args []string) error {
 	}
 
 	worker := Worker{
-		ID:        workerID,
-		Persona:   persona,
-		TaskID:    taskID,
-		Zone:      zone,
-		Status:    "running",
-		PID:       claudeCmd.Process.Pid,
-		StartedAt: time.Now().UTC().Format(time.RFC3339),
+		ID:      hashid.Generate("worker", taskID, persona, zone),
+		Persona: persona,
+		TaskID:  taskID,
+		Zone:    zone,
+		Task:    taskDesc,
+		Runtime: runtime,
+	}
+
+	// Over a limit, the worker waits for 'mc handoff', 'mc kill' or a
+	// dead worker's reaping to free a slot
+	reapWorkers(missionDir, &state)
+	running, inZone := runningWorkers(state, zone)
+	if !loadLimits(missionDir, projectConfig).Allows(zone, running, inZone) {
+		worker.Status = "queued"
+		state.Workers = append(state.Workers, worker)
+		if err := writeJSON(workersPath, state); err != nil {
+			return fmt.Errorf("failed to update workers state: %w", err)
+		}
+		setTaskStatus(missionDir, taskID, bridge.TaskStatusQueued)
+
+		writeAuditLog(missionDir, AuditWorkerQueued, "cli", map[string]interface{}{
+			"worker_id": worker.ID,
+			"persona":   persona,
+			"task_id":   taskID,
+			"zone":      zone,
+			"running":   running,
+		})
+		if overridden {
+			auditMatrixOverride(missionDir, stage, matrixZone, persona, overrideMatrix, map[string]interface{}{"worker_id": worker.ID, "task_id": taskID})
+		}
+		gitAutoCommit(missionDir, CommitCategoryWorker, fmt.Sprintf("queue %s (%s)", shortID(worker.ID), persona))
+
+		output, _ := json.MarshalIndent(worker, "", "  ")
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if err := startWorker(missionDir, projectConfig, &worker); err != nil {
+		return err
 	}
 
 	state.Workers = append(state.Workers, worker)
@@ -131,15 +179,19 @@ func runSpawn(cmd *cobra.Command, args []string) error {
 	}
 
 	writeAuditLog(missionDir, AuditWorkerSpawned, "cli", map[string]interface{}{
-		"worker_id": workerID,
+		"worker_id": worker.ID,
 		"persona":   persona,
 		"task_id":   taskID,
 		"zone":      zone,
-		"pid":       claudeCmd.Process.Pid,
+		"pid":       worker.PID,
+		"runtime":   runtime,
 	})
+	if overridden {
+		auditMatrixOverride(missionDir, stage, matrixZone, persona, overrideMatrix, map[string]interface{}{"worker_id": worker.ID, "task_id": taskID})
+	}
 
 	// Auto-commit
-	gitAutoCommit(missionDir, CommitCategoryWorker, fmt.Sprintf("spawn %s (%s)", shortID(workerID), persona))
+	gitAutoCommit(missionDir, CommitCategoryWorker, fmt.Sprintf("spawn %s (%s)", shortID(worker.ID), persona))
 
 	// Output worker info
 	output, _ := json.MarshalIndent(worker, "", "  ")
@@ -147,3 +199,275 @@ func runSpawn(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// startWorker starts the worker process for w and fills in its PID,
+// status and start time.
+func startWorker(missionDir string, projectConfig *bridge.ProjectConfig, w *Worker) error {
+	// Create worker prompt from template, or from the variant a running
+	// prompt experiment gives this worker. Only a worker that started
+	// counts towards its variant.
+	promptPath := filepath.Join(missionDir, "prompts", w.Persona+".md")
+	exp, variant, err := experiment.Assign(missionDir, w.Persona, w.ID, w.TaskID, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ prompt experiment for %s skipped: %v\n", w.Persona, err)
+	} else if variant != nil {
+		promptPath = filepath.Join(missionDir, filepath.FromSlash(variant.Prompt))
+		w.Variant = exp.ID + "/" + variant.Name
+		defer func() {
+			if w.PID == 0 {
+				w.Variant = ""
+				if err := experiment.Unassign(missionDir, w.ID); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠ spawn not dropped from experiment %s: %v\n", exp.ID, err)
+				}
+			}
+		}()
+	}
+	promptData, err := os.ReadFile(promptPath)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	// Substitute template variables
+	prompt := string(promptData)
+	prompt = strings.ReplaceAll(prompt, "{{zone}}", w.Zone)
+	prompt = strings.ReplaceAll(prompt, "{{task_description}}", w.Task)
+	prompt = strings.ReplaceAll(prompt, "{{task_id}}", w.TaskID)
+	prompt = strings.ReplaceAll(prompt, "{{worker_id}}", w.ID)
+
+	// A worker on a task gets a briefing of it; one that can't be written
+	// leaves the worker to its prompt
+	if w.TaskID != "" {
+		briefingPath, err := writeSpawnBriefing(missionDir, w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ briefing for %s not written: %v\n", w.TaskID, err)
+		} else {
+			w.Briefing = briefingPath
+			prompt = withBriefing(prompt, briefingPath)
+		}
+	}
+
+	// Write temp prompt file
+	tmpPrompt := filepath.Join(os.TempDir(), fmt.Sprintf("mc-worker-%s.md", w.ID))
+	if err := os.WriteFile(tmpPrompt, []byte(prompt), 0644); err != nil {
+		return fmt.Errorf("failed to write temp prompt: %w", err)
+	}
+
+	// Determine working directory
+	workDir := filepath.Dir(missionDir) // Project root
+	if w.Zone != "" {
+		zoneDir := filepath.Join(workDir, w.Zone)
+		if info, err := os.Stat(zoneDir); err == nil && info.IsDir() {
+			workDir = zoneDir
+		}
+	}
+
+	// Offline mode points the worker at the project's provider
+	rt, err := manager.RuntimeFor(manager.AgentType(w.Runtime))
+	if err != nil {
+		return err
+	}
+	env, record, err := workerEnv(projectConfig, w.Zone)
+	if err != nil {
+		return err
+	}
+	w.Env = record
+	launch := manager.Launch{
+		Task:       w.Task,
+		PromptFile: tmpPrompt,
+		Env:        env,
+	}
+	// The persona's model from the models config, or offline the provider's;
+	// recorded so its usage is priced as that model
+	launch.Model = projectConfig.WorkerModel(w.Persona, w.Runtime)
+	w.Model = launch.Model
+
+	// Spawn worker process
+	workerCmd, err := rt.Command(launch)
+	if err != nil {
+		return fmt.Errorf("failed to spawn worker: %w", err)
+	}
+	workerCmd.Dir = workDir
+
+	// Output goes to .mission/logs/<worker-id>.log, served by the API
+	logFile, err := openWorkerLog(missionDir, w.ID)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	workerCmd.Stdout = logFile
+	workerCmd.Stderr = logFile
+
+	// Start the process
+	if err := workerCmd.Start(); err != nil {
+		return fmt.Errorf("failed to spawn worker: %w", err)
+	}
+
+	w.Status = "running"
+	w.PID = workerCmd.Process.Pid
+	w.StartedAt = time.Now().UTC().Format(time.RFC3339)
+	return nil
+}
+
+// workerEnv is the environment added to a worker in zone: the offline
+// provider's, the stored secrets, then the zone's env profile, which wins.
+// It also returns the profile's variables as recorded on the worker.
+func workerEnv(projectConfig *bridge.ProjectConfig, zone string) ([]string, map[string]string, error) {
+	profile, record, err := projectConfig.EnvProfiles.For(zone)
+	if err != nil {
+		return nil, nil, err
+	}
+	env := projectConfig.WorkerEnv(os.Getenv)
+	env = append(env, secrets.Environ()...)
+	return append(env, profile...), record, nil
+}
+
+// openWorkerLog opens a worker's log file for appending.
+func openWorkerLog(missionDir, workerID string) (*os.File, error) {
+	dir := filepath.Join(missionDir, "logs")
+	if err := tracker.EnsureLogDir(dir); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(tracker.LogPath(dir, workerID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worker log: %w", err)
+	}
+	return f, nil
+}
+
+// trimWorkerLog keeps the last tracker.DefaultPersistBytes of a finished
+// worker's log.
+func trimWorkerLog(missionDir, workerID string) {
+	tracker.TrimLog(tracker.LogPath(filepath.Join(missionDir, "logs"), workerID), tracker.DefaultPersistBytes)
+}
+
+// runningWorkers counts the running workers, in total and in zone. A
+// worker that handed off "in_progress" is still running.
+func runningWorkers(state WorkersState, zone string) (running, inZone int) {
+	for _, w := range state.Workers {
+		if w.Status != "running" && w.Status != "in_progress" {
+			continue
+		}
+		running++
+		if w.Zone == zone {
+			inZone++
+		}
+	}
+	return running, inZone
+}
+
+// reapWorkers marks the running workers whose process is gone as failed,
+// so they no longer hold a concurrency slot: a worker that died without
+// a handoff would otherwise count against the limits forever. Their
+// tasks are blocked, as a killed worker's are. It reports whether any
+// worker was reaped; the caller saves state.
+func reapWorkers(missionDir string, state *WorkersState) bool {
+	reaped := false
+	for i := range state.Workers {
+		w := &state.Workers[i]
+		if w.Status != "running" && w.Status != "in_progress" {
+			continue
+		}
+		if isProcessAlive(w.PID) {
+			continue
+		}
+		w.Status = "failed"
+		reaped = true
+		setTaskStatus(missionDir, w.TaskID, bridge.TaskStatusBlocked)
+		trimWorkerLog(missionDir, w.ID)
+		writeAuditLog(missionDir, AuditWorkerReaped, "cli", map[string]interface{}{
+			"worker_id": w.ID,
+			"task_id":   w.TaskID,
+			"zone":      w.Zone,
+			"pid":       w.PID,
+		})
+	}
+	return reaped
+}
+
+// dispatchQueuedWorkers reaps dead workers, then starts queued workers,
+// oldest first, while the concurrency limits allow. It runs after a
+// worker hands off or is killed, and from mc workers dispatch, which
+// mc serve runs when it reaps a worker. It returns the workers it
+// started.
+func dispatchQueuedWorkers(missionDir string) []Worker {
+	workersPath := filepath.Join(missionDir, "state", "workers.json")
+	var state WorkersState
+	if err := readJSON(workersPath, &state); err != nil {
+		return nil
+	}
+	projectConfig, err := bridge.LoadProjectConfig(filepath.Dir(missionDir))
+	if err != nil {
+		return nil
+	}
+
+	reaped := reapWorkers(missionDir, &state)
+	save := func() {
+		if err := writeJSON(workersPath, state); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to update workers state: %v\n", err)
+		}
+	}
+
+	// Queued workers wait while the spend limits are exceeded
+	if spend.Check(spend.DefaultDir(), time.Now()) != nil {
+		if reaped {
+			save()
+		}
+		return nil
+	}
+	limits := loadLimits(missionDir, projectConfig)
+	var started []Worker
+	for i := range state.Workers {
+		w := &state.Workers[i]
+		if w.Status != "queued" {
+			continue
+		}
+		running, inZone := runningWorkers(state, w.Zone)
+		if !limits.Allows(w.Zone, running, inZone) {
+			continue
+		}
+		if err := startWorker(missionDir, projectConfig, w); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to start queued worker %s: %v\n", w.ID, err)
+			w.Status = "failed"
+			continue
+		}
+		setTaskStatus(missionDir, w.TaskID, bridge.TaskStatusInProgress)
+		writeAuditLog(missionDir, AuditWorkerDequeued, "cli", map[string]interface{}{
+			"worker_id": w.ID,
+			"task_id":   w.TaskID,
+			"zone":      w.Zone,
+			"pid":       w.PID,
+		})
+		started = append(started, *w)
+	}
+	if len(started) == 0 {
+		if reaped {
+			save()
+		}
+		return nil
+	}
+	save()
+	for _, w := range started {
+		fmt.Printf("Started queued worker %s (%s, PID %d)\n", w.ID, w.Persona, w.PID)
+	}
+	return started
+}
+
+// setTaskStatus updates one task's status, if taskID is set and known.
+func setTaskStatus(missionDir, taskID, status string) {
+	if taskID == "" {
+		return
+	}
+	err := updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+		for i := range tasks {
+			if tasks[i].ID == taskID {
+				tasks[i].Status = status
+				tasks[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+				return tasks, nil
+			}
+		}
+		return nil, errNoChange
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save task status update for %s: %v\n", taskID, err)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+)
+
+// briefingPlaceholder is where a worker prompt names its briefing file.
+// Prompts without it get a Briefing section appended instead.
+const briefingPlaceholder = "{{briefing_path}}"
+
+// briefingPredecessor is what a spawn briefing says about one dependency.
+type briefingPredecessor struct {
+	TaskID        string    `json:"task_id"`
+	Name          string    `json:"name"`
+	Status        string    `json:"status"`
+	Summary       string    `json:"summary,omitempty"`
+	FindingsPath  string    `json:"findings_path,omitempty"`
+	Findings      []Finding `json:"findings,omitempty"`
+	Artifacts     []string  `json:"artifacts,omitempty"`
+	OpenQuestions []string  `json:"open_questions,omitempty"`
+}
+
+// writeSpawnBriefing writes .mission/handoffs/<task>-briefing.json for the
+// task a worker is spawned on and returns its path relative to the
+// project. Unlike 'mc briefing generate' it doesn't require the
+// dependencies done: each is described as it stands, from its latest
+// handoff and findings file.
+func writeSpawnBriefing(missionDir string, w *Worker) (string, error) {
+	if !validTaskID.MatchString(w.TaskID) {
+		return "", fmt.Errorf("invalid task ID %q: must match [a-zA-Z0-9_-]+", w.TaskID)
+	}
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to load tasks: %w", err)
+	}
+	taskMap := buildTaskMap(tasks)
+	task, ok := taskMap[w.TaskID]
+	if !ok {
+		return "", fmt.Errorf("task %s not found", w.TaskID)
+	}
+
+	briefing := map[string]interface{}{
+		"task_id":      task.ID,
+		"task_name":    task.Name,
+		"stage":        task.Stage,
+		"zone":         task.Zone,
+		"persona":      task.Persona,
+		"scope_paths":  task.ScopePaths,
+		"output":       ".mission/findings/" + task.ID + ".md",
+		"objective":    w.Task,
+		"worker_id":    w.ID,
+		"generated_at": time.Now().UTC().Format(time.RFC3339),
+	}
+	if len(task.DependsOn) > 0 {
+		briefing["depends_on"] = task.DependsOn
+	}
+
+	handoffs := latestHandoffs(filepath.Join(missionDir, "handoffs"))
+	var preds []briefingPredecessor
+	var predPaths []string
+	predSummaries := map[string]string{}
+	for _, dep := range collectDeps(task, taskMap, map[string]bool{}) {
+		p := briefingPredecessor{TaskID: dep.ID, Name: dep.Name, Status: dep.Status}
+		if h, ok := handoffs[dep.ID]; ok {
+			p.Findings, p.Artifacts, p.OpenQuestions = h.Findings, h.Artifacts, h.OpenQuestions
+		}
+		findingsPath := filepath.Join(missionDir, "findings", dep.ID+".md")
+		if _, err := os.Stat(findingsPath); err == nil {
+			p.FindingsPath = ".mission/findings/" + dep.ID + ".md"
+			predPaths = append(predPaths, p.FindingsPath)
+			if summary, err := extractSummary(findingsPath); err == nil && summary != "" {
+				p.Summary = summary
+				predSummaries[dep.ID] = summary
+			}
+		}
+		preds = append(preds, p)
+	}
+	if len(preds) > 0 {
+		briefing["predecessors"] = preds
+	}
+	if len(predPaths) > 0 {
+		briefing["predecessor_findings_paths"] = predPaths
+	}
+	if len(predSummaries) > 0 {
+		briefing["predecessor_summaries"] = predSummaries
+	}
+	if task.Spec != "" {
+		briefing["spec"] = ".mission/specs/" + task.Spec + ".md"
+		if task.SpecHash != "" && specs.Changed(missionDir, task.Spec, task.SpecHash) {
+			briefing["spec_warning"] = fmt.Sprintf("spec %s has changed since this task was created", task.Spec)
+		}
+	}
+
+	data, err := json.MarshalIndent(briefing, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(missionDir, "handoffs", task.ID+"-briefing.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write briefing: %w", err)
+	}
+	return ".mission/handoffs/" + task.ID + "-briefing.json", nil
+}
+
+// latestHandoffs returns the newest stored handoff of each task, going by
+// the <worker>-<date>-<time>.json stamp mc handoff names them with.
+func latestHandoffs(dir string) map[string]Handoff {
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") || strings.HasSuffix(name, "-briefing.json") {
+			continue
+		}
+		names = append(names, name)
+	}
+	stamp := func(name string) string {
+		parts := strings.Split(strings.TrimSuffix(name, ".json"), "-")
+		if len(parts) < 2 {
+			return name
+		}
+		return strings.Join(parts[len(parts)-2:], "-")
+	}
+	sort.SliceStable(names, func(i, j int) bool { return stamp(names[i]) < stamp(names[j]) })
+
+	latest := map[string]Handoff{}
+	for _, name := range names {
+		var h Handoff
+		if readJSON(filepath.Join(dir, name), &h) != nil || h.TaskID == "" {
+			continue
+		}
+		latest[h.TaskID] = h
+	}
+	return latest
+}
+
+// withBriefing points a worker prompt at its briefing file: in place of
+// {{briefing_path}}, or in a section appended when the prompt doesn't say
+// where it goes.
+func withBriefing(prompt, briefingPath string) string {
+	if strings.Contains(prompt, briefingPlaceholder) {
+		return strings.ReplaceAll(prompt, briefingPlaceholder, briefingPath)
+	}
+	return strings.TrimRight(prompt, "\n") + "\n\n## Briefing\n\n" +
+		"Your task's briefing is in `" + briefingPath + "`: the task, its scope, the spec, and what the tasks before it found and left open. Read it before you start.\n"
+}
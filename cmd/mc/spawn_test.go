@@ -0,0 +1,416 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+	"github.com/spf13/cobra"
+)
+
+func newSpawnCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "spawn <persona> <task-description>",
+		Args: cobra.ExactArgs(2),
+		RunE: runSpawn,
+	}
+	cmd.Flags().StringP("zone", "z", "", "Zone to work in")
+	cmd.Flags().String("task-id", "", "Task ID to associate with")
+	cmd.Flags().String("runtime", "", "Worker CLI")
+	cmd.Flags().String("override-matrix", "", "Justification for overriding the matrix")
+	return cmd
+}
+
+// installFakeClaude puts a claude that exits at once on PATH.
+func installFakeClaude(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// setLimits adds a "limits" section to the project config.
+func setLimits(t *testing.T, missionDir string, limits map[string]interface{}) {
+	t.Helper()
+	configPath := filepath.Join(missionDir, "config.json")
+	var cfg map[string]interface{}
+	if err := readJSON(configPath, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg["limits"] = limits
+	if err := writeJSON(configPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpawnQueuesOverZoneLimit(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+	setLimits(t, missionDir, map[string]interface{}{"zones": map[string]int{"backend": 1}})
+
+	tasks := []Task{
+		{ID: "t1", Name: "First", Stage: "implement", Status: "pending"},
+		{ID: "t2", Name: "Second", Stage: "implement", Status: "pending"},
+	}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"t1", "t2"} {
+		cmd := newSpawnCmd()
+		cmd.Flags().Set("zone", "backend")
+		cmd.Flags().Set("task-id", id)
+		if err := cmd.RunE(cmd, []string{"developer", "Work on " + id}); err != nil {
+			t.Fatalf("spawn %s: %v", id, err)
+		}
+	}
+
+	workersPath := filepath.Join(missionDir, "state", "workers.json")
+	var state WorkersState
+	if err := readJSON(workersPath, &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Workers) != 2 || state.Workers[0].Status != "running" || state.Workers[1].Status != "queued" {
+		t.Fatalf("workers = %+v, want one running and one queued", state.Workers)
+	}
+	if state.Workers[1].PID != 0 {
+		t.Errorf("queued worker has PID %d", state.Workers[1].PID)
+	}
+	tasks, _ = loadTasks(missionDir)
+	if tasks[1].Status != "queued" {
+		t.Errorf("task t2 status = %q, want queued", tasks[1].Status)
+	}
+
+	// Killing the running worker frees the backend slot
+	if err := runKill(killCmd, []string{state.Workers[0].ID}); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	if err := readJSON(workersPath, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Workers[1].Status != "running" || state.Workers[1].PID == 0 {
+		t.Fatalf("queued worker = %+v, want running after the slot freed up", state.Workers[1])
+	}
+	tasks, _ = loadTasks(missionDir)
+	if tasks[1].Status != "in_progress" {
+		t.Errorf("task t2 status = %q, want in_progress", tasks[1].Status)
+	}
+}
+
+func TestDispatchReapsDeadWorkers(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+	setLimits(t, missionDir, map[string]interface{}{"zones": map[string]int{"backend": 1}})
+
+	tasks := []Task{
+		{ID: "t1", Name: "First", Stage: "implement", Status: "in_progress"},
+		{ID: "t2", Name: "Second", Stage: "implement", Status: "queued"},
+	}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	// A finished process's PID stands in for a worker that died
+	dead := exec.Command("true")
+	if err := dead.Run(); err != nil {
+		t.Fatal(err)
+	}
+	workersPath := filepath.Join(missionDir, "state", "workers.json")
+	state := WorkersState{Workers: []Worker{
+		{ID: "w-dead", Persona: "developer", Zone: "backend", TaskID: "t1", Status: "running", PID: dead.Process.Pid},
+		{ID: "w-queued", Persona: "developer", Zone: "backend", TaskID: "t2", Status: "queued"},
+	}}
+	if err := writeJSON(workersPath, state); err != nil {
+		t.Fatal(err)
+	}
+
+	started := dispatchQueuedWorkers(missionDir)
+	if len(started) != 1 || started[0].ID != "w-queued" {
+		t.Fatalf("started = %+v, want w-queued", started)
+	}
+	if err := readJSON(workersPath, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Workers[0].Status != "failed" {
+		t.Errorf("dead worker status = %q, want failed", state.Workers[0].Status)
+	}
+	if state.Workers[1].Status != "running" {
+		t.Errorf("queued worker status = %q, want running", state.Workers[1].Status)
+	}
+	tasks, _ = loadTasks(missionDir)
+	if tasks[0].Status != "blocked" || tasks[1].Status != "in_progress" {
+		t.Errorf("task statuses = %q, %q, want blocked, in_progress", tasks[0].Status, tasks[1].Status)
+	}
+}
+
+func TestSpawnRefusedOverSpendLimit(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".mission-control"), 0755)
+	os.WriteFile(filepath.Join(home, ".mission-control", "config.json"), []byte(`{"spendLimits":{"dailyUSD":1}}`), 0644)
+	spend.Record(spend.DefaultDir(), 1000, 2, time.Now())
+
+	cmd := newSpawnCmd()
+	err := cmd.RunE(cmd, []string{"developer", "Over budget"})
+	if !errors.Is(err, spend.ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	// An override lets it through
+	if _, err := spend.SetOverride(spend.DefaultDir(), "Test", "hotfix", spend.EndOfDay(time.Now()), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, []string{"developer", "Hotfix"}); err != nil {
+		t.Fatalf("spawn under the override: %v", err)
+	}
+	var state WorkersState
+	readJSON(filepath.Join(missionDir, "state", "workers.json"), &state)
+	if len(state.Workers) != 1 {
+		t.Errorf("workers = %+v, want only the hotfix", state.Workers)
+	}
+}
+
+func TestKillQueuedWorkerSendsNoSignal(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+
+	workersPath := filepath.Join(missionDir, "state", "workers.json")
+	state := WorkersState{Workers: []Worker{{ID: "w-queued", Persona: "developer", Status: "queued"}}}
+	if err := writeJSON(workersPath, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := runKill(killCmd, []string{"w-queued"}); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+	if err := readJSON(workersPath, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Workers[0].Status != "killed" {
+		t.Errorf("status = %q, want killed", state.Workers[0].Status)
+	}
+}
+
+func TestSpawnWritesWorkerLog(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte("#!/bin/sh\necho working\necho oops >&2\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cmd := newSpawnCmd()
+	if err := cmd.RunE(cmd, []string{"developer", "Write a log"}); err != nil {
+		t.Fatal(err)
+	}
+	var state WorkersState
+	if err := readJSON(filepath.Join(missionDir, "state", "workers.json"), &state); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(missionDir, "logs", state.Workers[0].ID+".log")
+
+	var data []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		data, _ = os.ReadFile(logPath)
+		if strings.Contains(string(data), "oops") {
+			break
+		}
+	}
+	if !strings.Contains(string(data), "working\n") || !strings.Contains(string(data), "oops\n") {
+		t.Fatalf("worker log = %q, want stdout and stderr", data)
+	}
+	if _, err := os.Stat(filepath.Join(missionDir, "logs", ".gitignore")); err != nil {
+		t.Errorf("logs directory is not git-ignored: %v", err)
+	}
+}
+
+func TestSpawnInjectsEnvProfile(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	t.Setenv("HOME", t.TempDir()) // no secrets store
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte("#!/bin/sh\necho \"db=$DATABASE_URL\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configPath := filepath.Join(missionDir, "config.json")
+	var cfg map[string]interface{}
+	readJSON(configPath, &cfg)
+	cfg["envProfiles"] = map[string]interface{}{"*": map[string]string{"DATABASE_URL": "postgres://localhost/test"}}
+	writeJSON(configPath, cfg)
+
+	cmd := newSpawnCmd()
+	if err := cmd.RunE(cmd, []string{"developer", "Run migrations"}); err != nil {
+		t.Fatal(err)
+	}
+	var state WorkersState
+	readJSON(filepath.Join(missionDir, "state", "workers.json"), &state)
+	if got := state.Workers[0].Env["DATABASE_URL"]; got != "postgres://localhost/test" {
+		t.Errorf("worker record env = %v", state.Workers[0].Env)
+	}
+
+	logPath := filepath.Join(missionDir, "logs", state.Workers[0].ID+".log")
+	var data []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if data, _ = os.ReadFile(logPath); len(data) > 0 {
+			break
+		}
+	}
+	if !strings.Contains(string(data), "db=postgres://localhost/test") {
+		t.Errorf("worker log = %q, want the profile's DATABASE_URL", data)
+	}
+
+	cfg["envProfiles"] = map[string]interface{}{"*": map[string]string{"API_TOKEN": "secret:NOT_STORED"}}
+	writeJSON(configPath, cfg)
+	cmd = newSpawnCmd()
+	if err := cmd.RunE(cmd, []string{"developer", "No token"}); err == nil {
+		t.Errorf("spawned without its secret")
+	}
+}
+
+func TestSpawnWritesBriefing(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	tasks := []Task{
+		{ID: "t1", Name: "Design", Stage: "design", Status: "done"},
+		{ID: "t2", Name: "Research", Stage: "research", Status: "in_progress"},
+		{ID: "t3", Name: "Build", Stage: "implement", Zone: "backend", Status: "pending", DependsOn: []string{"t1", "t2"}},
+	}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+	handoffs := filepath.Join(missionDir, "handoffs")
+	os.WriteFile(filepath.Join(handoffs, "w1-20260101-100000.json"), []byte(`{"task_id":"t1","worker_id":"w1","status":"blocked","open_questions":["old"]}`), 0644)
+	os.WriteFile(filepath.Join(handoffs, "w1-20260102-100000.json"), []byte(`{"task_id":"t1","worker_id":"w1","status":"complete","artifacts":["api.md"],"open_questions":["Paging?"]}`), 0644)
+	os.WriteFile(filepath.Join(missionDir, "findings", "t1.md"), []byte("# Design\nSummary: REST with JSON\n"), 0644)
+
+	cmd := newSpawnCmd()
+	cmd.Flags().Set("task-id", "t3")
+	if err := cmd.RunE(cmd, []string{"developer", "Build the API"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var state WorkersState
+	readJSON(filepath.Join(missionDir, "state", "workers.json"), &state)
+	w := state.Workers[0]
+	if w.Briefing != ".mission/handoffs/t3-briefing.json" {
+		t.Errorf("worker briefing = %q", w.Briefing)
+	}
+	var briefing struct {
+		TaskID       string                `json:"task_id"`
+		Objective    string                `json:"objective"`
+		WorkerID     string                `json:"worker_id"`
+		Predecessors []briefingPredecessor `json:"predecessors"`
+	}
+	if err := readJSON(filepath.Join(handoffs, "t3-briefing.json"), &briefing); err != nil {
+		t.Fatal(err)
+	}
+	if briefing.TaskID != "t3" || briefing.Objective != "Build the API" || briefing.WorkerID != w.ID {
+		t.Errorf("unexpected briefing %+v", briefing)
+	}
+	if len(briefing.Predecessors) != 2 {
+		t.Fatalf("predecessors = %+v, want t1 and t2", briefing.Predecessors)
+	}
+	for _, p := range briefing.Predecessors {
+		switch p.TaskID {
+		case "t1":
+			if p.Summary != "REST with JSON" || len(p.Artifacts) != 1 || len(p.OpenQuestions) != 1 || p.OpenQuestions[0] != "Paging?" {
+				t.Errorf("t1 = %+v, want its summary and latest handoff", p)
+			}
+		case "t2":
+			if p.Status != "in_progress" || p.FindingsPath != "" {
+				t.Errorf("t2 = %+v, want it described as it stands", p)
+			}
+		}
+	}
+
+	prompt, err := os.ReadFile(filepath.Join(os.TempDir(), "mc-worker-"+w.ID+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(prompt), "## Briefing") || !strings.Contains(string(prompt), ".mission/handoffs/t3-briefing.json") {
+		t.Errorf("prompt doesn't point at the briefing:\n%s", prompt)
+	}
+}
+
+func TestSpawnChecksMatrix(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+	setMatrix(t, missionDir, "backend")
+	if err := saveTasks(missionDir, []Task{{ID: "t1", Name: "Survey", Stage: "discovery", Zone: "backend", Status: "pending"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newSpawnCmd()
+	cmd.Flags().Set("task-id", "t1")
+	if err := cmd.RunE(cmd, []string{"developer", "Survey the code"}); err == nil || !strings.Contains(err.Error(), "--override-matrix") {
+		t.Fatalf("expected the developer refused for a discovery task, got %v", err)
+	}
+	if err := cmd.RunE(cmd, []string{"researcher", "Survey the code"}); err != nil {
+		t.Fatalf("researcher refused: %v", err)
+	}
+
+	cmd.Flags().Set("override-matrix", "Needs a prototype")
+	if err := cmd.RunE(cmd, []string{"developer", "Prototype it"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := readAuditLog(missionDir)
+	last := entries[len(entries)-1]
+	if last.Action != AuditMatrixOverridden || last.Details["persona"] != "developer" || last.Details["worker_id"] == nil {
+		t.Errorf("override not audited: %+v", last)
+	}
+}
+
+func TestSpawnUsesPersonaModel(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte("#!/bin/sh\necho \"args=$*\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configPath := filepath.Join(missionDir, "config.json")
+	var cfg map[string]interface{}
+	readJSON(configPath, &cfg)
+	cfg["models"] = map[string]interface{}{"personas": map[string]interface{}{"architect": map[string]string{"model": "opus"}}}
+	writeJSON(configPath, cfg)
+
+	cmd := newSpawnCmd()
+	if err := cmd.RunE(cmd, []string{"architect", "Design the API"}); err != nil {
+		t.Fatal(err)
+	}
+	var state WorkersState
+	readJSON(filepath.Join(missionDir, "state", "workers.json"), &state)
+	if state.Workers[0].Model != "opus" {
+		t.Errorf("worker model = %q, want opus", state.Workers[0].Model)
+	}
+
+	logPath := filepath.Join(missionDir, "logs", state.Workers[0].ID+".log")
+	var data []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if data, _ = os.ReadFile(logPath); len(data) > 0 {
+			break
+		}
+	}
+	if !strings.Contains(string(data), "--model opus") {
+		t.Errorf("worker log = %q, want claude run with --model opus", data)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(specCmd)
+	specCmd.AddCommand(specListCmd)
+	specCmd.AddCommand(specSubmitCmd)
+	specCmd.AddCommand(specApproveCmd)
+	specCmd.AddCommand(specRejectCmd)
+	specCmd.AddCommand(specSupersedeCmd)
+
+	specListCmd.Flags().Bool("json", false, "Output as JSON")
+	for _, c := range []*cobra.Command{specSubmitCmd, specApproveCmd, specRejectCmd, specSupersedeCmd} {
+		c.Flags().String("note", "", "Note recorded with the change")
+	}
+	specRejectCmd.MarkFlagRequired("note")
+	specSupersedeCmd.Flags().String("by", "", "ID of the spec that replaces it")
+}
+
+var specCmd = &cobra.Command{
+	Use:   "spec",
+	Short: "Review and approve specs",
+	Long: `Specs in .mission/specs/ move draft → review → approved → superseded.
+Approving a spec locks that version: its content hash is recorded, and a
+spec edited afterwards is reported as modified until a new version is
+submitted and approved. Tasks created with --spec record the spec's hash,
+and are reported when the spec changes after they were created.`,
+	RunE: runSpecList,
+}
+
+var specListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List specs with their status and tasks created against older versions",
+	Args:  cobra.NoArgs,
+	RunE:  runSpecList,
+}
+
+var specSubmitCmd = &cobra.Command{
+	Use:   "submit <spec-id>",
+	Short: "Submit a draft, or a changed approved spec, for review",
+	Args:  cobra.ExactArgs(1),
+	RunE:  specTransitionCmd(specs.StatusReview),
+}
+
+var specApproveCmd = &cobra.Command{
+	Use:   "approve <spec-id>",
+	Short: "Approve a spec in review, locking its current content",
+	Args:  cobra.ExactArgs(1),
+	RunE:  specTransitionCmd(specs.StatusApproved),
+}
+
+var specRejectCmd = &cobra.Command{
+	Use:   "reject <spec-id>",
+	Short: "Send a spec in review back to draft",
+	Args:  cobra.ExactArgs(1),
+	RunE:  specTransitionCmd(specs.StatusDraft),
+}
+
+var specSupersedeCmd = &cobra.Command{
+	Use:   "supersede <spec-id>",
+	Short: "Mark an approved spec superseded",
+	Args:  cobra.ExactArgs(1),
+	RunE:  specTransitionCmd(specs.StatusSuperseded),
+}
+
+func specTransitionCmd(status string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		missionDir, err := findMissionDir()
+		if err != nil {
+			return err
+		}
+		note, _ := cmd.Flags().GetString("note")
+		by, _ := cmd.Flags().GetString("by")
+		spec, err := transitionSpec(missionDir, args[0], status, note, by)
+		if err != nil {
+			return err
+		}
+		output, _ := json.MarshalIndent(spec, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+		return nil
+	}
+}
+
+// transitionSpec moves spec id to status in the current user's name and
+// audits the change.
+func transitionSpec(missionDir, id, status, note, supersededBy string) (specs.Spec, error) {
+	before, err := specs.Get(missionDir, id)
+	if err != nil {
+		return specs.Spec{}, err
+	}
+	spec, err := specs.Transition(missionDir, id, status, identity.Current().String(), note, supersededBy)
+	if err != nil {
+		return spec, err
+	}
+	details := map[string]interface{}{
+		"spec":    id,
+		"from":    before.Status,
+		"to":      spec.Status,
+		"version": spec.Version,
+	}
+	if note != "" {
+		details["note"] = note
+	}
+	if status == specs.StatusApproved {
+		details["hash"] = spec.Hash
+	}
+	if supersededBy != "" {
+		details["superseded_by"] = supersededBy
+	}
+	writeAuditLog(missionDir, AuditSpecStatusChanged, "cli", details)
+	gitAutoCommit(missionDir, CommitCategoryGate, fmt.Sprintf("spec %s %s", id, status))
+	return spec, nil
+}
+
+// staleTasks returns the unarchived tasks whose spec changed after they
+// were created, by spec ID.
+func staleTasks(missionDir string) (map[string][]Task, error) {
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	stale := map[string][]Task{}
+	for _, t := range tasks {
+		if t.Spec == "" || t.SpecHash == "" || t.Status == bridge.TaskStatusArchived {
+			continue
+		}
+		if specs.Changed(missionDir, t.Spec, t.SpecHash) {
+			stale[t.Spec] = append(stale[t.Spec], t)
+		}
+	}
+	return stale, nil
+}
+
+func runSpecList(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	list, err := specs.List(missionDir)
+	if err != nil {
+		return err
+	}
+	stale, err := staleTasks(missionDir)
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		out := map[string]interface{}{"specs": list, "stale_tasks": stale}
+		data, _ := json.MarshalIndent(out, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	w := cmd.OutOrStdout()
+	if len(list) == 0 {
+		fmt.Fprintln(w, "No specs in .mission/specs/")
+	}
+	for _, s := range list {
+		line := fmt.Sprintf("%-24s %-10s", s.ID, s.Status)
+		if s.Version > 0 {
+			line += fmt.Sprintf(" v%d", s.Version)
+		}
+		if s.Modified {
+			line += " (modified since approval)"
+		}
+		if s.SupersededBy != "" {
+			line += " → " + s.SupersededBy
+		}
+		fmt.Fprintln(w, line)
+	}
+	ids := make([]string, 0, len(stale))
+	for id := range stale {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, t := range stale[id] {
+			fmt.Fprintf(cmd.ErrOrStderr(), "⚠ Warning: task %s (%s) was created against an earlier version of spec %s\n", t.ID, t.Name, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+)
+
+func TestSpecApprovalAndStaleTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("mc init failed: %v", err)
+	}
+	missionDir := filepath.Join(tmpDir, ".mission")
+	specPath := specs.Path(missionDir, "login")
+	os.WriteFile(specPath, []byte("# Login\n\nEmail and password.\n"), 0644)
+
+	if _, err := transitionSpec(missionDir, "login", specs.StatusReview, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := transitionSpec(missionDir, "login", specs.StatusApproved, "Reviewed", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Version != 1 || spec.Hash == "" {
+		t.Fatalf("approved spec = %+v", spec)
+	}
+	entries, _ := readAuditLog(missionDir)
+	last := entries[len(entries)-1]
+	if last.Action != AuditSpecStatusChanged || last.Details["to"] != specs.StatusApproved || last.Details["hash"] != spec.Hash {
+		t.Errorf("last audit entry = %+v", last)
+	}
+
+	addTask(t, missionDir, Task{ID: "t1", Name: "login form", Stage: "implement", Status: "pending", Spec: "login", SpecHash: spec.Hash})
+	if stale, _ := staleTasks(missionDir); len(stale) != 0 {
+		t.Fatalf("stale before the edit = %v", stale)
+	}
+
+	os.WriteFile(specPath, []byte("# Login\n\nEmail, password and 2FA.\n"), 0644)
+	stale, err := staleTasks(missionDir)
+	if err != nil || len(stale["login"]) != 1 || stale["login"][0].ID != "t1" {
+		t.Fatalf("stale after the edit = %v, %v", stale, err)
+	}
+
+	// The briefing carries the warning
+	data, err := generateBriefing(missionDir, "t1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var briefing map[string]interface{}
+	json.Unmarshal(data, &briefing)
+	if briefing["spec_warning"] == nil {
+		t.Errorf("briefing has no spec_warning: %s", data)
+	}
+
+	if _, err := transitionSpec(missionDir, "login", specs.StatusApproved, "", ""); !errors.Is(err, specs.ErrTransition) {
+		t.Errorf("approving without review: err = %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(spendCmd)
+	spendCmd.AddCommand(spendOverrideCmd)
+	spendCmd.Flags().Bool("json", false, "Output as JSON")
+	spendOverrideCmd.Flags().String("reason", "", "Why spending may continue")
+	spendOverrideCmd.Flags().Int("hours", 0, "How long the override lasts (default: until the end of the UTC day)")
+	spendOverrideCmd.Flags().Bool("clear", false, "End the override")
+}
+
+var spendCmd = &cobra.Command{
+	Use:   "spend",
+	Short: "Show token spend against the global spend limits",
+	Long: `Shows today's and the last seven days' token spend across all projects
+against the limits under "spendLimits" in ~/.mission-control/config.json:
+
+  "spendLimits": {"dailyUSD": 50, "weeklyUSD": 200, "dailyTokens": 0, "weeklyTokens": 0}
+
+Once a limit is reached, mc spawn and the orchestrator start no new workers
+and King messages are not forwarded, until the day rolls over, the limit
+is raised or an override is set with 'mc spend override'.`,
+	Args: cobra.NoArgs,
+	RunE: runSpend,
+}
+
+var spendOverrideCmd = &cobra.Command{
+	Use:   "override",
+	Short: "Let spending continue past the spend limits",
+	Args:  cobra.NoArgs,
+	RunE:  runSpendOverride,
+}
+
+func runSpend(cmd *cobra.Command, args []string) error {
+	st := spend.Current(spend.DefaultDir(), time.Now())
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		data, _ := json.MarshalIndent(st, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+	printSpendStatus(cmd, st)
+	return nil
+}
+
+func printSpendStatus(cmd *cobra.Command, st spend.Status) {
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Today:       $%.2f, %d tokens%s\n", st.Today.CostUSD, st.Today.Tokens, spendLimitText(st.Limits.DailyUSD, st.Limits.DailyTokens))
+	fmt.Fprintf(w, "Last 7 days: $%.2f, %d tokens%s\n", st.Week.CostUSD, st.Week.Tokens, spendLimitText(st.Limits.WeeklyUSD, st.Limits.WeeklyTokens))
+	switch {
+	case st.Tripped:
+		fmt.Fprintf(w, "✗ Limit reached (%s): new workers and King messages are paused\n", strings.Join(st.Exceeded, ", "))
+	case st.Override != nil:
+		fmt.Fprintf(w, "Override by %s until %s: %s\n", st.Override.By, st.Override.Until, st.Override.Reason)
+	}
+}
+
+func spendLimitText(usd float64, tokens int) string {
+	var limits []string
+	if usd > 0 {
+		limits = append(limits, fmt.Sprintf("$%.2f", usd))
+	}
+	if tokens > 0 {
+		limits = append(limits, fmt.Sprintf("%d tokens", tokens))
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	return " (limit " + strings.Join(limits, ", ") + ")"
+}
+
+func runSpendOverride(cmd *cobra.Command, args []string) error {
+	reason, _ := cmd.Flags().GetString("reason")
+	hours, _ := cmd.Flags().GetInt("hours")
+	end, _ := cmd.Flags().GetBool("clear")
+	now := time.Now()
+
+	var st spend.Status
+	var err error
+	details := map[string]interface{}{}
+	action := AuditSpendOverrideEnd
+	if end {
+		st, err = spend.ClearOverride(spend.DefaultDir(), now)
+	} else {
+		if strings.TrimSpace(reason) == "" {
+			return fmt.Errorf("--reason is required")
+		}
+		if hours < 0 {
+			return fmt.Errorf("--hours must not be negative")
+		}
+		until := spend.EndOfDay(now)
+		if hours > 0 {
+			until = now.Add(time.Duration(hours) * time.Hour)
+		}
+		st, err = spend.SetOverride(spend.DefaultDir(), identity.Current().String(), reason, until, now)
+		action = AuditSpendOverrideSet
+		details["reason"], details["until"] = reason, until.UTC().Format(time.RFC3339)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The limits are global; the override is audited in the mission it
+	// was set from, if any
+	if missionDir, err := findMissionDir(); err == nil {
+		details["exceeded"] = st.Exceeded
+		writeAuditLog(missionDir, action, "cli", details)
+	}
+	printSpendStatus(cmd, st)
+	return nil
+}
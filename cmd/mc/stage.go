@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
 	"github.com/spf13/cobra"
 )
 
@@ -143,6 +145,7 @@ func runStage(cmd *cobra.Command, args []string) error {
 			"from_stage": getPrevStage(nextStage),
 			"to_stage":   nextStage,
 		})
+		recordEvent(missionDir, eventstore.StageChanged, eventstore.StageData{From: getPrevStage(nextStage), To: nextStage, Forced: force})
 
 		gitAutoCommit(missionDir, CommitCategoryStage, fmt.Sprintf("advance %s → %s", getPrevStage(nextStage), nextStage))
 
@@ -191,6 +194,7 @@ func runStage(cmd *cobra.Command, args []string) error {
 	writeAuditLog(missionDir, AuditStageSet, "cli", map[string]interface{}{
 		"stage": targetStage,
 	})
+	recordEvent(missionDir, eventstore.StageChanged, eventstore.StageData{From: currentState.Current, To: targetStage, Forced: force})
 
 	gitAutoCommit(missionDir, CommitCategoryStage, fmt.Sprintf("set %s", targetStage))
 
@@ -263,7 +267,7 @@ func advanceStageChecked(missionDir string, currentStage string, force bool, for
 	var stageTasks []Task
 	var completedTasks int
 	for _, t := range tasks {
-		if t.Stage == currentStage {
+		if t.Stage == currentStage && t.Status != bridge.TaskStatusArchived {
 			stageTasks = append(stageTasks, t)
 			if t.Status == "done" {
 				completedTasks++
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	stageCmd.AddCommand(stageRollbackCmd)
+	stageRollbackCmd.Flags().String("reason", "", "Why the stage is rolled back (required, logged to the audit trail)")
+}
+
+var stageRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Return to the previous stage and reopen its gate",
+	Long: `Rolls the mission back one stage, for when a gate was approved too early.
+The previous stage's gate is reopened with its approval cleared, and a
+checkpoint tagged "rollback" is taken of the rolled-back state.
+
+Refused while workers are running, since they were briefed for the stage
+being left.
+
+Example:
+  mc stage rollback --reason "design review missed the auth flow"`,
+	Args: cobra.NoArgs,
+	RunE: runStageRollback,
+}
+
+func runStageRollback(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+	if reason == "" {
+		return fmt.Errorf("--reason is required (so the audit trail captures why)")
+	}
+
+	stagePath := filepath.Join(missionDir, "state", "stage.json")
+	var state StageState
+	if err := readJSON(stagePath, &state); err != nil {
+		return fmt.Errorf("failed to read stage: %w", err)
+	}
+	from := state.Current
+	to := getPrevStage(from)
+	if to == "" {
+		return fmt.Errorf("cannot roll back from %q: there is no previous stage", from)
+	}
+
+	if running := liveWorkers(missionDir); len(running) > 0 {
+		return fmt.Errorf("workers %s are still running; wait for them or mc kill them before rolling back", strings.Join(running, ", "))
+	}
+
+	if err := reopenGate(missionDir, to); err != nil {
+		return fmt.Errorf("failed to reopen gate: %w", err)
+	}
+	if err := writeJSON(stagePath, StageState{
+		Current:        to,
+		UpdatedAt:      time.Now().UTC().Format(time.RFC3339),
+		RolledBackFrom: from,
+	}); err != nil {
+		return fmt.Errorf("failed to write stage: %w", err)
+	}
+
+	writeAuditLog(missionDir, AuditStageRolledBack, "cli", map[string]interface{}{
+		"from_stage": from,
+		"to_stage":   to,
+		"reason":     reason,
+	})
+	recordEvent(missionDir, eventstore.StageRolledBack, eventstore.StageData{From: from, To: to, Reason: reason})
+	gitAutoCommit(missionDir, CommitCategoryStage, fmt.Sprintf("rollback %s → %s", from, to))
+
+	// Compensating checkpoint of the rolled-back state
+	cp, err := createTaggedCheckpoint(missionDir, "", "rollback", fmt.Sprintf("rollback: %s → %s (%s)", from, to, reason))
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "⚠ Could not create rollback checkpoint: %v\n", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Stage rolled back: %s → %s (gate for %s reopened)\n", from, to, to)
+	if cp != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "Checkpoint created: %s\n", cp.ID)
+	}
+	return nil
+}
+
+// liveWorkers returns the IDs of the workers in workers.json that are
+// running, or handed off in_progress, and whose process is still alive.
+func liveWorkers(missionDir string) []string {
+	var state WorkersState
+	if err := readJSON(filepath.Join(missionDir, "state", "workers.json"), &state); err != nil {
+		return nil
+	}
+	var ids []string
+	for _, w := range state.Workers {
+		if (w.Status == "running" || w.Status == "in_progress") && isProcessAlive(w.PID) {
+			ids = append(ids, w.ID)
+		}
+	}
+	return ids
+}
+
+// reopenGate sets stage's gate in gates.json back to pending and clears
+// its approval, leaving the rest of the file as it is. A stage without a
+// gate is left alone.
+func reopenGate(missionDir, stage string) error {
+	path := filepath.Join(missionDir, "state", "gates.json")
+	var file map[string]interface{}
+	if err := readJSON(path, &file); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	gates, _ := file["gates"].(map[string]interface{})
+	gate, ok := gates[stage].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	gate["status"] = "pending"
+	delete(gate, "approved_at")
+	delete(gate, "approval_note")
+	delete(gate, "approved_by")
+	return writeJSON(path, file)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/spf13/cobra"
+)
+
+func TestStageRollback(t *testing.T) {
+	missionDir := setupStageTestMission(t, "goal", time.Now(), []Task{}, nil)
+	writeJSONFile(t, filepath.Join(missionDir, "state", "gates.json"), GatesState{Gates: map[string]Gate{
+		"discovery": {Stage: "discovery", Criteria: []string{"scope agreed"}, Status: "approved", ApprovedBy: "Alice", ApprovalNote: "lgtm"},
+	}})
+	writeJSONFile(t, filepath.Join(missionDir, "state", "workers.json"), WorkersState{Workers: []Worker{
+		{ID: "w1", Status: "running", PID: os.Getpid()},
+	}})
+	origDir, _ := os.Getwd()
+	os.Chdir(filepath.Dir(missionDir))
+	defer os.Chdir(origDir)
+
+	rollback := &cobra.Command{Use: "rollback", RunE: runStageRollback}
+	rollback.Flags().String("reason", "", "")
+	rollback.SetOut(io.Discard)
+
+	if err := rollback.RunE(rollback, nil); err == nil || !strings.Contains(err.Error(), "--reason") {
+		t.Errorf("Expected --reason to be required, got %v", err)
+	}
+	rollback.Flags().Set("reason", "approved too early")
+	if err := rollback.RunE(rollback, nil); err == nil || !strings.Contains(err.Error(), "w1") {
+		t.Fatalf("Expected rollback refused while w1 runs, got %v", err)
+	}
+
+	writeJSONFile(t, filepath.Join(missionDir, "state", "workers.json"), WorkersState{Workers: []Worker{
+		{ID: "w1", Status: "complete", PID: os.Getpid()},
+	}})
+	if err := rollback.RunE(rollback, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var stage StageState
+	readJSON(filepath.Join(missionDir, "state", "stage.json"), &stage)
+	if stage.Current != "discovery" || stage.RolledBackFrom != "goal" {
+		t.Errorf("stage = %+v", stage)
+	}
+	gates, _ := loadGates(missionDir)
+	if g := gates.Gates["discovery"]; g.Status != "pending" || g.ApprovedBy != "" || g.ApprovalNote != "" || len(g.Criteria) != 1 {
+		t.Errorf("discovery gate = %+v, want reopened with criteria kept", g)
+	}
+
+	cps, _ := filepath.Glob(filepath.Join(missionDir, "orchestrator", "checkpoints", "*.json"))
+	if len(cps) != 1 {
+		t.Fatalf("checkpoints = %v, want one", cps)
+	}
+	var cp CheckpointData
+	readJSON(cps[0], &cp)
+	if cp.Tag != "rollback" || cp.Stage != "discovery" {
+		t.Errorf("checkpoint = tag %q stage %q", cp.Tag, cp.Stage)
+	}
+
+	events, _, _ := eventstore.Read(missionDir, eventstore.Filter{Type: eventstore.StageRolledBack})
+	if len(events) != 1 {
+		t.Errorf("Expected one stage_rolled_back event, got %d", len(events))
+	}
+
+	if err := rollback.RunE(rollback, nil); err == nil {
+		t.Error("Expected error rolling back from the first stage")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
 	"github.com/spf13/cobra"
 )
 
@@ -18,8 +19,10 @@ func init() {
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show current MissionControl status",
-	Long:  `Displays the current stage, tasks, workers, and gate status.`,
-	RunE:  runStatus,
+	Long: `Displays the current stage, tasks, workers, and gate status. Worker,
+token and budget details from the orchestrator are included as "runtime"
+from the cache 'mc sync' last wrote, with when it was synced.`,
+	RunE: runStatus,
 }
 
 type Status struct {
@@ -27,6 +30,9 @@ type Status struct {
 	Tasks   TasksState   `json:"tasks"`
 	Workers WorkersState `json:"workers"`
 	Gates   GatesState   `json:"gates"`
+
+	// Runtime is the orchestrator state cached by mc sync, if any.
+	Runtime *RuntimeCache `json:"runtime,omitempty"`
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -76,6 +82,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		status.Gates.Gates[name] = Gate{Stage: name, Status: "pending", Criteria: cs}
 	}
 
+	if cache, ok := loadRuntimeCache(missionDir); ok {
+		status.Runtime = &cache
+	}
+
 	// Output as JSON
 	output, err := json.MarshalIndent(status, "", "  ")
 	if err != nil {
@@ -172,7 +182,7 @@ func printStatusSummary(missionDir string, cmd *cobra.Command) {
 	counts := map[string]int{}
 	total := 0
 	for _, t := range tasks {
-		if t.Stage == state.Current {
+		if t.Stage == state.Current && t.Status != bridge.TaskStatusArchived {
 			counts[t.Status]++
 			total++
 		}
@@ -185,7 +195,7 @@ func printStatusSummary(missionDir string, cmd *cobra.Command) {
 
 	// Tasks line — only show non-zero buckets
 	var parts []string
-	for _, s := range []string{"complete", "in_progress", "pending", "blocked"} {
+	for _, s := range []string{"complete", "in_progress", "queued", "pending", "blocked"} {
 		if c := counts[s]; c > 0 {
 			parts = append(parts, fmt.Sprintf("%d %s", c, s))
 		}
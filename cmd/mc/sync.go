@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/api"
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cacheDir         = "cache"
+	runtimeCacheFile = "runtime.json"
+)
+
+// chatSummaryMessages is how many recent chat messages mc sync summarises.
+const chatSummaryMessages = 200
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("url", "http://localhost:8080", "Orchestrator base URL")
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Cache the orchestrator's runtime state for offline use",
+	Long: `Copies the state only the running orchestrator holds (workers, token
+usage, the budget forecast and a summary of recent chat) into
+.mission/cache/runtime.json, so 'mc status' can show it when the
+orchestrator is down. The cache is kept out of git.
+
+To share the .mission state itself between machines through git, see
+'mc sync push' and 'mc sync pull'.
+
+MC_API_TOKEN is sent as the bearer token if set.
+
+Examples:
+  mc sync                              # From mc serve on localhost:8080
+  mc sync --url http://build-box:9000`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+// RuntimeCache is the orchestrator state cached by mc sync.
+type RuntimeCache struct {
+	SyncedAt string               `json:"synced_at"`
+	Source   string               `json:"source"`  // orchestrator URL
+	Version  uint64               `json:"version"` // event log position at the sync
+	Workers  interface{}          `json:"workers"`
+	Tokens   *tokens.TokenSummary `json:"tokens,omitempty"`
+	Budget   *budget.Forecast     `json:"budget,omitempty"`
+	Chat     []ChatSummary        `json:"chat,omitempty"`
+}
+
+// ChatSummary sums up one chat session's recent messages.
+type ChatSummary struct {
+	Session  string `json:"session"`
+	Messages int    `json:"messages"`
+	LastAt   string `json:"last_at"`
+	LastRole string `json:"last_role"`
+	Last     string `json:"last"` // first line of the last message, shortened
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	base, _ := cmd.Flags().GetString("url")
+
+	cache, err := fetchRuntime(strings.TrimRight(base, "/"), time.Now())
+	if err != nil {
+		if prev, ok := loadRuntimeCache(missionDir); ok {
+			return fmt.Errorf("%w (cache left as synced at %s)", err, prev.SyncedAt)
+		}
+		return err
+	}
+	if err := saveRuntimeCache(missionDir, cache); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	workers := 0
+	if list, ok := cache.Workers.([]interface{}); ok {
+		workers = len(list)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Synced %d worker(s), %d chat session(s) from %s\n", workers, len(cache.Chat), cache.Source)
+	return nil
+}
+
+// fetchRuntime reads the runtime state from the orchestrator at base. The
+// state snapshot is required; the forecast and chat are cached when
+// available.
+func fetchRuntime(base string, now time.Time) (RuntimeCache, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	cache := RuntimeCache{SyncedAt: now.UTC().Format(time.RFC3339), Source: base}
+
+	var state api.StateResponse
+	if err := getOrchestrator(client, base+"/api/state", &state); err != nil {
+		return cache, err
+	}
+	cache.Version = state.Version
+	cache.Workers = state.Workers
+	cache.Tokens = state.Tokens
+
+	var forecast budget.Forecast
+	if err := getOrchestrator(client, base+"/api/budgets/forecast", &forecast); err == nil {
+		cache.Budget = &forecast
+	}
+
+	var history struct {
+		Messages []chat.Message `json:"messages"`
+	}
+	if err := getOrchestrator(client, fmt.Sprintf("%s/api/chat/history?limit=%d", base, chatSummaryMessages), &history); err == nil {
+		cache.Chat = summarizeChat(history.Messages)
+	}
+	return cache, nil
+}
+
+func getOrchestrator(client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if token := os.Getenv(identity.EnvToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach the orchestrator: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// summarizeChat groups messages by session, most recently active first.
+func summarizeChat(messages []chat.Message) []ChatSummary {
+	bySession := map[string]*ChatSummary{}
+	for _, m := range messages {
+		s, ok := bySession[m.Session]
+		if !ok {
+			s = &ChatSummary{Session: m.Session}
+			bySession[m.Session] = s
+		}
+		s.Messages++
+		if m.Timestamp >= s.LastAt {
+			s.LastAt = m.Timestamp
+			s.LastRole = m.Role
+			last, _, _ := strings.Cut(strings.TrimSpace(m.Content), "\n")
+			if len(last) > 120 {
+				last = last[:117] + "..."
+			}
+			s.Last = last
+		}
+	}
+	var out []ChatSummary
+	for _, s := range bySession {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].LastAt != out[j].LastAt {
+			return out[i].LastAt > out[j].LastAt
+		}
+		return out[i].Session < out[j].Session
+	})
+	return out
+}
+
+// saveRuntimeCache writes cache to .mission/cache.
+func saveRuntimeCache(missionDir string, cache RuntimeCache) error {
+	dir, err := ensureCacheDir(missionDir)
+	if err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, runtimeCacheFile), cache)
+}
+
+// ensureCacheDir creates .mission/cache, which ignores itself in git so
+// auto-commits and mc sync push leave it out, and returns its path.
+func ensureCacheDir(missionDir string) (string, error) {
+	dir := filepath.Join(missionDir, cacheDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// loadRuntimeCache reads the cache mc sync last wrote.
+func loadRuntimeCache(missionDir string) (RuntimeCache, bool) {
+	var cache RuntimeCache
+	if err := readJSON(filepath.Join(missionDir, cacheDir, runtimeCacheFile), &cache); err != nil {
+		return RuntimeCache{}, false
+	}
+	return cache, true
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultSyncRemote = "origin"
+	defaultSyncBranch = "mission-state"
+	gitSyncFile       = "git-sync.json"
+	syncReportFile    = "sync-report.json"
+)
+
+func init() {
+	syncCmd.AddCommand(syncPushCmd, syncPullCmd)
+	for _, c := range []*cobra.Command{syncPushCmd, syncPullCmd} {
+		c.Flags().String("remote", defaultSyncRemote, "Git remote to sync with")
+		c.Flags().String("branch", defaultSyncBranch, "Branch the .mission state is kept on")
+	}
+	syncPullCmd.Flags().Bool("json", false, "Output the merge report as JSON")
+}
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push the .mission state to a git branch",
+	Long: `Commits the .mission directory (less git-ignored files such as
+.mission/cache) to a dedicated branch of a git remote, leaving the
+checked-out branch and its index alone. The branch holds the contents of
+.mission at its root.
+
+The push is refused if the remote branch has moved since this machine
+last pushed or pulled; run 'mc sync pull' first.
+
+Examples:
+  mc sync push
+  mc sync push --remote backup --branch mission-state`,
+	Args: cobra.NoArgs,
+	RunE: runSyncPush,
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Merge the .mission state from a git branch",
+	Long: `Fetches the state branch written by 'mc sync push' and merges it into
+the local .mission directory, against the state of the last sync:
+
+  - files changed on one side only take that side
+  - tasks.jsonl is merged task by task; a task changed on both sides
+    keeps the later updated_at (last writer wins)
+  - other JSONL logs changed on both sides keep the lines of both
+  - other files changed on both sides keep the later write
+
+Conflicts are listed in a merge report, also saved to
+.mission/cache/sync-report.json.
+
+Examples:
+  mc sync pull
+  mc sync pull --json`,
+	Args: cobra.NoArgs,
+	RunE: runSyncPull,
+}
+
+// GitSyncState records the state branch commit this machine last pushed or
+// pulled: the base later pulls merge against.
+type GitSyncState struct {
+	Remote   string `json:"remote"`
+	Branch   string `json:"branch"`
+	Base     string `json:"base"`
+	SyncedAt string `json:"synced_at"`
+}
+
+// SyncReport is the outcome of mc sync pull.
+type SyncReport struct {
+	Remote    string         `json:"remote"`
+	Branch    string         `json:"branch"`
+	Commit    string         `json:"commit"`
+	Updated   []string       `json:"updated"` // taken from the remote
+	Deleted   []string       `json:"deleted"` // deleted on the remote
+	Merged    []string       `json:"merged"`  // changed on both sides and merged
+	Conflicts []SyncConflict `json:"conflicts"`
+}
+
+// SyncConflict is a file, or a task in tasks.jsonl, changed on both sides.
+type SyncConflict struct {
+	Path   string `json:"path"`
+	Task   string `json:"task,omitempty"`
+	Winner string `json:"winner"` // "local" or "remote"
+	Local  string `json:"local"`  // when the local side was written
+	Remote string `json:"remote"` // when the remote side was written
+}
+
+// stateRepo runs git for the repository holding a .mission directory.
+type stateRepo struct {
+	missionDir string
+	remote     string
+	branch     string
+}
+
+func newStateRepo(cmd *cobra.Command) (*stateRepo, error) {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return nil, err
+	}
+	r := &stateRepo{missionDir: missionDir}
+	r.remote, _ = cmd.Flags().GetString("remote")
+	r.branch, _ = cmd.Flags().GetString("branch")
+	if _, err := r.git(nil, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf(".mission is not inside a git repository: %w", err)
+	}
+	return r, nil
+}
+
+func (r *stateRepo) git(env []string, args ...string) (string, error) {
+	c := exec.Command("git", append([]string{"-C", r.missionDir}, args...)...)
+	c.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// fetch returns the remote state branch's commit, or "" when the remote
+// has no such branch yet.
+func (r *stateRepo) fetch() (string, error) {
+	heads, err := r.git(nil, "ls-remote", "--heads", r.remote, r.branch)
+	if err != nil {
+		return "", err
+	}
+	if heads == "" {
+		return "", nil
+	}
+	ref := fmt.Sprintf("refs/mc-sync/%s/%s", r.remote, r.branch)
+	if _, err := r.git(nil, "fetch", "--quiet", r.remote, fmt.Sprintf("+refs/heads/%s:%s", r.branch, ref)); err != nil {
+		return "", err
+	}
+	return r.git(nil, "rev-parse", ref)
+}
+
+// snapshot writes the .mission directory to a tree through a scratch
+// index, honouring .gitignore files, and returns the tree's ID.
+func (r *stateRepo) snapshot() (string, error) {
+	index, err := os.CreateTemp("", "mc-sync-index-*")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	os.Remove(index.Name()) // git wants to create the index itself
+	defer os.Remove(index.Name())
+
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+	if _, err := r.git(env, "--work-tree", r.missionDir, "add", "-A", "--", "."); err != nil {
+		return "", err
+	}
+	return r.git(env, "--work-tree", r.missionDir, "write-tree")
+}
+
+// blobs lists the files of a tree or commit as path → blob ID.
+func (r *stateRepo) blobs(treeish string) (map[string]string, error) {
+	files := map[string]string{}
+	if treeish == "" {
+		return files, nil
+	}
+	out, err := r.git(nil, "ls-tree", "-r", "-z", "--full-tree", treeish)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range strings.Split(out, "\x00") {
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		if fields := strings.Fields(meta); len(fields) == 3 && fields[1] == "blob" {
+			files[path] = fields[2]
+		}
+	}
+	return files, nil
+}
+
+func (r *stateRepo) blob(id string) ([]byte, error) {
+	if id == "" {
+		return nil, nil
+	}
+	c := exec.Command("git", "-C", r.missionDir, "cat-file", "blob", id)
+	return c.Output()
+}
+
+func (r *stateRepo) loadState() GitSyncState {
+	var state GitSyncState
+	if err := readJSON(filepath.Join(r.missionDir, cacheDir, gitSyncFile), &state); err != nil ||
+		state.Remote != r.remote || state.Branch != r.branch {
+		return GitSyncState{Remote: r.remote, Branch: r.branch}
+	}
+	return state
+}
+
+func (r *stateRepo) saveState(base string) error {
+	dir, err := ensureCacheDir(r.missionDir)
+	if err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, gitSyncFile), GitSyncState{
+		Remote:   r.remote,
+		Branch:   r.branch,
+		Base:     base,
+		SyncedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func runSyncPush(cmd *cobra.Command, args []string) error {
+	r, err := newStateRepo(cmd)
+	if err != nil {
+		return err
+	}
+	tip, err := r.fetch()
+	if err != nil {
+		return err
+	}
+	if state := r.loadState(); tip != "" && tip != state.Base {
+		return fmt.Errorf("%s/%s has state this machine hasn't merged; run 'mc sync pull' first", r.remote, r.branch)
+	}
+
+	tree, err := r.snapshot()
+	if err != nil {
+		return err
+	}
+	if tip != "" {
+		if tipTree, err := r.git(nil, "rev-parse", tip+"^{tree}"); err == nil && tipTree == tree {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s/%s is up to date\n", r.remote, r.branch)
+			return r.saveState(tip)
+		}
+	}
+
+	host, _ := os.Hostname()
+	commitArgs := []string{"commit-tree", tree, "-m", "mc sync push from " + host}
+	if tip != "" {
+		commitArgs = append(commitArgs, "-p", tip)
+	}
+	commit, err := r.git(nil, commitArgs...)
+	if err != nil {
+		return err
+	}
+	if _, err := r.git(nil, "push", "--quiet", r.remote, commit+":refs/heads/"+r.branch); err != nil {
+		return fmt.Errorf("%w (if the branch moved meanwhile, run 'mc sync pull' and push again)", err)
+	}
+	if err := r.saveState(commit); err != nil {
+		return err
+	}
+
+	writeAuditLog(r.missionDir, AuditStateSynced, "cli", map[string]interface{}{
+		"direction": "push",
+		"remote":    r.remote,
+		"branch":    r.branch,
+		"commit":    commit,
+	})
+	fmt.Fprintf(cmd.OutOrStdout(), "Pushed .mission to %s/%s (%s)\n", r.remote, r.branch, shortID(commit))
+	return nil
+}
+
+func runSyncPull(cmd *cobra.Command, args []string) error {
+	r, err := newStateRepo(cmd)
+	if err != nil {
+		return err
+	}
+	jsonOut, _ := cmd.Flags().GetBool("json")
+	tip, err := r.fetch()
+	if err != nil {
+		return err
+	}
+	if tip == "" {
+		return fmt.Errorf("%s has no %s branch; run 'mc sync push' on the machine that has the state", r.remote, r.branch)
+	}
+	state := r.loadState()
+	if tip == state.Base {
+		fmt.Fprintf(cmd.OutOrStdout(), "Already up to date with %s/%s\n", r.remote, r.branch)
+		return nil
+	}
+
+	report, err := r.merge(state.Base, tip)
+	if err != nil {
+		return err
+	}
+	if err := r.saveState(tip); err != nil {
+		return err
+	}
+	if dir, err := ensureCacheDir(r.missionDir); err == nil {
+		_ = writeJSON(filepath.Join(dir, syncReportFile), report)
+	}
+
+	writeAuditLog(r.missionDir, AuditStateSynced, "cli", map[string]interface{}{
+		"direction": "pull",
+		"remote":    r.remote,
+		"branch":    r.branch,
+		"commit":    tip,
+		"updated":   len(report.Updated) + len(report.Deleted),
+		"merged":    len(report.Merged),
+		"conflicts": len(report.Conflicts),
+	})
+
+	if jsonOut {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	}
+	printSyncReport(cmd, report)
+	return nil
+}
+
+// merge merges the remote commit tip into the .mission directory, with
+// base as the common ancestor ("" when the two have never synced).
+func (r *stateRepo) merge(base, tip string) (SyncReport, error) {
+	report := SyncReport{Remote: r.remote, Branch: r.branch, Commit: tip,
+		Updated: []string{}, Deleted: []string{}, Merged: []string{}, Conflicts: []SyncConflict{}}
+
+	tree, err := r.snapshot()
+	if err != nil {
+		return report, err
+	}
+	baseFiles, err := r.blobs(base)
+	if err != nil {
+		return report, err
+	}
+	ours, err := r.blobs(tree)
+	if err != nil {
+		return report, err
+	}
+	theirs, err := r.blobs(tip)
+	if err != nil {
+		return report, err
+	}
+	remoteTime, err := r.git(nil, "show", "-s", "--format=%cI", tip)
+	if err != nil {
+		return report, err
+	}
+
+	paths := map[string]bool{}
+	for _, files := range []map[string]string{ours, theirs} {
+		for p := range files {
+			paths[p] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, p := range sorted {
+		o, t, b := ours[p], theirs[p], baseFiles[p]
+		local := filepath.Join(r.missionDir, filepath.FromSlash(p))
+		switch {
+		case o == t, t == b:
+			continue // same on both sides, or only changed here
+		case o == b:
+			if t == "" {
+				if err := os.Remove(local); err != nil && !os.IsNotExist(err) {
+					return report, err
+				}
+				report.Deleted = append(report.Deleted, p)
+				continue
+			}
+			if err := r.checkout(t, local); err != nil {
+				return report, err
+			}
+			report.Updated = append(report.Updated, p)
+			continue
+		}
+
+		// Changed on both sides
+		oData, err := r.blob(o)
+		if err != nil {
+			return report, err
+		}
+		tData, err := r.blob(t)
+		if err != nil {
+			return report, err
+		}
+		bData, err := r.blob(b)
+		if err != nil {
+			return report, err
+		}
+		switch {
+		case p == "state/"+tasksJSONLFile && o != "" && t != "":
+			merged, conflicts := mergeTasks(parseTaskLines(bData), parseTaskLines(oData), parseTaskLines(tData))
+			for i := range conflicts {
+				conflicts[i].Path = p
+			}
+			report.Conflicts = append(report.Conflicts, conflicts...)
+			if err := writeTasksJSONL(local, merged); err != nil {
+				return report, err
+			}
+			report.Merged = append(report.Merged, p)
+		case strings.HasSuffix(p, ".jsonl") && o != "" && t != "":
+			if err := os.WriteFile(local, unionLines(oData, tData), 0644); err != nil {
+				return report, err
+			}
+			report.Merged = append(report.Merged, p)
+		default:
+			conflict := SyncConflict{Path: p, Winner: "local", Remote: remoteTime}
+			if info, err := os.Stat(local); err == nil {
+				conflict.Local = info.ModTime().UTC().Format(time.RFC3339)
+			}
+			if laterThan(conflict.Remote, conflict.Local) {
+				conflict.Winner = "remote"
+				if t == "" {
+					if err := os.Remove(local); err != nil && !os.IsNotExist(err) {
+						return report, err
+					}
+				} else if err := r.checkout(t, local); err != nil {
+					return report, err
+				}
+			}
+			report.Conflicts = append(report.Conflicts, conflict)
+		}
+	}
+	return report, nil
+}
+
+// checkout writes blob id to path.
+func (r *stateRepo) checkout(id, path string) error {
+	data, err := r.blob(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// parseTaskLines reads tasks.jsonl content, a later line for a task
+// superseding earlier ones. Lines that don't parse are left out.
+func parseTaskLines(data []byte) []Task {
+	var tasks []Task
+	index := map[string]int{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var task Task
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &task) != nil {
+			continue
+		}
+		if i, seen := index[task.ID]; seen {
+			tasks[i] = task
+			continue
+		}
+		index[task.ID] = len(tasks)
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// mergeTasks merges two task lists against their common base. A task
+// changed on one side takes that side; changed on both, the later
+// updated_at wins (the local side on a tie) and the clash is reported.
+// A task deleted on one side and untouched on the other stays deleted.
+func mergeTasks(base, ours, theirs []Task) ([]Task, []SyncConflict) {
+	byID := func(tasks []Task) map[string]Task {
+		m := make(map[string]Task, len(tasks))
+		for _, t := range tasks {
+			m[t.ID] = t
+		}
+		return m
+	}
+	b, o, t := byID(base), byID(ours), byID(theirs)
+
+	order := make([]string, 0, len(ours)+len(theirs))
+	seen := map[string]bool{}
+	for _, list := range [][]Task{ours, theirs} {
+		for _, task := range list {
+			if !seen[task.ID] {
+				seen[task.ID] = true
+				order = append(order, task.ID)
+			}
+		}
+	}
+
+	var merged []Task
+	var conflicts []SyncConflict
+	for _, id := range order {
+		bt, inBase := b[id]
+		ot, inOurs := o[id]
+		tt, inTheirs := t[id]
+		switch {
+		case inOurs && inTheirs && sameTask(ot, tt):
+			merged = append(merged, ot)
+		case !inTheirs:
+			if !inBase || !sameTask(ot, bt) {
+				merged = append(merged, ot)
+			}
+		case !inOurs:
+			if !inBase || !sameTask(tt, bt) {
+				merged = append(merged, tt)
+			}
+		case inBase && sameTask(ot, bt):
+			merged = append(merged, tt)
+		case inBase && sameTask(tt, bt):
+			merged = append(merged, ot)
+		default:
+			c := SyncConflict{Task: id, Winner: "local", Local: ot.UpdatedAt, Remote: tt.UpdatedAt}
+			if laterThan(tt.UpdatedAt, ot.UpdatedAt) {
+				c.Winner = "remote"
+				merged = append(merged, tt)
+			} else {
+				merged = append(merged, ot)
+			}
+			conflicts = append(conflicts, c)
+		}
+	}
+	return merged, conflicts
+}
+
+func sameTask(a, b Task) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// laterThan reports whether RFC 3339 time a is after b. An unparseable
+// time loses to a parseable one.
+func laterThan(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	switch {
+	case errA != nil:
+		return false
+	case errB != nil:
+		return true
+	}
+	return ta.After(tb)
+}
+
+// unionLines keeps every line of ours, then the lines of theirs that ours
+// doesn't have, so both sides' log entries survive.
+func unionLines(ours, theirs []byte) []byte {
+	have := map[string]bool{}
+	var out bytes.Buffer
+	for _, line := range strings.Split(string(ours), "\n") {
+		if line == "" {
+			continue
+		}
+		have[line] = true
+		out.WriteString(line + "\n")
+	}
+	for _, line := range strings.Split(string(theirs), "\n") {
+		if line == "" || have[line] {
+			continue
+		}
+		have[line] = true
+		out.WriteString(line + "\n")
+	}
+	return out.Bytes()
+}
+
+func printSyncReport(cmd *cobra.Command, report SyncReport) {
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Pulled %s/%s (%s)\n", report.Remote, report.Branch, shortID(report.Commit))
+	for _, p := range report.Updated {
+		fmt.Fprintf(w, "  updated  %s\n", p)
+	}
+	for _, p := range report.Deleted {
+		fmt.Fprintf(w, "  deleted  %s\n", p)
+	}
+	for _, p := range report.Merged {
+		fmt.Fprintf(w, "  merged   %s\n", p)
+	}
+	if len(report.Conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%d conflict(s), last writer wins:\n", len(report.Conflicts))
+	for _, c := range report.Conflicts {
+		what := c.Path
+		if c.Task != "" {
+			what = fmt.Sprintf("%s task %s", c.Path, c.Task)
+		}
+		fmt.Fprintf(w, "  %s: kept %s (local %s, remote %s)\n", what, c.Winner, orDash(c.Local), orDash(c.Remote))
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestSyncPushPull(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(v, "mc")
+	}
+	for _, v := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(v, "mc@example.com")
+	}
+
+	remote := t.TempDir()
+	gitIn(t, remote, "init", "--quiet", "--bare")
+
+	// Machine A has the mission
+	dirA, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	gitIn(t, dirA, "init", "--quiet")
+	gitIn(t, dirA, "remote", "add", "origin", remote)
+	tasksA := filepath.Join(dirA, ".mission", "state", tasksJSONLFile)
+	if err := writeTasksJSONL(tasksA, []Task{
+		{ID: "t1", Name: "Design", Status: "pending", UpdatedAt: "2026-01-01T10:00:00Z"},
+		{ID: "t2", Name: "Build", Status: "pending", UpdatedAt: "2026-01-01T10:00:00Z"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dirA, ".mission", "cache"), 0755)
+	os.WriteFile(filepath.Join(dirA, ".mission", "cache", ".gitignore"), []byte("*\n"), 0644)
+	os.WriteFile(filepath.Join(dirA, ".mission", "cache", runtimeCacheFile), []byte("{}"), 0644)
+
+	newCmd := func(run func(*cobra.Command, []string) error) *cobra.Command {
+		c := &cobra.Command{RunE: run}
+		c.Flags().String("remote", defaultSyncRemote, "")
+		c.Flags().String("branch", defaultSyncBranch, "")
+		c.Flags().Bool("json", false, "")
+		c.SetOut(io.Discard)
+		return c
+	}
+	push := newCmd(runSyncPush)
+	pull := newCmd(runSyncPull)
+
+	if err := push.RunE(push, nil); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := exec.Command("git", "-C", remote, "ls-tree", "-r", "--name-only", defaultSyncBranch).Output()
+	if !strings.Contains(string(out), "state/"+tasksJSONLFile) || strings.Contains(string(out), runtimeCacheFile) {
+		t.Errorf("unexpected branch contents:\n%s", out)
+	}
+
+	// Machine B pulls it fresh
+	dirB := t.TempDir()
+	gitIn(t, dirB, "init", "--quiet")
+	gitIn(t, dirB, "remote", "add", "origin", remote)
+	os.MkdirAll(filepath.Join(dirB, ".mission", "state"), 0755)
+	os.Chdir(dirB)
+	if err := pull.RunE(pull, nil); err != nil {
+		t.Fatal(err)
+	}
+	tasksB := filepath.Join(dirB, ".mission", "state", tasksJSONLFile)
+	if tasks, err := readTasksJSONL(tasksB); err != nil || len(tasks) != 2 {
+		t.Fatalf("expected both tasks pulled, got %v, %v", tasks, err)
+	}
+
+	// B changes t1 later than A does; both change different fields of t2
+	writeTasksJSONL(tasksB, []Task{
+		{ID: "t1", Name: "Design", Status: "done", UpdatedAt: "2026-01-02T12:00:00Z"},
+		{ID: "t2", Name: "Build", Status: "pending", UpdatedAt: "2026-01-01T10:00:00Z"},
+		{ID: "t3", Name: "Docs", Status: "pending", UpdatedAt: "2026-01-02T12:00:00Z"},
+	})
+	if err := push.RunE(push, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Chdir(dirA)
+	writeTasksJSONL(tasksA, []Task{
+		{ID: "t1", Name: "Design", Status: "in_progress", UpdatedAt: "2026-01-02T09:00:00Z"},
+		{ID: "t2", Name: "Build", Status: "in_progress", UpdatedAt: "2026-01-02T09:00:00Z"},
+	})
+	if err := push.RunE(push, nil); err == nil || !strings.Contains(err.Error(), "mc sync pull") {
+		t.Fatalf("expected the push refused until A pulls, got %v", err)
+	}
+
+	var report strings.Builder
+	pull.SetOut(&report)
+	pull.Flags().Set("json", "true")
+	if err := pull.RunE(pull, nil); err != nil {
+		t.Fatal(err)
+	}
+	var r SyncReport
+	if err := json.Unmarshal([]byte(report.String()), &r); err != nil {
+		t.Fatalf("bad report %q: %v", report.String(), err)
+	}
+	if len(r.Conflicts) != 1 || r.Conflicts[0].Task != "t1" || r.Conflicts[0].Winner != "remote" {
+		t.Errorf("expected t1 reported with the remote winning, got %+v", r.Conflicts)
+	}
+
+	tasks, err := readTasksJSONL(tasksA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := map[string]string{}
+	for _, task := range tasks {
+		status[task.ID] = task.Status
+	}
+	if status["t1"] != "done" || status["t2"] != "in_progress" || status["t3"] != "pending" {
+		t.Errorf("unexpected merge: %v", status)
+	}
+
+	// With the remote merged, A can push again
+	if err := push.RunE(push, nil); err != nil {
+		t.Errorf("expected the push after pulling to work, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSyncCachesRuntime(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	var auth string
+	orch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/api/state":
+			io.WriteString(w, `{"version": 7, "workers": [{"id": "w1"}], "tokens": {"total_tokens": 1200}}`)
+		case "/api/budgets/forecast":
+			io.WriteString(w, `{"method": "backlog", "over_budget": true}`)
+		case "/api/chat/history":
+			io.WriteString(w, `{"messages": [
+				{"seq": 1, "session": "main", "role": "user", "content": "status?", "timestamp": "2026-01-01T10:00:00Z"},
+				{"seq": 2, "session": "main", "role": "assistant", "content": "All green\nDetails follow", "timestamp": "2026-01-01T10:01:00Z"},
+				{"seq": 3, "session": "ops", "role": "user", "content": "deploy", "timestamp": "2026-01-01T09:00:00Z"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Setenv("MC_API_TOKEN", "secret")
+
+	sync := &cobra.Command{Use: "sync", RunE: runSync}
+	sync.Flags().String("url", orch.URL+"/", "")
+	sync.SetOut(io.Discard)
+	if err := sync.RunE(sync, nil); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("expected the API token sent, got %q", auth)
+	}
+
+	cache, ok := loadRuntimeCache(missionDir)
+	if !ok {
+		t.Fatal("expected a cache")
+	}
+	if cache.Version != 7 || cache.Tokens == nil || cache.Tokens.TotalTokens != 1200 {
+		t.Errorf("unexpected cache: %+v", cache)
+	}
+	if workers, _ := cache.Workers.([]interface{}); len(workers) != 1 {
+		t.Errorf("expected one worker, got %v", cache.Workers)
+	}
+	if cache.Budget == nil || !cache.Budget.OverBudget {
+		t.Errorf("expected the forecast cached, got %+v", cache.Budget)
+	}
+	if len(cache.Chat) != 2 || cache.Chat[0].Session != "main" || cache.Chat[0].Messages != 2 || cache.Chat[0].Last != "All green" {
+		t.Errorf("unexpected chat summary: %+v", cache.Chat)
+	}
+	if data, _ := os.ReadFile(filepath.Join(missionDir, "cache", ".gitignore")); string(data) != "*\n" {
+		t.Errorf("expected the cache ignored by git, got %q", data)
+	}
+
+	// With the orchestrator down the cache stays as it was
+	orch.Close()
+	if err := sync.RunE(sync, nil); err == nil {
+		t.Error("expected an error with the orchestrator down")
+	}
+	if again, _ := loadRuntimeCache(missionDir); again.SyncedAt != cache.SyncedAt || again.Version != 7 {
+		t.Errorf("expected the cache kept, got %+v", again)
+	}
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
 	"github.com/MikeSquared-Agency/MissionControl/hashid"
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
 	"github.com/spf13/cobra"
 )
 
@@ -19,23 +24,38 @@ func init() {
 	taskCmd.AddCommand(taskListCmd)
 	taskCmd.AddCommand(taskUpdateCmd)
 	taskCmd.AddCommand(taskDepsCmd)
+	taskCmd.AddCommand(taskDepCmd)
+	taskDepCmd.AddCommand(taskDepAddCmd)
+	taskDepCmd.AddCommand(taskDepRemoveCmd)
 	rootCmd.AddCommand(queueCmd)
 
 	// task create flags
 	taskCreateCmd.Flags().StringP("stage", "s", "", "Stage for the task")
 	taskCreateCmd.Flags().StringP("zone", "z", "", "Zone for the task")
 	taskCreateCmd.Flags().String("persona", "", "Persona to assign")
+	taskCreateCmd.Flags().String("assignee", "", "Person to assign (persona defaults to manual)")
 	taskCreateCmd.Flags().StringSlice("depends-on", nil, "Task IDs this task depends on")
 	taskCreateCmd.Flags().Bool("force", false, "Bypass stage validation")
 	taskCreateCmd.Flags().String("scope-paths", "", "Comma-separated list of file paths in scope for this task")
+	taskCreateCmd.Flags().Float64("estimate", 0, "Estimate in points or hours, used for the critical path and burn-down (default 1)")
+	taskCreateCmd.Flags().StringSliceP("label", "l", nil, "Labels for the task (e.g. tech-debt,security)")
+	taskCreateCmd.Flags().String("spec", "", "ID of the spec in .mission/specs/ the task implements")
+	taskCreateCmd.Flags().String("override-matrix", "", "Create the task though the matrix doesn't enable its persona for the stage and zone, with this justification (logged to the audit trail)")
 
 	// task list flags
 	taskListCmd.Flags().String("stage", "", "Filter by stage")
 	taskListCmd.Flags().StringP("status", "s", "", "Filter by status")
 	taskListCmd.Flags().Bool("ready", false, "Show only tasks ready to work on (pending + all deps met)")
+	taskListCmd.Flags().Bool("all", false, "Include archived tasks")
+	taskListCmd.Flags().StringSliceP("label", "l", nil, "Show only tasks with all of these labels")
+	taskListCmd.Flags().String("assignee", "", "Show only tasks assigned to this person")
 
 	// task update flags
 	taskUpdateCmd.Flags().StringP("status", "s", "", "New status")
+	taskUpdateCmd.Flags().Float64("estimate", 0, "New estimate (points or hours)")
+	taskUpdateCmd.Flags().StringSlice("add-label", nil, "Labels to add")
+	taskUpdateCmd.Flags().StringSlice("remove-label", nil, "Labels to remove")
+	taskUpdateCmd.Flags().String("assignee", "", "Person to assign (empty to unassign)")
 
 	// task deps flags
 	taskDepsCmd.Flags().Bool("tree", false, "Show ASCII dependency tree")
@@ -74,6 +94,27 @@ var taskDepsCmd = &cobra.Command{
 	RunE:  runTaskDeps,
 }
 
+var taskDepCmd = &cobra.Command{
+	Use:   "dep",
+	Short: "Add or remove a task dependency",
+}
+
+var taskDepAddCmd = &cobra.Command{
+	Use:   "add <task-id> <dep-id>",
+	Short: "Make a task depend on another",
+	Long: `Makes <task-id> depend on <dep-id>. Both tasks must exist, and the
+dependency is rejected if it would create a cycle.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTaskDepAdd,
+}
+
+var taskDepRemoveCmd = &cobra.Command{
+	Use:   "remove <task-id> <dep-id>",
+	Short: "Remove a task dependency",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTaskDepRemove,
+}
+
 var queueCmd = &cobra.Command{
 	Use:   "queue",
 	Short: "Show tasks ready to be worked on",
@@ -98,6 +139,11 @@ func runTaskCreate(cmd *cobra.Command, args []string) error {
 	stage, _ := cmd.Flags().GetString("stage")
 	zone, _ := cmd.Flags().GetString("zone")
 	persona, _ := cmd.Flags().GetString("persona")
+	assignee, _ := cmd.Flags().GetString("assignee")
+	assignee = strings.TrimSpace(assignee)
+	if assignee != "" && persona == "" {
+		persona = bridge.PersonaManual
+	}
 	dependsOn, _ := cmd.Flags().GetStringSlice("depends-on")
 	scopePathsStr, _ := cmd.Flags().GetString("scope-paths")
 	var scopePaths []string
@@ -110,82 +156,115 @@ func runTaskCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	force, _ := cmd.Flags().GetBool("force")
+	estimate, _ := cmd.Flags().GetFloat64("estimate")
+	if estimate < 0 {
+		return fmt.Errorf("--estimate must not be negative")
+	}
+	labelFlags, _ := cmd.Flags().GetStringSlice("label")
+	labels, err := bridge.NormalizeLabels(labelFlags)
+	if err != nil {
+		return err
+	}
 
-	// Read current stage
-	var currentStage string
-	var stageState StageState
-	stagePath := filepath.Join(missionDir, "state", "stage.json")
-	if err := readJSON(stagePath, &stageState); err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("failed to read stage: %w", err)
+	force, _ := cmd.Flags().GetBool("force")
+	overrideMatrix, _ := cmd.Flags().GetString("override-matrix")
+
+	specID, _ := cmd.Flags().GetString("spec")
+	var specHash string
+	if specID != "" {
+		spec, err := specs.Get(missionDir, specID)
+		if err != nil {
+			return err
 		}
-	} else {
-		currentStage = stageState.Current
+		if spec.Status != specs.StatusApproved {
+			fmt.Fprintf(cmd.ErrOrStderr(), "⚠ Warning: spec %s is %s, not approved\n", spec.ID, spec.Status)
+		} else if spec.Modified {
+			fmt.Fprintf(cmd.ErrOrStderr(), "⚠ Warning: spec %s has changed since version %d was approved\n", spec.ID, spec.Version)
+		}
+		specHash = spec.CurrentHash
+	}
+
+	currentStage, err := loadCurrentStage(missionDir)
+	if err != nil {
+		return err
 	}
 
 	// Default task stage to current stage if not specified
 	if stage == "" {
 		stage = currentStage
 	}
-
-	// Validate: reject tasks for stages ahead of current stage
-	if currentStage != "" && stage != "" {
-		taskIdx := stageIndex(stage)
-		curIdx := stageIndex(currentStage)
-		if taskIdx > curIdx && curIdx >= 0 && taskIdx >= 0 {
-			if !force {
-				return fmt.Errorf("cannot create task for stage %q — current stage is %q.\n       Advance to %q first, or use --force to bypass", stage, currentStage, stage)
-			}
-			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: creating task for future stage %q (current: %q). This bypasses progressive refinement.\n", stage, currentStage)
-		}
+	if err := checkTaskStage(stage, currentStage, force, cmd.ErrOrStderr()); err != nil {
+		return err
 	}
-
-	tasks, err := loadTasks(missionDir)
+	matrix, err := loadMatrix(missionDir)
 	if err != nil {
-		return fmt.Errorf("failed to read tasks: %w", err)
+		return err
+	}
+	overridden, err := checkMatrix(matrix, stage, zone, persona, overrideMatrix)
+	if err != nil {
+		return err
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
 	taskID := hashid.Generate("task", name, stage, zone, persona)
-
-	// Check for duplicate IDs
-	for _, existing := range tasks {
-		if existing.ID == taskID {
-			return fmt.Errorf("task with this ID already exists: %s (name=%q)", taskID, existing.Name)
-		}
-	}
-
 	task := Task{
 		ID:         taskID,
 		Name:       name,
 		Stage:      stage,
 		Zone:       zone,
 		Persona:    persona,
+		Assignee:   assignee,
 		Status:     "pending",
 		DependsOn:  dependsOn,
 		ScopePaths: scopePaths,
+		Estimate:   estimate,
+		Labels:     labels,
+		Spec:       specID,
+		SpecHash:   specHash,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
 
-	tasks = append(tasks, task)
-
-	if err := saveTasks(missionDir, tasks); err != nil {
+	var rejected error
+	err = updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+		for _, existing := range tasks {
+			if existing.ID == taskID {
+				rejected = fmt.Errorf("task with this ID already exists: %s (name=%q)", taskID, existing.Name)
+				return nil, rejected
+			}
+		}
+		tasks = append(tasks, task)
+		rejected = checkTaskDeps(tasks)
+		return tasks, rejected
+	})
+	if rejected != nil {
+		return rejected
+	}
+	if err != nil {
 		return fmt.Errorf("failed to write tasks: %w", err)
 	}
 
 	writeAuditLog(missionDir, AuditTaskCreated, "cli", map[string]interface{}{
-		"task_id": task.ID,
-		"name":    task.Name,
-		"stage":   task.Stage,
-		"zone":    task.Zone,
-		"persona": task.Persona,
+		"task_id":  task.ID,
+		"name":     task.Name,
+		"stage":    task.Stage,
+		"zone":     task.Zone,
+		"persona":  task.Persona,
+		"assignee": task.Assignee,
+		"labels":   task.Labels,
+		"spec":     task.Spec,
 	})
+	if overridden {
+		auditMatrixOverride(missionDir, task.Stage, task.Zone, task.Persona, overrideMatrix, map[string]interface{}{"task_id": task.ID})
+	}
 
 	// Auto-commit
 	gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("create", task.ID, task.Name))
 
+	if task.Assignee != "" {
+		notifyAssignee(missionDir, task, cmd.ErrOrStderr())
+	}
+
 	// Output task as JSON
 	output, _ := json.MarshalIndent(task, "", "  ")
 	fmt.Println(string(output))
@@ -193,6 +272,37 @@ func runTaskCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// loadCurrentStage returns the current stage from stage.json, or "" if
+// none is set.
+func loadCurrentStage(missionDir string) (string, error) {
+	var stageState StageState
+	stagePath := filepath.Join(missionDir, "state", "stage.json")
+	if err := readJSON(stagePath, &stageState); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read stage: %w", err)
+		}
+		return "", nil
+	}
+	return stageState.Current, nil
+}
+
+// checkTaskStage rejects tasks for stages ahead of the current stage unless
+// force is set, in which case it warns on w.
+func checkTaskStage(stage, currentStage string, force bool, w io.Writer) error {
+	if currentStage == "" || stage == "" {
+		return nil
+	}
+	taskIdx := stageIndex(stage)
+	curIdx := stageIndex(currentStage)
+	if taskIdx > curIdx && curIdx >= 0 && taskIdx >= 0 {
+		if !force {
+			return fmt.Errorf("cannot create task for stage %q — current stage is %q.\n       Advance to %q first, or use --force to bypass", stage, currentStage, stage)
+		}
+		fmt.Fprintf(w, "Warning: creating task for future stage %q (current: %q). This bypasses progressive refinement.\n", stage, currentStage)
+	}
+	return nil
+}
+
 func runTaskList(cmd *cobra.Command, args []string) error {
 	missionDir, err := findMissionDir()
 	if err != nil {
@@ -202,6 +312,13 @@ func runTaskList(cmd *cobra.Command, args []string) error {
 	stageFilter, _ := cmd.Flags().GetString("stage")
 	statusFilter, _ := cmd.Flags().GetString("status")
 	readyOnly, _ := cmd.Flags().GetBool("ready")
+	showAll, _ := cmd.Flags().GetBool("all")
+	assigneeFilter, _ := cmd.Flags().GetString("assignee")
+	labelFlags, _ := cmd.Flags().GetStringSlice("label")
+	labels, err := bridge.NormalizeLabels(labelFlags)
+	if err != nil {
+		return err
+	}
 
 	tasks, err := loadTasks(missionDir)
 	if err != nil {
@@ -219,9 +336,18 @@ func runTaskList(cmd *cobra.Command, args []string) error {
 		if statusFilter != "" && task.Status != statusFilter {
 			continue
 		}
+		if statusFilter == "" && !showAll && task.Status == bridge.TaskStatusArchived {
+			continue
+		}
 		if readyOnly && !isReady(task, taskMap) {
 			continue
 		}
+		if !hasLabels(task, labels) {
+			continue
+		}
+		if assigneeFilter != "" && task.Assignee != assigneeFilter {
+			continue
+		}
 		filtered = append(filtered, task)
 	}
 
@@ -240,58 +366,273 @@ func runTaskUpdate(cmd *cobra.Command, args []string) error {
 
 	taskID := args[0]
 	newStatus, _ := cmd.Flags().GetString("status")
+	setEstimate := cmd.Flags().Changed("estimate")
+	estimate, _ := cmd.Flags().GetFloat64("estimate")
+	addFlags, _ := cmd.Flags().GetStringSlice("add-label")
+	removeFlags, _ := cmd.Flags().GetStringSlice("remove-label")
+	setAssignee := cmd.Flags().Changed("assignee")
+	assignee, _ := cmd.Flags().GetString("assignee")
+	assignee = strings.TrimSpace(assignee)
 
-	if newStatus == "" {
-		return fmt.Errorf("--status is required")
+	if newStatus == "" && !setEstimate && len(addFlags) == 0 && len(removeFlags) == 0 && !setAssignee {
+		return fmt.Errorf("--status, --estimate, --add-label, --remove-label or --assignee is required")
 	}
-
-	tasks, err := loadTasks(missionDir)
+	if estimate < 0 {
+		return fmt.Errorf("--estimate must not be negative")
+	}
+	addLabels, err := bridge.NormalizeLabels(addFlags)
 	if err != nil {
-		return fmt.Errorf("failed to read tasks: %w", err)
+		return err
+	}
+	removeLabels, err := bridge.NormalizeLabels(removeFlags)
+	if err != nil {
+		return err
 	}
 
 	found := false
-	var oldStatus string
-	for i := range tasks {
-		if tasks[i].ID == taskID {
-			oldStatus = tasks[i].Status
-			tasks[i].Status = newStatus
-			tasks[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-			found = true
-
-			output, _ := json.MarshalIndent(tasks[i], "", "  ")
-			fmt.Println(string(output))
-			break
+	var oldStatus, oldAssignee string
+	var updated Task
+	err = updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+		for i := range tasks {
+			if tasks[i].ID == taskID {
+				oldStatus = tasks[i].Status
+				oldAssignee = tasks[i].Assignee
+				if newStatus != "" {
+					tasks[i].Status = newStatus
+				}
+				if setEstimate {
+					tasks[i].Estimate = estimate
+				}
+				tasks[i].Labels = updateLabels(tasks[i].Labels, addLabels, removeLabels)
+				if setAssignee {
+					tasks[i].Assignee = assignee
+				}
+				tasks[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+				found = true
+				updated = tasks[i]
+				return tasks, nil
+			}
 		}
+		return nil, errNoChange
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tasks: %w", err)
 	}
-
 	if !found {
 		return fmt.Errorf("task not found: %s", taskID)
 	}
 
-	if err := saveTasks(missionDir, tasks); err != nil {
-		return fmt.Errorf("failed to write tasks: %w", err)
-	}
+	output, _ := json.MarshalIndent(updated, "", "  ")
+	fmt.Println(string(output))
 
 	// Audit after successful persistence
 	auditAction := AuditTaskUpdated
 	if newStatus == "done" {
 		auditAction = AuditTaskCompleted
 	}
-	writeAuditLog(missionDir, auditAction, "cli", map[string]interface{}{
+	details := map[string]interface{}{
 		"task_id":    taskID,
 		"old_status": oldStatus,
 		"new_status": newStatus,
-	})
+	}
+	if newStatus == "" {
+		details["new_status"] = oldStatus
+	}
+	if setEstimate {
+		details["estimate"] = estimate
+	}
+	if len(addLabels) > 0 {
+		details["labels_added"] = addLabels
+	}
+	if len(removeLabels) > 0 {
+		details["labels_removed"] = removeLabels
+	}
+	if setAssignee && assignee != oldAssignee {
+		details["old_assignee"] = oldAssignee
+		details["assignee"] = assignee
+	}
+	writeAuditLog(missionDir, auditAction, "cli", details)
 
 	// Auto-commit
-	gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("update", taskID, newStatus))
+	gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("update", taskID, fmt.Sprint(details["new_status"])))
+
+	if setAssignee && assignee != "" && assignee != oldAssignee {
+		notifyAssignee(missionDir, updated, cmd.ErrOrStderr())
+	}
 
 	printStatusSummary(missionDir, cmd)
 
 	return nil
 }
 
+func runTaskDepAdd(cmd *cobra.Command, args []string) error {
+	return updateTaskDep(args[0], args[1], true)
+}
+
+func runTaskDepRemove(cmd *cobra.Command, args []string) error {
+	return updateTaskDep(args[0], args[1], false)
+}
+
+// updateTaskDep adds or removes the dependency of taskID on depID.
+func updateTaskDep(taskID, depID string, add bool) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	var task Task
+	var rejected error
+	unchanged := false
+	err = updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+		idx := -1
+		depFound := false
+		for i := range tasks {
+			if tasks[i].ID == taskID {
+				idx = i
+			}
+			if tasks[i].ID == depID {
+				depFound = true
+			}
+		}
+		if idx < 0 {
+			rejected = fmt.Errorf("task not found: %s", taskID)
+			return nil, rejected
+		}
+		t := &tasks[idx]
+
+		var kept []string
+		for _, d := range t.DependsOn {
+			if d != depID {
+				kept = append(kept, d)
+			}
+		}
+		if add {
+			if !depFound {
+				rejected = fmt.Errorf("task not found: %s", depID)
+				return nil, rejected
+			}
+			if len(kept) < len(t.DependsOn) {
+				unchanged = true
+				return nil, errNoChange
+			}
+			t.DependsOn = append(t.DependsOn, depID)
+			if rejected = checkTaskDeps(tasks); rejected != nil {
+				return nil, rejected
+			}
+		} else {
+			if len(kept) == len(t.DependsOn) {
+				rejected = fmt.Errorf("%s does not depend on %s", taskID, depID)
+				return nil, rejected
+			}
+			t.DependsOn = kept
+		}
+		t.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		task = *t
+		return tasks, nil
+	})
+	if rejected != nil {
+		return rejected
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write tasks: %w", err)
+	}
+	if unchanged {
+		fmt.Printf("%s already depends on %s\n", taskID, depID)
+		return nil
+	}
+
+	action := "add"
+	if !add {
+		action = "remove"
+	}
+	writeAuditLog(missionDir, AuditTaskUpdated, "cli", map[string]interface{}{
+		"task_id":    taskID,
+		"dependency": depID,
+		"action":     action,
+	})
+	gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("dep "+action, taskID, shortID(depID)))
+
+	output, _ := json.MarshalIndent(task, "", "  ")
+	fmt.Println(string(output))
+	return nil
+}
+
+// checkTaskDeps returns an error naming the cycle if the tasks' dependencies
+// have one, which would leave every task on it blocked forever.
+func checkTaskDeps(tasks []Task) error {
+	deps := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		deps[t.ID] = t.DependsOn
+	}
+	return bridge.CheckDependencies(deps)
+}
+
+// notifyAssignee sends a task_assigned event to the project's webhooks.
+// Delivery is best effort: failures are only reported on w.
+func notifyAssignee(missionDir string, task Task, w io.Writer) {
+	cfg, err := bridge.LoadProjectConfig(filepath.Dir(missionDir))
+	if err != nil || len(cfg.Webhooks) == 0 {
+		return
+	}
+	err = webhook.Send(context.Background(), cfg.Webhooks, webhook.EventTaskAssigned, map[string]interface{}{
+		"task_id":  task.ID,
+		"name":     task.Name,
+		"stage":    task.Stage,
+		"zone":     task.Zone,
+		"persona":  task.Persona,
+		"assignee": task.Assignee,
+	})
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to notify %s: %v\n", task.Assignee, err)
+	}
+}
+
+// errManualTask refuses to spawn a worker for a task meant for a person.
+func errManualTask(task Task) error {
+	if task.Persona != bridge.PersonaManual {
+		return nil
+	}
+	who := task.Assignee
+	if who == "" {
+		who = "a person"
+	}
+	return fmt.Errorf("task %s is a manual task for %s: no worker is spawned", task.ID, who)
+}
+
+// hasLabels reports whether task carries every one of labels.
+func hasLabels(task Task, labels []string) bool {
+	for _, want := range labels {
+		found := false
+		for _, l := range task.Labels {
+			if l == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// updateLabels returns labels with add appended, where missing, and remove
+// taken out.
+func updateLabels(labels, add, remove []string) []string {
+	drop := map[string]bool{}
+	for _, l := range remove {
+		drop[l] = true
+	}
+	var out []string
+	for _, l := range append(append([]string{}, labels...), add...) {
+		if !drop[l] {
+			drop[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
 // buildTaskMap creates a lookup map of task ID to Task.
 func buildTaskMap(tasks []Task) map[string]Task {
 	m := make(map[string]Task, len(tasks))
@@ -364,6 +705,8 @@ func printDepTree(task Task, taskMap map[string]Task, prefix string, isLast bool
 		statusIcon = "●"
 	case "in_progress":
 		statusIcon = "◐"
+	case "queued":
+		statusIcon = "◌"
 	case "blocked":
 		statusIcon = "✕"
 	}
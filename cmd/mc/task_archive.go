@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	taskCmd.AddCommand(taskArchiveCmd)
+	taskCmd.AddCommand(taskUnarchiveCmd)
+	taskCmd.AddCommand(taskCompactCmd)
+
+	taskArchiveCmd.Flags().String("reason", "", "Why the task is archived (e.g. cancelled, duplicate of <id>)")
+	taskArchiveCmd.Flags().Bool("force", false, "Archive even if other tasks still depend on it")
+}
+
+var taskArchiveCmd = &cobra.Command{
+	Use:   "archive <task-id>",
+	Short: "Archive a cancelled or duplicate task",
+	Long: `Archives a task. Archived tasks stay in tasks.jsonl but are left out of
+mc task list, the task graph, gate checks and status counts.
+
+A task that unarchived tasks still depend on is only archived with --force.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskArchive,
+}
+
+var taskUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive <task-id>",
+	Short: "Restore an archived task",
+	Long:  `Restores an archived task to the status it had when it was archived.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskUnarchive,
+}
+
+var taskCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Drop superseded entries from tasks.jsonl",
+	Long: `Rewrites tasks.jsonl keeping only the last entry for each task. Extra
+entries can be left behind by git merges or hand edits; mc serve also
+compacts the file periodically.`,
+	Args: cobra.NoArgs,
+	RunE: runTaskCompact,
+}
+
+func runTaskArchive(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	taskID := args[0]
+	reason, _ := cmd.Flags().GetString("reason")
+	force, _ := cmd.Flags().GetBool("force")
+
+	var task Task
+	var oldStatus string
+	var rejected error
+	err = updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+		idx := -1
+		var dependents []string
+		for i, t := range tasks {
+			if t.ID == taskID {
+				idx = i
+				continue
+			}
+			if t.Status == bridge.TaskStatusArchived {
+				continue
+			}
+			for _, d := range t.DependsOn {
+				if d == taskID {
+					dependents = append(dependents, t.ID)
+				}
+			}
+		}
+		switch {
+		case idx < 0:
+			rejected = fmt.Errorf("task not found: %s", taskID)
+		case tasks[idx].Status == bridge.TaskStatusArchived:
+			rejected = fmt.Errorf("task %s is already archived", taskID)
+		case len(dependents) > 0 && !force:
+			rejected = fmt.Errorf("tasks %s depend on it; remove the dependencies or use --force", strings.Join(dependents, ", "))
+		}
+		if rejected != nil {
+			return nil, rejected
+		}
+
+		t := &tasks[idx]
+		oldStatus = t.Status
+		t.ArchivedFrom = oldStatus
+		t.ArchiveReason = reason
+		t.Status = bridge.TaskStatusArchived
+		t.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		task = *t
+		return tasks, nil
+	})
+	if rejected != nil {
+		return rejected
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write tasks: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"task_id":    taskID,
+		"old_status": oldStatus,
+		"new_status": bridge.TaskStatusArchived,
+	}
+	if reason != "" {
+		details["reason"] = reason
+	}
+	writeAuditLog(missionDir, AuditTaskArchived, "cli", details)
+	gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("archive", taskID, task.Name))
+
+	output, _ := json.MarshalIndent(task, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	return nil
+}
+
+func runTaskUnarchive(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	taskID := args[0]
+	var task Task
+	var rejected error
+	err = updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+		for i := range tasks {
+			if tasks[i].ID != taskID {
+				continue
+			}
+			t := &tasks[i]
+			if t.Status != bridge.TaskStatusArchived {
+				rejected = fmt.Errorf("task %s is not archived", taskID)
+				return nil, rejected
+			}
+			t.Status = t.ArchivedFrom
+			if t.Status == "" {
+				t.Status = "pending"
+			}
+			t.ArchivedFrom = ""
+			t.ArchiveReason = ""
+			t.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+			task = *t
+			return tasks, nil
+		}
+		rejected = fmt.Errorf("task not found: %s", taskID)
+		return nil, rejected
+	})
+	if rejected != nil {
+		return rejected
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write tasks: %w", err)
+	}
+
+	writeAuditLog(missionDir, AuditTaskUnarchived, "cli", map[string]interface{}{
+		"task_id":    taskID,
+		"old_status": bridge.TaskStatusArchived,
+		"new_status": task.Status,
+	})
+	gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("unarchive", taskID, task.Name))
+
+	output, _ := json.MarshalIndent(task, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	return nil
+}
+
+func runTaskCompact(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	dropped, err := bridge.CompactTasks(tasksPath(missionDir))
+	if err != nil {
+		return fmt.Errorf("failed to compact tasks: %w", err)
+	}
+	if dropped == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "tasks.jsonl is already compact")
+		return nil
+	}
+	gitAutoCommit(missionDir, CommitCategoryTask, fmt.Sprintf("compact tasks.jsonl (%d superseded)", dropped))
+	fmt.Fprintf(cmd.OutOrStdout(), "Dropped %d superseded entries from tasks.jsonl\n", dropped)
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestTaskArchiveAndUnarchive(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	if err := saveTasks(missionDir, []Task{
+		{ID: "a", Name: "a", Status: "in_progress"},
+		{ID: "b", Name: "b", Status: "pending", DependsOn: []string{"a"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := &cobra.Command{Use: "archive", RunE: runTaskArchive}
+	archive.Flags().String("reason", "", "")
+	archive.Flags().Bool("force", false, "")
+	archive.SetOut(io.Discard)
+	unarchive := &cobra.Command{Use: "unarchive", RunE: runTaskUnarchive}
+	unarchive.SetOut(io.Discard)
+
+	if err := archive.RunE(archive, []string{"a"}); err == nil {
+		t.Error("Expected error archiving a task b depends on")
+	}
+	archive.Flags().Set("force", "true")
+	archive.Flags().Set("reason", "duplicate")
+	if err := archive.RunE(archive, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := loadTasks(missionDir)
+	if tasks[0].Status != "archived" || tasks[0].ArchivedFrom != "in_progress" || tasks[0].ArchiveReason != "duplicate" {
+		t.Errorf("archived task = %+v", tasks[0])
+	}
+	if err := archive.RunE(archive, []string{"a"}); err == nil {
+		t.Error("Expected error archiving an archived task")
+	}
+
+	if err := unarchive.RunE(unarchive, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ = loadTasks(missionDir)
+	if tasks[0].Status != "in_progress" || tasks[0].ArchivedFrom != "" {
+		t.Errorf("unarchived task = %+v", tasks[0])
+	}
+	if err := unarchive.RunE(unarchive, []string{"b"}); err == nil {
+		t.Error("Expected error unarchiving a task that isn't archived")
+	}
+}
+
+func TestReadTasksJSONLLastLineWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	os.WriteFile(path, []byte(`{"id":"a","status":"pending"}
+{"id":"b","status":"pending"}
+{"id":"a","status":"archived"}
+`), 0644)
+
+	tasks, err := readTasksJSONL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != "a" || tasks[0].Status != "archived" || tasks[1].ID != "b" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
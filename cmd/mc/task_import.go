@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/hashid"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	taskCmd.AddCommand(taskImportCmd)
+	taskImportCmd.Flags().String("format", "", "Input format: md or json (default: from the file extension, md for stdin)")
+	taskImportCmd.Flags().StringP("stage", "s", "", "Default stage for tasks without one")
+	taskImportCmd.Flags().StringP("zone", "z", "", "Default zone for tasks without one")
+	taskImportCmd.Flags().String("persona", "", "Default persona for tasks without one")
+	taskImportCmd.Flags().StringSliceP("label", "l", nil, "Labels to add to every task")
+	taskImportCmd.Flags().Bool("force", false, "Bypass stage validation")
+	taskImportCmd.Flags().String("override-matrix", "", "Create tasks the matrix doesn't enable the persona of, with this justification (logged to the audit trail)")
+	taskImportCmd.Flags().Bool("dry-run", false, "Print the tasks without creating them")
+}
+
+var taskImportCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Create tasks from a markdown checklist or JSON",
+	Long: `Creates every task in a markdown checklist, or a JSON array, at once:
+if any task is invalid, none are created.
+
+In markdown, each checkbox item is a task; checked items are created done.
+A heading that names a stage sets the stage of the items below it, and
+@stage:, @zone:, @persona:, @assignee:, @estimate: and @label: annotations
+set a task's fields (@label: may be repeated; @assignee: makes the persona
+default to manual):
+
+  ## Implement
+  - [ ] Build the tasks API @zone:backend @persona:developer @estimate:3
+  - [ ] Rotate the API keys @label:security
+  - [x] Add the tasks.jsonl schema
+
+JSON input is an array of objects with "title" (or "name"), "stage",
+"zone", "persona", "assignee", "depends_on", "estimate" and "labels".
+
+Examples:
+  mc task import plan.md
+  mc task import plan.md --stage implement --dry-run
+  mc task import - --format json < tasks.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskImport,
+}
+
+// TaskSpec is a task to import, as given in JSON or parsed from markdown.
+type TaskSpec struct {
+	Title     string   `json:"title"`
+	Name      string   `json:"name,omitempty"` // alias for title
+	Stage     string   `json:"stage,omitempty"`
+	Zone      string   `json:"zone,omitempty"`
+	Persona   string   `json:"persona,omitempty"`
+	Assignee  string   `json:"assignee,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	Estimate  float64  `json:"estimate,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Done      bool     `json:"done,omitempty"`
+	Line      int      `json:"-"` // markdown line, for errors
+}
+
+var (
+	checklistItemRe = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*)$`)
+	headingRe       = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	annotationRe    = regexp.MustCompile(`(?:^|\s)@(\w+):(\S+)`)
+)
+
+// parseChecklist parses markdown checkbox items into task specs.
+func parseChecklist(r io.Reader) ([]TaskSpec, error) {
+	var specs []TaskSpec
+	section := ""
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if m := headingRe.FindStringSubmatch(text); m != nil {
+			section = ""
+			if name := strings.ToLower(m[1]); stageIndex(name) >= 0 {
+				section = name
+			}
+			continue
+		}
+		m := checklistItemRe.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+
+		spec := TaskSpec{Stage: section, Done: m[1] != " ", Line: line}
+		for _, a := range annotationRe.FindAllStringSubmatch(m[2], -1) {
+			switch a[1] {
+			case "stage":
+				if stageIndex(a[2]) < 0 {
+					return nil, fmt.Errorf("line %d: unknown stage %q", line, a[2])
+				}
+				spec.Stage = a[2]
+			case "zone":
+				spec.Zone = a[2]
+			case "persona":
+				spec.Persona = a[2]
+			case "estimate":
+				e, err := strconv.ParseFloat(a[2], 64)
+				if err != nil || e < 0 {
+					return nil, fmt.Errorf("line %d: invalid estimate %q", line, a[2])
+				}
+				spec.Estimate = e
+			case "label":
+				spec.Labels = append(spec.Labels, a[2])
+			case "assignee":
+				spec.Assignee = a[2]
+			default:
+				return nil, fmt.Errorf("line %d: unknown annotation @%s (use @stage, @zone, @persona, @assignee, @estimate or @label)", line, a[1])
+			}
+		}
+		spec.Title = strings.Join(strings.Fields(annotationRe.ReplaceAllString(m[2], " ")), " ")
+		if spec.Title == "" {
+			return nil, fmt.Errorf("line %d: task has no title", line)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, scanner.Err()
+}
+
+// importTasks builds tasks from specs, applying defaults and the same
+// checks as mc task create. It returns an error, and no tasks, if any spec
+// is invalid, duplicates a task or closes a dependency cycle.
+func importTasks(existing []Task, specs []TaskSpec, defaults TaskSpec, currentStage string, force bool, warn io.Writer) ([]Task, error) {
+	ids := make(map[string]string, len(existing)+len(specs))
+	for _, t := range existing {
+		ids[t.ID] = t.Name
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	created := make([]Task, 0, len(specs))
+	for i, spec := range specs {
+		where := fmt.Sprintf("task %d", i+1)
+		if spec.Line > 0 {
+			where = fmt.Sprintf("line %d", spec.Line)
+		}
+
+		name := spec.Title
+		if name == "" {
+			name = spec.Name
+		}
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%s: title is required", where)
+		}
+		if spec.Estimate < 0 {
+			return nil, fmt.Errorf("%s: estimate must not be negative", where)
+		}
+		labels, err := bridge.NormalizeLabels(append(append([]string{}, spec.Labels...), defaults.Labels...))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		stage := firstNonEmpty(spec.Stage, defaults.Stage, currentStage)
+		zone := firstNonEmpty(spec.Zone, defaults.Zone)
+		assignee := strings.TrimSpace(spec.Assignee)
+		persona := firstNonEmpty(spec.Persona, defaults.Persona)
+		if assignee != "" && persona == "" {
+			persona = bridge.PersonaManual
+		}
+		if err := checkTaskStage(stage, currentStage, force, warn); err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+
+		id := hashid.Generate("task", name, stage, zone, persona)
+		if other, dup := ids[id]; dup {
+			return nil, fmt.Errorf("%s: task with this ID already exists: %s (name=%q)", where, id, other)
+		}
+		ids[id] = name
+
+		status := "pending"
+		if spec.Done {
+			status = "done"
+		}
+		created = append(created, Task{
+			ID:        id,
+			Name:      name,
+			Stage:     stage,
+			Zone:      zone,
+			Persona:   persona,
+			Assignee:  assignee,
+			Status:    status,
+			DependsOn: spec.DependsOn,
+			Estimate:  spec.Estimate,
+			Labels:    labels,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := checkTaskDeps(append(append([]Task{}, existing...), created...)); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func runTaskImport(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	overrideMatrix, _ := cmd.Flags().GetString("override-matrix")
+	var defaults TaskSpec
+	defaults.Stage, _ = cmd.Flags().GetString("stage")
+	defaults.Zone, _ = cmd.Flags().GetString("zone")
+	defaults.Persona, _ = cmd.Flags().GetString("persona")
+	defaults.Labels, _ = cmd.Flags().GetStringSlice("label")
+	if defaults.Stage != "" && stageIndex(defaults.Stage) < 0 {
+		return fmt.Errorf("unknown stage %q", defaults.Stage)
+	}
+
+	source := args[0]
+	var in io.Reader = cmd.InOrStdin()
+	if source != "-" {
+		f, err := os.Open(source)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", source, err)
+		}
+		defer f.Close()
+		in = f
+		if format == "" && strings.EqualFold(filepath.Ext(source), ".json") {
+			format = "json"
+		}
+	}
+
+	var specs []TaskSpec
+	switch format {
+	case "", "md", "markdown":
+		specs, err = parseChecklist(in)
+	case "json":
+		err = json.NewDecoder(in).Decode(&specs)
+	default:
+		return fmt.Errorf("invalid --format %q (use md or json)", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("no tasks found in %s", source)
+	}
+
+	currentStage, err := loadCurrentStage(missionDir)
+	if err != nil {
+		return err
+	}
+	matrix, err := loadMatrix(missionDir)
+	if err != nil {
+		return err
+	}
+	var created []Task
+	overridden := map[string]bool{}
+	plan := func(tasks []Task) error {
+		var err error
+		created, err = importTasks(tasks, specs, defaults, currentStage, force, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		for _, task := range created {
+			over, err := checkMatrix(matrix, task.Stage, task.Zone, task.Persona, overrideMatrix)
+			if err != nil {
+				return fmt.Errorf("task %q: %w", task.Name, err)
+			}
+			overridden[task.ID] = over
+		}
+		return nil
+	}
+
+	if dryRun {
+		tasks, err := loadTasks(missionDir)
+		if err != nil {
+			return fmt.Errorf("failed to read tasks: %w", err)
+		}
+		if err := plan(tasks); err != nil {
+			return err
+		}
+	} else {
+		var rejected error
+		err := updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+			if rejected = plan(tasks); rejected != nil {
+				return nil, rejected
+			}
+			return append(tasks, created...), nil
+		})
+		if rejected != nil {
+			return rejected
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write tasks: %w", err)
+		}
+		for _, task := range created {
+			writeAuditLog(missionDir, AuditTaskCreated, "cli", map[string]interface{}{
+				"task_id":  task.ID,
+				"name":     task.Name,
+				"stage":    task.Stage,
+				"zone":     task.Zone,
+				"persona":  task.Persona,
+				"assignee": task.Assignee,
+				"labels":   task.Labels,
+				"import":   source,
+			})
+			if overridden[task.ID] {
+				auditMatrixOverride(missionDir, task.Stage, task.Zone, task.Persona, overrideMatrix, map[string]interface{}{"task_id": task.ID})
+			}
+		}
+		gitAutoCommit(missionDir, CommitCategoryTask, taskCommitMsg("import", "", fmt.Sprintf("%d tasks", len(created))))
+		for _, task := range created {
+			if task.Assignee != "" {
+				notifyAssignee(missionDir, task, cmd.ErrOrStderr())
+			}
+		}
+	}
+
+	output, _ := json.MarshalIndent(created, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+const testPlan = `# Plan
+
+Intro text with a [link](x) and - a dash.
+
+## Implement
+- [ ] Build the tasks API @zone:backend @persona:developer @estimate:3
+* [x] Add the schema
+  1. [ ] Write   migrations @stage:design
+
+## Notes
+- [ ] Follow up with docs
+`
+
+func TestParseChecklist(t *testing.T) {
+	specs, err := parseChecklist(strings.NewReader(testPlan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaskSpec{
+		{Title: "Build the tasks API", Stage: "implement", Zone: "backend", Persona: "developer", Estimate: 3, Line: 6},
+		{Title: "Add the schema", Stage: "implement", Done: true, Line: 7},
+		{Title: "Write migrations", Stage: "design", Line: 8},
+		{Title: "Follow up with docs", Line: 11},
+	}
+	if len(specs) != len(want) {
+		t.Fatalf("specs = %+v", specs)
+	}
+	for i := range want {
+		got := specs[i]
+		if got.Title != want[i].Title || got.Stage != want[i].Stage || got.Zone != want[i].Zone ||
+			got.Persona != want[i].Persona || got.Estimate != want[i].Estimate || got.Done != want[i].Done || got.Line != want[i].Line {
+			t.Errorf("spec %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	specs, err = parseChecklist(strings.NewReader("- [ ] Rotate keys @label:security @label:ops"))
+	if err != nil || len(specs) != 1 || strings.Join(specs[0].Labels, ",") != "security,ops" {
+		t.Errorf("labels: specs = %+v, err = %v", specs, err)
+	}
+
+	for _, bad := range []string{"- [ ] x @color:red", "- [ ] x @stage:nope", "- [ ] x @estimate:-1", "- [ ] @zone:a"} {
+		if _, err := parseChecklist(strings.NewReader(bad)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("%q: err = %v, want a line 1 error", bad, err)
+		}
+	}
+}
+
+func TestImportTasksIsAtomic(t *testing.T) {
+	existing := []Task{{ID: "a", Name: "a", Stage: "implement", Status: "pending"}}
+	defaults := TaskSpec{Zone: "web"}
+
+	created, err := importTasks(existing, []TaskSpec{
+		{Title: "one"},
+		{Name: "two", Stage: "design", DependsOn: []string{"a"}, Done: true},
+	}, defaults, "implement", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0].Stage != "implement" || created[0].Zone != "web" ||
+		created[1].Name != "two" || created[1].Status != "done" {
+		t.Errorf("created = %+v", created)
+	}
+
+	cases := map[string][]TaskSpec{
+		"future stage": {{Title: "ok"}, {Title: "later", Stage: "release"}},
+		"duplicate":    {{Title: "same"}, {Title: "same"}},
+		"no title":     {{Zone: "x"}},
+	}
+	for name, specs := range cases {
+		if created, err := importTasks(existing, specs, defaults, "implement", false, io.Discard); err == nil {
+			t.Errorf("%s: expected error, created %+v", name, created)
+		}
+	}
+	if _, err := importTasks(existing, cases["future stage"], defaults, "implement", true, io.Discard); err != nil {
+		t.Errorf("--force: %v", err)
+	}
+}
+
+func TestTaskImportCommand(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+	setStage(t, tmpDir, "implement")
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "import", RunE: runTaskImport}
+		cmd.Flags().String("format", "", "")
+		cmd.Flags().StringP("stage", "s", "", "")
+		cmd.Flags().StringP("zone", "z", "", "")
+		cmd.Flags().String("persona", "", "")
+		cmd.Flags().Bool("force", false, "")
+		cmd.Flags().Bool("dry-run", false, "")
+		cmd.Flags().String("override-matrix", "", "")
+		cmd.SetOut(io.Discard)
+		return cmd
+	}
+	run := func(path string) error {
+		cmd := newCmd()
+		return cmd.RunE(cmd, []string{path})
+	}
+
+	plan := filepath.Join(tmpDir, "plan.md")
+	os.WriteFile(plan, []byte(testPlan), 0644)
+
+	cmd := newCmd()
+	cmd.Flags().Set("dry-run", "true")
+	if err := cmd.RunE(cmd, []string{plan}); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ := loadTasks(missionDir); len(tasks) != 0 {
+		t.Fatalf("dry run created %d tasks", len(tasks))
+	}
+
+	if err := run(plan); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := loadTasks(missionDir)
+	if len(tasks) != 4 {
+		t.Fatalf("imported %d tasks, want 4", len(tasks))
+	}
+
+	// A second import duplicates every task and must create none
+	if err := run(plan); err == nil {
+		t.Error("Expected error re-importing the same plan")
+	}
+
+	jsonPlan := filepath.Join(tmpDir, "plan.json")
+	os.WriteFile(jsonPlan, []byte(`[{"title":"From JSON","depends_on":["`+tasks[0].ID+`"],"estimate":2}]`), 0644)
+	if err := run(jsonPlan); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ = loadTasks(missionDir); len(tasks) != 5 || tasks[4].Estimate != 2 || tasks[4].Stage != "implement" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+	// The matrix refuses the whole import for one disabled persona
+	setMatrix(t, missionDir, "backend")
+	matrixPlan := filepath.Join(tmpDir, "matrix.json")
+	os.WriteFile(matrixPlan, []byte(`[{"title":"Write code","persona":"developer"},{"title":"Look around","stage":"discovery","persona":"developer"}]`), 0644)
+	if err := run(matrixPlan); err == nil || !strings.Contains(err.Error(), `task "Look around": persona developer is not enabled`) {
+		t.Errorf("expected the discovery developer refused, got %v", err)
+	}
+	cmd = newCmd()
+	cmd.Flags().Set("override-matrix", "Agreed in planning")
+	if err := cmd.RunE(cmd, []string{matrixPlan}); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ = loadTasks(missionDir); len(tasks) != 7 {
+		t.Errorf("imported %d tasks, want 7", len(tasks))
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+	"github.com/MikeSquared-Agency/MissionControl/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -61,9 +70,61 @@ func newTaskCreateCmd() *cobra.Command {
 	cmd.Flags().String("persona", "", "Persona to assign")
 	cmd.Flags().StringSlice("depends-on", nil, "Task IDs this task depends on")
 	cmd.Flags().Bool("force", false, "Bypass stage validation")
+	cmd.Flags().Float64("estimate", 0, "Relative effort")
+	cmd.Flags().String("override-matrix", "", "Justification for overriding the matrix")
 	return cmd
 }
 
+// setMatrix writes the default matrix for zones to config.json.
+func setMatrix(t *testing.T, missionDir string, zones ...string) {
+	t.Helper()
+	configPath := filepath.Join(missionDir, "config.json")
+	cfg := map[string]interface{}{}
+	readJSON(configPath, &cfg)
+	cfg["matrix"] = workspace.DefaultMatrix(zones, false)
+	if err := writeJSON(configPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTaskCreateMatrix(t *testing.T) {
+	dir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	setStage(t, dir, "implement")
+	missionDir := filepath.Join(dir, ".mission")
+	setMatrix(t, missionDir, "backend")
+
+	cmd := newTaskCreateCmd()
+	cmd.Flags().Set("stage", "discovery")
+	cmd.Flags().Set("zone", "backend")
+	cmd.Flags().Set("persona", "developer")
+	err := cmd.RunE(cmd, []string{"Prototype early"})
+	if err == nil || !strings.Contains(err.Error(), "persona developer is not enabled by the matrix for the discovery stage in zone backend (enabled: researcher)") {
+		t.Fatalf("expected the matrix to refuse, got %v", err)
+	}
+	if tasks, _ := loadTasks(missionDir); len(tasks) != 0 {
+		t.Fatalf("refused task was created: %+v", tasks)
+	}
+
+	cmd.Flags().Set("override-matrix", "Spike agreed with the team")
+	if err := cmd.RunE(cmd, []string{"Prototype early"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := readAuditLog(missionDir)
+	last := entries[len(entries)-1]
+	if last.Action != AuditMatrixOverridden || last.Details["justification"] != "Spike agreed with the team" || last.Details["task_id"] == nil {
+		t.Errorf("override not audited: %+v", last)
+	}
+
+	// Manual tasks are for people, whatever the matrix says
+	cmd = newTaskCreateCmd()
+	cmd.Flags().Set("stage", "discovery")
+	cmd.Flags().Set("persona", bridge.PersonaManual)
+	if err := cmd.RunE(cmd, []string{"Interview users"}); err != nil {
+		t.Errorf("manual task refused: %v", err)
+	}
+}
+
 func TestTaskCreateSameStage(t *testing.T) {
 	missionDir, cleanup := setupTaskTestDir(t)
 	defer cleanup()
@@ -135,3 +196,216 @@ func TestTaskCreateNoStageSet(t *testing.T) {
 		t.Errorf("Expected success when no stage is set, got: %v", err)
 	}
 }
+
+func TestTaskCreateEstimate(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+
+	cmd := newTaskCreateCmd()
+	cmd.Flags().Set("estimate", "-1")
+	if err := cmd.RunE(cmd, []string{"test-task-negative"}); err == nil {
+		t.Error("Expected error for a negative estimate, got nil")
+	}
+
+	cmd = newTaskCreateCmd()
+	cmd.Flags().Set("estimate", "2.5")
+	if err := cmd.RunE(cmd, []string{"test-task-estimate"}); err != nil {
+		t.Fatalf("Expected success with --estimate, got: %v", err)
+	}
+	tasks, err := loadTasks(filepath.Join(tmpDir, ".mission"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Estimate != 2.5 {
+		t.Errorf("tasks = %+v, want one task with estimate 2.5", tasks)
+	}
+}
+
+func TestTaskDepRejectsCycle(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	tasks := []Task{
+		{ID: "a", Name: "a", Status: "pending"},
+		{ID: "b", Name: "b", Status: "pending", DependsOn: []string{"a"}},
+		{ID: "c", Name: "c", Status: "pending", DependsOn: []string{"b"}},
+	}
+	if err := saveTasks(missionDir, tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	err := updateTaskDep("a", "c", true)
+	if err == nil || !strings.Contains(err.Error(), "a → c → b → a") {
+		t.Fatalf("err = %v, want the cycle named", err)
+	}
+	if err := updateTaskDep("a", "a", true); err == nil {
+		t.Error("Expected error for a self-dependency")
+	}
+	if err := updateTaskDep("a", "missing", true); err == nil {
+		t.Error("Expected error for an unknown dependency")
+	}
+
+	if err := updateTaskDep("c", "b", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateTaskDep("a", "c", true); err != nil {
+		t.Fatalf("Expected success once the cycle is broken, got: %v", err)
+	}
+	tasks, _ = loadTasks(missionDir)
+	if got := buildTaskMap(tasks)["a"].DependsOn; len(got) != 1 || got[0] != "c" {
+		t.Errorf("a depends on %v, want [c]", got)
+	}
+}
+
+func TestUpdateTasksConcurrent(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := updateTasks(missionDir, func(tasks []Task) ([]Task, error) {
+				return append(tasks, Task{ID: fmt.Sprintf("t%d", i), Status: "pending"}), nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 20 {
+		t.Errorf("got %d tasks, want 20: a concurrent save was lost", len(tasks))
+	}
+}
+
+func TestTaskUpdateEstimate(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	if err := saveTasks(missionDir, []Task{{ID: "a", Name: "a", Status: "pending"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{Use: "update <task-id>", RunE: runTaskUpdate}
+	cmd.Flags().StringP("status", "s", "", "New status")
+	cmd.Flags().Float64("estimate", 0, "New estimate")
+	if err := cmd.RunE(cmd, []string{"a"}); err == nil {
+		t.Error("Expected error without --status or --estimate")
+	}
+
+	cmd.Flags().Set("estimate", "3")
+	if err := cmd.RunE(cmd, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := loadTasks(missionDir)
+	if tasks[0].Estimate != 3 || tasks[0].Status != "pending" {
+		t.Errorf("task = %+v, want estimate 3 and status unchanged", tasks[0])
+	}
+}
+
+func TestTaskLabels(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	create := &cobra.Command{Use: "create <name>", RunE: runTaskCreate}
+	create.Flags().StringP("stage", "s", "", "")
+	create.Flags().StringP("zone", "z", "", "")
+	create.Flags().String("persona", "", "")
+	create.Flags().StringSlice("depends-on", nil, "")
+	create.Flags().Bool("force", false, "")
+	create.Flags().String("scope-paths", "", "")
+	create.Flags().Float64("estimate", 0, "")
+	create.Flags().StringSliceP("label", "l", nil, "")
+	create.Flags().Set("label", "Security,tech-debt,security")
+	if err := create.RunE(create, []string{"Rotate keys"}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := loadTasks(missionDir)
+	if len(tasks) != 1 || strings.Join(tasks[0].Labels, ",") != "security,tech-debt" {
+		t.Fatalf("labels = %q, want security,tech-debt", tasks[0].Labels)
+	}
+
+	update := &cobra.Command{Use: "update <task-id>", RunE: runTaskUpdate}
+	update.Flags().StringP("status", "s", "", "")
+	update.Flags().Float64("estimate", 0, "")
+	update.Flags().StringSlice("add-label", nil, "")
+	update.Flags().StringSlice("remove-label", nil, "")
+	update.Flags().Set("add-label", "ops,security")
+	update.Flags().Set("remove-label", "tech-debt")
+	if err := update.RunE(update, []string{tasks[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ = loadTasks(missionDir)
+	if strings.Join(tasks[0].Labels, ",") != "security,ops" || tasks[0].Status != "pending" {
+		t.Errorf("task = %+v, want labels security,ops", tasks[0])
+	}
+
+	if !hasLabels(tasks[0], []string{"ops", "security"}) || hasLabels(tasks[0], []string{"ops", "tech-debt"}) {
+		t.Error("hasLabels should require every label")
+	}
+}
+
+func TestTaskAssigneeNotifiesWebhook(t *testing.T) {
+	tmpDir, cleanup := setupTaskTestDir(t)
+	defer cleanup()
+	missionDir := filepath.Join(tmpDir, ".mission")
+
+	events := make(chan webhook.Event, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e webhook.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		events <- e
+	}))
+	defer srv.Close()
+	config := `{"webhooks": [{"url": "` + srv.URL + `", "events": ["task_assigned"]}]}`
+	if err := os.WriteFile(filepath.Join(missionDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	create := &cobra.Command{Use: "create <name>", RunE: runTaskCreate}
+	create.Flags().StringP("stage", "s", "", "")
+	create.Flags().StringP("zone", "z", "", "")
+	create.Flags().String("persona", "", "")
+	create.Flags().String("assignee", "", "")
+	create.Flags().StringSlice("depends-on", nil, "")
+	create.Flags().Bool("force", false, "")
+	create.Flags().String("scope-paths", "", "")
+	create.Flags().Float64("estimate", 0, "")
+	create.Flags().StringSliceP("label", "l", nil, "")
+	create.Flags().Set("assignee", "alice@example.com")
+	if err := create.RunE(create, []string{"Sign the vendor contract"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, _ := loadTasks(missionDir)
+	if len(tasks) != 1 || tasks[0].Persona != "manual" || tasks[0].Assignee != "alice@example.com" {
+		t.Fatalf("task = %+v, want a manual task for alice", tasks)
+	}
+	e := <-events
+	data, _ := e.Data.(map[string]interface{})
+	if e.Event != webhook.EventTaskAssigned || data["assignee"] != "alice@example.com" || data["task_id"] != tasks[0].ID {
+		t.Errorf("event = %+v", e)
+	}
+
+	if err := errManualTask(tasks[0]); err == nil {
+		t.Error("Expected manual task to refuse a worker")
+	}
+	spawn := &cobra.Command{Use: "spawn", RunE: runSpawn}
+	spawn.Flags().StringP("zone", "z", "", "")
+	spawn.Flags().String("task-id", tasks[0].ID, "")
+	spawn.Flags().String("runtime", "", "")
+	if err := spawn.RunE(spawn, []string{"developer", "do it"}); err == nil || !strings.Contains(err.Error(), "manual") {
+		t.Errorf("spawn for a manual task: err = %v", err)
+	}
+}
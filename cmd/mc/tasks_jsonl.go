@@ -1,11 +1,14 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
 )
 
 const (
@@ -19,65 +22,67 @@ func tasksPath(missionDir string) string {
 }
 
 // readTasksJSONL reads tasks from a JSONL file (one JSON task per line).
+// When a task appears on several lines, the last one wins. A malformed
+// line is an error, so a later save can't silently drop it.
 func readTasksJSONL(path string) ([]Task, error) {
-	f, err := os.Open(path)
-	if err != nil {
+	if _, err := os.Stat(path); err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	var tasks []Task
-	scanner := bufio.NewScanner(f)
-	// Increase buffer for potentially large lines
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
+	index := map[string]int{}
+	skipped, err := jsonl.Read(path, func(line []byte) error {
 		var task Task
 		if err := json.Unmarshal(line, &task); err != nil {
-			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			return err
 		}
-		tasks = append(tasks, task)
-	}
-	if err := scanner.Err(); err != nil {
+		tasks = mergeTask(tasks, index, task)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	if len(skipped) > 0 {
+		return nil, corruptTaskLine(skipped[0].Line, skipped[0].Err)
+	}
 	return tasks, nil
 }
 
-// writeTasksJSONL writes tasks to a JSONL file (one JSON task per line).
+// mergeTask appends task to tasks, or replaces the task with its ID: a
+// later line for the same task supersedes the earlier one.
+func mergeTask(tasks []Task, index map[string]int, task Task) []Task {
+	if i, seen := index[task.ID]; seen && task.ID != "" {
+		tasks[i] = task
+		return tasks
+	}
+	index[task.ID] = len(tasks)
+	return append(tasks, task)
+}
+
+func corruptTaskLine(line int, err string) error {
+	return fmt.Errorf("line %d: %s (run 'mc doctor --repair' to set corrupt lines aside)", line, err)
+}
+
+// writeTasksJSONL writes tasks to a JSONL file (one JSON task per line),
+// replacing it atomically under the jsonl lock.
 func writeTasksJSONL(path string, tasks []Task) error {
-	f, err := os.CreateTemp(filepath.Dir(path), ".tasks-*.jsonl")
+	lines, err := encodeTasks(tasks)
 	if err != nil {
 		return err
 	}
-	tmpPath := f.Name()
+	return jsonl.Rewrite(path, jsonl.Options{}, lines)
+}
 
-	w := bufio.NewWriter(f)
+func encodeTasks(tasks []Task) ([][]byte, error) {
+	lines := make([][]byte, 0, len(tasks))
 	for _, task := range tasks {
 		data, err := json.Marshal(task)
 		if err != nil {
-			f.Close()
-			os.Remove(tmpPath)
-			return err
+			return nil, err
 		}
-		_, _ = w.Write(data)
-		_ = w.WriteByte('\n')
-	}
-	if err := w.Flush(); err != nil {
-		f.Close()
-		os.Remove(tmpPath)
-		return err
+		lines = append(lines, data)
 	}
-	if err := f.Close(); err != nil {
-		os.Remove(tmpPath)
-		return err
-	}
-	return os.Rename(tmpPath, path)
+	return lines, nil
 }
 
 // loadTasks loads tasks from tasks.jsonl, auto-migrating from tasks.json if needed.
@@ -103,9 +108,58 @@ func loadTasks(missionDir string) ([]Task, error) {
 	return []Task{}, nil
 }
 
-// saveTasks saves tasks to tasks.jsonl.
+// saveTasks replaces every task in tasks.jsonl with tasks. Callers that
+// change tasks they loaded should use updateTasks instead.
 func saveTasks(missionDir string, tasks []Task) error {
-	return writeTasksJSONL(tasksPath(missionDir), tasks)
+	return updateTasks(missionDir, func([]Task) ([]Task, error) {
+		return tasks, nil
+	})
+}
+
+// errNoChange, returned by an updateTasks callback, leaves tasks.jsonl as
+// it is without failing the update.
+var errNoChange = errors.New("no change")
+
+// updateTasks replaces the tasks in tasks.jsonl with what fn makes of
+// them, holding the jsonl lock from the read to the write so a task
+// another mc saved meanwhile isn't lost. Nothing is written if fn fails.
+// What changed is recorded in the event stream.
+func updateTasks(missionDir string, fn func(tasks []Task) ([]Task, error)) error {
+	path := tasksPath(missionDir)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		// Migrates tasks.json, if there is one
+		if _, err := loadTasks(missionDir); err != nil {
+			return err
+		}
+	}
+
+	var before, after []Task
+	err := jsonl.Update(path, jsonl.Options{}, func(lines [][]byte) ([][]byte, error) {
+		index := map[string]int{}
+		for n, line := range lines {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var task Task
+			if err := json.Unmarshal(line, &task); err != nil {
+				return nil, corruptTaskLine(n+1, err.Error())
+			}
+			before = mergeTask(before, index, task)
+		}
+		var err error
+		if after, err = fn(append([]Task{}, before...)); err != nil {
+			return nil, err
+		}
+		return encodeTasks(after)
+	})
+	if errors.Is(err, errNoChange) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	recordTaskEvents(missionDir, before, after)
+	return nil
 }
 
 // migrateTasksJSONToJSONL reads tasks.json, writes tasks.jsonl, returns the tasks.
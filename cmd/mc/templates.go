@@ -0,0 +1,229 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/workspace"
+)
+
+// builtinTemplates holds the templates shipped with mc. Each template is a
+// directory with a template.json and optional example specs in specs/.
+//
+//go:embed templates
+var builtinTemplates embed.FS
+
+// ProjectTemplate seeds a new .mission/ for a kind of project.
+type ProjectTemplate struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Zones       []string            `json:"zones"`
+	Personas    []string            `json:"personas"`        // enabled personas; others start disabled
+	Gates       map[string][]string `json:"gates,omitempty"` // criteria overrides per stage
+
+	Source string            `json:"-"` // "builtin" or the template directory
+	Specs  map[string][]byte `json:"-"` // example specs by file name
+}
+
+// PersonaConfig enables or disables a persona in .mission/config.json.
+type PersonaConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// userTemplatesDir holds user templates, which shadow builtins of the same
+// name: ~/.mission-control/templates/<name>/template.json.
+func userTemplatesDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".mission-control", "templates")
+}
+
+// loadTemplate finds a template by name, preferring user templates.
+func loadTemplate(name string) (*ProjectTemplate, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	userDir := filepath.Join(userTemplatesDir(), name)
+	if _, err := os.Stat(filepath.Join(userDir, "template.json")); err == nil {
+		return readTemplate(os.DirFS(userDir), name, userDir)
+	}
+	sub, err := fs.Sub(builtinTemplates, path.Join("templates", name))
+	if err == nil {
+		if _, err := fs.Stat(sub, "template.json"); err == nil {
+			return readTemplate(sub, name, "builtin")
+		}
+	}
+
+	var names []string
+	if all, err := listTemplates(); err == nil {
+		for _, t := range all {
+			names = append(names, t.Name)
+		}
+	}
+	return nil, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// listTemplates returns builtin and user templates by name; a user
+// template replaces a builtin of the same name.
+func listTemplates() ([]*ProjectTemplate, error) {
+	byName := make(map[string]*ProjectTemplate)
+
+	entries, err := fs.ReadDir(builtinTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		sub, _ := fs.Sub(builtinTemplates, path.Join("templates", e.Name()))
+		t, err := readTemplate(sub, e.Name(), "builtin")
+		if err != nil {
+			return nil, err
+		}
+		byName[t.Name] = t
+	}
+
+	if entries, err := os.ReadDir(userTemplatesDir()); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			dir := filepath.Join(userTemplatesDir(), e.Name())
+			t, err := readTemplate(os.DirFS(dir), e.Name(), dir)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipping template %s: %v\n", dir, err)
+				continue
+			}
+			byName[t.Name] = t
+		}
+	}
+
+	templates := make([]*ProjectTemplate, 0, len(byName))
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// readTemplate parses template.json and the specs/ directory from fsys.
+// The template is always named after its directory.
+func readTemplate(fsys fs.FS, name, source string) (*ProjectTemplate, error) {
+	data, err := fs.ReadFile(fsys, "template.json")
+	if err != nil {
+		return nil, err
+	}
+	var t ProjectTemplate
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse template.json: %w", err)
+	}
+	t.Name = name
+	t.Source = source
+	if len(t.Zones) == 0 {
+		t.Zones = workspace.DefaultZones
+	}
+	for stage := range t.Gates {
+		if _, ok := workspace.StagePersonas[stage]; !ok {
+			return nil, fmt.Errorf("template.json: unknown stage %q in gates", stage)
+		}
+	}
+
+	t.Specs = make(map[string][]byte)
+	specs, err := fs.ReadDir(fsys, "specs")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range specs {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join("specs", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		t.Specs[e.Name()] = content
+	}
+	return &t, nil
+}
+
+// personaEnabled reports whether the template selects persona. A template
+// without a persona list enables all of them.
+func (t *ProjectTemplate) personaEnabled(persona string) bool {
+	if len(t.Personas) == 0 {
+		return true
+	}
+	for _, p := range t.Personas {
+		if p == persona {
+			return true
+		}
+	}
+	return false
+}
+
+// matrix builds the stage matrix for zones with the template's personas.
+func (t *ProjectTemplate) matrix(zones []string) []workspace.MatrixCell {
+	var cells []workspace.MatrixCell
+	for _, stage := range workspace.Stages {
+		for _, zone := range zones {
+			for _, persona := range workspace.StagePersonas[stage] {
+				cells = append(cells, workspace.MatrixCell{
+					Stage:   stage,
+					Zone:    zone,
+					Persona: persona,
+					Enabled: t.personaEnabled(persona),
+				})
+			}
+		}
+	}
+	return cells
+}
+
+// personaConfig records the persona selection for config.json.
+func (t *ProjectTemplate) personaConfig() map[string]PersonaConfig {
+	personas := make(map[string]PersonaConfig)
+	for _, list := range workspace.StagePersonas {
+		for _, p := range list {
+			personas[p] = PersonaConfig{Enabled: t.personaEnabled(p)}
+		}
+	}
+	return personas
+}
+
+// applyGates overrides gate criteria with the template's.
+func (t *ProjectTemplate) applyGates(gates map[string]Gate) {
+	for stage, criteria := range t.Gates {
+		g := gates[stage]
+		g.Stage = stage
+		if g.Status == "" {
+			g.Status = "pending"
+		}
+		g.Criteria = criteria
+		gates[stage] = g
+	}
+}
+
+// printTemplates lists the available templates.
+func printTemplates() error {
+	templates, err := listTemplates()
+	if err != nil {
+		return err
+	}
+	for _, t := range templates {
+		source := ""
+		if t.Source != "builtin" {
+			source = " (" + t.Source + ")"
+		}
+		fmt.Printf("  %-12s %s%s\n", t.Name, t.Description, source)
+	}
+	fmt.Println("")
+	fmt.Printf("User templates: %s/<name>/template.json\n", userTemplatesDir())
+	return nil
+}
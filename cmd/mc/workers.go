@@ -4,13 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"syscall"
 
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(workersCmd)
+	workersCmd.AddCommand(workersDispatchCmd)
 }
 
 var workersCmd = &cobra.Command{
@@ -20,6 +20,16 @@ var workersCmd = &cobra.Command{
 	RunE:  runWorkers,
 }
 
+var workersDispatchCmd = &cobra.Command{
+	Use:   "dispatch",
+	Short: "Reap dead workers and start queued ones",
+	Long: `Marks running workers whose process is gone as failed, freeing their
+concurrency slots, then starts queued workers the limits allow. mc serve
+runs it when it reaps a worker.`,
+	Args: cobra.NoArgs,
+	RunE: runWorkersDispatch,
+}
+
 type WorkerInfo struct {
 	ID      string `json:"id"`
 	Persona string `json:"persona"`
@@ -63,11 +73,11 @@ func runWorkers(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func isProcessAlive(pid int) bool {
-	if pid <= 0 {
-		return false
+func runWorkersDispatch(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
 	}
-	// Send signal 0 to check if process exists
-	err := syscall.Kill(pid, 0)
-	return err == nil
+	dispatchQueuedWorkers(missionDir)
+	return nil
 }
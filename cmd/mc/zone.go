@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(zoneCmd)
+	zoneCmd.AddCommand(zoneListCmd)
+	zoneCmd.AddCommand(zoneAddCmd)
+	zoneCmd.AddCommand(zoneUpdateCmd)
+	zoneCmd.AddCommand(zoneRemoveCmd)
+
+	for _, c := range []*cobra.Command{zoneAddCmd, zoneUpdateCmd} {
+		c.Flags().String("color", "", "Dashboard color, #rgb or #rrggbb")
+		c.Flags().StringSlice("path", nil, "Path owned by the zone: dir/, glob or file (repeatable)")
+		c.Flags().Int("max-workers", 0, "Concurrent workers in the zone (0 = no limit)")
+	}
+}
+
+var zoneCmd = &cobra.Command{
+	Use:   "zone",
+	Short: "Manage zones",
+	Long: `Zones group the project's paths and workers. They are kept in
+.mission/state/zones.json with a color for the dashboard, the paths they
+own and an optional worker limit, and are used by the graph, by mc spawn's
+concurrency limits and by mc commit's scope check.`,
+}
+
+var zoneListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List zones",
+	RunE:  runZoneList,
+}
+
+var zoneAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a zone",
+	Long: `Adds a zone to .mission/state/zones.json.
+
+Example:
+  mc zone add backend --path orchestrator/ --path cmd/ --max-workers 2
+  mc zone add web --color "#10b981" --path "web/"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runZoneAdd,
+}
+
+var zoneUpdateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Change a zone's color, paths or worker limit",
+	Long: `Changes the given settings of a zone; --path replaces its paths.
+
+Example:
+  mc zone update backend --max-workers 3`,
+	Args: cobra.ExactArgs(1),
+	RunE: runZoneUpdate,
+}
+
+var zoneRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a zone",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runZoneRemove,
+}
+
+// zonesPath returns the path of zones.json
+func zonesPath(missionDir string) string {
+	return filepath.Join(missionDir, "state", "zones.json")
+}
+
+// loadZones reads the zones in zones.json
+func loadZones(missionDir string) ([]bridge.Zone, error) {
+	return bridge.LoadZones(zonesPath(missionDir))
+}
+
+// loadLimits returns the worker limits from config.json and zones.json.
+func loadLimits(missionDir string, projectConfig *bridge.ProjectConfig) *bridge.LimitsConfig {
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring zones.json: %v\n", err)
+	}
+	return projectConfig.Limits.WithZones(zones)
+}
+
+func runZoneList(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		return err
+	}
+	if len(zones) == 0 {
+		fmt.Println("No zones defined. Add one with 'mc zone add <name>'.")
+		return nil
+	}
+	for _, z := range zones {
+		limit := "-"
+		if z.MaxWorkers > 0 {
+			limit = fmt.Sprint(z.MaxWorkers)
+		}
+		fmt.Printf("%-16s %-8s max=%-3s %s\n", z.Name, z.Color, limit, strings.Join(z.Paths, " "))
+	}
+	return nil
+}
+
+func runZoneAdd(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		return err
+	}
+	if bridge.FindZone(zones, args[0]) != nil {
+		return fmt.Errorf("zone %s already exists (use 'mc zone update')", args[0])
+	}
+
+	zone := bridge.Zone{Name: args[0]}
+	applyZoneFlags(cmd, &zone)
+	if zone.Color == "" {
+		zone.Color = bridge.ZoneColors[len(zones)%len(bridge.ZoneColors)]
+	}
+	if err := zone.Validate(); err != nil {
+		return err
+	}
+
+	if err := bridge.SaveZones(zonesPath(missionDir), append(zones, zone)); err != nil {
+		return fmt.Errorf("failed to save zones: %w", err)
+	}
+	writeAuditLog(missionDir, AuditZoneAdded, "cli", zoneDetails(zone))
+	fmt.Printf("Added zone %s (%s)\n", zone.Name, zone.Color)
+	return nil
+}
+
+func runZoneUpdate(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		return err
+	}
+	zone := bridge.FindZone(zones, args[0])
+	if zone == nil {
+		return fmt.Errorf("zone %s not found", args[0])
+	}
+
+	applyZoneFlags(cmd, zone)
+	if err := zone.Validate(); err != nil {
+		return err
+	}
+
+	if err := bridge.SaveZones(zonesPath(missionDir), zones); err != nil {
+		return fmt.Errorf("failed to save zones: %w", err)
+	}
+	writeAuditLog(missionDir, AuditZoneUpdated, "cli", zoneDetails(*zone))
+	fmt.Printf("Updated zone %s\n", zone.Name)
+	return nil
+}
+
+func runZoneRemove(cmd *cobra.Command, args []string) error {
+	missionDir, err := findMissionDir()
+	if err != nil {
+		return err
+	}
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		return err
+	}
+	kept := make([]bridge.Zone, 0, len(zones))
+	for _, z := range zones {
+		if z.Name != args[0] {
+			kept = append(kept, z)
+		}
+	}
+	if len(kept) == len(zones) {
+		return fmt.Errorf("zone %s not found", args[0])
+	}
+
+	if err := bridge.SaveZones(zonesPath(missionDir), kept); err != nil {
+		return fmt.Errorf("failed to save zones: %w", err)
+	}
+	writeAuditLog(missionDir, AuditZoneRemoved, "cli", map[string]interface{}{"zone": args[0]})
+	fmt.Printf("Removed zone %s\n", args[0])
+
+	// Tasks keep their zone name; say so rather than rewriting them
+	if tasks, err := loadTasks(missionDir); err == nil {
+		n := 0
+		for _, t := range tasks {
+			if t.Zone == args[0] && t.Status != "done" {
+				n++
+			}
+		}
+		if n > 0 {
+			fmt.Printf("  %d open task(s) are still in zone %s\n", n, args[0])
+		}
+	}
+	return nil
+}
+
+// applyZoneFlags copies the flags that were given onto zone.
+func applyZoneFlags(cmd *cobra.Command, zone *bridge.Zone) {
+	if cmd.Flags().Changed("color") {
+		zone.Color, _ = cmd.Flags().GetString("color")
+	}
+	if cmd.Flags().Changed("path") {
+		zone.Paths, _ = cmd.Flags().GetStringSlice("path")
+	}
+	if cmd.Flags().Changed("max-workers") {
+		zone.MaxWorkers, _ = cmd.Flags().GetInt("max-workers")
+	}
+}
+
+// zoneDetails is a zone as audit log details
+func zoneDetails(z bridge.Zone) map[string]interface{} {
+	return map[string]interface{}{
+		"zone":        z.Name,
+		"color":       z.Color,
+		"paths":       z.Paths,
+		"max_workers": z.MaxWorkers,
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/spf13/cobra"
+)
+
+// newZoneCmd returns a fresh zone subcommand, so flags don't carry over
+// between calls.
+func newZoneCmd(run func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := &cobra.Command{Args: cobra.ExactArgs(1), RunE: run}
+	cmd.Flags().String("color", "", "")
+	cmd.Flags().StringSlice("path", nil, "")
+	cmd.Flags().Int("max-workers", 0, "")
+	return cmd
+}
+
+func TestInitSeedsZones(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+
+	zones, err := loadZones(missionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := bridge.FindZone(zones, "backend")
+	if len(zones) != 5 || backend == nil || backend.Color == "" {
+		t.Errorf("zones = %+v, want the five default zones with colors", zones)
+	}
+}
+
+func TestZoneAddUpdateRemove(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+
+	add := newZoneCmd(runZoneAdd)
+	add.Flags().Set("path", "api/")
+	add.Flags().Set("path", "*.proto")
+	add.Flags().Set("max-workers", "2")
+	if err := add.RunE(add, []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := add.RunE(add, []string{"api"}); err == nil {
+		t.Error("adding an existing zone should fail")
+	}
+
+	zones, _ := loadZones(missionDir)
+	api := bridge.FindZone(zones, "api")
+	if api == nil || api.MaxWorkers != 2 || len(api.Paths) != 2 || api.Color == "" {
+		t.Fatalf("api zone = %+v", api)
+	}
+
+	update := newZoneCmd(runZoneUpdate)
+	update.Flags().Set("color", "#ff0000")
+	if err := update.RunE(update, []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+	zones, _ = loadZones(missionDir)
+	api = bridge.FindZone(zones, "api")
+	if api.Color != "#ff0000" || api.MaxWorkers != 2 {
+		t.Errorf("after update: %+v, want only the color changed", api)
+	}
+
+	bad := newZoneCmd(runZoneUpdate)
+	bad.Flags().Set("color", "red")
+	if err := bad.RunE(bad, []string{"api"}); err == nil {
+		t.Error("an invalid color should be rejected")
+	}
+
+	remove := newZoneCmd(runZoneRemove)
+	if err := remove.RunE(remove, []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := remove.RunE(remove, []string{"api"}); err == nil {
+		t.Error("removing a missing zone should fail")
+	}
+	zones, _ = loadZones(missionDir)
+	if bridge.FindZone(zones, "api") != nil {
+		t.Error("api zone still defined")
+	}
+
+	data, _ := os.ReadFile(filepath.Join(missionDir, "audit.jsonl"))
+	for _, action := range []string{AuditZoneAdded, AuditZoneUpdated, AuditZoneRemoved} {
+		if !strings.Contains(string(data), action) {
+			t.Errorf("audit log has no %s", action)
+		}
+	}
+}
+
+func TestScopeBoundedByZone(t *testing.T) {
+	dir := setupStrictTestDir(t, "implement", []Task{
+		{ID: "t1", Stage: "implement", Persona: "developer", Status: "in_progress", Zone: "backend"},
+	})
+	if err := bridge.SaveZones(zonesPath(dir), []bridge.Zone{{Name: "backend", Paths: []string{"orchestrator/"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := validateScope(dir, "t1", []string{"orchestrator/api/zones.go"}); len(errs) != 0 {
+		t.Errorf("file in the task's zone rejected: %v", errs)
+	}
+	errs := validateScope(dir, "t1", []string{"web/index.ts"})
+	if len(errs) == 0 || !strings.Contains(strings.Join(errs, "\n"), "zone backend") {
+		t.Errorf("file outside the zone: %v", errs)
+	}
+}
+
+func TestSpawnQueuesOverZoneMaxWorkers(t *testing.T) {
+	_, missionDir, cleanup := setupTestMission(t)
+	defer cleanup()
+	installFakeClaude(t)
+
+	update := newZoneCmd(runZoneUpdate)
+	update.Flags().Set("max-workers", "1")
+	if err := update.RunE(update, []string{"backend"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, task := range []string{"first", "second"} {
+		cmd := newSpawnCmd()
+		cmd.Flags().Set("zone", "backend")
+		if err := cmd.RunE(cmd, []string{"developer", task}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var state WorkersState
+	if err := readJSON(filepath.Join(missionDir, "state", "workers.json"), &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Workers) != 2 || state.Workers[1].Status != "queued" {
+		t.Errorf("workers = %+v, want the second queued by the zone's maxWorkers", state.Workers)
+	}
+}
@@ -1,4 +1,4 @@
-use tiktoken_rs::cl100k_base;
+use tiktoken_rs::{cl100k_base, o200k_base};
 
 pub struct TokenCounter {
     bpe: tiktoken_rs::CoreBPE,
@@ -11,6 +11,19 @@ impl TokenCounter {
         }
     }
 
+    /// Counter for a named encoding ("cl100k_base" or "o200k_base").
+    /// Returns None for encodings tiktoken doesn't ship.
+    pub fn with_encoding(name: &str) -> Option<Self> {
+        let bpe = match name {
+            "cl100k_base" => cl100k_base(),
+            "o200k_base" => o200k_base(),
+            _ => return None,
+        };
+        Some(Self {
+            bpe: bpe.expect("Failed to initialize tiktoken"),
+        })
+    }
+
     pub fn count(&self, text: &str) -> usize {
         self.bpe.encode_with_special_tokens(text).len()
     }
@@ -45,4 +58,12 @@ mod tests {
         let count = counter.count(text);
         assert!(count > 10);
     }
+
+    #[test]
+    fn test_with_encoding() {
+        let o200k = TokenCounter::with_encoding("o200k_base").unwrap();
+        assert!(o200k.count("hello world") > 0);
+        assert!(TokenCounter::with_encoding("cl100k_base").is_some());
+        assert!(TokenCounter::with_encoding("llama").is_none());
+    }
 }
@@ -36,6 +36,9 @@ enum Commands {
         /// Path to file, or "-" for stdin (default: stdin)
         #[arg(default_value = "-")]
         source: String,
+        /// Tokenizer encoding: cl100k_base or o200k_base
+        #[arg(long, default_value = "cl100k_base")]
+        encoding: String,
     },
     /// Compile a checkpoint JSON file into a markdown briefing
     CheckpointCompile {
@@ -90,8 +93,8 @@ fn main() -> Result<()> {
             let result = check_gate(&stage, &mission_dir)?;
             println!("{}", serde_json::to_string_pretty(&result)?);
         }
-        Commands::CountTokens { source } => {
-            let result = count_tokens(&source)?;
+        Commands::CountTokens { source, encoding } => {
+            let result = count_tokens(&source, &encoding)?;
             println!("{}", serde_json::to_string(&result)?);
         }
         Commands::CheckpointCompile { file } => {
@@ -332,7 +335,7 @@ fn check_gate(stage_str: &str, mission_dir: &Path) -> Result<GateCheckResult> {
     })
 }
 
-fn count_tokens(source: &str) -> Result<TokenCountResult> {
+fn count_tokens(source: &str, encoding: &str) -> Result<TokenCountResult> {
     let content = if source == "-" {
         // Read from stdin
         let mut buffer = String::new();
@@ -346,7 +349,8 @@ fn count_tokens(source: &str) -> Result<TokenCountResult> {
             .with_context(|| format!("Failed to read file: {}", source))?
     };
 
-    let counter = TokenCounter::new();
+    let counter = TokenCounter::with_encoding(encoding)
+        .ok_or_else(|| anyhow::anyhow!("Unknown encoding: {}", encoding))?;
     let tokens = counter.count(&content);
 
     Ok(TokenCountResult { tokens })
@@ -446,8 +450,13 @@ mod tests {
         let mut file = NamedTempFile::new().unwrap();
         file.write_all(content.as_bytes()).unwrap();
 
-        let result = count_tokens(file.path().to_str().unwrap()).unwrap();
+        let result = count_tokens(file.path().to_str().unwrap(), "cl100k_base").unwrap();
         assert!(result.tokens > 0);
+
+        let result = count_tokens(file.path().to_str().unwrap(), "o200k_base").unwrap();
+        assert!(result.tokens > 0);
+
+        assert!(count_tokens(file.path().to_str().unwrap(), "llama").is_err());
     }
 
     #[test]
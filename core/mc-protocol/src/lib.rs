@@ -1,4 +1,5 @@
 pub mod conversation;
+pub mod pricing;
 pub mod protocol;
 pub mod tokens;
 pub mod watcher;
@@ -0,0 +1,133 @@
+use std::collections::HashMap;
+use std::fs;
+use std::path::PathBuf;
+
+use serde::Deserialize;
+
+/// Newest pricing file format this build reads.
+pub const VERSION: u32 = 1;
+
+/// A model's cost in USD per million tokens.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Deserialize)]
+pub struct Price {
+    pub input_per_mtok: f64,
+    pub output_per_mtok: f64,
+    #[serde(default)]
+    pub cache_read_per_mtok: f64,
+}
+
+#[derive(Debug, Deserialize)]
+struct PricingFile {
+    version: u32,
+    #[serde(default)]
+    models: HashMap<String, Price>,
+}
+
+/// Per-model prices: the built-in defaults, overridden by
+/// ~/.mission-control/pricing.json. The defaults mirror
+/// orchestrator/pricing/defaults.json.
+#[derive(Debug, Clone)]
+pub struct PricingTable {
+    pub models: HashMap<String, Price>,
+}
+
+impl PricingTable {
+    pub fn defaults() -> Self {
+        let models = [
+            ("opus", 15.0, 75.0, 1.5),
+            ("sonnet", 3.0, 15.0, 0.3),
+            ("haiku", 0.25, 1.25, 0.03),
+            ("gpt-4o", 2.5, 10.0, 1.25),
+            ("gpt-4o-mini", 0.15, 0.6, 0.075),
+            ("gpt-4.1", 2.0, 8.0, 0.5),
+            ("gpt-4.1-mini", 0.4, 1.6, 0.1),
+        ]
+        .into_iter()
+        .map(|(name, input, output, cache)| {
+            (
+                name.to_string(),
+                Price {
+                    input_per_mtok: input,
+                    output_per_mtok: output,
+                    cache_read_per_mtok: cache,
+                },
+            )
+        })
+        .collect();
+        Self { models }
+    }
+
+    /// Merge a pricing file's models over the defaults. Unreadable files
+    /// and newer versions leave the defaults in place.
+    pub fn with_file(mut self, content: &str) -> Self {
+        if let Ok(file) = serde_json::from_str::<PricingFile>(content) {
+            if file.version <= VERSION {
+                for (model, price) in file.models {
+                    self.models.insert(model.to_lowercase(), price);
+                }
+            }
+        }
+        self
+    }
+
+    /// Price of a model: an exact entry, else the longest entry the model
+    /// ID starts with or names as a tier ("claude-sonnet-4" is "sonnet").
+    pub fn lookup(&self, model: &str) -> Option<Price> {
+        let m = model.trim().to_lowercase();
+        if m.is_empty() || m.starts_with("ollama:") {
+            return None;
+        }
+        if let Some(price) = self.models.get(&m) {
+            return Some(*price);
+        }
+        self.models
+            .iter()
+            .filter(|(key, _)| m.starts_with(key.as_str()) || m.contains(&format!("-{}", key)))
+            .max_by_key(|(key, _)| key.len())
+            .map(|(_, price)| *price)
+    }
+}
+
+/// Path of the user's pricing file.
+pub fn path() -> Option<PathBuf> {
+    std::env::var_os("HOME").map(|home| {
+        PathBuf::from(home)
+            .join(".mission-control")
+            .join("pricing.json")
+    })
+}
+
+/// Load the pricing table: defaults plus the user's pricing file, if any.
+pub fn load() -> PricingTable {
+    let table = PricingTable::defaults();
+    match path().and_then(|p| fs::read_to_string(p).ok()) {
+        Some(content) => table.with_file(&content),
+        None => table,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_lookup() {
+        let table = PricingTable::defaults();
+        assert_eq!(table.lookup("claude-sonnet-4").unwrap().input_per_mtok, 3.0);
+        assert_eq!(table.lookup("gpt-4o-mini-2024-07-18").unwrap().input_per_mtok, 0.15);
+        assert!(table.lookup("ollama:llama3").is_none());
+        assert!(table.lookup("mystery").is_none());
+    }
+
+    #[test]
+    fn test_with_file() {
+        let table = PricingTable::defaults().with_file(
+            r#"{"version":1,"models":{"sonnet":{"input_per_mtok":2,"output_per_mtok":10}}}"#,
+        );
+        assert_eq!(table.lookup("sonnet").unwrap().input_per_mtok, 2.0);
+        assert_eq!(table.lookup("haiku").unwrap().input_per_mtok, 0.25);
+
+        let newer = PricingTable::defaults().with_file(r#"{"version":2,"models":{}}"#);
+        assert_eq!(newer.lookup("sonnet").unwrap().input_per_mtok, 3.0);
+    }
+}
@@ -8,6 +8,11 @@ use serde::Serialize;
 
 use knowledge::TokenCounter;
 
+use crate::pricing;
+
+/// Model the King's conversation is priced at.
+pub const KING_MODEL: &str = "sonnet";
+
 #[derive(Debug, Serialize)]
 pub struct TokenUsage {
     pub total_tokens: usize,
@@ -80,9 +85,9 @@ pub fn count_tokens(path: &Path) -> Result<TokenUsage, String> {
     let counter = TokenCounter::new();
     let total_tokens = counter.count(&content);
 
-    // Estimate cost using Claude pricing (rough estimate)
-    // Input: $3/MTok, Output: $15/MTok - assume 50/50 split
-    let avg_cost_per_token = (0.003 + 0.015) / 2.0 / 1000.0;
+    // Rough estimate from the pricing table - assume a 50/50 input/output split
+    let price = pricing::load().lookup(KING_MODEL).unwrap_or_default();
+    let avg_cost_per_token = (price.input_per_mtok + price.output_per_mtok) / 2.0 / 1_000_000.0;
     let estimated_cost_usd = total_tokens as f64 * avg_cost_per_token;
 
     Ok(TokenUsage {
@@ -0,0 +1,273 @@
+// Package alerts keeps the notification center: alerts that need a human
+// (a gate ready for approval, the King asking for one, the token budget
+// running out, a worker failing, a task blocked), persisted in
+// .mission/orchestrator/alerts.json until acknowledged, so they outlast
+// the WebSocket events that raised them.
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/hashid"
+)
+
+// Alert kinds
+const (
+	KindGateReady      = "gate_ready"
+	KindBudgetWarning  = "budget_warning"
+	KindBudgetCritical = "budget_critical"
+	KindWorkerFailed   = "worker_failed"
+	KindBlocker        = "blocker_raised"
+	KindApproval       = "approval_requested"
+	KindSpecChanged    = "spec_changed"
+	KindStageOverrun   = "stage_overrun"
+	KindSpendLimit     = "spend_limit_exceeded"
+	KindBudgetForecast = "budget_forecast_exceeded"
+	KindGateEscalated  = "gate_escalated"
+)
+
+// Severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// FileName is the store file in .mission/orchestrator/.
+const FileName = "alerts.json"
+
+// MaxAlerts is how many alerts are kept; the oldest acknowledged ones go
+// first.
+const MaxAlerts = 500
+
+// ErrNotFound is returned when acknowledging an unknown alert.
+var ErrNotFound = errors.New("alert not found")
+
+// Alert is one notification.
+type Alert struct {
+	ID        string                 `json:"id"`
+	Kind      string                 `json:"kind"`
+	Severity  string                 `json:"severity"`
+	Title     string                 `json:"title"`
+	Key       string                 `json:"key"` // what it is about; an unread alert with the same key isn't repeated
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	Read      bool                   `json:"read"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+}
+
+// Counts are the badge counts: unread alerts, in total and by kind.
+type Counts struct {
+	Unread int            `json:"unread"`
+	ByKind map[string]int `json:"by_kind"`
+}
+
+// Store holds the alerts. OnAdd and OnChange, if set, are called after an
+// alert is added and after alerts are acknowledged, outside the lock.
+type Store struct {
+	path string
+
+	mu     sync.Mutex
+	alerts []Alert // oldest first
+
+	OnAdd    func(Alert, Counts)
+	OnChange func(Counts)
+}
+
+// Open loads the store at path; a missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Alerts []Alert `json:"alerts"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s.alerts = f.Alerts
+	return s, nil
+}
+
+// Add records an alert unless an unread one with the same key exists. It
+// returns the alert and whether it was added.
+func (s *Store) Add(kind, severity, key, title string, data map[string]interface{}) (Alert, bool) {
+	if s == nil {
+		return Alert{}, false
+	}
+	s.mu.Lock()
+	for _, a := range s.alerts {
+		if a.Key == key && !a.Read {
+			s.mu.Unlock()
+			return a, false
+		}
+	}
+	now := time.Now().UTC()
+	a := Alert{
+		ID:        hashid.Generate("alert", kind, key, now.Format(time.RFC3339Nano)),
+		Kind:      kind,
+		Severity:  severity,
+		Title:     title,
+		Key:       key,
+		Data:      data,
+		CreatedAt: now,
+	}
+	s.alerts = append(s.alerts, a)
+	s.trim()
+	s.save()
+	counts := s.counts()
+	s.mu.Unlock()
+
+	if s.OnAdd != nil {
+		s.OnAdd(a, counts)
+	}
+	return a, true
+}
+
+// List returns the alerts newest first, only the unread ones with
+// unreadOnly, and only those of kind when it isn't empty.
+func (s *Store) List(unreadOnly bool, kind string) []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []Alert{}
+	for i := len(s.alerts) - 1; i >= 0; i-- {
+		a := s.alerts[i]
+		if (unreadOnly && a.Read) || (kind != "" && a.Kind != kind) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// Ack marks the alert with id read.
+func (s *Store) Ack(id string) (Alert, error) {
+	s.mu.Lock()
+	var found *Alert
+	for i := range s.alerts {
+		if s.alerts[i].ID == id {
+			found = &s.alerts[i]
+			break
+		}
+	}
+	if found == nil {
+		s.mu.Unlock()
+		return Alert{}, ErrNotFound
+	}
+	changed := !found.Read
+	if changed {
+		now := time.Now().UTC()
+		found.Read, found.ReadAt = true, &now
+		s.save()
+	}
+	a, counts := *found, s.counts()
+	s.mu.Unlock()
+
+	if changed && s.OnChange != nil {
+		s.OnChange(counts)
+	}
+	return a, nil
+}
+
+// AckAll marks every unread alert read, or those of kind when it isn't
+// empty, and returns how many were.
+func (s *Store) AckAll(kind string) int {
+	s.mu.Lock()
+	now := time.Now().UTC()
+	n := 0
+	for i := range s.alerts {
+		a := &s.alerts[i]
+		if a.Read || (kind != "" && a.Kind != kind) {
+			continue
+		}
+		a.Read, a.ReadAt = true, &now
+		n++
+	}
+	if n > 0 {
+		s.save()
+	}
+	counts := s.counts()
+	s.mu.Unlock()
+
+	if n > 0 && s.OnChange != nil {
+		s.OnChange(counts)
+	}
+	return n
+}
+
+// Counts returns the badge counts.
+func (s *Store) Counts() Counts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts()
+}
+
+func (s *Store) counts() Counts {
+	c := Counts{ByKind: map[string]int{}}
+	for _, a := range s.alerts {
+		if !a.Read {
+			c.Unread++
+			c.ByKind[a.Kind]++
+		}
+	}
+	return c
+}
+
+// trim drops the oldest alerts past MaxAlerts, read ones first. Callers
+// hold s.mu.
+func (s *Store) trim() {
+	excess := len(s.alerts) - MaxAlerts
+	if excess <= 0 {
+		return
+	}
+	drop := map[int]bool{}
+	for pass := 0; pass < 2 && len(drop) < excess; pass++ {
+		for i, a := range s.alerts {
+			if len(drop) == excess {
+				break
+			}
+			if (pass == 0 && a.Read) || pass == 1 {
+				drop[i] = true
+			}
+		}
+	}
+	kept := make([]Alert, 0, len(s.alerts)-excess)
+	for i, a := range s.alerts {
+		if !drop[i] {
+			kept = append(kept, a)
+		}
+	}
+	s.alerts = kept
+}
+
+// save writes the store; failures are logged, alerts stay in memory.
+// Callers hold s.mu.
+func (s *Store) save() {
+	if s.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{"alerts": s.alerts}, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.path), 0755)
+	}
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = os.WriteFile(tmp, append(data, '\n'), 0644); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saving alerts: %v\n", err)
+	}
+}
@@ -0,0 +1,105 @@
+package alerts
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added, changed int
+	var badge Counts
+	s.OnAdd = func(a Alert, c Counts) { added++; badge = c }
+	s.OnChange = func(c Counts) { changed++; badge = c }
+
+	s.Observe("gate_ready", map[string]interface{}{"stage": "design"})
+	s.Observe("gate_ready", map[string]interface{}{"stage": "design"}) // unread duplicate
+	s.Observe("task_updated", map[string]interface{}{"task_id": "t1", "status": "blocked"})
+	s.Observe("task_updated", map[string]interface{}{"task_id": "t2", "status": "done"})
+	s.Observe("worker_status_changed", map[string]interface{}{"worker_id": "w1", "status": "error"})
+	s.Budget("w2", 1000, 800, 200)
+	s.Budget("w2", 1000, 1000, 0)
+
+	if added != 5 || badge.Unread != 5 || badge.ByKind[KindBudgetCritical] != 1 {
+		t.Fatalf("added %d, badge %+v", added, badge)
+	}
+	list := s.List(false, "")
+	if list[0].Kind != KindBudgetCritical || list[len(list)-1].Kind != KindGateReady {
+		t.Errorf("not newest first: %+v", list)
+	}
+
+	gate := s.List(true, KindGateReady)[0]
+	if _, err := s.Ack(gate.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Ack("nope"); err != ErrNotFound {
+		t.Errorf("Ack(nope) = %v", err)
+	}
+	if changed != 1 || badge.Unread != 4 {
+		t.Errorf("after ack: changed %d, badge %+v", changed, badge)
+	}
+
+	// Acknowledged, the gate can alert again
+	s.Observe("gate_ready", map[string]interface{}{"stage": "design"})
+	if n := len(s.List(false, KindGateReady)); n != 2 {
+		t.Errorf("gate alerts = %d, want 2", n)
+	}
+
+	if n := s.AckAll(KindBudgetWarning); n != 1 {
+		t.Errorf("AckAll(budget_warning) = %d", n)
+	}
+
+	// Reopened, the read state is kept
+	s2, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := s2.Counts(); c.Unread != 4 || c.ByKind[KindBudgetWarning] != 0 {
+		t.Errorf("reopened counts = %+v", c)
+	}
+	if n := s2.AckAll(""); n != 4 || s2.Counts().Unread != 0 {
+		t.Errorf("AckAll = %d, counts %+v", n, s2.Counts())
+	}
+}
+
+func TestTrimDropsReadFirst(t *testing.T) {
+	s, _ := Open("")
+	first, _ := s.Add(KindBlocker, SeverityWarning, "unread", "keep", nil)
+	read, _ := s.Add(KindBlocker, SeverityWarning, "read", "drop", nil)
+	s.Ack(read.ID)
+	for i := 0; i < MaxAlerts-1; i++ {
+		s.Add(KindGateReady, SeverityInfo, fmt.Sprintf("gate:%d", i), "gate", nil)
+	}
+	list := s.List(false, "")
+	if len(list) != MaxAlerts {
+		t.Fatalf("kept %d alerts", len(list))
+	}
+	for _, a := range list {
+		if a.ID == read.ID {
+			t.Error("read alert kept")
+		}
+	}
+	if list[len(list)-1].ID != first.ID {
+		t.Error("oldest unread alert dropped before the read one")
+	}
+}
+
+func TestSpecChangedNeedsOpenTasks(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Observe("spec_changed", map[string]interface{}{"id": "signup", "linked_tasks": []interface{}{}})
+	// As replayed from the event log, the task list is []interface{}
+	s.Observe("spec_changed", map[string]interface{}{"id": "login", "linked_tasks": []interface{}{"t1", "t2"}})
+
+	list := s.List(false, KindSpecChanged)
+	if len(list) != 1 || list[0].Data["spec"] != "login" || list[0].Severity != SeverityWarning {
+		t.Fatalf("spec alerts = %+v", list)
+	}
+}
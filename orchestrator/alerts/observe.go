@@ -0,0 +1,137 @@
+package alerts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Observe raises the alert, if any, for a watcher event: gate_ready, a
+// task moving to blocked, a worker moving to error, or a spec changing
+// under open tasks.
+func (s *Store) Observe(eventType string, data interface{}) {
+	if s == nil {
+		return
+	}
+	m, _ := data.(map[string]interface{})
+	str := func(k string) string {
+		v, _ := m[k].(string)
+		return v
+	}
+	switch eventType {
+	case "gate_ready":
+		stage := str("stage")
+		s.Add(KindGateReady, SeverityInfo, "gate:"+stage,
+			fmt.Sprintf("Gate %s is ready for approval", stage),
+			map[string]interface{}{"stage": stage})
+	case "task_updated":
+		if str("status") == "blocked" {
+			s.Blocker(str("task_id"))
+		}
+	case "worker_status_changed":
+		if status := str("status"); status == "error" || status == "failed" {
+			s.WorkerFailed(str("worker_id"), "", status)
+		}
+	case "spec_changed":
+		var tasks []string
+		switch v := m["linked_tasks"].(type) {
+		case []string:
+			tasks = v
+		case []interface{}:
+			for _, t := range v {
+				if id, ok := t.(string); ok {
+					tasks = append(tasks, id)
+				}
+			}
+		}
+		if len(tasks) > 0 {
+			s.SpecChanged(str("id"), tasks)
+		}
+	}
+}
+
+// Blocker raises a blocker_raised alert for a blocked task.
+func (s *Store) Blocker(taskID string) {
+	s.Add(KindBlocker, SeverityWarning, "blocker:"+taskID,
+		fmt.Sprintf("Task %s is blocked", taskID),
+		map[string]interface{}{"task_id": taskID})
+}
+
+// WorkerFailed raises a worker_failed alert. reason is why, e.g. "error"
+// for a worker whose process died or "reaped" for one that went silent.
+func (s *Store) WorkerFailed(workerID, taskID, reason string) {
+	data := map[string]interface{}{"worker_id": workerID, "reason": reason}
+	if taskID != "" {
+		data["task_id"] = taskID
+	}
+	s.Add(KindWorkerFailed, SeverityCritical, "worker:"+workerID,
+		fmt.Sprintf("Worker %s failed (%s)", workerID, reason), data)
+}
+
+// Budget raises a budget alert from the token accumulator's warning: a
+// budget_warning while some budget remains, budget_critical once it is
+// used up.
+func (s *Store) Budget(workerID string, budget, used, remaining int) {
+	data := map[string]interface{}{
+		"worker_id": workerID,
+		"budget":    budget,
+		"used":      used,
+		"remaining": remaining,
+	}
+	if remaining <= 0 {
+		s.Add(KindBudgetCritical, SeverityCritical, "budget_critical:"+workerID,
+			fmt.Sprintf("Worker %s used its token budget (%d/%d)", workerID, used, budget), data)
+		return
+	}
+	s.Add(KindBudgetWarning, SeverityWarning, "budget_warning:"+workerID,
+		fmt.Sprintf("Worker %s is at %d%% of its token budget", workerID, used*100/budget), data)
+}
+
+// ApprovalRequested raises an approval_requested alert for a gate approval
+// the King asked for, which id confirms.
+func (s *Store) ApprovalRequested(id, stage, note string) {
+	s.Add(KindApproval, SeverityInfo, "approval:"+id,
+		fmt.Sprintf("The King asks to approve the %s gate", stage),
+		map[string]interface{}{"approval_id": id, "stage": stage, "note": note})
+}
+
+// SpecChanged raises a spec_changed alert for a spec edited or removed
+// while tasks linked to it are still open.
+func (s *Store) SpecChanged(spec string, taskIDs []string) {
+	s.Add(KindSpecChanged, SeverityWarning, "spec:"+spec,
+		fmt.Sprintf("Spec %s changed under %d open task(s)", spec, len(taskIDs)),
+		map[string]interface{}{"spec": spec, "task_ids": taskIDs})
+}
+
+// StageOverrun raises a stage_overrun alert for a stage that has run past
+// its SLA.
+func (s *Store) StageOverrun(stage, sla string, elapsedMin int) {
+	s.Add(KindStageOverrun, SeverityWarning, "overrun:"+stage,
+		fmt.Sprintf("Stage %s has run %dh%02dm, over its %s SLA", stage, elapsedMin/60, elapsedMin%60, sla),
+		map[string]interface{}{"stage": stage, "sla": sla, "elapsed_min": elapsedMin})
+}
+
+// GateEscalated raises a gate_escalated alert each time a gate awaiting
+// approval passes another escalation level. severity is warning, or
+// critical at the last level.
+func (s *Store) GateEscalated(stage string, level, levels int, severity string, pendingMin int) {
+	s.Add(KindGateEscalated, severity, fmt.Sprintf("gate_escalated:%s:%d", stage, level),
+		fmt.Sprintf("Gate %s has awaited approval %dh%02dm (escalation %d of %d)", stage, pendingMin/60, pendingMin%60, level, levels),
+		map[string]interface{}{"stage": stage, "level": level, "levels": levels, "pending_min": pendingMin})
+}
+
+// SpendLimit raises a spend_limit_exceeded alert when the global spend
+// limits trip the breaker. exceeded names the limits reached.
+func (s *Store) SpendLimit(exceeded []string, costUSD float64, tokens int) {
+	s.Add(KindSpendLimit, SeverityCritical, "spend:"+strings.Join(exceeded, ","),
+		fmt.Sprintf("Spend limit reached (%s): new workers and King messages are paused", strings.Join(exceeded, ", ")),
+		map[string]interface{}{"exceeded": exceeded, "cost_usd": costUSD, "tokens": tokens})
+}
+
+// BudgetForecast raises a budget_forecast_exceeded alert when the
+// projected end-of-mission spend goes over budget. exceeds names the
+// budgets it goes over.
+func (s *Store) BudgetForecast(exceeds []string, projectedUSD float64, projectedTokens int) {
+	s.Add(KindBudgetForecast, SeverityWarning, "forecast:"+strings.Join(exceeds, ","),
+		fmt.Sprintf("Projected spend ($%.2f, %d tokens) is over the mission budget", projectedUSD, projectedTokens),
+		map[string]interface{}{"exceeds": exceeds, "projected_usd": projectedUSD, "projected_tokens": projectedTokens})
+}
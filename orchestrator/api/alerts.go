@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/alerts"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// AlertsResponse is the response for GET /api/alerts.
+type AlertsResponse struct {
+	Alerts []alerts.Alert `json:"alerts"`
+	alerts.Counts
+}
+
+// AlertAckRequest is the optional body of POST /api/alerts/ack: the alerts
+// to acknowledge, or with no IDs every unread one (of Kind, if set).
+type AlertAckRequest struct {
+	IDs  []string `json:"ids,omitempty"`
+	Kind string   `json:"kind,omitempty"`
+}
+
+// SetAlerts serves store on /api/alerts.
+func (s *Server) SetAlerts(store *alerts.Store) {
+	s.mu.Lock()
+	s.alerts = store
+	s.mu.Unlock()
+}
+
+func (s *Server) alertStore(w http.ResponseWriter) *alerts.Store {
+	s.mu.RLock()
+	store := s.alerts
+	s.mu.RUnlock()
+	if store == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.CodeUnavailable, "alerts are not available", nil)
+	}
+	return store
+}
+
+// handleAlerts serves GET /api/alerts, newest first, with the unread
+// counts. ?unread=true leaves out acknowledged alerts, ?kind= filters by
+// kind and ?limit= caps the list.
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	store := s.alertStore(w)
+	if store == nil {
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	list := store.List(q.Get("unread") == "true", q.Get("kind"))
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	writeJSON(w, http.StatusOK, AlertsResponse{Alerts: list, Counts: store.Counts()})
+}
+
+// handleAlertAck serves POST /api/alerts/{id}/ack and POST
+// /api/alerts/ack.
+func (s *Server) handleAlertAck(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/alerts/")
+	if rest == "ack" {
+		s.handleAlertAckMany(w, r)
+		return
+	}
+	id, action, _ := strings.Cut(rest, "/")
+	if action != "ack" || id == "" {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	store := s.alertStore(w)
+	if store == nil {
+		return
+	}
+	a, err := store.Ack(id)
+	if errors.Is(err, alerts.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "alert not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"alert":  a,
+		"counts": store.Counts(),
+	})
+}
+
+func (s *Server) handleAlertAckMany(w http.ResponseWriter, r *http.Request) {
+	var req AlertAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		problem.InvalidBody(w, err)
+		return
+	}
+	store := s.alertStore(w)
+	if store == nil {
+		return
+	}
+	acked := 0
+	if len(req.IDs) == 0 {
+		acked = store.AckAll(req.Kind)
+	} else {
+		known := map[string]bool{}
+		for _, a := range store.List(false, "") {
+			known[a.ID] = true
+		}
+		for _, id := range req.IDs {
+			if !known[id] {
+				problem.Validation(w, "unknown alert: "+id)
+				return
+			}
+		}
+		for _, id := range req.IDs {
+			if _, err := store.Ack(id); err == nil {
+				acked++
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"acked":  acked,
+		"counts": store.Counts(),
+	})
+}
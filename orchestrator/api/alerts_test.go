@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/alerts"
+)
+
+func TestAlerts(t *testing.T) {
+	s, _ := newTestServer(t)
+	routes := s.Routes()
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/alerts", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("without a store = %d", w.Code)
+	}
+
+	store, _ := alerts.Open("")
+	s.SetAlerts(store)
+	gate, _ := store.Add(alerts.KindGateReady, alerts.SeverityInfo, "gate:design", "Gate design is ready", nil)
+	store.WorkerFailed("w1", "t1", "process exited")
+	store.Blocker("t2")
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("POST", "/api/alerts/"+gate.ID+"/ack", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ack %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/alerts?unread=true", nil))
+	var resp AlertsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Alerts) != 2 || resp.Unread != 2 || resp.ByKind[alerts.KindWorkerFailed] != 1 {
+		t.Fatalf("list %d: %s", w.Code, w.Body.String())
+	}
+
+	for body, want := range map[string]int{
+		`{"ids": ["missing"]}`:      http.StatusBadRequest,
+		`{"kind": "worker_failed"}`: http.StatusOK,
+		``:                          http.StatusOK,
+	} {
+		w = httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("POST", "/api/alerts/ack", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("ack %q = %d, want %d: %s", body, w.Code, want, w.Body.String())
+		}
+	}
+	if c := store.Counts(); c.Unread != 0 {
+		t.Errorf("unread after ack all = %d", c.Unread)
+	}
+
+	for path, want := range map[string]int{
+		"/api/alerts/missing/ack": http.StatusNotFound,
+		"/api/alerts/" + gate.ID:  http.StatusNotFound,
+	} {
+		w = httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		if w.Code != want {
+			t.Errorf("%s = %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+const (
+	// maxBurndownDays caps the burn-down series; older days are dropped.
+	maxBurndownDays = 366
+	// velocityWindow is how far back velocity averages completed work.
+	velocityWindow = 7 * 24 * time.Hour
+)
+
+// auditEntries returns every audit entry, archives included, oldest first.
+func (s *Server) auditEntries() ([]audit.Entry, error) {
+	res, err := audit.Query(s.missionPath(), audit.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]audit.Entry, 0, len(res.Entries))
+	for _, raw := range res.Entries {
+		var e audit.Entry
+		if json.Unmarshal(raw, &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (s *Server) handleBurndown(w http.ResponseWriter, r *http.Request) {
+	since, err := parseTimeParam(r.URL.Query().Get("since"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+		return
+	}
+	entries, err := s.auditEntries()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tasks, _ := s.readTasks()
+	writeJSON(w, http.StatusOK, buildBurndown(tasks, entries, since, time.Now().UTC()))
+}
+
+func (s *Server) handleStageDurations(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.auditEntries()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var stage struct {
+		Current string `json:"current"`
+	}
+	_ = readJSON(s.statePath("stage.json"), &stage)
+	writeJSON(w, http.StatusOK, buildStageDurations(entries, stage.Current, time.Now().UTC()))
+}
+
+// taskTime parses one of a task's RFC3339 timestamps.
+func taskTime(v string) time.Time {
+	ts, _ := time.Parse(time.RFC3339, v)
+	return ts
+}
+
+// completionTimes returns when each task was last marked finished in the
+// audit log: by mc task update or by a worker's handoff.
+func completionTimes(entries []audit.Entry) map[string]time.Time {
+	done := map[string]time.Time{}
+	for _, e := range entries {
+		var status string
+		switch e.Action {
+		case "task_completed", "task_updated":
+			status = fmt.Sprint(e.Details["new_status"])
+		case "handoff_received":
+			status = fmt.Sprint(e.Details["status"])
+		default:
+			continue
+		}
+		id, _ := e.Details["task_id"].(string)
+		if id != "" && taskFinished(status) && !e.Time().IsZero() {
+			done[id] = e.Time()
+		}
+	}
+	return done
+}
+
+// buildBurndown computes the daily burn-down of task estimates up to now.
+// A task counts towards scope from when it was created and as completed
+// from when the audit log last shows it finished; a finished task with no
+// such entry falls back to its updated_at.
+func buildBurndown(tasks []Task, entries []audit.Entry, since, now time.Time) BurndownResponse {
+	created := map[string]time.Time{}
+	for _, e := range entries {
+		if e.Action != "task_created" {
+			continue
+		}
+		if id, _ := e.Details["task_id"].(string); id != "" {
+			created[id] = e.Time()
+		}
+	}
+	done := completionTimes(entries)
+
+	type span struct {
+		estimate    float64
+		start, stop time.Time // stop is zero while unfinished
+	}
+	spans := make([]span, 0, len(tasks))
+	resp := BurndownResponse{Points: []BurndownPoint{}}
+	var first time.Time
+	for _, t := range tasks {
+		if t.Status == bridge.TaskStatusArchived {
+			continue
+		}
+		sp := span{estimate: taskEstimate(t), start: taskTime(t.CreatedAt)}
+		if sp.start.IsZero() {
+			sp.start = created[t.ID]
+		}
+		if t.Finished() {
+			if sp.stop = done[t.ID]; sp.stop.IsZero() {
+				sp.stop = taskTime(t.UpdatedAt)
+			}
+			if sp.stop.IsZero() {
+				sp.stop = now
+			}
+		}
+		if !sp.start.IsZero() && (first.IsZero() || sp.start.Before(first)) {
+			first = sp.start
+		}
+		spans = append(spans, sp)
+
+		resp.Total += sp.estimate
+		if sp.stop.IsZero() {
+			resp.Remaining += sp.estimate
+		} else if now.Sub(sp.stop) < velocityWindow {
+			resp.Velocity += sp.estimate
+		}
+	}
+	if len(spans) == 0 {
+		return resp
+	}
+	if first.IsZero() {
+		first = now
+	}
+
+	window := now.Sub(first)
+	if window > velocityWindow {
+		window = velocityWindow
+	}
+	resp.Velocity /= max(window.Hours()/24, 1)
+
+	day := first.UTC().Truncate(24 * time.Hour)
+	if !since.IsZero() && since.After(day) {
+		day = since.UTC().Truncate(24 * time.Hour)
+	}
+	if oldest := now.Truncate(24*time.Hour).AddDate(0, 0, 1-maxBurndownDays); day.Before(oldest) {
+		day = oldest
+	}
+	for ; !day.After(now); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		p := BurndownPoint{Date: day.Format("2006-01-02")}
+		for _, sp := range spans {
+			if sp.start.IsZero() || sp.start.Before(end) {
+				p.Scope += sp.estimate
+				if !sp.stop.IsZero() && sp.stop.Before(end) {
+					p.Completed += sp.estimate
+				}
+			}
+		}
+		p.Remaining = p.Scope - p.Completed
+		resp.Points = append(resp.Points, p)
+	}
+	return resp
+}
+
+// buildStageDurations splits the audit log into the periods spent in each
+// stage: mc init starts discovery, and each stage_advanced or stage_set
+// entry ends one period and starts the next. The last period runs until
+// now when it is the current stage.
+func buildStageDurations(entries []audit.Entry, current string, now time.Time) StageDurationsResponse {
+	resp := StageDurationsResponse{Stages: []StageDuration{}, Periods: []StagePeriod{}}
+	var stage string
+	var start time.Time
+	closePeriod := func(end time.Time) {
+		if stage == "" {
+			return
+		}
+		p := StagePeriod{Stage: stage, Start: start.Format(time.RFC3339), Seconds: end.Sub(start).Seconds()}
+		if !end.Equal(now) {
+			p.End = end.Format(time.RFC3339)
+		}
+		resp.Periods = append(resp.Periods, p)
+	}
+
+	for _, e := range entries {
+		var next string
+		switch e.Action {
+		case "project_initialized":
+			next = "discovery"
+		case "stage_advanced":
+			next, _ = e.Details["to_stage"].(string)
+		case "stage_set":
+			next, _ = e.Details["stage"].(string)
+		}
+		at := e.Time()
+		if next == "" || at.IsZero() || next == stage {
+			continue
+		}
+		closePeriod(at)
+		stage, start = next, at
+	}
+	if stage != "" && (current == "" || current == stage) {
+		closePeriod(now)
+	}
+
+	index := map[string]int{}
+	for _, p := range resp.Periods {
+		i, ok := index[p.Stage]
+		if !ok {
+			i = len(resp.Stages)
+			index[p.Stage] = i
+			resp.Stages = append(resp.Stages, StageDuration{Stage: p.Stage})
+		}
+		resp.Stages[i].Seconds += p.Seconds
+		resp.Stages[i].Visits++
+		resp.Stages[i].Current = p.End == ""
+	}
+	return resp
+}
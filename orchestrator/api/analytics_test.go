@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+)
+
+func auditAt(ts, action string, details map[string]interface{}) audit.Entry {
+	return audit.Entry{Timestamp: ts, Action: action, Actor: "cli", Details: details}
+}
+
+func TestBuildBurndown(t *testing.T) {
+	now := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	tasks := []Task{
+		{ID: "a", Status: "done", Estimate: 3, CreatedAt: "2026-03-01T09:00:00Z"},
+		{ID: "b", Status: "complete", CreatedAt: "2026-03-01T10:00:00Z", UpdatedAt: "2026-03-03T08:00:00Z"},
+		{ID: "c", Status: "pending", Estimate: 2, CreatedAt: "2026-03-02T10:00:00Z"},
+	}
+	entries := []audit.Entry{
+		auditAt("2026-03-02T15:00:00Z", "task_completed", map[string]interface{}{"task_id": "a", "new_status": "done"}),
+	}
+
+	resp := buildBurndown(tasks, entries, time.Time{}, now)
+	if resp.Total != 6 || resp.Remaining != 2 {
+		t.Errorf("total/remaining = %v/%v, want 6/2", resp.Total, resp.Remaining)
+	}
+	want := []BurndownPoint{
+		{Date: "2026-03-01", Scope: 4, Completed: 0, Remaining: 4},
+		{Date: "2026-03-02", Scope: 6, Completed: 3, Remaining: 3},
+		{Date: "2026-03-03", Scope: 6, Completed: 4, Remaining: 2},
+	}
+	if len(resp.Points) != len(want) {
+		t.Fatalf("points = %+v", resp.Points)
+	}
+	for i, p := range want {
+		if resp.Points[i] != p {
+			t.Errorf("point %d = %+v, want %+v", i, resp.Points[i], p)
+		}
+	}
+	// 4 completed over the 2.125 days since the first task
+	if v := resp.Velocity; v < 1.88 || v > 1.89 {
+		t.Errorf("velocity = %v, want 4/2.125", v)
+	}
+
+	resp = buildBurndown(tasks, entries, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), now)
+	if len(resp.Points) != 1 || resp.Points[0].Date != "2026-03-03" {
+		t.Errorf("since: points = %+v", resp.Points)
+	}
+
+	resp = buildBurndown(nil, nil, time.Time{}, now)
+	if resp.Points == nil || len(resp.Points) != 0 || resp.Total != 0 {
+		t.Errorf("empty = %+v", resp)
+	}
+}
+
+func TestBuildStageDurations(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []audit.Entry{
+		auditAt("2026-03-01T08:00:00Z", "project_initialized", nil),
+		auditAt("2026-03-01T09:00:00Z", "stage_advanced", map[string]interface{}{"from_stage": "discovery", "to_stage": "goal"}),
+		auditAt("2026-03-01T09:30:00Z", "task_created", map[string]interface{}{"task_id": "a"}),
+		auditAt("2026-03-01T10:00:00Z", "stage_set", map[string]interface{}{"stage": "discovery"}),
+		auditAt("2026-03-01T11:00:00Z", "stage_advanced", map[string]interface{}{"from_stage": "discovery", "to_stage": "goal"}),
+	}
+
+	resp := buildStageDurations(entries, "goal", now)
+	if len(resp.Periods) != 4 {
+		t.Fatalf("periods = %+v", resp.Periods)
+	}
+	if last := resp.Periods[3]; last.Stage != "goal" || last.End != "" || last.Seconds != 3600 {
+		t.Errorf("current period = %+v", last)
+	}
+	want := []StageDuration{
+		{Stage: "discovery", Seconds: 7200, Visits: 2},
+		{Stage: "goal", Seconds: 7200, Visits: 2, Current: true},
+	}
+	if len(resp.Stages) != 2 || resp.Stages[0] != want[0] || resp.Stages[1] != want[1] {
+		t.Errorf("stages = %+v, want %+v", resp.Stages, want)
+	}
+}
+
+func TestAnalyticsRoutes(t *testing.T) {
+	s, dir := newTestServer(t)
+	created := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"),
+		[]byte(`{"id":"a","status":"pending","estimate":5,"created_at":"`+created+`"}`+"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".mission", audit.FileName),
+		[]byte(`{"timestamp":"`+created+`","action":"project_initialized","actor":"cli"}`+"\n"), 0644)
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics/burndown", nil))
+	var burndown BurndownResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &burndown)
+	if w.Code != http.StatusOK || burndown.Remaining != 5 || len(burndown.Points) == 0 {
+		t.Errorf("burndown: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics/stage-durations", nil))
+	var stages StageDurationsResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &stages)
+	if w.Code != http.StatusOK || len(stages.Stages) != 1 || stages.Stages[0].Stage != "discovery" {
+		t.Errorf("stage-durations: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/analytics/burndown?since=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad since: expected 400, got %d", w.Code)
+	}
+}
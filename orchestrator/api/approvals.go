@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/commands"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// handleApprovals serves GET /api/approvals, the gate approvals the King
+// requested, oldest first; ?status=pending keeps those still waiting.
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	list, err := commands.Approvals(s.missionPath())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		kept := []commands.Approval{}
+		for _, a := range list {
+			if a.Status == status {
+				kept = append(kept, a)
+			}
+		}
+		list = kept
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"approvals": list})
+}
+
+// handleApprovalAction serves POST /api/approvals/{id}/confirm, which
+// approves the gate with mc gate approve in the caller's name, as POST
+// /api/gates/{stage}/approve would, and POST /api/approvals/{id}/dismiss.
+// Both need the approver role.
+func (s *Server) handleApprovalAction(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/approvals/"), "/")
+	if id == "" || (action != "confirm" && action != "dismiss") {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !allowRole(w, r, s.getMissionDir(), identity.RoleApprover) {
+		return
+	}
+	a, err := commands.FindApproval(s.missionPath(), id)
+	if errors.Is(err, commands.ErrApprovalNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if a.Status != commands.ApprovalPending {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, "approval request already "+a.Status, nil)
+		return
+	}
+
+	who := UserFromContext(r.Context()).String()
+	if who == "" {
+		who = "api"
+	}
+	status := commands.ApprovalDismissed
+	var out string
+	if action == "confirm" {
+		status = commands.ApprovalConfirmed
+		if out, err = s.runMC(r.Context(), "gate", "approve", a.Stage, "--note", a.Note); err != nil {
+			respondCommandError(w, "mc gate approve failed", out)
+			return
+		}
+		s.broadcast(r.Context(), "gates", "gate_approved", map[string]string{"stage": a.Stage})
+	}
+	a, err = commands.ResolveApproval(s.missionPath(), id, status, who)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.broadcast(r.Context(), "gates", "gate_approval_"+status, a)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"approval": a,
+		"output":   out,
+	})
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/commands"
+)
+
+func TestApprovalDismiss(t *testing.T) {
+	s, dir := newTestServer(t)
+	if _, err := commands.RequestApproval(filepath.Join(dir, ".mission"), "req-1", "design", "Specs reviewed"); err != nil {
+		t.Fatal(err)
+	}
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := do("GET", "/api/approvals?status=pending")
+	var list struct {
+		Approvals []commands.Approval `json:"approvals"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Approvals) != 1 || list.Approvals[0].Stage != "design" {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+
+	if w := do("POST", "/api/approvals/req-1/dismiss"); w.Code != http.StatusOK {
+		t.Fatalf("dismiss: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/approvals/req-1/confirm"); w.Code != http.StatusConflict {
+		t.Errorf("confirm after dismiss: %d", w.Code)
+	}
+	if w := do("POST", "/api/approvals/nope/confirm"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown request: %d", w.Code)
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+)
+
+// handleBudgetForecast serves GET /api/budgets/forecast: the projected
+// end-of-mission spend against the budget in config.json.
+func (s *Server) handleBudgetForecast(w http.ResponseWriter, r *http.Request) {
+	f, err := budget.Compute(s.missionPath(), time.Now())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/MikeSquared-Agency/MissionControl/commands"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// handleCommands serves /api/commands: GET lists the acknowledged King
+// commands, newest first (?limit=); POST carries out one, like a request
+// dropped in .mission/commands/, and answers with its ack. Posting needs
+// the contributor role, or admin for stage_advance, and the command runs
+// in the caller's name. A gate_request also raises an approval_requested
+// alert.
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		acks, err := commands.Acks(s.missionPath())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit <= 0 || limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+		if len(acks) > limit {
+			acks = acks[:limit]
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"commands": acks})
+	case http.MethodPost:
+		var req commands.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			problem.InvalidBody(w, err)
+			return
+		}
+		need := identity.RoleContributor
+		if req.Command == "stage_advance" {
+			need = identity.RoleAdmin
+		}
+		if !allowRole(w, r, s.getMissionDir(), need) {
+			return
+		}
+		ack := commands.ExecuteAs(s.getMissionDir(), req, UserFromContext(r.Context()).String())
+		s.broadcast(r.Context(), "commands", "command_executed", ack)
+		if a := ack.Approval; a != nil && a.ID == ack.ID {
+			s.broadcast(r.Context(), "gates", "gate_approval_requested", a)
+			s.mu.RLock()
+			store := s.alerts
+			s.mu.RUnlock()
+			store.ApprovalRequested(a.ID, a.Stage, a.Note)
+		}
+		switch ack.Status {
+		case commands.StatusRejected:
+			problem.Write(w, http.StatusBadRequest, problem.CodeValidation, ack.Error, map[string]interface{}{"ack": ack})
+		case commands.StatusFailed:
+			problem.Write(w, http.StatusInternalServerError, problem.CodeCommandFailed, ack.Error, map[string]interface{}{"ack": ack})
+		case commands.StatusPending:
+			writeJSON(w, http.StatusAccepted, ack)
+		default:
+			writeJSON(w, http.StatusOK, ack)
+		}
+	default:
+		problem.MethodNotAllowed(w)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/commands"
+)
+
+func TestCommandsRejectedAndListed(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("POST", "/api/commands", strings.NewReader(`{"id": "c1", "command": "spawn", "args": {"persona": "developer"}}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "args.task is required") {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/commands", nil))
+	var resp struct {
+		Commands []commands.Ack `json:"commands"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Commands) != 1 || resp.Commands[0].Status != commands.StatusRejected {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCommandsRequireRole(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"roles": {"dev@example.com": "contributor", "*": "viewer"}}`), 0644)
+	t.Setenv("MC_API_TOKEN", "shared")
+	t.Setenv("MC_API_TOKENS", "dev@example.com=dev")
+	handler := AuthMiddleware(s.Routes())
+
+	post := func(token, body string) int {
+		req := httptest.NewRequest("POST", "/api/commands", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(UserHeader, "king")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	// Rejected for its arguments only once the role allows it
+	spawn := `{"id": "c1", "command": "spawn", "args": {"persona": "developer"}}`
+	if code := post("shared", spawn); code != http.StatusForbidden {
+		t.Errorf("expected a viewer refused, got %d", code)
+	}
+	if code := post("dev", spawn); code != http.StatusBadRequest {
+		t.Errorf("expected a contributor let through, got %d", code)
+	}
+	if code := post("dev", `{"id": "c2", "command": "stage_advance"}`); code != http.StatusForbidden {
+		t.Errorf("expected stage_advance to need an admin, got %d", code)
+	}
+}
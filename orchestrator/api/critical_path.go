@@ -0,0 +1,144 @@
+package api
+
+import "math"
+
+// slackEpsilon absorbs float rounding when comparing schedule times
+const slackEpsilon = 1e-9
+
+// taskFinished reports whether a task no longer gates anything.
+func taskFinished(status string) bool {
+	return status == "done" || status == "complete"
+}
+
+// taskEstimate is a task's "estimate" if it has a positive one, else 1,
+// so that without estimates the critical path is the longest chain by
+// task count.
+func taskEstimate(t Task) float64 {
+	if t.Estimate > 0 {
+		return t.Estimate
+	}
+	return 1
+}
+
+// markCriticalPath schedules the unfinished tasks in work (ID → estimate)
+// as early as their dependencies allow. It sets each one's slack, marks
+// the tasks and edges with none as critical, and returns the critical
+// path with its length. Finished tasks gate nothing and are left out, as
+// are tasks in or behind a dependency cycle.
+func markCriticalPath(nodes []GraphNode, edges []GraphEdge, work map[string]float64) ([]string, float64) {
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+	}
+
+	// Dependency edges between unfinished tasks, in node order
+	succs := map[string][]string{}
+	preds := map[string][]string{}
+	seen := map[[2]string]bool{}
+	for _, e := range edges {
+		_, from := work[e.Source]
+		_, to := work[e.Target]
+		key := [2]string{e.Source, e.Target}
+		if !from || !to || seen[key] {
+			continue
+		}
+		seen[key] = true
+		succs[e.Source] = append(succs[e.Source], e.Target)
+		preds[e.Target] = append(preds[e.Target], e.Source)
+	}
+
+	// Topological order (Kahn); tasks in cycles never reach zero in-degree
+	indegree := map[string]int{}
+	for id := range work {
+		indegree[id] = len(preds[id])
+	}
+	var order, queue []string
+	for _, n := range nodes {
+		if d, ok := indegree[n.ID]; ok && d == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, s := range succs[id] {
+			indegree[s]--
+			if indegree[s] == 0 {
+				queue = append(queue, s)
+			}
+		}
+	}
+	scheduled := make(map[string]bool, len(order))
+	for _, id := range order {
+		scheduled[id] = true
+	}
+
+	// Forward pass: earliest start and finish
+	start := map[string]float64{}
+	finish := map[string]float64{}
+	length := 0.0
+	for _, id := range order {
+		for _, p := range preds[id] {
+			start[id] = math.Max(start[id], finish[p])
+		}
+		finish[id] = start[id] + work[id]
+		length = math.Max(length, finish[id])
+	}
+
+	// Backward pass: latest finish, and slack
+	slack := map[string]float64{}
+	for i := len(order) - 1; i >= 0; i-- {
+		id := order[i]
+		latest := length
+		for _, s := range succs[id] {
+			if scheduled[s] {
+				latest = math.Min(latest, finish[s]-work[s]+slack[s])
+			}
+		}
+		slack[id] = latest - finish[id]
+	}
+
+	critical := func(id string) bool {
+		return scheduled[id] && length > 0 && slack[id] < slackEpsilon
+	}
+	for _, id := range order {
+		sl := slack[id]
+		if sl < slackEpsilon {
+			sl = 0
+		}
+		n := &nodes[index[id]]
+		n.Slack = &sl
+		n.Critical = critical(id)
+	}
+
+	// Walk one chain of critical tasks from the earliest
+	path := []string{}
+	cur := ""
+	for _, id := range order {
+		if critical(id) && start[id] < slackEpsilon {
+			cur = id
+			break
+		}
+	}
+	for cur != "" {
+		path = append(path, cur)
+		next := ""
+		for _, s := range succs[cur] {
+			if critical(s) && math.Abs(start[s]-finish[cur]) < slackEpsilon &&
+				(next == "" || index[s] < index[next]) {
+				next = s
+			}
+		}
+		cur = next
+	}
+
+	onPath := map[[2]string]bool{}
+	for i := 1; i < len(path); i++ {
+		onPath[[2]string{path[i-1], path[i]}] = true
+	}
+	for i := range edges {
+		edges[i].Critical = onPath[[2]string{edges[i].Source, edges[i].Target}]
+	}
+	return path, length
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+// graphFor builds the graph of tasks given as JSON lines.
+func graphFor(t *testing.T, lines string) GraphResponse {
+	t.Helper()
+	var tasks []Task
+	for _, line := range strings.Split(strings.TrimSpace(lines), "\n") {
+		task, err := bridge.DecodeTask([]byte(line))
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		tasks = append(tasks, task)
+	}
+	return BuildGraph(tasks, nil)
+}
+
+func nodeByID(g GraphResponse, id string) GraphNode {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return GraphNode{}
+}
+
+func TestCriticalPathByCount(t *testing.T) {
+	g := graphFor(t, `
+{"id":"a","status":"pending"}
+{"id":"b","status":"pending","dependencies":["a"]}
+{"id":"c","status":"pending","dependencies":["b"]}
+{"id":"d","status":"pending"}
+`)
+	if !reflect.DeepEqual(g.CriticalPath, []string{"a", "b", "c"}) || g.CriticalLength != 3 {
+		t.Errorf("critical path = %v (%v), want a b c (3)", g.CriticalPath, g.CriticalLength)
+	}
+	if d := nodeByID(g, "d"); d.Critical || d.Slack == nil || *d.Slack != 2 {
+		t.Errorf("d = %+v, want slack 2", d)
+	}
+	if b := nodeByID(g, "b"); !b.Critical || *b.Slack != 0 {
+		t.Errorf("b = %+v, want critical", b)
+	}
+	for _, e := range g.Edges {
+		if !e.Critical {
+			t.Errorf("edge %s→%s is on the critical path", e.Source, e.Target)
+		}
+	}
+}
+
+func TestCriticalPathByEstimate(t *testing.T) {
+	g := graphFor(t, `
+{"id":"big","status":"pending","estimate":5}
+{"id":"s1","status":"in_progress","estimate":1}
+{"id":"s2","status":"pending","depends_on":["s1"],"estimate":2}
+{"id":"end","status":"pending","depends_on":["big","s2"],"estimate":1}
+`)
+	if !reflect.DeepEqual(g.CriticalPath, []string{"big", "end"}) || g.CriticalLength != 6 {
+		t.Errorf("critical path = %v (%v), want big end (6)", g.CriticalPath, g.CriticalLength)
+	}
+	for id, want := range map[string]float64{"big": 0, "s1": 2, "s2": 2, "end": 0} {
+		if n := nodeByID(g, id); n.Slack == nil || *n.Slack != want {
+			t.Errorf("%s slack = %v, want %v", id, n.Slack, want)
+		}
+	}
+}
+
+func TestCriticalPathSkipsFinishedAndCycles(t *testing.T) {
+	g := graphFor(t, `
+{"id":"done","status":"done"}
+{"id":"next","status":"pending","dependencies":["done"]}
+{"id":"x","status":"pending","dependencies":["y"]}
+{"id":"y","status":"pending","dependencies":["x"]}
+`)
+	if !reflect.DeepEqual(g.CriticalPath, []string{"next"}) {
+		t.Errorf("critical path = %v, want next", g.CriticalPath)
+	}
+	for _, id := range []string{"done", "x", "y"} {
+		if n := nodeByID(g, id); n.Slack != nil || n.Critical {
+			t.Errorf("%s = %+v, want no schedule", id, n)
+		}
+	}
+
+	g = graphFor(t, `{"id":"only","status":"complete"}`)
+	if len(g.CriticalPath) != 0 || g.CriticalLength != 0 {
+		t.Errorf("finished mission: %v (%v), want empty", g.CriticalPath, g.CriticalLength)
+	}
+}
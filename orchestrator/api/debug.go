@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// GoroutineGroup is a set of goroutines parked on the same stack.
+type GoroutineGroup struct {
+	Count    int      `json:"count"`
+	Function string   `json:"function"` // first frame outside the runtime
+	Stack    []string `json:"stack"`    // "func file:line", innermost first
+}
+
+// GoroutineSummary is the GET /api/debug/goroutines response.
+type GoroutineSummary struct {
+	Total  int              `json:"total"`
+	Groups []GoroutineGroup `json:"groups"` // largest first
+}
+
+// RegisterDebugRoutes adds the pprof handlers under /debug/pprof/ and a
+// goroutine summary at /api/debug/goroutines to mux. Both expose process
+// internals, so mc serve only registers them with --debug, and they
+// answer only requests over a Unix socket or from a user whose own token
+// makes them an admin of projectDir. A user who merely names themselves,
+// or an anonymous caller of a project without roles, is refused.
+func RegisterDebugRoutes(mux *http.ServeMux, projectDir string) {
+	admin := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if overUnixSocket(r) {
+				h(w, r)
+				return
+			}
+			if VerifiedUser(r.Context()).IsZero() {
+				problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "debug endpoints require an admin token or the Unix socket", nil)
+				return
+			}
+			if allowRole(w, r, projectDir, identity.RoleAdmin) {
+				h(w, r)
+			}
+		}
+	}
+	mux.HandleFunc("/debug/pprof/", admin(pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", admin(pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", admin(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", admin(pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", admin(pprof.Trace))
+	mux.HandleFunc("/api/debug/goroutines", admin(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			problem.MethodNotAllowed(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, Goroutines())
+	}))
+}
+
+// overUnixSocket reports whether r came in over a Unix socket, whose
+// peer address is empty or "@".
+func overUnixSocket(r *http.Request) bool {
+	return r.RemoteAddr == "" || r.RemoteAddr == "@"
+}
+
+// Goroutines groups the running goroutines by stack, so a leak shows up as
+// one group that keeps growing.
+func Goroutines() GoroutineSummary {
+	var buf bytes.Buffer
+	runtimepprof.Lookup("goroutine").WriteTo(&buf, 1)
+	summary := parseGoroutineProfile(buf.Bytes())
+	if summary.Total == 0 {
+		summary.Total = runtime.NumGoroutine()
+	}
+	return summary
+}
+
+// parseGoroutineProfile reads the debug=1 goroutine profile: blocks of
+// "<count> @ <pcs>" followed by "#\t<pc>\t<func>+<off>\t<file>:<line>"
+// frames.
+func parseGoroutineProfile(data []byte) GoroutineSummary {
+	summary := GoroutineSummary{Groups: []GoroutineGroup{}}
+	var g *GoroutineGroup
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.Contains(line, " @ "):
+			n, err := strconv.Atoi(strings.TrimSpace(line[:strings.Index(line, " @ ")]))
+			if err != nil {
+				continue
+			}
+			summary.Groups = append(summary.Groups, GoroutineGroup{Count: n})
+			g = &summary.Groups[len(summary.Groups)-1]
+			summary.Total += n
+		case strings.HasPrefix(line, "#\t") && g != nil:
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				continue
+			}
+			fn := fields[2]
+			if i := strings.LastIndex(fn, "+0x"); i >= 0 {
+				fn = fn[:i]
+			}
+			g.Stack = append(g.Stack, fn+" "+fields[3])
+			if g.Function == "" && !strings.HasPrefix(fn, "runtime") && !strings.HasPrefix(fn, "internal/") {
+				g.Function = fn
+			}
+		case line == "":
+			g = nil
+		}
+	}
+	sort.SliceStable(summary.Groups, func(i, j int) bool {
+		return summary.Groups[i].Count > summary.Groups[j].Count
+	})
+	return summary
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+)
+
+func TestParseGoroutineProfile(t *testing.T) {
+	profile := `goroutine profile: total 5
+1 @ 0x1 0x2
+#	0x4cc7d0	runtime/pprof.writeRuntimeProfile+0xb0	/go/src/runtime/pprof/pprof.go:848
+#	0x4de756	main.main+0x36				/src/main.go:8
+
+4 @ 0x1 0x3
+#	0x43e1c5	runtime.gopark+0xc5		/go/src/runtime/proc.go:398
+#	0x9a1b2c	github.com/MikeSquared-Agency/MissionControl/openclaw.(*Bridge).waitForResponse+0x8c	/src/openclaw/bridge.go:210
+`
+	s := parseGoroutineProfile([]byte(profile))
+	if s.Total != 5 || len(s.Groups) != 2 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	g := s.Groups[0]
+	if g.Count != 4 || g.Function != "github.com/MikeSquared-Agency/MissionControl/openclaw.(*Bridge).waitForResponse" {
+		t.Errorf("expected the waitForResponse group first, got %+v", g)
+	}
+	if len(g.Stack) != 2 || g.Stack[0] != "runtime.gopark /go/src/runtime/proc.go:398" {
+		t.Errorf("unexpected stack: %q", g.Stack)
+	}
+	if s.Groups[1].Function != "main.main" {
+		t.Errorf("expected runtime/pprof skipped for the function, got %q", s.Groups[1].Function)
+	}
+}
+
+func TestDebugRoutesAdminOnly(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"roles": {"root": "admin", "*": "viewer"}}`), 0644)
+	mux := http.NewServeMux()
+	RegisterDebugRoutes(mux, dir)
+
+	get := func(path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req.WithContext(WithUser(req.Context(), identity.Parse(user))))
+		return w
+	}
+
+	if w := get("/api/debug/goroutines", "guest"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a viewer, got %d", w.Code)
+	}
+	// Naming yourself root is not being root
+	req := httptest.NewRequest("GET", "/api/debug/goroutines", nil)
+	req.Header.Set(UserHeader, "root")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, withHeaderUser(req))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a header-named root, got %d", w.Code)
+	}
+	w = get("/api/debug/goroutines", "root")
+	var s GoroutineSummary
+	json.Unmarshal(w.Body.Bytes(), &s)
+	if w.Code != http.StatusOK || s.Total == 0 || len(s.Groups) == 0 {
+		t.Errorf("expected a goroutine summary, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := get("/debug/pprof/", "root"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Errorf("expected the pprof index, got %d", w.Code)
+	}
+
+	// The Unix socket needs no token
+	req = httptest.NewRequest("GET", "/api/debug/goroutines", nil)
+	req.RemoteAddr = "@"
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 over the Unix socket, got %d", w.Code)
+	}
+}
+
+func TestDebugRoutesNeedTokenWithoutRoles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{}`), 0644)
+	mux := http.NewServeMux()
+	RegisterDebugRoutes(mux, dir)
+
+	// Everyone is an admin of a project without roles, but an anonymous
+	// TCP caller still gets no pprof
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/debug/pprof/", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an anonymous caller, got %d", w.Code)
+	}
+	req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req.WithContext(WithUser(req.Context(), identity.Parse("alice"))))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for a token user, got %d", w.Code)
+	}
+}
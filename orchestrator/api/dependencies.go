@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/workspace"
+)
+
+// dependencyMap returns each task's dependencies by task ID.
+func dependencyMap(tasks []Task) map[string][]string {
+	deps := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		deps[t.ID] = append([]string{}, t.Deps()...)
+	}
+	return deps
+}
+
+// findCycles returns dependency cycles in deps until none is left, cutting
+// each one's closing dependency before looking for the next. Every task
+// caught in a cycle is on at least one of them.
+func findCycles(deps map[string][]string) [][]string {
+	work := make(map[string][]string, len(deps))
+	for id, d := range deps {
+		work[id] = append([]string{}, d...)
+	}
+	cycles := [][]string{}
+	for {
+		cycle := bridge.FindCycle(work)
+		if cycle == nil {
+			return cycles
+		}
+		cycles = append(cycles, cycle)
+		from, to := cycle[len(cycle)-2], cycle[len(cycle)-1]
+		kept := work[from][:0]
+		for _, d := range work[from] {
+			if d != to {
+				kept = append(kept, d)
+			}
+		}
+		work[from] = kept
+	}
+}
+
+// respondCycle writes a 409 naming the dependency cycle.
+func respondCycle(w http.ResponseWriter, cycle []string) {
+	err := &bridge.CycleError{Cycle: cycle}
+	problem.Write(w, http.StatusConflict, problem.CodeConflict, err.Error(),
+		map[string]interface{}{"cycle": cycle})
+}
+
+// respondTaskCommandError is respondCommandError, except that mc refusing
+// a dependency cycle is a 409 and the stage matrix refusing a persona a
+// 422 (override_matrix lets it through).
+func respondTaskCommandError(w http.ResponseWriter, msg string, out string) {
+	if i := strings.Index(out, "dependency cycle: "); i >= 0 {
+		line := strings.SplitN(out[i:], "\n", 2)[0]
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, strings.TrimSpace(line),
+			map[string]interface{}{"output": strings.TrimSpace(out)})
+		return
+	}
+	if i := strings.Index(out, workspace.MatrixRefusal); i >= 0 {
+		start := strings.LastIndex(out[:i], "persona ")
+		if start < 0 {
+			start = 0
+		}
+		line := strings.SplitN(out[start:], "\n", 2)[0]
+		problem.Write(w, http.StatusUnprocessableEntity, problem.CodeValidation, strings.TrimSpace(line),
+			map[string]interface{}{"output": strings.TrimSpace(out)})
+		return
+	}
+	respondCommandError(w, msg, out)
+}
+
+func (s *Server) handleGraphValidate(w http.ResponseWriter, r *http.Request) {
+	tasks, _ := s.readTasks()
+	deps := dependencyMap(tasks)
+
+	resp := GraphValidation{Cycles: findCycles(deps), MissingDependencies: []MissingDependency{}}
+	ids := make([]string, 0, len(deps))
+	for id := range deps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, d := range deps[id] {
+			if _, ok := deps[d]; !ok {
+				resp.MissingDependencies = append(resp.MissingDependencies, MissingDependency{TaskID: id, DependsOn: d})
+			}
+		}
+	}
+	resp.Valid = len(resp.Cycles) == 0 && len(resp.MissingDependencies) == 0
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeDependencyTasks writes tasks a ← b ← c, with d and e depending on
+// each other and f on a task that does not exist.
+func writeDependencyTasks(t *testing.T, dir string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(`{"id":"a","status":"pending"}
+{"id":"b","status":"pending","depends_on":["a"]}
+{"id":"c","status":"pending","depends_on":["b"]}
+{"id":"d","status":"pending","depends_on":["e"]}
+{"id":"e","status":"pending","depends_on":["d"]}
+{"id":"f","status":"pending","depends_on":["gone"]}
+`), 0644)
+}
+
+func TestGraphValidate(t *testing.T) {
+	s, dir := newTestServer(t)
+	writeDependencyTasks(t, dir)
+
+	req := httptest.NewRequest("GET", "/api/graph/validate", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var resp GraphValidation
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Valid {
+		t.Error("Expected valid = false")
+	}
+	if want := [][]string{{"d", "e", "d"}}; !reflect.DeepEqual(resp.Cycles, want) {
+		t.Errorf("cycles = %v, want %v", resp.Cycles, want)
+	}
+	if want := []MissingDependency{{TaskID: "f", DependsOn: "gone"}}; !reflect.DeepEqual(resp.MissingDependencies, want) {
+		t.Errorf("missing = %v, want %v", resp.MissingDependencies, want)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(`{"id":"a"}
+{"id":"b","dependencies":["a"]}
+`), 0644)
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/graph/validate", nil))
+	resp = GraphValidation{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if !resp.Valid || len(resp.Cycles) != 0 {
+		t.Errorf("Expected a valid graph, got %s", w.Body.String())
+	}
+}
+
+func TestFindCyclesCoversEachCycle(t *testing.T) {
+	deps := map[string][]string{
+		"a": {"b"}, "b": {"a", "c"}, "c": {"b"},
+		"x": {"x"},
+	}
+	cycles := findCycles(deps)
+	if len(cycles) != 3 {
+		t.Fatalf("cycles = %v, want 3", cycles)
+	}
+	if len(deps["b"]) != 2 {
+		t.Error("findCycles must not change its input")
+	}
+}
+
+func TestAddDependencyRejectsCycle(t *testing.T) {
+	s, dir := newTestServer(t)
+	writeDependencyTasks(t, dir)
+
+	req := httptest.NewRequest("POST", "/api/tasks/a/dependencies", strings.NewReader(`{"action":"add","dep_id":"c"}`))
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "a → c → b → a") {
+		t.Errorf("body = %s, want the cycle named", w.Body.String())
+	}
+}
+
+func TestCreateTaskRejectsUnknownDependency(t *testing.T) {
+	s, dir := newTestServer(t)
+	writeDependencyTasks(t, dir)
+
+	req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"t","depends_on":["a","nope"]}`))
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "nope") {
+		t.Errorf("Expected 400 naming the dependency, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRespondTaskCommandErrorCycle(t *testing.T) {
+	w := httptest.NewRecorder()
+	respondTaskCommandError(w, "mc task create failed", "Error: dependency cycle: a → b → a\nUsage: ...")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"dependency cycle: a → b → a"`) {
+		t.Errorf("got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	respondTaskCommandError(w, "mc task create failed", "Error: persona developer is not enabled by the matrix for the discovery stage (enabled: researcher)\n       Enable it in the matrix, or use --override-matrix \"justification\"\n")
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"persona developer is not enabled by the matrix for the discovery stage (enabled: researcher)"`) {
+		t.Errorf("got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	respondTaskCommandError(w, "mc task create failed", "boom")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// etagCache remembers the ETag last served for a URL together with a
+// fingerprint of the files it was built from, so a matching If-None-Match
+// can be answered with 304 before any state is read or marshaled.
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+type etagEntry struct {
+	fingerprint string
+	etag        string
+}
+
+func (c *etagCache) get(key, fingerprint string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.fingerprint != fingerprint {
+		return "", false
+	}
+	return e.etag, true
+}
+
+func (c *etagCache) put(key, fingerprint, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]etagEntry)
+	}
+	c.entries[key] = etagEntry{fingerprint: fingerprint, etag: etag}
+}
+
+// withETag serves GET responses with a content-hash ETag and answers
+// conditional requests with 304 Not Modified.
+//
+// sources, when non-nil, returns the files the response is derived from.
+// If none of them changed (path, size, mtime) since the last response for
+// the same URL, a matching If-None-Match is answered without calling h.
+// Handlers that also read in-memory state (e.g. /api/status) pass nil and
+// only save bandwidth.
+func (s *Server) withETag(sources func() []string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			h(w, r)
+			return
+		}
+
+		key := r.URL.RequestURI()
+		var fingerprint string
+		if sources != nil {
+			fingerprint = fingerprintFiles(sources())
+			if etag, ok := s.etags.get(key, fingerprint); ok && etagMatches(r.Header.Get("If-None-Match"), etag) {
+				writeNotModified(w, etag)
+				return
+			}
+		}
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		h(rec, r)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		if sources != nil {
+			s.etags.put(key, fingerprint, etag)
+		}
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			writeNotModified(w, etag)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		w.Write(rec.body.Bytes())
+	}
+}
+
+func writeNotModified(w http.ResponseWriter, etag string) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// etagMatches implements the weak comparison If-None-Match requires.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprintFiles summarises the size and mtime of each path. Missing
+// files are recorded as such so their creation changes the fingerprint.
+func fingerprintFiles(paths []string) string {
+	var b strings.Builder
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:-;", p)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", p, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
+
+// bufferedResponse captures a handler's output so it can be hashed.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTasksConditionalGet(t *testing.T) {
+	s, dir := newTestServer(t)
+	tasksFile := filepath.Join(dir, ".mission", "state", "tasks.jsonl")
+	os.WriteFile(tasksFile, []byte(`{"id":"a","name":"Alpha"}`+"\n"), 0644)
+	routes := s.Routes()
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", w.Code, etag)
+	}
+
+	req := httptest.NewRequest("GET", "/api/tasks", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("304 must not have a body, got %q", w.Body.String())
+	}
+
+	// A different query is a different representation.
+	req = httptest.NewRequest("GET", "/api/tasks?limit=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for paged query, got %d", w.Code)
+	}
+
+	os.WriteFile(tasksFile, []byte(`{"id":"a","name":"Alpha"}`+"\n"+`{"id":"b","name":"Beta"}`+"\n"), 0644)
+	req = httptest.NewRequest("GET", "/api/tasks", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after change, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("ETag did not change with content")
+	}
+}
+
+func TestWithETagSkipsHandlerWhenSourcesUnchanged(t *testing.T) {
+	s, dir := newTestServer(t)
+	src := filepath.Join(dir, "src.json")
+	os.WriteFile(src, []byte(`{}`), 0644)
+
+	calls := 0
+	h := s.withETag(func() []string { return []string{src} }, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeJSON(w, http.StatusOK, map[string]int{"n": 1})
+	})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/x", nil))
+	etag := w.Header().Get("ETag")
+
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	w = httptest.NewRecorder()
+	h(w, req)
+	if w.Code != http.StatusNotModified || calls != 1 {
+		t.Fatalf("expected cached 304 without calling handler, got %d after %d calls", w.Code, calls)
+	}
+
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(src, later, later)
+	w = httptest.NewRecorder()
+	h(w, req)
+	if calls != 2 {
+		t.Errorf("expected handler to run after source changed, calls = %d", calls)
+	}
+	if w.Code != http.StatusNotModified {
+		t.Errorf("unchanged body should still be 304, got %d", w.Code)
+	}
+}
+
+func TestWithETagPassesErrorsThrough(t *testing.T) {
+	s, _ := newTestServer(t)
+	h := s.withETag(nil, func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusInternalServerError, "boom")
+	})
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/x", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("ETag") != "" {
+		t.Errorf("expected un-tagged 500, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
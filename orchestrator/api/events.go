@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// EventsResponse is the response for GET /api/events.
+type EventsResponse struct {
+	Events []eventstore.Event `json:"events"`
+	Last   uint64             `json:"last_seq"` // newest event in the stream
+}
+
+// seqParam parses an event sequence number query parameter, 0 if absent.
+func seqParam(w http.ResponseWriter, r *http.Request, name string) (uint64, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, true
+	}
+	seq, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		problem.Validation(w, name+" must be an event sequence number")
+		return 0, false
+	}
+	return seq, true
+}
+
+// handleEvents serves GET /api/events: the mutation stream after ?since=,
+// optionally only events of ?type=, at most ?limit= of them.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	since, ok := seqParam(w, r, "since")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	events, last, err := eventstore.Read(s.missionPath(), eventstore.Filter{Since: since, Type: r.URL.Query().Get("type")})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	writeJSON(w, http.StatusOK, EventsResponse{Events: events, Last: last})
+}
+
+// handleEventState serves GET /api/events/state: the mission state
+// projected from the stream, as of ?until= when given.
+func (s *Server) handleEventState(w http.ResponseWriter, r *http.Request) {
+	until, ok := seqParam(w, r, "until")
+	if !ok {
+		return
+	}
+	events, _, err := eventstore.Read(s.missionPath(), eventstore.Filter{Until: until})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, eventstore.Project(events))
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventstore"
+)
+
+func TestEventsStreamAndState(t *testing.T) {
+	s, dir := newTestServer(t)
+	missionDir := filepath.Join(dir, ".mission")
+	eventstore.Append(missionDir, eventstore.Event{Type: eventstore.StageChanged}, eventstore.StageData{To: "discovery"})
+	eventstore.Append(missionDir, eventstore.Event{Type: eventstore.TaskCreated}, eventstore.TaskData{ID: "t1", Task: json.RawMessage(`{"id":"t1"}`)})
+	eventstore.Append(missionDir, eventstore.Event{Type: eventstore.StageChanged}, eventstore.StageData{From: "discovery", To: "goal"})
+	handler := s.Routes()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	var resp EventsResponse
+	json.Unmarshal(get("/api/events?since=1").Body.Bytes(), &resp)
+	if len(resp.Events) != 2 || resp.Events[0].Seq != 2 || resp.Events[0].Type != eventstore.TaskCreated || resp.Last != 3 {
+		t.Errorf("Unexpected events: %+v", resp)
+	}
+	json.Unmarshal(get("/api/events?type=stage_changed&limit=1").Body.Bytes(), &resp)
+	if len(resp.Events) != 1 || resp.Events[0].Seq != 1 {
+		t.Errorf("Unexpected filtered events: %+v", resp.Events)
+	}
+	if w := get("/api/events?since=-1"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad since, got %d", w.Code)
+	}
+
+	var state eventstore.State
+	json.Unmarshal(get("/api/events/state?until=2").Body.Bytes(), &state)
+	if state.Seq != 2 || state.Stage != "discovery" || len(state.Tasks) != 1 {
+		t.Errorf("Unexpected state at #2: %+v", state)
+	}
+	json.Unmarshal(get("/api/events/state").Body.Bytes(), &state)
+	if state.Stage != "goal" {
+		t.Errorf("Expected stage goal, got %+v", state)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/MikeSquared-Agency/MissionControl/experiment"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// handleExperiments serves GET /api/experiments: each A/B prompt
+// experiment with its variants' outcomes and the winner they point to,
+// optionally only those on ?persona=. Experiments are started and stopped
+// with mc experiment.
+func (s *Server) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	var tok *tokens.TokenSummary
+	if s.tokens != nil {
+		summary := s.tokens.Summary()
+		tok = &summary
+	}
+
+	results, err := experiment.Report(s.missionPath(), tok)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if persona := r.URL.Query().Get("persona"); persona != "" {
+		filtered := []experiment.Result{}
+		for _, res := range results {
+			if res.Persona == persona {
+				filtered = append(filtered, res)
+			}
+		}
+		results = filtered
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"experiments": results})
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/experiment"
+)
+
+func TestExperiments(t *testing.T) {
+	s, dir := newTestServer(t)
+	mission := filepath.Join(dir, ".mission")
+	now := time.Now()
+	if _, err := experiment.Start(mission, "developer", []byte("A"), []byte("B"), now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := experiment.Start(mission, "tester", []byte("A"), []byte("B"), now); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := experiment.Assign(mission, "developer", "w1", "t1", now); err != nil {
+		t.Fatal(err)
+	}
+	handler := s.Routes()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/experiments?persona=developer", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Experiments []experiment.Result `json:"experiments"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Experiments) != 1 || body.Experiments[0].Variants[0].Spawns != 1 || body.Experiments[0].Winner != "" {
+		t.Errorf("unexpected experiments %+v", body.Experiments)
+	}
+}
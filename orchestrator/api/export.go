@@ -0,0 +1,52 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/export"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// handleExport serves GET /api/export/{audit,usage}: the audit trail or
+// the token usage ledger as a CSV (the default) or Parquet download.
+// ?format= picks the format and ?since= (RFC3339 or a duration) limits the
+// rows, as with mc export.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	table := strings.TrimPrefix(r.URL.Path, "/api/export/")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	since, err := parseTimeParam(r.URL.Query().Get("since"))
+	if err != nil {
+		problem.Validation(w, "invalid since: "+err.Error())
+		return
+	}
+
+	if !slices.Contains(export.Tables, table) {
+		problem.Validation(w, fmt.Sprintf("unknown table %q (valid: %s)", table, strings.Join(export.Tables, ", ")))
+		return
+	}
+	if !slices.Contains(export.Formats, format) {
+		problem.Validation(w, fmt.Sprintf("unknown format %q (valid: %s)", format, strings.Join(export.Formats, ", ")))
+		return
+	}
+
+	t, err := export.Build(s.missionPath(), table, since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var buf bytes.Buffer
+	if err := export.Write(&buf, t, format); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, table, format))
+	w.Write(buf.Bytes())
+}
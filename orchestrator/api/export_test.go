@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+)
+
+func TestExport(t *testing.T) {
+	s, dir := newTestServer(t)
+	mission := filepath.Join(dir, ".mission")
+	old := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	recent := time.Now().UTC().Format(time.RFC3339)
+	os.WriteFile(filepath.Join(mission, audit.FileName), []byte(
+		`{"timestamp":"`+old+`","action":"task_created","actor":"cli"}`+"\n"+
+			`{"timestamp":"`+recent+`","action":"gate_approved","actor":"api","details":{"stage":"design"}}`+"\n"), 0644)
+	budget.Record(mission, 500, 0.25, time.Now())
+	handler := s.Routes()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/export/audit?since=24h", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected CSV, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="audit.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "gate_approved") {
+		t.Errorf("expected the header and the recent entry, got %q", lines)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/export/usage?format=parquet", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.Bytes(); !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Errorf("expected a Parquet file, got %q", body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.apache.parquet" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+
+	for _, path := range []string{"/api/export/usage?format=xlsx", "/api/export/workers", "/api/export/audit?since=yesterday"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+// tailSize is how many bytes before the indexed end of tasks.jsonl the
+// GraphCache keeps, to tell an append from a rewrite.
+const tailSize = 64
+
+// GraphCache keeps a typed index of a project's tasks.jsonl and the
+// dependency graph built from it, so GET /api/graph doesn't re-read and
+// re-parse the file on every request. Lines appended since the last read
+// are decoded on their own; a rewritten file, e.g. after mc task compact,
+// is read again in full. The graph is rebuilt only when the index or
+// zones.json changed, and is stamped with when that happened.
+type GraphCache struct {
+	mu    sync.Mutex
+	dir   string          // the project indexed
+	tasks map[string]Task // by ID
+	order []string        // IDs by first appearance
+	file  os.FileInfo     // tasks.jsonl as last read
+	end   int64           // bytes of tasks.jsonl indexed
+	tail  []byte          // the last tailSize bytes indexed
+	zones string          // zones.json fingerprint
+	graph *GraphResponse
+}
+
+// NewGraphCache returns an empty GraphCache.
+func NewGraphCache() *GraphCache {
+	return &GraphCache{tasks: map[string]Task{}}
+}
+
+// SetGraphCache serves the graph from c, which the orchestrator keeps up
+// to date from the watcher.
+func (s *Server) SetGraphCache(c *GraphCache) {
+	s.mu.Lock()
+	s.graph = c
+	s.mu.Unlock()
+}
+
+func (s *Server) graphCache() *GraphCache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph
+}
+
+// Refresh brings the cache up to date with projectDir's state files. The
+// orchestrator calls it on every watcher event so requests find the graph
+// already built.
+func (c *GraphCache) Refresh(projectDir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refresh(projectDir, time.Now())
+}
+
+// Graph returns projectDir's graph, refreshing the cache first.
+func (c *GraphCache) Graph(projectDir string) GraphResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refresh(projectDir, time.Now())
+	return *c.graph
+}
+
+func (c *GraphCache) refresh(projectDir string, now time.Time) {
+	if projectDir != c.dir {
+		c.dir = projectDir
+		c.reset()
+		c.zones = ""
+		c.graph = nil
+	}
+	stateDir := filepath.Join(projectDir, ".mission", "state")
+	changed := c.readTasks(filepath.Join(stateDir, "tasks.jsonl"))
+	zonesPath := filepath.Join(stateDir, "zones.json")
+	if zf := fingerprintFiles([]string{zonesPath}); zf != c.zones {
+		c.zones = zf
+		changed = true
+	}
+	if !changed && c.graph != nil {
+		return
+	}
+
+	zones, _ := bridge.LoadZones(zonesPath)
+	tasks := make([]Task, 0, len(c.order))
+	for _, id := range c.order {
+		tasks = append(tasks, c.tasks[id])
+	}
+	g := BuildGraph(tasks, zones)
+	g.UpdatedAt = now.UTC().Format(time.RFC3339)
+	c.graph = &g
+}
+
+// reset empties the task index.
+func (c *GraphCache) reset() {
+	c.tasks = map[string]Task{}
+	c.order = nil
+	c.file = nil
+	c.end = 0
+	c.tail = nil
+}
+
+// readTasks indexes what changed in tasks.jsonl at path and reports
+// whether anything did.
+func (c *GraphCache) readTasks(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		changed := c.file != nil
+		c.reset()
+		return changed
+	}
+	if c.file != nil && os.SameFile(c.file, info) && info.Size() == c.file.Size() && info.ModTime().Equal(c.file.ModTime()) {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	// An append leaves what was indexed in place; anything else is read
+	// again from the start
+	var data []byte
+	if c.file != nil && os.SameFile(c.file, info) && info.Size() >= c.end {
+		if _, err := f.Seek(c.end-int64(len(c.tail)), io.SeekStart); err == nil {
+			data, _ = io.ReadAll(f)
+		}
+		if bytes.HasPrefix(data, c.tail) {
+			data = data[len(c.tail):]
+		} else {
+			data = nil
+		}
+	}
+	if data == nil {
+		c.reset()
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return true
+		}
+		data, _ = io.ReadAll(f)
+	}
+	c.file = info
+
+	// Index complete lines only, and a final one without its newline if
+	// it is whole
+	n := bytes.LastIndexByte(data, '\n') + 1
+	if rest := bytes.TrimSpace(data[n:]); len(rest) > 0 && json.Valid(rest) {
+		n = len(data)
+	}
+	for _, line := range bytes.Split(data[:n], []byte("\n")) {
+		c.index(line)
+	}
+	c.end += int64(n)
+	c.tail = append(c.tail, data[:n]...)
+	if len(c.tail) > tailSize {
+		c.tail = append([]byte(nil), c.tail[len(c.tail)-tailSize:]...)
+	}
+	return true
+}
+
+// index adds or supersedes the task on line.
+func (c *GraphCache) index(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	t, err := bridge.DecodeTask(line)
+	if err != nil {
+		return
+	}
+	if _, seen := c.tasks[t.ID]; !seen {
+		c.order = append(c.order, t.ID)
+	}
+	c.tasks[t.ID] = t
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGraphCacheIndexesAppends(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, ".mission", "state")
+	os.MkdirAll(stateDir, 0755)
+	tasksPath := filepath.Join(stateDir, "tasks.jsonl")
+	os.WriteFile(tasksPath, []byte(`{"id":"a","name":"Design","status":"done"}
+{"id":"b","name":"Build","status":"pending","dependencies":["a"],"estimate":3}
+`), 0644)
+
+	c := NewGraphCache()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	c.refresh(dir, start)
+	g := *c.graph
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 || g.ReadyCount != 0 || g.UpdatedAt != "2026-03-01T10:00:00Z" {
+		t.Fatalf("unexpected graph: %+v", g)
+	}
+
+	// Unchanged files keep the graph and its stamp
+	c.refresh(dir, start.Add(time.Minute))
+	if c.graph.UpdatedAt != g.UpdatedAt {
+		t.Errorf("graph rebuilt without a change: %s", c.graph.UpdatedAt)
+	}
+
+	// An appended line supersedes b and adds c, in place
+	f, _ := os.OpenFile(tasksPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"id":"b","name":"Build","status":"blocked","dependencies":["a"]}` + "\n" +
+		`{"id":"c","name":"Ship","status":"pending","labels":["release"]}` + "\n")
+	f.Close()
+	c.refresh(dir, start.Add(2*time.Minute))
+	g = *c.graph
+	if len(g.Nodes) != 3 || g.Nodes[1].ID != "b" || g.Nodes[1].Status != "blocked" || g.BlockedCount != 1 || g.ReadyCount != 1 {
+		t.Fatalf("appended lines not indexed: %+v", g.Nodes)
+	}
+	if g.UpdatedAt != "2026-03-01T10:02:00Z" {
+		t.Errorf("updated_at = %s", g.UpdatedAt)
+	}
+
+	// A compacted file is read again in full
+	os.WriteFile(tasksPath+".tmp", []byte(`{"id":"c","name":"Ship","status":"archived"}
+{"id":"d","name":"Docs","status":"pending"}
+`), 0644)
+	os.Rename(tasksPath+".tmp", tasksPath)
+	os.WriteFile(filepath.Join(stateDir, "zones.json"), []byte(`{"zones":[]}`), 0644)
+	c.refresh(dir, start.Add(3*time.Minute))
+	g = *c.graph
+	if len(g.Nodes) != 1 || g.Nodes[0].ID != "d" {
+		t.Errorf("expected only d after the rewrite, got %+v", g.Nodes)
+	}
+
+	// The graph matches a fresh build from the file
+	tasks, _ := (&Server{missionDir: dir}).readTasks()
+	fresh := BuildGraph(tasks, nil)
+	if !reflect.DeepEqual(fresh.Nodes, g.Nodes) {
+		t.Errorf("cached graph %+v differs from a fresh build %+v", g.Nodes, fresh.Nodes)
+	}
+}
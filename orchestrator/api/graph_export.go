@@ -0,0 +1,209 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// Graph export formats
+const (
+	GraphFormatMermaid = "mermaid"
+	GraphFormatDOT     = "dot"
+)
+
+// statusColors fill task nodes in exported graphs.
+var statusColors = map[string]string{
+	"pending":     "#e5e7eb",
+	"queued":      "#fef3c7",
+	"active":      "#bfdbfe",
+	"in_progress": "#bfdbfe",
+	"blocked":     "#fecaca",
+	"done":        "#bbf7d0",
+	"complete":    "#bbf7d0",
+}
+
+// criticalColor outlines critical path tasks and edges.
+const criticalColor = "#dc2626"
+
+var classNameRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func (s *Server) handleGraphExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = GraphFormatMermaid
+	}
+	labels, err := labelQuery(r.URL.Query())
+	if err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	out, err := ExportGraph(s.graphCache().Graph(s.getMissionDir()).WithLabels(labels), format)
+	if err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	contentType := "text/plain; charset=utf-8"
+	if format == GraphFormatDOT {
+		contentType = "text/vnd.graphviz; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(out))
+}
+
+// ExportGraph renders g as a Mermaid flowchart or a Graphviz digraph.
+func ExportGraph(g GraphResponse, format string) (string, error) {
+	switch format {
+	case GraphFormatMermaid:
+		return g.Mermaid(), nil
+	case GraphFormatDOT:
+		return g.DOT(), nil
+	}
+	return "", fmt.Errorf("unknown format %q: use mermaid or dot", format)
+}
+
+// stageGroups returns the graph's nodes grouped by stage, in order of each
+// stage's first task. Tasks without a stage are grouped under "".
+func (g GraphResponse) stageGroups() ([]string, map[string][]GraphNode) {
+	var stages []string
+	groups := map[string][]GraphNode{}
+	for _, n := range g.Nodes {
+		stage := n.Stage
+		if stage == "<nil>" {
+			stage = ""
+		}
+		if _, ok := groups[stage]; !ok {
+			stages = append(stages, stage)
+		}
+		groups[stage] = append(groups[stage], n)
+	}
+	return stages, groups
+}
+
+// exportLabel is a node's label: its name, falling back to its ID, and status.
+func exportLabel(n GraphNode) (string, string) {
+	name := n.Name
+	if name == "" || name == "<nil>" {
+		name = n.ID
+	}
+	return name, n.Status
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, with a subgraph per
+// stage, nodes colored by status and the critical path drawn thick.
+func (g GraphResponse) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("t%d", i)
+	}
+	escape := strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace
+
+	stages, groups := g.stageGroups()
+	byStatus := map[string][]string{}
+	var statuses, critical []string
+	for i, stage := range stages {
+		indent := "    "
+		if stage != "" {
+			fmt.Fprintf(&b, "    subgraph s%d[\"%s\"]\n", i, escape(stage))
+			indent = "        "
+		}
+		for _, n := range groups[stage] {
+			name, status := exportLabel(n)
+			fmt.Fprintf(&b, "%s%s[\"%s<br/><small>%s</small>\"]\n", indent, ids[n.ID], escape(name), escape(status))
+			class := classNameRe.ReplaceAllString(status, "_")
+			if _, ok := byStatus[class]; !ok {
+				statuses = append(statuses, class)
+			}
+			byStatus[class] = append(byStatus[class], ids[n.ID])
+			if n.Critical {
+				critical = append(critical, ids[n.ID])
+			}
+		}
+		if stage != "" {
+			b.WriteString("    end\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		from, okFrom := ids[e.Source]
+		to, okTo := ids[e.Target]
+		if !okFrom || !okTo {
+			continue
+		}
+		arrow := "-->"
+		if e.Critical {
+			arrow = "==>"
+		}
+		fmt.Fprintf(&b, "    %s %s %s\n", from, arrow, to)
+	}
+
+	for _, class := range statuses {
+		if color, ok := statusColors[class]; ok {
+			fmt.Fprintf(&b, "    classDef %s fill:%s\n", class, color)
+		}
+		fmt.Fprintf(&b, "    class %s %s\n", strings.Join(byStatus[class], ","), class)
+	}
+	if len(critical) > 0 {
+		fmt.Fprintf(&b, "    classDef critical stroke:%s,stroke-width:3px\n", criticalColor)
+		fmt.Fprintf(&b, "    class %s critical\n", strings.Join(critical, ","))
+	}
+	return b.String()
+}
+
+// DOT renders the graph as a Graphviz digraph, with a cluster per stage,
+// nodes filled by status and the critical path outlined.
+func (g GraphResponse) DOT() string {
+	var b strings.Builder
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+
+	b.WriteString("digraph tasks {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fillcolor=\"#ffffff\"];\n")
+
+	known := make(map[string]bool, len(g.Nodes))
+	stages, groups := g.stageGroups()
+	for i, stage := range stages {
+		indent := "    "
+		if stage != "" {
+			fmt.Fprintf(&b, "    subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&b, "        label=%s;\n", quote(stage))
+			indent = "        "
+		}
+		for _, n := range groups[stage] {
+			known[n.ID] = true
+			name, status := exportLabel(n)
+			attrs := []string{"label=" + quote(name+"\n"+status)}
+			if color, ok := statusColors[status]; ok {
+				attrs = append(attrs, "fillcolor="+quote(color))
+			}
+			if n.Critical {
+				attrs = append(attrs, "color="+quote(criticalColor), "penwidth=2")
+			}
+			fmt.Fprintf(&b, "%s%s [%s];\n", indent, quote(n.ID), strings.Join(attrs, ", "))
+		}
+		if stage != "" {
+			b.WriteString("    }\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		if !known[e.Source] || !known[e.Target] {
+			continue
+		}
+		attrs := ""
+		if e.Critical {
+			attrs = fmt.Sprintf(" [color=%s, penwidth=2]", quote(criticalColor))
+		}
+		fmt.Fprintf(&b, "    %s -> %s%s;\n", quote(e.Source), quote(e.Target), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func exportTestGraph() GraphResponse {
+	return BuildGraph([]Task{
+		{ID: "a", Name: `Design "API"`, Status: "done", Stage: "design"},
+		{ID: "b", Name: "Build API", Status: "in_progress", Stage: "implement", DependsOn: []string{"a"}},
+		{ID: "c", Name: "Test API", Status: "pending", Stage: "implement", DependsOn: []string{"b"}},
+		{ID: "d", Name: "Notes", Status: "pending"},
+	}, nil)
+}
+
+func TestExportMermaid(t *testing.T) {
+	out, err := ExportGraph(exportTestGraph(), GraphFormatMermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"flowchart LR\n",
+		`subgraph s0["design"]`,
+		`t0["Design #quot;API#quot;<br/><small>done</small>"]`,
+		`subgraph s1["implement"]`,
+		"    t3[\"Notes<br/><small>pending</small>\"]\n",
+		"t0 --> t1",
+		"t1 ==> t2",
+		"classDef in_progress fill:#bfdbfe",
+		"class t2,t3 pending",
+		"class t1,t2 critical",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mermaid output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	out, err := ExportGraph(exportTestGraph(), GraphFormatDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph tasks {\n",
+		`label="design";`,
+		`"a" [label="Design \"API\"\ndone", fillcolor="#bbf7d0"];`,
+		`"b" [label="Build API\nin_progress", fillcolor="#bfdbfe", color="#dc2626", penwidth=2];`,
+		`"a" -> "b";`,
+		`"b" -> "c" [color="#dc2626", penwidth=2];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dot output lacks %q:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("dot output is not closed:\n%s", out)
+	}
+
+	if _, err := ExportGraph(exportTestGraph(), "svg"); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
+
+func TestExportColorsActive(t *testing.T) {
+	g := BuildGraph([]Task{{ID: "a", Name: "Run", Status: "active"}}, nil)
+	out, err := ExportGraph(g, GraphFormatDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `fillcolor="#bfdbfe"`) {
+		t.Errorf("active task not filled like in_progress:\n%s", out)
+	}
+}
+
+func TestGraphExportRoute(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"),
+		[]byte(`{"id":"a","name":"A","status":"pending","stage":"implement"}`+"\n"), 0644)
+
+	cases := []struct {
+		query, contentType, prefix string
+		code                       int
+	}{
+		{"", "text/plain; charset=utf-8", "flowchart LR", http.StatusOK},
+		{"?format=dot", "text/vnd.graphviz; charset=utf-8", "digraph tasks", http.StatusOK},
+		{"?format=png", "", "", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/graph/export"+c.query, nil))
+		if w.Code != c.code {
+			t.Errorf("%q: expected %d, got %d", c.query, c.code, w.Code)
+			continue
+		}
+		if c.code != http.StatusOK {
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != c.contentType {
+			t.Errorf("%q: Content-Type = %q", c.query, ct)
+		}
+		if !strings.HasPrefix(w.Body.String(), c.prefix) {
+			t.Errorf("%q: body = %s", c.query, w.Body.String())
+		}
+	}
+}
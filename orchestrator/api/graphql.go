@@ -0,0 +1,501 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/graphql"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// GraphQLPath serves GraphQL queries. A POSTed query gets the checks every
+// POST does (read-only mode, the rate limit and the contributor role);
+// viewers and read-only mode use GET.
+const GraphQLPath = "/api/graphql"
+
+// MaxGraphQLBytes limits a POSTed GraphQL request body.
+const MaxGraphQLBytes = 64 << 10
+
+// handleGraphQL runs a query against the mission's tasks, gates, handoffs,
+// findings and workers, so the dashboard can fetch nested data (task →
+// handoffs → findings → artifacts) in one round trip. GET takes query,
+// operationName and variables (as JSON) parameters; POST takes them as a
+// JSON body.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				problem.Validation(w, "variables must be a JSON object")
+				return
+			}
+		}
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, MaxGraphQLBytes)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeValidation,
+					fmt.Sprintf("GraphQL requests are limited to %d bytes", MaxGraphQLBytes), nil)
+				return
+			}
+			problem.InvalidBody(w, err)
+			return
+		}
+	default:
+		problem.MethodNotAllowed(w)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		problem.Validation(w, "query is required")
+		return
+	}
+
+	resp := s.graphQLSchema().Execute(r.Context(), req)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, resp)
+}
+
+// gqlLoader reads each file a query needs once, however many fields ask
+// for it.
+type gqlLoader struct {
+	s *Server
+
+	tasks    []Task
+	taskRecs []map[string]interface{}
+	byID     map[string]int
+	taskErr  error
+	tasksOK  bool
+	handoffs []map[string]interface{}
+	handOK   bool
+	procs    []map[string]interface{}
+	findings map[string][]map[string]interface{}
+}
+
+func (l *gqlLoader) loadTasks() error {
+	if l.tasksOK {
+		return l.taskErr
+	}
+	l.tasksOK = true
+	tasks, err := l.s.readTasks()
+	if err != nil {
+		l.taskErr = err
+		return err
+	}
+	l.byID = map[string]int{}
+	for _, t := range tasks {
+		rec := taskRecords([]Task{t})
+		if len(rec) == 0 {
+			continue
+		}
+		l.byID[t.ID] = len(l.tasks)
+		l.tasks = append(l.tasks, t)
+		l.taskRecs = append(l.taskRecs, rec[0])
+	}
+	return nil
+}
+
+// task returns the record of task id, or nil.
+func (l *gqlLoader) task(id string) (interface{}, error) {
+	if err := l.loadTasks(); err != nil {
+		return nil, err
+	}
+	if i, ok := l.byID[id]; ok {
+		return l.taskRecs[i], nil
+	}
+	return nil, nil
+}
+
+// taskList returns the records of the tasks keep accepts.
+func (l *gqlLoader) taskList(keep func(Task) bool) ([]map[string]interface{}, error) {
+	if err := l.loadTasks(); err != nil {
+		return nil, err
+	}
+	out := []map[string]interface{}{}
+	for i, t := range l.tasks {
+		if keep(t) {
+			out = append(out, l.taskRecs[i])
+		}
+	}
+	return out, nil
+}
+
+// loadHandoffs reads .mission/handoffs, leaving out briefings, oldest
+// file first. Each handoff gets its file name.
+func (l *gqlLoader) loadHandoffs() []map[string]interface{} {
+	if l.handOK {
+		return l.handoffs
+	}
+	l.handOK = true
+	dir := l.s.missionPath("handoffs")
+	entries, _ := os.ReadDir(dir)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") || strings.HasSuffix(name, "-briefing.json") {
+			continue
+		}
+		var h map[string]interface{}
+		if readJSON(filepath.Join(dir, name), &h) != nil || h == nil {
+			continue
+		}
+		h["file"] = name
+		l.handoffs = append(l.handoffs, h)
+	}
+	return l.handoffs
+}
+
+// taskFindings reads .mission/findings/{id}.json, numbering findings
+// without an ID as handleFindings does.
+func (l *gqlLoader) taskFindings(id string) []map[string]interface{} {
+	if l.findings == nil {
+		l.findings = map[string][]map[string]interface{}{}
+	}
+	if list, ok := l.findings[id]; ok {
+		return list
+	}
+	var list []map[string]interface{}
+	if validateTaskID(id) {
+		_ = readJSON(l.s.missionPath("findings", id+".json"), &list)
+	}
+	for i, f := range list {
+		f["task_id"] = id
+		if _, ok := f["id"]; !ok {
+			f["id"] = fmt.Sprintf("%s/%d", id, i)
+		}
+	}
+	l.findings[id] = list
+	return list
+}
+
+func (l *gqlLoader) gates() (map[string]map[string]interface{}, error) {
+	var raw map[string]json.RawMessage
+	if err := readJSON(l.s.statePath("gates.json"), &raw); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// mc writes {"gates": {...}}; older files are the map itself
+	if inner, ok := raw["gates"]; ok {
+		raw = nil
+		if err := json.Unmarshal(inner, &raw); err != nil {
+			return nil, err
+		}
+	}
+	gates := map[string]map[string]interface{}{}
+	for stage, data := range raw {
+		var g map[string]interface{}
+		if json.Unmarshal(data, &g) != nil || g == nil {
+			continue
+		}
+		g["stage"] = stage
+		gates[stage] = g
+	}
+	return gates, nil
+}
+
+func (l *gqlLoader) workers() []map[string]interface{} {
+	if l.procs != nil {
+		return l.procs
+	}
+	out := []map[string]interface{}{}
+	l.procs = out
+	if l.s.tracker == nil {
+		return out
+	}
+	procs := l.s.tracker.List()
+	sort.Slice(procs, func(i, j int) bool { return procs[i].WorkerID < procs[j].WorkerID })
+	for _, p := range procs {
+		if recs, err := toRecords([]interface{}{p}); err == nil && len(recs) == 1 {
+			out = append(out, recs[0])
+		}
+	}
+	l.procs = out
+	return out
+}
+
+// worker returns the worker whose field key is id, or nil.
+func (l *gqlLoader) worker(key, id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	for _, w := range l.workers() {
+		if str(w[key]) == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// artifact describes a path a handoff lists, relative to the project.
+// Paths outside the project are reported as missing rather than looked up.
+func (l *gqlLoader) artifact(path string) map[string]interface{} {
+	a := map[string]interface{}{"path": path, "exists": false}
+	if !filepath.IsLocal(path) {
+		return a
+	}
+	if info, err := os.Stat(filepath.Join(l.s.getMissionDir(), path)); err == nil {
+		a["exists"] = true
+		a["size"] = info.Size()
+		a["modified_at"] = info.ModTime().UTC()
+	}
+	return a
+}
+
+// graphQLSchema is the schema queries run against, with a fresh loader so
+// every request sees the files as they are.
+func (s *Server) graphQLSchema() *graphql.Schema {
+	l := &gqlLoader{s: s}
+	source := func(p graphql.Params) map[string]interface{} {
+		m, _ := p.Source.(map[string]interface{})
+		return m
+	}
+	taskField := func(key string) *graphql.Field {
+		return &graphql.Field{Type: "Task", Resolve: func(p graphql.Params) (interface{}, error) {
+			return l.task(str(source(p)[key]))
+		}}
+	}
+
+	task := scalarFields("id", "name", "stage", "zone", "persona", "assignee", "status",
+		"depends_on", "scope_paths", "estimate", "labels", "spec", "spec_hash", "worker_id",
+		"archived_from", "archive_reason", "created_at", "updated_at")
+	// dependencies is the older name of depends_on on disk; here it is the
+	// tasks themselves
+	task["dependencies"] = &graphql.Field{Type: "Task", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		id := str(source(p)["id"])
+		if err := l.loadTasks(); err != nil {
+			return nil, err
+		}
+		out := []interface{}{}
+		if i, ok := l.byID[id]; ok {
+			for _, dep := range l.tasks[i].Deps() {
+				if j, ok := l.byID[dep]; ok {
+					out = append(out, l.taskRecs[j])
+				}
+			}
+		}
+		return out, nil
+	}}
+	task["dependents"] = &graphql.Field{Type: "Task", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		id := str(source(p)["id"])
+		return l.taskList(func(t Task) bool {
+			for _, dep := range t.Deps() {
+				if dep == id {
+					return true
+				}
+			}
+			return false
+		})
+	}}
+	task["handoffs"] = &graphql.Field{Type: "Handoff", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		return filterRecords(l.loadHandoffs(), "task_id", str(source(p)["id"])), nil
+	}}
+	task["findings"] = &graphql.Field{Type: "Finding", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		return l.taskFindings(str(source(p)["id"])), nil
+	}}
+	task["worker"] = &graphql.Field{Type: "Worker", Resolve: func(p graphql.Params) (interface{}, error) {
+		src := source(p)
+		if w := l.worker("worker_id", str(src["worker_id"])); w != nil {
+			return w, nil
+		}
+		return l.worker("task_id", str(src["id"])), nil
+	}}
+
+	handoff := scalarFields("task_id", "worker_id", "status", "open_questions", "file")
+	handoff["task"] = taskField("task_id")
+	handoff["worker"] = &graphql.Field{Type: "Worker", Resolve: func(p graphql.Params) (interface{}, error) {
+		return l.worker("worker_id", str(source(p)["worker_id"])), nil
+	}}
+	handoff["findings"] = &graphql.Field{Type: "Finding", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		src := source(p)
+		list, _ := src["findings"].([]interface{})
+		out := []map[string]interface{}{}
+		for i, item := range list {
+			f, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			f["task_id"] = src["task_id"]
+			if _, ok := f["id"]; !ok {
+				f["id"] = fmt.Sprintf("%s#%d", src["file"], i)
+			}
+			out = append(out, f)
+		}
+		return out, nil
+	}}
+	handoff["artifacts"] = &graphql.Field{Type: "Artifact", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		list, _ := source(p)["artifacts"].([]interface{})
+		out := []map[string]interface{}{}
+		for _, item := range list {
+			if path := str(item); path != "" {
+				out = append(out, l.artifact(path))
+			}
+		}
+		return out, nil
+	}}
+
+	finding := scalarFields("id", "task_id", "type", "summary", "severity", "resolved")
+	finding["task"] = taskField("task_id")
+
+	gate := scalarFields("stage", "status", "criteria", "approved_at", "approval_note", "approved_by")
+	gate["tasks"] = &graphql.Field{Type: "Task", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+		stage := str(source(p)["stage"])
+		return l.taskList(func(t Task) bool { return t.Stage == stage && t.Status != bridge.TaskStatusArchived })
+	}}
+
+	worker := scalarFields("worker_id", "persona", "task_id", "zone", "model", "pid", "status",
+		"started_at", "token_count", "cost_usd", "last_seen")
+	worker["task"] = taskField("task_id")
+
+	query := map[string]*graphql.Field{
+		"tasks": {Type: "Task", List: true, Args: []string{"stage", "status", "zone", "persona", "assignee", "label", "include_archived"},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				a := p.Args
+				var labels []string
+				if label := str(a["label"]); label != "" {
+					labels = strings.Split(label, ",")
+				}
+				status := str(a["status"])
+				archived := status == bridge.TaskStatusArchived || a["include_archived"] == true
+				return l.taskList(func(t Task) bool {
+					return (archived || t.Status != bridge.TaskStatusArchived) &&
+						matches(a, "stage", t.Stage) && matches(a, "status", t.Status) &&
+						matches(a, "zone", t.Zone) && matches(a, "persona", t.Persona) &&
+						matches(a, "assignee", t.Assignee) && hasLabels(taskLabels(t), labels)
+				})
+			}},
+		"task": {Type: "Task", Args: []string{"id"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			return l.task(str(p.Args["id"]))
+		}},
+		"gates": {Type: "Gate", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+			gates, err := l.gates()
+			if err != nil {
+				return nil, err
+			}
+			stages := make([]string, 0, len(gates))
+			for stage := range gates {
+				stages = append(stages, stage)
+			}
+			sort.Strings(stages)
+			out := make([]map[string]interface{}, 0, len(stages))
+			for _, stage := range stages {
+				out = append(out, gates[stage])
+			}
+			return out, nil
+		}},
+		"gate": {Type: "Gate", Args: []string{"stage"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			gates, err := l.gates()
+			if err != nil {
+				return nil, err
+			}
+			if g, ok := gates[str(p.Args["stage"])]; ok {
+				return g, nil
+			}
+			return nil, nil
+		}},
+		"workers": {Type: "Worker", List: true, Resolve: func(p graphql.Params) (interface{}, error) {
+			return l.workers(), nil
+		}},
+		"worker": {Type: "Worker", Args: []string{"id"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			return l.worker("worker_id", str(p.Args["id"])), nil
+		}},
+		"handoffs": {Type: "Handoff", List: true, Args: []string{"task"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			return filterRecords(l.loadHandoffs(), "task_id", str(p.Args["task"])), nil
+		}},
+		"findings": {Type: "Finding", List: true, Args: []string{"task", "type", "severity"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			ids := []string{str(p.Args["task"])}
+			if ids[0] == "" {
+				ids = nil
+				entries, _ := os.ReadDir(s.missionPath("findings"))
+				for _, e := range entries {
+					if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+						ids = append(ids, strings.TrimSuffix(e.Name(), ".json"))
+					}
+				}
+			}
+			out := []map[string]interface{}{}
+			for _, id := range ids {
+				for _, f := range l.taskFindings(id) {
+					if matches(p.Args, "type", str(f["type"])) && matches(p.Args, "severity", str(f["severity"])) {
+						out = append(out, f)
+					}
+				}
+			}
+			return out, nil
+		}},
+		"stage": {Type: "Stage", Resolve: func(p graphql.Params) (interface{}, error) {
+			recs, err := toRecords([]interface{}{s.readStage()})
+			if err != nil || len(recs) != 1 {
+				return nil, err
+			}
+			return recs[0], nil
+		}},
+	}
+
+	types := map[string]*graphql.Object{
+		"Query":    {Fields: query},
+		"Task":     {Fields: task},
+		"Handoff":  {Fields: handoff},
+		"Finding":  {Fields: finding},
+		"Artifact": {Fields: scalarFields("path", "exists", "size", "modified_at")},
+		"Gate":     {Fields: gate},
+		"Worker":   {Fields: worker},
+		"Stage":    {Fields: scalarFields("current", "updated_at", "rolled_back_from")},
+	}
+	for name, t := range types {
+		t.Name = name
+	}
+	return &graphql.Schema{Query: "Query", Types: types}
+}
+
+// scalarFields declares fields read straight from a record.
+func scalarFields(names ...string) map[string]*graphql.Field {
+	fields := make(map[string]*graphql.Field, len(names))
+	for _, name := range names {
+		fields[name] = &graphql.Field{}
+	}
+	return fields
+}
+
+// filterRecords returns the records whose key is value; all of them when
+// value is empty.
+func filterRecords(recs []map[string]interface{}, key, value string) []map[string]interface{} {
+	out := []map[string]interface{}{}
+	for _, rec := range recs {
+		if value == "" || str(rec[key]) == value {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// matches reports whether the string argument name is unset or equals v.
+func matches(args map[string]interface{}, name, v string) bool {
+	want := str(args[name])
+	return want == "" || want == v
+}
+
+func str(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGraphQLNestedQuery(t *testing.T) {
+	s, dir := newTestServer(t)
+	s.tracker = &mockTracker{}
+	mission := filepath.Join(dir, ".mission")
+	os.WriteFile(filepath.Join(mission, "state", "tasks.jsonl"), []byte(
+		`{"id":"t1","name":"Design API","stage":"design","status":"done"}`+"\n"+
+			`{"id":"t2","name":"Build API","stage":"implement","status":"pending","depends_on":["t1"]}`+"\n"), 0644)
+	os.MkdirAll(filepath.Join(mission, "handoffs"), 0755)
+	os.WriteFile(filepath.Join(mission, "handoffs", "w1-20260101-120000.json"), []byte(
+		`{"task_id":"t1","worker_id":"w1","status":"complete","findings":[{"type":"decision","summary":"Use REST","severity":"info"}],"artifacts":["docs/api.md","missing.md","../outside.md"]}`), 0644)
+	os.WriteFile(filepath.Join(mission, "handoffs", "t2-briefing.json"), []byte(`{"task_id":"t2"}`), 0644)
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "api.md"), []byte("# API"), 0644)
+	os.WriteFile(filepath.Join(mission, "state", "gates.json"), []byte(`{"gates":{"design":{"status":"approved"}}}`), 0644)
+
+	query := `query Task($id: String!) {
+		task(id: $id) {
+			id
+			dependencies { id name }
+			handoffs {
+				worker { worker_id }
+				findings { summary task { name } }
+				artifacts { path exists size }
+			}
+		}
+		gate(stage: "design") { status tasks { id } }
+	}`
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": map[string]string{"id": "t2"}})
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("POST", GraphQLPath, strings.NewReader(string(body))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), `"handoffs":[{`) {
+		t.Errorf("Expected t2 to have no handoffs (briefings aren't handoffs), got %s", w.Body.String())
+	}
+
+	// Through the dependency to t1's handoff
+	query = `{ task(id: "t1") { handoffs { worker { worker_id } findings { summary task { name } } artifacts { path exists size } } dependents { id } } gate(stage: "design") { status tasks { id } } }`
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", GraphQLPath+"?query="+url.QueryEscape(query), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Task struct {
+				Handoffs []struct {
+					Worker   map[string]interface{}
+					Findings []struct {
+						Summary string
+						Task    map[string]interface{}
+					}
+					Artifacts []map[string]interface{}
+				}
+				Dependents []map[string]interface{}
+			}
+			Gate struct {
+				Status string
+				Tasks  []map[string]interface{}
+			}
+		}
+		Errors []interface{}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected response %s (%v)", w.Body.String(), err)
+	}
+	task := resp.Data.Task
+	if len(task.Handoffs) != 1 || task.Handoffs[0].Worker["worker_id"] != "w1" {
+		t.Fatalf("Expected one handoff by w1, got %s", w.Body.String())
+	}
+	h := task.Handoffs[0]
+	if len(h.Findings) != 1 || h.Findings[0].Summary != "Use REST" || h.Findings[0].Task["name"] != "Design API" {
+		t.Errorf("Unexpected findings %+v", h.Findings)
+	}
+	if len(h.Artifacts) != 3 || h.Artifacts[0]["exists"] != true || h.Artifacts[0]["size"] != float64(5) ||
+		h.Artifacts[1]["exists"] != false || h.Artifacts[2]["exists"] != false {
+		t.Errorf("Unexpected artifacts %v", h.Artifacts)
+	}
+	if len(task.Dependents) != 1 || task.Dependents[0]["id"] != "t2" {
+		t.Errorf("Expected t2 to depend on t1, got %v", task.Dependents)
+	}
+	if resp.Data.Gate.Status != "approved" || len(resp.Data.Gate.Tasks) != 1 {
+		t.Errorf("Unexpected gate %+v", resp.Data.Gate)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	s, _ := newTestServer(t)
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("POST", GraphQLPath, strings.NewReader(body)))
+		return w
+	}
+
+	if w := send(`{"query": "{ task(id: \"t1\") { nope } }"}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "nope") {
+		t.Errorf("Expected 400 naming the unknown field, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := send(`{"query": "mutation { tasks { id } }"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected mutations refused, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := send(`{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a missing query refused, got %d", w.Code)
+	}
+	if w := send(`{"query": "{ task(id: \"none\") { id } }"}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"task":null`) {
+		t.Errorf("Expected a null task, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// A POSTed query is checked like any other POST; GET stays a read.
+func TestGraphQLPostsAreChecked(t *testing.T) {
+	query := `{"query":"{ stage { current } }"}`
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	readOnly := NewReadOnly(true, "migrating").Middleware(ok)
+	w := httptest.NewRecorder()
+	readOnly.ServeHTTP(w, httptest.NewRequest("POST", GraphQLPath, strings.NewReader(query)))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected a POSTed query refused in read-only mode, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	readOnly.ServeHTTP(w, httptest.NewRequest("GET", GraphQLPath+"?query="+url.QueryEscape("{ stage { current } }"), nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected GET allowed in read-only mode, got %d", w.Code)
+	}
+
+	limited := NewRateLimiter(1, 1).Middleware(ok)
+	codes := []int{}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		limited.ServeHTTP(w, httptest.NewRequest("POST", "/api/p/beta/graphql", strings.NewReader(query)))
+		codes = append(codes, w.Code)
+	}
+	if codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected the second POSTed query throttled, got %v", codes)
+	}
+
+	s, _ := newTestServer(t)
+	w = httptest.NewRecorder()
+	big := `{"query":"{ stage { current } }","operationName":"` + strings.Repeat("x", MaxGraphQLBytes) + `"}`
+	s.Routes().ServeHTTP(w, httptest.NewRequest("POST", GraphQLPath, strings.NewReader(big)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized body refused with 413, got %d", w.Code)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipMiddlewareCompressesJSON(t *testing.T) {
+	h := GzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		writeJSON(w, http.StatusOK, map[string]string{"hello": strings.Repeat("world", 100)})
+	}))
+
+	req := httptest.NewRequest("GET", "/api/tasks", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", w.Header().Get("Content-Encoding"))
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary = %q", w.Header().Get("Vary"))
+	}
+	if w.Header().Get("ETag") != `W/"abc"` {
+		t.Errorf("ETag = %q, want weak", w.Header().Get("ETag"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if !strings.Contains(string(body), `"hello":"worldworld`) {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestGzipMiddlewarePassThrough(t *testing.T) {
+	tests := []struct {
+		name, accept, contentType string
+		status                    int
+	}{
+		{"no accept-encoding", "", "application/json", http.StatusOK},
+		{"gzip refused", "gzip;q=0", "application/json", http.StatusOK},
+		{"event stream", "gzip", "text/event-stream", http.StatusOK},
+		{"not modified", "gzip", "application/json", http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		h := GzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			w.WriteHeader(tt.status)
+			if tt.status == http.StatusOK {
+				w.Write([]byte("data"))
+			}
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept-Encoding", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if enc := w.Header().Get("Content-Encoding"); enc != "" {
+			t.Errorf("%s: unexpected Content-Encoding %q", tt.name, enc)
+		}
+		if tt.status == http.StatusOK && w.Body.String() != "data" {
+			t.Errorf("%s: body = %q", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestGzipConditionalGetRoundTrip(t *testing.T) {
+	s, _ := newTestServer(t)
+	h := Chain(s.Routes(), GzipMiddleware)
+
+	req := httptest.NewRequest("GET", "/api/gates", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+	if !strings.HasPrefix(etag, "W/") {
+		t.Fatalf("expected weak ETag on gzip response, got %q", etag)
+	}
+
+	req = httptest.NewRequest("GET", "/api/gates", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for weak ETag, got %d", w.Code)
+	}
+}
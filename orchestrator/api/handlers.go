@@ -1,9 +1,12 @@
 package api
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -11,38 +14,40 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/briefing"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/conversation"
+	"github.com/MikeSquared-Agency/MissionControl/gatetimer"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/report"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
 )
 
 // --- Helpers ---
 
+// readJSONL reads the objects in a JSONL file. Malformed lines are skipped.
 func readJSONL(path string) ([]map[string]interface{}, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []map[string]interface{}{}, nil
-		}
-		return nil, err
-	}
-	defer f.Close()
-
-	var results []map[string]interface{}
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
+	results := []map[string]interface{}{}
+	_, err := jsonl.Read(path, func(line []byte) error {
 		var obj map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &obj); err != nil {
-			continue // skip malformed lines
+		if err := json.Unmarshal(line, &obj); err != nil {
+			return err
 		}
 		results = append(results, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if results == nil {
-		results = []map[string]interface{}{}
-	}
-	return results, scanner.Err()
+	return results, nil
 }
 
 func readJSON(path string, target interface{}) error {
@@ -53,18 +58,97 @@ func readJSON(path string, target interface{}) error {
 	return json.Unmarshal(data, target)
 }
 
-func (s *Server) runMC(args ...string) (string, error) {
+// runMC shells out to the mc CLI. The request ID from ctx is passed via
+// MC_REQUEST_ID so audit entries written by mc can be correlated.
+func (s *Server) runMC(ctx context.Context, args ...string) (string, error) {
+	return s.runMCInput(ctx, nil, args...)
+}
+
+// runMCInput is runMC with stdin, for commands that read from "-".
+func (s *Server) runMCInput(ctx context.Context, stdin []byte, args ...string) (string, error) {
 	s.mu.RLock()
 	dir := s.missionDir
 	s.mu.RUnlock()
 	cmd := exec.Command("mc", args...)
 	cmd.Dir = dir
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	// mc records who it acts for on audit entries and gate approvals;
+	// requests that name no one are attributed to "api".
+	user := UserFromContext(ctx).String()
+	if user == "" {
+		user = "api"
+	}
+	cmd.Env = append(os.Environ(), identity.EnvUser+"="+user)
+	reqID := RequestIDFromContext(ctx)
+	if reqID != "" {
+		cmd.Env = append(cmd.Env, "MC_REQUEST_ID="+reqID)
+	}
 	out, err := cmd.CombinedOutput()
+	if err != nil && reqID != "" {
+		log.Printf("[req %s] mc %s failed: %v", reqID, strings.Join(args, " "), err)
+	}
 	return strings.TrimSpace(string(out)), err
 }
 
+// broadcast sends a hub event, tagged with the request ID from ctx when the
+// hub supports it.
+func (s *Server) broadcast(ctx context.Context, topic, eventType string, data interface{}) {
+	if s.hub == nil {
+		return
+	}
+	if rb, ok := s.hub.(RequestBroadcaster); ok {
+		if reqID := RequestIDFromContext(ctx); reqID != "" {
+			rb.BroadcastRawWithRequestID(reqID, topic, eventType, data)
+			return
+		}
+	}
+	s.hub.BroadcastRaw(topic, eventType, data)
+}
+
+// respondError writes an application/problem+json error whose code is
+// derived from status. The legacy "error" field is kept in the body.
 func respondError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, ErrorResponse{Error: msg})
+	problem.Error(w, status, msg)
+}
+
+// respondCommandError reports a failed mc subprocess, returning its output
+// in details rather than folding it into the message.
+func respondCommandError(w http.ResponseWriter, msg string, out string) {
+	problem.Write(w, http.StatusInternalServerError, problem.CodeCommandFailed, msg,
+		map[string]interface{}{"output": strings.TrimSpace(out)})
+}
+
+// readTasks reads tasks.jsonl. Where a task has several lines the last one
+// supersedes the others, as mc task compact would leave it. Lines that
+// don't decode as a Task are left out.
+func (s *Server) readTasks() ([]Task, error) {
+	tasks, _, err := bridge.ReadTasks(s.statePath("tasks.jsonl"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	return tasks, nil
+}
+
+// taskRecords returns tasks as JSON objects, for writeList to sort and
+// page by field name.
+func taskRecords(tasks []Task) []map[string]interface{} {
+	recs := make([]map[string]interface{}, 0, len(tasks))
+	for _, t := range tasks {
+		data, err := json.Marshal(t)
+		if err != nil {
+			continue
+		}
+		var rec map[string]interface{}
+		if json.Unmarshal(data, &rec) == nil {
+			recs = append(recs, rec)
+		}
+	}
+	return recs
 }
 
 func (s *Server) getMissionDir() string {
@@ -94,44 +178,52 @@ func (s *Server) handleTaskFindings(w http.ResponseWriter, r *http.Request, id s
 		respondError(w, http.StatusBadRequest, "invalid task ID")
 		return
 	}
-	path := s.missionPath("findings", id+".md")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			respondError(w, http.StatusNotFound, "findings not found")
-			return
-		}
-		respondError(w, http.StatusInternalServerError, "failed to read findings")
-		return
-	}
-	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	s.serveFile(w, r, s.missionPath("findings", id+".md"), "text/markdown; charset=utf-8", "findings", nil)
 }
 
-// handleTaskBriefing serves .mission/handoffs/{id}-briefing.json as application/json.
+// handleTaskBriefing serves .mission/handoffs/{id}-briefing.json as
+// application/json. With ?budget= it instead packs the task's context
+// into a briefing of that many tokens (0 for the default) and reports
+// what was left out.
 func (s *Server) handleTaskBriefing(w http.ResponseWriter, r *http.Request, id string) {
 	if !validateTaskID(id) {
 		respondError(w, http.StatusBadRequest, "invalid task ID")
 		return
 	}
-	path := s.missionPath("handoffs", id+"-briefing.json")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			respondError(w, http.StatusNotFound, "briefing not found")
+	if r.URL.Query().Has("budget") {
+		budget, ok := intParam(w, r, "budget")
+		if !ok {
 			return
 		}
-		respondError(w, http.StatusInternalServerError, "failed to read briefing")
+		pack, err := briefing.PackTask(s.missionPath(), id, budget, briefing.CounterFor(s.taskModel(r, id)))
+		if errors.Is(err, briefing.ErrTaskNotFound) {
+			respondError(w, http.StatusNotFound, "task not found")
+			return
+		}
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, pack)
 		return
 	}
-	if !json.Valid(data) {
-		respondError(w, http.StatusInternalServerError, "briefing file contains invalid JSON")
-		return
+	s.serveFile(w, r, s.missionPath("handoffs", id+"-briefing.json"), "application/json", "briefing", validJSON)
+}
+
+// taskModel is the model a briefing for task id is counted against: the
+// model query parameter, else the model of the worker running the task.
+func (s *Server) taskModel(r *http.Request, id string) string {
+	if model := r.URL.Query().Get("model"); model != "" {
+		return model
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	if s.tracker != nil {
+		for _, w := range s.tracker.List() {
+			if w.TaskID == id && w.Model != "" {
+				return w.Model
+			}
+		}
+	}
+	return ""
 }
 
 // --- GET handlers ---
@@ -143,21 +235,10 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	result := map[string]interface{}{}
 
-	// Read current stage
-	var stage map[string]interface{}
-	if err := readJSON(s.statePath("stage.json"), &stage); err == nil {
-		result["stage"] = stage
-	} else {
-		// Fallback: try stages.jsonl and use last entry
-		if entries, err := readJSONL(s.statePath("stages.jsonl")); err == nil && len(entries) > 0 {
-			result["stage"] = entries[len(entries)-1]
-		} else {
-			result["stage"] = nil
-		}
-	}
+	result["stage"] = s.readStage()
 
 	// Read tasks
-	tasks, _ := readJSONL(s.statePath("tasks.jsonl"))
+	tasks, _ := s.readTasks()
 	result["tasks"] = tasks
 
 	// Read gates
@@ -167,12 +248,10 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 	result["gates"] = gates
 
+	result["labels"] = labelFacets(tasks)
+
 	// Read zones
-	var zones interface{}
-	if err := readJSON(s.statePath("zones.json"), &zones); err != nil {
-		zones = deriveZones(tasks)
-	}
-	result["zones"] = zones
+	result["zones"] = s.loadZones(tasks)
 
 	// Checkpoints
 	result["checkpoints"] = s.loadCheckpoints()
@@ -196,7 +275,7 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks, err := readJSONL(s.statePath("tasks.jsonl"))
+	tasks, err := s.readTasks()
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -204,32 +283,50 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 
 	// Apply filters
 	q := r.URL.Query()
+	page, err := parsePage(q)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	stage := q.Get("stage")
 	zone := q.Get("zone")
 	status := q.Get("status")
 	persona := q.Get("persona")
+	assignee := q.Get("assignee")
+	labels, err := labelQuery(q)
+	if err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	archived := status == bridge.TaskStatusArchived || q.Get("include_archived") == "true"
 
-	var filtered []map[string]interface{}
+	var filtered []Task
 	for _, t := range tasks {
-		if stage != "" && fmt.Sprint(t["stage"]) != stage {
+		if !archived && t.Status == bridge.TaskStatusArchived {
+			continue
+		}
+		if stage != "" && t.Stage != stage {
+			continue
+		}
+		if zone != "" && t.Zone != zone {
+			continue
+		}
+		if status != "" && t.Status != status {
 			continue
 		}
-		if zone != "" && fmt.Sprint(t["zone"]) != zone {
+		if persona != "" && t.Persona != persona {
 			continue
 		}
-		if status != "" && fmt.Sprint(t["status"]) != status {
+		if assignee != "" && t.Assignee != assignee {
 			continue
 		}
-		if persona != "" && fmt.Sprint(t["persona"]) != persona {
+		if !hasLabels(taskLabels(t), labels) {
 			continue
 		}
 		filtered = append(filtered, t)
 	}
-	if filtered == nil {
-		filtered = []map[string]interface{}{}
-	}
 
-	writeJSON(w, http.StatusOK, filtered)
+	writeList(w, "tasks", taskRecords(filtered), page)
 }
 
 func (s *Server) handleTaskByID(w http.ResponseWriter, r *http.Request, id string) {
@@ -238,14 +335,14 @@ func (s *Server) handleTaskByID(w http.ResponseWriter, r *http.Request, id strin
 		return
 	}
 
-	tasks, err := readJSONL(s.statePath("tasks.jsonl"))
+	tasks, err := s.readTasks()
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	for _, t := range tasks {
-		if fmt.Sprint(t["id"]) == id {
+		if t.ID == id {
 			writeJSON(w, http.StatusOK, t)
 			return
 		}
@@ -254,63 +351,77 @@ func (s *Server) handleTaskByID(w http.ResponseWriter, r *http.Request, id strin
 }
 
 func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
-	tasks, _ := readJSONL(s.statePath("tasks.jsonl"))
-	writeJSON(w, http.StatusOK, BuildGraph(tasks))
+	labels, err := labelQuery(r.URL.Query())
+	if err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.graphCache().Graph(s.getMissionDir()).WithLabels(labels))
 }
 
-// BuildGraph constructs a GraphResponse from raw task data.
+// BuildGraph constructs a GraphResponse from tasks, coloring
+// nodes by their zone in zones and marking the critical path.
 // Exported so serve.go can call it from buildState().
-func BuildGraph(tasks []map[string]interface{}) GraphResponse {
+func BuildGraph(tasks []Task, zones []bridge.Zone) GraphResponse {
 	var nodes []GraphNode
 	var edges []GraphEdge
 	blockedCount := 0
 	readyCount := 0
+	remaining := map[string]float64{} // work left per unfinished task
 
+	archived := map[string]bool{}
 	for _, t := range tasks {
-		id := fmt.Sprint(t["id"])
-		status := fmt.Sprint(t["status"])
-		name := fmt.Sprint(t["name"])
-		persona := ""
-		if p, ok := t["persona"].(string); ok {
-			persona = p
+		if t.Status == bridge.TaskStatusArchived {
+			archived[t.ID] = true
 		}
-		workerID := ""
-		if w, ok := t["worker_id"].(string); ok {
-			workerID = w
+	}
+
+	for _, t := range tasks {
+		if archived[t.ID] {
+			continue
+		}
+		zoneColor := ""
+		if z := bridge.FindZone(zones, t.Zone); z != nil {
+			zoneColor = z.Color
 		}
 
 		nodes = append(nodes, GraphNode{
-			ID:       id,
-			Name:     name,
-			Title:    name,
-			Type:     "task",
-			Status:   status,
-			Stage:    fmt.Sprint(t["stage"]),
-			Zone:     fmt.Sprint(t["zone"]),
-			Persona:  persona,
-			WorkerID: workerID,
+			ID:        t.ID,
+			Name:      t.Name,
+			Title:     t.Name,
+			Type:      "task",
+			Status:    t.Status,
+			Stage:     t.Stage,
+			Zone:      t.Zone,
+			ZoneColor: zoneColor,
+			Persona:   t.Persona,
+			Assignee:  t.Assignee,
+			WorkerID:  t.WorkerID,
+			Labels:    taskLabels(t),
 		})
 
-		if status == "blocked" {
+		if t.Status == "blocked" {
 			blockedCount++
 		}
-		if status == "pending" {
-			if deps, ok := t["dependencies"].([]interface{}); !ok || len(deps) == 0 {
-				readyCount++
-			}
+		deps := t.Deps()
+		if t.Status == "pending" && len(deps) == 0 {
+			readyCount++
+		}
+		if !t.Finished() {
+			remaining[t.ID] = taskEstimate(t)
 		}
 
-		if deps, ok := t["dependencies"].([]interface{}); ok {
-			for _, d := range deps {
-				depStr := fmt.Sprint(d)
-				edges = append(edges, GraphEdge{
-					From:   depStr,
-					To:     id,
-					Source: depStr,
-					Target: id,
-					Type:   "blocks",
-				})
+		for _, dep := range deps {
+			if archived[dep] {
+				continue
 			}
+			edges = append(edges, GraphEdge{
+				From:   dep,
+				To:     t.ID,
+				Source: dep,
+				Target: t.ID,
+				Type:   "blocks",
+			})
 		}
 	}
 	if nodes == nil {
@@ -320,12 +431,14 @@ func BuildGraph(tasks []map[string]interface{}) GraphResponse {
 		edges = []GraphEdge{}
 	}
 
+	path, length := markCriticalPath(nodes, edges, remaining)
 	return GraphResponse{
-		Nodes:        nodes,
-		Edges:        edges,
-		CriticalPath: []string{},
-		BlockedCount: blockedCount,
-		ReadyCount:   readyCount,
+		Nodes:          nodes,
+		Edges:          edges,
+		CriticalPath:   path,
+		CriticalLength: length,
+		BlockedCount:   blockedCount,
+		ReadyCount:     readyCount,
 	}
 }
 
@@ -364,6 +477,14 @@ func (s *Server) handleGates(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// mc nests the gates under "gates"; older files have them at the top
+	byStage := gates
+	if nested, ok := gates["gates"].(map[string]interface{}); ok {
+		byStage = nested
+	}
+	for stage, gate := range byStage {
+		s.addGatePending(stage, gate, time.Now())
+	}
 	writeJSON(w, http.StatusOK, gates)
 }
 
@@ -378,23 +499,32 @@ func (s *Server) handleGateByStage(w http.ResponseWriter, r *http.Request, stage
 		respondError(w, http.StatusNotFound, "gate not found")
 		return
 	}
+	s.addGatePending(stage, gate, time.Now())
 	writeJSON(w, http.StatusOK, gate)
 }
 
-func (s *Server) handleZones(w http.ResponseWriter, r *http.Request) {
-	var zones interface{}
-	if err := readJSON(s.statePath("zones.json"), &zones); err != nil {
-		tasks, _ := readJSONL(s.statePath("tasks.jsonl"))
-		zones = deriveZones(tasks)
+// addGatePending adds how long a gate has awaited approval, as the gate
+// timer recorded it, to the gate's JSON: awaiting_since, pending_min and
+// the escalation_level reached.
+func (s *Server) addGatePending(stage string, gate interface{}, now time.Time) {
+	g, ok := gate.(map[string]interface{})
+	if !ok || g["status"] == "approved" {
+		return
 	}
-	writeJSON(w, http.StatusOK, zones)
+	t, ok := gatetimer.Load(s.missionPath())[stage]
+	if !ok {
+		return
+	}
+	g["awaiting_since"] = t.AwaitingSince
+	g["pending_min"] = int(now.Sub(t.Since()).Minutes())
+	g["escalation_level"] = t.Level
 }
 
-func deriveZones(tasks []map[string]interface{}) []string {
+func deriveZones(tasks []Task) []string {
 	seen := map[string]bool{}
 	for _, t := range tasks {
-		if z, ok := t["zone"].(string); ok && z != "" {
-			seen[z] = true
+		if t.Zone != "" {
+			seen[t.Zone] = true
 		}
 	}
 	zones := make([]string, 0, len(seen))
@@ -419,9 +549,8 @@ func (s *Server) loadCheckpoints() []map[string]interface{} {
 			continue
 		}
 		name := strings.TrimSuffix(e.Name(), ".json")
-		path := filepath.Join(dir, e.Name())
-		var cpData map[string]interface{}
-		if err := readJSON(path, &cpData); err != nil {
+		cpData, err := checkpointSummary(filepath.Join(dir, e.Name()))
+		if err != nil {
 			continue
 		}
 		cp := map[string]interface{}{
@@ -445,65 +574,267 @@ func (s *Server) loadCheckpoints() []map[string]interface{} {
 	return checkpoints
 }
 
+// handleCheckpointByID streams the checkpoint file with its full task
+// snapshot.
+func (s *Server) handleCheckpointByID(w http.ResponseWriter, r *http.Request, id string) {
+	if !validateTaskID(id) {
+		respondError(w, http.StatusBadRequest, "invalid checkpoint ID")
+		return
+	}
+	s.serveFile(w, r, s.missionPath("orchestrator", "checkpoints", id+".json"), "application/json", "checkpoint", nil)
+}
+
 func (s *Server) handleCheckpoints(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		s.handleCreateCheckpoint(w, r)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, s.loadCheckpoints())
+	page, err := parsePage(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeList(w, "checkpoints", s.loadCheckpoints(), page)
 }
 
-func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+// handleFindings lists findings recorded by handoffs (.mission/findings/*.json),
+// one record per finding tagged with its task_id. Filters: task, type, severity.
+// Always paged; ordered by task_id then file order unless sort is given.
+func (s *Server) handleFindings(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
-	limit, _ := strconv.Atoi(q.Get("limit"))
-	if limit <= 0 {
-		limit = 50
+	page, err := parsePage(q)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	offset, _ := strconv.Atoi(q.Get("offset"))
-	category := q.Get("category")
-	actor := q.Get("actor")
+	page.Paged = true
+	task, typ, severity := q.Get("task"), q.Get("type"), q.Get("severity")
 
-	entries, err := readJSONL(s.missionPath("audit.jsonl"))
-	if err != nil {
+	dir := s.missionPath("findings")
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Filter
-	var filtered []map[string]interface{}
+	var findings []map[string]interface{}
 	for _, e := range entries {
-		if category != "" && fmt.Sprint(e["category"]) != category {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		taskID := strings.TrimSuffix(e.Name(), ".json")
+		if task != "" && taskID != task {
 			continue
 		}
-		if actor != "" && fmt.Sprint(e["actor"]) != actor {
+		var list []map[string]interface{}
+		if err := readJSON(filepath.Join(dir, e.Name()), &list); err != nil {
 			continue
 		}
-		filtered = append(filtered, e)
+		for i, f := range list {
+			if typ != "" && fmt.Sprint(f["type"]) != typ {
+				continue
+			}
+			if severity != "" && fmt.Sprint(f["severity"]) != severity {
+				continue
+			}
+			f["task_id"] = taskID
+			if _, ok := f["id"]; !ok {
+				f["id"] = fmt.Sprintf("%s/%d", taskID, i)
+			}
+			findings = append(findings, f)
+		}
 	}
 
-	// Paginate
-	total := len(filtered)
-	if offset > total {
-		offset = total
+	writeList(w, "findings", findings, page)
+}
+
+// handleConversation lists .mission/conversation.md as messages, or with
+// view=exchanges as human/assistant exchanges. role filters messages.
+func (s *Server) handleConversation(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := parsePage(q)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	end := offset + limit
-	if end > total {
-		end = total
+	page.Paged = true
+
+	messages, err := conversation.ParseFile(s.missionPath(conversation.FileName))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
-	page := filtered[offset:end]
-	if page == nil {
-		page = []map[string]interface{}{}
+
+	var key string
+	var items interface{}
+	switch view := q.Get("view"); view {
+	case "", "messages":
+		key = "messages"
+		if role := q.Get("role"); role != "" {
+			var filtered []conversation.Message
+			for _, m := range messages {
+				if m.Role == role {
+					filtered = append(filtered, m)
+				}
+			}
+			messages = filtered
+		}
+		items = messages
+	case "exchanges":
+		key, items = "exchanges", conversation.Exchanges(messages)
+	default:
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid view %q", view))
+		return
 	}
 
+	recs, err := toRecords(items)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeList(w, key, recs, page)
+}
+
+// toRecords converts a slice of structs to the generic records the list
+// helpers sort and page.
+func toRecords(v interface{}) ([]map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var recs []map[string]interface{}
+	if err := json.Unmarshal(data, &recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// handleChatHistory pages backwards through .mission/chat/history.jsonl:
+// the newest limit messages older than before, oldest first. next_before
+// is the before value for the previous page, absent on the first.
+func (s *Server) handleChatHistory(w http.ResponseWriter, r *http.Request) {
+	s.serveChat(w, r, chat.NewStore(s.missionPath()))
+}
+
+// serveChat pages backwards through store's messages, filtered by
+// ?session= and ?q= text.
+func (s *Server) serveChat(w http.ResponseWriter, r *http.Request, store *chat.Store) {
+	q := r.URL.Query()
+	query := chat.Query{Session: q.Get("session"), Search: q.Get("q"), Limit: 50}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit %q", v))
+			return
+		}
+		query.Limit = min(n, maxPageLimit)
+	}
+	if v := q.Get("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid before %q", v))
+			return
+		}
+		query.Before = n
+	}
+
+	messages, more, err := store.History(query)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := map[string]interface{}{
+		"messages": messages,
+		"limit":    query.Limit,
+		"has_more": more,
+	}
+	if more {
+		resp["next_before"] = messages[0].Seq
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if c := q.Get("cursor"); c != "" {
+		var err error
+		if offset, err = decodeCursor(c); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	var reverse bool
+	switch q.Get("sort") {
+	case "", "timestamp":
+	case "-timestamp":
+		reverse = true
+	default:
+		respondError(w, http.StatusBadRequest, "audit entries can only be sorted by timestamp or -timestamp")
+		return
+	}
+
+	filter := audit.Filter{
+		Actor:     q.Get("actor"),
+		User:      q.Get("user"),
+		Category:  q.Get("category"),
+		Action:    q.Get("action"),
+		RequestID: q.Get("request_id"),
+		Offset:    offset,
+		Limit:     limit,
+		Reverse:   reverse,
+	}
+	var err error
+	if filter.Since, err = parseTimeParam(q.Get("since")); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+		return
+	}
+	if filter.Until, err = parseTimeParam(q.Get("until")); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid until: "+err.Error())
+		return
+	}
+
+	res, err := audit.Query(s.missionPath(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if offset > res.Total {
+		offset = res.Total
+	}
+	page := pageParams{Limit: limit, Offset: offset}
+
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"entries": page,
-		"total":   total,
-		"offset":  offset,
-		"limit":   limit,
+		"entries":     res.Entries,
+		"total":       res.Total,
+		"offset":      offset,
+		"limit":       limit,
+		"next_cursor": page.nextCursor(len(res.Entries), res.Total),
 	})
 }
 
+// parseTimeParam accepts an RFC3339 timestamp or a duration ("1h" = one hour ago).
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
 func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
 	if s.tokens != nil {
 		writeJSON(w, http.StatusOK, s.tokens.Summary())
@@ -518,33 +849,28 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	home, _ := os.UserHomeDir()
-	registryPath := filepath.Join(home, ".mc", "projects.json")
-
-	var registry struct {
-		Projects map[string]string `json:"projects"`
-	}
-	if err := readJSON(registryPath, &registry); err != nil {
-		writeJSON(w, http.StatusOK, []interface{}{})
-		return
-	}
-	if registry.Projects == nil {
+	registry, err := LoadProjectRegistry()
+	if err != nil || len(registry) == 0 {
 		writeJSON(w, http.StatusOK, []interface{}{})
 		return
 	}
 
 	type projectInfo struct {
-		Name   string `json:"name"`
-		Path   string `json:"path"`
-		Active bool   `json:"active"`
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		Path      string `json:"path"`
+		APIPrefix string `json:"api_prefix"`
+		Active    bool   `json:"active"`
 	}
 	currentDir := s.getMissionDir()
 	var projects []projectInfo
-	for name, path := range registry.Projects {
+	for name, path := range registry {
 		projects = append(projects, projectInfo{
-			Name:   name,
-			Path:   path,
-			Active: path == currentDir,
+			ID:        name,
+			Name:      name,
+			Path:      path,
+			APIPrefix: ProjectAPIPrefix(name),
+			Active:    ProjectDir(path) == currentDir,
 		})
 	}
 	sort.Slice(projects, func(i, j int) bool {
@@ -572,9 +898,14 @@ func (s *Server) loadSpecs() []SpecInfo {
 		return []SpecInfo{}
 	}
 
-	tasks, _ := readJSONL(s.statePath("tasks.jsonl"))
+	tasks, _ := s.readTasks()
+	states, _ := specs.List(s.missionPath())
+	byID := map[string]specs.Spec{}
+	for _, st := range states {
+		byID[st.ID] = st
+	}
 
-	var specs []SpecInfo
+	var list []SpecInfo
 	for _, e := range entries {
 		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
 			continue
@@ -594,30 +925,40 @@ func (s *Server) loadSpecs() []SpecInfo {
 			}
 		}
 
-		// Find linked tasks
-		var linked []string
+		// Find linked tasks, and those linked to an earlier version
+		st := byID[id]
+		var linked, stale []string
 		for _, t := range tasks {
-			tid := fmt.Sprint(t["id"])
-			if spec, ok := t["spec"].(string); ok && spec == id {
-				linked = append(linked, tid)
+			if t.Spec == id {
+				linked = append(linked, t.ID)
+				if t.SpecHash != "" && t.SpecHash != st.CurrentHash && t.Status != bridge.TaskStatusArchived {
+					stale = append(stale, t.ID)
+				}
 			}
 		}
 		if linked == nil {
 			linked = []string{}
 		}
+		if st.Status == "" {
+			st.Status = specs.StatusDraft
+		}
 
-		specs = append(specs, SpecInfo{
+		list = append(list, SpecInfo{
 			ID:          id,
 			Title:       title,
 			Filename:    name,
 			LinkedTasks: linked,
 			IsOrphan:    len(linked) == 0,
+			Status:      st.Status,
+			Version:     st.Version,
+			Modified:    st.Modified,
+			StaleTasks:  stale,
 		})
 	}
-	if specs == nil {
-		specs = []SpecInfo{}
+	if list == nil {
+		list = []SpecInfo{}
 	}
-	return specs
+	return list
 }
 
 func (s *Server) handleSpecs(w http.ResponseWriter, r *http.Request) {
@@ -665,14 +1006,23 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleGateApprove(w http.ResponseWriter, r *http.Request, stage string) {
-	out, err := s.runMC("gate", "approve", stage)
+	var req GateActionRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	args := []string{"gate", "approve", stage}
+	if req.Note != "" {
+		args = append(args, "--note", req.Note)
+	}
+	if req.OverrideFindings != "" {
+		args = append(args, "--override-findings", req.OverrideFindings)
+	}
+
+	out, err := s.runMC(r.Context(), args...)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc gate approve failed: %s", out))
+		respondCommandError(w, "mc gate approve failed", out)
 		return
 	}
-	if s.hub != nil {
-		s.hub.BroadcastRaw("gates", "gate_approved", map[string]string{"stage": stage})
-	}
+	s.broadcast(r.Context(), "gates", "gate_approved", map[string]string{"stage": stage})
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
 }
 
@@ -685,48 +1035,49 @@ func (s *Server) handleGateReject(w http.ResponseWriter, r *http.Request, stage
 		args = append(args, "--reason", req.Reason)
 	}
 
-	out, err := s.runMC(args...)
+	out, err := s.runMC(r.Context(), args...)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc gate reject failed: %s", out))
+		respondCommandError(w, "mc gate reject failed", out)
 		return
 	}
-	if s.hub != nil {
-		s.hub.BroadcastRaw("gates", "gate_rejected", map[string]string{"stage": stage, "reason": req.Reason})
-	}
+	s.broadcast(r.Context(), "gates", "gate_rejected", map[string]string{"stage": stage, "reason": req.Reason})
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
 }
 
 func (s *Server) handleSpawnWorker(w http.ResponseWriter, r *http.Request) {
-	out, err := s.runMC("worker", "spawn")
+	if spendBlocked(w) {
+		return
+	}
+	out, err := s.runMC(r.Context(), "worker", "spawn")
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc worker spawn failed: %s", out))
+		respondCommandError(w, "mc worker spawn failed", out)
 		return
 	}
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
 }
 
 func (s *Server) handleKillWorker(w http.ResponseWriter, r *http.Request, id string) {
-	out, err := s.runMC("worker", "kill", id)
+	out, err := s.runMC(r.Context(), "worker", "kill", id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc worker kill failed: %s", out))
+		respondCommandError(w, "mc worker kill failed", out)
 		return
 	}
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
 }
 
 func (s *Server) handleCreateCheckpoint(w http.ResponseWriter, r *http.Request) {
-	out, err := s.runMC("checkpoint")
+	out, err := s.runMC(r.Context(), "checkpoint")
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc checkpoint failed: %s", out))
+		respondCommandError(w, "mc checkpoint failed", out)
 		return
 	}
 	writeJSON(w, http.StatusCreated, CommandResult{Success: true, Output: out})
 }
 
 func (s *Server) handleRestartCheckpoint(w http.ResponseWriter, r *http.Request, id string) {
-	out, err := s.runMC("checkpoint", "restart", id)
+	out, err := s.runMC(r.Context(), "checkpoint", "restart", id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc checkpoint restart failed: %s", out))
+		respondCommandError(w, "mc checkpoint restart failed", out)
 		return
 	}
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
@@ -735,11 +1086,11 @@ func (s *Server) handleRestartCheckpoint(w http.ResponseWriter, r *http.Request,
 func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 	var req CreateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid request body")
+		problem.InvalidBody(w, nil)
 		return
 	}
 	if req.Title == "" {
-		respondError(w, http.StatusBadRequest, "title is required")
+		problem.Validation(w, "title is required")
 		return
 	}
 
@@ -750,10 +1101,96 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 	if req.Zone != "" {
 		args = append(args, "--zone", req.Zone)
 	}
+	if req.Persona != "" {
+		args = append(args, "--persona", req.Persona)
+	}
+	if req.Assignee != "" {
+		args = append(args, "--assignee", req.Assignee)
+	}
+	if len(req.DependsOn) > 0 {
+		tasks, _ := s.readTasks()
+		deps := dependencyMap(tasks)
+		for _, d := range req.DependsOn {
+			if _, ok := deps[d]; !ok {
+				problem.Validation(w, "unknown dependency: "+d)
+				return
+			}
+		}
+		args = append(args, "--depends-on", strings.Join(req.DependsOn, ","))
+	}
+	if req.Estimate < 0 {
+		problem.Validation(w, "estimate must not be negative")
+		return
+	}
+	if req.Estimate > 0 {
+		args = append(args, "--estimate", strconv.FormatFloat(req.Estimate, 'f', -1, 64))
+	}
+	if len(req.Labels) > 0 {
+		labels, err := bridge.NormalizeLabels(req.Labels)
+		if err != nil {
+			problem.Validation(w, err.Error())
+			return
+		}
+		args = append(args, "--label", strings.Join(labels, ","))
+	}
+	if req.OverrideMatrix != "" {
+		args = append(args, "--override-matrix", req.OverrideMatrix)
+	}
+
+	out, err := s.runMC(r.Context(), args...)
+	if err != nil {
+		respondTaskCommandError(w, "mc task create failed", out)
+		return
+	}
+	writeJSON(w, http.StatusCreated, CommandResult{Success: true, Output: out})
+}
+
+// maxBulkTasks caps the tasks accepted by one POST /api/tasks/bulk.
+const maxBulkTasks = 500
+
+func (s *Server) handleBulkCreateTasks(w http.ResponseWriter, r *http.Request) {
+	var reqs []CreateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		problem.InvalidBody(w, err)
+		return
+	}
+	if len(reqs) == 0 {
+		problem.Validation(w, "at least one task is required")
+		return
+	}
+	if len(reqs) > maxBulkTasks {
+		problem.Validation(w, fmt.Sprintf("at most %d tasks can be created at once", maxBulkTasks))
+		return
+	}
+
+	tasks, _ := s.readTasks()
+	deps := dependencyMap(tasks)
+	for i, req := range reqs {
+		switch {
+		case strings.TrimSpace(req.Title) == "":
+			problem.Validation(w, fmt.Sprintf("task %d: title is required", i))
+			return
+		case req.Estimate < 0:
+			problem.Validation(w, fmt.Sprintf("task %d: estimate must not be negative", i))
+			return
+		}
+		if _, err := bridge.NormalizeLabels(req.Labels); err != nil {
+			problem.Validation(w, fmt.Sprintf("task %d: %v", i, err))
+			return
+		}
+		for _, d := range req.DependsOn {
+			if _, ok := deps[d]; !ok {
+				problem.Validation(w, fmt.Sprintf("task %d: unknown dependency: %s", i, d))
+				return
+			}
+		}
+	}
 
-	out, err := s.runMC(args...)
+	// mc task import creates all of the tasks or none of them
+	input, _ := json.Marshal(reqs)
+	out, err := s.runMCInput(r.Context(), input, "task", "import", "-", "--format", "json")
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc task create failed: %s", out))
+		respondTaskCommandError(w, "mc task import failed", out)
 		return
 	}
 	writeJSON(w, http.StatusCreated, CommandResult{Success: true, Output: out})
@@ -762,7 +1199,7 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request, id string) {
 	var req UpdateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid request body")
+		problem.InvalidBody(w, nil)
 		return
 	}
 
@@ -773,10 +1210,34 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request, id str
 	if req.Stage != "" {
 		args = append(args, "--stage", req.Stage)
 	}
+	if req.Estimate != nil {
+		if *req.Estimate < 0 {
+			problem.Validation(w, "estimate must not be negative")
+			return
+		}
+		args = append(args, "--estimate", strconv.FormatFloat(*req.Estimate, 'f', -1, 64))
+	}
+	if req.Assignee != nil {
+		args = append(args, "--assignee", *req.Assignee)
+	}
+	for _, l := range []struct {
+		flag   string
+		labels []string
+	}{{"--add-label", req.AddLabels}, {"--remove-label", req.RemoveLabels}} {
+		if len(l.labels) == 0 {
+			continue
+		}
+		labels, err := bridge.NormalizeLabels(l.labels)
+		if err != nil {
+			problem.Validation(w, err.Error())
+			return
+		}
+		args = append(args, l.flag, strings.Join(labels, ","))
+	}
 
-	out, err := s.runMC(args...)
+	out, err := s.runMC(r.Context(), args...)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc task update failed: %s", out))
+		respondCommandError(w, "mc task update failed", out)
 		return
 	}
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
@@ -785,7 +1246,7 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request, id str
 func (s *Server) handleTaskDependencies(w http.ResponseWriter, r *http.Request, id string) {
 	var req TaskDepRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid request body")
+		problem.InvalidBody(w, nil)
 		return
 	}
 
@@ -794,9 +1255,58 @@ func (s *Server) handleTaskDependencies(w http.ResponseWriter, r *http.Request,
 		action = "remove"
 	}
 
-	out, err := s.runMC("task", "dep", action, id, req.DepID)
+	// Refuse a cycle up front; mc checks again under its own read of the file
+	if action == "add" {
+		tasks, _ := s.readTasks()
+		deps := dependencyMap(tasks)
+		if _, ok := deps[id]; ok {
+			deps[id] = append(deps[id], req.DepID)
+			if cycle := bridge.FindCycle(deps); cycle != nil {
+				respondCycle(w, cycle)
+				return
+			}
+		}
+	}
+
+	out, err := s.runMC(r.Context(), "task", "dep", action, id, req.DepID)
+	if err != nil {
+		respondTaskCommandError(w, "mc task dep failed", out)
+		return
+	}
+	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
+}
+
+// handleTaskArchive archives or unarchives a task via mc. mc refuses to
+// archive a task others still depend on unless force is set.
+func (s *Server) handleTaskArchive(w http.ResponseWriter, r *http.Request, id, action string) {
+	var req TaskArchiveRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			problem.InvalidBody(w, nil)
+			return
+		}
+	}
+
+	args := []string{"task", action, id}
+	if action == "archive" {
+		if req.Reason != "" {
+			args = append(args, "--reason", req.Reason)
+		}
+		if req.Force {
+			args = append(args, "--force")
+		}
+	}
+	out, err := s.runMC(r.Context(), args...)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc task dep failed: %s", out))
+		switch {
+		case strings.Contains(out, "task not found"):
+			problem.NotFound(w, "task not found")
+		case strings.Contains(out, "depend on it"), strings.Contains(out, "is not archived"), strings.Contains(out, "already archived"):
+			problem.Write(w, http.StatusConflict, problem.CodeConflict, fmt.Sprintf("cannot %s task %s", action, id),
+				map[string]interface{}{"output": strings.TrimSpace(out)})
+		default:
+			respondCommandError(w, "mc task "+action+" failed", out)
+		}
 		return
 	}
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
@@ -805,11 +1315,11 @@ func (s *Server) handleTaskDependencies(w http.ResponseWriter, r *http.Request,
 func (s *Server) handleStageOverride(w http.ResponseWriter, r *http.Request) {
 	var req StageOverrideRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid request body")
+		problem.InvalidBody(w, nil)
 		return
 	}
 	if req.Stage == "" {
-		respondError(w, http.StatusBadRequest, "stage is required")
+		problem.Validation(w, "stage is required")
 		return
 	}
 
@@ -818,42 +1328,76 @@ func (s *Server) handleStageOverride(w http.ResponseWriter, r *http.Request) {
 		args = append(args, "--reason", req.Reason)
 	}
 
-	out, err := s.runMC(args...)
+	out, err := s.runMC(r.Context(), args...)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("mc stage set failed: %s", out))
+		respondCommandError(w, "mc stage set failed", out)
 		return
 	}
-	if s.hub != nil {
-		s.hub.BroadcastRaw("stage", "stage_changed", map[string]string{"stage": req.Stage, "reason": req.Reason})
+	s.broadcast(r.Context(), "stage", "stage_changed", map[string]string{"stage": req.Stage, "reason": req.Reason})
+	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
+}
+
+// handleStageRollback returns to the previous stage and reopens its gate
+// via mc stage rollback, which refuses while workers are running.
+func (s *Server) handleStageRollback(w http.ResponseWriter, r *http.Request) {
+	var req StageRollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, nil)
+		return
+	}
+	if req.Reason == "" {
+		problem.Validation(w, "reason is required")
+		return
+	}
+
+	out, err := s.runMC(r.Context(), "stage", "rollback", "--reason", req.Reason)
+	if err != nil {
+		respondCommandError(w, "mc stage rollback failed", out)
+		return
 	}
+	var stage struct {
+		Current        string `json:"current"`
+		RolledBackFrom string `json:"rolled_back_from"`
+	}
+	_ = readJSON(s.statePath("stage.json"), &stage)
+	s.broadcast(r.Context(), "stage", "stage_rolled_back", map[string]string{
+		"previous": stage.RolledBackFrom,
+		"current":  stage.Current,
+		"reason":   req.Reason,
+	})
 	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
 }
 
 func (s *Server) handleProjectSwitch(w http.ResponseWriter, r *http.Request) {
 	var req ProjectSwitchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid request body")
+		problem.InvalidBody(w, nil)
 		return
 	}
 	if req.Path == "" {
-		respondError(w, http.StatusBadRequest, "path is required")
+		problem.Validation(w, "path is required")
 		return
 	}
 
-	// Validate path against project registry
-	home, _ := os.UserHomeDir()
-	registryPath := filepath.Join(home, ".mc", "projects.json")
-	var registry struct {
-		Projects map[string]string `json:"projects"`
+	s.mu.RLock()
+	pinned := s.pinned
+	s.mu.RUnlock()
+	if pinned {
+		respondError(w, http.StatusConflict, "this API is bound to one project; use /api/p/{id}/ to address another")
+		return
 	}
-	if err := readJSON(registryPath, &registry); err != nil {
+
+	// Validate path against project registry
+	registry, err := LoadProjectRegistry()
+	if err != nil {
 		respondError(w, http.StatusInternalServerError, "cannot read project registry")
 		return
 	}
 
+	req.Path = ProjectDir(req.Path)
 	registered := false
-	for _, p := range registry.Projects {
-		if p == req.Path {
+	for _, p := range registry {
+		if ProjectDir(p) == req.Path {
 			registered = true
 			break
 		}
@@ -873,8 +1417,101 @@ func (s *Server) handleProjectSwitch(w http.ResponseWriter, r *http.Request) {
 	s.missionDir = req.Path
 	s.mu.Unlock()
 
+	// Record the switch in config.json so other dashboards and the next
+	// start agree on the active project.
+	config, err := updateGlobalConfig(globalConfigPath(), func(config *GlobalConfig) error {
+		config.LastProject = req.Path
+		for i := range config.Projects {
+			if config.Projects[i].Path == req.Path {
+				config.Projects[i].LastOpened = time.Now().UTC().Format(time.RFC3339)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[projects] cannot update config.json after switch: %v", err)
+		config = &GlobalConfig{Projects: []Project{}, LastProject: req.Path}
+	}
+	var project *Project
+	for i := range config.Projects {
+		if config.Projects[i].Path == req.Path {
+			project = &config.Projects[i]
+		}
+	}
+	s.broadcast(r.Context(), ProjectTopic, EventProjectSwitched, newProjectEvent(req.Path, project, config))
+
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"success":    true,
 		"missionDir": req.Path,
 	})
 }
+
+// handleReport compiles the mission report as Markdown (default) or HTML.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	var tok *tokens.TokenSummary
+	if s.tokens != nil {
+		summary := s.tokens.Summary()
+		tok = &summary
+	}
+
+	rep, err := report.Build(s.missionPath(), tok)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "md", "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(rep.Markdown()))
+	case "html":
+		out, err := rep.HTML()
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(out))
+	case "json":
+		writeJSON(w, http.StatusOK, rep)
+	default:
+		respondError(w, http.StatusBadRequest, "format must be one of: md, html, json")
+	}
+}
+
+// handleReleaseNotes serves GET /api/release/notes: what was completed
+// since the last release tag (or ?since=), traced to the specs it
+// implements. ?version= titles the notes; ?format= is md or json. Stored
+// secrets are redacted, as in mc release notes.
+func (s *Server) handleReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	notes, err := report.BuildReleaseNotes(s.missionPath(), q.Get("since"), q.Get("version"))
+	if errors.Is(err, report.ErrUnknownTag) {
+		problem.Validation(w, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch q.Get("format") {
+	case "", "md", "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(secrets.Redact(notes.Markdown())))
+	case "json":
+		data, err := json.Marshal(notes)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(secrets.Redact(string(data)) + "\n"))
+	default:
+		respondError(w, http.StatusBadRequest, "format must be one of: md, json")
+	}
+}
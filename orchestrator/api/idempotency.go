@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// Idempotency headers. A POST carrying Idempotency-Key is run once; a retry
+// with the same key within the TTL gets the first response again, marked
+// with Idempotent-Replayed.
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	DefaultIdempotencyTTL     = 10 * time.Minute
+	maxIdempotencyKeyLen      = 255
+	maxIdempotencyBody        = 1 << 20 // larger responses (streams) aren't cached
+	maxIdempotencyRequestBody = 1 << 20
+)
+
+// IdempotencyCache remembers the responses to POSTs sent with an
+// Idempotency-Key so agents can retry them safely. Keys are scoped to the
+// client (API token or IP, as for rate limiting), the user and the path.
+// Reusing a key with a different body is refused with 422, and a retry that
+// arrives while the first request is still running gets 409. Server errors
+// (5xx) are not cached, so those requests can be retried for real.
+type IdempotencyCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*idempotentResponse
+	lastSweep time.Time
+}
+
+type idempotentResponse struct {
+	bodyHash [sha256.Size]byte
+	done     bool
+	expires  time.Time
+	status   int
+	header   http.Header
+	body     []byte
+}
+
+// NewIdempotencyCache returns a cache keeping responses for ttl. A ttl <= 0
+// disables it.
+func NewIdempotencyCache(ttl time.Duration) *IdempotencyCache {
+	return &IdempotencyCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*idempotentResponse),
+	}
+}
+
+// sweep drops expired entries at most once a minute. Callers hold c.mu.
+func (c *IdempotencyCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+	for k, e := range c.entries {
+		if e.done && now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Middleware applies the cache to POST requests that carry an
+// Idempotency-Key.
+func (c *IdempotencyCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if c == nil || c.ttl <= 0 || r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			problem.Validation(w, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotencyRequestBody+1))
+		if err != nil {
+			problem.InvalidBody(w, err)
+			return
+		}
+		if len(body) > maxIdempotencyRequestBody {
+			problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeBadRequest, "request body too large for an idempotent request", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+		cacheKey := rateLimitKey(r) + " " + UserFromContext(r.Context()).String() + " " + r.URL.Path + " " + key
+
+		now := c.now()
+		c.mu.Lock()
+		c.sweep(now)
+		e, ok := c.entries[cacheKey]
+		if ok && e.done && now.After(e.expires) {
+			ok = false
+		}
+		if ok {
+			c.mu.Unlock()
+			switch {
+			case e.bodyHash != hash:
+				problem.Write(w, http.StatusUnprocessableEntity, problem.CodeConflict,
+					"Idempotency-Key was already used with a different request body", nil)
+			case !e.done:
+				problem.Write(w, http.StatusConflict, problem.CodeConflict,
+					"a request with this Idempotency-Key is still in progress", nil)
+			default:
+				log.Printf("[idempotency] replaying %s %s", r.Method, r.URL.Path)
+				for k, v := range e.header {
+					w.Header()[k] = v
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(e.status)
+				w.Write(e.body)
+			}
+			return
+		}
+		e = &idempotentResponse{bodyHash: hash}
+		c.entries[cacheKey] = e
+		c.mu.Unlock()
+
+		// Unless the response is stored below, the key is released again
+		// so a retry can run; this includes a handler that panics.
+		done := false
+		defer func() {
+			if !done {
+				c.mu.Lock()
+				delete(c.entries, cacheKey)
+				c.mu.Unlock()
+			}
+		}()
+
+		before := w.Header().Clone()
+		rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status >= 500 || rec.overflow {
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		done = true
+		e.done = true
+		e.expires = c.now().Add(c.ttl)
+		e.status = rec.status
+		e.header = handlerHeaders(before, rec.Header())
+		e.body = rec.body.Bytes()
+	})
+}
+
+// handlerHeaders returns the headers the handler added to a response,
+// leaving out those set by outer middleware (request ID, rate limits) and
+// by compression, which apply to each delivery afresh.
+func handlerHeaders(before, after http.Header) http.Header {
+	h := http.Header{}
+	for k, v := range after {
+		switch k {
+		case "Content-Encoding", "Content-Length", "Vary":
+			continue
+		}
+		if _, ok := before[k]; !ok {
+			h[k] = append([]string(nil), v...)
+		}
+	}
+	return h
+}
+
+// idempotencyRecorder copies the response it writes through so it can be
+// replayed.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (r *idempotencyRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if r.body.Len()+len(b) > maxIdempotencyBody {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *idempotencyRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	calls := 0
+	c := NewIdempotencyCache(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Location", "/api/tasks/t1")
+		writeJSON(w, http.StatusCreated, map[string]int{"call": calls})
+	}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("k1", `{"title":"a"}`)
+	retry := send("k1", `{"title":"a"}`)
+	if calls != 1 {
+		t.Fatalf("Expected the handler to run once, ran %d times", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response replayed, got %d %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Header().Get("Location") != "/api/tasks/t1" {
+		t.Errorf("Expected replay headers, got %v", retry.Header())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("First response must not be marked as replayed")
+	}
+
+	if w := send("k1", `{"title":"b"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 reusing a key with a new body, got %d", w.Code)
+	}
+
+	send("", `{"title":"a"}`)
+	send("k2", `{"title":"a"}`)
+	if calls != 3 {
+		t.Errorf("Expected requests without or with new keys to run, ran %d times", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	send("k1", `{"title":"a"}`)
+	if calls != 4 {
+		t.Errorf("Expected an expired key to run again, ran %d times", calls)
+	}
+}
+
+func TestIdempotencySkipsServerErrors(t *testing.T) {
+	calls := 0
+	c := NewIdempotencyCache(time.Minute)
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		respondError(w, http.StatusInternalServerError, "boom")
+	}))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/gates/goal/approve", nil)
+		req.Header.Set(IdempotencyKeyHeader, "k")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 5xx responses not to be cached, ran %d times", calls)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterPanic(t *testing.T) {
+	calls := 0
+	c := NewIdempotencyCache(time.Minute)
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	serve := func() (rec *httptest.ResponseRecorder) {
+		defer func() { recover() }()
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/tasks", nil)
+		req.Header.Set(IdempotencyKeyHeader, "k")
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	serve()
+	if rec := serve(); rec.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("Expected a retry after a panic to run, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	c := NewIdempotencyCache(time.Minute)
+	var inner *httptest.ResponseRecorder
+	var handler http.Handler
+	handler = c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inner == nil {
+			// A retry arriving while this request is still running
+			inner = httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/tasks", nil)
+			req.Header.Set(IdempotencyKeyHeader, "k")
+			handler.ServeHTTP(inner, req)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest("POST", "/api/tasks", nil)
+	req.Header.Set(IdempotencyKeyHeader, "k")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if inner.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a concurrent retry, got %d", inner.Code)
+	}
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/briefing"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+// Limits on files shared with the King.
+const (
+	MaxKingAttachments     = 10
+	MaxKingAttachmentBytes = 1 << 20 // each
+	MaxKingAttachmentTotal = 4 << 20 // all of a message's together
+)
+
+// kingShareTTL is how long the King has to read a message's attachments
+// before their copies are removed.
+const kingShareTTL = time.Hour
+
+// kingSharePrefix names the temporary directories attachments are copied to.
+const kingSharePrefix = "mc-king-"
+
+// KingMessageRequest is the body of POST /api/king/message.
+type KingMessageRequest struct {
+	Content     string           `json:"content"`
+	Attachments []KingAttachment `json:"attachments,omitempty"`
+}
+
+// KingAttachment is a file to share with the King: a path in the project,
+// or with Diff its uncommitted changes (git diff HEAD), which may be a
+// directory's.
+type KingAttachment struct {
+	Path string `json:"path"`
+	Diff bool   `json:"diff,omitempty"`
+}
+
+// SharedFile is an attachment as the King got it.
+type SharedFile struct {
+	Path  string `json:"path"`
+	Diff  bool   `json:"diff,omitempty"`
+	File  string `json:"file"` // the copy the message points to
+	Bytes int    `json:"bytes"`
+}
+
+// handleKingPrompt serves GET /api/king/prompt: the prompt a King spawned
+// now would be given, assembled from CLAUDE.md, config.json's king
+// section, the personas for the active stage and the stage.
+func (s *Server) handleKingPrompt(w http.ResponseWriter, r *http.Request) {
+	prompt, err := briefing.LoadKingPrompt(s.missionPath())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, prompt)
+}
+
+// handleKingTranscript serves GET /api/king/transcript, the King
+// conversation in .mission/king/transcript.jsonl, paged like
+// /api/chat/history; ?q= keeps the messages containing that text.
+func (s *Server) handleKingTranscript(w http.ResponseWriter, r *http.Request) {
+	s.serveChat(w, r, chat.NewTranscript(s.missionPath()))
+}
+
+// SetKing sends King messages with send.
+func (s *Server) SetKing(send func(message string) error) {
+	s.mu.Lock()
+	s.king = send
+	s.mu.Unlock()
+}
+
+// handleKingMessage serves POST /api/king/message. Attachments are copied,
+// with stored secrets redacted, to a temporary directory, listed with their
+// copies at the end of the message and recorded in the audit log as
+// king_file_shared. The directory is removed if the message isn't sent,
+// and otherwise after kingShareTTL. Nothing is forwarded while the spend
+// limits are exceeded.
+func (s *Server) handleKingMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	send := s.king
+	s.mu.RUnlock()
+	if send == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.CodeUnavailable, "the King is not available", nil)
+		return
+	}
+	if spendBlocked(w) {
+		return
+	}
+	var req KingMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, err)
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" && len(req.Attachments) == 0 {
+		problem.Validation(w, "content or attachments are required")
+		return
+	}
+	if len(req.Attachments) > MaxKingAttachments {
+		problem.Validation(w, fmt.Sprintf("at most %d attachments are allowed", MaxKingAttachments))
+		return
+	}
+
+	// Read everything before writing anything, so a bad attachment
+	// shares nothing
+	contents := make([][]byte, len(req.Attachments))
+	total := 0
+	for i, a := range req.Attachments {
+		data, err := s.readAttachment(a)
+		if err != nil && !errors.Is(err, errAttachmentTooLarge) {
+			problem.Validation(w, fmt.Sprintf("%s: %v", a.Path, err))
+			return
+		}
+		total += len(data)
+		if err != nil || len(data) > MaxKingAttachmentBytes || total > MaxKingAttachmentTotal {
+			problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeValidation,
+				fmt.Sprintf("%s: attachments are limited to %d bytes each and %d in total", a.Path, MaxKingAttachmentBytes, MaxKingAttachmentTotal),
+				map[string]interface{}{"path": a.Path})
+			return
+		}
+		contents[i] = []byte(secrets.Redact(string(data)))
+	}
+
+	message := req.Content
+	shared := []SharedFile{}
+	sent := false
+	if len(req.Attachments) > 0 {
+		sweepKingShares(os.TempDir(), time.Now())
+		dir, err := os.MkdirTemp("", kingSharePrefix)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		defer func() {
+			if sent {
+				time.AfterFunc(kingShareTTL, func() { os.RemoveAll(dir) })
+			} else {
+				os.RemoveAll(dir)
+			}
+		}()
+		lines := []string{"Attached files:"}
+		for i, a := range req.Attachments {
+			name := strings.ReplaceAll(filepath.ToSlash(filepath.Clean(a.Path)), "/", "_")
+			if a.Diff {
+				name += ".diff"
+			}
+			f := SharedFile{Path: a.Path, Diff: a.Diff, File: filepath.Join(dir, fmt.Sprintf("%d-%s", i+1, name)), Bytes: len(contents[i])}
+			if err := os.WriteFile(f.File, contents[i], 0644); err != nil {
+				respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			what := f.Path
+			if f.Diff {
+				what = "changes to " + f.Path
+			}
+			lines = append(lines, fmt.Sprintf("- %s: %s (%d bytes)", what, f.File, f.Bytes))
+			shared = append(shared, f)
+		}
+		message = strings.TrimSpace(message + "\n\n" + strings.Join(lines, "\n"))
+	}
+
+	if err := send(message); err != nil {
+		problem.Write(w, http.StatusBadGateway, problem.CodeUpstream, err.Error(), nil)
+		return
+	}
+	sent = true
+	user := UserFromContext(r.Context()).String()
+	for _, f := range shared {
+		err := audit.Append(s.missionPath(), audit.Entry{
+			Action:    "king_file_shared",
+			Actor:     "api",
+			User:      user,
+			Category:  "king",
+			RequestID: RequestIDFromContext(r.Context()),
+			Details: map[string]interface{}{
+				"path":  f.Path,
+				"diff":  f.Diff,
+				"file":  f.File,
+				"bytes": f.Bytes,
+			},
+		})
+		if err != nil {
+			log.Printf("Warning: auditing King attachment %s: %v", f.Path, err)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sent":        true,
+		"attachments": shared,
+	})
+}
+
+// sweepKingShares removes attachment directories in tmp older than
+// kingShareTTL, which a restart kept from being removed on time.
+func sweepKingShares(tmp string, now time.Time) {
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), kingSharePrefix) {
+			continue
+		}
+		if info, err := e.Info(); err == nil && now.Sub(info.ModTime()) > kingShareTTL {
+			os.RemoveAll(filepath.Join(tmp, e.Name()))
+		}
+	}
+}
+
+var errAttachmentTooLarge = errors.New("attachment too large")
+
+// readAttachment reads a's contents: the file, or its diff against HEAD.
+// The path must be inside the project, symlinks included.
+func (s *Server) readAttachment(a KingAttachment) ([]byte, error) {
+	root, err := filepath.EvalSymlinks(s.getMissionDir())
+	if err != nil {
+		return nil, err
+	}
+	path := a.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, errors.New("not found")
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errors.New("outside the project")
+	}
+	if a.Diff {
+		cmd := exec.Command("git", "diff", "HEAD", "--", rel)
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git diff: %v", err)
+		}
+		return out, nil
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, errors.New("not a file")
+	}
+	if info.Size() > MaxKingAttachmentBytes {
+		return nil, errAttachmentTooLarge
+	}
+	return os.ReadFile(resolved)
+}
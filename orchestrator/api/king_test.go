@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/briefing"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+func TestKingPrompt(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"king": {"instructions": "Prefer small PRs."}}`), 0644)
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/king/prompt", nil))
+	var p briefing.KingPrompt
+	json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusOK || p.Inject != briefing.InjectSystem || len(p.Sources) == 0 {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestKingTranscript(t *testing.T) {
+	s, dir := newTestServer(t)
+	store := chat.NewTranscript(filepath.Join(dir, ".mission"))
+	for _, c := range []string{"Plan the auth work", "Done planning", "Start auth now"} {
+		store.Append(chat.Message{Session: "king", Role: "user", Content: c})
+	}
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/king/transcript?q=auth&limit=1", nil))
+	var page struct {
+		Messages   []chat.Message `json:"messages"`
+		HasMore    bool           `json:"has_more"`
+		NextBefore int64          `json:"next_before"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if w.Code != http.StatusOK || len(page.Messages) != 1 || page.Messages[0].Content != "Start auth now" || !page.HasMore || page.NextBefore != 3 {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestKingMessageAttachments(t *testing.T) {
+	s, dir := newTestServer(t)
+	var sent string
+	s.SetKing(func(message string) error {
+		sent = message
+		return nil
+	})
+	os.WriteFile(filepath.Join(dir, "spec.md"), []byte("# Auth spec\n"), 0644)
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("POST", "/api/king/message", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"content": "Review this", "attachments": [{"path": "spec.md"}]}`)
+	var resp struct {
+		Attachments []SharedFile `json:"attachments"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Attachments) != 1 {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+	f := resp.Attachments[0]
+	defer os.RemoveAll(filepath.Dir(f.File))
+	if data, _ := os.ReadFile(f.File); string(data) != "# Auth spec\n" || f.Bytes != len(data) {
+		t.Errorf("copy %s = %q", f.File, data)
+	}
+	if !strings.HasPrefix(sent, "Review this\n\nAttached files:\n") || !strings.Contains(sent, f.File) {
+		t.Errorf("message = %q", sent)
+	}
+	res, _ := audit.Query(filepath.Join(dir, ".mission"), audit.Filter{Action: "king_file_shared"})
+	if len(res.Entries) != 1 {
+		t.Errorf("audit entries = %d, want 1", len(res.Entries))
+	}
+
+	// Outside the project
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	os.WriteFile(outside, []byte("x"), 0644)
+	if w := post(`{"content": "x", "attachments": [{"path": "../` + filepath.Base(filepath.Dir(outside)) + `/secret.txt"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("relative escape: %d", w.Code)
+	}
+	if w := post(`{"content": "x", "attachments": [{"path": "` + outside + `"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("absolute path outside: %d", w.Code)
+	}
+
+	// Too large
+	os.WriteFile(filepath.Join(dir, "big.log"), make([]byte, MaxKingAttachmentBytes+1), 0644)
+	if w := post(`{"content": "x", "attachments": [{"path": "big.log"}]}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too large: %d", w.Code)
+	}
+}
+
+func TestKingMessageRedactsAndCleansUp(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	store, err := secrets.Open(filepath.Join(home, ".mission-control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("API_TOKEN", "tok-12345"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Attachments are copied under TMPDIR
+	t.Setenv("TMPDIR", t.TempDir())
+
+	s, dir := newTestServer(t)
+	sendErr := errors.New("gateway down")
+	s.SetKing(func(string) error { return sendErr })
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("API_TOKEN=tok-12345\n"), 0644)
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("POST", "/api/king/message", strings.NewReader(`{"content": "x", "attachments": [{"path": ".env"}]}`)))
+		return w
+	}
+
+	// A message that isn't sent leaves no copies behind
+	if w := post(); w.Code != http.StatusBadGateway {
+		t.Fatalf("failed send: %d", w.Code)
+	}
+	if left, _ := filepath.Glob(filepath.Join(os.TempDir(), kingSharePrefix+"*")); len(left) != 0 {
+		t.Errorf("attachment directories left = %v", left)
+	}
+
+	sendErr = nil
+	w := post()
+	var resp struct {
+		Attachments []SharedFile `json:"attachments"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Attachments) != 1 {
+		t.Fatalf("%d: %s", w.Code, w.Body.String())
+	}
+	share := filepath.Dir(resp.Attachments[0].File)
+	if data, _ := os.ReadFile(resp.Attachments[0].File); string(data) != "API_TOKEN="+secrets.Mask+"\n" {
+		t.Errorf("copy = %q, want the secret redacted", data)
+	}
+
+	// Copies past their TTL are swept
+	sweepKingShares(filepath.Dir(share), time.Now())
+	if _, err := os.Stat(share); err != nil {
+		t.Errorf("fresh copies swept: %v", err)
+	}
+	sweepKingShares(filepath.Dir(share), time.Now().Add(kingShareTTL+time.Minute))
+	if _, err := os.Stat(share); !os.IsNotExist(err) {
+		t.Errorf("stale copies kept: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+// taskLabels returns a task's labels.
+func taskLabels(t Task) []string {
+	labels := make([]string, 0, len(t.Labels))
+	for _, l := range t.Labels {
+		if l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// hasLabels reports whether have includes every one of want.
+func hasLabels(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// labelQuery parses the label filter of a request: ?label= may be repeated
+// or comma-separated, and a task must carry all of the labels to match.
+func labelQuery(q url.Values) ([]string, error) {
+	var labels []string
+	for _, v := range q["label"] {
+		labels = append(labels, strings.Split(v, ",")...)
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return bridge.NormalizeLabels(labels)
+}
+
+// WithLabels returns the graph cut down to the tasks carrying every one of
+// labels and the edges between them.
+func (g GraphResponse) WithLabels(labels []string) GraphResponse {
+	if len(labels) == 0 {
+		return g
+	}
+	kept := map[string]bool{}
+	nodes := make([]GraphNode, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if hasLabels(n.Labels, labels) {
+			kept[n.ID] = true
+			nodes = append(nodes, n)
+		}
+	}
+	edges := make([]GraphEdge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		if kept[e.Source] && kept[e.Target] {
+			edges = append(edges, e)
+		}
+	}
+	g.Nodes, g.Edges = nodes, edges
+	return g
+}
+
+// labelFacets counts the unarchived tasks carrying each label, most used
+// first.
+func labelFacets(tasks []Task) []LabelFacet {
+	index := map[string]int{}
+	facets := []LabelFacet{}
+	for _, t := range tasks {
+		if t.Status == bridge.TaskStatusArchived {
+			continue
+		}
+		for _, l := range taskLabels(t) {
+			i, ok := index[l]
+			if !ok {
+				i = len(facets)
+				index[l] = i
+				facets = append(facets, LabelFacet{Label: l})
+			}
+			facets[i].Count++
+			if !t.Finished() {
+				facets[i].Open++
+			}
+		}
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Label < facets[j].Label
+	})
+	return facets
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLabelFilters(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(`{"id":"a","status":"done","labels":["security"]}
+{"id":"b","status":"pending","labels":["security","tech-debt"],"depends_on":["a"]}
+{"id":"c","status":"pending","labels":["tech-debt"],"depends_on":["b"]}
+{"id":"d","status":"archived","labels":["security"]}
+`), 0644)
+	routes := s.Routes()
+
+	get := func(url string, v interface{}) int {
+		t.Helper()
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		_ = json.Unmarshal(w.Body.Bytes(), v)
+		return w.Code
+	}
+
+	var tasks []map[string]interface{}
+	get("/api/tasks?label=security", &tasks)
+	if len(tasks) != 2 {
+		t.Errorf("label=security: got %d tasks, want 2", len(tasks))
+	}
+	get("/api/tasks?label=Security,tech-debt", &tasks)
+	if len(tasks) != 1 || tasks[0]["id"] != "b" {
+		t.Errorf("label=security,tech-debt: got %v, want b", tasks)
+	}
+	if code := get("/api/tasks?label=tech%20debt", &tasks); code != http.StatusBadRequest {
+		t.Errorf("invalid label: got %d, want 400", code)
+	}
+
+	var graph GraphResponse
+	get("/api/graph?label=security", &graph)
+	if len(graph.Nodes) != 2 || len(graph.Edges) != 1 {
+		t.Errorf("graph label=security: %d nodes, %d edges; want 2 and 1", len(graph.Nodes), len(graph.Edges))
+	}
+
+	var status struct {
+		Labels []LabelFacet `json:"labels"`
+	}
+	get("/api/status", &status)
+	want := []LabelFacet{{Label: "security", Count: 2, Open: 1}, {Label: "tech-debt", Count: 2, Open: 2}}
+	if len(status.Labels) != len(want) {
+		t.Fatalf("facets = %+v", status.Labels)
+	}
+	for i := range want {
+		if status.Labels[i] != want[i] {
+			t.Errorf("facet %d = %+v, want %+v", i, status.Labels[i], want[i])
+		}
+	}
+}
@@ -1,71 +1,227 @@
 package api
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"net"
 	"net/http"
-	"os"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/origins"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
 )
 
-// AllowedOrigins for CORS
-var AllowedOrigins = []string{
-	"https://darlington.dev",
-	"https://www.darlington.dev",
-	"http://localhost:3000",
-}
+// RequestIDHeader carries the request ID on requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// UserHeader names the caller when no per-user token identifies them. The
+// name is only used for attribution; roles are granted to callers a
+// per-user token identifies.
+const UserHeader = "X-MC-User"
+
+type requestIDKey struct{}
 
-// CORSMiddleware adds CORS headers for darlington.dev and local dev
+type userKey struct{}
+
+// verifiedKey marks a context whose user was identified by a per-user
+// token rather than named in X-MC-User.
+type verifiedKey struct{}
+
+// CORSMiddleware applies the default origin policy (localhost and the
+// hosted dashboard). Use CORS for a configured policy.
 func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		for _, allowed := range AllowedOrigins {
-			if origin == allowed {
+	return CORS(origins.Default())(next)
+}
+
+// CORS adds CORS headers for origins allowed by p. Preflights and
+// state-changing requests from other origins are refused with 403;
+// requests without an Origin header (CLI, server-to-server) pass through.
+func CORS(p *origins.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			if origin != "" && p.Allowed(origin) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-				break
 			}
-		}
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match, "+RequestIDHeader+", "+UserHeader+", "+IdempotencyKeyHeader)
+			w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader+", ETag, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, "+IdempotentReplayedHeader)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
+			if r.Method != http.MethodGet && r.Method != http.MethodHead && !p.AllowRequest(r) {
+				problem.Error(w, http.StatusForbidden, "origin not allowed: "+origin)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-// AuthMiddleware checks bearer token against MC_API_TOKEN env var.
-// If MC_API_TOKEN is not set, auth is disabled (local dev mode).
+// AuthMiddleware checks the bearer token (or ?token=) against the tokens
+// configured in MC_API_TOKEN and MC_API_TOKENS, and stores the user a
+// per-user token belongs to in the request context. With only the shared
+// token, or no tokens at all (auth disabled, local dev mode), the caller may
+// name themselves in the X-MC-User header instead; such a caller is
+// attributed by that name but holds only the "*" role.
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := os.Getenv("MC_API_TOKEN")
-		if token == "" {
-			// No token configured — auth disabled
-			next.ServeHTTP(w, r)
+		tokens := identity.Tokens()
+		if len(tokens) == 0 {
+			next.ServeHTTP(w, withHeaderUser(r))
 			return
 		}
 
-		// Check Authorization header
-		auth := r.Header.Get("Authorization")
-		if strings.HasPrefix(auth, "Bearer ") {
-			if strings.TrimPrefix(auth, "Bearer ") == token {
-				next.ServeHTTP(w, r)
-				return
-			}
+		// Check Authorization header, then the query param fallback
+		var t identity.Token
+		var ok bool
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			t, ok = identity.Lookup(tokens, strings.TrimPrefix(auth, "Bearer "))
 		}
-
-		// Check query param fallback
-		if r.URL.Query().Get("token") == token {
-			next.ServeHTTP(w, r)
+		if !ok {
+			t, ok = identity.Lookup(tokens, r.URL.Query().Get("token"))
+		}
+		if !ok {
+			problem.Error(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if t.Shared {
+			next.ServeHTTP(w, withHeaderUser(r))
 			return
 		}
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), t.User)))
+	})
+}
+
+// withHeaderUser attributes r to the user named in its X-MC-User header,
+// unverified.
+func withHeaderUser(r *http.Request) *http.Request {
+	u := identity.Parse(r.Header.Get(UserHeader))
+	if u.IsZero() {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), userKey{}, u))
+}
+
+// WithUser returns a context carrying the user behind a request, as
+// identified by their own token.
+func WithUser(ctx context.Context, u identity.User) context.Context {
+	return context.WithValue(context.WithValue(ctx, userKey{}, u), verifiedKey{}, true)
+}
+
+// VerifiedUser returns the user a per-user token identified, or the zero
+// User for callers who only named themselves or are anonymous.
+func VerifiedUser(ctx context.Context) identity.User {
+	if verified, _ := ctx.Value(verifiedKey{}).(bool); !verified {
+		return identity.User{}
+	}
+	return UserFromContext(ctx)
+}
+
+// UserFromContext returns the user stored by AuthMiddleware, or the zero
+// User.
+func UserFromContext(ctx context.Context) identity.User {
+	u, _ := ctx.Value(userKey{}).(identity.User)
+	return u
+}
+
+// RequestIDMiddleware assigns every request an ID (reusing a well-formed
+// incoming X-Request-ID), echoes it in the response header, stores it in the
+// request context and logs the request outcome with it.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
 
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(WithRequestID(r.Context(), id)))
+		log.Printf("[req %s] %s %s -> %d (%s)", id, r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
 	})
 }
 
+// WithRequestID returns a context carrying the given request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by RequestIDMiddleware, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID accepts short IDs of URL-safe characters so client-supplied
+// values can't inject into logs or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder captures the response status for logging while still
+// supporting streaming (Flush) and WebSocket upgrades (Hijack).
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // Chain applies middlewares in order
 func Chain(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for i := len(middlewares) - 1; i >= 0; i-- {
@@ -5,6 +5,9 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/origins"
 )
 
 func TestCORSMiddleware_AllowedOrigin(t *testing.T) {
@@ -52,6 +55,40 @@ func TestCORSMiddleware_Preflight(t *testing.T) {
 	}
 }
 
+func TestCORS_ConfiguredPolicy(t *testing.T) {
+	called := false
+	handler := CORS(origins.New([]string{"https://mc.example.com"}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest("POST", "/api/tasks", nil)
+	req.Header.Set("Origin", "https://mc.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !called || rec.Header().Get("Access-Control-Allow-Origin") != "https://mc.example.com" {
+		t.Errorf("allowed origin: called=%v header=%q", called, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	// The default localhost origin is not part of this policy.
+	called = false
+	req = httptest.NewRequest("POST", "/api/tasks", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if called || rec.Code != http.StatusForbidden {
+		t.Errorf("disallowed POST: called=%v status=%d", called, rec.Code)
+	}
+
+	// Reads without CORS headers are harmless; the browser withholds the body.
+	req = httptest.NewRequest("GET", "/api/tasks", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !called || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed GET: called=%v header=%q", called, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
 func TestAuthMiddleware_NoTokenConfigured(t *testing.T) {
 	os.Unsetenv("MC_API_TOKEN")
 
@@ -121,6 +158,33 @@ func TestAuthMiddleware_QueryParamToken(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_PerUserToken(t *testing.T) {
+	t.Setenv("MC_API_TOKEN", "shared")
+	t.Setenv("MC_API_TOKENS", "Alice <alice@example.com>=alice-token")
+
+	var got identity.User
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = UserFromContext(r.Context())
+	}))
+
+	// A per-user token names its owner; X-MC-User can't override it
+	req := httptest.NewRequest("POST", "/api/gates/goal/approve", nil)
+	req.Header.Set("Authorization", "Bearer alice-token")
+	req.Header.Set(UserHeader, "mallory")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got.Email != "alice@example.com" {
+		t.Errorf("expected alice from her token, got %+v", got)
+	}
+
+	// The shared token leaves attribution to X-MC-User
+	req = httptest.NewRequest("POST", "/api/gates/goal/approve?token=shared", nil)
+	req.Header.Set(UserHeader, "bob")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got.Name != "bob" {
+		t.Errorf("expected bob from X-MC-User, got %+v", got)
+	}
+}
+
 func TestChain(t *testing.T) {
 	called := false
 	handler := Chain(
@@ -143,3 +207,61 @@ func TestChain(t *testing.T) {
 		t.Errorf("CORS not applied through chain, got %q", got)
 	}
 }
+
+func TestRequestIDMiddleware_GeneratesID(t *testing.T) {
+	var ctxID string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID = RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/status", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	got := rec.Header().Get(RequestIDHeader)
+	if got == "" {
+		t.Fatal("expected X-Request-ID response header")
+	}
+	if ctxID != got {
+		t.Errorf("expected context ID %q to match header %q", ctxID, got)
+	}
+}
+
+func TestRequestIDMiddleware_ReusesIncomingID(t *testing.T) {
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/api/status", nil)
+	req.Header.Set(RequestIDHeader, "client-abc.123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "client-abc.123" {
+		t.Errorf("expected incoming ID to be reused, got %q", got)
+	}
+}
+
+func TestRequestIDMiddleware_RejectsMalformedID(t *testing.T) {
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/api/status", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nInjected: yes")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got == "" || got == "bad id\nInjected: yes" {
+		t.Errorf("expected a fresh ID for malformed input, got %q", got)
+	}
+}
+
+func TestRequestIDMiddleware_PreservesFlusher(t *testing.T) {
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("expected wrapped writer to implement http.Flusher")
+		}
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Error("expected wrapped writer to implement http.Hijacker")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ws", nil))
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+)
+
+// MaxMockupBytes limits an uploaded mockup.
+const MaxMockupBytes = 10 << 20
+
+// mockupIndex lists the mockups' links and metadata, in .mission/mockups/.
+const mockupIndex = "index.json"
+
+// mockupTypes are the image types accepted, by detected content type, with
+// the extension they are stored under. SVG is left out: served from the
+// dashboard's origin, its scripts would run there.
+var mockupTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var mockupName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Mockup is an image in .mission/mockups/, optionally linked to a task
+// and a spec.
+type Mockup struct {
+	Name        string    `json:"name"` // file name in .mission/mockups/
+	ContentType string    `json:"content_type"`
+	Bytes       int       `json:"bytes"`
+	Task        string    `json:"task,omitempty"`
+	Spec        string    `json:"spec,omitempty"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+	URL         string    `json:"url"`
+}
+
+// loadMockups returns the mockups in .mission/mockups/ by name. Images
+// dropped there by hand, without an index entry, are listed unlinked.
+func (s *Server) loadMockups() (map[string]Mockup, error) {
+	dir := s.missionPath("mockups")
+	index := map[string]Mockup{}
+	if data, err := os.ReadFile(filepath.Join(dir, mockupIndex)); err == nil {
+		var f struct {
+			Mockups map[string]Mockup `json:"mockups"`
+		}
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("mockups/%s: %w", mockupIndex, err)
+		}
+		for name, m := range f.Mockups {
+			index[name] = m
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	found := map[string]Mockup{}
+	for _, e := range entries {
+		name := e.Name()
+		contentType := mockupContentType(name)
+		if e.IsDir() || contentType == "" {
+			continue
+		}
+		m, ok := index[name]
+		if !ok {
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			m = Mockup{ContentType: contentType, Bytes: int(info.Size()), UploadedAt: info.ModTime().UTC()}
+		}
+		m.Name, m.URL = name, "/api/mockups/"+name
+		found[name] = m
+	}
+	return found, nil
+}
+
+// mockupContentType is the content type a stored mockup is served with,
+// by extension, or "" for files that aren't mockups.
+func mockupContentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".jpeg" {
+		ext = ".jpg"
+	}
+	for ct, e := range mockupTypes {
+		if e == ext {
+			return ct
+		}
+	}
+	return ""
+}
+
+func (s *Server) saveMockupIndex(all map[string]Mockup) error {
+	data, err := json.MarshalIndent(map[string]interface{}{"mockups": all}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := s.missionPath("mockups", mockupIndex)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// handleMockups serves GET /api/mockups, by name, and POST /api/mockups.
+// ?task= and ?spec= keep the mockups linked to a task or spec.
+func (s *Server) handleMockups(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		all, err := s.loadMockups()
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		q := r.URL.Query()
+		list := []Mockup{}
+		for _, m := range all {
+			if (q.Get("task") == "" || m.Task == q.Get("task")) && (q.Get("spec") == "" || m.Spec == q.Get("spec")) {
+				list = append(list, m)
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		writeJSON(w, http.StatusOK, map[string]interface{}{"mockups": list})
+	case http.MethodPost:
+		s.handleMockupUpload(w, r)
+	default:
+		problem.MethodNotAllowed(w)
+	}
+}
+
+// handleMockupUpload takes a multipart form: the image in "file" and
+// optionally "name", "task" and "spec". The type is detected from the
+// content, not the file name; an existing mockup is only replaced with
+// "replace=true", keeping its task and spec unless new ones are given.
+func (s *Server) handleMockupUpload(w http.ResponseWriter, r *http.Request) {
+	if !allowRole(w, r, s.getMissionDir(), identity.RoleContributor) {
+		return
+	}
+	// Room for the other form fields on top of the image
+	r.Body = http.MaxBytesReader(w, r.Body, MaxMockupBytes+64<<10)
+	if err := r.ParseMultipartForm(MaxMockupBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeValidation,
+				fmt.Sprintf("mockups are limited to %d bytes", MaxMockupBytes), nil)
+			return
+		}
+		problem.InvalidBody(w, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		problem.Validation(w, "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, MaxMockupBytes+1))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(data) > MaxMockupBytes {
+		problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeValidation,
+			fmt.Sprintf("mockups are limited to %d bytes", MaxMockupBytes), nil)
+		return
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := mockupTypes[contentType]
+	if !ok {
+		problem.Write(w, http.StatusUnsupportedMediaType, problem.CodeValidation,
+			fmt.Sprintf("%s is not a PNG, JPEG, GIF or WebP image", contentType), map[string]interface{}{"content_type": contentType})
+		return
+	}
+
+	base := r.FormValue("name")
+	if base == "" {
+		base = strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+	}
+	base = strings.Trim(mockupName.ReplaceAllString(base, "-"), "-")
+	if base == "" {
+		problem.Validation(w, "name must contain letters or digits")
+		return
+	}
+	m := Mockup{
+		Name:        base + ext,
+		ContentType: contentType,
+		Bytes:       len(data),
+		Task:        r.FormValue("task"),
+		Spec:        r.FormValue("spec"),
+		UploadedBy:  UserFromContext(r.Context()).String(),
+		UploadedAt:  time.Now().UTC(),
+		URL:         "/api/mockups/" + base + ext,
+	}
+	if m.Task != "" && !s.taskExists(m.Task) {
+		problem.Validation(w, "unknown task: "+m.Task)
+		return
+	}
+	if m.Spec != "" {
+		if _, err := specs.CurrentHash(s.missionPath(), m.Spec); err != nil {
+			problem.Validation(w, err.Error())
+			return
+		}
+	}
+
+	s.mockupsMu.Lock()
+	defer s.mockupsMu.Unlock()
+	all, err := s.loadMockups()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if old, exists := all[m.Name]; exists {
+		if r.FormValue("replace") != "true" {
+			problem.Write(w, http.StatusConflict, problem.CodeConflict, "mockup "+m.Name+" already exists", map[string]interface{}{"name": m.Name})
+			return
+		}
+		// A new version keeps the links it isn't given
+		if m.Task == "" {
+			m.Task = old.Task
+		}
+		if m.Spec == "" {
+			m.Spec = old.Spec
+		}
+	}
+	dir := s.missionPath("mockups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, m.Name), data, 0644); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	all[m.Name] = m
+	if err := s.saveMockupIndex(all); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = audit.Append(s.missionPath(), audit.Entry{
+		Action:    "mockup_uploaded",
+		Actor:     "api",
+		User:      m.UploadedBy,
+		Category:  "design",
+		RequestID: RequestIDFromContext(r.Context()),
+		Details: map[string]interface{}{
+			"name":  m.Name,
+			"bytes": m.Bytes,
+			"task":  m.Task,
+			"spec":  m.Spec,
+		},
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.broadcast(r.Context(), "mockups", "mockup_uploaded", m)
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// handleMockupFile serves GET /api/mockups/{name}, the image itself.
+func (s *Server) handleMockupFile(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/mockups/")
+	contentType := mockupContentType(name)
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") || contentType == "" {
+		respondError(w, http.StatusNotFound, "mockup not found")
+		return
+	}
+	data, err := os.ReadFile(s.missionPath("mockups", name))
+	if os.IsNotExist(err) {
+		respondError(w, http.StatusNotFound, "mockup not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
+
+// taskExists reports whether tasks.jsonl has a task with id.
+func (s *Server) taskExists(id string) bool {
+	tasks, _ := s.readTasks()
+	for _, t := range tasks {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
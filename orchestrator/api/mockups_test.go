@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func uploadMockup(t *testing.T, s *Server, filename string, content []byte, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", filename)
+	fw.Write(content)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	mw.Close()
+	req := httptest.NewRequest("POST", "/api/mockups", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	return w
+}
+
+func TestMockupUploadAndServe(t *testing.T) {
+	s, dir := newTestServer(t)
+	missionDir := filepath.Join(dir, ".mission")
+	os.WriteFile(filepath.Join(missionDir, "state", "tasks.jsonl"), []byte(`{"id":"t1","name":"login page","status":"pending"}`+"\n"), 0644)
+	img := pngBytes(t)
+
+	// The name comes from the file, the extension from the content
+	w := uploadMockup(t, s, "Login Screen.jpg", img, map[string]string{"task": "t1"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upload: %d: %s", w.Code, w.Body.String())
+	}
+	var m Mockup
+	json.Unmarshal(w.Body.Bytes(), &m)
+	if m.Name != "Login-Screen.png" || m.ContentType != "image/png" || m.Task != "t1" || m.URL != "/api/mockups/Login-Screen.png" {
+		t.Fatalf("mockup = %+v", m)
+	}
+
+	if w := uploadMockup(t, s, "Login Screen.png", img, nil); w.Code != http.StatusConflict {
+		t.Errorf("duplicate: %d", w.Code)
+	}
+	if w := uploadMockup(t, s, "Login Screen.png", img, map[string]string{"replace": "true"}); w.Code != http.StatusCreated {
+		t.Errorf("replace: %d: %s", w.Code, w.Body.String())
+	}
+	if w := uploadMockup(t, s, "page.png", []byte("<html><script>alert(1)</script></html>"), nil); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("html: %d", w.Code)
+	}
+	if w := uploadMockup(t, s, "x.png", img, map[string]string{"task": "nope"}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown task: %d", w.Code)
+	}
+	if w := uploadMockup(t, s, "big.png", make([]byte, MaxMockupBytes+1), nil); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too large: %d", w.Code)
+	}
+
+	// A mockup dropped in by hand is listed unlinked
+	os.WriteFile(filepath.Join(missionDir, "mockups", "flow.png"), img, 0644)
+	for query, want := range map[string]int{"": 2, "?task=t1": 1} {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/mockups"+query, nil))
+		var list struct {
+			Mockups []Mockup `json:"mockups"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &list)
+		if len(list.Mockups) != want {
+			t.Errorf("GET /api/mockups%s: %d mockups, want %d", query, len(list.Mockups), want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/mockups/Login-Screen.png", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || !bytes.Equal(w.Body.Bytes(), img) {
+		t.Errorf("serve: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	for _, path := range []string{"/api/mockups/index.json", "/api/mockups/..%2Fstate%2Ftasks.png", "/api/mockups/missing.png"} {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: %d", path, w.Code)
+		}
+	}
+}
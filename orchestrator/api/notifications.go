@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// NotificationsResponse is the response for GET /api/notifications.
+type NotificationsResponse struct {
+	Events []eventlog.Record `json:"events"`
+	Last   uint64            `json:"last_seq"`
+	Missed bool              `json:"missed"` // events after the requested position were evicted
+}
+
+// NotificationAckRequest is the request body for POST /api/notifications/ack.
+type NotificationAckRequest struct {
+	Consumer string `json:"consumer"`
+	Seq      uint64 `json:"seq"`
+}
+
+// SetEventLog serves log on /api/notifications, so clients that missed
+// WebSocket events can replay them.
+func (s *Server) SetEventLog(log *eventlog.Log) {
+	s.mu.Lock()
+	s.events = log
+	s.mu.Unlock()
+}
+
+func (s *Server) eventLog(w http.ResponseWriter) *eventlog.Log {
+	s.mu.RLock()
+	log := s.events
+	s.mu.RUnlock()
+	if log == nil {
+		problem.Write(w, http.StatusServiceUnavailable, problem.CodeUnavailable, "event log is not available; the file watcher is not running", nil)
+	}
+	return log
+}
+
+// handleNotifications serves GET /api/notifications. With ?consumer= it returns the
+// events that consumer has not acknowledged, otherwise those after
+// ?since= (default 0, everything kept).
+func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	log := s.eventLog(w)
+	if log == nil {
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	var recs []eventlog.Record
+	var missed bool
+	if consumer := q.Get("consumer"); consumer != "" {
+		recs, missed = log.Pending(consumer)
+	} else {
+		since, ok := seqParam(w, r, "since")
+		if !ok {
+			return
+		}
+		recs, missed = log.Since(since)
+	}
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+	if recs == nil {
+		recs = []eventlog.Record{}
+	}
+	writeJSON(w, http.StatusOK, NotificationsResponse{Events: recs, Last: log.Last(), Missed: missed})
+}
+
+// handleNotificationAck serves POST /api/notifications/ack.
+func (s *Server) handleNotificationAck(w http.ResponseWriter, r *http.Request) {
+	var req NotificationAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, err)
+		return
+	}
+	if req.Consumer == "" {
+		problem.Validation(w, "consumer is required")
+		return
+	}
+	log := s.eventLog(w)
+	if log == nil {
+		return
+	}
+	if err := log.Ack(req.Consumer, req.Seq); err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"consumer": req.Consumer,
+		"acked":    log.Acked(req.Consumer),
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
+)
+
+func TestNotificationsReplayAndAck(t *testing.T) {
+	s, _ := newTestServer(t)
+	handler := s.Routes()
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := send("GET", "/api/notifications", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without an event log, got %d", w.Code)
+	}
+
+	log := eventlog.New(0)
+	s.SetEventLog(log)
+	for _, typ := range []string{"task_created", "task_updated", "gate_approved"} {
+		log.Append(typ, map[string]string{"id": "t1"})
+	}
+
+	var resp NotificationsResponse
+	json.Unmarshal(send("GET", "/api/notifications?since=1", "").Body.Bytes(), &resp)
+	if len(resp.Events) != 2 || resp.Events[0].Type != "task_updated" || resp.Last != 3 || resp.Missed {
+		t.Errorf("Unexpected replay: %+v", resp)
+	}
+	if w := send("GET", "/api/notifications?since=abc", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad since, got %d", w.Code)
+	}
+
+	if w := send("POST", "/api/notifications/ack", `{"consumer": "ci", "seq": 2}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected ack to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(send("GET", "/api/notifications?consumer=ci", "").Body.Bytes(), &resp)
+	if len(resp.Events) != 1 || resp.Events[0].Type != "gate_approved" {
+		t.Errorf("Expected only the unacknowledged event, got %+v", resp.Events)
+	}
+	if w := send("POST", "/api/notifications/ack", `{"seq": 2}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a consumer, got %d", w.Code)
+	}
+	if w := send("POST", "/api/notifications/ack", `{"consumer": "ci", "seq": 99}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 acking past the log, got %d", w.Code)
+	}
+}
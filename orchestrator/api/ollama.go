@@ -1,52 +1,160 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/httpstream"
 	"github.com/MikeSquared-Agency/MissionControl/ollama"
+	"github.com/MikeSquared-Agency/MissionControl/openai"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
 )
 
 // OllamaHandler handles Ollama-related API endpoints
 type OllamaHandler struct {
-	client *ollama.Client
+	client     *ollama.Client // used unless the project sets ollamaURL
+	projectDir string         // project whose .mission/config.json picks the server and model
+	usage      UsageRecorder  // token ledger; nil = not recorded
+	hub        HubBroadcaster
+
+	pullsMu sync.Mutex
+	pulls   map[string]time.Time // model -> pull start, while pulling
+
+	clientsMu sync.Mutex
+	clients   map[string]*ollama.Client // by ollamaURL
 }
 
+// UsageRecorder is satisfied by tokens.Accumulator
+type UsageRecorder interface {
+	Record(workerID, persona string, model tokens.ModelTier, inputTokens, outputTokens int)
+}
+
+// ollamaChatWorker is the ledger entry for chats that name no worker.
+const ollamaChatWorker = "ollama-chat"
+
 // NewOllamaHandler creates a new OllamaHandler
 func NewOllamaHandler() *OllamaHandler {
 	return &OllamaHandler{
 		client: ollama.NewClient(""),
+		pulls:  make(map[string]time.Time),
+	}
+}
+
+// ollama returns the client for the project's ollamaURL, re-read on every
+// request so edits to config.json apply without a restart.
+func (h *OllamaHandler) ollama() *ollama.Client {
+	url := h.projectConfig().OllamaURL
+	if url == "" {
+		return h.client
+	}
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	if h.clients == nil {
+		h.clients = make(map[string]*ollama.Client)
+	}
+	c, ok := h.clients[url]
+	if !ok {
+		c = ollama.NewClient(url)
+		h.clients[url] = c
 	}
+	return c
+}
+
+// SetProject sets the project directory whose .mission/config.json
+// "ollamaURL" selects the server and "ollamaModel" the default model.
+func (h *OllamaHandler) SetProject(dir string) {
+	h.projectDir = dir
+}
+
+// SetUsage records the token counts of proxied chats in rec.
+func (h *OllamaHandler) SetUsage(rec UsageRecorder) {
+	h.usage = rec
+}
+
+// SetHub sets where model pull progress is broadcast.
+func (h *OllamaHandler) SetHub(hub HubBroadcaster) {
+	h.hub = hub
 }
 
 // OllamaStatusResponse is the response for GET /api/ollama/status
 type OllamaStatusResponse struct {
-	Running bool     `json:"running"`
-	Models  []string `json:"models,omitempty"`
+	Running   bool             `json:"running"`
+	URL       string           `json:"url"`
+	LatencyMs float64          `json:"latency_ms,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Models    []string         `json:"models,omitempty"`
+	Warm      *OllamaWarmProbe `json:"warm,omitempty"`
+}
+
+// OllamaWarmProbe reports loading the project's model for
+// GET /api/ollama/status?warm=true.
+type OllamaWarmProbe struct {
+	Model     string  `json:"model"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
 }
 
+// warmTimeout bounds a warm probe; loading a large model from disk can
+// take a while.
+const warmTimeout = 2 * time.Minute
+
 // RegisterRoutes adds Ollama routes to the given mux
 func (h *OllamaHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/ollama/status", h.handleStatus)
 	mux.HandleFunc("/api/ollama/models", h.handleModels)
+	mux.HandleFunc("/api/ollama/models/pull", h.handlePull)
+	mux.HandleFunc("/api/ollama/chat", h.handleChat)
 }
 
-// handleStatus handles GET /api/ollama/status
+// handleStatus handles GET /api/ollama/status. With ?warm=true and a
+// reachable server it also loads the project's model (or ?model=) and
+// reports how long that took.
 func (h *OllamaHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
-	response := OllamaStatusResponse{
-		Running: h.client.IsRunning(),
+	client := h.ollama()
+	response := OllamaStatusResponse{URL: client.BaseURL()}
+	latency, err := client.Ping()
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Running = true
+		response.LatencyMs = milliseconds(latency)
 	}
 
 	if response.Running {
-		names, err := h.client.GetModelNames()
+		names, err := client.GetModelNames()
 		if err == nil {
 			response.Models = names
 		}
+
+		q := r.URL.Query()
+		if q.Get("warm") == "true" || q.Get("warm") == "1" {
+			model := q.Get("model")
+			if model == "" {
+				model = h.projectConfig().OllamaModel
+			}
+			if model != "" {
+				ctx, cancel := context.WithTimeout(r.Context(), warmTimeout)
+				defer cancel()
+				probe := &OllamaWarmProbe{Model: model}
+				if d, err := client.Warm(ctx, model); err != nil {
+					probe.Error = err.Error()
+				} else {
+					probe.LatencyMs = milliseconds(d)
+				}
+				response.Warm = probe
+			}
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -56,16 +164,220 @@ func (h *OllamaHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
 // handleModels handles GET /api/ollama/models
 func (h *OllamaHandler) handleModels(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
-	models, err := h.client.ListModels()
+	models, err := h.ollama().ListModels()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		problem.Error(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(models)
 }
+
+// OllamaChatRequest is the request body for POST /api/ollama/chat. Model
+// defaults to the project's configured model; Stream defaults to true, as
+// in Ollama. WorkerID and Persona attribute the tokens in the usage ledger.
+type OllamaChatRequest struct {
+	Model    string                 `json:"model,omitempty"`
+	Messages []ollama.Message       `json:"messages"`
+	Stream   *bool                  `json:"stream,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+	WorkerID string                 `json:"worker_id,omitempty"`
+	Persona  string                 `json:"persona,omitempty"`
+}
+
+// chatFunc runs a chat on the project's provider, calling fn (if not nil)
+// with each chunk, and returns the final chunk.
+type chatFunc func(ctx context.Context, fn func(ollama.ChatResponse) error) (*ollama.ChatResponse, error)
+
+// handleChat handles POST /api/ollama/chat. Streaming replies are Ollama's
+// own newline-delimited JSON chunks, flushed as they arrive; an error after
+// the stream has started is sent as a final {"error": "..."} line. Projects
+// with provider "openai" are served by their OpenAI-compatible server, in
+// the same format.
+func (h *OllamaHandler) handleChat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		problem.MethodNotAllowed(w)
+		return
+	}
+
+	var req OllamaChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, err)
+		return
+	}
+	if len(req.Messages) == 0 {
+		problem.Validation(w, "messages is required")
+		return
+	}
+	cfg := h.projectConfig()
+	if req.Model == "" {
+		req.Model = cfg.Models.For(req.Persona).OfflineModel
+	}
+	if req.Model == "" {
+		req.Model = cfg.Model()
+	}
+	// The persona's generation settings, unless the request sets them
+	for k, v := range cfg.ChatOptions(req.Persona) {
+		if _, ok := req.Options[k]; !ok {
+			if req.Options == nil {
+				req.Options = map[string]interface{}{}
+			}
+			req.Options[k] = v
+		}
+	}
+	if req.Model == "" {
+		problem.Validation(w, "model is required: none given and no model configured in .mission/config.json")
+		return
+	}
+	stream := req.Stream == nil || *req.Stream
+
+	provider := cfg.ProviderName()
+	var chat chatFunc
+	switch provider {
+	case bridge.ProviderOpenAI:
+		if cfg.OpenAI == nil || cfg.OpenAI.BaseURL == "" {
+			problem.Validation(w, "provider openai needs openai.baseURL in .mission/config.json")
+			return
+		}
+		chat = openAIChat(openai.NewClient(cfg.OpenAI.BaseURL, cfg.OpenAI.Key(os.Getenv)), req)
+	default:
+		client := h.ollama()
+		chatReq := ollama.ChatRequest{
+			Model:    req.Model,
+			Messages: req.Messages,
+			Stream:   stream,
+			Options:  req.Options,
+		}
+		chat = func(ctx context.Context, fn func(ollama.ChatResponse) error) (*ollama.ChatResponse, error) {
+			return client.Chat(ctx, chatReq, fn)
+		}
+	}
+
+	if !stream {
+		final, err := chat(r.Context(), nil)
+		if err != nil {
+			problem.Write(w, http.StatusBadGateway, problem.CodeUpstream, err.Error(), nil)
+			return
+		}
+		h.recordUsage(provider, req, final)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(final)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Error(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	httpstream.Begin(w)
+
+	started := false
+	enc := json.NewEncoder(w)
+	final, err := chat(r.Context(), func(chunk ollama.ChatResponse) error {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := enc.Encode(chunk); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			problem.Write(w, http.StatusBadGateway, problem.CodeUpstream, err.Error(), nil)
+			return
+		}
+		_ = enc.Encode(map[string]string{"error": err.Error()})
+		flusher.Flush()
+		return
+	}
+	h.recordUsage(provider, req, final)
+}
+
+// openAIChat adapts an OpenAI-compatible chat to Ollama's chunks: one per
+// piece of the reply, then a done chunk with the whole reply and usage.
+func openAIChat(client *openai.Client, req OllamaChatRequest) chatFunc {
+	messages := make([]openai.Message, len(req.Messages))
+	for i, m := range req.Messages {
+		messages[i] = openai.Message{Role: m.Role, Content: m.Content}
+	}
+	return func(ctx context.Context, fn func(ollama.ChatResponse) error) (*ollama.ChatResponse, error) {
+		var onDelta func(string) error
+		if fn != nil {
+			onDelta = func(delta string) error {
+				return fn(ollama.ChatResponse{
+					Model:   req.Model,
+					Message: ollama.Message{Role: "assistant", Content: delta},
+				})
+			}
+		}
+		c, err := client.Chat(ctx, req.Model, messages, req.Options, onDelta)
+		if err != nil {
+			return nil, err
+		}
+		final := ollama.ChatResponse{
+			Model:           c.Model,
+			Message:         ollama.Message{Role: "assistant", Content: c.Content},
+			Done:            true,
+			DoneReason:      c.FinishReason,
+			PromptEvalCount: c.PromptTokens,
+			EvalCount:       c.CompletionTokens,
+		}
+		if fn != nil {
+			// The done chunk carries no text, as in Ollama's streams.
+			last := final
+			last.Message.Content = ""
+			if err := fn(last); err != nil {
+				return nil, err
+			}
+		}
+		return &final, nil
+	}
+}
+
+// projectConfig returns the project's offline-mode settings; an unset
+// project or unreadable config yields the defaults.
+func (h *OllamaHandler) projectConfig() *bridge.ProjectConfig {
+	if h.projectDir == "" {
+		return &bridge.ProjectConfig{}
+	}
+	cfg, err := bridge.LoadProjectConfig(h.projectDir)
+	if err != nil {
+		return &bridge.ProjectConfig{}
+	}
+	return cfg
+}
+
+// milliseconds converts d for JSON latency fields.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// recordUsage adds a finished chat's token counts to the usage ledger as
+// model "<provider>:<name>". Offline models have no price, so they cost
+// nothing but count against the token budget.
+func (h *OllamaHandler) recordUsage(provider string, req OllamaChatRequest, final *ollama.ChatResponse) {
+	if h.usage == nil || final == nil {
+		return
+	}
+	workerID := req.WorkerID
+	if workerID == "" {
+		workerID = ollamaChatWorker
+	}
+	model := final.Model
+	if model == "" {
+		model = req.Model
+	}
+	h.usage.Record(workerID, req.Persona, tokens.ModelTier(provider+":"+model), final.PromptEvalCount, final.EvalCount)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/ollama"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// Model pull events, broadcast on the "ollama" topic.
+const (
+	OllamaTopic = "ollama"
+
+	EventOllamaPullProgress = "ollama_pull_progress"
+)
+
+// pullProgressInterval throttles progress broadcasts: Ollama reports every
+// few kilobytes, far more often than a progress bar needs.
+const pullProgressInterval = 250 * time.Millisecond
+
+// OllamaPullRequest is the request body for POST /api/ollama/models/pull
+type OllamaPullRequest struct {
+	Model string `json:"model"`
+}
+
+// OllamaPullResponse acknowledges a pull; progress follows over WebSocket.
+type OllamaPullResponse struct {
+	Model          string `json:"model"`
+	Status         string `json:"status"` // "pulling"
+	DiskUsageBytes int64  `json:"disk_usage_bytes"`
+}
+
+// OllamaPullEvent is the payload of ollama_pull_progress. Status is
+// Ollama's ("pulling manifest", "pulling <digest>", "verifying sha256
+// digest", ...) until the last event, which is "success" with the model's
+// size and the new disk usage, or "error".
+type OllamaPullEvent struct {
+	Model          string  `json:"model"`
+	Status         string  `json:"status"`
+	Digest         string  `json:"digest,omitempty"`
+	Total          int64   `json:"total,omitempty"`
+	Completed      int64   `json:"completed,omitempty"`
+	Percent        float64 `json:"percent,omitempty"`
+	Done           bool    `json:"done,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	SizeBytes      int64   `json:"size_bytes,omitempty"`
+	DiskUsageBytes int64   `json:"disk_usage_bytes,omitempty"`
+	DurationMs     int64   `json:"duration_ms,omitempty"`
+}
+
+// handlePull handles POST /api/ollama/models/pull. The pull runs in the
+// background and reports over WebSocket; a second pull of the same model
+// while one is running is a conflict.
+func (h *OllamaHandler) handlePull(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		problem.MethodNotAllowed(w)
+		return
+	}
+
+	var req OllamaPullRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, err)
+		return
+	}
+	if req.Model == "" {
+		problem.Validation(w, "model is required")
+		return
+	}
+
+	client := h.ollama()
+	usage, err := client.DiskUsage()
+	if err != nil {
+		problem.Error(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	h.pullsMu.Lock()
+	if _, ok := h.pulls[req.Model]; ok {
+		h.pullsMu.Unlock()
+		problem.Error(w, http.StatusConflict, "model "+req.Model+" is already being pulled")
+		return
+	}
+	h.pulls[req.Model] = time.Now()
+	h.pullsMu.Unlock()
+
+	go h.pull(client, req.Model)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(OllamaPullResponse{
+		Model:          req.Model,
+		Status:         "pulling",
+		DiskUsageBytes: usage,
+	})
+}
+
+// pull downloads model, broadcasting throttled progress and a final
+// success or error event.
+func (h *OllamaHandler) pull(client *ollama.Client, model string) {
+	h.pullsMu.Lock()
+	started := h.pulls[model]
+	h.pullsMu.Unlock()
+	defer func() {
+		h.pullsMu.Lock()
+		delete(h.pulls, model)
+		h.pullsMu.Unlock()
+	}()
+
+	var last time.Time
+	var lastStatus string
+	err := client.Pull(context.Background(), model, func(p ollama.PullProgress) {
+		if p.Status == "success" {
+			return
+		}
+		// Always report a new phase; throttle byte counts within one.
+		if p.Status == lastStatus && time.Since(last) < pullProgressInterval {
+			return
+		}
+		last, lastStatus = time.Now(), p.Status
+
+		ev := OllamaPullEvent{
+			Model:     model,
+			Status:    p.Status,
+			Digest:    p.Digest,
+			Total:     p.Total,
+			Completed: p.Completed,
+		}
+		if p.Total > 0 {
+			ev.Percent = float64(p.Completed) * 100 / float64(p.Total)
+		}
+		h.broadcastPull(ev)
+	})
+
+	ev := OllamaPullEvent{
+		Model:      model,
+		Done:       true,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		ev.Status = "error"
+		ev.Error = err.Error()
+		h.broadcastPull(ev)
+		return
+	}
+	ev.Status = "success"
+	ev.Percent = 100
+	if models, err := client.ListModels(); err == nil {
+		for _, m := range models {
+			ev.DiskUsageBytes += m.Size
+			if m.Name == model || m.Name == model+":latest" {
+				ev.SizeBytes = m.Size
+			}
+		}
+	}
+	h.broadcastPull(ev)
+}
+
+func (h *OllamaHandler) broadcastPull(ev OllamaPullEvent) {
+	if h.hub != nil {
+		h.hub.BroadcastRaw(OllamaTopic, EventOllamaPullProgress, ev)
+	}
+}
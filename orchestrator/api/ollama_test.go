@@ -0,0 +1,337 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/ollama"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// fakeOllama answers /api/chat with two chunks and a done line, echoing
+// the requested model and stream flag.
+func fakeOllama(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":"Hello"},"done":true,"prompt_eval_count":10,"eval_count":2}`+"\n", req.Model)
+			return
+		}
+		fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":"Hel"},"done":false}`+"\n", req.Model)
+		fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":"lo"},"done":false}`+"\n", req.Model)
+		fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":10,"eval_count":2}`+"\n", req.Model)
+	}))
+}
+
+func newTestOllamaHandler(t *testing.T, baseURL string) (*OllamaHandler, *tokens.Accumulator, *http.ServeMux) {
+	t.Helper()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"mode":"offline","ollamaModel":"qwen3-coder"}`), 0644)
+
+	acc := tokens.NewAccumulator(0, nil)
+	h := &OllamaHandler{client: ollama.NewClient(baseURL)}
+	h.SetProject(dir)
+	h.SetUsage(acc)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, acc, mux
+}
+
+func TestOllamaChatStreaming(t *testing.T) {
+	upstream := fakeOllama(t)
+	defer upstream.Close()
+	_, acc, mux := newTestOllamaHandler(t, upstream.URL)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/ollama/chat",
+		strings.NewReader(`{"messages":[{"role":"user","content":"hi"}],"worker_id":"w1","persona":"developer"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected application/x-ndjson, got %q", ct)
+	}
+
+	var lines []ollama.ChatResponse
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var chunk ollama.ChatResponse
+		if err := json.Unmarshal(sc.Bytes(), &chunk); err != nil {
+			t.Fatalf("bad line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, chunk)
+	}
+	if len(lines) != 3 || lines[0].Model != "qwen3-coder" || !lines[2].Done {
+		t.Errorf("unexpected chunks %+v", lines)
+	}
+
+	sess, ok := acc.GetSession("w1")
+	if !ok {
+		t.Fatal("expected usage recorded for w1")
+	}
+	if sess.InputTokens != 10 || sess.OutputTokens != 2 || sess.Persona != "developer" || sess.Model != "ollama:qwen3-coder" {
+		t.Errorf("unexpected session %+v", sess)
+	}
+	if sess.EstimatedCost != 0 {
+		t.Errorf("local model should cost nothing, got %v", sess.EstimatedCost)
+	}
+}
+
+func TestOllamaChatNonStreaming(t *testing.T) {
+	upstream := fakeOllama(t)
+	defer upstream.Close()
+	_, acc, mux := newTestOllamaHandler(t, upstream.URL)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/ollama/chat",
+		strings.NewReader(`{"model":"llama3.1:8b","stream":false,"messages":[{"role":"user","content":"hi"}]}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var resp ollama.ChatResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Model != "llama3.1:8b" || resp.Message.Content != "Hello" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if _, ok := acc.GetSession(ollamaChatWorker); !ok {
+		t.Error("expected usage recorded under the default worker")
+	}
+}
+
+func TestOllamaChatPersonaSettings(t *testing.T) {
+	var got ollama.ChatRequest
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprintf(w, `{"model":%q,"message":{"role":"assistant","content":"ok"},"done":true}`+"\n", got.Model)
+	}))
+	defer upstream.Close()
+	h, _, mux := newTestOllamaHandler(t, upstream.URL)
+	os.WriteFile(filepath.Join(h.projectDir, ".mission", "config.json"), []byte(`{"mode":"offline","ollamaModel":"qwen3-coder",
+		"models":{"default":{"maxTokens":2048},"personas":{"researcher":{"offlineModel":"llama3.1:8b","temperature":0.7}}}}`), 0644)
+
+	chat := func(body string) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ollama/chat", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	chat(`{"stream":false,"persona":"researcher","messages":[{"role":"user","content":"hi"}]}`)
+	if got.Model != "llama3.1:8b" || got.Options["temperature"] != 0.7 || got.Options["num_predict"] != float64(2048) {
+		t.Errorf("researcher request = %+v", got)
+	}
+
+	// The request's own model and options win
+	chat(`{"stream":false,"persona":"researcher","model":"mistral","options":{"temperature":0},"messages":[{"role":"user","content":"hi"}]}`)
+	if got.Model != "mistral" || got.Options["temperature"] != float64(0) {
+		t.Errorf("explicit request = %+v", got)
+	}
+}
+
+func TestOllamaChatErrors(t *testing.T) {
+	t.Run("no model configured", func(t *testing.T) {
+		h := &OllamaHandler{client: ollama.NewClient("http://localhost:99999")}
+		req := httptest.NewRequest(http.MethodPost, "/api/ollama/chat",
+			strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		w := httptest.NewRecorder()
+		h.handleChat(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("Ollama unreachable", func(t *testing.T) {
+		_, _, mux := newTestOllamaHandler(t, "http://localhost:99999")
+		req := httptest.NewRequest(http.MethodPost, "/api/ollama/chat",
+			strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("expected 502, got %d", w.Code)
+		}
+	})
+}
+
+// pullHub collects ollama_pull_progress events.
+type pullHub struct {
+	events chan OllamaPullEvent
+}
+
+func (h *pullHub) BroadcastRaw(topic, eventType string, data interface{}) {
+	if topic == OllamaTopic && eventType == EventOllamaPullProgress {
+		h.events <- data.(OllamaPullEvent)
+	}
+}
+
+func TestOllamaPull(t *testing.T) {
+	release := make(chan struct{})
+	pulled := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			models := `{"name":"llama3.1:8b","size":4000}`
+			if pulled {
+				models += `,{"name":"qwen3-coder:latest","size":1000}`
+			}
+			fmt.Fprintf(w, `{"models":[%s]}`, models)
+		case "/api/pull":
+			fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+			w.(http.Flusher).Flush()
+			<-release
+			fmt.Fprintln(w, `{"status":"pulling abc","digest":"sha256:abc","total":1000,"completed":500}`)
+			pulled = true
+			fmt.Fprintln(w, `{"status":"success"}`)
+		}
+	}))
+	defer upstream.Close()
+
+	hub := &pullHub{events: make(chan OllamaPullEvent, 16)}
+	h := NewOllamaHandler()
+	h.client = ollama.NewClient(upstream.URL)
+	h.SetHub(hub)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ollama/models/pull", strings.NewReader(`{"model":"qwen3-coder"}`)))
+		return w
+	}
+
+	w := post()
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp OllamaPullResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.DiskUsageBytes != 4000 || resp.Status != "pulling" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	if ev := <-hub.events; ev.Status != "pulling manifest" {
+		t.Errorf("first event = %+v", ev)
+	}
+	if w := post(); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate pull, got %d", w.Code)
+	}
+	close(release)
+
+	if ev := <-hub.events; ev.Percent != 50 || ev.Digest != "sha256:abc" {
+		t.Errorf("progress event = %+v", ev)
+	}
+	ev := <-hub.events
+	if ev.Status != "success" || !ev.Done || ev.SizeBytes != 1000 || ev.DiskUsageBytes != 5000 {
+		t.Errorf("final event = %+v", ev)
+	}
+}
+
+func TestOllamaPullValidation(t *testing.T) {
+	h := NewOllamaHandler()
+	w := httptest.NewRecorder()
+	h.handlePull(w, httptest.NewRequest(http.MethodPost, "/api/ollama/models/pull", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestOllamaStatusUsesProjectURL(t *testing.T) {
+	var warmed string
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, "Ollama is running")
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"qwen3-coder:latest","size":1000}]}`)
+		case "/api/generate":
+			var req struct{ Model string }
+			json.NewDecoder(r.Body).Decode(&req)
+			warmed = req.Model
+			fmt.Fprint(w, `{"done":true}`)
+		}
+	}))
+	defer remote.Close()
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"),
+		[]byte(`{"mode":"offline","ollamaModel":"qwen3-coder","ollamaURL":"`+remote.URL+`"}`), 0644)
+
+	// The default client points nowhere; the project's URL must win.
+	h := &OllamaHandler{client: ollama.NewClient("http://localhost:99999")}
+	h.SetProject(dir)
+
+	w := httptest.NewRecorder()
+	h.handleStatus(w, httptest.NewRequest(http.MethodGet, "/api/ollama/status?warm=true", nil))
+
+	var resp OllamaStatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Running || resp.URL != remote.URL || resp.LatencyMs <= 0 || len(resp.Models) != 1 {
+		t.Errorf("unexpected status %+v", resp)
+	}
+	if resp.Warm == nil || resp.Warm.Model != "qwen3-coder" || resp.Warm.Error != "" || warmed != "qwen3-coder" {
+		t.Errorf("unexpected warm probe %+v (warmed %q)", resp.Warm, warmed)
+	}
+}
+
+func TestOllamaStatusUnreachable(t *testing.T) {
+	h := &OllamaHandler{client: ollama.NewClient("http://localhost:99999")}
+	w := httptest.NewRecorder()
+	h.handleStatus(w, httptest.NewRequest(http.MethodGet, "/api/ollama/status?warm=true", nil))
+
+	var resp OllamaStatusResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Running || resp.Error == "" || resp.Warm != nil {
+		t.Errorf("unexpected status %+v", resp)
+	}
+}
+
+func TestOllamaChatOpenAIProvider(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-local" {
+			t.Errorf("missing API key")
+		}
+		fmt.Fprint(w, "data: {\"model\":\"qwen-32b\",\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":7,\"completion_tokens\":1}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer upstream.Close()
+
+	t.Setenv("LOCAL_KEY", "sk-local")
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"mode":"offline","provider":"openai",
+		"openai":{"baseURL":"`+upstream.URL+`/v1","apiKeyEnv":"LOCAL_KEY","model":"qwen-32b"}}`), 0644)
+
+	acc := tokens.NewAccumulator(0, nil)
+	h := &OllamaHandler{client: ollama.NewClient("http://localhost:99999")}
+	h.SetProject(dir)
+	h.SetUsage(acc)
+
+	w := httptest.NewRecorder()
+	h.handleChat(w, httptest.NewRequest(http.MethodPost, "/api/ollama/chat",
+		strings.NewReader(`{"messages":[{"role":"user","content":"hi"}],"worker_id":"w2"}`)))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"content":"Hi"`) || !strings.Contains(lines[1], `"done":true`) {
+		t.Errorf("unexpected stream %q", w.Body.String())
+	}
+	sess, ok := acc.GetSession("w2")
+	if !ok || sess.Model != "openai:qwen-32b" || sess.InputTokens != 7 || sess.OutputTokens != 1 {
+		t.Errorf("unexpected usage %+v", sess)
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// pageParams are the list-endpoint query parameters:
+//
+//	limit   page size (default 100, max 1000)
+//	cursor  opaque token from a previous response's next_cursor
+//	sort    field to order by; a leading "-" sorts descending
+//
+// Paged is false when none were supplied, in which case list endpoints keep
+// returning a bare array for older clients.
+type pageParams struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+	Paged  bool
+}
+
+func parsePage(q url.Values) (pageParams, error) {
+	p := pageParams{Limit: defaultPageLimit}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("invalid limit %q", v)
+		}
+		if n > maxPageLimit {
+			n = maxPageLimit
+		}
+		p.Limit = n
+		p.Paged = true
+	}
+	if v := q.Get("cursor"); v != "" {
+		off, err := decodeCursor(v)
+		if err != nil {
+			return p, err
+		}
+		p.Offset = off
+		p.Paged = true
+	}
+	if v := q.Get("sort"); v != "" {
+		p.Desc = strings.HasPrefix(v, "-")
+		p.Sort = strings.TrimPrefix(v, "-")
+		p.Paged = true
+	}
+	return p, nil
+}
+
+// encodeCursor returns the opaque cursor for the item at offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(c string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err == nil && strings.HasPrefix(string(raw), "o:") {
+		if n, err := strconv.Atoi(string(raw[2:])); err == nil && n >= 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", c)
+}
+
+// nextCursor returns the cursor following a page, or "" on the last page.
+func (p pageParams) nextCursor(returned, total int) string {
+	if p.Offset+returned >= total || returned == 0 {
+		return ""
+	}
+	return encodeCursor(p.Offset + returned)
+}
+
+// sortRecords orders records by p.Sort, breaking ties on "id" so pages are
+// deterministic. Numbers compare numerically, everything else as strings;
+// records missing the field sort last. Without p.Sort the input order is kept.
+func sortRecords(recs []map[string]interface{}, p pageParams) {
+	if p.Sort == "" {
+		if p.Desc {
+			for i, j := 0, len(recs)-1; i < j; i, j = i+1, j-1 {
+				recs[i], recs[j] = recs[j], recs[i]
+			}
+		}
+		return
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		c := compareField(recs[i][p.Sort], recs[j][p.Sort])
+		if c == 0 {
+			c = compareField(recs[i]["id"], recs[j]["id"])
+		}
+		if p.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+func compareField(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// pageRecords returns the slice of recs for p.
+func pageRecords(recs []map[string]interface{}, p pageParams) []map[string]interface{} {
+	if p.Offset >= len(recs) {
+		return []map[string]interface{}{}
+	}
+	end := p.Offset + p.Limit
+	if end > len(recs) {
+		end = len(recs)
+	}
+	return recs[p.Offset:end]
+}
+
+// writeList sends recs, sorted and paged per p. Unpaged requests get the
+// bare array; paged requests get {key: [...], total, limit, next_cursor}.
+func writeList(w http.ResponseWriter, key string, recs []map[string]interface{}, p pageParams) {
+	if recs == nil {
+		recs = []map[string]interface{}{}
+	}
+	sortRecords(recs, p)
+	if !p.Paged {
+		writeJSON(w, http.StatusOK, recs)
+		return
+	}
+	page := pageRecords(recs, p)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		key:           page,
+		"total":       len(recs),
+		"limit":       p.Limit,
+		"next_cursor": p.nextCursor(len(page), len(recs)),
+	})
+}
@@ -4,11 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+	"github.com/MikeSquared-Agency/MissionControl/workspace"
 )
 
 // GlobalConfig represents ~/.mission-control/config.json
@@ -32,16 +40,54 @@ type Preferences struct {
 	Theme string `json:"theme"`
 }
 
+// Project lifecycle events, broadcast on the "project" topic so every
+// connected dashboard sees changes to the project set.
+const (
+	ProjectTopic = "project"
+
+	EventProjectCreated  = "project_created"
+	EventProjectImported = "project_imported"
+	EventProjectDeleted  = "project_deleted"
+	EventProjectSwitched = "project_switched"
+)
+
+// ProjectEvent is the payload of project_* events: the project concerned
+// and the resulting project set, so clients can replace their list.
+type ProjectEvent struct {
+	Project  *Project  `json:"project,omitempty"`
+	Path     string    `json:"path"`
+	Active   string    `json:"active"`
+	Projects []Project `json:"projects"`
+}
+
+func newProjectEvent(path string, project *Project, config *GlobalConfig) ProjectEvent {
+	return ProjectEvent{
+		Project:  project,
+		Path:     path,
+		Active:   config.LastProject,
+		Projects: config.Projects,
+	}
+}
+
 // ProjectsHandler handles project-related endpoints
 type ProjectsHandler struct {
 	configPath string
 	mcPath     string
+	hub        HubBroadcaster
+}
+
+// globalConfigMu serialises read-modify-write cycles on config.json.
+var globalConfigMu sync.Mutex
+
+// globalConfigPath is ~/.mission-control/config.json.
+func globalConfigPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".mission-control", "config.json")
 }
 
 // NewProjectsHandler creates a new projects handler
 func NewProjectsHandler() *ProjectsHandler {
-	home, _ := os.UserHomeDir()
-	configPath := filepath.Join(home, ".mission-control", "config.json")
+	configPath := globalConfigPath()
 
 	// Find mc binary
 	mcPath := "mc"
@@ -76,6 +122,17 @@ func NewProjectsHandler() *ProjectsHandler {
 	}
 }
 
+// SetHub sets where project lifecycle events are broadcast.
+func (h *ProjectsHandler) SetHub(hub HubBroadcaster) {
+	h.hub = hub
+}
+
+func (h *ProjectsHandler) broadcast(eventType string, ev ProjectEvent) {
+	if h.hub != nil {
+		h.hub.BroadcastRaw(ProjectTopic, eventType, ev)
+	}
+}
+
 // RegisterRoutes registers project API routes
 func (h *ProjectsHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/projects", h.handleProjects)
@@ -91,7 +148,7 @@ func (h *ProjectsHandler) handleProjects(w http.ResponseWriter, r *http.Request)
 	case http.MethodPost:
 		h.createProject(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 	}
 }
 
@@ -115,19 +172,19 @@ func (h *ProjectsHandler) handleProject(w http.ResponseWriter, r *http.Request)
 	case http.MethodDelete:
 		h.deleteProject(w, r, urlPath)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 	}
 }
 
 func (h *ProjectsHandler) handleCheckPath(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
 	path := r.URL.Query().Get("path")
 	if path == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "path parameter required"})
+		respondError(w, http.StatusBadRequest, "path parameter required")
 		return
 	}
 
@@ -180,6 +237,16 @@ type CreateProjectRequest struct {
 	Matrix      []MatrixCell `json:"matrix"`
 	Mode        string       `json:"mode"`        // "online" or "offline"
 	OllamaModel string       `json:"ollamaModel"` // For offline mode, e.g., "qwen3-coder"
+	OllamaURL   string       `json:"ollamaURL"`   // For offline mode against a remote Ollama server
+	Analyze     bool         `json:"analyze"`     // Inspect the repository and seed zones, matrix and spec
+	Template    string       `json:"template"`    // mc init --template, e.g. "webapp"
+}
+
+// CreateProjectResponse is the created project plus, when requested, the
+// workspace analysis that seeded it.
+type CreateProjectResponse struct {
+	Project
+	Analysis *workspace.Analysis `json:"analysis,omitempty"`
 }
 
 // MatrixCell represents a cell in the workflow matrix
@@ -205,6 +272,13 @@ type ProjectConfig struct {
 	Personas    map[string]PersonaConfig `json:"personas,omitempty"`
 	Mode        string                   `json:"mode,omitempty"`        // "online" or "offline"
 	OllamaModel string                   `json:"ollamaModel,omitempty"` // For offline mode
+	OllamaURL   string                   `json:"ollamaURL,omitempty"`   // Remote Ollama server; default localhost
+	Provider    string                   `json:"provider,omitempty"`    // Offline backend: "ollama" or "openai"
+	OpenAI      *bridge.OpenAIConfig     `json:"openai,omitempty"`      // OpenAI-compatible server for provider "openai"
+	Runtimes    *bridge.RuntimeConfig    `json:"runtimes,omitempty"`    // Worker CLI per persona/zone
+	Limits      *bridge.LimitsConfig     `json:"limits,omitempty"`      // Concurrent workers, total and per zone
+	Webhooks    []webhook.Hook           `json:"webhooks,omitempty"`    // Event notifications
+	Roles       identity.Roles           `json:"roles,omitempty"`       // API access per user
 }
 
 // PersonaResponse represents persona data returned by API
@@ -222,12 +296,12 @@ type UpdatePersonaRequest struct {
 func (h *ProjectsHandler) createProject(w http.ResponseWriter, r *http.Request) {
 	var req CreateProjectRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if req.Path == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "path is required"})
+		problem.Validation(w, "path is required")
 		return
 	}
 
@@ -243,25 +317,39 @@ func (h *ProjectsHandler) createProject(w http.ResponseWriter, r *http.Request)
 		// Validate .mission directory exists
 		missionDir := filepath.Join(path, ".mission")
 		if _, err := os.Stat(missionDir); os.IsNotExist(err) {
-			writeJSON(w, http.StatusBadRequest, map[string]string{
-				"error": "No .mission directory found. Cannot import - use create instead.",
-			})
+			respondError(w, http.StatusBadRequest, "No .mission directory found. Cannot import - use create instead.")
 			return
 		}
 		// Note: config.json validation is optional - project may still work without it
-	} else {
+	}
+
+	// Analyze before mc init so the proposal reflects the repository only.
+	// For imports the analysis is returned but nothing is rewritten.
+	var analysis *workspace.Analysis
+	if req.Analyze {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create directory: %v", err))
+			return
+		}
+		a, err := workspace.Analyze(path)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to analyze project: %v", err))
+			return
+		}
+		analysis = a
+	}
+
+	if !req.Import {
 		// Create directory if it doesn't exist
 		if err := os.MkdirAll(path, 0755); err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{
-				"error": fmt.Sprintf("Failed to create directory: %v", err),
-			})
+			respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create directory: %v", err))
 			return
 		}
 
 		// Write matrix config to temp file
 		configFile, err := os.CreateTemp("", "mc-config-*.json")
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to create config file"})
+			respondError(w, http.StatusInternalServerError, "Failed to create config file")
 			return
 		}
 		defer os.Remove(configFile.Name())
@@ -282,21 +370,28 @@ func (h *ProjectsHandler) createProject(w http.ResponseWriter, r *http.Request)
 		} else {
 			args = append(args, "--king=false")
 		}
-		args = append(args, "--config", configFile.Name())
+		if req.Analyze {
+			args = append(args, "--analyze")
+		}
+		if req.Template != "" {
+			args = append(args, "--template", req.Template)
+		}
+		// An explicit matrix wins over the analyzed or template one
+		if len(req.Matrix) > 0 || (!req.Analyze && req.Template == "") {
+			args = append(args, "--config", configFile.Name())
+		}
 
 		// Execute mc init
 		cmd := exec.Command(h.mcPath, args...)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{
-				"error":  fmt.Sprintf("mc init failed: %v", err),
-				"output": string(output),
-			})
+			problem.Write(w, http.StatusInternalServerError, problem.CodeCommandFailed,
+				fmt.Sprintf("mc init failed: %v", err), map[string]interface{}{"output": string(output)})
 			return
 		}
 
-		// Update project config with mode and ollamaModel if specified
-		if req.Mode != "" || req.OllamaModel != "" {
+		// Update project config with mode, ollamaModel and ollamaURL if specified
+		if req.Mode != "" || req.OllamaModel != "" || req.OllamaURL != "" {
 			projectConfig, err := h.loadProjectConfig(path)
 			if err == nil && projectConfig != nil {
 				if req.Mode != "" {
@@ -305,6 +400,9 @@ func (h *ProjectsHandler) createProject(w http.ResponseWriter, r *http.Request)
 				if req.OllamaModel != "" {
 					projectConfig.OllamaModel = req.OllamaModel
 				}
+				if req.OllamaURL != "" {
+					projectConfig.OllamaURL = req.OllamaURL
+				}
 				_ = h.saveProjectConfig(path, projectConfig)
 			}
 		}
@@ -323,74 +421,83 @@ func (h *ProjectsHandler) createProject(w http.ResponseWriter, r *http.Request)
 		OllamaModel: req.OllamaModel,
 	}
 
-	config, _ := h.loadConfig()
-	if config == nil {
-		config = &GlobalConfig{
-			Projects:    []Project{},
-			Preferences: Preferences{Theme: "dark"},
-		}
-	}
-
-	// Remove duplicate if exists
-	filtered := []Project{}
-	for _, p := range config.Projects {
-		if p.Path != path {
-			filtered = append(filtered, p)
+	config, err := updateGlobalConfig(h.configPath, func(config *GlobalConfig) error {
+		// Remove duplicate if exists
+		filtered := []Project{}
+		for _, p := range config.Projects {
+			if p.Path != path {
+				filtered = append(filtered, p)
+			}
 		}
+		config.Projects = append(filtered, project)
+		config.LastProject = path
+		return nil
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save config")
+		return
 	}
-	config.Projects = append(filtered, project)
-	config.LastProject = path
 
-	if err := h.saveConfig(config); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to save config"})
-		return
+	eventType := EventProjectCreated
+	if req.Import {
+		eventType = EventProjectImported
 	}
+	h.broadcast(eventType, newProjectEvent(path, &project, config))
 
-	writeJSON(w, http.StatusCreated, project)
+	writeJSON(w, http.StatusCreated, CreateProjectResponse{Project: project, Analysis: analysis})
 }
 
 func (h *ProjectsHandler) deleteProject(w http.ResponseWriter, r *http.Request, path string) {
-	config, err := h.loadConfig()
-	if err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Config not found"})
+	if _, err := os.Stat(h.configPath); err != nil {
+		respondError(w, http.StatusNotFound, "Config not found")
 		return
 	}
 
-	// Remove from list (don't delete from disk)
-	filtered := []Project{}
-	found := false
-	for _, p := range config.Projects {
-		if p.Path != path {
-			filtered = append(filtered, p)
-		} else {
-			found = true
+	errNotFound := fmt.Errorf("project not found")
+	config, err := updateGlobalConfig(h.configPath, func(config *GlobalConfig) error {
+		// Remove from list (don't delete from disk)
+		filtered := []Project{}
+		found := false
+		for _, p := range config.Projects {
+			if p.Path != path {
+				filtered = append(filtered, p)
+			} else {
+				found = true
+			}
+		}
+		if !found {
+			return errNotFound
 		}
-	}
-
-	if !found {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Project not found"})
-		return
-	}
 
-	config.Projects = filtered
-	if config.LastProject == path {
-		if len(filtered) > 0 {
-			config.LastProject = filtered[0].Path
-		} else {
-			config.LastProject = ""
+		config.Projects = filtered
+		if config.LastProject == path {
+			if len(filtered) > 0 {
+				config.LastProject = filtered[0].Path
+			} else {
+				config.LastProject = ""
+			}
 		}
+		return nil
+	})
+	if err == errNotFound {
+		respondError(w, http.StatusNotFound, "Project not found")
+		return
 	}
-
-	if err := h.saveConfig(config); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to save config"})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save config")
 		return
 	}
 
+	h.broadcast(EventProjectDeleted, newProjectEvent(path, nil, config))
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *ProjectsHandler) loadConfig() (*GlobalConfig, error) {
-	data, err := os.ReadFile(h.configPath)
+	return loadGlobalConfig(h.configPath)
+}
+
+func loadGlobalConfig(path string) (*GlobalConfig, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -402,9 +509,11 @@ func (h *ProjectsHandler) loadConfig() (*GlobalConfig, error) {
 	return &config, nil
 }
 
-func (h *ProjectsHandler) saveConfig(config *GlobalConfig) error {
+// saveGlobalConfig writes config via a temp file and rename, so readers
+// never see a partial file. Callers hold globalConfigMu.
+func saveGlobalConfig(path string, config *GlobalConfig) error {
 	// Ensure directory exists
-	dir := filepath.Dir(h.configPath)
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
@@ -413,7 +522,53 @@ func (h *ProjectsHandler) saveConfig(config *GlobalConfig) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(h.configPath, data, 0644)
+
+	tmp, err := os.CreateTemp(dir, ".config-*.json")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// updateGlobalConfig applies fn to config.json under globalConfigMu and
+// saves the result. A missing file starts from an empty config; if fn
+// returns an error nothing is written.
+func updateGlobalConfig(path string, fn func(*GlobalConfig) error) (*GlobalConfig, error) {
+	globalConfigMu.Lock()
+	defer globalConfigMu.Unlock()
+
+	config, err := loadGlobalConfig(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if config == nil {
+		config = &GlobalConfig{
+			Projects:    []Project{},
+			Preferences: Preferences{Theme: "dark"},
+		}
+	}
+	if err := fn(config); err != nil {
+		return nil, err
+	}
+	if err := saveGlobalConfig(path, config); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
 // The 11 builtin persona IDs
@@ -433,7 +588,12 @@ func (h *ProjectsHandler) handlePersonas(w http.ResponseWriter, r *http.Request,
 	// Check project exists
 	missionDir := filepath.Join(projectPath, ".mission")
 	if _, err := os.Stat(missionDir); os.IsNotExist(err) {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Project not found or not initialized"})
+		respondError(w, http.StatusNotFound, "Project not found or not initialized")
+		return
+	}
+
+	// Enabling personas and editing their prompts is for admins
+	if r.Method != http.MethodGet && !allowRole(w, r, projectPath, identity.RoleAdmin) {
 		return
 	}
 
@@ -449,7 +609,7 @@ func (h *ProjectsHandler) handlePersonas(w http.ResponseWriter, r *http.Request,
 		if r.Method == http.MethodGet {
 			h.listPersonas(w, r, projectPath)
 		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 		}
 		return
 	}
@@ -463,7 +623,7 @@ func (h *ProjectsHandler) handlePersonas(w http.ResponseWriter, r *http.Request,
 		case http.MethodPut:
 			h.updatePersona(w, r, projectPath, personaID)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 		}
 		return
 	}
@@ -476,12 +636,12 @@ func (h *ProjectsHandler) handlePersonas(w http.ResponseWriter, r *http.Request,
 		case http.MethodPut:
 			h.updatePersonaPrompt(w, r, projectPath, personaID)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 		}
 		return
 	}
 
-	http.Error(w, "Not found", http.StatusNotFound)
+	problem.NotFound(w, "Not found")
 }
 
 // listPersonas returns all persona configurations for a project
@@ -548,7 +708,7 @@ func (h *ProjectsHandler) getPersona(w http.ResponseWriter, r *http.Request, pro
 func (h *ProjectsHandler) updatePersona(w http.ResponseWriter, r *http.Request, projectPath, personaID string) {
 	var req UpdatePersonaRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
@@ -572,7 +732,7 @@ func (h *ProjectsHandler) updatePersona(w http.ResponseWriter, r *http.Request,
 	}
 
 	if err := h.saveProjectConfig(projectPath, config); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to save config"})
+		respondError(w, http.StatusInternalServerError, "Failed to save config")
 		return
 	}
 
@@ -587,9 +747,9 @@ func (h *ProjectsHandler) getPersonaPrompt(w http.ResponseWriter, r *http.Reques
 	content, err := os.ReadFile(promptPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Prompt not found"})
+			respondError(w, http.StatusNotFound, "Prompt not found")
 		} else {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to read prompt"})
+			respondError(w, http.StatusInternalServerError, "Failed to read prompt")
 		}
 		return
 	}
@@ -606,7 +766,7 @@ func (h *ProjectsHandler) updatePersonaPrompt(w http.ResponseWriter, r *http.Req
 		Content string `json:"content"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
@@ -615,12 +775,12 @@ func (h *ProjectsHandler) updatePersonaPrompt(w http.ResponseWriter, r *http.Req
 	// Ensure prompts directory exists
 	promptsDir := filepath.Dir(promptPath)
 	if err := os.MkdirAll(promptsDir, 0755); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to create prompts directory"})
+		respondError(w, http.StatusInternalServerError, "Failed to create prompts directory")
 		return
 	}
 
 	if err := os.WriteFile(promptPath, []byte(req.Content), 0644); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to write prompt"})
+		respondError(w, http.StatusInternalServerError, "Failed to write prompt")
 		return
 	}
 
@@ -671,7 +831,7 @@ type BrowseResponse struct {
 // handleBrowse handles directory browsing requests
 func (h *ProjectsHandler) handleBrowse(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
@@ -681,7 +841,7 @@ func (h *ProjectsHandler) handleBrowse(w http.ResponseWriter, r *http.Request) {
 	if path == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get home directory"})
+			respondError(w, http.StatusInternalServerError, "Failed to get home directory")
 			return
 		}
 		path = home
@@ -700,22 +860,22 @@ func (h *ProjectsHandler) handleBrowse(w http.ResponseWriter, r *http.Request) {
 	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Path does not exist"})
+			respondError(w, http.StatusNotFound, "Path does not exist")
 		} else {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			respondError(w, http.StatusInternalServerError, err.Error())
 		}
 		return
 	}
 
 	if !info.IsDir() {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Path is not a directory"})
+		respondError(w, http.StatusBadRequest, "Path is not a directory")
 		return
 	}
 
 	// Read directory contents
 	dirEntries, err := os.ReadDir(path)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to read directory"})
+		respondError(w, http.StatusInternalServerError, "Failed to read directory")
 		return
 	}
 
@@ -740,3 +900,46 @@ func (h *ProjectsHandler) handleBrowse(w http.ResponseWriter, r *http.Request) {
 		Entries: entries,
 	})
 }
+
+// ProjectRegistryPath is the registry maintained by `mc project register`.
+func ProjectRegistryPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".mc", "projects.json")
+}
+
+// LoadProjectRegistry returns the registered projects as name → path. The
+// paths are as recorded, usually the project's .mission directory; use
+// ProjectDir to normalise. A missing registry yields an empty map.
+func LoadProjectRegistry() (map[string]string, error) {
+	var registry struct {
+		Projects map[string]string `json:"projects"`
+	}
+	if err := readJSON(ProjectRegistryPath(), &registry); err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	if registry.Projects == nil {
+		registry.Projects = map[string]string{}
+	}
+	return registry.Projects, nil
+}
+
+// ProjectDir normalises a registered path to the project directory (the
+// parent of .mission), cleaned and absolute.
+func ProjectDir(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.Clean(path)
+	if filepath.Base(path) == ".mission" {
+		path = filepath.Dir(path)
+	}
+	return path
+}
+
+// ProjectAPIPrefix is the URL prefix serving a registered project's API.
+func ProjectAPIPrefix(id string) string {
+	return "/api/p/" + url.PathEscape(id)
+}
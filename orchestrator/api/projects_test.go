@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type projectHub struct {
+	events []string
+	last   ProjectEvent
+}
+
+func (h *projectHub) BroadcastRaw(topic, eventType string, data interface{}) {
+	if topic != ProjectTopic {
+		return
+	}
+	h.events = append(h.events, eventType)
+	h.last = data.(ProjectEvent)
+}
+
+func TestProjectLifecycleEvents(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	hub := &projectHub{}
+	h := &ProjectsHandler{configPath: configPath}
+	h.SetHub(hub)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, ".mission"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"path":` + jsonString(projectDir) + `,"import":true}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/projects", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("import: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(hub.events) != 1 || hub.events[0] != EventProjectImported {
+		t.Fatalf("expected project_imported, got %v", hub.events)
+	}
+	if hub.last.Active != projectDir || len(hub.last.Projects) != 1 || hub.last.Project == nil {
+		t.Errorf("unexpected import event payload: %+v", hub.last)
+	}
+
+	config, err := loadGlobalConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.LastProject != projectDir || len(config.Projects) != 1 {
+		t.Errorf("config not updated: %+v", config)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(configPath))
+	if len(entries) != 1 {
+		t.Errorf("expected only config.json after save, found %d entries", len(entries))
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/projects/"+url.PathEscape(projectDir), nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(hub.events) != 2 || hub.events[1] != EventProjectDeleted {
+		t.Fatalf("expected project_deleted, got %v", hub.events)
+	}
+	if hub.last.Active != "" || len(hub.last.Projects) != 0 {
+		t.Errorf("unexpected delete event payload: %+v", hub.last)
+	}
+
+	// Deleting again is a 404 and broadcasts nothing.
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/projects/"+url.PathEscape(projectDir), nil))
+	if w.Code != http.StatusNotFound || len(hub.events) != 2 {
+		t.Errorf("expected 404 without event, got %d, events %v", w.Code, hub.events)
+	}
+}
+
+func TestProjectSwitchBroadcastsAndUpdatesConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, ".mission"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	registry := `{"projects":{"demo":` + jsonString(filepath.Join(projectDir, ".mission")) + `}}`
+	os.MkdirAll(filepath.Join(home, ".mc"), 0755)
+	if err := os.WriteFile(filepath.Join(home, ".mc", "projects.json"), []byte(registry), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hub := &projectHub{}
+	s := NewServer(t.TempDir(), hub, nil, nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("POST", "/api/projects", strings.NewReader(`{"path":`+jsonString(projectDir)+`}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("switch: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(hub.events) != 1 || hub.events[0] != EventProjectSwitched || hub.last.Active != projectDir {
+		t.Errorf("expected project_switched to %s, got %v %+v", projectDir, hub.events, hub.last)
+	}
+
+	config, err := loadGlobalConfig(filepath.Join(home, ".mission-control", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.LastProject != projectDir {
+		t.Errorf("expected lastProject %s, got %s", projectDir, config.LastProject)
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func TestImportProjectWithAnalysis(t *testing.T) {
+	h := &ProjectsHandler{configPath: filepath.Join(t.TempDir(), "config.json")}
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	projectDir := t.TempDir()
+	os.MkdirAll(filepath.Join(projectDir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module example.com/svc\n"), 0644)
+
+	body := `{"path":` + jsonString(projectDir) + `,"import":true,"analyze":true}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/projects", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp CreateProjectResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Path != projectDir {
+		t.Errorf("expected project path %s, got %s", projectDir, resp.Path)
+	}
+	if resp.Analysis == nil || len(resp.Analysis.Languages) != 1 || resp.Analysis.Languages[0] != "Go" {
+		t.Errorf("expected Go analysis, got %+v", resp.Analysis)
+	}
+}
+
+// Creating and removing projects through the API's own routes broadcasts
+// the lifecycle events and passes analysis and templates on to mc init.
+func TestProjectRoutes(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	hub := &projectHub{}
+	s := NewServer(t.TempDir(), hub, nil, nil)
+	argsFile := filepath.Join(t.TempDir(), "args")
+	s.projects.mcPath = filepath.Join(t.TempDir(), "mc")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\nmkdir -p \"$3/.mission\"\n"
+	if err := os.WriteFile(s.projects.mcPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	routes := s.Routes()
+
+	projectDir := filepath.Join(t.TempDir(), "shop")
+	body := `{"path":` + jsonString(projectDir) + `,"template":"webapp"}`
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("POST", "/api/projects/create", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	args, _ := os.ReadFile(argsFile)
+	if got := strings.TrimSpace(string(args)); got != "init --path "+projectDir+" --king=false --template webapp" {
+		t.Errorf("mc init args = %q", got)
+	}
+	if len(hub.events) != 1 || hub.events[0] != EventProjectCreated || hub.last.Active != projectDir {
+		t.Fatalf("expected project_created, got %v %+v", hub.events, hub.last)
+	}
+
+	importDir := t.TempDir()
+	os.MkdirAll(filepath.Join(importDir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(importDir, "go.mod"), []byte("module example.com/svc\n"), 0644)
+	w = httptest.NewRecorder()
+	body = `{"path":` + jsonString(importDir) + `,"import":true,"analyze":true}`
+	routes.ServeHTTP(w, httptest.NewRequest("POST", "/api/projects/create", strings.NewReader(body)))
+	var resp CreateProjectResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || resp.Analysis == nil || len(hub.events) != 2 || hub.events[1] != EventProjectImported {
+		t.Fatalf("import: got %d %s, events %v", w.Code, w.Body.String(), hub.events)
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/projects/check?path="+url.QueryEscape(importDir), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"hasMission":true`) {
+		t.Errorf("check: got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/projects/"+url.PathEscape(projectDir), nil))
+	if w.Code != http.StatusNoContent || len(hub.events) != 3 || hub.events[2] != EventProjectDeleted {
+		t.Fatalf("delete: got %d %s, events %v", w.Code, w.Body.String(), hub.events)
+	}
+	config, err := loadGlobalConfig(filepath.Join(home, ".mission-control", "config.json"))
+	if err != nil || len(config.Projects) != 1 || config.Projects[0].Path != importDir {
+		t.Errorf("config after delete = %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// Rate limit defaults for mutating requests, per client.
+const (
+	DefaultRateLimit = 5.0 // requests per second
+	DefaultRateBurst = 20
+
+	rateLimitIdle = 10 * time.Minute // buckets unused this long are dropped
+)
+
+// RateLimiter applies a token bucket per client to mutating requests
+// (POST, PUT, PATCH, DELETE). Clients are identified by API token when one
+// is presented, otherwise by remote IP. Reads are never limited.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*bucket
+	allowed   uint64
+	limited   uint64
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens      float64
+	last        time.Time
+	allowed     uint64
+	limited     uint64
+	lastLimited time.Time
+}
+
+// NewRateLimiter returns a limiter allowing rate requests per second with
+// the given burst. A rate <= 0 disables limiting.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		clients: make(map[string]*bucket),
+	}
+}
+
+// Enabled reports whether the limiter enforces anything.
+func (l *RateLimiter) Enabled() bool {
+	return l != nil && l.rate > 0
+}
+
+// Allow takes a token for key. When none is available it returns false and
+// how long until one will be.
+func (l *RateLimiter) Allow(key string) (ok bool, remaining int, retryAfter time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, exists := l.clients[key]
+	if !exists {
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		b.limited++
+		b.lastLimited = now
+		l.limited++
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	b.allowed++
+	l.allowed++
+	return true, int(b.tokens), 0
+}
+
+// sweep drops idle buckets at most once a minute. Callers hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for k, b := range l.clients {
+		if now.Sub(b.last) > rateLimitIdle {
+			delete(l.clients, k)
+		}
+	}
+}
+
+// Middleware enforces the limit, answering 429 with Retry-After.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.Enabled() || !isMutation(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := rateLimitKey(r)
+		ok, remaining, retry := l.Allow(key)
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(int(l.burst)))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			secs := int(math.Ceil(retry.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			log.Printf("[ratelimit] %s %s %s throttled (retry in %ds)", key, r.Method, r.URL.Path, secs)
+			problem.Write(w, http.StatusTooManyRequests, problem.CodeRateLimited,
+				fmt.Sprintf("rate limit exceeded; retry in %ds", secs),
+				map[string]interface{}{"client": key, "retry_after": secs})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rateLimitKey identifies the client: a hash of its API token, or its IP.
+// Requests over a Unix socket share the "local" bucket.
+func rateLimitKey(r *http.Request) string {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == r.Header.Get("Authorization") {
+		token = r.URL.Query().Get("token")
+	}
+	if token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "key:" + hex.EncodeToString(sum[:4])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if host == "" || host == "@" {
+		return "local"
+	}
+	return "ip:" + host
+}
+
+// RateLimitStats is the GET /api/ratelimit response.
+type RateLimitStats struct {
+	Enabled bool                   `json:"enabled"`
+	Rate    float64                `json:"rate"`
+	Burst   int                    `json:"burst"`
+	Allowed uint64                 `json:"allowed"`
+	Limited uint64                 `json:"limited"`
+	Clients []RateLimitClientStats `json:"clients"`
+}
+
+// RateLimitClientStats are one client's counters.
+type RateLimitClientStats struct {
+	Client      string  `json:"client"`
+	Tokens      float64 `json:"tokens"`
+	Allowed     uint64  `json:"allowed"`
+	Limited     uint64  `json:"limited"`
+	LastLimited string  `json:"last_limited,omitempty"`
+}
+
+// Stats snapshots the limiter counters, most-limited clients first.
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := RateLimitStats{
+		Enabled: l.rate > 0,
+		Rate:    l.rate,
+		Burst:   int(l.burst),
+		Allowed: l.allowed,
+		Limited: l.limited,
+		Clients: []RateLimitClientStats{},
+	}
+	for k, b := range l.clients {
+		c := RateLimitClientStats{Client: k, Tokens: math.Floor(b.tokens), Allowed: b.allowed, Limited: b.limited}
+		if !b.lastLimited.IsZero() {
+			c.LastLimited = b.lastLimited.UTC().Format(time.RFC3339)
+		}
+		s.Clients = append(s.Clients, c)
+	}
+	sort.Slice(s.Clients, func(i, j int) bool {
+		if s.Clients[i].Limited != s.Clients[j].Limited {
+			return s.Clients[i].Limited > s.Clients[j].Limited
+		}
+		return s.Clients[i].Client < s.Clients[j].Client
+	})
+	return s
+}
+
+// StatsHandler serves Stats as JSON.
+func (l *RateLimiter) StatsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			problem.MethodNotAllowed(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, l.Stats())
+	}
+}
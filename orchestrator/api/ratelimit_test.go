@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within burst was limited", i+1)
+		}
+	}
+	ok, _, retry := l.Allow("a")
+	if ok {
+		t.Fatal("expected limit after burst")
+	}
+	if retry != 500*time.Millisecond {
+		t.Errorf("retry = %v, want 500ms at 2 req/s", retry)
+	}
+
+	// Other clients have their own bucket.
+	if ok, _, _ := l.Allow("b"); !ok {
+		t.Error("independent client was limited")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _, _ := l.Allow("a"); !ok {
+		t.Error("expected a token after refill")
+	}
+
+	s := l.Stats()
+	if s.Allowed != 5 || s.Limited != 1 || len(s.Clients) != 2 || s.Clients[0].Client != "a" {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(method, auth, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/tasks", nil)
+		req.RemoteAddr = remote
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "", "10.0.0.1:1234"); w.Code != http.StatusOK {
+		t.Fatalf("first POST: %d", w.Code)
+	}
+	w := do("POST", "", "10.0.0.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second POST from same IP: expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After")
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["code"] != "rate_limited" || body["details"].(map[string]interface{})["client"] != "ip:10.0.0.1" {
+		t.Errorf("body = %v", body)
+	}
+
+	if w := do("GET", "", "10.0.0.1:1234"); w.Code != http.StatusOK {
+		t.Errorf("GET should not be limited, got %d", w.Code)
+	}
+	// An API key gets its own bucket regardless of IP.
+	if w := do("PATCH", "agent-key", "10.0.0.1:1234"); w.Code != http.StatusOK {
+		t.Errorf("keyed client: %d", w.Code)
+	}
+	if w := do("PATCH", "agent-key", "10.0.0.2:1234"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("same key from another IP: expected 429, got %d", w.Code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	l := NewRateLimiter(0, 0)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 50; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/api/tasks", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("disabled limiter returned %d", w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	l.StatsHandler()(w, httptest.NewRequest("GET", "/api/ratelimit", nil))
+	var s RateLimitStats
+	json.Unmarshal(w.Body.Bytes(), &s)
+	if s.Enabled {
+		t.Error("stats should report disabled")
+	}
+}
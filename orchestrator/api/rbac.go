@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// allowRole reports whether the user behind r holds role need under the
+// roles in projectDir's .mission/config.json, answering 403 when not. Only
+// a user identified by their own token is matched against the roles;
+// callers who just name themselves in X-MC-User get the "*" role.
+func allowRole(w http.ResponseWriter, r *http.Request, projectDir, need string) bool {
+	cfg, err := bridge.LoadProjectConfig(projectDir)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to read project roles: "+err.Error())
+		return false
+	}
+	user := UserFromContext(r.Context())
+	role := cfg.Roles.Of(VerifiedUser(r.Context()))
+	if identity.Allows(role, need) {
+		return true
+	}
+	who := user.String()
+	if who == "" {
+		who = "anonymous"
+	}
+	problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "requires the "+need+" role", map[string]interface{}{
+		"user":     who,
+		"role":     role,
+		"required": need,
+	})
+	return false
+}
+
+// requireRole wraps h so only users holding role need in the current
+// project reach it.
+func (s *Server) requireRole(need string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if allowRole(w, r, s.getMissionDir(), need) {
+			h(w, r)
+		}
+	}
+}
+
+// authorize requires the contributor role for every request to the
+// server's routes that isn't a read.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return Authorize(s.getMissionDir, next)
+}
+
+// Authorize requires the contributor role in the project projectDir
+// returns for every request that isn't a read; viewers can only look.
+// mc serve wraps the routes it registers beside the Server's with it.
+func Authorize(projectDir func() string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if !allowRole(w, r, projectDir(), identity.RoleContributor) {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ProjectDir returns the directory of the project the server is on.
+func (s *Server) ProjectDir() string {
+	return s.getMissionDir()
+}
+
+// handleMe reports who the caller is and their role in the current project,
+// so clients can hide what they aren't allowed to do.
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	cfg, err := bridge.LoadProjectConfig(s.getMissionDir())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to read project roles: "+err.Error())
+		return
+	}
+	user := UserFromContext(r.Context())
+	writeJSON(w, http.StatusOK, MeResponse{
+		User:  user,
+		Role:  cfg.Roles.Of(VerifiedUser(r.Context())),
+		Roles: len(cfg.Roles) > 0,
+	})
+}
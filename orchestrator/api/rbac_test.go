@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRoleEnforcement(t *testing.T) {
+	installFakeMC(t)
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"roles": {
+		"admin@example.com": "admin",
+		"approver@example.com": "approver",
+		"dev@example.com": "contributor",
+		"*": "viewer"
+	}}`), 0644)
+	t.Setenv("MC_API_TOKEN", "")
+	t.Setenv("MC_API_TOKENS", "admin@example.com=admin,approver@example.com=approver,dev@example.com=dev,guest@example.com=guest")
+	handler := AuthMiddleware(s.Routes())
+
+	tests := []struct {
+		token, method, path, body string
+		status                    int
+	}{
+		{"guest", "GET", "/api/tasks", "", http.StatusOK},
+		{"guest", "POST", "/api/checkpoints", "", http.StatusForbidden},
+		{"dev", "POST", "/api/checkpoints", "", http.StatusCreated},
+		{"dev", "POST", "/api/gates/goal/approve", "", http.StatusForbidden},
+		{"approver", "POST", "/api/gates/goal/approve", "", http.StatusOK},
+		{"approver", "POST", "/api/gates/goal/reject", `{"reason":"no"}`, http.StatusOK},
+		{"approver", "POST", "/api/stages/override", `{"stage":"goal"}`, http.StatusForbidden},
+		{"approver", "POST", "/api/projects", `{"path":"/elsewhere"}`, http.StatusForbidden},
+		{"approver", "POST", "/api/projects/create", `{"path":"/elsewhere"}`, http.StatusForbidden},
+		{"approver", "GET", "/api/browse", "", http.StatusForbidden},
+		{"admin", "POST", "/api/stages/override", `{"stage":"goal"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s %s as %s: expected %d, got %d: %s", tt.method, tt.path, tt.token, tt.status, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer approver")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	var me MeResponse
+	json.Unmarshal(w.Body.Bytes(), &me)
+	if me.User.Email != "approver@example.com" || me.Role != "approver" || !me.Roles {
+		t.Errorf("unexpected /api/me: %+v", me)
+	}
+}
+
+func TestHeaderUserGetsNoConfiguredRole(t *testing.T) {
+	installFakeMC(t)
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"roles": {"admin@example.com": "admin", "*": "viewer"}}`), 0644)
+	t.Setenv("MC_API_TOKEN", "shared")
+	t.Setenv("MC_API_TOKENS", "admin@example.com=admin")
+	handler := AuthMiddleware(s.Routes())
+
+	req := httptest.NewRequest("POST", "/api/stages/override", strings.NewReader(`{"stage":"goal"}`))
+	req.Header.Set("Authorization", "Bearer shared")
+	req.Header.Set(UserHeader, "admin@example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected a header-named admin refused, got %d: %s", w.Code, w.Body.String())
+	}
+
+	t.Setenv("MC_API_TOKEN", "")
+	t.Setenv("MC_API_TOKENS", "")
+	req = httptest.NewRequest("POST", "/api/stages/override", strings.NewReader(`{"stage":"goal"}`))
+	req.Header.Set(UserHeader, "admin@example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected a header-named admin refused with auth off, got %d", w.Code)
+	}
+}
+
+func TestNoRolesConfiguredAllowsAll(t *testing.T) {
+	installFakeMC(t)
+	s, _ := newTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/stages/override", strings.NewReader(`{"stage":"goal"}`))
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 without roles, got %d: %s", w.Code, w.Body.String())
+	}
+}
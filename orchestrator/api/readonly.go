@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// ReadOnlyPath toggles read-only mode; it stays writable while the rest of
+// the API is not.
+const ReadOnlyPath = "/api/readonly"
+
+// ReadOnly is the orchestrator's maintenance switch. While enabled every
+// mutating request (POST, PUT, PATCH, DELETE) is refused with 503; reads
+// and WebSocket events carry on, which suits migrations, demos and
+// post-incident inspection. Worker heartbeats still pass so running
+// workers aren't reaped as stale.
+type ReadOnly struct {
+	now func() time.Time
+
+	mu     sync.RWMutex
+	status ReadOnlyStatus
+}
+
+// ReadOnlyStatus is the GET /api/readonly response.
+type ReadOnlyStatus struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	Since   string `json:"since,omitempty"`
+	By      string `json:"by,omitempty"` // user who turned it on
+}
+
+// ReadOnlyRequest is the request body for POST /api/readonly.
+type ReadOnlyRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// NewReadOnly returns the switch, on from the start when enabled
+// (--read-only).
+func NewReadOnly(enabled bool, reason string) *ReadOnly {
+	m := &ReadOnly{now: time.Now}
+	if enabled {
+		m.Set(true, reason, "")
+	}
+	return m
+}
+
+// Enabled reports whether mutations are being refused.
+func (m *ReadOnly) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Enabled
+}
+
+// Status snapshots the switch.
+func (m *ReadOnly) Status() ReadOnlyStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Set turns read-only mode on or off and returns the new status.
+func (m *ReadOnly) Set(enabled bool, reason, by string) ReadOnlyStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !enabled {
+		m.status = ReadOnlyStatus{}
+		return m.status
+	}
+	m.status = ReadOnlyStatus{
+		Enabled: true,
+		Reason:  reason,
+		Since:   m.now().UTC().Format(time.RFC3339),
+		By:      by,
+	}
+	return m.status
+}
+
+// Middleware refuses mutations with 503 while read-only mode is on.
+func (m *ReadOnly) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutation(r.Method) || r.URL.Path == ReadOnlyPath || strings.HasSuffix(r.URL.Path, "/heartbeat") || !m.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		status := m.Status()
+		msg := "orchestrator is in read-only mode"
+		if status.Reason != "" {
+			msg += ": " + status.Reason
+		}
+		problem.Write(w, http.StatusServiceUnavailable, problem.CodeUnavailable, msg, map[string]interface{}{
+			"read_only": true,
+			"since":     status.Since,
+		})
+	})
+}
+
+// Handler serves GET and POST /api/readonly. Toggling needs the admin role
+// in projectDir, and each change is broadcast on the "system" topic.
+func (m *ReadOnly) Handler(projectDir string, hub HubBroadcaster) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, m.Status())
+		case http.MethodPost:
+			var req ReadOnlyRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				problem.InvalidBody(w, err)
+				return
+			}
+			if req.Enabled == nil {
+				problem.Validation(w, "enabled is required")
+				return
+			}
+			if !allowRole(w, r, projectDir, identity.RoleAdmin) {
+				return
+			}
+			status := m.Set(*req.Enabled, req.Reason, UserFromContext(r.Context()).String())
+			log.Printf("[readonly] enabled=%v reason=%q", status.Enabled, status.Reason)
+			if hub != nil {
+				hub.BroadcastRaw("system", "read_only_changed", status)
+			}
+			writeJSON(w, http.StatusOK, status)
+		default:
+			problem.MethodNotAllowed(w)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyRefusesMutations(t *testing.T) {
+	m := NewReadOnly(false, "")
+	hub := &requestHub{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReadOnlyPath, m.Handler(t.TempDir(), hub))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := m.Middleware(mux)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := send("POST", "/api/tasks", "{}"); w.Code != http.StatusOK {
+		t.Fatalf("Expected mutations allowed before read-only mode, got %d", w.Code)
+	}
+	if w := send("POST", ReadOnlyPath, `{"enabled": true, "reason": "migrating"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected toggle to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := send("PATCH", "/api/tasks/t1", "{}")
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "migrating") {
+		t.Errorf("Expected 503 naming the reason, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := send("GET", "/api/tasks", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected reads to pass in read-only mode, got %d", w.Code)
+	}
+	if w := send("POST", "/api/mc/worker/w1/heartbeat", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected heartbeats to pass in read-only mode, got %d", w.Code)
+	}
+
+	var status ReadOnlyStatus
+	json.Unmarshal(send("GET", ReadOnlyPath, "").Body.Bytes(), &status)
+	if !status.Enabled || status.Reason != "migrating" || status.Since == "" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if w := send("POST", ReadOnlyPath, `{"reason": "no flag"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without enabled, got %d", w.Code)
+	}
+	send("POST", ReadOnlyPath, `{"enabled": false}`)
+	if w := send("DELETE", "/api/zones/a", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected mutations allowed after turning read-only off, got %d", w.Code)
+	}
+	if len(hub.events) != 2 || hub.events[0].eventType != "read_only_changed" {
+		t.Errorf("Expected two read_only_changed broadcasts, got %+v", hub.events)
+	}
+}
+
+func TestNewReadOnlyEnabled(t *testing.T) {
+	if m := NewReadOnly(true, "started with --read-only"); !m.Enabled() || m.Status().Reason != "started with --read-only" {
+		t.Errorf("Expected read-only from the start, got %+v", m.Status())
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/httpstream"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/recording"
+)
+
+// defaultMaxIdle caps the pause between events during playback, so a
+// worker thinking for minutes replays in seconds.
+const defaultMaxIdle = 2.0
+
+// RecordingsResponse is the body of GET /api/recordings.
+type RecordingsResponse struct {
+	Recordings []recording.Info `json:"recordings"`
+}
+
+// handleRecordings lists the recordings in .mission/recordings/, newest
+// first.
+func (s *Server) handleRecordings(w http.ResponseWriter, r *http.Request) {
+	infos, err := recording.List(s.missionPath("recordings"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list recordings")
+		return
+	}
+	writeJSON(w, http.StatusOK, RecordingsResponse{Recordings: infos})
+}
+
+// handleRecordingRouter serves GET /api/recordings/{id}, the asciicast
+// file, and GET /api/recordings/{id}/play, its output replayed.
+func (s *Server) handleRecordingRouter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.MethodNotAllowed(w)
+		return
+	}
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/recordings/"), "/")
+	if !validateTaskID(id) {
+		respondError(w, http.StatusBadRequest, "invalid recording ID")
+		return
+	}
+	path := recording.Path(s.missionPath("recordings"), id)
+	switch rest {
+	case "":
+		if _, _, err := recording.Read(path); err != nil {
+			s.recordingError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-asciicast")
+		http.ServeFile(w, r, path)
+	case "play":
+		s.handleRecordingPlay(w, r, path)
+	default:
+		respondError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleRecordingPlay streams a recording's output as plain text with its
+// original timing, for `curl -N`. speed (default 1) scales the timing and
+// max_idle (seconds, default 2) caps each pause.
+func (s *Server) handleRecordingPlay(w http.ResponseWriter, r *http.Request, path string) {
+	speed, ok := positiveFloatParam(w, r, "speed", 1)
+	if !ok {
+		return
+	}
+	maxIdle, ok := positiveFloatParam(w, r, "max_idle", defaultMaxIdle)
+	if !ok {
+		return
+	}
+	_, events, err := recording.Read(path)
+	if err != nil {
+		s.recordingError(w, err)
+		return
+	}
+
+	httpstream.Begin(w)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	var last float64
+	for _, ev := range events {
+		pause := ev.Time - last
+		if pause > maxIdle {
+			pause = maxIdle
+		}
+		last = ev.Time
+		if pause > 0 {
+			timer := time.NewTimer(time.Duration(pause / speed * float64(time.Second)))
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+		w.Write([]byte(ev.Data))
+		flush(w)
+	}
+}
+
+func (s *Server) recordingError(w http.ResponseWriter, err error) {
+	if errors.Is(err, recording.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "recording not found")
+		return
+	}
+	respondError(w, http.StatusInternalServerError, err.Error())
+}
+
+// positiveFloatParam parses an optional positive number query parameter.
+func positiveFloatParam(w http.ResponseWriter, r *http.Request, name string, def float64) (float64, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		problem.Validation(w, name+" must be a positive number")
+		return 0, false
+	}
+	return f, true
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/recording"
+)
+
+func TestRecordings(t *testing.T) {
+	s, dir := newTestServer(t)
+	recDir := filepath.Join(dir, ".mission", "recordings")
+	os.MkdirAll(recDir, 0755)
+	os.WriteFile(recording.Path(recDir, "w1"), []byte(`{"version": 2, "width": 120, "height": 40, "timestamp": 1700000000, "title": "w1"}
+[0.1, "o", "hello\r\n"]
+[30, "o", "world\r\n"]
+`), 0644)
+	routes := s.Routes()
+
+	req := httptest.NewRequest("GET", "/api/recordings", nil)
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	var list RecordingsResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Recordings) != 1 || list.Recordings[0].ID != "w1" || list.Recordings[0].Duration != 30 {
+		t.Fatalf("list %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/recordings/w1", nil)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-asciicast" {
+		t.Errorf("cast %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	// The 30s pause is capped by max_idle, then sped up
+	req = httptest.NewRequest("GET", "/api/recordings/w1/play?speed=100&max_idle=0.5", nil)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "hello\r\nworld\r\n" {
+		t.Errorf("play %d: %q", w.Code, w.Body.String())
+	}
+
+	for path, want := range map[string]int{
+		"/api/recordings/missing":          http.StatusNotFound,
+		"/api/recordings/w1/play?speed=0":  http.StatusBadRequest,
+		"/api/recordings/..%2Fconfig/play": http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != want {
+			t.Errorf("%s = %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -6,6 +6,12 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/MikeSquared-Agency/MissionControl/alerts"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/conversation"
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
 	"github.com/MikeSquared-Agency/MissionControl/tokens"
 	"github.com/MikeSquared-Agency/MissionControl/tracker"
 )
@@ -13,11 +19,20 @@ import (
 // Server holds dependencies for the API.
 // All external dependencies are injected via interfaces.
 type Server struct {
-	missionDir string
-	mu         sync.RWMutex // protects missionDir
-	hub        HubBroadcaster
-	tracker    TrackerReader
-	tokens     TokenReader
+	missionDir  string
+	mu          sync.RWMutex // protects missionDir
+	hub         HubBroadcaster
+	tracker     TrackerReader
+	tokens      TokenReader
+	etags       etagCache
+	pinned      bool // project switching disabled (per-project servers)
+	events      *eventlog.Log
+	alerts      *alerts.Store
+	graph       *GraphCache
+	king        func(message string) error
+	projects    *ProjectsHandler // creating, importing and removing projects
+	maxResponse int64            // file response cap; see SetMaxResponseBytes
+	mockupsMu   sync.Mutex       // serializes uploads, which rewrite the mockup index
 }
 
 // HubBroadcaster is satisfied by ws.Hub
@@ -25,6 +40,12 @@ type HubBroadcaster interface {
 	BroadcastRaw(topic, eventType string, data interface{})
 }
 
+// RequestBroadcaster is optionally implemented by hubs that can tag events
+// with the originating request ID (ws.Hub does).
+type RequestBroadcaster interface {
+	BroadcastRawWithRequestID(requestID, topic, eventType string, data interface{})
+}
+
 // TrackerReader is satisfied by tracker.Tracker
 type TrackerReader interface {
 	List() []*tracker.TrackedProcess
@@ -43,9 +64,20 @@ func NewServer(missionDir string, hub HubBroadcaster, tracker TrackerReader, tok
 		hub:        hub,
 		tracker:    tracker,
 		tokens:     tokens,
+		graph:      NewGraphCache(),
+		projects:   NewProjectsHandler(),
 	}
 }
 
+// PinProject disables POST /api/projects/switch. Servers created for a
+// single project in a multi-project orchestrator are pinned so one tab
+// cannot repoint another's API.
+func (s *Server) PinProject() {
+	s.mu.Lock()
+	s.pinned = true
+	s.mu.Unlock()
+}
+
 // Routes returns the HTTP handler with all API routes.
 func (s *Server) Routes() http.Handler {
 	mux := http.NewServeMux()
@@ -53,45 +85,112 @@ func (s *Server) Routes() http.Handler {
 	// Health
 	mux.HandleFunc("/api/health", s.methodGET(s.handleHealth))
 
+	// Identity
+	mux.HandleFunc("/api/me", s.methodGET(s.handleMe))
+
 	// Status
-	mux.HandleFunc("/api/status", s.methodGET(s.handleStatus))
+	mux.HandleFunc("/api/status", s.methodGET(s.withETag(nil, s.handleStatus)))
+	mux.HandleFunc("/api/state", s.methodGET(s.withETag(nil, s.handleState)))
 
 	// Tasks
 	mux.HandleFunc("/api/tasks", s.handleTasksRouter)
 	mux.HandleFunc("/api/tasks/", s.handleTaskRouter)
+	mux.HandleFunc("/api/tasks/bulk", s.methodPOST(s.handleBulkCreateTasks))
 
 	// Graph
-	mux.HandleFunc("/api/graph", s.methodGET(s.handleGraph))
+	mux.HandleFunc("/api/graph", s.methodGET(s.withETag(s.stateSources("tasks.jsonl", "zones.json"), s.handleGraph)))
+	mux.HandleFunc("/api/graph/validate", s.methodGET(s.withETag(s.stateSources("tasks.jsonl"), s.handleGraphValidate)))
+	mux.HandleFunc("/api/graph/export", s.methodGET(s.withETag(s.stateSources("tasks.jsonl", "zones.json"), s.handleGraphExport)))
+
+	// Analytics
+	mux.HandleFunc("/api/analytics/burndown", s.methodGET(s.handleBurndown))
+	mux.HandleFunc("/api/analytics/stage-durations", s.methodGET(s.handleStageDurations))
 
 	// Workers
 	mux.HandleFunc("/api/workers", s.handleWorkersRouter)
 	mux.HandleFunc("/api/workers/", s.handleWorkerRouter)
 
 	// Gates
-	mux.HandleFunc("/api/gates", s.methodGET(s.handleGates))
+	mux.HandleFunc("/api/gates", s.methodGET(s.withETag(nil, s.handleGates))) // pending_min moves with the clock
 	mux.HandleFunc("/api/gates/", s.handleGateRouter)
+	mux.HandleFunc("/api/approvals", s.methodGET(s.handleApprovals))
+	mux.HandleFunc("/api/approvals/", s.methodPOST(s.handleApprovalAction))
 
 	// Zones
-	mux.HandleFunc("/api/zones", s.methodGET(s.handleZones))
+	mux.HandleFunc("/api/zones", s.handleZonesRouter)
+	mux.HandleFunc("/api/zones/", s.handleZoneRouter)
 
 	// Checkpoints
 	mux.HandleFunc("/api/checkpoints", s.handleCheckpointsRouter)
 	mux.HandleFunc("/api/checkpoints/", s.handleCheckpointRouter)
 
+	// Findings
+	mux.HandleFunc("/api/findings", s.methodGET(s.handleFindings))
+	mux.HandleFunc(GraphQLPath, s.handleGraphQL)
+
+	// Conversation
+	mux.HandleFunc("/api/conversation", s.methodGET(s.withETag(s.conversationSources, s.handleConversation)))
+
 	// Audit
 	mux.HandleFunc("/api/audit", s.methodGET(s.handleAudit))
 
+	// Events: the mutation stream, and replay of WebSocket notifications
+	mux.HandleFunc("/api/events", s.methodGET(s.handleEvents))
+	mux.HandleFunc("/api/events/state", s.methodGET(s.handleEventState))
+	mux.HandleFunc("/api/notifications", s.methodGET(s.handleNotifications))
+	mux.HandleFunc("/api/notifications/ack", s.methodPOST(s.handleNotificationAck))
+
 	// Tokens
 	mux.HandleFunc("/api/tokens", s.methodGET(s.handleTokens))
+	mux.HandleFunc("/api/budgets/forecast", s.methodGET(s.handleBudgetForecast))
+
+	// Report
+	mux.HandleFunc("/api/report", s.methodGET(s.handleReport))
+	mux.HandleFunc("/api/release/notes", s.methodGET(s.handleReleaseNotes))
+
+	// Simulation
+	mux.HandleFunc("/api/simulate", s.methodGET(s.handleSimulate))
+
+	// Prompt experiments
+	mux.HandleFunc("/api/experiments", s.methodGET(s.handleExperiments))
+
+	// Export: audit and usage as CSV or Parquet
+	mux.HandleFunc("/api/export/", s.methodGET(s.handleExport))
+
+	// King
+	mux.HandleFunc("/api/king/prompt", s.methodGET(s.handleKingPrompt))
+	mux.HandleFunc("/api/commands", s.handleCommands)
+	mux.HandleFunc("/api/king/message", s.methodPOST(s.handleKingMessage))
+	mux.HandleFunc("/api/king/transcript", s.methodGET(s.withETag(s.transcriptSources, s.handleKingTranscript)))
+
+	// Notification center
+	mux.HandleFunc("/api/alerts", s.methodGET(s.handleAlerts))
+	mux.HandleFunc("/api/alerts/", s.methodPOST(s.handleAlertAck))
+	mux.HandleFunc("/api/schedules", s.methodGET(s.handleSchedules))
+	mux.HandleFunc("/api/spend", s.methodGET(s.handleSpend))
+	mux.HandleFunc("/api/spend/override", s.handleSpendOverride)
 
-	// Projects (new endpoint for reading config)
+	// Recordings of worker output
+	mux.HandleFunc("/api/recordings", s.methodGET(s.handleRecordings))
+	mux.HandleFunc("/api/recordings/", s.handleRecordingRouter)
+
+	// Projects: the registry and switching between its projects, plus
+	// creating, importing and removing projects for the dashboard
+	s.projects.SetHub(s.hub)
 	mux.HandleFunc("/api/projects", s.handleProjectsRouter)
+	mux.HandleFunc("/api/projects/switch", s.methodPOST(s.requireRole(identity.RoleAdmin, s.handleProjectSwitch)))
+	mux.HandleFunc("/api/projects/create", s.methodPOST(s.requireRole(identity.RoleAdmin, s.projects.createProject)))
+	mux.HandleFunc("/api/projects/check", s.requireRole(identity.RoleAdmin, s.projects.handleCheckPath))
+	mux.HandleFunc("/api/projects/", s.handleProjectRouter)
+	mux.HandleFunc("/api/browse", s.requireRole(identity.RoleAdmin, s.projects.handleBrowse))
 
 	// Chat
 	mux.HandleFunc("/api/chat", s.methodPOST(s.handleChat))
+	mux.HandleFunc("/api/chat/history", s.methodGET(s.withETag(s.chatSources, s.handleChatHistory)))
 
 	// Stages
-	mux.HandleFunc("/api/stages/override", s.methodPOST(s.handleStageOverride))
+	mux.HandleFunc("/api/stages/override", s.methodPOST(s.requireRole(identity.RoleAdmin, s.handleStageOverride)))
+	mux.HandleFunc("/api/stages/rollback", s.methodPOST(s.requireRole(identity.RoleAdmin, s.handleStageRollback)))
 
 	// Swarm BFF
 	mux.HandleFunc("/api/swarm/overview", s.methodGET(s.handleSwarmOverview))
@@ -104,8 +203,10 @@ func (s *Server) Routes() http.Handler {
 	mux.HandleFunc("/api/requirements/coverage", s.methodGET(s.handleRequirementsCoverage))
 	mux.HandleFunc("/api/specs", s.methodGET(s.handleSpecs))
 	mux.HandleFunc("/api/specs/", s.handleSpecRouter)
+	mux.HandleFunc("/api/mockups", s.handleMockups)
+	mux.HandleFunc("/api/mockups/", s.methodGET(s.handleMockupFile))
 
-	return mux
+	return s.authorize(mux)
 }
 
 // --- Routers for path-based dispatch ---
@@ -113,11 +214,11 @@ func (s *Server) Routes() http.Handler {
 func (s *Server) handleTasksRouter(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		s.handleTasks(w, r)
+		s.withETag(s.stateSources("tasks.jsonl"), s.handleTasks)(w, r)
 	case http.MethodPost:
 		s.handleCreateTask(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 	}
 }
 
@@ -137,21 +238,28 @@ func (s *Server) handleTaskRouter(w http.ResponseWriter, r *http.Request) {
 				s.handleTaskDependencies(w, r, id)
 				return
 			}
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 			return
 		case "findings":
 			if r.Method == http.MethodGet {
 				s.handleTaskFindings(w, r, id)
 				return
 			}
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 			return
 		case "briefing":
 			if r.Method == http.MethodGet {
 				s.handleTaskBriefing(w, r, id)
 				return
 			}
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
+			return
+		case "archive", "unarchive":
+			if r.Method == http.MethodPost {
+				s.handleTaskArchive(w, r, id, parts[1])
+				return
+			}
+			problem.MethodNotAllowed(w)
 			return
 		}
 	}
@@ -162,7 +270,7 @@ func (s *Server) handleTaskRouter(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPatch:
 		s.handleUpdateTask(w, r, id)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 	}
 }
 
@@ -179,7 +287,7 @@ func (s *Server) handleWorkersRouter(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		s.handleSpawnWorker(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 	}
 }
 
@@ -204,12 +312,21 @@ func (s *Server) handleWorkerRouter(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) > 1 && parts[1] == "logs" {
+		if r.Method != http.MethodGet {
+			problem.MethodNotAllowed(w)
+			return
+		}
+		s.handleWorkerLogs(w, r, id)
+		return
+	}
+
 	if r.Method == http.MethodGet {
 		s.handleWorkerByID(w, r, id)
 		return
 	}
 
-	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	problem.MethodNotAllowed(w)
 }
 
 func (s *Server) handleGateRouter(w http.ResponseWriter, r *http.Request) {
@@ -226,16 +343,20 @@ func (s *Server) handleGateRouter(w http.ResponseWriter, r *http.Request) {
 		switch parts[1] {
 		case "approve":
 			if r.Method == http.MethodPost {
-				s.handleGateApprove(w, r, stage)
+				if allowRole(w, r, s.getMissionDir(), identity.RoleApprover) {
+					s.handleGateApprove(w, r, stage)
+				}
 				return
 			}
 		case "reject":
 			if r.Method == http.MethodPost {
-				s.handleGateReject(w, r, stage)
+				if allowRole(w, r, s.getMissionDir(), identity.RoleApprover) {
+					s.handleGateReject(w, r, stage)
+				}
 				return
 			}
 		}
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
@@ -243,17 +364,17 @@ func (s *Server) handleGateRouter(w http.ResponseWriter, r *http.Request) {
 		s.handleGateByStage(w, r, stage)
 		return
 	}
-	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	problem.MethodNotAllowed(w)
 }
 
 func (s *Server) handleCheckpointsRouter(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		s.handleCheckpoints(w, r)
+		s.withETag(s.checkpointSources, s.handleCheckpoints)(w, r)
 	case http.MethodPost:
 		s.handleCreateCheckpoint(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 	}
 }
 
@@ -270,8 +391,16 @@ func (s *Server) handleCheckpointRouter(w http.ResponseWriter, r *http.Request)
 		s.handleRestartCheckpoint(w, r, id)
 		return
 	}
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			problem.MethodNotAllowed(w)
+			return
+		}
+		s.handleCheckpointByID(w, r, id)
+		return
+	}
 
-	http.Error(w, "Not found", http.StatusNotFound)
+	problem.NotFound(w, "Not found")
 }
 
 func (s *Server) handleProjectsRouter(w http.ResponseWriter, r *http.Request) {
@@ -279,23 +408,45 @@ func (s *Server) handleProjectsRouter(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		s.handleProjects(w, r)
 	case http.MethodPost:
-		// Check path for /api/projects/switch
-		if r.URL.Path == "/api/projects/switch" {
-			s.handleProjectSwitch(w, r)
-			return
-		}
-		s.handleProjectSwitch(w, r)
+		s.requireRole(identity.RoleAdmin, s.handleProjectSwitch)(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
+	}
+}
+
+// handleProjectRouter serves /api/projects/{path}: removing a project from
+// the list, which is for admins, and its personas, which check the role
+// in that project themselves.
+func (s *Server) handleProjectRouter(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete && !strings.Contains(r.URL.Path, "/personas") {
+		s.requireRole(identity.RoleAdmin, s.projects.handleProject)(w, r)
+		return
 	}
+	s.projects.handleProject(w, r)
 }
 
 func (s *Server) handleSpecRouter(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/specs/")
+	if id, action, ok := strings.Cut(path, "/"); ok {
+		want := http.MethodPost
+		if action == "diff" {
+			want = http.MethodGet
+		}
+		if r.Method != want {
+			problem.MethodNotAllowed(w)
+			return
+		}
+		if action == "diff" {
+			s.handleSpecDiff(w, r, id)
+		} else {
+			s.handleSpecAction(w, r, id, action)
+		}
+		return
+	}
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
-	path := strings.TrimPrefix(r.URL.Path, "/api/specs/")
 	if path == "orphans" {
 		s.handleSpecsOrphans(w, r)
 		return
@@ -303,12 +454,46 @@ func (s *Server) handleSpecRouter(w http.ResponseWriter, r *http.Request) {
 	s.handleSpecByID(w, r, path)
 }
 
+// --- Conditional request sources ---
+
+// stateSources returns a source func for files under .mission/state/.
+func (s *Server) stateSources(names ...string) func() []string {
+	return func() []string {
+		paths := make([]string, len(names))
+		for i, n := range names {
+			paths[i] = s.statePath(n)
+		}
+		return paths
+	}
+}
+
+// checkpointSources covers the checkpoint directory; creating or removing a
+// checkpoint updates its mtime.
+func (s *Server) checkpointSources() []string {
+	return []string{s.missionPath("orchestrator", "checkpoints")}
+}
+
+// conversationSources covers the .mission/conversation.md transcript.
+func (s *Server) conversationSources() []string {
+	return []string{s.missionPath(conversation.FileName)}
+}
+
+// chatSources covers the persisted chat history.
+func (s *Server) chatSources() []string {
+	return []string{s.missionPath(chat.Dir, chat.FileName)}
+}
+
+// transcriptSources covers the King transcript.
+func (s *Server) transcriptSources() []string {
+	return []string{s.missionPath(chat.TranscriptDir, chat.TranscriptFile)}
+}
+
 // --- Method helpers ---
 
 func (s *Server) methodGET(h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 			return
 		}
 		h(w, r)
@@ -318,7 +503,7 @@ func (s *Server) methodGET(h http.HandlerFunc) http.HandlerFunc {
 func (s *Server) methodPOST(h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			problem.MethodNotAllowed(w)
 			return
 		}
 		h(w, r)
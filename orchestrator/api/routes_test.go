@@ -2,13 +2,22 @@ package api
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
-
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/core"
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
+	"github.com/MikeSquared-Agency/MissionControl/gatetimer"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
 	"github.com/MikeSquared-Agency/MissionControl/tracker"
 )
 
@@ -122,6 +131,21 @@ func TestTasksWithData(t *testing.T) {
 	}
 }
 
+func TestTasksFilterByAssignee(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(`{"id":"a","persona":"manual","assignee":"alice","status":"pending"}
+{"id":"b","persona":"developer","status":"pending"}
+`), 0644)
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks?assignee=alice", nil))
+	var tasks []map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0]["id"] != "a" {
+		t.Errorf("Expected only task a, got %v", tasks)
+	}
+}
+
 func TestGatesEndpoint(t *testing.T) {
 	s, dir := newTestServer(t)
 
@@ -157,6 +181,30 @@ func TestGatesEndpoint(t *testing.T) {
 	}
 }
 
+func TestGatesPendingDuration(t *testing.T) {
+	s, dir := newTestServer(t)
+	state := filepath.Join(dir, ".mission", "state")
+	os.WriteFile(filepath.Join(state, "gates.json"), []byte(`{"gates":{"discovery":{"status":"approved"},"design":{"status":"ready"}}}`), 0644)
+	since := time.Now().Add(-90 * time.Minute).UTC().Format(time.RFC3339)
+	os.WriteFile(filepath.Join(state, gatetimer.File), []byte(`{"gates":{"design":{"stage":"design","awaiting_since":"`+since+`","level":1}}}`), 0644)
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/gates", nil))
+	var body struct {
+		Gates map[string]map[string]interface{} `json:"gates"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	design := body.Gates["design"]
+	if design["awaiting_since"] != since || design["pending_min"] != float64(90) || design["escalation_level"] != float64(1) {
+		t.Errorf("expected the design gate's wait, got %v", design)
+	}
+	if _, ok := body.Gates["discovery"]["pending_min"]; ok {
+		t.Errorf("expected no wait on an approved gate, got %v", body.Gates["discovery"])
+	}
+}
+
 func TestRequirementsPlaceholder(t *testing.T) {
 	s, _ := newTestServer(t)
 	routes := s.Routes()
@@ -304,6 +352,84 @@ func TestGraphEndpoint(t *testing.T) {
 	}
 }
 
+func TestArchivedTasksHidden(t *testing.T) {
+	s, dir := newTestServer(t)
+
+	// b's second line supersedes its first and archives it
+	tasksFile := filepath.Join(dir, ".mission", "state", "tasks.jsonl")
+	os.WriteFile(tasksFile, []byte(`{"id":"a","name":"A","status":"pending","dependencies":["b"]}
+{"id":"b","name":"B","status":"pending"}
+{"id":"c","name":"C","status":"done"}
+{"id":"b","name":"B","status":"archived","archived_from":"pending"}
+`), 0644)
+
+	routes := s.Routes()
+	get := func(url string, v interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", url, w.Code, w.Body.String())
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), v)
+	}
+
+	var tasks []map[string]interface{}
+	get("/api/tasks", &tasks)
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 unarchived tasks, got %v", tasks)
+	}
+	get("/api/tasks?include_archived=true", &tasks)
+	if len(tasks) != 3 {
+		t.Errorf("Expected 3 tasks with archived, got %d", len(tasks))
+	}
+	get("/api/tasks?status=archived", &tasks)
+	if len(tasks) != 1 || tasks[0]["id"] != "b" {
+		t.Errorf("Expected only b, got %v", tasks)
+	}
+
+	var graph GraphResponse
+	get("/api/graph", &graph)
+	if len(graph.Nodes) != 2 || len(graph.Edges) != 0 {
+		t.Errorf("Expected 2 nodes and no edges, got %+v", graph)
+	}
+}
+
+func TestTaskArchiveCallsMC(t *testing.T) {
+	s, dir := newTestServer(t)
+
+	// Fake mc records its arguments and refuses to archive unless forced
+	bin := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	os.WriteFile(filepath.Join(bin, "mc"), []byte(`#!/bin/sh
+echo "$@" > `+argsFile+`
+case "$*" in *--force*|*unarchive*) exit 0 ;; esac
+echo "Error: tasks b depend on it; remove the dependencies or use --force"
+exit 1
+`), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	routes := s.Routes()
+	post := func(url, body string) int {
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("POST", url, strings.NewReader(body)))
+		return w.Code
+	}
+
+	if code := post("/api/tasks/a/archive", `{"reason":"duplicate"}`); code != http.StatusConflict {
+		t.Errorf("Expected 409 with dependents, got %d", code)
+	}
+	if code := post("/api/tasks/a/archive", `{"reason":"duplicate","force":true}`); code != http.StatusOK {
+		t.Errorf("Expected 200 forced, got %d", code)
+	}
+	if args, _ := os.ReadFile(argsFile); strings.TrimSpace(string(args)) != "task archive a --reason duplicate --force" {
+		t.Errorf("mc args = %q", args)
+	}
+	if code := post("/api/tasks/a/unarchive", ""); code != http.StatusOK {
+		t.Errorf("Expected 200 unarchiving, got %d", code)
+	}
+}
+
 func TestWorkersWithoutTracker(t *testing.T) {
 	s, _ := newTestServer(t)
 	routes := s.Routes()
@@ -360,3 +486,618 @@ func TestWorkersWithTracker(t *testing.T) {
 		t.Errorf("Expected 404, got %d", w.Code)
 	}
 }
+
+func TestReportEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "stage.json"), []byte(`{"current":"implement"}`), 0644)
+	routes := s.Routes()
+
+	req := httptest.NewRequest("GET", "/api/report", nil)
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/markdown; charset=utf-8" {
+		t.Errorf("Expected markdown content type, got %s", ct)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("**Current stage:** implement")) {
+		t.Errorf("Expected current stage in report, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/report?format=html", nil)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected html content type, got %s", ct)
+	}
+
+	req = httptest.NewRequest("GET", "/api/report?format=pdf", nil)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown format, got %d", w.Code)
+	}
+}
+
+func TestSchedulesEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"),
+		[]byte(`{"schedules":[{"name":"nightly","cron":"0 2 * * *","task":"Audit dependencies"}]}`), 0644)
+
+	req := httptest.NewRequest("GET", "/api/schedules", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Schedules []struct {
+			Name    string `json:"name"`
+			NextRun string `json:"next_run"`
+		} `json:"schedules"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Schedules) != 1 || resp.Schedules[0].Name != "nightly" || !strings.Contains(resp.Schedules[0].NextRun, "T02:00:00") {
+		t.Errorf("Expected nightly with its next run, got %s", w.Body.String())
+	}
+}
+
+func TestBudgetForecastEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"budget":{"usd":10}}`), 0644)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(
+		`{"id":"t1","status":"done","estimate":2}`+"\n"+
+			`{"id":"t2","status":"pending","estimate":3}`+"\n"), 0644)
+	budget.Record(filepath.Join(dir, ".mission"), 1000, 4, time.Now())
+
+	req := httptest.NewRequest("GET", "/api/budgets/forecast", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var f budget.Forecast
+	json.Unmarshal(w.Body.Bytes(), &f)
+	// $2 a point, 3 points open
+	if f.Method != "backlog" || f.Projected.CostUSD != 10 || f.OverBudget {
+		t.Errorf("Expected a $10 projection within budget, got %s", w.Body.String())
+	}
+}
+
+func TestStateEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	stateDir := filepath.Join(dir, ".mission", "state")
+	os.WriteFile(filepath.Join(stateDir, "stage.json"), []byte(`{"current":"implement"}`), 0644)
+	os.WriteFile(filepath.Join(stateDir, "tasks.jsonl"), []byte(
+		`{"id":"t1","name":"API","status":"done"}`+"\n"+
+			`{"id":"t2","name":"UI","status":"pending","depends_on":["t1"]}`+"\n"), 0644)
+	os.WriteFile(filepath.Join(stateDir, "gates.json"), []byte(`{"implement":{"status":"pending"}}`), 0644)
+	os.WriteFile(filepath.Join(dir, ".mission", "orchestrator", "checkpoints", "cp-1.json"), []byte(`{"stage":"design"}`), 0644)
+	log := eventlog.New(0)
+	log.Append("task_updated", map[string]string{"id": "t1"})
+	log.Append("task_updated", map[string]string{"id": "t2"})
+	s.SetEventLog(log)
+
+	req := httptest.NewRequest("GET", "/api/state", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var snap StateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Version != 2 || !snap.Consistent {
+		t.Errorf("Expected a consistent snapshot at version 2, got %d, %v", snap.Version, snap.Consistent)
+	}
+	if stage, _ := snap.Stage.(map[string]interface{}); stage["current"] != "implement" {
+		t.Errorf("Unexpected stage %v", snap.Stage)
+	}
+	if len(snap.Tasks) != 2 || len(snap.Graph.Nodes) != 2 || len(snap.Graph.Edges) != 1 {
+		t.Errorf("Expected 2 tasks with one edge, got %d tasks, %d nodes, %d edges", len(snap.Tasks), len(snap.Graph.Nodes), len(snap.Graph.Edges))
+	}
+	if snap.Gates["implement"] == nil || len(snap.Checkpoints) != 1 || snap.Workers == nil {
+		t.Errorf("Unexpected snapshot: %s", w.Body.String())
+	}
+}
+
+func TestReleaseNotesEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"),
+		[]byte(`{"id":"t1","name":"Login form","stage":"implement","status":"complete","updated_at":"2026-02-01T00:00:00Z"}`+"\n"), 0644)
+	routes := s.Routes()
+
+	req := httptest.NewRequest("GET", "/api/release/notes?version=v1.0.0", nil)
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(": v1.0.0")) || !bytes.Contains(w.Body.Bytes(), []byte("`t1` Login form")) {
+		t.Errorf("Expected version and task in notes, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/release/notes?format=json", nil)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	var notes struct {
+		Tasks []struct {
+			ID string `json:"id"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &notes); err != nil || len(notes.Tasks) != 1 {
+		t.Errorf("Expected one task in JSON notes, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/release/notes?since=v0.0.1", nil)
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown since tag, got %d", w.Code)
+	}
+}
+
+func TestReleaseNotesRedactsSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	store, err := secrets.Open(filepath.Join(home, ".mission-control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("API_TOKEN", "tok-12345"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"),
+		[]byte(`{"id":"t1","name":"Rotate tok-12345","stage":"implement","status":"complete","updated_at":"2026-02-01T00:00:00Z"}`+"\n"), 0644)
+	routes := s.Routes()
+
+	for _, format := range []string{"md", "json"} {
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/release/notes?format="+format, nil))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "tok-12345") || !strings.Contains(w.Body.String(), secrets.Mask) {
+			t.Errorf("%s: expected the secret redacted, got %d: %s", format, w.Code, w.Body.String())
+		}
+	}
+}
+
+type capturedEvent struct {
+	requestID, topic, eventType string
+}
+
+type requestHub struct {
+	events []capturedEvent
+}
+
+func (h *requestHub) BroadcastRaw(topic, eventType string, data interface{}) {
+	h.events = append(h.events, capturedEvent{"", topic, eventType})
+}
+
+func (h *requestHub) BroadcastRawWithRequestID(requestID, topic, eventType string, data interface{}) {
+	h.events = append(h.events, capturedEvent{requestID, topic, eventType})
+}
+
+// installFakeMC puts an mc stub on PATH that echoes MC_REQUEST_ID.
+func installFakeMC(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"request=$MC_REQUEST_ID\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "mc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGateApproveCorrelatesRequestID(t *testing.T) {
+	installFakeMC(t)
+	dir := t.TempDir()
+	hub := &requestHub{}
+	s := NewServer(dir, hub, nil, nil)
+	handler := RequestIDMiddleware(s.Routes())
+
+	req := httptest.NewRequest("POST", "/api/gates/implement/approve", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result CommandResult
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Output != "request=req-42" {
+		t.Errorf("Expected mc to receive MC_REQUEST_ID, got output %q", result.Output)
+	}
+	if len(hub.events) != 1 || hub.events[0].requestID != "req-42" || hub.events[0].eventType != "gate_approved" {
+		t.Errorf("Expected gate_approved broadcast tagged with req-42, got %+v", hub.events)
+	}
+}
+
+func TestGateApprovePassesUserToMC(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "mc"), []byte("#!/bin/sh\necho \"user=$MC_USER\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("MC_API_TOKEN", "")
+	t.Setenv("MC_API_TOKENS", "Alice <alice@example.com>=alice-token")
+	s := NewServer(t.TempDir(), nil, nil, nil)
+	handler := AuthMiddleware(s.Routes())
+
+	req := httptest.NewRequest("POST", "/api/gates/implement/approve", nil)
+	req.Header.Set("Authorization", "Bearer alice-token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	var result CommandResult
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Output != "user=Alice <alice@example.com>" {
+		t.Errorf("Expected mc to act for alice, got %q", result.Output)
+	}
+
+	// Requests naming no one are attributed to "api"
+	t.Setenv("MC_API_TOKENS", "")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/gates/implement/approve", nil))
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Output != "user=api" {
+		t.Errorf("Expected user=api, got %q", result.Output)
+	}
+}
+
+func TestErrorsAreProblemJSON(t *testing.T) {
+	s, _ := newTestServer(t)
+	routes := Chain(s.Routes(), RequestIDMiddleware)
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		code               string
+	}{
+		{"GET", "/api/tasks/missing", "", http.StatusNotFound, "not_found"},
+		{"DELETE", "/api/tasks", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"POST", "/api/tasks", "{", http.StatusBadRequest, "invalid_body"},
+		{"POST", "/api/tasks", `{"title":""}`, http.StatusBadRequest, "validation_failed"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+		req.Header.Set(RequestIDHeader, "problem-test")
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, w.Code, tt.status)
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s %s: Content-Type = %q", tt.method, tt.path, ct)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if body["code"] != tt.code {
+			t.Errorf("%s %s: code = %v, want %s", tt.method, tt.path, body["code"], tt.code)
+		}
+		if body["request_id"] != "problem-test" {
+			t.Errorf("%s %s: request_id = %v", tt.method, tt.path, body["request_id"])
+		}
+		if body["error"] == "" || body["error"] != body["message"] {
+			t.Errorf("%s %s: legacy error field = %v", tt.method, tt.path, body["error"])
+		}
+	}
+}
+
+func TestTasksPagination(t *testing.T) {
+	s, dir := newTestServer(t)
+	tasksFile := filepath.Join(dir, ".mission", "state", "tasks.jsonl")
+	data := `{"id":"c","name":"Gamma","priority":2}
+{"id":"a","name":"Alpha","priority":10}
+{"id":"b","name":"Beta","priority":2}
+`
+	os.WriteFile(tasksFile, []byte(data), 0644)
+	routes := s.Routes()
+
+	type envelope struct {
+		Tasks      []map[string]interface{} `json:"tasks"`
+		Total      int                      `json:"total"`
+		Limit      int                      `json:"limit"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	get := func(url string) envelope {
+		t.Helper()
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", url, w.Code, w.Body.String())
+		}
+		var env envelope
+		if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil {
+			t.Fatalf("%s: %v", url, err)
+		}
+		return env
+	}
+
+	// Numeric sort with id tie-break: b(2), c(2), a(10).
+	env := get("/api/tasks?sort=priority&limit=2")
+	if env.Total != 3 || len(env.Tasks) != 2 || env.NextCursor == "" {
+		t.Fatalf("first page = %+v", env)
+	}
+	if env.Tasks[0]["id"] != "b" || env.Tasks[1]["id"] != "c" {
+		t.Errorf("first page order = %v, %v", env.Tasks[0]["id"], env.Tasks[1]["id"])
+	}
+
+	env = get("/api/tasks?sort=priority&limit=2&cursor=" + env.NextCursor)
+	if len(env.Tasks) != 1 || env.Tasks[0]["id"] != "a" || env.NextCursor != "" {
+		t.Errorf("second page = %+v", env)
+	}
+
+	env = get("/api/tasks?sort=-name")
+	if env.Tasks[0]["name"] != "Gamma" || env.Limit != defaultPageLimit {
+		t.Errorf("descending sort = %+v", env)
+	}
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks?cursor=bogus", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor: expected 400, got %d", w.Code)
+	}
+}
+
+func TestFindingsEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	findingsDir := filepath.Join(dir, ".mission", "findings")
+	os.MkdirAll(findingsDir, 0755)
+	os.WriteFile(filepath.Join(findingsDir, "t1.json"),
+		[]byte(`[{"type":"discovery","summary":"one","severity":"high"},{"type":"blocker","summary":"two","severity":"low"}]`), 0644)
+	os.WriteFile(filepath.Join(findingsDir, "t2.json"),
+		[]byte(`[{"type":"discovery","summary":"three","severity":"high"}]`), 0644)
+	os.WriteFile(filepath.Join(findingsDir, "t2.md"), []byte("# notes"), 0644)
+	routes := s.Routes()
+
+	var env struct {
+		Findings   []map[string]interface{} `json:"findings"`
+		Total      int                      `json:"total"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/findings?limit=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if env.Total != 3 || len(env.Findings) != 2 || env.NextCursor == "" {
+		t.Fatalf("page = %+v", env)
+	}
+	if env.Findings[0]["task_id"] != "t1" || env.Findings[0]["summary"] != "one" {
+		t.Errorf("first finding = %v", env.Findings[0])
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/findings?severity=high&task=t2", nil))
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if env.Total != 1 || env.Findings[0]["summary"] != "three" {
+		t.Errorf("filtered = %+v", env)
+	}
+}
+
+func TestTaskBriefingBudget(t *testing.T) {
+	s, dir := newTestServer(t)
+	mission := filepath.Join(dir, ".mission")
+	os.WriteFile(filepath.Join(mission, "state", "tasks.jsonl"), []byte(
+		`{"id":"t1","name":"Schema","stage":"design","zone":"backend","status":"done"}`+"\n"+
+			`{"id":"t2","name":"Handlers","stage":"implement","zone":"backend","status":"pending","depends_on":["t1"]}`+"\n"), 0644)
+	os.MkdirAll(filepath.Join(mission, "findings"), 0755)
+	os.WriteFile(filepath.Join(mission, "findings", "t1.json"), []byte(`[{"type":"discovery","summary":"Users have emails","severity":"high"}]`), 0644)
+	routes := s.Routes()
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks/t2/briefing?budget=500", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var pack struct {
+		Budget   int    `json:"budget"`
+		Briefing string `json:"briefing"`
+		Included []struct {
+			Kind   string `json:"kind"`
+			Source string `json:"source"`
+		} `json:"included"`
+		Omitted []interface{} `json:"omitted"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &pack)
+	if pack.Budget != 500 || len(pack.Included) != 2 || pack.Included[1].Source != "t1" || !strings.Contains(pack.Briefing, "Users have emails") {
+		t.Errorf("pack = %+v", pack)
+	}
+	if pack.Omitted == nil {
+		t.Errorf("omitted should be an empty list, not null")
+	}
+
+	for path, code := range map[string]int{
+		"/api/tasks/t2/briefing?budget=-1":  http.StatusBadRequest,
+		"/api/tasks/t9/briefing?budget=100": http.StatusNotFound,
+		"/api/tasks/t2/briefing":            http.StatusNotFound, // no stored briefing
+	} {
+		w = httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != code {
+			t.Errorf("%s: expected %d, got %d", path, code, w.Code)
+		}
+	}
+
+	// Offline models are counted with the llama tokenizer estimate
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks/t2/briefing?budget=500&model=ollama:llama3.1:8b", nil))
+	var llama struct {
+		Tokens   int    `json:"tokens"`
+		Briefing string `json:"briefing"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &llama)
+	if want := core.EstimateTokens(llama.Briefing, core.EncodingLlama); llama.Tokens != want {
+		t.Errorf("llama tokens = %d, want %d", llama.Tokens, want)
+	}
+}
+
+func TestConversationEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "conversation.md"), []byte(
+		"## Human\n\none\n\n---\n\n## Assistant\n\ntwo\n\n---END---\n\n## Human\n\nthree\n\n---\n"), 0644)
+	routes := s.Routes()
+
+	var env struct {
+		Messages   []map[string]interface{} `json:"messages"`
+		Exchanges  []map[string]interface{} `json:"exchanges"`
+		Total      int                      `json:"total"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/conversation?limit=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if env.Total != 3 || len(env.Messages) != 2 || env.NextCursor == "" || env.Messages[1]["content"] != "two" {
+		t.Fatalf("page = %+v", env)
+	}
+
+	env.Messages = nil
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/conversation?role=human&sort=-id", nil))
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if env.Total != 2 || env.Messages[0]["content"] != "three" {
+		t.Errorf("filtered = %+v", env)
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/conversation?view=exchanges", nil))
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if env.Total != 2 || env.Exchanges[0]["complete"] != true || env.Exchanges[1]["complete"] != false {
+		t.Errorf("exchanges = %+v", env.Exchanges)
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/conversation?view=bogus", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad view: expected 400, got %d", w.Code)
+	}
+}
+
+func TestChatHistoryEndpoint(t *testing.T) {
+	s, dir := newTestServer(t)
+	store := chat.NewStore(filepath.Join(dir, ".mission"))
+	for _, c := range []string{"a", "b", "c"} {
+		store.Append(chat.Message{Session: "webchat", Role: "user", Content: c})
+	}
+	store.Append(chat.Message{Session: "king", Role: "user", Content: "k"})
+	routes := s.Routes()
+
+	type historyPage struct {
+		Messages   []chat.Message `json:"messages"`
+		HasMore    bool           `json:"has_more"`
+		NextBefore int64          `json:"next_before"`
+	}
+	var env historyPage
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/chat/history?session=webchat&limit=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if !env.HasMore || env.NextBefore != 2 || len(env.Messages) != 2 || env.Messages[1].Content != "c" {
+		t.Fatalf("first page = %+v", env)
+	}
+
+	env = historyPage{}
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/chat/history?session=webchat&limit=2&before=2", nil))
+	json.Unmarshal(w.Body.Bytes(), &env)
+	if env.HasMore || len(env.Messages) != 1 || env.Messages[0].Content != "a" {
+		t.Errorf("second page = %+v", env)
+	}
+
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/api/chat/history?before=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad before: expected 400, got %d", w.Code)
+	}
+}
+
+func TestBulkCreateTasks(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(`{"id":"a","status":"pending"}`+"\n"), 0644)
+
+	// A stand-in mc that records its arguments and stdin
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > \"" + filepath.Join(bin, "args") + "\"\ncat > \"" + filepath.Join(bin, "stdin") + "\"\necho imported\n"
+	if err := os.WriteFile(filepath.Join(bin, "mc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`[]`, http.StatusBadRequest},
+		{`{"title":"x"}`, http.StatusBadRequest},
+		{`[{"title":"x"},{"title":" "}]`, http.StatusBadRequest},
+		{`[{"title":"x","estimate":-1}]`, http.StatusBadRequest},
+		{`[{"title":"x","depends_on":["nope"]}]`, http.StatusBadRequest},
+		{`[{"title":"x","depends_on":["a"]},{"title":"y","persona":"developer"}]`, http.StatusCreated},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("POST", "/api/tasks/bulk", strings.NewReader(c.body)))
+		if w.Code != c.want {
+			t.Errorf("%s: expected %d, got %d: %s", c.body, c.want, w.Code, w.Body.String())
+		}
+	}
+
+	args, _ := os.ReadFile(filepath.Join(bin, "args"))
+	if strings.TrimSpace(string(args)) != "task import - --format json" {
+		t.Errorf("mc args = %q", args)
+	}
+	var sent []CreateTaskRequest
+	data, _ := os.ReadFile(filepath.Join(bin, "stdin"))
+	if err := json.Unmarshal(data, &sent); err != nil || len(sent) != 2 || sent[1].Persona != "developer" {
+		t.Errorf("mc stdin = %s", data)
+	}
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/tasks/bulk", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", w.Code)
+	}
+}
+
+func TestStageRollbackBroadcasts(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\"\nprintf '{\"current\":\"design\",\"rolled_back_from\":\"implement\"}' > .mission/state/stage.json\n"
+	if err := os.WriteFile(filepath.Join(bin, "mc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	_, dir := newTestServer(t)
+	hub := &requestHub{}
+	handler := NewServer(dir, hub, nil, nil).Routes()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/stages/rollback", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a reason, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/stages/rollback", strings.NewReader(`{"reason": "too early"}`)))
+	var result CommandResult
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || result.Output != "stage rollback --reason too early" {
+		t.Fatalf("Expected mc stage rollback, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(hub.events) != 1 || hub.events[0].eventType != "stage_rolled_back" {
+		t.Errorf("Expected a stage_rolled_back broadcast, got %+v", hub.events)
+	}
+}
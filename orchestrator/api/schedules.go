@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/schedule"
+)
+
+// handleSchedules serves GET /api/schedules: the recurring tasks in
+// config.json with their next and last runs.
+func (s *Server) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schedules": schedule.List(s.missionPath(), time.Now()),
+	})
+}
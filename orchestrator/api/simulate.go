@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/simulate"
+)
+
+// intParam parses a non-negative integer query parameter, 0 if absent.
+func intParam(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		problem.Validation(w, name+" must be a non-negative integer")
+		return 0, false
+	}
+	return n, true
+}
+
+// handleSimulate serves GET /api/simulate: a dry-run schedule of the
+// remaining tasks, optionally with ?max_workers= and ?tokens_per_unit=
+// overriding the mission's settings. Nothing is spawned.
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	maxWorkers, ok := intParam(w, r, "max_workers")
+	if !ok {
+		return
+	}
+	perUnit, ok := intParam(w, r, "tokens_per_unit")
+	if !ok {
+		return
+	}
+	res, err := simulate.Run(s.missionPath(), simulate.Options{MaxWorkers: maxWorkers, TokensPerUnit: perUnit})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
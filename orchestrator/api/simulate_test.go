@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/simulate"
+)
+
+func TestSimulate(t *testing.T) {
+	s, dir := newTestServer(t)
+	tasks := `{"id":"a","stage":"implement","status":"pending","estimate":2}
+{"id":"b","stage":"implement","status":"pending","estimate":3}
+{"id":"c","stage":"verify","status":"pending","depends_on":["a"]}
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), []byte(tasks), 0644); err != nil {
+		t.Fatal(err)
+	}
+	handler := s.Routes()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	var res simulate.Result
+	w := get("/api/simulate")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if res.Duration != 4 || len(res.Stages) != 2 || res.Tokens != 6*simulate.DefaultTokensPerUnit {
+		t.Errorf("Unexpected simulation: %+v", res)
+	}
+
+	json.Unmarshal(get("/api/simulate?max_workers=1&tokens_per_unit=10").Body.Bytes(), &res)
+	if res.Duration != 6 || res.Tokens != 60 || len(res.Bottlenecks) != 1 {
+		t.Errorf("Unexpected simulation with one worker: %+v", res)
+	}
+
+	if w := get("/api/simulate?max_workers=lots"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad max_workers, got %d", w.Code)
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+)
+
+// SpecActionRequest is the optional body of POST /api/specs/{id}/{action}.
+type SpecActionRequest struct {
+	Note string `json:"note,omitempty"`
+	By   string `json:"by,omitempty"` // supersede: the replacing spec
+}
+
+// specActions map the actions of POST /api/specs/{id}/{action} to the
+// mc spec subcommand and the role they need. Moving a spec out of review
+// or out of approval is a review decision; submitting is not.
+var specActions = map[string]string{
+	"submit":    identity.RoleContributor,
+	"approve":   identity.RoleApprover,
+	"reject":    identity.RoleApprover,
+	"supersede": identity.RoleApprover,
+}
+
+// handleSpecAction serves POST /api/specs/{id}/{submit|approve|reject|supersede}
+// through mc spec in the caller's name, and broadcasts spec_status_changed.
+func (s *Server) handleSpecAction(w http.ResponseWriter, r *http.Request, id, action string) {
+	role, ok := specActions[action]
+	if !ok {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !allowRole(w, r, s.getMissionDir(), role) {
+		return
+	}
+	var req SpecActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		problem.InvalidBody(w, err)
+		return
+	}
+	if action == "reject" && strings.TrimSpace(req.Note) == "" {
+		problem.Validation(w, "note is required to reject a spec")
+		return
+	}
+	before, err := specs.Get(s.missionPath(), id)
+	if errors.Is(err, specs.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "spec not found")
+		return
+	}
+	if err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+
+	args := []string{"spec", action, id}
+	if req.Note != "" {
+		args = append(args, "--note", req.Note)
+	}
+	if action == "supersede" && req.By != "" {
+		args = append(args, "--by", req.By)
+	}
+	out, err := s.runMC(r.Context(), args...)
+	if err != nil {
+		if strings.Contains(out, specs.ErrTransition.Error()) {
+			problem.Write(w, http.StatusConflict, problem.CodeConflict, "spec "+id+" is "+before.Status, map[string]interface{}{"output": strings.TrimSpace(out)})
+			return
+		}
+		respondCommandError(w, "mc spec "+action+" failed", out)
+		return
+	}
+	spec, err := specs.Get(s.missionPath(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.broadcast(r.Context(), "spec", "spec_status_changed", map[string]interface{}{
+		"spec": spec,
+		"from": before.Status,
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"spec": spec})
+}
+
+// SpecRevision is a commit that touched a spec file.
+type SpecRevision struct {
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+}
+
+// SpecDiffResponse is the response for GET /api/specs/{id}/diff.
+type SpecDiffResponse struct {
+	Spec      string         `json:"spec"`
+	From      string         `json:"from"`
+	To        string         `json:"to,omitempty"` // empty: the working tree
+	Diff      string         `json:"diff"`
+	Changed   bool           `json:"changed"`
+	Revisions []SpecRevision `json:"revisions"` // newest first, at most maxSpecRevisions
+}
+
+const maxSpecRevisions = 20
+
+// handleSpecDiff serves GET /api/specs/{id}/diff?from=<rev>&to=<rev> from
+// the git history of the spec file. from defaults to HEAD and to to the
+// working tree, so by default it shows uncommitted edits.
+func (s *Server) handleSpecDiff(w http.ResponseWriter, r *http.Request, id string) {
+	if !validateTaskID(id) {
+		respondError(w, http.StatusBadRequest, "invalid spec ID")
+		return
+	}
+	dir := s.missionPath("specs")
+	file := id + ".md"
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(r.Context(), "git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		return string(out), err
+	}
+	if _, err := git("rev-parse", "--is-inside-work-tree"); err != nil {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, "spec history needs the project to be a git repository", nil)
+		return
+	}
+
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	if from == "" {
+		from = "HEAD"
+	}
+	for _, rev := range []string{from, to} {
+		if rev == "" {
+			continue
+		}
+		if strings.HasPrefix(rev, "-") {
+			problem.Validation(w, "invalid revision: "+rev)
+			return
+		}
+		if _, err := git("rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+			problem.Validation(w, "unknown revision: "+rev)
+			return
+		}
+	}
+
+	history, _ := git("log", "-n", strconv.Itoa(maxSpecRevisions), "--format=%H%x09%an%x09%aI%x09%s", "--", file)
+	revisions := []SpecRevision{}
+	for _, line := range strings.Split(strings.TrimSpace(history), "\n") {
+		f := strings.SplitN(line, "\t", 4)
+		if len(f) == 4 {
+			revisions = append(revisions, SpecRevision{Commit: f[0], Author: f[1], Date: f[2], Subject: f[3]})
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, file)); os.IsNotExist(err) && len(revisions) == 0 {
+		respondError(w, http.StatusNotFound, "spec not found")
+		return
+	}
+
+	args := []string{"diff", "--no-color", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	diff, err := git(append(args, "--", file)...)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "git diff failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, SpecDiffResponse{
+		Spec:      id,
+		From:      from,
+		To:        to,
+		Diff:      diff,
+		Changed:   diff != "",
+		Revisions: revisions,
+	})
+}
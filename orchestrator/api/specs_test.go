@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+)
+
+func TestSpecStatusAndStaleTasks(t *testing.T) {
+	s, dir := newTestServer(t)
+	missionDir := filepath.Join(dir, ".mission")
+	os.MkdirAll(filepath.Join(missionDir, "specs"), 0755)
+	os.WriteFile(specs.Path(missionDir, "login"), []byte("# Login\n\nv1\n"), 0644)
+	specs.Transition(missionDir, "login", specs.StatusReview, "bob", "", "")
+	v1, err := specs.Transition(missionDir, "login", specs.StatusApproved, "alice", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := `{"id":"t1","name":"form","status":"pending","spec":"login","spec_hash":"` + v1.Hash + `"}` + "\n"
+	os.WriteFile(filepath.Join(missionDir, "state", "tasks.jsonl"), []byte(tasks), 0644)
+
+	get := func() SpecInfo {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/specs", nil))
+		var list []SpecInfo
+		json.Unmarshal(w.Body.Bytes(), &list)
+		if len(list) != 1 {
+			t.Fatalf("%d: %s", w.Code, w.Body.String())
+		}
+		return list[0]
+	}
+	if info := get(); info.Status != specs.StatusApproved || info.Version != 1 || info.Modified || len(info.StaleTasks) != 0 {
+		t.Fatalf("approved spec = %+v", info)
+	}
+
+	os.WriteFile(specs.Path(missionDir, "login"), []byte("# Login\n\nv2\n"), 0644)
+	if info := get(); !info.Modified || len(info.StaleTasks) != 1 || info.StaleTasks[0] != "t1" {
+		t.Errorf("edited spec = %+v", info)
+	}
+}
+
+func TestSpecActionValidation(t *testing.T) {
+	s, dir := newTestServer(t)
+	os.MkdirAll(filepath.Join(dir, ".mission", "specs"), 0755)
+	os.WriteFile(specs.Path(filepath.Join(dir, ".mission"), "login"), []byte("# Login\n"), 0644)
+
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{"POST", "/api/specs/login/publish", "", http.StatusNotFound},
+		{"POST", "/api/specs/nope/submit", "", http.StatusNotFound},
+		{"POST", "/api/specs/login/reject", `{"note":" "}`, http.StatusBadRequest},
+		{"GET", "/api/specs/login/approve", "", http.StatusMethodNotAllowed},
+	} {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+		if w.Code != tc.want {
+			t.Errorf("%s %s: %d, want %d: %s", tc.method, tc.path, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestSpecDiff(t *testing.T) {
+	s, dir := newTestServer(t)
+	missionDir := filepath.Join(dir, ".mission")
+	os.MkdirAll(filepath.Join(missionDir, "specs"), 0755)
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@test.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	get := func(query string) (*httptest.ResponseRecorder, SpecDiffResponse) {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest("GET", "/api/specs/login/diff"+query, nil))
+		var resp SpecDiffResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	if w, _ := get(""); w.Code != http.StatusConflict {
+		t.Errorf("outside git: %d", w.Code)
+	}
+
+	git("init", "-q")
+	os.WriteFile(specs.Path(missionDir, "login"), []byte("# Login\n\nEmail only.\n"), 0644)
+	git("add", "-A")
+	git("commit", "-qm", "login v1")
+	v1 := git("rev-parse", "HEAD")
+	os.WriteFile(specs.Path(missionDir, "login"), []byte("# Login\n\nEmail and 2FA.\n"), 0644)
+
+	// Default: uncommitted edits against HEAD
+	w, resp := get("")
+	if w.Code != http.StatusOK || !resp.Changed || !strings.Contains(resp.Diff, "+Email and 2FA.") || len(resp.Revisions) != 1 {
+		t.Fatalf("%d: %+v", w.Code, resp)
+	}
+
+	git("commit", "-qam", "login v2")
+	if _, resp := get(""); resp.Changed {
+		t.Errorf("committed spec still differs from HEAD: %s", resp.Diff)
+	}
+	w, resp = get("?from=" + v1 + "&to=HEAD")
+	if w.Code != http.StatusOK || !strings.Contains(resp.Diff, "-Email only.") || len(resp.Revisions) != 2 || resp.Revisions[0].Subject != "login v2" {
+		t.Errorf("%d: %+v", w.Code, resp)
+	}
+
+	for _, q := range []string{"?from=nope", "?from=--output=x"} {
+		if w, _ := get(q); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: %d", q, w.Code)
+		}
+	}
+}
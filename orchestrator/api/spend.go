@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+)
+
+// MaxSpendOverrideHours caps how long an override lasts.
+const MaxSpendOverrideHours = 7 * 24
+
+// SpendOverrideRequest is the body of POST /api/spend/override. Without
+// Hours the override lasts until the end of the UTC day.
+type SpendOverrideRequest struct {
+	Reason string `json:"reason"`
+	Hours  int    `json:"hours,omitempty"`
+}
+
+// handleSpend serves GET /api/spend: the global spend limits, today's and
+// the week's usage, and whether the breaker is tripped.
+func (s *Server) handleSpend(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, spend.Current(spend.DefaultDir(), time.Now()))
+}
+
+// handleSpendOverride serves POST /api/spend/override, which lets workers
+// spawn and King messages through past the limits, and DELETE, which
+// ends the override. Both need the admin role and are audited.
+func (s *Server) handleSpendOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.MethodNotAllowed(w)
+		return
+	}
+	if !allowRole(w, r, s.getMissionDir(), identity.RoleAdmin) {
+		return
+	}
+	user := UserFromContext(r.Context()).String()
+	now := time.Now()
+
+	var st spend.Status
+	var err error
+	details := map[string]interface{}{}
+	action := "spend_override_cleared"
+	if r.Method == http.MethodPost {
+		var req SpendOverrideRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			problem.InvalidBody(w, err)
+			return
+		}
+		if strings.TrimSpace(req.Reason) == "" {
+			problem.Validation(w, "reason is required")
+			return
+		}
+		if req.Hours < 0 || req.Hours > MaxSpendOverrideHours {
+			problem.Validation(w, "hours must be between 1 and 168")
+			return
+		}
+		until := spend.EndOfDay(now)
+		if req.Hours > 0 {
+			until = now.Add(time.Duration(req.Hours) * time.Hour)
+		}
+		st, err = spend.SetOverride(spend.DefaultDir(), user, req.Reason, until, now)
+		action = "spend_override_set"
+		details["reason"], details["until"] = req.Reason, until.UTC().Format(time.RFC3339)
+	} else {
+		st, err = spend.ClearOverride(spend.DefaultDir(), now)
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	details["exceeded"] = st.Exceeded
+	err = audit.Append(s.missionPath(), audit.Entry{
+		Action:    action,
+		Actor:     "api",
+		User:      user,
+		Category:  "spend",
+		RequestID: RequestIDFromContext(r.Context()),
+		Details:   details,
+	})
+	if err != nil {
+		log.Printf("Warning: auditing %s: %v", action, err)
+	}
+	s.broadcast(r.Context(), "token", action, st)
+	writeJSON(w, http.StatusOK, st)
+}
+
+// spendBlocked writes a 429 and returns true while the spend limits'
+// breaker is tripped.
+func spendBlocked(w http.ResponseWriter) bool {
+	err := spend.Check(spend.DefaultDir(), time.Now())
+	if err == nil {
+		return false
+	}
+	problem.Write(w, http.StatusTooManyRequests, problem.CodeSpendLimit, err.Error(), nil)
+	return true
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+)
+
+func TestSpendLimitPausesKingUntilOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".mission-control"), 0755)
+	os.WriteFile(filepath.Join(home, ".mission-control", "config.json"), []byte(`{"spendLimits":{"dailyTokens":100}}`), 0644)
+	if _, tripped, _ := spend.Record(spend.DefaultDir(), 150, 0.5, time.Now()); !tripped {
+		t.Fatal("expected the breaker to trip")
+	}
+
+	s, _ := newTestServer(t)
+	sent := 0
+	s.SetKing(func(message string) error {
+		sent++
+		return nil
+	})
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("POST", "/api/king/message", `{"content": "Carry on"}`)
+	if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "spend_limit_exceeded") || sent != 0 {
+		t.Fatalf("Expected 429 spend_limit_exceeded, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var st spend.Status
+	json.Unmarshal(do("GET", "/api/spend", "").Body.Bytes(), &st)
+	if !st.Tripped || st.Today.Tokens != 150 {
+		t.Errorf("Expected a tripped breaker with 150 tokens today, got %+v", st)
+	}
+
+	if w := do("POST", "/api/spend/override", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a reason, got %d", w.Code)
+	}
+	if w := do("POST", "/api/spend/override", `{"reason": "release day", "hours": 2}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/king/message", `{"content": "Carry on"}`); w.Code != http.StatusOK || sent != 1 {
+		t.Errorf("Expected the message through under the override, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := do("DELETE", "/api/spend/override", ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/king/message", `{"content": "Carry on"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 once the override ended, got %d", w.Code)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// stateAttempts is how many times GET /api/state reads the state files
+// before it settles for a snapshot taken while they were changing.
+const stateAttempts = 3
+
+// StateResponse is the response for GET /api/state: everything the
+// dashboard needs to boot, read at one point in time.
+type StateResponse struct {
+	// Version is the event log position the snapshot reflects. Apply
+	// WebSocket events with a higher seq on top of it, or replay the
+	// gap from /api/notifications?since=<version>.
+	Version     uint64                   `json:"version"`
+	Consistent  bool                     `json:"consistent"` // no state file changed while the snapshot was read
+	Stage       interface{}              `json:"stage"`
+	Tasks       []Task                   `json:"tasks"`
+	Gates       map[string]interface{}   `json:"gates"`
+	Zones       []bridge.Zone            `json:"zones"`
+	Graph       GraphResponse            `json:"graph"`
+	Checkpoints []map[string]interface{} `json:"checkpoints"`
+	Workers     interface{}              `json:"workers"`
+	Tokens      *tokens.TokenSummary     `json:"tokens,omitempty"`
+	GeneratedAt string                   `json:"generated_at"`
+}
+
+// stateFiles are the files a state snapshot is read from.
+func (s *Server) stateFiles() []string {
+	return append(s.stateSources("stage.json", "stages.jsonl", "tasks.jsonl", "gates.json", "zones.json")(), s.checkpointSources()...)
+}
+
+// handleState serves GET /api/state. The state files are read, then
+// checked unchanged; if a writer got in between, they are read again.
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	var snap StateResponse
+	for i := 0; i < stateAttempts && !snap.Consistent; i++ {
+		before := fingerprintFiles(s.stateFiles())
+		snap = s.readState()
+		snap.Consistent = fingerprintFiles(s.stateFiles()) == before
+	}
+	writeJSON(w, http.StatusOK, snap)
+}
+
+// readState reads one snapshot. The version is taken first, so events
+// that land during the read are replayed on top rather than lost.
+func (s *Server) readState() StateResponse {
+	var snap StateResponse
+	s.mu.RLock()
+	if s.events != nil {
+		snap.Version = s.events.Last()
+	}
+	s.mu.RUnlock()
+
+	snap.Stage = s.readStage()
+	snap.Tasks, _ = s.readTasks()
+	if snap.Tasks == nil {
+		snap.Tasks = []Task{}
+	}
+	if err := readJSON(s.statePath("gates.json"), &snap.Gates); err != nil || snap.Gates == nil {
+		snap.Gates = map[string]interface{}{}
+	}
+	snap.Zones = s.loadZones(snap.Tasks)
+	snap.Graph = s.graphCache().Graph(s.getMissionDir())
+	snap.Checkpoints = s.loadCheckpoints()
+	if s.tracker != nil {
+		snap.Workers = s.tracker.List()
+	} else {
+		snap.Workers = []interface{}{}
+	}
+	if s.tokens != nil {
+		summary := s.tokens.Summary()
+		snap.Tokens = &summary
+	}
+	snap.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+	return snap
+}
+
+// readStage reads stage.json, falling back to the last entry of
+// stages.jsonl, or nil when there is neither.
+func (s *Server) readStage() interface{} {
+	var stage map[string]interface{}
+	if err := readJSON(s.statePath("stage.json"), &stage); err == nil {
+		return stage
+	}
+	if entries, err := readJSONL(s.statePath("stages.jsonl")); err == nil && len(entries) > 0 {
+		return entries[len(entries)-1]
+	}
+	return nil
+}
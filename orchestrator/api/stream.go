@@ -0,0 +1,206 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// DefaultMaxResponseBytes caps how much of a findings, briefing or
+// checkpoint file one response carries; larger files are fetched in
+// byte ranges.
+const DefaultMaxResponseBytes = 32 << 20
+
+// SetMaxResponseBytes sets the cap on file responses. Zero restores the
+// default; a negative n lifts the cap.
+func (s *Server) SetMaxResponseBytes(n int64) {
+	s.mu.Lock()
+	s.maxResponse = n
+	s.mu.Unlock()
+}
+
+func (s *Server) maxResponseBytes() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.maxResponse == 0 {
+		return DefaultMaxResponseBytes
+	}
+	return s.maxResponse
+}
+
+// serveFile streams the file at path as contentType without reading it
+// into memory. Range, If-Range and If-Modified-Since are honoured, and a
+// response over the size cap is refused with 413 and the file's size, so
+// the client can ask for ranges instead. what names the file in errors.
+// check, if set, vets the file before it is served.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, path, contentType, what string, check func(io.Reader) error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			respondError(w, http.StatusNotFound, what+" not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to read "+what)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		respondError(w, http.StatusInternalServerError, "failed to read "+what)
+		return
+	}
+
+	if limit := s.maxResponseBytes(); limit > 0 {
+		if n := responseBytes(r, info); n > limit {
+			problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeResponseTooLarge,
+				fmt.Sprintf("%s is %d bytes, over the %d byte response limit; request it in byte ranges", what, info.Size(), limit),
+				map[string]interface{}{"size": info.Size(), "max_bytes": limit})
+			return
+		}
+	}
+
+	if check != nil {
+		if err := check(f); err != nil {
+			respondError(w, http.StatusInternalServerError, fmt.Sprintf("%s is invalid: %v", what, err))
+			return
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to read "+what)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+// validJSON reads r through, reporting the first syntax error, without
+// holding the document in memory.
+func validJSON(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := skipValue(dec); err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the document")
+	}
+	return nil
+}
+
+// responseBytes is how many bytes of the file r would be sent: the
+// ranges asked for, or the whole file when there are none, they don't
+// parse or If-Range doesn't match.
+func responseBytes(r *http.Request, info os.FileInfo) int64 {
+	size := info.Size()
+	spec := r.Header.Get("Range")
+	if spec == "" || !strings.HasPrefix(spec, "bytes=") {
+		return size
+	}
+	if ir := r.Header.Get("If-Range"); ir != "" {
+		t, err := http.ParseTime(ir)
+		if err != nil || info.ModTime().Truncate(time.Second).After(t) {
+			return size
+		}
+	}
+	var total int64
+	for _, part := range strings.Split(strings.TrimPrefix(spec, "bytes="), ",") {
+		first, last, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return size
+		}
+		if first == "" {
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil {
+				return size
+			}
+			total += min(n, size)
+			continue
+		}
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil {
+			return size
+		}
+		end := size - 1
+		if last != "" {
+			if end, err = strconv.ParseInt(last, 10, 64); err != nil {
+				return size
+			}
+			end = min(end, size-1)
+		}
+		if end >= start {
+			total += end - start + 1
+		}
+	}
+	return min(total, size)
+}
+
+// checkpointSummary reads the list fields of the checkpoint at path, token
+// by token, so the task snapshot it carries is never held in memory.
+func checkpointSummary(path string) (map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	summary := map[string]interface{}{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := t.(string)
+		switch key {
+		case "stage", "task_count", "auto":
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return nil, err
+			}
+			summary[key] = v
+		default:
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err // cut off before the closing brace
+	}
+	return summary, nil
+}
+
+// skipValue consumes the next value from dec, however deeply nested.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err == io.EOF && depth > 0 {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
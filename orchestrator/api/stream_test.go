@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTaskFindingsStreamsRanges(t *testing.T) {
+	s, dir := newTestServer(t)
+	findingsDir := filepath.Join(dir, ".mission", "findings")
+	os.MkdirAll(findingsDir, 0755)
+	body := strings.Repeat("0123456789", 10)
+	os.WriteFile(filepath.Join(findingsDir, "t1.md"), []byte(body), 0644)
+	s.SetMaxResponseBytes(50)
+
+	get := func(rng string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/tasks/t1/findings", nil)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 over the cap, got %d", w.Code)
+	}
+	var p struct {
+		Code    string                 `json:"code"`
+		Details map[string]interface{} `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &p)
+	if p.Code != "response_too_large" || p.Details["size"] != float64(100) {
+		t.Errorf("unexpected problem: %s", w.Body.String())
+	}
+
+	w = get("bytes=10-19")
+	if w.Code != http.StatusPartialContent || w.Body.String() != "0123456789" {
+		t.Fatalf("expected the range, got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 10-19/100" {
+		t.Errorf("unexpected Content-Range %q", got)
+	}
+	if w := get("bytes=0-59"); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected a range over the cap refused, got %d", w.Code)
+	}
+	if w := get("bytes=-40"); w.Code != http.StatusPartialContent || w.Body.Len() != 40 {
+		t.Errorf("expected the last 40 bytes, got %d, %d bytes", w.Code, w.Body.Len())
+	}
+
+	s.SetMaxResponseBytes(-1)
+	if w := get(""); w.Code != http.StatusOK || w.Body.String() != body {
+		t.Errorf("expected the whole file without a cap, got %d", w.Code)
+	}
+	if w := get(""); !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") {
+		t.Errorf("unexpected Content-Type %q", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestTaskBriefingRejectsInvalidJSON(t *testing.T) {
+	s, dir := newTestServer(t)
+	handoffs := filepath.Join(dir, ".mission", "handoffs")
+	os.MkdirAll(handoffs, 0755)
+	os.WriteFile(filepath.Join(handoffs, "t1-briefing.json"), []byte(`{"task_id": "t1"`), 0644)
+	os.WriteFile(filepath.Join(handoffs, "t2-briefing.json"), []byte(`{"task_id": "t2"}`), 0644)
+
+	req := httptest.NewRequest("GET", "/api/tasks/t1/briefing", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a truncated briefing, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/tasks/t2/briefing", nil)
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != `{"task_id": "t2"}` {
+		t.Errorf("expected the briefing, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCheckpointByID(t *testing.T) {
+	s, dir := newTestServer(t)
+	cp := `{"id":"cp-1","stage":"implement","tasks":[{"id":"a","deps":[["x"]]}],"gates":{"design":{"status":"approved"}},"auto":true}`
+	os.WriteFile(filepath.Join(dir, ".mission", "orchestrator", "checkpoints", "cp-1.json"), []byte(cp), 0644)
+
+	req := httptest.NewRequest("GET", "/api/checkpoints/cp-1", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != cp {
+		t.Fatalf("expected the checkpoint, got %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/checkpoints/cp-2", nil)
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+
+	list := s.loadCheckpoints()
+	if len(list) != 1 || list[0]["stage"] != "implement" || list[0]["auto"] != true {
+		t.Errorf("unexpected summary: %v", list)
+	}
+}
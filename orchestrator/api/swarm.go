@@ -9,6 +9,8 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/httpstream"
 )
 
 // Service base URLs — configurable via environment variables.
@@ -248,6 +250,8 @@ func (s *Server) handleSwarmWarrenEvents(w http.ResponseWriter, r *http.Request)
 	}
 	defer resp.Body.Close()
 
+	httpstream.Begin(w)
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -1,10 +1,17 @@
 package api
 
+import (
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+)
+
 // --- Request types ---
 
 // GateActionRequest is used for gate approve/reject
 type GateActionRequest struct {
-	Reason string `json:"reason,omitempty"`
+	Reason           string `json:"reason,omitempty"`            // reject
+	Note             string `json:"note,omitempty"`              // approve
+	OverrideFindings string `json:"override_findings,omitempty"` // approve despite open findings
 }
 
 // ChatRequest is the request for POST /api/chat
@@ -19,17 +26,40 @@ type SpawnWorkerRequest struct {
 	Task    string `json:"task,omitempty"`
 }
 
-// CreateTaskRequest is the request for POST /api/tasks
+// CreateTaskRequest is the request for POST /api/tasks, and an element of
+// the array POST /api/tasks/bulk takes
 type CreateTaskRequest struct {
-	Title string `json:"title"`
-	Stage string `json:"stage"`
-	Zone  string `json:"zone"`
+	Title     string   `json:"title"`
+	Stage     string   `json:"stage"`
+	Zone      string   `json:"zone"`
+	Persona   string   `json:"persona,omitempty"`
+	Assignee  string   `json:"assignee,omitempty"` // a person; persona defaults to manual
+	DependsOn []string `json:"depends_on,omitempty"`
+	Estimate  float64  `json:"estimate,omitempty"` // points or hours
+	Labels    []string `json:"labels,omitempty"`
+
+	// OverrideMatrix justifies a persona the stage matrix doesn't enable
+	// for the stage and zone; the override is audited.
+	OverrideMatrix string `json:"override_matrix,omitempty"`
+}
+
+// ZoneRequest is the request for POST /api/zones and PUT /api/zones/{name}.
+// Fields left out are not changed.
+type ZoneRequest struct {
+	Name       string   `json:"name"` // POST only
+	Color      *string  `json:"color,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+	MaxWorkers *int     `json:"maxWorkers,omitempty"`
 }
 
 // UpdateTaskRequest is the request for PATCH /api/tasks/{id}
 type UpdateTaskRequest struct {
-	Status string `json:"status,omitempty"`
-	Stage  string `json:"stage,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	Stage        string   `json:"stage,omitempty"`
+	Estimate     *float64 `json:"estimate,omitempty"`
+	Assignee     *string  `json:"assignee,omitempty"` // "" unassigns
+	AddLabels    []string `json:"add_labels,omitempty"`
+	RemoveLabels []string `json:"remove_labels,omitempty"`
 }
 
 // TaskDepRequest is the request for POST /api/tasks/{id}/dependencies
@@ -38,12 +68,23 @@ type TaskDepRequest struct {
 	DepID  string `json:"dep_id"`
 }
 
+// TaskArchiveRequest is the optional request for POST /api/tasks/{id}/archive
+type TaskArchiveRequest struct {
+	Reason string `json:"reason,omitempty"`
+	Force  bool   `json:"force,omitempty"`
+}
+
 // StageOverrideRequest is the request for POST /api/stages/override
 type StageOverrideRequest struct {
 	Stage  string `json:"stage"`
 	Reason string `json:"reason,omitempty"`
 }
 
+// StageRollbackRequest is the request for POST /api/stages/rollback
+type StageRollbackRequest struct {
+	Reason string `json:"reason"`
+}
+
 // ProjectSwitchRequest is the request for POST /api/projects/switch
 type ProjectSwitchRequest struct {
 	Path string `json:"path"`
@@ -54,7 +95,8 @@ type CheckpointRestartRequest struct{}
 
 // --- Response types ---
 
-// ErrorResponse is a standard error response
+// ErrorResponse is the legacy error body; errors are now written as
+// problem.Problem, which keeps the same "error" field.
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -65,49 +107,106 @@ type HealthResponse struct {
 	Version string `json:"version"`
 }
 
-// Task represents a task from tasks.jsonl
-type Task struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Stage        string   `json:"stage"`
-	Zone         string   `json:"zone"`
-	Persona      string   `json:"persona"`
-	Status       string   `json:"status"`
-	CreatedAt    string   `json:"created_at"`
-	UpdatedAt    string   `json:"updated_at"`
-	Dependencies []string `json:"dependencies,omitempty"`
-	BlockedBy    []string `json:"blocked_by,omitempty"`
-}
+// Task is a task from tasks.jsonl
+type Task = bridge.Task
 
 // GraphResponse is the response for GET /api/graph
 type GraphResponse struct {
-	Nodes        []GraphNode `json:"nodes"`
-	Edges        []GraphEdge `json:"edges"`
-	CriticalPath []string    `json:"critical_path"`
-	BlockedCount int         `json:"blocked_count"`
-	ReadyCount   int         `json:"ready_count"`
+	Nodes          []GraphNode `json:"nodes"`
+	Edges          []GraphEdge `json:"edges"`
+	CriticalPath   []string    `json:"critical_path"`   // task IDs, first to last
+	CriticalLength float64     `json:"critical_length"` // remaining work on the critical path, in estimates
+	BlockedCount   int         `json:"blocked_count"`
+	ReadyCount     int         `json:"ready_count"`
+	UpdatedAt      string      `json:"updated_at,omitempty"` // when the cached graph was last rebuilt
+}
+
+// GraphValidation is the response for GET /api/graph/validate
+type GraphValidation struct {
+	Valid               bool                `json:"valid"`
+	Cycles              [][]string          `json:"cycles"` // task IDs in dependency order, ending where they start
+	MissingDependencies []MissingDependency `json:"missing_dependencies"`
+}
+
+// MissingDependency is a dependency on a task that does not exist
+type MissingDependency struct {
+	TaskID    string `json:"task_id"`
+	DependsOn string `json:"depends_on"`
+}
+
+// BurndownResponse is the response for GET /api/analytics/burndown.
+// Amounts are task estimates, in the team's own unit (points or hours);
+// tasks without one count as 1.
+type BurndownResponse struct {
+	Total     float64         `json:"total"`
+	Remaining float64         `json:"remaining"`
+	Velocity  float64         `json:"velocity"` // completed per day over the last 7 days
+	Points    []BurndownPoint `json:"points"`
+}
+
+// BurndownPoint is the state at the end of one UTC day
+type BurndownPoint struct {
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Scope     float64 `json:"scope"`
+	Completed float64 `json:"completed"`
+	Remaining float64 `json:"remaining"`
+}
+
+// StageDurationsResponse is the response for GET /api/analytics/stage-durations
+type StageDurationsResponse struct {
+	Stages  []StageDuration `json:"stages"` // in order of first entry
+	Periods []StagePeriod   `json:"periods"`
+}
+
+// StageDuration is the total time spent in a stage
+type StageDuration struct {
+	Stage   string  `json:"stage"`
+	Seconds float64 `json:"seconds"`
+	Visits  int     `json:"visits"`
+	Current bool    `json:"current,omitempty"`
+}
+
+// StagePeriod is one stay in a stage
+type StagePeriod struct {
+	Stage   string  `json:"stage"`
+	Start   string  `json:"start"`
+	End     string  `json:"end,omitempty"` // absent for the current stage
+	Seconds float64 `json:"seconds"`
 }
 
 // GraphNode is a node in the dependency graph
 type GraphNode struct {
-	ID       string `json:"id"`
-	Name     string `json:"name,omitempty"`
-	Title    string `json:"title"`
-	Type     string `json:"type"`
-	Status   string `json:"status"`
-	Stage    string `json:"stage"`
-	Zone     string `json:"zone"`
-	Persona  string `json:"persona"`
-	WorkerID string `json:"worker_id,omitempty"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	Title     string   `json:"title"`
+	Type      string   `json:"type"`
+	Status    string   `json:"status"`
+	Stage     string   `json:"stage"`
+	Zone      string   `json:"zone"`
+	ZoneColor string   `json:"zone_color,omitempty"` // from zones.json
+	Persona   string   `json:"persona"`
+	Assignee  string   `json:"assignee,omitempty"`
+	WorkerID  string   `json:"worker_id,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Slack     *float64 `json:"slack,omitempty"` // how far the task can slip; absent when finished or in a cycle
+	Critical  bool     `json:"critical,omitempty"`
+}
+
+// LabelFacet counts the tasks carrying a label in GET /api/status
+type LabelFacet struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+	Open  int    `json:"open"` // not yet done
 }
 
 // GraphEdge is an edge in the dependency graph
 type GraphEdge struct {
-	From   string `json:"from,omitempty"`
-	To     string `json:"to,omitempty"`
-	Source string `json:"source"`
-	Target string `json:"target"`
-	Type   string `json:"type"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Type     string `json:"type"`
+	Critical bool   `json:"critical,omitempty"` // joins two critical path tasks
 }
 
 // CheckpointInfo represents a checkpoint directory
@@ -121,6 +220,7 @@ type AuditEntry struct {
 	Timestamp string `json:"timestamp"`
 	Action    string `json:"action"`
 	Actor     string `json:"actor"`
+	User      string `json:"user,omitempty"`
 	Category  string `json:"category"`
 	Details   string `json:"details,omitempty"`
 }
@@ -145,6 +245,10 @@ type SpecInfo struct {
 	Stage       string   `json:"stage,omitempty"`
 	LinkedTasks []string `json:"linked_tasks"`
 	IsOrphan    bool     `json:"is_orphan"`
+	Status      string   `json:"status"`                // draft, review, approved or superseded
+	Version     int      `json:"version,omitempty"`     // last approved version
+	Modified    bool     `json:"modified"`              // edited since that version
+	StaleTasks  []string `json:"stale_tasks,omitempty"` // linked tasks created against an earlier version
 }
 
 // CommandResult is the response for action endpoints that shell out
@@ -153,3 +257,10 @@ type CommandResult struct {
 	Output  string `json:"output,omitempty"`
 	Error   string `json:"error,omitempty"`
 }
+
+// MeResponse is the response for GET /api/me.
+type MeResponse struct {
+	User  identity.User `json:"user"`
+	Role  string        `json:"role"`
+	Roles bool          `json:"roles_configured"` // false: everyone is an admin
+}
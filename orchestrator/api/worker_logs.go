@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/httpstream"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/MikeSquared-Agency/MissionControl/tracker"
+)
+
+// LogSource is optionally implemented by trackers that keep worker output
+// (tracker.Tracker does).
+type LogSource interface {
+	Logs() *tracker.LogStore
+}
+
+// logTailInterval is how often a followed log file is checked for output.
+const logTailInterval = 500 * time.Millisecond
+
+// WorkerLogsResponse is the body of GET /api/workers/{id}/logs.
+type WorkerLogsResponse struct {
+	WorkerID  string            `json:"worker_id"`
+	Lines     []tracker.LogLine `json:"lines"`
+	Persisted bool              `json:"persisted"` // read from .mission/logs/
+}
+
+// logStore returns the tracker's log store, or nil.
+func (s *Server) logStore() *tracker.LogStore {
+	if src, ok := s.tracker.(LogSource); ok {
+		return src.Logs()
+	}
+	return nil
+}
+
+// handleWorkerLogs serves a worker's output: the lines buffered in memory
+// while it runs, else the log persisted in .mission/logs/ (where mc spawn
+// also sends worker output). With follow=true the lines are streamed as
+// NDJSON, followed by new ones until the worker finishes.
+func (s *Server) handleWorkerLogs(w http.ResponseWriter, r *http.Request, id string) {
+	if !validateTaskID(id) {
+		respondError(w, http.StatusBadRequest, "invalid worker ID")
+		return
+	}
+	follow := r.URL.Query().Get("follow") == "true"
+	store := s.logStore()
+	path := tracker.LogPath(s.missionPath("logs"), id)
+
+	var buffered, running bool
+	if store != nil {
+		_, buffered = store.Lines(id)
+	}
+	if s.tracker != nil {
+		if p, ok := s.tracker.Get(id); ok && p.Status == tracker.StatusRunning {
+			running = true
+		}
+	}
+
+	if !follow {
+		if buffered {
+			lines, _ := store.Lines(id)
+			writeJSON(w, http.StatusOK, WorkerLogsResponse{WorkerID: id, Lines: lines})
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) && running {
+				writeJSON(w, http.StatusOK, WorkerLogsResponse{WorkerID: id, Lines: []tracker.LogLine{}})
+				return
+			}
+			respondError(w, http.StatusNotFound, "no logs for worker")
+			return
+		}
+		writeJSON(w, http.StatusOK, WorkerLogsResponse{WorkerID: id, Lines: fileLines(data), Persisted: true})
+		return
+	}
+
+	_, statErr := os.Stat(path)
+	if store == nil || (!buffered && !running) {
+		if statErr != nil {
+			respondError(w, http.StatusNotFound, "no logs for worker")
+			return
+		}
+		s.tailWorkerLog(w, r, id, path)
+		return
+	}
+
+	httpstream.Begin(w)
+	backlog, lines, stop := store.Follow(id)
+	defer stop()
+	enc := startLogStream(w)
+	for _, line := range backlog {
+		enc.Encode(line)
+	}
+	flush(w)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			enc.Encode(line)
+			flush(w)
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// tailWorkerLog streams a log file as NDJSON, with secret values masked,
+// and keeps streaming what is appended while the worker is running
+// according to workers.json.
+func (s *Server) tailWorkerLog(w http.ResponseWriter, r *http.Request, id, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to read logs")
+		return
+	}
+	defer f.Close()
+
+	httpstream.Begin(w)
+	enc := startLogStream(w)
+	reader := bufio.NewReader(f)
+	var partial []byte
+	send := func() {
+		for {
+			chunk, err := reader.ReadBytes('\n')
+			partial = append(partial, chunk...)
+			if err != nil {
+				break // partial line: wait for the rest
+			}
+			enc.Encode(tracker.LogLine{Timestamp: time.Now(), Content: secrets.Redact(string(bytes.TrimRight(partial, "\r\n"))), Stream: "stdout"})
+			partial = partial[:0]
+		}
+		flush(w)
+	}
+
+	send()
+	ticker := time.NewTicker(logTailInterval)
+	defer ticker.Stop()
+	for s.cliWorkerRunning(id) {
+		select {
+		case <-ticker.C:
+			send()
+		case <-r.Context().Done():
+			return
+		}
+	}
+	send()
+	if len(partial) > 0 {
+		enc.Encode(tracker.LogLine{Timestamp: time.Now(), Content: secrets.Redact(string(partial)), Stream: "stdout"})
+		flush(w)
+	}
+}
+
+// cliWorkerRunning reports whether workers.json lists the worker as
+// running.
+func (s *Server) cliWorkerRunning(id string) bool {
+	var state struct {
+		Workers []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"workers"`
+	}
+	if err := readJSON(s.statePath("workers.json"), &state); err != nil {
+		return false
+	}
+	for _, wr := range state.Workers {
+		if wr.ID == id {
+			return wr.Status == "running" || wr.Status == "in_progress"
+		}
+	}
+	return false
+}
+
+// startLogStream writes the headers of a streamed log.
+func startLogStream(w http.ResponseWriter) *json.Encoder {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w)
+}
+
+// flush sends buffered output to the client, if the writer supports it.
+func flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// fileLines turns a persisted log into log lines. Files carry no
+// timestamps or stream names: worker output is stdout and stderr combined.
+// mc spawn writes worker output to the file as is, so secret values are
+// masked here.
+func fileLines(data []byte) []tracker.LogLine {
+	lines := []tracker.LogLine{}
+	sc := bufio.NewScanner(bytes.NewReader([]byte(secrets.Redact(string(data)))))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, tracker.LogLine{Content: sc.Text(), Stream: "stdout"})
+	}
+	return lines
+}
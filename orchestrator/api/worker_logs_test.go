@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/tracker"
+)
+
+func TestWorkerLogsBuffered(t *testing.T) {
+	dir := t.TempDir()
+	trk := tracker.NewTracker(dir, nil)
+	trk.Register("w1", "t1", "developer", "backend", "")
+	trk.Logs().Append("w1", "stdout", "hello\nworld")
+	s := NewServer(dir, nil, trk, nil)
+
+	req := httptest.NewRequest("GET", "/api/workers/w1/logs", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp WorkerLogsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Lines) != 2 || resp.Lines[1].Content != "world" || resp.Persisted {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestWorkerLogsPersisted(t *testing.T) {
+	s, dir := newTestServer(t)
+	logDir := filepath.Join(dir, ".mission", "logs")
+	if err := tracker.EnsureLogDir(logDir); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(tracker.LogPath(logDir, "w1"), []byte("one\ntwo\n"), 0644)
+
+	req := httptest.NewRequest("GET", "/api/workers/w1/logs", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+
+	var resp WorkerLogsResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || !resp.Persisted || len(resp.Lines) != 2 || resp.Lines[0].Content != "one" {
+		t.Errorf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	// A finished worker's persisted log can be followed too: the stream
+	// ends once the file is sent.
+	req = httptest.NewRequest("GET", "/api/workers/w1/logs?follow=true", nil)
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if n := countLines(t, w.Body.String()); n != 2 {
+		t.Errorf("followed %d lines, want 2", n)
+	}
+}
+
+func TestWorkerLogsNotFound(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, path := range []string{"/api/workers/nope/logs", "/api/workers/nope/logs?follow=true"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/api/workers/w1/logs", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected 405, got %d", w.Code)
+	}
+}
+
+func TestWorkerLogsFollow(t *testing.T) {
+	dir := t.TempDir()
+	trk := tracker.NewTracker(dir, nil)
+	trk.Register("w1", "t1", "developer", "backend", "")
+	trk.Logs().Append("w1", "stdout", "first")
+	srv := httptest.NewServer(NewServer(dir, nil, trk, nil).Routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/workers/w1/logs?follow=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	sc := bufio.NewScanner(resp.Body)
+
+	var got []string
+	next := func() {
+		t.Helper()
+		if !sc.Scan() {
+			t.Fatalf("stream ended early after %v", got)
+		}
+		var line tracker.LogLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, line.Content)
+	}
+
+	next()
+	trk.Logs().Append("w1", "stderr", "second")
+	next()
+	if got[0] != "first" || got[1] != "second" {
+		t.Errorf("followed %v", got)
+	}
+
+	trk.Deregister("w1", tracker.StatusComplete)
+	if sc.Scan() {
+		t.Errorf("unexpected line after the worker finished: %s", sc.Text())
+	}
+}
+
+func countLines(t *testing.T, body string) int {
+	t.Helper()
+	n := 0
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		n++
+	}
+	return n
+}
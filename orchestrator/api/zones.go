@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+func (s *Server) handleZonesRouter(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.withETag(s.stateSources("zones.json", "tasks.jsonl"), s.handleZones)(w, r)
+	case http.MethodPost:
+		s.handleCreateZone(w, r)
+	default:
+		problem.MethodNotAllowed(w)
+	}
+}
+
+func (s *Server) handleZoneRouter(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/zones/")
+	if name == "" || strings.Contains(name, "/") {
+		problem.NotFound(w, "zone not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleZoneByName(w, r, name)
+	case http.MethodPut, http.MethodPatch:
+		s.handleUpdateZone(w, r, name)
+	case http.MethodDelete:
+		s.handleDeleteZone(w, r, name)
+	default:
+		problem.MethodNotAllowed(w)
+	}
+}
+
+// loadZones returns the zones in zones.json, followed by zones that tasks
+// use but zones.json does not define (name only).
+func (s *Server) loadZones(tasks []Task) []bridge.Zone {
+	zones, _ := bridge.LoadZones(s.statePath("zones.json"))
+	out := append([]bridge.Zone{}, zones...)
+	for _, name := range deriveZones(tasks) {
+		if bridge.FindZone(zones, name) == nil {
+			out = append(out, bridge.Zone{Name: name})
+		}
+	}
+	return out
+}
+
+// definedZones returns the zones in zones.json.
+func (s *Server) definedZones(w http.ResponseWriter) ([]bridge.Zone, bool) {
+	zones, err := bridge.LoadZones(s.statePath("zones.json"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return zones, true
+}
+
+func (s *Server) handleZones(w http.ResponseWriter, r *http.Request) {
+	tasks, _ := s.readTasks()
+	writeJSON(w, http.StatusOK, s.loadZones(tasks))
+}
+
+func (s *Server) handleZoneByName(w http.ResponseWriter, r *http.Request, name string) {
+	tasks, _ := s.readTasks()
+	zone := bridge.FindZone(s.loadZones(tasks), name)
+	if zone == nil {
+		problem.NotFound(w, "zone not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, zone)
+}
+
+func (s *Server) handleCreateZone(w http.ResponseWriter, r *http.Request) {
+	var req ZoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, nil)
+		return
+	}
+	zone := req.apply(bridge.Zone{Name: req.Name})
+	if err := zone.Validate(); err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	zones, ok := s.definedZones(w)
+	if !ok {
+		return
+	}
+	if bridge.FindZone(zones, req.Name) != nil {
+		respondError(w, http.StatusConflict, "zone already exists")
+		return
+	}
+
+	out, err := s.runMC(r.Context(), append([]string{"zone", "add", req.Name}, req.flags()...)...)
+	if err != nil {
+		respondCommandError(w, "mc zone add failed", out)
+		return
+	}
+	s.broadcast(r.Context(), "zone", "zone_created", map[string]string{"zone": req.Name})
+	writeJSON(w, http.StatusCreated, CommandResult{Success: true, Output: out})
+}
+
+func (s *Server) handleUpdateZone(w http.ResponseWriter, r *http.Request, name string) {
+	var req ZoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, nil)
+		return
+	}
+	zones, ok := s.definedZones(w)
+	if !ok {
+		return
+	}
+	current := bridge.FindZone(zones, name)
+	if current == nil {
+		problem.NotFound(w, "zone not found")
+		return
+	}
+	zone := req.apply(*current)
+	if err := zone.Validate(); err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+
+	out, err := s.runMC(r.Context(), append([]string{"zone", "update", name}, req.flags()...)...)
+	if err != nil {
+		respondCommandError(w, "mc zone update failed", out)
+		return
+	}
+	s.broadcast(r.Context(), "zone", "zone_updated", map[string]string{"zone": name})
+	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
+}
+
+func (s *Server) handleDeleteZone(w http.ResponseWriter, r *http.Request, name string) {
+	zones, ok := s.definedZones(w)
+	if !ok {
+		return
+	}
+	if bridge.FindZone(zones, name) == nil {
+		problem.NotFound(w, "zone not found")
+		return
+	}
+
+	out, err := s.runMC(r.Context(), "zone", "remove", name)
+	if err != nil {
+		respondCommandError(w, "mc zone remove failed", out)
+		return
+	}
+	s.broadcast(r.Context(), "zone", "zone_removed", map[string]string{"zone": name})
+	writeJSON(w, http.StatusOK, CommandResult{Success: true, Output: out})
+}
+
+// apply returns z with the request's fields set.
+func (req ZoneRequest) apply(z bridge.Zone) bridge.Zone {
+	if req.Color != nil {
+		z.Color = *req.Color
+	}
+	if req.Paths != nil {
+		z.Paths = req.Paths
+	}
+	if req.MaxWorkers != nil {
+		z.MaxWorkers = *req.MaxWorkers
+	}
+	return z
+}
+
+// flags are the mc zone add/update flags for the request's fields.
+func (req ZoneRequest) flags() []string {
+	var args []string
+	if req.Color != nil {
+		args = append(args, "--color", *req.Color)
+	}
+	if req.Paths != nil && len(req.Paths) == 0 {
+		args = append(args, "--path=")
+	}
+	for _, p := range req.Paths {
+		args = append(args, "--path", p)
+	}
+	if req.MaxWorkers != nil {
+		args = append(args, "--max-workers", fmt.Sprint(*req.MaxWorkers))
+	}
+	return args
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+// writeZonesState writes zones.json and tasks.jsonl for the zone tests.
+func writeZonesState(t *testing.T, dir string) {
+	t.Helper()
+	stateDir := filepath.Join(dir, ".mission", "state")
+	if err := bridge.SaveZones(filepath.Join(stateDir, "zones.json"), []bridge.Zone{
+		{Name: "backend", Color: "#3b82f6", Paths: []string{"orchestrator/"}, MaxWorkers: 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(stateDir, "tasks.jsonl"), []byte(`{"id":"a","name":"A","zone":"backend","status":"pending"}
+{"id":"b","name":"B","zone":"web","status":"pending"}
+`), 0644)
+}
+
+func TestZonesList(t *testing.T) {
+	s, dir := newTestServer(t)
+	writeZonesState(t, dir)
+
+	req := httptest.NewRequest("GET", "/api/zones", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var zones []bridge.Zone
+	_ = json.Unmarshal(w.Body.Bytes(), &zones)
+	if len(zones) != 2 || zones[0].Color != "#3b82f6" || zones[0].MaxWorkers != 2 || zones[1].Name != "web" {
+		t.Errorf("zones = %+v, want backend from zones.json and web from tasks", zones)
+	}
+
+	req = httptest.NewRequest("GET", "/api/zones/backend", nil)
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	var zone bridge.Zone
+	_ = json.Unmarshal(w.Body.Bytes(), &zone)
+	if w.Code != http.StatusOK || len(zone.Paths) != 1 || zone.Paths[0] != "orchestrator/" {
+		t.Errorf("GET backend: %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/zones/nope", nil)
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET nope: expected 404, got %d", w.Code)
+	}
+}
+
+func TestZoneMutationsAreChecked(t *testing.T) {
+	s, dir := newTestServer(t)
+	writeZonesState(t, dir)
+
+	cases := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"POST", "/api/zones", `{"name":"bad/name"}`, http.StatusBadRequest},
+		{"POST", "/api/zones", `{"name":"web","color":"blue"}`, http.StatusBadRequest},
+		{"POST", "/api/zones", `{"name":"backend"}`, http.StatusConflict},
+		{"POST", "/api/zones", `not json`, http.StatusBadRequest},
+		{"PUT", "/api/zones/web", `{"color":"#fff"}`, http.StatusNotFound},
+		{"PUT", "/api/zones/backend", `{"maxWorkers":-1}`, http.StatusBadRequest},
+		{"DELETE", "/api/zones/web", ``, http.StatusNotFound},
+		{"POST", "/api/zones/backend", `{}`, http.StatusMethodNotAllowed},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		w := httptest.NewRecorder()
+		s.Routes().ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("%s %s %s: got %d, want %d (%s)", c.method, c.path, c.body, w.Code, c.want, w.Body.String())
+		}
+	}
+}
+
+func TestGraphZoneColors(t *testing.T) {
+	s, dir := newTestServer(t)
+	writeZonesState(t, dir)
+
+	req := httptest.NewRequest("GET", "/api/graph", nil)
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, req)
+
+	var graph GraphResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &graph)
+	colors := map[string]string{}
+	for _, n := range graph.Nodes {
+		colors[n.ID] = n.ZoneColor
+	}
+	if colors["a"] != "#3b82f6" || colors["b"] != "" {
+		t.Errorf("zone colors = %v", colors)
+	}
+}
@@ -0,0 +1,339 @@
+// Package audit manages .mission/audit.jsonl: size/age-based rotation into
+// gzip archives, a segment index, and filtered queries that skip archives
+// which cannot contain matching entries.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+const (
+	// FileName is the active audit log inside .mission/.
+	FileName = "audit.jsonl"
+	// ArchiveDir holds rotated, gzip-compressed segments inside .mission/.
+	ArchiveDir = "audit"
+	// IndexFile lists archived segments and their metadata.
+	IndexFile = "index.json"
+)
+
+// Policy controls when the active audit log is rotated.
+type Policy struct {
+	MaxBytes    int64         // rotate when the active file exceeds this size (0 = no size limit)
+	MaxAge      time.Duration // rotate when the oldest active entry is older than this (0 = no age limit)
+	MaxArchives int           // keep at most this many archives (0 = unlimited)
+}
+
+// DefaultPolicy returns the default rotation policy: 10 MiB, 30 days, 20 archives.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxBytes:    10 << 20,
+		MaxAge:      30 * 24 * time.Hour,
+		MaxArchives: 20,
+	}
+}
+
+// Entry is the subset of an audit line used for filtering and indexing.
+type Entry struct {
+	Timestamp string                 `json:"timestamp"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	User      string                 `json:"user,omitempty"` // person behind the mutation, e.g. "Alice <alice@example.com>"
+	Category  string                 `json:"category,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// CategoryOf returns the entry's category, deriving it from the action
+// prefix (e.g. "gate_approved" → "gate") when none was recorded.
+func (e Entry) CategoryOf() string {
+	if e.Category != "" {
+		return e.Category
+	}
+	if i := strings.Index(e.Action, "_"); i > 0 {
+		return e.Action[:i]
+	}
+	return e.Action
+}
+
+// Time parses the entry timestamp. The zero time is returned if unparsable.
+func (e Entry) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339, e.Timestamp)
+	return t
+}
+
+// Segment describes one archived audit file.
+type Segment struct {
+	File       string   `json:"file"`
+	First      string   `json:"first"`
+	Last       string   `json:"last"`
+	Count      int      `json:"count"`
+	Actors     []string `json:"actors"`
+	Categories []string `json:"categories"`
+	Actions    []string `json:"actions"`
+}
+
+// Index is the on-disk list of archived segments, oldest first.
+type Index struct {
+	Segments []Segment `json:"segments"`
+}
+
+// rotateMu serialises rotation within a process; cross-process writers
+// append through jsonl, which follows the rename to the fresh log.
+var rotateMu sync.Mutex
+
+// MaybeRotate rotates the active audit log in missionDir if it violates the
+// policy. It returns the archive path when a rotation happened.
+func MaybeRotate(missionDir string, p Policy) (string, error) {
+	rotateMu.Lock()
+	defer rotateMu.Unlock()
+
+	active := filepath.Join(missionDir, FileName)
+	info, err := os.Stat(active)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if info.Size() == 0 {
+		return "", nil
+	}
+
+	due := p.MaxBytes > 0 && info.Size() >= p.MaxBytes
+	if !due && p.MaxAge > 0 {
+		if first, ok := firstTimestamp(active); ok && time.Since(first) >= p.MaxAge {
+			due = true
+		}
+	}
+	if !due {
+		return "", nil
+	}
+	return rotate(missionDir, p)
+}
+
+// Rotate unconditionally archives the active audit log.
+func Rotate(missionDir string, p Policy) (string, error) {
+	rotateMu.Lock()
+	defer rotateMu.Unlock()
+	return rotate(missionDir, p)
+}
+
+// Append adds e to the active audit log in missionDir, stamping it with the
+// current time if it has none.
+func Append(missionDir string, e Entry) error {
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return jsonl.AppendLines(filepath.Join(missionDir, FileName), jsonl.Options{}, data)
+}
+
+func rotate(missionDir string, p Policy) (string, error) {
+	active := filepath.Join(missionDir, FileName)
+	archiveDir := filepath.Join(missionDir, ArchiveDir)
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return "", err
+	}
+
+	// Move the active file aside first so new writers start a fresh log.
+	pending := filepath.Join(archiveDir, fmt.Sprintf(".rotating-%d.jsonl", time.Now().UnixNano()))
+	if err := os.Rename(active, pending); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	seg, err := summarise(pending)
+	if err != nil {
+		return "", err
+	}
+	if seg.Count == 0 {
+		os.Remove(pending)
+		return "", nil
+	}
+
+	name := "audit-" + archiveStamp(seg.First) + ".jsonl.gz"
+	dest := filepath.Join(archiveDir, name)
+	for i := 1; fileExists(dest); i++ {
+		name = fmt.Sprintf("audit-%s-%d.jsonl.gz", archiveStamp(seg.First), i)
+		dest = filepath.Join(archiveDir, name)
+	}
+	if err := gzipFile(pending, dest); err != nil {
+		return "", err
+	}
+	os.Remove(pending)
+
+	seg.File = name
+	idx, _ := LoadIndex(missionDir)
+	idx.Segments = append(idx.Segments, seg)
+	if p.MaxArchives > 0 {
+		for len(idx.Segments) > p.MaxArchives {
+			os.Remove(filepath.Join(archiveDir, idx.Segments[0].File))
+			idx.Segments = idx.Segments[1:]
+		}
+	}
+	if err := saveIndex(missionDir, idx); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// LoadIndex reads the archive index. A missing index yields an empty one.
+func LoadIndex(missionDir string) (Index, error) {
+	var idx Index
+	data, err := os.ReadFile(filepath.Join(missionDir, ArchiveDir, IndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return idx, err
+	}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return Index{}, err
+	}
+	return idx, nil
+}
+
+func saveIndex(missionDir string, idx Index) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(missionDir, ArchiveDir, IndexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// summarise scans a plain JSONL file and builds its segment metadata.
+func summarise(path string) (Segment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Segment{}, err
+	}
+	defer f.Close()
+
+	actors, categories, actions := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	var seg Segment
+	err = scanLines(f, func(line []byte, e Entry) bool {
+		seg.Count++
+		if seg.First == "" || e.Timestamp < seg.First {
+			seg.First = e.Timestamp
+		}
+		if e.Timestamp > seg.Last {
+			seg.Last = e.Timestamp
+		}
+		actors[e.Actor] = true
+		categories[e.CategoryOf()] = true
+		actions[e.Action] = true
+		return true
+	})
+	seg.Actors = sortedKeys(actors)
+	seg.Categories = sortedKeys(categories)
+	seg.Actions = sortedKeys(actions)
+	return seg, err
+}
+
+// scanLines decodes each JSONL line and calls fn with the raw line and the
+// parsed entry. Malformed lines are skipped. fn returns false to stop.
+// Lines of any length are accepted; audit details are caller-controlled.
+func scanLines(r io.Reader, fn func(line []byte, e Entry) bool) error {
+	br := bufio.NewReader(r)
+	for {
+		raw, err := br.ReadBytes('\n')
+		if line := bytes.TrimSpace(raw); len(line) > 0 {
+			var e Entry
+			if json.Unmarshal(line, &e) == nil && !fn(line, e) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func firstTimestamp(path string) (time.Time, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	var first time.Time
+	_ = scanLines(f, func(_ []byte, e Entry) bool {
+		first = e.Time()
+		return first.IsZero()
+	})
+	return first, !first.IsZero()
+}
+
+func gzipFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		zw.Close()
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+	return out.Close()
+}
+
+func archiveStamp(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		t = time.Now().UTC()
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
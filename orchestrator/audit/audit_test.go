@@ -0,0 +1,240 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func appendEntry(t *testing.T, missionDir string, e Entry) {
+	t.Helper()
+	data, _ := json.Marshal(e)
+	f, err := os.OpenFile(filepath.Join(missionDir, FileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+func ts(offset time.Duration) string {
+	return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(offset).Format(time.RFC3339)
+}
+
+func TestCategoryOf(t *testing.T) {
+	if got := (Entry{Action: "gate_approved"}).CategoryOf(); got != "gate" {
+		t.Errorf("expected gate, got %s", got)
+	}
+	if got := (Entry{Action: "gate_approved", Category: "custom"}).CategoryOf(); got != "custom" {
+		t.Errorf("expected explicit category, got %s", got)
+	}
+}
+
+func TestMaybeRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		appendEntry(t, dir, Entry{Timestamp: ts(time.Duration(i) * time.Minute), Action: "task_created", Actor: "cli"})
+	}
+
+	archive, err := MaybeRotate(dir, Policy{MaxBytes: 1 << 20})
+	if err != nil || archive != "" {
+		t.Fatalf("expected no rotation under limit, got %q %v", archive, err)
+	}
+
+	archive, err = MaybeRotate(dir, Policy{MaxBytes: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archive == "" {
+		t.Fatal("expected rotation over size limit")
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Error("expected active log to be moved aside")
+	}
+
+	idx, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Segments) != 1 || idx.Segments[0].Count != 5 {
+		t.Fatalf("unexpected index: %+v", idx)
+	}
+	if idx.Segments[0].First != ts(0) || idx.Segments[0].Last != ts(4*time.Minute) {
+		t.Errorf("unexpected segment range: %+v", idx.Segments[0])
+	}
+	if len(idx.Segments[0].Categories) != 1 || idx.Segments[0].Categories[0] != "task" {
+		t.Errorf("unexpected categories: %v", idx.Segments[0].Categories)
+	}
+}
+
+func TestMaybeRotateByAge(t *testing.T) {
+	dir := t.TempDir()
+	appendEntry(t, dir, Entry{Timestamp: time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339), Action: "stage_set", Actor: "cli"})
+
+	archive, err := MaybeRotate(dir, Policy{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archive == "" {
+		t.Fatal("expected rotation when oldest entry exceeds max age")
+	}
+}
+
+func TestRotatePrunesOldArchives(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 4; i++ {
+		appendEntry(t, dir, Entry{Timestamp: ts(time.Duration(i) * time.Hour), Action: "task_created", Actor: "cli"})
+		if _, err := Rotate(dir, Policy{MaxArchives: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx, _ := LoadIndex(dir)
+	if len(idx.Segments) != 2 {
+		t.Fatalf("expected 2 archives kept, got %d", len(idx.Segments))
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, ArchiveDir, "*.jsonl.gz"))
+	if len(files) != 2 {
+		t.Errorf("expected 2 archive files on disk, got %d", len(files))
+	}
+}
+
+func TestQueryAcrossArchivesAndActive(t *testing.T) {
+	dir := t.TempDir()
+	appendEntry(t, dir, Entry{Timestamp: ts(0), Action: "gate_approved", Actor: "alice"})
+	appendEntry(t, dir, Entry{Timestamp: ts(time.Hour), Action: "task_created", Actor: "bob"})
+	if _, err := Rotate(dir, DefaultPolicy()); err != nil {
+		t.Fatal(err)
+	}
+	appendEntry(t, dir, Entry{Timestamp: ts(2 * time.Hour), Action: "gate_approved", Actor: "bob"})
+	appendEntry(t, dir, Entry{Timestamp: ts(3 * time.Hour), Action: "task_updated", Actor: "alice"})
+
+	res, err := Query(dir, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 4 {
+		t.Fatalf("expected 4 entries, got %d", res.Total)
+	}
+	var first Entry
+	json.Unmarshal(res.Entries[0], &first)
+	if first.Timestamp != ts(0) {
+		t.Errorf("expected archived entries first, got %s", first.Timestamp)
+	}
+
+	res, _ = Query(dir, Filter{Category: "gate"})
+	if res.Total != 2 {
+		t.Errorf("expected 2 gate entries, got %d", res.Total)
+	}
+
+	res, _ = Query(dir, Filter{Actor: "alice", Since: mustParse(ts(30 * time.Minute))})
+	if res.Total != 1 {
+		t.Errorf("expected 1 alice entry after 00:30, got %d", res.Total)
+	}
+
+	res, _ = Query(dir, Filter{Offset: 1, Limit: 2})
+	if res.Total != 4 || len(res.Entries) != 2 {
+		t.Errorf("expected page of 2 from 4, got %d/%d", len(res.Entries), res.Total)
+	}
+
+	res, _ = Query(dir, Filter{Offset: 1, Limit: 2, Reverse: true})
+	if res.Total != 4 || len(res.Entries) != 2 {
+		t.Fatalf("expected reverse page of 2 from 4, got %d/%d", len(res.Entries), res.Total)
+	}
+	var a, b Entry
+	json.Unmarshal(res.Entries[0], &a)
+	json.Unmarshal(res.Entries[1], &b)
+	if a.Timestamp != ts(2*time.Hour) || b.Timestamp != ts(time.Hour) {
+		t.Errorf("reverse page = %s, %s; want 02:00, 01:00", a.Timestamp, b.Timestamp)
+	}
+
+	// A reverse offset past the oldest entry gives an empty page
+	for _, offset := range []int{4, 5} {
+		res, err := Query(dir, Filter{Offset: offset, Limit: 2, Reverse: true})
+		if err != nil || res.Total != 4 || len(res.Entries) != 0 {
+			t.Errorf("offset %d: expected empty reverse page of 4, got %d/%d (%v)", offset, len(res.Entries), res.Total, err)
+		}
+	}
+}
+
+func TestQuerySkipsNonMatchingArchives(t *testing.T) {
+	dir := t.TempDir()
+	appendEntry(t, dir, Entry{Timestamp: ts(0), Action: "task_created", Actor: "cli"})
+	if _, err := Rotate(dir, DefaultPolicy()); err != nil {
+		t.Fatal(err)
+	}
+	// Corrupt the archive: a query the index rules out must never open it.
+	idx, _ := LoadIndex(dir)
+	os.WriteFile(filepath.Join(dir, ArchiveDir, idx.Segments[0].File), []byte("not gzip"), 0644)
+
+	if _, err := Query(dir, Filter{Actor: "someone-else"}); err != nil {
+		t.Errorf("expected archive to be skipped via index, got %v", err)
+	}
+	if _, err := Query(dir, Filter{Actor: "cli"}); err == nil {
+		t.Error("expected matching archive to be read")
+	}
+}
+
+func TestFollowSurvivesRotation(t *testing.T) {
+	dir := t.TempDir()
+	appendEntry(t, dir, Entry{Timestamp: ts(0), Action: "old", Actor: "cli"})
+
+	var mu sync.Mutex
+	var seen []string
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Follow(dir, 10*time.Millisecond, stop, func(_ []byte, e Entry) {
+			mu.Lock()
+			seen = append(seen, e.Action)
+			mu.Unlock()
+		})
+		close(done)
+	}()
+
+	waitFor := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			got := len(seen)
+			mu.Unlock()
+			if got >= n {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %d entries, saw %v", n, seen)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	appendEntry(t, dir, Entry{Timestamp: ts(time.Minute), Action: "first", Actor: "cli"})
+	waitFor(1)
+
+	if _, err := Rotate(dir, DefaultPolicy()); err != nil {
+		t.Fatal(err)
+	}
+	appendEntry(t, dir, Entry{Timestamp: ts(2 * time.Minute), Action: "second", Actor: "cli"})
+	waitFor(2)
+
+	close(stop)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(seen) != "[first second]" {
+		t.Errorf("expected [first second], got %v", seen)
+	}
+}
+
+func mustParse(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
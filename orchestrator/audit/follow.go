@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Follow tails the active audit log from its current end, calling fn for each
+// new line until stop is closed. Rotation (the file being replaced or
+// truncated) is detected and the new file is read from the beginning.
+func Follow(missionDir string, interval time.Duration, stop <-chan struct{}, fn func(line []byte, e Entry)) error {
+	path := filepath.Join(missionDir, FileName)
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	var (
+		f      *os.File
+		info   os.FileInfo
+		reader *bufio.Reader
+	)
+	open := func(seekEnd bool) {
+		nf, err := os.Open(path)
+		if err != nil {
+			return
+		}
+		if seekEnd {
+			if _, err := nf.Seek(0, io.SeekEnd); err != nil {
+				nf.Close()
+				return
+			}
+		}
+		f = nf
+		info, _ = nf.Stat()
+		reader = bufio.NewReader(nf)
+	}
+	open(true)
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var partial []byte
+	for {
+		if f != nil {
+			for {
+				chunk, err := reader.ReadBytes('\n')
+				partial = append(partial, chunk...)
+				if err != nil {
+					break
+				}
+				_ = scanLines(bytes.NewReader(partial), func(line []byte, e Entry) bool {
+					fn(line, e)
+					return true
+				})
+				partial = partial[:0]
+			}
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		cur, err := os.Stat(path)
+		switch {
+		case err != nil:
+			// Mid-rotation: the active file is briefly absent.
+		case f == nil:
+			open(false)
+		case !os.SameFile(info, cur):
+			f.Close()
+			f = nil
+			partial = partial[:0]
+			open(false)
+		default:
+			if pos, err := f.Seek(0, io.SeekCurrent); err == nil && cur.Size() < pos-int64(reader.Buffered()) {
+				// Truncated in place — start over.
+				f.Seek(0, io.SeekStart)
+				reader.Reset(f)
+				partial = partial[:0]
+			}
+		}
+	}
+}
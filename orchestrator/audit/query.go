@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Filter selects audit entries. Zero values match everything.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	Actor     string
+	User      string
+	Category  string
+	Action    string
+	RequestID string
+	Offset    int
+	Limit     int  // 0 = no limit
+	Reverse   bool // newest first; Offset counts back from the newest match
+}
+
+// Result is a page of matching entries in chronological order (newest first
+// when Filter.Reverse is set).
+type Result struct {
+	Entries []json.RawMessage `json:"entries"`
+	Total   int               `json:"total"`
+}
+
+// Match reports whether e satisfies the filter (ignoring pagination).
+func (f Filter) Match(e Entry) bool {
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	if f.User != "" && e.User != f.User {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.RequestID != "" && e.RequestID != f.RequestID {
+		return false
+	}
+	if f.Category != "" && e.CategoryOf() != f.Category {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		t := e.Time()
+		if t.IsZero() {
+			return false
+		}
+		if !f.Since.IsZero() && t.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && t.After(f.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// mayContain reports whether an archived segment can hold matching entries,
+// letting Query skip decompressing archives outside the filter.
+func (f Filter) mayContain(s Segment) bool {
+	if f.Actor != "" && !contains(s.Actors, f.Actor) {
+		return false
+	}
+	if f.Action != "" && !contains(s.Actions, f.Action) {
+		return false
+	}
+	if f.Category != "" && !contains(s.Categories, f.Category) {
+		return false
+	}
+	if !f.Since.IsZero() {
+		if last, err := time.Parse(time.RFC3339, s.Last); err == nil && last.Before(f.Since) {
+			return false
+		}
+	}
+	if !f.Until.IsZero() {
+		if first, err := time.Parse(time.RFC3339, s.First); err == nil && first.After(f.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query streams archived segments (via the index) and the active log,
+// returning the requested page of matching entries and the total match count.
+func Query(missionDir string, f Filter) (Result, error) {
+	res := Result{Entries: []json.RawMessage{}}
+
+	// Entries are stored oldest first, so a reverse page is the window
+	// [total-offset-limit, total-offset) of a forward scan, flipped.
+	start, end := f.Offset, -1
+	if f.Reverse {
+		total := 0
+		if err := scan(missionDir, f, func([]byte) { total++ }); err != nil {
+			return res, err
+		}
+		end = total - f.Offset
+		if end <= 0 {
+			// The offset is past the oldest entry: an empty page
+			res.Total = total
+			return res, nil
+		}
+		start = 0
+		if f.Limit > 0 && end-f.Limit > 0 {
+			start = end - f.Limit
+		}
+	} else if f.Limit > 0 {
+		end = start + f.Limit
+	}
+
+	err := scan(missionDir, f, func(line []byte) {
+		if res.Total >= start && (end < 0 || res.Total < end) {
+			res.Entries = append(res.Entries, json.RawMessage(append([]byte(nil), line...)))
+		}
+		res.Total++
+	})
+	if f.Reverse {
+		for i, j := 0, len(res.Entries)-1; i < j; i, j = i+1, j-1 {
+			res.Entries[i], res.Entries[j] = res.Entries[j], res.Entries[i]
+		}
+	}
+	return res, err
+}
+
+// scan calls fn for every entry matching f, oldest first.
+func scan(missionDir string, f Filter, fn func(line []byte)) error {
+	idx, err := LoadIndex(missionDir)
+	if err != nil {
+		return err
+	}
+	segments := append([]Segment(nil), idx.Segments...)
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].First < segments[j].First })
+
+	collect := func(line []byte, e Entry) bool {
+		if f.Match(e) {
+			fn(line)
+		}
+		return true
+	}
+
+	for _, seg := range segments {
+		if !f.mayContain(seg) {
+			continue
+		}
+		if err := scanArchive(filepath.Join(missionDir, ArchiveDir, seg.File), collect); err != nil {
+			return err
+		}
+	}
+
+	active, err := os.Open(filepath.Join(missionDir, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer active.Close()
+	return scanLines(active, collect)
+}
+
+func scanArchive(path string, fn func(line []byte, e Entry) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // pruned since the index was read
+		}
+		return err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return scanLines(zr, fn)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
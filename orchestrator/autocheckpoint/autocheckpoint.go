@@ -0,0 +1,256 @@
+// Package autocheckpoint takes checkpoints on a schedule, configured under
+// "auto_checkpoint" in .mission/config.json:
+//
+//	"auto_checkpoint": {"interval_minutes": 30, "every_tasks": 5, "on_token_threshold": true, "keep": 20}
+//
+// A Scheduler checks the policy every TickInterval and runs
+// mc checkpoint auto when a trigger fires: interval_minutes have passed
+// since the newest checkpoint, every_tasks more tasks have completed since
+// the last auto-checkpoint, or a King session has crossed token_threshold
+// (default 150k). mc tags these checkpoints "auto" and prunes them to
+// keep. The config is re-read on every tick.
+package autocheckpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// TickInterval is how often a Scheduler checks its triggers.
+const TickInterval = time.Minute
+
+// DefaultTokenThreshold is the King session size that triggers a
+// checkpoint when config.json sets no token_threshold.
+const DefaultTokenThreshold = 150000
+
+// Trigger reasons, passed to mc checkpoint auto --reason
+const (
+	ReasonInterval = "interval"
+	ReasonTasks    = "tasks"
+	ReasonTokens   = "token-threshold"
+)
+
+// Policy is the "auto_checkpoint" section of config.json. Zero values
+// disable a trigger.
+type Policy struct {
+	IntervalMinutes  int  `json:"interval_minutes,omitempty"`
+	EveryTasks       int  `json:"every_tasks,omitempty"`
+	OnTokenThreshold bool `json:"on_token_threshold,omitempty"`
+	Keep             int  `json:"keep,omitempty"` // applied by mc
+}
+
+// Checkpoint is an automatic checkpoint the scheduler took.
+type Checkpoint struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	Tokens int    `json:"tokens,omitempty"`
+}
+
+// Scheduler checkpoints one project on its auto_checkpoint policy.
+type Scheduler struct {
+	projectDir   string
+	acc          *tokens.Accumulator
+	onCheckpoint func(Checkpoint)
+	done         chan struct{}
+	stopOnce     sync.Once
+
+	started   time.Time
+	tasksDone int             // completed tasks at the last auto-checkpoint
+	crossed   map[string]bool // King sessions already checkpointed for tokens
+}
+
+// Start starts a Scheduler for projectDir. acc supplies the King session
+// token counts and may be nil. onCheckpoint, if set, is called after each
+// checkpoint.
+func Start(projectDir string, acc *tokens.Accumulator, onCheckpoint func(Checkpoint)) *Scheduler {
+	s := newScheduler(projectDir, acc, onCheckpoint, time.Now())
+	go s.run(TickInterval)
+	return s
+}
+
+func newScheduler(projectDir string, acc *tokens.Accumulator, onCheckpoint func(Checkpoint), now time.Time) *Scheduler {
+	s := &Scheduler{
+		projectDir:   projectDir,
+		acc:          acc,
+		onCheckpoint: onCheckpoint,
+		done:         make(chan struct{}),
+		started:      now,
+		crossed:      map[string]bool{},
+	}
+	s.tasksDone = completedTasks(s.missionDir())
+	return s
+}
+
+// Stop stops the scheduler.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+func (s *Scheduler) missionDir() string {
+	return filepath.Join(s.projectDir, ".mission")
+}
+
+func (s *Scheduler) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+// tick checkpoints if a trigger has fired.
+func (s *Scheduler) tick(now time.Time) {
+	policy, threshold := loadPolicy(s.missionDir())
+	reason, tokenCount := s.due(policy, threshold, now)
+	if reason == "" {
+		return
+	}
+	cp, err := s.checkpoint(reason, tokenCount)
+	if err != nil {
+		log.Printf("autocheckpoint: %s checkpoint in %s failed: %v", reason, s.projectDir, err)
+		return
+	}
+	log.Printf("autocheckpoint: %s (%s)", cp.ID, reason)
+	if s.onCheckpoint != nil {
+		s.onCheckpoint(cp)
+	}
+}
+
+// due returns the reason a checkpoint is due now, "" if none, and for the
+// token trigger the session's token count. Every trigger that has fired
+// is reset, so one checkpoint covers them all.
+func (s *Scheduler) due(policy Policy, threshold int, now time.Time) (string, int) {
+	var reasons []string
+	tokenCount := 0
+
+	if policy.OnTokenThreshold && s.acc != nil {
+		for _, session := range s.acc.Summary().Sessions {
+			if session.Persona != "king" || session.TotalTokens < threshold || s.crossed[session.WorkerID] {
+				continue
+			}
+			s.crossed[session.WorkerID] = true
+			if session.TotalTokens > tokenCount {
+				tokenCount = session.TotalTokens
+			}
+		}
+		if tokenCount > 0 {
+			reasons = append(reasons, ReasonTokens)
+		}
+	}
+
+	if policy.EveryTasks > 0 {
+		done := completedTasks(s.missionDir())
+		if done < s.tasksDone {
+			s.tasksDone = done // tasks reopened or deleted
+		}
+		if done-s.tasksDone >= policy.EveryTasks {
+			reasons = append(reasons, ReasonTasks)
+		}
+	}
+
+	if policy.IntervalMinutes > 0 {
+		last := latestCheckpoint(s.missionDir())
+		if last.Before(s.started) {
+			last = s.started
+		}
+		if now.Sub(last) >= time.Duration(policy.IntervalMinutes)*time.Minute {
+			reasons = append(reasons, ReasonInterval)
+		}
+	}
+
+	if len(reasons) == 0 {
+		return "", 0
+	}
+	s.tasksDone = completedTasks(s.missionDir())
+	return strings.Join(reasons, ","), tokenCount
+}
+
+// checkpoint runs mc checkpoint auto in the project.
+func (s *Scheduler) checkpoint(reason string, tokenCount int) (Checkpoint, error) {
+	args := []string{"checkpoint", "auto", "--reason", reason}
+	if tokenCount > 0 {
+		args = append(args, "--tokens", strconv.Itoa(tokenCount))
+	}
+	cmd := exec.Command("mc", args...)
+	cmd.Dir = s.projectDir
+	out, err := cmd.Output()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	cp := Checkpoint{Reason: reason, Tokens: tokenCount}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &created); err != nil {
+		return Checkpoint{}, fmt.Errorf("unexpected mc output: %s", strings.TrimSpace(string(out)))
+	}
+	cp.ID = created.ID
+	return cp, nil
+}
+
+// loadPolicy reads the auto_checkpoint policy and token threshold from
+// config.json.
+func loadPolicy(missionDir string) (Policy, int) {
+	var cfg struct {
+		AutoCheckpoint Policy `json:"auto_checkpoint"`
+		TokenThreshold int    `json:"token_threshold"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	if cfg.TokenThreshold <= 0 {
+		cfg.TokenThreshold = DefaultTokenThreshold
+	}
+	return cfg.AutoCheckpoint, cfg.TokenThreshold
+}
+
+// completedTasks counts the complete tasks in tasks.jsonl.
+func completedTasks(missionDir string) int {
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "tasks.jsonl"))
+	if err != nil {
+		return 0
+	}
+	lines, _ := bridge.LatestTaskLines(data)
+	n := 0
+	for _, line := range lines {
+		var t struct {
+			Status string `json:"status"`
+		}
+		if json.Unmarshal(line, &t) == nil && (t.Status == "complete" || t.Status == "done") {
+			n++
+		}
+	}
+	return n
+}
+
+// latestCheckpoint returns when the newest checkpoint was written, the
+// zero time if there is none.
+func latestCheckpoint(missionDir string) time.Time {
+	var latest time.Time
+	entries, _ := os.ReadDir(filepath.Join(missionDir, "orchestrator", "checkpoints"))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
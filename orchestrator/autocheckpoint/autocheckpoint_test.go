@@ -0,0 +1,121 @@
+package autocheckpoint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTasks(t *testing.T, dir string, done int) {
+	t.Helper()
+	var lines []string
+	for i := 0; i < done; i++ {
+		lines = append(lines, `{"id":"t`+string(rune('a'+i))+`","status":"complete"}`)
+	}
+	lines = append(lines, `{"id":"open","status":"pending"}`)
+	writeFile(t, filepath.Join(dir, ".mission", "state", "tasks.jsonl"), strings.Join(lines, "\n")+"\n")
+}
+
+func TestDueEveryTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeTasks(t, dir, 1)
+	now := time.Now()
+	s := newScheduler(dir, nil, nil, now)
+	policy := Policy{EveryTasks: 2}
+
+	writeTasks(t, dir, 2)
+	if reason, _ := s.due(policy, DefaultTokenThreshold, now); reason != "" {
+		t.Errorf("one more task done: due %q, want nothing", reason)
+	}
+	writeTasks(t, dir, 3)
+	if reason, _ := s.due(policy, DefaultTokenThreshold, now); reason != ReasonTasks {
+		t.Errorf("two more tasks done: due %q, want %q", reason, ReasonTasks)
+	}
+	if reason, _ := s.due(policy, DefaultTokenThreshold, now); reason != "" {
+		t.Errorf("after the checkpoint: due %q, want the count reset", reason)
+	}
+}
+
+func TestDueInterval(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	s := newScheduler(dir, nil, nil, start)
+	policy := Policy{IntervalMinutes: 30}
+
+	if reason, _ := s.due(policy, DefaultTokenThreshold, start.Add(29*time.Minute)); reason != "" {
+		t.Errorf("29 minutes in: due %q, want nothing", reason)
+	}
+	if reason, _ := s.due(policy, DefaultTokenThreshold, start.Add(30*time.Minute)); reason != ReasonInterval {
+		t.Errorf("30 minutes in: due %q, want %q", reason, ReasonInterval)
+	}
+
+	// A newer checkpoint of any kind restarts the interval
+	writeFile(t, filepath.Join(dir, ".mission", "orchestrator", "checkpoints", "cp-1.json"), `{}`)
+	if reason, _ := s.due(policy, DefaultTokenThreshold, time.Now().Add(10*time.Minute)); reason != "" {
+		t.Errorf("10 minutes after a checkpoint: due %q, want nothing", reason)
+	}
+}
+
+func TestDueTokenThreshold(t *testing.T) {
+	dir := t.TempDir()
+	acc := tokens.NewAccumulator(0, nil)
+	s := newScheduler(dir, acc, nil, time.Now())
+	policy := Policy{OnTokenThreshold: true}
+
+	acc.Record("king-1", "king", tokens.ModelOpus, 900, 50)
+	acc.Record("dev-1", "developer", tokens.ModelSonnet, 5000, 0)
+	if reason, _ := s.due(policy, 1000, time.Now()); reason != "" {
+		t.Errorf("below threshold: due %q, want nothing", reason)
+	}
+	acc.Record("king-1", "king", tokens.ModelOpus, 100, 0)
+	reason, count := s.due(policy, 1000, time.Now())
+	if reason != ReasonTokens || count != 1050 {
+		t.Errorf("King over threshold: due %q at %d tokens, want %q at 1050", reason, count, ReasonTokens)
+	}
+	acc.Record("king-1", "king", tokens.ModelOpus, 100, 0)
+	if reason, _ := s.due(policy, 1000, time.Now()); reason != "" {
+		t.Errorf("same session again: due %q, want one checkpoint per session", reason)
+	}
+	if reason, _ := s.due(Policy{}, 1000, time.Now()); reason != "" {
+		t.Errorf("no policy: due %q, want nothing", reason)
+	}
+}
+
+func TestTickRunsMC(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mission", "config.json"), `{"auto_checkpoint":{"interval_minutes":1}}`)
+
+	bin := t.TempDir()
+	argsFile := filepath.Join(dir, "mc-args")
+	script := "#!/bin/sh\necho \"$*\" > " + argsFile + "\necho '{\"id\": \"cp-20260301-120000\", \"tag\": \"auto\"}'\n"
+	writeFile(t, filepath.Join(bin, "mc"), script)
+	os.Chmod(filepath.Join(bin, "mc"), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	start := time.Now()
+	var got []Checkpoint
+	s := newScheduler(dir, nil, func(cp Checkpoint) { got = append(got, cp) }, start)
+	s.tick(start.Add(2 * time.Minute))
+
+	if len(got) != 1 || got[0].ID != "cp-20260301-120000" || got[0].Reason != ReasonInterval {
+		t.Fatalf("checkpoints = %+v, want one interval checkpoint", got)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if strings.TrimSpace(string(args)) != "checkpoint auto --reason interval" {
+		t.Errorf("mc ran with %q", args)
+	}
+}
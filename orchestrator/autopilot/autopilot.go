@@ -0,0 +1,331 @@
+// Package autopilot approves stage gates on the mission's behalf when
+// auto_mode is on in .mission/config.json. The policy lives under
+// "auto_approve":
+//
+//	"auto_mode": true,
+//	"auto_approve": {
+//	  "block_severity": "critical",
+//	  "stages": {"verify": {"block_severity": "high"}, "release": {"disabled": true}}
+//	}
+//
+// The current stage's gate is approved once every one of its criteria is
+// satisfied and none of the stage's tasks has a finding at or above the
+// blocking severity (critical unless configured). A stage can opt out
+// with "disabled". Approvals go through mc gate approve with Approver as
+// the user, so the audit trail and gates.json name the policy.
+package autopilot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/report"
+)
+
+// Approver is recorded as the approver of every gate the policy approves.
+const Approver = "policy:auto-mode"
+
+// DefaultBlockSeverity is the least severe finding that blocks approval
+// when the policy names none.
+const DefaultBlockSeverity = "critical"
+
+// PollInterval is how often a Runner evaluates the policy without being
+// triggered, to catch changes the watcher doesn't report.
+const PollInterval = 30 * time.Second
+
+// StagePolicy overrides the policy for one stage.
+type StagePolicy struct {
+	Disabled      bool   `json:"disabled,omitempty"`
+	BlockSeverity string `json:"block_severity,omitempty"`
+}
+
+// Policy is the "auto_approve" section of config.json.
+type Policy struct {
+	BlockSeverity string                 `json:"block_severity,omitempty"`
+	Stages        map[string]StagePolicy `json:"stages,omitempty"`
+}
+
+// blockSeverity returns the blocking severity for stage.
+func (p Policy) blockSeverity(stage string) string {
+	if s := p.Stages[stage].BlockSeverity; s != "" {
+		return strings.ToLower(s)
+	}
+	if p.BlockSeverity != "" {
+		return strings.ToLower(p.BlockSeverity)
+	}
+	return DefaultBlockSeverity
+}
+
+// Decision is the outcome of evaluating the policy for the current stage.
+// Reason explains it either way and becomes the approval note.
+type Decision struct {
+	Stage   string `json:"stage"`
+	Approve bool   `json:"approve"`
+	Reason  string `json:"reason"`
+}
+
+// Evaluate decides whether the current stage's gate of the mission in
+// missionDir (.mission) should be approved now.
+func Evaluate(missionDir string) (Decision, error) {
+	var cfg struct {
+		AutoMode    bool   `json:"auto_mode"`
+		AutoApprove Policy `json:"auto_approve"`
+	}
+	if err := readJSON(filepath.Join(missionDir, "config.json"), &cfg); err != nil && !os.IsNotExist(err) {
+		return Decision{}, fmt.Errorf("reading config: %w", err)
+	}
+
+	var stage struct {
+		Current string `json:"current"`
+	}
+	if err := readJSON(filepath.Join(missionDir, "state", "stage.json"), &stage); err != nil {
+		return Decision{}, fmt.Errorf("reading stage: %w", err)
+	}
+	d := Decision{Stage: stage.Current}
+	policy := cfg.AutoApprove
+
+	switch {
+	case !cfg.AutoMode:
+		d.Reason = "auto_mode is off"
+		return d, nil
+	case policy.Stages[d.Stage].Disabled:
+		d.Reason = fmt.Sprintf("auto-approval is disabled for %s", d.Stage)
+		return d, nil
+	}
+
+	gate, err := loadGate(missionDir, d.Stage)
+	if err != nil {
+		return Decision{}, err
+	}
+	if gate == nil {
+		d.Reason = fmt.Sprintf("no gate for %s", d.Stage)
+		return d, nil
+	}
+	if gate.Status == "approved" {
+		d.Reason = fmt.Sprintf("gate for %s is already approved", d.Stage)
+		return d, nil
+	}
+	satisfied := 0
+	for _, c := range gate.Criteria {
+		if c.Satisfied {
+			satisfied++
+		}
+	}
+	if len(gate.Criteria) == 0 || satisfied < len(gate.Criteria) {
+		d.Reason = fmt.Sprintf("%d/%d criteria satisfied", satisfied, len(gate.Criteria))
+		return d, nil
+	}
+
+	block := policy.blockSeverity(d.Stage)
+	if n := blockingFindings(missionDir, d.Stage, block); n > 0 {
+		d.Reason = fmt.Sprintf("%d/%d criteria satisfied, but %d finding(s) at or above %s", satisfied, len(gate.Criteria), n, block)
+		return d, nil
+	}
+
+	d.Approve = true
+	d.Reason = fmt.Sprintf("auto-approved by policy: %d/%d criteria satisfied, no findings at or above %s", satisfied, len(gate.Criteria), block)
+	return d, nil
+}
+
+// criterion is a gate criterion in either gates.json format: a plain
+// string (never satisfied) or {"description", "satisfied"}.
+type criterion struct {
+	Description string
+	Satisfied   bool
+}
+
+func (c *criterion) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Description); err == nil {
+		return nil
+	}
+	var obj struct {
+		Description string `json:"description"`
+		Satisfied   bool   `json:"satisfied"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	c.Description, c.Satisfied = obj.Description, obj.Satisfied
+	return nil
+}
+
+type gate struct {
+	Status   string      `json:"status"`
+	Criteria []criterion `json:"criteria"`
+}
+
+// loadGate returns stage's gate from gates.json, nil if it has none.
+func loadGate(missionDir, stage string) (*gate, error) {
+	var gates struct {
+		Gates map[string]gate `json:"gates"`
+	}
+	if err := readJSON(filepath.Join(missionDir, "state", "gates.json"), &gates); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading gates: %w", err)
+	}
+	g, ok := gates.Gates[stage]
+	if !ok {
+		return nil, nil
+	}
+	return &g, nil
+}
+
+// blockingFindings counts the findings of stage's tasks at or above the
+// block severity.
+func blockingFindings(missionDir, stage, block string) int {
+	limit := severityRank(block)
+	if limit < 0 {
+		return 0
+	}
+	tasks := stageTasks(missionDir, stage)
+	n := 0
+	for _, group := range report.Findings(missionDir) {
+		if severityRank(group.Severity) > limit {
+			continue
+		}
+		for _, f := range group.Findings {
+			if tasks[f.TaskID] {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// severityRank is the position of sev in report.SeverityOrder, -1 if it
+// is not a known severity.
+func severityRank(sev string) int {
+	for i, s := range report.SeverityOrder {
+		if s == sev {
+			return i
+		}
+	}
+	return -1
+}
+
+// stageTasks returns the IDs of the tasks in stage.
+func stageTasks(missionDir, stage string) map[string]bool {
+	ids := map[string]bool{}
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "tasks.jsonl"))
+	if err != nil {
+		return ids
+	}
+	lines, _ := bridge.LatestTaskLines(data)
+	for _, line := range lines {
+		var t struct {
+			ID    string `json:"id"`
+			Stage string `json:"stage"`
+		}
+		if json.Unmarshal(line, &t) == nil && t.Stage == stage {
+			ids[t.ID] = true
+		}
+	}
+	return ids
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Approve approves d.Stage's gate by running mc gate approve in projectDir
+// as Approver, with d.Reason as the note.
+func Approve(ctx context.Context, projectDir string, d Decision) (string, error) {
+	cmd := exec.CommandContext(ctx, "mc", "gate", "approve", d.Stage, "--note", d.Reason)
+	cmd.Dir = projectDir
+	cmd.Env = append(os.Environ(), identity.EnvUser+"="+Approver)
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Runner evaluates the policy for one project whenever it is triggered and
+// every PollInterval, and approves the gates the policy allows.
+type Runner struct {
+	projectDir string
+	onApprove  func(Decision)
+	trigger    chan struct{}
+	done       chan struct{}
+	stopOnce   sync.Once
+
+	failed map[string]string // stage → reason of the last failed approval
+}
+
+// Start starts a Runner for projectDir. onApprove, if set, is called after
+// each approval.
+func Start(projectDir string, onApprove func(Decision)) *Runner {
+	r := &Runner{
+		projectDir: projectDir,
+		onApprove:  onApprove,
+		trigger:    make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		failed:     map[string]string{},
+	}
+	go r.run(PollInterval)
+	return r
+}
+
+// Trigger asks for an evaluation soon. It never blocks; triggers that
+// arrive while one is pending are coalesced.
+func (r *Runner) Trigger() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops the runner.
+func (r *Runner) Stop() {
+	r.stopOnce.Do(func() { close(r.done) })
+}
+
+func (r *Runner) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.trigger:
+		}
+		r.evaluate()
+	}
+}
+
+// evaluate runs one evaluation. A failed approval is logged once and not
+// retried until the decision changes.
+func (r *Runner) evaluate() {
+	d, err := Evaluate(filepath.Join(r.projectDir, ".mission"))
+	if err != nil || !d.Approve {
+		return
+	}
+	if r.failed[d.Stage] == d.Reason {
+		return
+	}
+	out, err := Approve(context.Background(), r.projectDir, d)
+	if err != nil {
+		r.failed[d.Stage] = d.Reason
+		log.Printf("autopilot: approving %s in %s failed: %v: %s", d.Stage, r.projectDir, err, out)
+		return
+	}
+	delete(r.failed, d.Stage)
+	log.Printf("autopilot: %s (%s)", out, d.Reason)
+	if r.onApprove != nil {
+		r.onApprove(d)
+	}
+}
@@ -0,0 +1,180 @@
+package autopilot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeMission lays out a .mission under a temp project directory in the
+// implement stage and returns the project directory.
+func writeMission(t *testing.T, config, gates string) string {
+	t.Helper()
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	files := map[string]string{
+		"config.json":           config,
+		"state/stage.json":      `{"current":"implement"}`,
+		"state/gates.json":      gates,
+		"state/tasks.jsonl":     `{"id":"t1","stage":"implement","status":"complete"}` + "\n" + `{"id":"t0","stage":"design","status":"complete"}` + "\n",
+		"findings/.placeholder": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(mission, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func writeFindings(t *testing.T, dir, taskID, findings string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ".mission", "findings", taskID+".json"), []byte(findings), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const satisfiedGates = `{"gates":{"implement":{"criteria":[{"description":"All tasks done","satisfied":true},{"description":"Tests pass","satisfied":true}]}}}`
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		gates    string
+		findings map[string]string
+		approve  bool
+		reason   string
+	}{
+		{
+			name:    "approves when criteria are satisfied",
+			config:  `{"auto_mode":true}`,
+			gates:   satisfiedGates,
+			approve: true,
+			reason:  "auto-approved by policy: 2/2 criteria satisfied, no findings at or above critical",
+		},
+		{
+			name:   "auto mode off",
+			config: `{}`,
+			gates:  satisfiedGates,
+			reason: "auto_mode is off",
+		},
+		{
+			name:   "stage opted out",
+			config: `{"auto_mode":true,"auto_approve":{"stages":{"implement":{"disabled":true}}}}`,
+			gates:  satisfiedGates,
+			reason: "auto-approval is disabled for implement",
+		},
+		{
+			name:   "unsatisfied criterion",
+			config: `{"auto_mode":true}`,
+			gates:  `{"gates":{"implement":{"criteria":[{"description":"All tasks done","satisfied":true},{"description":"Tests pass","satisfied":false}]}}}`,
+			reason: "1/2 criteria satisfied",
+		},
+		{
+			name:   "legacy string criteria are never satisfied",
+			config: `{"auto_mode":true}`,
+			gates:  `{"gates":{"implement":{"stage":"implement","status":"pending","criteria":["All tasks done"]}}}`,
+			reason: "0/1 criteria satisfied",
+		},
+		{
+			name:   "no criteria",
+			config: `{"auto_mode":true}`,
+			gates:  `{"gates":{"implement":{"criteria":[]}}}`,
+			reason: "0/0 criteria satisfied",
+		},
+		{
+			name:   "already approved",
+			config: `{"auto_mode":true}`,
+			gates:  `{"gates":{"implement":{"status":"approved","criteria":[{"description":"x","satisfied":true}]}}}`,
+			reason: "gate for implement is already approved",
+		},
+		{
+			name:     "critical finding blocks",
+			config:   `{"auto_mode":true}`,
+			gates:    satisfiedGates,
+			findings: map[string]string{"t1": `[{"type":"bug","summary":"SQL injection","severity":"Critical"}]`},
+			reason:   "2/2 criteria satisfied, but 1 finding(s) at or above critical",
+		},
+		{
+			name:     "findings of other stages are ignored",
+			config:   `{"auto_mode":true}`,
+			gates:    satisfiedGates,
+			findings: map[string]string{"t0": `[{"type":"bug","summary":"old","severity":"critical"}]`},
+			approve:  true,
+			reason:   "auto-approved by policy: 2/2 criteria satisfied, no findings at or above critical",
+		},
+		{
+			name:     "high finding passes by default",
+			config:   `{"auto_mode":true}`,
+			gates:    satisfiedGates,
+			findings: map[string]string{"t1": `[{"type":"bug","summary":"slow","severity":"high"}]`},
+			approve:  true,
+			reason:   "auto-approved by policy: 2/2 criteria satisfied, no findings at or above critical",
+		},
+		{
+			name:     "per-stage block severity",
+			config:   `{"auto_mode":true,"auto_approve":{"block_severity":"critical","stages":{"implement":{"block_severity":"high"}}}}`,
+			gates:    satisfiedGates,
+			findings: map[string]string{"t1": `[{"type":"bug","summary":"slow","severity":"high"}]`},
+			reason:   "2/2 criteria satisfied, but 1 finding(s) at or above high",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeMission(t, tt.config, tt.gates)
+			for task, f := range tt.findings {
+				writeFindings(t, dir, task, f)
+			}
+			d, err := Evaluate(filepath.Join(dir, ".mission"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Stage != "implement" || d.Approve != tt.approve || d.Reason != tt.reason {
+				t.Errorf("Evaluate = %+v, want approve=%v reason %q", d, tt.approve, tt.reason)
+			}
+		})
+	}
+}
+
+func TestRunnerApprovesAsPolicy(t *testing.T) {
+	dir := writeMission(t, `{"auto_mode":true}`, satisfiedGates)
+
+	// The mc stub records its arguments and the user it acts for
+	bin := t.TempDir()
+	argsFile := filepath.Join(dir, "mc-args")
+	script := "#!/bin/sh\necho \"$MC_USER|$*\" >> " + argsFile + "\necho \"Gate approved: $3\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "mc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	approved := make(chan Decision, 1)
+	r := Start(dir, func(d Decision) { approved <- d })
+	defer r.Stop()
+	r.Trigger()
+
+	select {
+	case d := <-approved:
+		if d.Stage != "implement" {
+			t.Errorf("approved stage %q, want implement", d.Stage)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner did not approve the gate")
+	}
+
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.TrimSpace(string(data))
+	want := Approver + "|gate approve implement --note auto-approved by policy: 2/2 criteria satisfied, no findings at or above critical"
+	if got != want {
+		t.Errorf("mc ran as\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,219 @@
+// Package autorestart restarts the King session when its health turns
+// red, opted into under "auto_restart" in .mission/config.json:
+//
+//	"auto_restart": {"enabled": true, "session_key": "webchat"}
+//
+// A Restarter reads mc checkpoint status every TickInterval. Once the
+// current session is red (over two hours long), it runs mc checkpoint
+// restart, sends the compiled briefing to the King through the briefer
+// (the OpenClaw chat session_key when a gateway is configured), and
+// notifies the user through its callback and the session_restarted
+// webhook. Each session is restarted at most once, so a failing restart
+// is not retried in a loop. The config is re-read on every tick.
+package autorestart
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+)
+
+// TickInterval is how often a Restarter checks session health.
+const TickInterval = time.Minute
+
+// HealthRed is the mc checkpoint status health that triggers a restart.
+const HealthRed = "red"
+
+// Policy is the "auto_restart" section of config.json.
+type Policy struct {
+	Enabled    bool   `json:"enabled"`
+	SessionKey string `json:"session_key,omitempty"` // OpenClaw chat session to re-brief
+}
+
+// Restart is an automatic session restart. Briefed is false when no
+// briefer is set or sending the briefing failed (BriefError).
+type Restart struct {
+	OldSession   string `json:"old_session"`
+	NewSession   string `json:"new_session"`
+	CheckpointID string `json:"checkpoint_id"`
+	Stage        string `json:"stage"`
+	DurationMin  int    `json:"duration_min"`
+	Briefed      bool   `json:"briefed"`
+	BriefError   string `json:"brief_error,omitempty"`
+}
+
+// Briefer delivers a briefing to the King's session.
+type Briefer func(sessionKey, briefing string) error
+
+// status is the part of mc checkpoint status the Restarter reads.
+type status struct {
+	SessionID   string `json:"session_id"`
+	DurationMin int    `json:"duration_minutes"`
+	Health      string `json:"health"`
+}
+
+// Restarter restarts one project's King session on its auto_restart
+// policy.
+type Restarter struct {
+	projectDir string
+	onRestart  func(Restart)
+	done       chan struct{}
+	stopOnce   sync.Once
+
+	mu        sync.Mutex
+	brief     Briefer
+	attempted map[string]bool // sessions already restarted, or tried
+}
+
+// Start starts a Restarter for projectDir. onRestart, if set, is called
+// after each restart.
+func Start(projectDir string, onRestart func(Restart)) *Restarter {
+	r := newRestarter(projectDir, onRestart)
+	go r.run(TickInterval)
+	return r
+}
+
+func newRestarter(projectDir string, onRestart func(Restart)) *Restarter {
+	return &Restarter{
+		projectDir: projectDir,
+		onRestart:  onRestart,
+		done:       make(chan struct{}),
+		attempted:  map[string]bool{},
+	}
+}
+
+// SetBriefer sets how briefings reach the King. Without one the briefing
+// is only left in current.json for the next King to read.
+func (r *Restarter) SetBriefer(brief Briefer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.brief = brief
+}
+
+// Stop stops the restarter.
+func (r *Restarter) Stop() {
+	r.stopOnce.Do(func() { close(r.done) })
+}
+
+func (r *Restarter) missionDir() string {
+	return filepath.Join(r.projectDir, ".mission")
+}
+
+func (r *Restarter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.tick()
+		}
+	}
+}
+
+// tick restarts the session if the policy is on and its health is red.
+func (r *Restarter) tick() {
+	policy := loadPolicy(r.missionDir())
+	if !policy.Enabled {
+		return
+	}
+	var st status
+	if err := r.mc(&st, "checkpoint", "status"); err != nil {
+		log.Printf("autorestart: session status in %s failed: %v", r.projectDir, err)
+		return
+	}
+	if st.Health != HealthRed || r.attempted[st.SessionID] {
+		return
+	}
+	r.attempted[st.SessionID] = true
+
+	var res struct {
+		OldSession   string `json:"old_session"`
+		NewSession   string `json:"new_session"`
+		CheckpointID string `json:"checkpoint_id"`
+		Stage        string `json:"stage"`
+		Briefing     string `json:"briefing"`
+	}
+	if err := r.mc(&res, "checkpoint", "restart"); err != nil {
+		log.Printf("autorestart: restarting session %s in %s failed: %v", st.SessionID, r.projectDir, err)
+		return
+	}
+	restart := Restart{
+		OldSession:   res.OldSession,
+		NewSession:   res.NewSession,
+		CheckpointID: res.CheckpointID,
+		Stage:        res.Stage,
+		DurationMin:  st.DurationMin,
+	}
+	log.Printf("autorestart: session %s → %s after %d minutes (%s)", restart.OldSession, restart.NewSession, restart.DurationMin, restart.CheckpointID)
+
+	r.mu.Lock()
+	brief := r.brief
+	r.mu.Unlock()
+	if brief != nil {
+		if err := brief(policy.SessionKey, briefingMessage(restart, res.Briefing)); err != nil {
+			restart.BriefError = err.Error()
+			log.Printf("autorestart: briefing the King failed: %v", err)
+		} else {
+			restart.Briefed = true
+		}
+	}
+
+	r.notify(restart)
+	if r.onRestart != nil {
+		r.onRestart(restart)
+	}
+}
+
+// mc runs mc with args in the project and decodes its JSON output into v.
+func (r *Restarter) mc(v interface{}, args ...string) error {
+	cmd := exec.Command("mc", args...)
+	cmd.Dir = r.projectDir
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("unexpected mc output: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// notify sends the session_restarted webhook. Delivery is best effort.
+func (r *Restarter) notify(restart Restart) {
+	cfg, err := bridge.LoadProjectConfig(r.projectDir)
+	if err != nil || len(cfg.Webhooks) == 0 {
+		return
+	}
+	if err := webhook.Send(context.Background(), cfg.Webhooks, webhook.EventSessionRestarted, restart); err != nil {
+		log.Printf("autorestart: session_restarted webhook: %v", err)
+	}
+}
+
+// briefingMessage introduces the compiled briefing to the new session.
+func briefingMessage(restart Restart, briefing string) string {
+	return fmt.Sprintf("Your previous session (%s) ran %d minutes and was restarted automatically from checkpoint %s. Resume from this briefing:\n\n%s",
+		restart.OldSession, restart.DurationMin, restart.CheckpointID, briefing)
+}
+
+// loadPolicy reads the auto_restart policy from config.json.
+func loadPolicy(missionDir string) Policy {
+	var cfg struct {
+		AutoRestart Policy `json:"auto_restart"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	return cfg.AutoRestart
+}
@@ -0,0 +1,117 @@
+package autorestart
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeMC puts an mc on PATH that reports health and logs its arguments
+// to the returned file.
+func fakeMC(t *testing.T, dir, health string) string {
+	t.Helper()
+	bin := t.TempDir()
+	argsFile := filepath.Join(dir, "mc-args")
+	script := `#!/bin/sh
+echo "$*" >> ` + argsFile + `
+case "$2" in
+status) echo '{"session_id": "s-old", "duration_minutes": 130, "health": "` + health + `"}' ;;
+restart) echo '{"old_session": "s-old", "new_session": "s-new", "checkpoint_id": "cp-1", "stage": "implement", "briefing": "BRIEFING"}' ;;
+esac
+`
+	writeFile(t, filepath.Join(bin, "mc"), script)
+	os.Chmod(filepath.Join(bin, "mc"), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func mcCalls(t *testing.T, argsFile string) []string {
+	t.Helper()
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestTickRestartsRedSession(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mission", "config.json"), `{"auto_restart":{"enabled":true,"session_key":"main"}}`)
+	argsFile := fakeMC(t, dir, HealthRed)
+
+	var got []Restart
+	r := newRestarter(dir, func(rs Restart) { got = append(got, rs) })
+	var briefedKey, briefing string
+	r.SetBriefer(func(sessionKey, message string) error {
+		briefedKey, briefing = sessionKey, message
+		return nil
+	})
+	r.tick()
+
+	if len(got) != 1 || got[0].OldSession != "s-old" || got[0].NewSession != "s-new" || got[0].CheckpointID != "cp-1" || !got[0].Briefed {
+		t.Fatalf("restarts = %+v, want one briefed restart s-old → s-new", got)
+	}
+	if got[0].DurationMin != 130 {
+		t.Errorf("duration = %d, want the 130 minutes mc reported", got[0].DurationMin)
+	}
+	if briefedKey != "main" || !strings.Contains(briefing, "BRIEFING") || !strings.Contains(briefing, "cp-1") {
+		t.Errorf("briefed %q with %q, want the main session given the briefing", briefedKey, briefing)
+	}
+
+	// The old session is not restarted twice
+	r.tick()
+	if len(got) != 1 {
+		t.Errorf("second tick restarted again: %+v", got)
+	}
+	calls := mcCalls(t, argsFile)
+	if strings.Join(calls, ";") != "checkpoint status;checkpoint restart;checkpoint status" {
+		t.Errorf("mc calls = %q", calls)
+	}
+}
+
+func TestTickBriefFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mission", "config.json"), `{"auto_restart":{"enabled":true}}`)
+	fakeMC(t, dir, HealthRed)
+
+	var got []Restart
+	r := newRestarter(dir, func(rs Restart) { got = append(got, rs) })
+	r.SetBriefer(func(string, string) error { return errors.New("gateway down") })
+	r.tick()
+
+	if len(got) != 1 || got[0].Briefed || got[0].BriefError != "gateway down" {
+		t.Errorf("restarts = %+v, want one unbriefed restart reporting the error", got)
+	}
+}
+
+func TestTickLeavesHealthySessions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mission", "config.json"), `{"auto_restart":{"enabled":true}}`)
+	argsFile := fakeMC(t, dir, "yellow")
+
+	r := newRestarter(dir, func(rs Restart) { t.Errorf("restarted a yellow session: %+v", rs) })
+	r.tick()
+	if calls := mcCalls(t, argsFile); len(calls) != 1 || calls[0] != "checkpoint status" {
+		t.Errorf("mc calls = %q, want only the status check", calls)
+	}
+
+	// Without the policy nothing is checked at all
+	writeFile(t, filepath.Join(dir, ".mission", "config.json"), `{}`)
+	os.Remove(argsFile)
+	r.tick()
+	if calls := mcCalls(t, argsFile); len(calls) != 0 {
+		t.Errorf("mc calls = %q with auto_restart off, want none", calls)
+	}
+}
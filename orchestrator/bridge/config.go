@@ -2,14 +2,470 @@ package bridge
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/ollama"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+)
+
+// Offline providers
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai" // any OpenAI-compatible server: LM Studio, vLLM, OpenRouter, ...
 )
 
 // ProjectConfig represents the offline mode settings from .mission/config.json
 type ProjectConfig struct {
-	Mode        string `json:"mode,omitempty"`        // "online" or "offline"
-	OllamaModel string `json:"ollamaModel,omitempty"` // e.g. "qwen2.5-coder:32b"
+	Mode        string          `json:"mode,omitempty"`        // "online" or "offline"
+	Provider    string          `json:"provider,omitempty"`    // offline backend: "ollama" (default) or "openai"
+	OllamaModel string          `json:"ollamaModel,omitempty"` // e.g. "qwen2.5-coder:32b"
+	OllamaURL   string          `json:"ollamaURL,omitempty"`   // e.g. "http://gpu-box:11434"; default localhost
+	OpenAI      *OpenAIConfig   `json:"openai,omitempty"`      // for provider "openai"
+	Runtimes    *RuntimeConfig  `json:"runtimes,omitempty"`    // worker CLI per persona/zone
+	Limits      *LimitsConfig   `json:"limits,omitempty"`      // concurrent workers
+	Webhooks    []webhook.Hook  `json:"webhooks,omitempty"`    // event notifications
+	Roles       identity.Roles  `json:"roles,omitempty"`       // API access per user
+	EnvProfiles EnvProfiles     `json:"envProfiles,omitempty"` // extra worker environment per zone
+	Resources   *ResourceConfig `json:"resources,omitempty"`   // worker time and memory bounds
+	Restart     *RestartConfig  `json:"restart,omitempty"`     // restart policy per agent type
+	Recordings  bool            `json:"recordings,omitempty"`  // record worker output to .mission/recordings
+	Permissions *Permissions    `json:"permissions,omitempty"` // tools workers may use without asking
+	Models      *ModelConfig    `json:"models,omitempty"`      // model and generation settings per persona
+}
+
+// LimitsConfig caps how many workers run at once, in total and per zone;
+// zero or absent means no limit. Workers over a limit are queued.
+//
+//	"limits": {"maxWorkers": 5, "zones": {"backend": 2}}
+type LimitsConfig struct {
+	MaxWorkers int            `json:"maxWorkers,omitempty"`
+	Zones      map[string]int `json:"zones,omitempty"`
+}
+
+// Allows reports whether one more worker may start in zone, given how
+// many are running in total and in that zone. A nil config allows all.
+func (c *LimitsConfig) Allows(zone string, running, inZone int) bool {
+	if c == nil {
+		return true
+	}
+	if c.MaxWorkers > 0 && running >= c.MaxWorkers {
+		return false
+	}
+	if max := c.Zones[zone]; max > 0 && inZone >= max {
+		return false
+	}
+	return true
+}
+
+// ResourceConfig bounds each worker process by persona; a persona's
+// limits override the default's one field at a time, and zero or absent
+// means unbounded:
+//
+//	"resources": {"default": {"timeout": "1h", "maxMemoryMB": 4096}, "personas": {"researcher": {"timeout": "20m"}}}
+type ResourceConfig struct {
+	Default  ResourceLimits            `json:"default"`
+	Personas map[string]ResourceLimits `json:"personas,omitempty"`
+}
+
+// ResourceLimits are one worker's bounds. Timeout is a Go duration
+// ("30m", "1h30m") of wall-clock time.
+type ResourceLimits struct {
+	Timeout     string `json:"timeout,omitempty"`
+	MaxMemoryMB int    `json:"maxMemoryMB,omitempty"`
+}
+
+// For returns the limits for persona. A nil config has none.
+func (c *ResourceConfig) For(persona string) ResourceLimits {
+	if c == nil {
+		return ResourceLimits{}
+	}
+	l := c.Default
+	if p, ok := c.Personas[persona]; ok {
+		if p.Timeout != "" {
+			l.Timeout = p.Timeout
+		}
+		if p.MaxMemoryMB > 0 {
+			l.MaxMemoryMB = p.MaxMemoryMB
+		}
+	}
+	return l
+}
+
+// TimeoutDuration parses Timeout; an empty, invalid or non-positive
+// timeout is none.
+func (l ResourceLimits) TimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(l.Timeout)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// IsZero reports whether l bounds nothing.
+func (l ResourceLimits) IsZero() bool {
+	return l.TimeoutDuration() == 0 && l.MaxMemoryMB <= 0
+}
+
+// ModelConfig picks the model and generation settings of each persona's
+// workers; a persona's settings override the default's one field at a
+// time:
+//
+//	"models": {"default": {"model": "sonnet", "offlineModel": "qwen3-coder", "runtimes": {"codex": "o4-mini"}}, "personas": {"researcher": {"model": "haiku"}, "architect": {"model": "opus", "temperature": 0.2}}}
+type ModelConfig struct {
+	Default  ModelSettings            `json:"default"`
+	Personas map[string]ModelSettings `json:"personas,omitempty"`
+}
+
+// ModelSettings are one persona's model and generation settings. Model is
+// Claude Code's online: a Claude tier ("opus", "sonnet", "haiku") or a
+// model ID. Runtimes holds the online model of each other worker CLI
+// ("codex", "gemini", "aider"), which otherwise runs on its own default.
+// OfflineModel is used in offline mode, in place of the provider's model.
+// Temperature and MaxTokens apply where MissionControl calls the model
+// itself; the worker CLIs don't take them.
+type ModelSettings struct {
+	Model        string            `json:"model,omitempty"`
+	Runtimes     map[string]string `json:"runtimes,omitempty"`
+	OfflineModel string            `json:"offlineModel,omitempty"`
+	Temperature  *float64          `json:"temperature,omitempty"`
+	MaxTokens    int               `json:"maxTokens,omitempty"`
+}
+
+// For returns the settings for persona. A nil config has none.
+func (c *ModelConfig) For(persona string) ModelSettings {
+	if c == nil {
+		return ModelSettings{}
+	}
+	s := c.Default
+	if p, ok := c.Personas[persona]; ok {
+		if p.Model != "" {
+			s.Model = p.Model
+		}
+		if len(p.Runtimes) > 0 {
+			runtimes := make(map[string]string, len(s.Runtimes)+len(p.Runtimes))
+			for rt, model := range s.Runtimes {
+				runtimes[rt] = model
+			}
+			for rt, model := range p.Runtimes {
+				runtimes[rt] = model
+			}
+			s.Runtimes = runtimes
+		}
+		if p.OfflineModel != "" {
+			s.OfflineModel = p.OfflineModel
+		}
+		if p.Temperature != nil {
+			s.Temperature = p.Temperature
+		}
+		if p.MaxTokens > 0 {
+			s.MaxTokens = p.MaxTokens
+		}
+	}
+	return s
+}
+
+// Restart policies
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// Default restart backoff: the first restart waits Backoff, each one
+// after that twice as long, up to MaxBackoff.
+const (
+	DefaultRestartBackoff    = time.Second
+	DefaultRestartMaxBackoff = 5 * time.Minute
+)
+
+// RestartConfig sets how the manager restarts agents that exit, by agent
+// type ("claude-code", "aider", ...); a type's policy replaces the
+// default:
+//
+//	"restart": {"default": {"policy": "on-failure", "maxAttempts": 3}, "types": {"custom": {"policy": "always", "backoff": "5s"}}}
+type RestartConfig struct {
+	Default RestartPolicy            `json:"default"`
+	Types   map[string]RestartPolicy `json:"types,omitempty"`
+}
+
+// RestartPolicy is one agent type's policy. MaxAttempts caps consecutive
+// restarts (zero means no cap); Backoff and MaxBackoff are Go durations.
+type RestartPolicy struct {
+	Policy      string `json:"policy,omitempty"` // "never" (default), "on-failure" or "always"
+	MaxAttempts int    `json:"maxAttempts,omitempty"`
+	Backoff     string `json:"backoff,omitempty"`
+	MaxBackoff  string `json:"maxBackoff,omitempty"`
+}
+
+// For returns the policy for agentType. A nil config never restarts.
+func (c *RestartConfig) For(agentType string) RestartPolicy {
+	if c == nil {
+		return RestartPolicy{}
+	}
+	if p, ok := c.Types[agentType]; ok {
+		return p
+	}
+	return c.Default
+}
+
+// Restarts reports whether an agent that exited, failed or not, should be
+// restarted by the policy at all, before MaxAttempts is considered.
+func (p RestartPolicy) Restarts(failed bool) bool {
+	switch p.Policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return failed
+	}
+	return false
+}
+
+// Delay is how long to wait before restart number attempt (from 1):
+// Backoff doubled for each earlier attempt, capped at MaxBackoff.
+func (p RestartPolicy) Delay(attempt int) time.Duration {
+	base := parseDuration(p.Backoff, DefaultRestartBackoff)
+	max := parseDuration(p.MaxBackoff, DefaultRestartMaxBackoff)
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// parseDuration parses s, falling back to def for an empty, invalid or
+// non-positive duration.
+func parseDuration(s string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// Permissions lists the tools agents may use without asking, in
+// Claude Code's allowed-tools syntax: a tool name, or a tool with a
+// command that must match exactly or, ending in "*", by prefix:
+//
+//	"permissions": {"autoApprove": ["Read", "Grep", "Bash(go test:*)"]}
+type Permissions struct {
+	AutoApprove []string `json:"autoApprove,omitempty"`
+}
+
+// Allows reports whether tool, run with command (empty if unknown), is
+// auto-approved. A nil config approves nothing.
+func (c *Permissions) Allows(tool, command string) bool {
+	if c == nil || tool == "" {
+		return false
+	}
+	for _, rule := range c.AutoApprove {
+		name, spec, scoped := strings.Cut(strings.TrimSuffix(rule, ")"), "(")
+		if name != tool {
+			continue
+		}
+		if !scoped {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(spec, "*"); ok {
+			prefix = strings.TrimSuffix(prefix, ":")
+			if command != "" && strings.HasPrefix(command, prefix) {
+				return true
+			}
+		} else if command == spec {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvProfiles add environment variables to workers by zone. "*" applies
+// to every zone and a zone's own values win; "secret:NAME" values are
+// read from the secrets store:
+//
+//	"envProfiles": {"*": {"LOG_LEVEL": "debug"}, "backend": {"DATABASE_URL": "secret:DATABASE_URL"}}
+type EnvProfiles map[string]map[string]string
+
+// For returns the environment for a worker in zone as sorted NAME=value
+// pairs, and the same variables as recorded on the worker: secret
+// references as written and any stored secret values masked. A missing
+// secret is an error, so a worker never starts without its credentials.
+func (p EnvProfiles) For(zone string) (env []string, record map[string]string, err error) {
+	merged := map[string]string{}
+	for _, profile := range []string{"*", zone} {
+		for name, value := range p[profile] {
+			merged[name] = value
+		}
+	}
+	if len(merged) == 0 {
+		return nil, nil, nil
+	}
+	record = make(map[string]string, len(merged))
+	for name, value := range merged {
+		resolved, err := secrets.Resolve(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("env profile %s: %w", name, err)
+		}
+		env = append(env, name+"="+resolved)
+		if strings.HasPrefix(value, secrets.RefPrefix) {
+			record[name] = value
+		} else {
+			record[name] = secrets.Redact(value)
+		}
+	}
+	sort.Strings(env)
+	return env, record, nil
+}
+
+// RuntimeClaudeCode is the built-in worker CLI, the one Claude tiers are
+// meant for.
+const RuntimeClaudeCode = "claude-code"
+
+// RuntimeConfig picks the worker CLI ("claude-code", "codex", "gemini"):
+//
+//	"runtimes": {"default": "claude-code", "personas": {"researcher": "gemini"}, "zones": {"frontend": "codex"}}
+//
+// A persona's runtime wins over its zone's.
+type RuntimeConfig struct {
+	Default  string            `json:"default,omitempty"`
+	Personas map[string]string `json:"personas,omitempty"`
+	Zones    map[string]string `json:"zones,omitempty"`
+}
+
+// For returns the runtime for a worker, or "" for the built-in default.
+func (c *RuntimeConfig) For(persona, zone string) string {
+	if c == nil {
+		return ""
+	}
+	if rt, ok := c.Personas[persona]; ok {
+		return rt
+	}
+	if rt, ok := c.Zones[zone]; ok {
+		return rt
+	}
+	return c.Default
+}
+
+// OpenAIConfig is an OpenAI-compatible backend:
+//
+//	"provider": "openai",
+//	"openai": {"baseURL": "http://localhost:1234/v1", "apiKeyEnv": "LMSTUDIO_KEY", "model": "qwen2.5-coder-32b"}
+type OpenAIConfig struct {
+	BaseURL   string `json:"baseURL"`             // up to and including /v1
+	APIKey    string `json:"apiKey,omitempty"`    // or "secret:NAME", or read from APIKeyEnv
+	APIKeyEnv string `json:"apiKeyEnv,omitempty"` // variable holding the key
+	Model     string `json:"model,omitempty"`
+}
+
+// Key returns the API key, reading APIKeyEnv when no key is inline and
+// the secrets store for a "secret:NAME" key.
+func (c *OpenAIConfig) Key(getenv func(string) string) string {
+	if c.APIKey == "" && c.APIKeyEnv != "" {
+		return getenv(c.APIKeyEnv)
+	}
+	key, err := secrets.Resolve(c.APIKey)
+	if err != nil {
+		log.Printf("[bridge] openai apiKey: %v", err)
+		return ""
+	}
+	return key
+}
+
+// ProviderName returns the offline provider, defaulting to Ollama.
+func (c *ProjectConfig) ProviderName() string {
+	if c.Provider == "" {
+		return ProviderOllama
+	}
+	return c.Provider
+}
+
+// Model returns the offline provider's model.
+func (c *ProjectConfig) Model() string {
+	if c.ProviderName() == ProviderOpenAI && c.OpenAI != nil {
+		return c.OpenAI.Model
+	}
+	return c.OllamaModel
+}
+
+// WorkerModel returns the model persona's workers run on with runtime,
+// the worker CLI ("" is Claude Code): offline, the persona's offlineModel
+// or else the provider's model; online, the persona's model for that
+// runtime, or "" for the runtime's default. A Claude tier is only ever
+// given to Claude Code.
+func (c *ProjectConfig) WorkerModel(persona, runtime string) string {
+	s := c.Models.For(persona)
+	if c.Mode == "offline" {
+		if s.OfflineModel != "" {
+			return s.OfflineModel
+		}
+		return c.Model()
+	}
+	if runtime == "" || runtime == RuntimeClaudeCode {
+		return s.Model
+	}
+	return s.Runtimes[runtime]
+}
+
+// ChatOptions returns persona's generation settings as options for a chat
+// request to the offline provider: temperature and num_predict for
+// Ollama, temperature and max_tokens for an OpenAI-compatible server. It
+// returns nil when none are set.
+func (c *ProjectConfig) ChatOptions(persona string) map[string]interface{} {
+	s := c.Models.For(persona)
+	opts := map[string]interface{}{}
+	if s.Temperature != nil {
+		opts["temperature"] = *s.Temperature
+	}
+	if s.MaxTokens > 0 {
+		if c.ProviderName() == ProviderOpenAI {
+			opts["max_tokens"] = s.MaxTokens
+		} else {
+			opts["num_predict"] = s.MaxTokens
+		}
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts
+}
+
+// WorkerEnv returns the environment that points a Claude Code worker at
+// the offline provider, or nil in online mode. Claude Code speaks the
+// Anthropic Messages API, so an OpenAI-compatible server must also accept
+// that (OpenRouter, LiteLLM and recent vLLM do); the OPENAI_* variables
+// are set for runtimes that use the OpenAI API directly.
+func (c *ProjectConfig) WorkerEnv(getenv func(string) string) []string {
+	if c.Mode != "offline" {
+		return nil
+	}
+	env := []string{"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC=1"}
+	if c.ProviderName() == ProviderOpenAI && c.OpenAI != nil {
+		key := c.OpenAI.Key(getenv)
+		return append(env,
+			"ANTHROPIC_BASE_URL="+strings.TrimSuffix(strings.TrimSuffix(c.OpenAI.BaseURL, "/"), "/v1"),
+			"ANTHROPIC_AUTH_TOKEN="+key,
+			"OPENAI_BASE_URL="+c.OpenAI.BaseURL,
+			"OPENAI_API_KEY="+key,
+			"OPENAI_MODEL="+c.OpenAI.Model,
+		)
+	}
+	url := c.OllamaURL
+	if url == "" {
+		url = ollama.DefaultBaseURL
+	}
+	return append(env,
+		"ANTHROPIC_BASE_URL="+url,
+		"ANTHROPIC_AUTH_TOKEN=ollama",
+	)
 }
 
 // LoadProjectConfig loads config from .mission/config.json
@@ -0,0 +1,254 @@
+package bridge
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+func TestWorkerEnv(t *testing.T) {
+	getenv := func(name string) string {
+		if name == "ROUTER_KEY" {
+			return "sk-router"
+		}
+		return ""
+	}
+
+	t.Run("online mode sets nothing", func(t *testing.T) {
+		if env := (&ProjectConfig{Mode: "online", OllamaModel: "qwen"}).WorkerEnv(getenv); env != nil {
+			t.Errorf("expected no env, got %v", env)
+		}
+	})
+
+	t.Run("ollama defaults to localhost", func(t *testing.T) {
+		env := strings.Join((&ProjectConfig{Mode: "offline"}).WorkerEnv(getenv), " ")
+		if !strings.Contains(env, "ANTHROPIC_BASE_URL=http://localhost:11434") || !strings.Contains(env, "ANTHROPIC_AUTH_TOKEN=ollama") {
+			t.Errorf("unexpected env %s", env)
+		}
+	})
+
+	t.Run("openai reads the key from the environment", func(t *testing.T) {
+		cfg := &ProjectConfig{
+			Mode:     "offline",
+			Provider: ProviderOpenAI,
+			OpenAI:   &OpenAIConfig{BaseURL: "https://openrouter.ai/api/v1", APIKeyEnv: "ROUTER_KEY", Model: "qwen/qwen3-coder"},
+		}
+		env := strings.Join(cfg.WorkerEnv(getenv), " ")
+		for _, want := range []string{
+			"ANTHROPIC_BASE_URL=https://openrouter.ai/api ",
+			"ANTHROPIC_AUTH_TOKEN=sk-router",
+			"OPENAI_BASE_URL=https://openrouter.ai/api/v1",
+			"OPENAI_MODEL=qwen/qwen3-coder",
+		} {
+			if !strings.Contains(env+" ", want) {
+				t.Errorf("env %s missing %s", env, want)
+			}
+		}
+		if cfg.Model() != "qwen/qwen3-coder" {
+			t.Errorf("Model() = %q", cfg.Model())
+		}
+	})
+}
+
+func TestRuntimeFor(t *testing.T) {
+	cfg := &RuntimeConfig{
+		Default:  "claude-code",
+		Personas: map[string]string{"researcher": "gemini"},
+		Zones:    map[string]string{"frontend": "codex"},
+	}
+	tests := []struct{ persona, zone, want string }{
+		{"researcher", "frontend", "gemini"},
+		{"developer", "frontend", "codex"},
+		{"developer", "backend", "claude-code"},
+	}
+	for _, tt := range tests {
+		if got := cfg.For(tt.persona, tt.zone); got != tt.want {
+			t.Errorf("For(%s, %s) = %q, want %q", tt.persona, tt.zone, got, tt.want)
+		}
+	}
+	if got := (*RuntimeConfig)(nil).For("developer", "backend"); got != "" {
+		t.Errorf("nil config = %q", got)
+	}
+}
+
+func TestLimitsAllows(t *testing.T) {
+	cfg := &LimitsConfig{MaxWorkers: 5, Zones: map[string]int{"backend": 2}}
+	tests := []struct {
+		zone            string
+		running, inZone int
+		want            bool
+	}{
+		{"backend", 1, 1, true},
+		{"backend", 2, 2, false},
+		{"frontend", 4, 4, true},
+		{"frontend", 5, 3, false},
+	}
+	for _, tt := range tests {
+		if got := cfg.Allows(tt.zone, tt.running, tt.inZone); got != tt.want {
+			t.Errorf("Allows(%s, %d, %d) = %v, want %v", tt.zone, tt.running, tt.inZone, got, tt.want)
+		}
+	}
+	if !(*LimitsConfig)(nil).Allows("backend", 100, 100) {
+		t.Error("nil config should allow every worker")
+	}
+}
+
+func TestResourceLimitsFor(t *testing.T) {
+	cfg := &ResourceConfig{
+		Default:  ResourceLimits{Timeout: "1h", MaxMemoryMB: 4096},
+		Personas: map[string]ResourceLimits{"researcher": {Timeout: "20m"}},
+	}
+	if got := cfg.For("researcher"); got.TimeoutDuration() != 20*time.Minute || got.MaxMemoryMB != 4096 {
+		t.Errorf("researcher limits = %+v, want the 20m timeout over the default memory", got)
+	}
+	if got := cfg.For("developer"); got.TimeoutDuration() != time.Hour {
+		t.Errorf("developer limits = %+v, want the default", got)
+	}
+	if !(*ResourceConfig)(nil).For("developer").IsZero() {
+		t.Error("nil config should bound nothing")
+	}
+	if !(ResourceLimits{Timeout: "soon"}).IsZero() {
+		t.Error("an invalid timeout should be none")
+	}
+}
+
+func TestWorkerModel(t *testing.T) {
+	low := 0.2
+	cfg := &ProjectConfig{
+		OllamaModel: "llama3",
+		Models: &ModelConfig{
+			Default: ModelSettings{Model: "sonnet", Runtimes: map[string]string{"codex": "o4-mini"}, MaxTokens: 4096},
+			Personas: map[string]ModelSettings{
+				"researcher": {Model: "haiku"},
+				"architect":  {Model: "opus", Temperature: &low},
+				"developer":  {OfflineModel: "qwen3-coder"},
+			},
+		},
+	}
+	for persona, want := range map[string]string{"researcher": "haiku", "architect": "opus", "developer": "sonnet", "docs": "sonnet"} {
+		if got := cfg.WorkerModel(persona, ""); got != want {
+			t.Errorf("online %s model = %q, want %q", persona, got, want)
+		}
+	}
+	if got := cfg.WorkerModel("architect", RuntimeClaudeCode); got != "opus" {
+		t.Errorf("claude-code architect model = %q, want opus", got)
+	}
+	if got := cfg.WorkerModel("architect", "codex"); got != "o4-mini" {
+		t.Errorf("codex architect model = %q, want o4-mini", got)
+	}
+	if got := cfg.WorkerModel("architect", "gemini"); got != "" {
+		t.Errorf("gemini architect model = %q, want gemini's default, not a Claude tier", got)
+	}
+	cfg.Mode = "offline"
+	if got := cfg.WorkerModel("developer", ""); got != "qwen3-coder" {
+		t.Errorf("offline developer model = %q, want qwen3-coder", got)
+	}
+	if got := cfg.WorkerModel("researcher", ""); got != "llama3" {
+		t.Errorf("offline researcher model = %q, want the provider's", got)
+	}
+
+	opts := cfg.ChatOptions("architect")
+	if opts["temperature"] != 0.2 || opts["num_predict"] != 4096 {
+		t.Errorf("architect options = %v", opts)
+	}
+	cfg.Provider = ProviderOpenAI
+	if opts := cfg.ChatOptions("researcher"); opts["max_tokens"] != 4096 || opts["temperature"] != nil {
+		t.Errorf("researcher options = %v", opts)
+	}
+	if got := (&ProjectConfig{}).WorkerModel("developer", ""); got != "" {
+		t.Errorf("unconfigured model = %q, want the runtime's default", got)
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	cfg := &RestartConfig{
+		Default: RestartPolicy{Policy: RestartOnFailure},
+		Types:   map[string]RestartPolicy{"custom": {Policy: RestartAlways, Backoff: "2s", MaxBackoff: "5s"}},
+	}
+	if p := cfg.For("claude-code"); !p.Restarts(true) || p.Restarts(false) {
+		t.Errorf("on-failure policy %+v restarts wrongly", p)
+	}
+	if p := cfg.For("custom"); !p.Restarts(false) {
+		t.Errorf("always policy %+v didn't restart a clean exit", p)
+	}
+	if (*RestartConfig)(nil).For("custom").Restarts(true) {
+		t.Error("nil config should never restart")
+	}
+
+	custom := cfg.For("custom")
+	for attempt, want := range map[int]time.Duration{1: 2 * time.Second, 2: 4 * time.Second, 3: 5 * time.Second, 10: 5 * time.Second} {
+		if got := custom.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+	if got := cfg.For("claude-code").Delay(1); got != DefaultRestartBackoff {
+		t.Errorf("default Delay(1) = %s", got)
+	}
+}
+
+func TestPermissionsAllows(t *testing.T) {
+	p := &Permissions{AutoApprove: []string{"Read", "Bash(go test:*)", "Bash(make lint)"}}
+	for _, tt := range []struct {
+		tool, command string
+		want          bool
+	}{
+		{"Read", "", true},
+		{"Edit", "", false},
+		{"Bash", "go test ./...", true},
+		{"Bash", "make lint", true},
+		{"Bash", "make lint fix", false},
+		{"Bash", "rm -rf /", false},
+		{"Bash", "", false},
+	} {
+		if got := p.Allows(tt.tool, tt.command); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.tool, tt.command, got, tt.want)
+		}
+	}
+	if (*Permissions)(nil).Allows("Read", "") {
+		t.Error("nil permissions allowed a tool")
+	}
+}
+
+func TestEnvProfilesFor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	store, err := secrets.Open(filepath.Join(home, ".mission-control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("DATABASE_URL", "postgres://app:pw@db/app")
+
+	profiles := EnvProfiles{
+		"*":       {"LOG_LEVEL": "info", "REGION": "eu"},
+		"backend": {"LOG_LEVEL": "debug", "DATABASE_URL": "secret:DATABASE_URL", "DSN_COPY": "postgres://app:pw@db/app"},
+	}
+
+	env, record, err := profiles.For("backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DATABASE_URL=postgres://app:pw@db/app DSN_COPY=postgres://app:pw@db/app LOG_LEVEL=debug REGION=eu"
+	if got := strings.Join(env, " "); got != want {
+		t.Errorf("env = %s\nwant  %s", got, want)
+	}
+	if record["DATABASE_URL"] != "secret:DATABASE_URL" || record["DSN_COPY"] != secrets.Mask || record["LOG_LEVEL"] != "debug" {
+		t.Errorf("record = %v, want references kept and values masked", record)
+	}
+
+	if env, _, _ := profiles.For("frontend"); strings.Join(env, " ") != "LOG_LEVEL=info REGION=eu" {
+		t.Errorf("frontend env = %v, want the * profile", env)
+	}
+	if env, record, err := EnvProfiles(nil).For("backend"); env != nil || record != nil || err != nil {
+		t.Errorf("no profiles = %v, %v, %v", env, record, err)
+	}
+
+	profiles["backend"]["API_TOKEN"] = "secret:MISSING"
+	if _, _, err := profiles.For("backend"); err == nil || !strings.Contains(err.Error(), "API_TOKEN") {
+		t.Errorf("missing secret: err %v, want it named", err)
+	}
+}
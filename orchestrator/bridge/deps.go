@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"sort"
+	"strings"
+)
+
+// CycleError reports a task dependency cycle. Cycle lists the task IDs
+// in dependency order, ending where it started.
+type CycleError struct {
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Cycle, " → ")
+}
+
+// FindCycle returns a dependency cycle in deps (task ID → the IDs it
+// depends on), or nil if there is none. Dependencies on unknown tasks are
+// ignored. Tasks are visited in ID order so the same graph always reports
+// the same cycle.
+func FindCycle(deps map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(deps))
+	var stack []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range deps[id] {
+			if _, known := deps[dep]; !known {
+				continue
+			}
+			switch state[dep] {
+			case visiting:
+				for i, s := range stack {
+					if s == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+		return nil
+	}
+
+	ids := make([]string, 0, len(deps))
+	for id := range deps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// CheckDependencies returns a *CycleError if deps has a cycle.
+func CheckDependencies(deps map[string][]string) error {
+	if cycle := FindCycle(deps); cycle != nil {
+		return &CycleError{Cycle: cycle}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package bridge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[string][]string
+		want []string
+	}{
+		{"none", map[string][]string{"a": nil, "b": {"a"}, "c": {"a", "b"}}, nil},
+		{"unknown dep", map[string][]string{"a": {"gone"}}, nil},
+		{"self", map[string][]string{"a": {"a"}}, []string{"a", "a"}},
+		{"pair", map[string][]string{"a": {"b"}, "b": {"a"}}, []string{"a", "b", "a"}},
+		{"behind a chain", map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"d"}, "d": {"b"}}, []string{"b", "c", "d", "b"}},
+	}
+	for _, tt := range tests {
+		if got := FindCycle(tt.deps); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: FindCycle = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	if err := CheckDependencies(map[string][]string{"a": {"b"}, "b": nil}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := CheckDependencies(map[string][]string{"a": {"b"}, "b": {"a"}})
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("err = %v, want *CycleError", err)
+	}
+	if err.Error() != "dependency cycle: a → b → a" {
+		t.Errorf("message = %q", err.Error())
+	}
+}
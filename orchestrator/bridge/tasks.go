@@ -0,0 +1,262 @@
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+// PersonaManual marks a task for a human assignee: no worker is spawned
+// for it.
+const PersonaManual = "manual"
+
+// TaskStatusArchived marks a cancelled or duplicate task. Archived tasks
+// stay in tasks.jsonl but are left out of listings, the graph and gates.
+const TaskStatusArchived = "archived"
+
+// Statuses mc gives a task as its worker is queued, runs and stops.
+const (
+	TaskStatusQueued     = "queued"      // its worker waits for a slot
+	TaskStatusInProgress = "in_progress" // its worker is running
+	TaskStatusBlocked    = "blocked"     // its worker was killed or died
+)
+
+// Task is a task as mc writes it to tasks.jsonl, one JSON object a line.
+// mc, the api, the watcher and serve all share it.
+type Task struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Stage         string   `json:"stage"`
+	Zone          string   `json:"zone"`
+	Persona       string   `json:"persona"`
+	Assignee      string   `json:"assignee,omitempty"` // person responsible, for persona "manual" tasks
+	Status        string   `json:"status"`             // pending, queued, in_progress, complete, blocked, archived
+	DependsOn     []string `json:"depends_on,omitempty"`
+	Dependencies  []string `json:"dependencies,omitempty"` // older name for depends_on
+	ScopePaths    []string `json:"scope_paths,omitempty"`
+	Estimate      float64  `json:"estimate,omitempty"` // relative effort for the critical path
+	Labels        []string `json:"labels,omitempty"`   // free-form, e.g. tech-debt, security
+	Spec          string   `json:"spec,omitempty"`     // spec it implements; SpecHash is its hash then
+	SpecHash      string   `json:"spec_hash,omitempty"`
+	WorkerID      string   `json:"worker_id,omitempty"`
+	ArchivedFrom  string   `json:"archived_from,omitempty"` // status to restore on unarchive
+	ArchiveReason string   `json:"archive_reason,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+
+	// Extra holds the line's fields Task doesn't have, e.g. ones a newer
+	// mc or a user wrote, so they survive a decode and encode.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// taskFields are the JSON names of Task's fields.
+var taskFields = func() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(Task{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// MarshalJSON encodes t with its Extra fields after the known ones.
+func (t Task) MarshalJSON() ([]byte, error) {
+	type plain Task
+	data, err := json.Marshal(plain(t))
+	if err != nil || len(t.Extra) == 0 {
+		return data, err
+	}
+	keys := make([]string, 0, len(t.Extra))
+	for k := range t.Extra {
+		if !taskFields[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for _, k := range keys {
+		name, _ := json.Marshal(k)
+		buf.WriteByte(',')
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(t.Extra[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Deps returns the IDs the task depends on: "dependencies" if the line
+// has it, else "depends_on".
+func (t Task) Deps() []string {
+	if t.Dependencies != nil {
+		return t.Dependencies
+	}
+	return t.DependsOn
+}
+
+// Finished reports whether the task is done and no longer gates anything.
+func (t Task) Finished() bool {
+	return t.Status == "done" || t.Status == "complete"
+}
+
+// UnmarshalJSON decodes a task, keeping the fields Task doesn't have in
+// Extra.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type plain Task
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for k, v := range fields {
+		if !taskFields[k] {
+			if p.Extra == nil {
+				p.Extra = map[string]json.RawMessage{}
+			}
+			p.Extra[k] = v
+		}
+	}
+	*t = Task(p)
+	return nil
+}
+
+// DecodeTask decodes one tasks.jsonl line. A value of the wrong type,
+// anything after the object or a missing id is an error. Fields Task
+// doesn't have are kept in Extra rather than refused, so every reader
+// sees the same tasks mc does.
+func DecodeTask(line []byte) (Task, error) {
+	var t Task
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if err := dec.Decode(&t); err != nil {
+		return Task{}, err
+	}
+	if dec.More() {
+		return Task{}, fmt.Errorf("unexpected data after the task")
+	}
+	if t.ID == "" {
+		return Task{}, fmt.Errorf("task has no id")
+	}
+	return t, nil
+}
+
+// ReadTasks reads the tasks.jsonl file at path, a later line for a task
+// superseding the earlier ones as LatestTaskLines keeps them. Lines that
+// don't decode are left out and returned in invalid, one error each
+// naming its line.
+func ReadTasks(path string) (tasks []Task, invalid []error, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		t, err := DecodeTask(line)
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("line %d: %w", n, err))
+			continue
+		}
+		if i, seen := index[t.ID]; seen {
+			tasks[i] = t
+			continue
+		}
+		index[t.ID] = len(tasks)
+		tasks = append(tasks, t)
+	}
+	return tasks, invalid, scanner.Err()
+}
+
+// NormalizeLabels trims and lowercases task labels and drops duplicates,
+// keeping the order in which they first appear. A label may not be empty
+// or contain whitespace or commas.
+func NormalizeLabels(labels []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || strings.ContainsAny(l, ", \t\n") {
+			return nil, fmt.Errorf("invalid label %q: labels must be non-empty, without spaces or commas", l)
+		}
+		if !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// LatestTaskLines returns the lines of a tasks.jsonl file with blank lines
+// and superseded entries removed. When several lines share a task ID the
+// last one wins, kept at the position of the first. Lines without an ID
+// are kept as they are.
+func LatestTaskLines(data []byte) (lines [][]byte, superseded int) {
+	index := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		line = append([]byte(nil), line...)
+		var t struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(line, &t) == nil && t.ID != "" {
+			if i, seen := index[t.ID]; seen {
+				lines[i] = line
+				superseded++
+				continue
+			}
+			index[t.ID] = len(lines)
+		}
+		lines = append(lines, line)
+	}
+	return lines, superseded
+}
+
+// CompactTasks rewrites the tasks.jsonl file at path without superseded
+// entries and returns how many it dropped. The file is left alone if
+// nothing is superseded. The rewrite holds the jsonl lock, so tasks
+// appended meanwhile are compacted with the rest instead of lost.
+func CompactTasks(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if _, superseded := LatestTaskLines(data); superseded == 0 {
+		return 0, nil
+	}
+
+	var superseded int
+	err = jsonl.Update(path, jsonl.Options{}, func(lines [][]byte) ([][]byte, error) {
+		var kept [][]byte
+		kept, superseded = LatestTaskLines(bytes.Join(lines, []byte("\n")))
+		return kept, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return superseded, nil
+}
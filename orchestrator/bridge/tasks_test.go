@@ -0,0 +1,148 @@
+package bridge
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompactTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	os.WriteFile(path, []byte(`{"id":"a","status":"pending"}
+
+{"id":"b","status":"pending"}
+{"note":"no id"}
+{"id":"a","status":"done"}
+{"id":"b","status":"archived"}
+`), 0644)
+
+	n, err := CompactTasks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("superseded = %d, want 2", n)
+	}
+	data, _ := os.ReadFile(path)
+	want := `{"id":"a","status":"done"}
+{"id":"b","status":"archived"}
+{"note":"no id"}
+`
+	if string(data) != want {
+		t.Errorf("compacted =\n%s\nwant\n%s", data, want)
+	}
+
+	info, _ := os.Stat(path)
+	if n, err := CompactTasks(path); err != nil || n != 0 {
+		t.Errorf("second compaction = %d, %v", n, err)
+	}
+	if after, _ := os.Stat(path); !after.ModTime().Equal(info.ModTime()) {
+		t.Error("a compact file should not be rewritten")
+	}
+
+	if n, err := CompactTasks(filepath.Join(t.TempDir(), "missing.jsonl")); err != nil || n != 0 {
+		t.Errorf("missing file = %d, %v", n, err)
+	}
+}
+
+func TestNormalizeLabels(t *testing.T) {
+	got, err := NormalizeLabels([]string{" Security", "tech-debt", "security"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "security" || got[1] != "tech-debt" {
+		t.Errorf("labels = %q", got)
+	}
+	for _, bad := range []string{"", " ", "tech debt", "a,b"} {
+		if _, err := NormalizeLabels([]string{bad}); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestTaskRoundTrip(t *testing.T) {
+	// Every field mc writes, in its order
+	line := `{"id":"t1","name":"Login form","stage":"implement","zone":"frontend","persona":"developer",` +
+		`"assignee":"ana","status":"archived","depends_on":["t0"],"scope_paths":["web/src"],"estimate":2.5,` +
+		`"labels":["ui"],"spec":"auth","spec_hash":"abc123","worker_id":"w1","archived_from":"pending",` +
+		`"archive_reason":"duplicate","created_at":"2026-03-01T10:00:00Z","updated_at":"2026-03-02T10:00:00Z"}`
+	task, err := DecodeTask([]byte(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != line {
+		t.Errorf("round trip changed the task:\n got %s\nwant %s", out, line)
+	}
+	if deps := task.Deps(); len(deps) != 1 || deps[0] != "t0" {
+		t.Errorf("deps = %v", deps)
+	}
+}
+
+func TestDecodeTaskRejects(t *testing.T) {
+	for _, line := range []string{
+		`{"id":"t1","estimate":"3"}`,
+		`{"id":"t1","labels":"ui"}`,
+		`{"id":"t1"} {"id":"t2"}`,
+		`{"name":"no id"}`,
+		`not json`,
+	} {
+		if _, err := DecodeTask([]byte(line)); err == nil {
+			t.Errorf("expected %s to be rejected", line)
+		}
+	}
+	task, err := DecodeTask([]byte(`{"id":"t1","dependencies":["a"],"depends_on":["b"]}`))
+	if err != nil || task.Deps()[0] != "a" {
+		t.Errorf("expected the older dependencies field to win, got %v, %v", task.Deps(), err)
+	}
+}
+
+func TestDecodeTaskKeepsUnknownFields(t *testing.T) {
+	task, err := DecodeTask([]byte(`{"id":"t1","priority":2,"status":"pending","owner":{"team":"web"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != "pending" || string(task.Extra["priority"]) != "2" || len(task.Extra) != 2 {
+		t.Errorf("task = %+v", task)
+	}
+	out, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"t1","name":"","stage":"","zone":"","persona":"","status":"pending","created_at":"","updated_at":"","owner":{"team":"web"},"priority":2}`
+	if string(out) != want {
+		t.Errorf("encoded as %s, want %s", out, want)
+	}
+
+	// Plain json.Unmarshal, as mc uses, keeps them too
+	var again Task
+	if err := json.Unmarshal(out, &again); err != nil || string(again.Extra["owner"]) != `{"team":"web"}` {
+		t.Errorf("unmarshalled %+v, %v", again, err)
+	}
+}
+
+func TestReadTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	os.WriteFile(path, []byte(`{"id":"a","status":"pending"}
+{"id":"b","status":"pending","estimate":"big"}
+
+{"id":"c","status":"pending"}
+{"id":"a","status":"done"}
+`), 0644)
+
+	tasks, invalid, err := ReadTasks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != "a" || tasks[0].Status != "done" || !tasks[0].Finished() || tasks[1].ID != "c" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+	if len(invalid) != 1 || !strings.HasPrefix(invalid[0].Error(), "line 2:") {
+		t.Errorf("invalid = %v", invalid)
+	}
+}
@@ -0,0 +1,165 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Zone is a project zone as kept in .mission/state/zones.json:
+//
+//	{"zones": [{"name": "backend", "color": "#3b82f6", "paths": ["orchestrator/", "*.go"], "maxWorkers": 2}]}
+//
+// Paths use the scope path syntax: "dir/" prefixes, globs or exact files.
+type Zone struct {
+	Name       string   `json:"name"`
+	Color      string   `json:"color,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+	MaxWorkers int      `json:"maxWorkers,omitempty"` // concurrent workers; 0 = no limit
+}
+
+// ZonesState is the zones.json structure
+type ZonesState struct {
+	Zones []Zone `json:"zones"`
+}
+
+// ZoneColors are given in turn to zones added without a color.
+var ZoneColors = []string{"#3b82f6", "#10b981", "#f59e0b", "#ef4444", "#8b5cf6", "#ec4899", "#14b8a6", "#6b7280"}
+
+var (
+	zoneNameRe  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	zoneColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+)
+
+// Validate checks the zone's name, color, paths and limit.
+func (z *Zone) Validate() error {
+	if !zoneNameRe.MatchString(z.Name) {
+		return fmt.Errorf("invalid zone name %q: use letters, digits, '.', '_' and '-'", z.Name)
+	}
+	if z.Color != "" && !zoneColorRe.MatchString(z.Color) {
+		return fmt.Errorf("invalid color %q: use #rgb or #rrggbb", z.Color)
+	}
+	for _, p := range z.Paths {
+		if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, "..") {
+			return fmt.Errorf("invalid path %q: paths are relative to the project root", p)
+		}
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid path %q: %v", p, err)
+		}
+	}
+	if z.MaxWorkers < 0 {
+		return fmt.Errorf("maxWorkers must not be negative")
+	}
+	return nil
+}
+
+// Owns reports whether file, relative to the project root, is in one of
+// the zone's paths.
+func (z *Zone) Owns(file string) bool {
+	for _, p := range z.Paths {
+		if MatchPath(file, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether file matches a scope pattern: a directory
+// prefix (pattern ends with /), a glob, or an exact path. Patterns
+// without / also match the file's base name.
+func MatchPath(file, pattern string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(file, pattern)
+	}
+	if file == pattern {
+		return true
+	}
+	if matched, err := filepath.Match(pattern, file); err == nil && matched {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		if matched, err := filepath.Match(pattern, filepath.Base(file)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadZones reads zones.json at path. A missing file means no zones.
+func LoadZones(path string) ([]Zone, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state ZonesState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return state.Zones, nil
+}
+
+// SaveZones writes zones to path, sorted by name.
+func SaveZones(path string, zones []Zone) error {
+	if zones == nil {
+		zones = []Zone{}
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	data, err := json.MarshalIndent(ZonesState{Zones: zones}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// FindZone returns the zone called name, or nil.
+func FindZone(zones []Zone, name string) *Zone {
+	for i := range zones {
+		if zones[i].Name == name {
+			return &zones[i]
+		}
+	}
+	return nil
+}
+
+// ZoneOf returns the first zone that owns file, or "".
+func ZoneOf(zones []Zone, file string) string {
+	for i := range zones {
+		if zones[i].Owns(file) {
+			return zones[i].Name
+		}
+	}
+	return ""
+}
+
+// WithZones adds the zones' worker limits to c. Limits set in
+// config.json's "limits" section win over a zone's maxWorkers. The
+// result is nil if neither sets a limit.
+func (c *LimitsConfig) WithZones(zones []Zone) *LimitsConfig {
+	out := &LimitsConfig{Zones: map[string]int{}}
+	if c != nil {
+		out.MaxWorkers = c.MaxWorkers
+		for z, n := range c.Zones {
+			out.Zones[z] = n
+		}
+	}
+	for _, z := range zones {
+		if _, set := out.Zones[z.Name]; !set && z.MaxWorkers > 0 {
+			out.Zones[z.Name] = z.MaxWorkers
+		}
+	}
+	if out.MaxWorkers == 0 && len(out.Zones) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package bridge
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestZoneValidate(t *testing.T) {
+	valid := []Zone{
+		{Name: "backend"},
+		{Name: "web-ui", Color: "#10b981", Paths: []string{"web/", "*.tsx"}, MaxWorkers: 2},
+		{Name: "db", Color: "#abc"},
+	}
+	for _, z := range valid {
+		if err := z.Validate(); err != nil {
+			t.Errorf("%+v: %v", z, err)
+		}
+	}
+	invalid := []Zone{
+		{Name: ""},
+		{Name: "a/b"},
+		{Name: "x", Color: "blue"},
+		{Name: "x", Paths: []string{"../outside/"}},
+		{Name: "x", Paths: []string{"/etc/"}},
+		{Name: "x", Paths: []string{"src/[a"}},
+		{Name: "x", MaxWorkers: -1},
+	}
+	for _, z := range invalid {
+		if err := z.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", z)
+		}
+	}
+}
+
+func TestZoneOwns(t *testing.T) {
+	zones := []Zone{
+		{Name: "backend", Paths: []string{"orchestrator/", "go.mod"}},
+		{Name: "web", Paths: []string{"web/*.ts", "*.css"}},
+	}
+	cases := map[string]string{
+		"orchestrator/api/zones.go": "backend",
+		"go.mod":                    "backend",
+		"web/index.ts":              "web",
+		"web/src/deep.ts":           "",
+		"assets/site.css":           "web",
+		"README.md":                 "",
+	}
+	for file, want := range cases {
+		if got := ZoneOf(zones, file); got != want {
+			t.Errorf("ZoneOf(%s) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestLoadSaveZones(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zones.json")
+	zones, err := LoadZones(path)
+	if err != nil || zones != nil {
+		t.Fatalf("missing file: %v, %v", zones, err)
+	}
+	if err := SaveZones(path, []Zone{{Name: "web"}, {Name: "api", MaxWorkers: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	zones, err = LoadZones(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 || zones[0].Name != "api" || FindZone(zones, "web") == nil {
+		t.Errorf("zones = %+v, want api and web sorted", zones)
+	}
+}
+
+func TestLimitsWithZones(t *testing.T) {
+	zones := []Zone{{Name: "backend", MaxWorkers: 3}, {Name: "web", MaxWorkers: 1}, {Name: "docs"}}
+	limits := (&LimitsConfig{MaxWorkers: 5, Zones: map[string]int{"backend": 2}}).WithZones(zones)
+	if limits.MaxWorkers != 5 || limits.Zones["backend"] != 2 || limits.Zones["web"] != 1 {
+		t.Errorf("limits = %+v: config.json should win, zones fill in", limits)
+	}
+	if _, ok := limits.Zones["docs"]; ok {
+		t.Error("a zone without maxWorkers should not be limited")
+	}
+
+	var none *LimitsConfig
+	if none.WithZones([]Zone{{Name: "docs"}}) != nil {
+		t.Error("no limits anywhere should stay nil")
+	}
+	if got := none.WithZones(zones); got == nil || got.Allows("web", 0, 1) {
+		t.Errorf("zone limits alone = %+v, want web limited to 1", got)
+	}
+}
@@ -0,0 +1,347 @@
+// Package briefing compiles the markdown briefing a King session resumes
+// from after mc checkpoint restart. It fits the checkpoint into a token
+// budget, set under "briefing_tokens" in .mission/config.json:
+//
+//	"briefing_tokens": 800
+//
+// The header (stage, session, task counts, gates) is always included.
+// The rest is filled in priority order until the budget runs out:
+// decisions, open blockers, in-flight tasks, the recent King
+// conversation, then recent findings. A
+// section that doesn't fit whole ends with how many items were left out.
+// Newer decisions and findings are kept over older ones.
+//
+// PackTask does the same for worker briefings: it packs a task's
+// predecessor handoffs, spec excerpts and related findings into the
+// worker's budget and reports what was left out.
+package briefing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/core"
+	"github.com/MikeSquared-Agency/MissionControl/report"
+)
+
+// DefaultBudget is the briefing size in tokens when config.json sets no
+// briefing_tokens.
+const DefaultBudget = 500
+
+// Task is the part of a checkpointed task the briefing uses.
+type Task struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Persona string `json:"persona,omitempty"`
+	Status  string `json:"status"`
+}
+
+// Input is what a briefing is compiled from: a checkpoint plus the
+// mission's findings, newest first.
+type Input struct {
+	CheckpointID string
+	SessionID    string
+	Stage        string
+	Tasks        []Task
+	Gates        map[string]string // stage → gate status
+	Decisions    []string          // oldest first
+	Blockers     []string
+	Conversation []string         // recent King messages, oldest first
+	Findings     []report.Finding // newest first
+}
+
+// Options adjust compilation. Zero values use the defaults.
+type Options struct {
+	Budget int              // tokens; DefaultBudget if not positive
+	Count  func(string) int // token counter; mc-core, else an estimate
+}
+
+// Compile renders in as a briefing of at most opts.Budget tokens (the
+// header alone may exceed a tiny budget) and returns it with its size.
+func Compile(in Input, opts Options) (string, int) {
+	if opts.Budget <= 0 {
+		opts.Budget = DefaultBudget
+	}
+	if opts.Count == nil {
+		opts.Count = counter()
+	}
+
+	var b strings.Builder
+	b.WriteString(header(in))
+	used := opts.Count(b.String())
+
+	for _, s := range sections(in) {
+		text, n := s.fit(opts.Budget-used, opts.Count)
+		if text == "" {
+			continue
+		}
+		b.WriteString(text)
+		used += n
+	}
+	// Line counts add up to a little more than the whole, so count again
+	return b.String(), opts.Count(b.String())
+}
+
+// counter counts tokens with core.CountTokens (cl100k_base), switching to
+// EstimateTokens for good once mc-core fails, so a briefing compiled
+// without mc-core installed doesn't look for it on every line.
+func counter() func(string) int {
+	return CounterFor("")
+}
+
+// CounterFor returns a token counter using model's tokenizer, exact with
+// mc-core where it can be and estimated otherwise. After mc-core fails
+// once the counter keeps estimating rather than retrying per call.
+func CounterFor(model string) func(string) int {
+	encoding := core.EncodingForModel(model)
+	estimate := encoding == core.EncodingLlama
+	return func(text string) int {
+		if !estimate {
+			if n, err := core.CountTokensWithEncoding(text, encoding); err == nil {
+				return n
+			}
+			estimate = true
+		}
+		if encoding == core.EncodingCl100k {
+			return EstimateTokens(text)
+		}
+		return core.EstimateTokens(text, encoding)
+	}
+}
+
+// EstimateTokens approximates a token count at four characters a token.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// LoadBudget reads briefing_tokens from config.json in missionDir.
+func LoadBudget(missionDir string) int {
+	var cfg struct {
+		BriefingTokens int `json:"briefing_tokens"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	if cfg.BriefingTokens <= 0 {
+		return DefaultBudget
+	}
+	return cfg.BriefingTokens
+}
+
+// ConversationMessages is how many King transcript messages
+// RecentConversation returns.
+const ConversationMessages = 10
+
+// conversationChars caps each message in the briefing.
+const conversationChars = 200
+
+// RecentConversation reads the newest King transcript messages under
+// missionDir, oldest first, one line each: the speaker and the message,
+// shortened to 200 characters.
+func RecentConversation(missionDir string) []string {
+	messages, _, err := chat.NewTranscript(missionDir).History(chat.Query{Limit: ConversationMessages})
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, m := range messages {
+		text := strings.Join(strings.Fields(m.Content), " ")
+		if text == "" {
+			continue
+		}
+		if r := []rune(text); len(r) > conversationChars {
+			text = string(r[:conversationChars]) + "…"
+		}
+		speaker := "King"
+		if m.Role == "user" || m.Role == "human" {
+			speaker = "User"
+		}
+		out = append(out, speaker+": "+text)
+	}
+	return out
+}
+
+// RecentFindings reads the findings under missionDir, newest file first
+// and most severe first within a file.
+func RecentFindings(missionDir string) []report.Finding {
+	dir := filepath.Join(missionDir, "findings")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type file struct {
+		taskID  string
+		modTime int64
+	}
+	var files []file
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, file{strings.TrimSuffix(e.Name(), ".json"), info.ModTime().UnixNano()})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+
+	byTask := map[string][]report.Finding{}
+	for _, group := range report.Findings(missionDir) {
+		for _, f := range group.Findings {
+			byTask[f.TaskID] = append(byTask[f.TaskID], f)
+		}
+	}
+	var out []report.Finding
+	for _, f := range files {
+		out = append(out, byTask[f.taskID]...)
+	}
+	return out
+}
+
+func header(in Input) string {
+	var b strings.Builder
+	b.WriteString("# Session Briefing\n\n")
+	fmt.Fprintf(&b, "**Stage:** %s\n", in.Stage)
+	if in.SessionID != "" {
+		fmt.Fprintf(&b, "**Previous Session:** %s\n", in.SessionID)
+	}
+	if in.CheckpointID != "" {
+		fmt.Fprintf(&b, "**Checkpoint:** %s\n", in.CheckpointID)
+	}
+
+	counts := map[string]int{}
+	for _, t := range in.Tasks {
+		counts[taskState(t.Status)]++
+	}
+	fmt.Fprintf(&b, "**Tasks:** %d total, %d done, %d in flight, %d pending, %d blocked\n",
+		len(in.Tasks), counts["done"], counts["in_flight"], counts["pending"], counts["blocked"])
+
+	var approved []string
+	for stage, status := range in.Gates {
+		if status == "approved" {
+			approved = append(approved, stage)
+		}
+	}
+	if len(approved) > 0 {
+		sort.Strings(approved)
+		fmt.Fprintf(&b, "**Gates Approved:** %s\n", strings.Join(approved, ", "))
+	}
+	return b.String()
+}
+
+// taskState buckets a task status for the briefing.
+func taskState(status string) string {
+	switch status {
+	case "complete", "done", "archived":
+		return "done"
+	case "in_progress", "queued":
+		return "in_flight"
+	case "blocked":
+		return "blocked"
+	}
+	return "pending"
+}
+
+// section is a heading and its items, most important first.
+type section struct {
+	title string
+	items []string
+	// chronological sections are printed oldest first but trimmed from
+	// the front, so the newest items survive
+	chronological bool
+}
+
+func sections(in Input) []section {
+	decisions := make([]string, 0, len(in.Decisions))
+	for i := len(in.Decisions) - 1; i >= 0; i-- {
+		decisions = append(decisions, in.Decisions[i])
+	}
+
+	blockers := append([]string{}, in.Blockers...)
+	var inFlight []string
+	for _, t := range in.Tasks {
+		switch taskState(t.Status) {
+		case "blocked":
+			blockers = append(blockers, "Task "+describeTask(t)+" is blocked")
+		case "in_flight":
+			inFlight = append(inFlight, describeTask(t)+" ("+t.Status+")")
+		}
+	}
+
+	conversation := make([]string, 0, len(in.Conversation))
+	for i := len(in.Conversation) - 1; i >= 0; i-- {
+		conversation = append(conversation, in.Conversation[i])
+	}
+
+	findings := make([]string, 0, len(in.Findings))
+	for _, f := range in.Findings {
+		findings = append(findings, fmt.Sprintf("[%s] %s (%s)", f.Severity, f.Summary, f.TaskID))
+	}
+
+	return []section{
+		{title: "Decisions", items: decisions, chronological: true},
+		{title: "Open Blockers", items: blockers},
+		{title: "In Flight", items: inFlight},
+		{title: "Recent Conversation", items: conversation, chronological: true},
+		{title: "Recent Findings", items: findings},
+	}
+}
+
+func describeTask(t Task) string {
+	s := t.ID
+	if t.Name != "" {
+		s += " " + t.Name
+	}
+	if t.Persona != "" {
+		s += " [" + t.Persona + "]"
+	}
+	return s
+}
+
+// fit renders as many of the section's items as fit in budget tokens,
+// with a closing line counting the rest, and returns the text and its
+// size. It returns "" if not even the heading and one item fit.
+func (s section) fit(budget int, count func(string) int) (string, int) {
+	if len(s.items) == 0 {
+		return "", 0
+	}
+	heading := "\n## " + s.title + "\n"
+	used := count(heading)
+	var kept []string
+	for i, item := range s.items {
+		line := "- " + item + "\n"
+		n := count(line)
+		rest := 0
+		if i < len(s.items)-1 {
+			rest = count(moreLine(len(s.items) - i - 1))
+		}
+		if used+n+rest > budget {
+			break
+		}
+		kept = append(kept, line)
+		used += n
+	}
+	if len(kept) == 0 {
+		return "", 0
+	}
+	if s.chronological {
+		for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+			kept[i], kept[j] = kept[j], kept[i]
+		}
+	}
+	text := heading + strings.Join(kept, "")
+	if left := len(s.items) - len(kept); left > 0 {
+		more := moreLine(left)
+		text += more
+		used += count(more)
+	}
+	return text, used
+}
+
+func moreLine(n int) string {
+	return fmt.Sprintf("- … and %d more\n", n)
+}
@@ -0,0 +1,151 @@
+package briefing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/report"
+)
+
+func sampleInput() Input {
+	return Input{
+		CheckpointID: "cp-20260301-120000",
+		SessionID:    "s-1",
+		Stage:        "implement",
+		Tasks: []Task{
+			{ID: "t1", Name: "Schema", Status: "complete"},
+			{ID: "t2", Name: "Handlers", Persona: "developer", Status: "in_progress"},
+			{ID: "t3", Name: "Migrations", Status: "blocked"},
+			{ID: "t4", Name: "Docs", Status: "pending"},
+		},
+		Gates:     map[string]string{"design": "approved", "implement": "pending"},
+		Decisions: []string{"Use Postgres", "REST over gRPC", "Cursor pagination"},
+		Blockers:  []string{"Waiting on staging credentials"},
+		Findings: []report.Finding{
+			{TaskID: "t1", Severity: "high", Summary: "Missing index on users.email"},
+			{TaskID: "t1", Severity: "low", Summary: "Rename column"},
+		},
+	}
+}
+
+func TestCompileWithinBudget(t *testing.T) {
+	text, used := Compile(sampleInput(), Options{Budget: 1000, Count: EstimateTokens})
+
+	for _, want := range []string{
+		"**Stage:** implement",
+		"**Checkpoint:** cp-20260301-120000",
+		"**Tasks:** 4 total, 1 done, 1 in flight, 1 pending, 1 blocked",
+		"**Gates Approved:** design",
+		"## Decisions\n- Use Postgres\n- REST over gRPC\n- Cursor pagination\n",
+		"- Waiting on staging credentials",
+		"- Task t3 Migrations is blocked",
+		"## In Flight\n- t2 Handlers [developer] (in_progress)",
+		"- [high] Missing index on users.email (t1)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("briefing lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "more\n") {
+		t.Errorf("nothing should be cut at this budget:\n%s", text)
+	}
+	if used != EstimateTokens(text) {
+		t.Errorf("reported %d tokens, text is %d", used, EstimateTokens(text))
+	}
+}
+
+func TestCompileTrimsByPriority(t *testing.T) {
+	in := sampleInput()
+	full, _ := Compile(in, Options{Budget: 1000, Count: EstimateTokens})
+	headerOnly := EstimateTokens(header(in))
+
+	// Room for the header and a little more: decisions come first and
+	// keep the newest
+	budget := headerOnly + 14
+	text, used := Compile(in, Options{Budget: budget, Count: EstimateTokens})
+	if used > budget {
+		t.Errorf("used %d tokens, budget %d", used, budget)
+	}
+	if !strings.Contains(text, "- Cursor pagination\n") || strings.Contains(text, "Use Postgres") {
+		t.Errorf("trimmed briefing should keep the newest decision:\n%s", text)
+	}
+	if !strings.Contains(text, "more\n") {
+		t.Errorf("trimmed briefing should say what was left out:\n%s", text)
+	}
+	if strings.Contains(text, "Recent Findings") {
+		t.Errorf("findings should go before decisions:\n%s", text)
+	}
+	if len(text) >= len(full) {
+		t.Errorf("trimmed briefing is no shorter than the full one")
+	}
+
+	// A budget the header already fills still yields the header
+	text, _ = Compile(in, Options{Budget: 1, Count: EstimateTokens})
+	if text != header(in) {
+		t.Errorf("tiny budget = %q, want only the header", text)
+	}
+}
+
+func TestRecentFindingsNewestFirst(t *testing.T) {
+	mission := t.TempDir()
+	dir := filepath.Join(mission, "findings")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string, age time.Duration) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(-age)
+		os.Chtimes(path, when, when)
+	}
+	write("old.json", `[{"type":"risk","summary":"Old","severity":"critical"}]`, time.Hour)
+	write("new.json", `[{"type":"note","summary":"Minor","severity":"low"},{"type":"bug","summary":"Major","severity":"high"}]`, time.Minute)
+
+	var got []string
+	for _, f := range RecentFindings(mission) {
+		got = append(got, f.TaskID+":"+f.Summary)
+	}
+	if strings.Join(got, ",") != "new:Major,new:Minor,old:Old" {
+		t.Errorf("findings = %v, want new's (high first) before old's", got)
+	}
+}
+
+func TestRecentConversation(t *testing.T) {
+	mission := t.TempDir()
+	store := chat.NewTranscript(mission)
+	store.Append(chat.Message{Role: "user", Content: "Too old"})
+	store.Append(chat.Message{Role: "assistant", Content: strings.Repeat("x", 300)})
+	for i := 0; i < ConversationMessages-1; i++ {
+		store.Append(chat.Message{Role: "assistant", Content: "ok"})
+	}
+
+	// The oldest message is left out, long ones are shortened
+	got := RecentConversation(mission)
+	if len(got) != ConversationMessages || got[0] != "King: "+strings.Repeat("x", 200)+"…" {
+		t.Fatalf("conversation = %q", got)
+	}
+
+	in := sampleInput()
+	in.Conversation = []string{"User: Ship the login page", "King: On it"}
+	text, _ := Compile(in, Options{Budget: 1000, Count: EstimateTokens})
+	if !strings.Contains(text, "## Recent Conversation\n- User: Ship the login page\n- King: On it\n") {
+		t.Errorf("briefing without the conversation:\n%s", text)
+	}
+}
+
+func TestLoadBudget(t *testing.T) {
+	mission := t.TempDir()
+	if got := LoadBudget(mission); got != DefaultBudget {
+		t.Errorf("no config: budget %d, want %d", got, DefaultBudget)
+	}
+	os.WriteFile(filepath.Join(mission, "config.json"), []byte(`{"briefing_tokens": 800}`), 0644)
+	if got := LoadBudget(mission); got != 800 {
+		t.Errorf("budget = %d, want 800", got)
+	}
+}
@@ -0,0 +1,129 @@
+package briefing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// How the King prompt reaches the King, set by "king.inject" in
+// config.json.
+const (
+	InjectSystem  = "system"  // appended to the system prompt (default)
+	InjectMessage = "message" // prepended to the first message
+)
+
+// KingConfig is the "king" section of config.json:
+//
+//	"king": {"instructions": "Prefer small PRs.", "prompt_file": "king.md", "inject": "system"}
+//
+// prompt_file is relative to .mission/.
+type KingConfig struct {
+	Instructions string `json:"instructions,omitempty"`
+	PromptFile   string `json:"prompt_file,omitempty"`
+	Inject       string `json:"inject,omitempty"`
+}
+
+// KingPrompt is the King's effective prompt and what it was built from.
+type KingPrompt struct {
+	Text    string   `json:"text"`
+	Inject  string   `json:"inject"`
+	Stage   string   `json:"stage,omitempty"`
+	Sources []string `json:"sources"` // in order: files, "config", "personas", "stage"
+	Tokens  int      `json:"tokens"`
+}
+
+// LoadKingPrompt assembles the King prompt for missionDir from CLAUDE.md,
+// the king section of config.json, the personas enabled for the active
+// stage (by the matrix, else the personas section) and the stage itself.
+// Missing pieces are left out; a prompt_file that can't be read is an
+// error.
+func LoadKingPrompt(missionDir string) (KingPrompt, error) {
+	var cfg struct {
+		King     KingConfig `json:"king"`
+		Personas map[string]struct {
+			Enabled bool `json:"enabled"`
+		} `json:"personas"`
+		Matrix []struct {
+			Stage   string `json:"stage"`
+			Zone    string `json:"zone"`
+			Persona string `json:"persona"`
+			Enabled bool   `json:"enabled"`
+		} `json:"matrix"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return KingPrompt{}, fmt.Errorf("config.json: %w", err)
+		}
+	}
+	var stage struct {
+		Current string `json:"current"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "state", "stage.json")); err == nil {
+		_ = json.Unmarshal(data, &stage)
+	}
+
+	p := KingPrompt{Inject: InjectSystem, Stage: stage.Current, Sources: []string{}}
+	if cfg.King.Inject == InjectMessage {
+		p.Inject = InjectMessage
+	}
+	var parts []string
+
+	if data, err := os.ReadFile(filepath.Join(missionDir, "CLAUDE.md")); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		parts = append(parts, strings.TrimSpace(string(data)))
+		p.Sources = append(p.Sources, "CLAUDE.md")
+	}
+	if cfg.King.PromptFile != "" {
+		data, err := os.ReadFile(filepath.Join(missionDir, filepath.Clean(cfg.King.PromptFile)))
+		if err != nil {
+			return KingPrompt{}, fmt.Errorf("king prompt_file: %w", err)
+		}
+		parts = append(parts, strings.TrimSpace(string(data)))
+		p.Sources = append(p.Sources, cfg.King.PromptFile)
+	}
+	if s := strings.TrimSpace(cfg.King.Instructions); s != "" {
+		parts = append(parts, "## Project Instructions\n\n"+s)
+		p.Sources = append(p.Sources, "config")
+	}
+
+	// Personas: per zone for the stage from the matrix, else the enabled
+	// personas from the personas section
+	var personas []string
+	if stage.Current != "" && len(cfg.Matrix) > 0 {
+		byZone := map[string][]string{}
+		for _, c := range cfg.Matrix {
+			if c.Enabled && c.Stage == stage.Current {
+				byZone[c.Zone] = append(byZone[c.Zone], c.Persona)
+			}
+		}
+		for zone, names := range byZone {
+			sort.Strings(names)
+			personas = append(personas, fmt.Sprintf("- %s: %s", zone, strings.Join(names, ", ")))
+		}
+	} else {
+		for name, pc := range cfg.Personas {
+			if pc.Enabled {
+				personas = append(personas, "- "+name)
+			}
+		}
+	}
+	if len(personas) > 0 {
+		sort.Strings(personas)
+		parts = append(parts, "## Available Personas\n\n"+strings.Join(personas, "\n"))
+		p.Sources = append(p.Sources, "personas")
+	}
+	if stage.Current != "" {
+		parts = append(parts, fmt.Sprintf("## Current Stage\n\nThe mission is in the %s stage. Only spawn workers for %s tasks until its gate is approved.", stage.Current, stage.Current))
+		p.Sources = append(p.Sources, "stage")
+	}
+
+	p.Text = strings.Join(parts, "\n\n")
+	if p.Text != "" {
+		p.Text += "\n"
+	}
+	p.Tokens = counter()(p.Text)
+	return p, nil
+}
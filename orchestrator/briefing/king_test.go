@@ -0,0 +1,60 @@
+package briefing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadKingPrompt(t *testing.T) {
+	mission := t.TempDir()
+	os.MkdirAll(filepath.Join(mission, "state"), 0755)
+	os.WriteFile(filepath.Join(mission, "CLAUDE.md"), []byte("# OpenClaw\n\nYou coordinate.\n"), 0644)
+	os.WriteFile(filepath.Join(mission, "king.md"), []byte("Ship weekly."), 0644)
+	os.WriteFile(filepath.Join(mission, "config.json"), []byte(`{
+		"king": {"instructions": "Prefer small PRs.", "prompt_file": "king.md"},
+		"personas": {"designer": {"enabled": true}},
+		"matrix": [
+			{"stage": "design", "zone": "frontend", "persona": "designer", "enabled": true},
+			{"stage": "design", "zone": "frontend", "persona": "architect", "enabled": true},
+			{"stage": "design", "zone": "backend", "persona": "designer", "enabled": false},
+			{"stage": "implement", "zone": "backend", "persona": "developer", "enabled": true}
+		]}`), 0644)
+	os.WriteFile(filepath.Join(mission, "state", "stage.json"), []byte(`{"current": "design"}`), 0644)
+
+	p, err := LoadKingPrompt(mission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Inject != InjectSystem || p.Stage != "design" || p.Tokens == 0 {
+		t.Errorf("prompt = %+v", p)
+	}
+	if got := strings.Join(p.Sources, ","); got != "CLAUDE.md,king.md,config,personas,stage" {
+		t.Errorf("sources = %s", got)
+	}
+	for _, want := range []string{"You coordinate.", "Ship weekly.", "Prefer small PRs.", "- frontend: architect, designer", "design stage"} {
+		if !strings.Contains(p.Text, want) {
+			t.Errorf("prompt missing %q:\n%s", want, p.Text)
+		}
+	}
+	if strings.Contains(p.Text, "developer") || strings.Contains(p.Text, "backend") {
+		t.Errorf("personas of other stages or disabled cells included:\n%s", p.Text)
+	}
+
+	// Without a stage the personas section is used
+	os.Remove(filepath.Join(mission, "state", "stage.json"))
+	os.WriteFile(filepath.Join(mission, "config.json"), []byte(`{"king": {"inject": "message"}, "personas": {"designer": {"enabled": true}, "qa": {"enabled": false}}}`), 0644)
+	p, err = LoadKingPrompt(mission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Inject != InjectMessage || !strings.Contains(p.Text, "- designer") || strings.Contains(p.Text, "qa") {
+		t.Errorf("prompt = %+v", p)
+	}
+
+	os.WriteFile(filepath.Join(mission, "config.json"), []byte(`{"king": {"prompt_file": "missing.md"}}`), 0644)
+	if _, err := LoadKingPrompt(mission); err == nil {
+		t.Error("missing prompt_file not reported")
+	}
+}
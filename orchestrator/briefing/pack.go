@@ -0,0 +1,435 @@
+package briefing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/report"
+)
+
+// DefaultPackBudget is the worker briefing size in tokens when none is
+// given.
+const DefaultPackBudget = 2000
+
+// Kinds of packed context, in the order they are packed.
+const (
+	KindTask    = "task"
+	KindHandoff = "handoff"
+	KindSpec    = "spec"
+	KindFinding = "finding"
+)
+
+// ErrTaskNotFound is returned by PackTask for an unknown task.
+var ErrTaskNotFound = errors.New("task not found")
+
+// Pack is a worker briefing packed into a token budget. Included and
+// Omitted list each piece of context considered, with its size; omitted
+// pieces did not fit.
+type Pack struct {
+	TaskID   string     `json:"task_id"`
+	Budget   int        `json:"budget"`
+	Tokens   int        `json:"tokens"`
+	Briefing string     `json:"briefing"`
+	Included []PackItem `json:"included"`
+	Omitted  []PackItem `json:"omitted"`
+}
+
+// PackItem is one piece of context: the task itself, a predecessor's
+// handoff, a spec section or a finding from a related task.
+type PackItem struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	Tokens int    `json:"tokens"`
+}
+
+// packTask is a task as read from tasks.jsonl.
+type packTask struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Stage      string   `json:"stage"`
+	Zone       string   `json:"zone"`
+	Persona    string   `json:"persona"`
+	Status     string   `json:"status"`
+	DependsOn  []string `json:"depends_on"`
+	ScopePaths []string `json:"scope_paths"`
+}
+
+// piece is a candidate for the pack, rendered under a section heading.
+type piece struct {
+	item    PackItem
+	section string
+	text    string
+}
+
+// PackTask assembles the briefing for taskID from the mission in
+// missionDir (.mission): the task, then its predecessors' handoffs
+// (direct dependencies first), the spec sections that mention the task's
+// name, zone or scope, and findings from other tasks in its zone or
+// stage, most severe first. Pieces are added whole, in that order, while
+// they fit in budget tokens (DefaultPackBudget if not positive); the
+// task itself is always included. count may be nil.
+func PackTask(missionDir, taskID string, budget int, count func(string) int) (*Pack, error) {
+	if budget <= 0 {
+		budget = DefaultPackBudget
+	}
+	if count == nil {
+		count = counter()
+	}
+	tasks, err := loadPackTasks(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]packTask, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	task, ok := byID[taskID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+
+	preds := predecessors(task, byID)
+	pieces := []piece{taskPiece(task)}
+	pieces = append(pieces, handoffPieces(missionDir, preds, byID)...)
+	pieces = append(pieces, specPieces(missionDir, task)...)
+	pieces = append(pieces, findingPieces(missionDir, task, preds, byID)...)
+
+	p := &Pack{TaskID: taskID, Budget: budget, Included: []PackItem{}, Omitted: []PackItem{}}
+	var b strings.Builder
+	used := 0
+	lastSection := ""
+	for i, pc := range pieces {
+		text := pc.text
+		if pc.section != lastSection {
+			text = "\n## " + pc.section + "\n" + text
+		}
+		pc.item.Tokens = count(pc.text)
+		n := count(text)
+		if i > 0 && used+n > budget {
+			p.Omitted = append(p.Omitted, pc.item)
+			continue
+		}
+		b.WriteString(text)
+		used += n
+		lastSection = pc.section
+		p.Included = append(p.Included, pc.item)
+	}
+	p.Briefing = strings.TrimPrefix(b.String(), "\n")
+	p.Tokens = count(p.Briefing)
+	return p, nil
+}
+
+func loadPackTasks(missionDir string) ([]packTask, error) {
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "tasks.jsonl"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	lines, _ := bridge.LatestTaskLines(data)
+	tasks := make([]packTask, 0, len(lines))
+	for _, line := range lines {
+		var t packTask
+		if json.Unmarshal(line, &t) == nil && t.ID != "" {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+// predecessors returns task's dependencies breadth first: direct ones in
+// depends_on order, then theirs.
+func predecessors(task packTask, byID map[string]packTask) []string {
+	var out []string
+	seen := map[string]bool{task.ID: true}
+	queue := append([]string{}, task.DependsOn...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+		if dep, ok := byID[id]; ok {
+			queue = append(queue, dep.DependsOn...)
+		}
+	}
+	return out
+}
+
+func taskPiece(t packTask) piece {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** %s\n", t.ID, t.Name)
+	fmt.Fprintf(&b, "- Stage: %s\n", t.Stage)
+	if t.Zone != "" {
+		fmt.Fprintf(&b, "- Zone: %s\n", t.Zone)
+	}
+	if t.Persona != "" {
+		fmt.Fprintf(&b, "- Persona: %s\n", t.Persona)
+	}
+	if len(t.ScopePaths) > 0 {
+		fmt.Fprintf(&b, "- Scope: %s\n", strings.Join(t.ScopePaths, ", "))
+	}
+	fmt.Fprintf(&b, "- Output: .mission/findings/%s.md\n", t.ID)
+	return piece{item: PackItem{Kind: KindTask, Source: t.ID}, section: "Task", text: b.String()}
+}
+
+// handoff is the part of a stored handoff the pack uses.
+type handoff struct {
+	TaskID        string           `json:"task_id"`
+	Status        string           `json:"status"`
+	Findings      []report.Finding `json:"findings"`
+	Artifacts     []string         `json:"artifacts"`
+	OpenQuestions []string         `json:"open_questions"`
+}
+
+// handoffPieces renders each predecessor's latest handoff, or its
+// findings when no handoff was stored.
+func handoffPieces(missionDir string, preds []string, byID map[string]packTask) []piece {
+	latest := latestHandoffs(filepath.Join(missionDir, "handoffs"))
+	findings := findingsByTask(missionDir)
+
+	var out []piece
+	for _, id := range preds {
+		h, hasHandoff := latest[id]
+		summary := findingsSummary(filepath.Join(missionDir, "findings", id+".md"))
+		if !hasHandoff && summary == "" && len(findings[id]) == 0 {
+			continue
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "### %s %s\n", id, byID[id].Name)
+		if summary != "" {
+			fmt.Fprintf(&b, "Summary: %s\n", summary)
+		}
+		fs := h.Findings
+		if !hasHandoff {
+			fs = findings[id]
+		}
+		for _, f := range fs {
+			fmt.Fprintf(&b, "- [%s] %s\n", f.Severity, f.Summary)
+		}
+		if len(h.Artifacts) > 0 {
+			fmt.Fprintf(&b, "Artifacts: %s\n", strings.Join(h.Artifacts, ", "))
+		}
+		for _, q := range h.OpenQuestions {
+			fmt.Fprintf(&b, "Open question: %s\n", q)
+		}
+		out = append(out, piece{item: PackItem{Kind: KindHandoff, Source: id}, section: "Predecessor Handoffs", text: b.String()})
+	}
+	return out
+}
+
+// latestHandoffs reads the newest stored handoff of each task. Stored
+// handoffs are named <worker>-<timestamp>.json; briefings are skipped.
+func latestHandoffs(dir string) map[string]handoff {
+	entries, _ := os.ReadDir(dir)
+	type stored struct {
+		handoff
+		stamp string
+	}
+	newest := map[string]stored{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") || strings.HasSuffix(name, "-briefing.json") {
+			continue
+		}
+		var h handoff
+		if readJSON(filepath.Join(dir, name), &h) != nil || h.TaskID == "" {
+			continue
+		}
+		// The timestamp is the last two dash-separated fields
+		base := strings.TrimSuffix(name, ".json")
+		stamp := base
+		if parts := strings.Split(base, "-"); len(parts) >= 2 {
+			stamp = strings.Join(parts[len(parts)-2:], "-")
+		}
+		if cur, ok := newest[h.TaskID]; !ok || stamp > cur.stamp {
+			newest[h.TaskID] = stored{h, stamp}
+		}
+	}
+	out := make(map[string]handoff, len(newest))
+	for id, s := range newest {
+		for i := range s.Findings {
+			s.Findings[i].Severity = report.NormaliseSeverity(s.Findings[i].Severity)
+		}
+		out[id] = s.handoff
+	}
+	return out
+}
+
+// findingsSummary returns the Summary: line of a findings markdown file.
+func findingsSummary(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "Summary:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Summary:"))
+		}
+	}
+	return ""
+}
+
+// findingsByTask groups the recorded findings by task, most severe first.
+func findingsByTask(missionDir string) map[string][]report.Finding {
+	out := map[string][]report.Finding{}
+	for _, group := range report.Findings(missionDir) {
+		for _, f := range group.Findings {
+			out[f.TaskID] = append(out[f.TaskID], f)
+		}
+	}
+	return out
+}
+
+// specPieces returns the sections of .mission/specs/*.md that mention the
+// task's keywords, best match first.
+func specPieces(missionDir string, task packTask) []piece {
+	keywords := taskKeywords(task)
+	if len(keywords) == 0 {
+		return nil
+	}
+	files, _ := filepath.Glob(filepath.Join(missionDir, "specs", "*.md"))
+	sort.Strings(files)
+
+	type scored struct {
+		piece
+		score int
+	}
+	var matches []scored
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		name := "specs/" + filepath.Base(file)
+		for _, sec := range splitSections(string(data)) {
+			lower := strings.ToLower(sec.text)
+			score := 0
+			for _, k := range keywords {
+				if strings.Contains(lower, k) {
+					score++
+				}
+			}
+			if score == 0 {
+				continue
+			}
+			source := name
+			if sec.heading != "" {
+				source += "#" + sec.heading
+			}
+			matches = append(matches, scored{piece{
+				item:    PackItem{Kind: KindSpec, Source: source},
+				section: "Spec Excerpts",
+				text:    fmt.Sprintf("### %s\n%s\n", source, strings.TrimSpace(sec.text)),
+			}, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	out := make([]piece, len(matches))
+	for i, m := range matches {
+		out[i] = m.piece
+	}
+	return out
+}
+
+type specSection struct {
+	heading string
+	text    string
+}
+
+// splitSections splits markdown at its headings. Text before the first
+// heading is a section without one.
+func splitSections(md string) []specSection {
+	var out []specSection
+	cur := specSection{}
+	var body []string
+	flush := func() {
+		cur.text = strings.Join(body, "\n")
+		if strings.TrimSpace(cur.text) != "" {
+			out = append(out, cur)
+		}
+		body = nil
+	}
+	for _, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(line, "#") {
+			flush()
+			cur = specSection{heading: strings.TrimSpace(strings.TrimLeft(line, "#"))}
+		}
+		body = append(body, line)
+	}
+	flush()
+	return out
+}
+
+// taskKeywords are the lower-case words a relevant spec section mentions:
+// the longer words of the task name, its zone and its scope paths' base
+// names.
+func taskKeywords(t packTask) []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(w string) {
+		w = strings.ToLower(strings.Trim(w, ".,:;()[]\"'`"))
+		if len(w) < 4 || seen[w] {
+			return
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	for _, w := range strings.Fields(t.Name) {
+		add(w)
+	}
+	add(t.Zone)
+	for _, p := range t.ScopePaths {
+		base := filepath.Base(strings.TrimRight(p, "/*"))
+		add(strings.TrimSuffix(base, filepath.Ext(base)))
+	}
+	return out
+}
+
+// findingPieces returns the findings of other tasks in the task's zone or
+// stage, most severe first, excluding the predecessors' (already in their
+// handoffs).
+func findingPieces(missionDir string, task packTask, preds []string, byID map[string]packTask) []piece {
+	skip := map[string]bool{task.ID: true}
+	for _, id := range preds {
+		skip[id] = true
+	}
+	var out []piece
+	for _, group := range report.Findings(missionDir) {
+		fs := append([]report.Finding{}, group.Findings...)
+		sort.SliceStable(fs, func(i, j int) bool { return fs[i].TaskID < fs[j].TaskID })
+		for _, f := range fs {
+			other, ok := byID[f.TaskID]
+			if skip[f.TaskID] || !ok {
+				continue
+			}
+			if !(task.Zone != "" && other.Zone == task.Zone) && other.Stage != task.Stage {
+				continue
+			}
+			out = append(out, piece{
+				item:    PackItem{Kind: KindFinding, Source: f.TaskID},
+				section: "Related Findings",
+				text:    fmt.Sprintf("- [%s] %s (%s)\n", f.Severity, f.Summary, f.TaskID),
+			})
+		}
+	}
+	return out
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,115 @@
+package briefing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePackMission lays out a mission where t3 depends on t2, which
+// depends on t1.
+func writePackMission(t *testing.T) string {
+	t.Helper()
+	mission := filepath.Join(t.TempDir(), ".mission")
+	files := map[string]string{
+		"state/tasks.jsonl": strings.Join([]string{
+			`{"id":"t1","name":"Design schema","stage":"design","zone":"backend","status":"done"}`,
+			`{"id":"t2","name":"Auth API","stage":"implement","zone":"backend","status":"done","depends_on":["t1"]}`,
+			`{"id":"t3","name":"Session tokens","stage":"implement","zone":"backend","persona":"developer","status":"pending","depends_on":["t2"],"scope_paths":["internal/auth/"]}`,
+			`{"id":"t4","name":"Dashboard","stage":"implement","zone":"frontend","status":"done"}`,
+			`{"id":"t5","name":"Billing","stage":"design","zone":"payments","status":"done"}`,
+		}, "\n") + "\n",
+		"handoffs/dev-1-20260301-100000.json": `{"task_id":"t2","worker_id":"dev-1","status":"complete","findings":[{"type":"discovery","summary":"Old","severity":"low"}]}`,
+		"handoffs/dev-2-20260302-100000.json": `{"task_id":"t2","worker_id":"dev-2","status":"complete","findings":[{"type":"discovery","summary":"JWT chosen","severity":"high"}],"artifacts":["auth.go"],"open_questions":["Refresh lifetime?"]}`,
+		"handoffs/t3-briefing.json":           `{"task_id":"t3"}`,
+		"findings/t1.json":                    `[{"type":"discovery","summary":"Users table has email","severity":"medium"}]`,
+		"findings/t1.md":                      "# t1\nSummary: Schema ready\n",
+		"findings/t4.json":                    `[{"type":"risk","summary":"CSS bloat","severity":"critical"}]`,
+		"findings/t5.json":                    `[{"type":"risk","summary":"Stripe limits","severity":"high"}]`,
+		"specs/auth.md":                       "# Auth\nIntro\n\n## Session tokens\nTokens expire after an hour.\n\n## Passwords\nUse argon2.\n",
+		"specs/ui.md":                         "# Dashboard\nCharts.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(mission, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mission
+}
+
+func sources(items []PackItem) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.Kind + ":" + it.Source
+	}
+	return out
+}
+
+func TestPackTaskPrioritizes(t *testing.T) {
+	mission := writePackMission(t)
+
+	p, err := PackTask(mission, "t3", 10000, EstimateTokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "task:t3,handoff:t2,handoff:t1,spec:specs/auth.md#Session tokens,spec:specs/auth.md#Auth,finding:t4"
+	if got := strings.Join(sources(p.Included), ","); got != want {
+		t.Errorf("included = %s\nwant       %s", got, want)
+	}
+	if len(p.Omitted) != 0 {
+		t.Errorf("omitted = %v, want nothing at this budget", sources(p.Omitted))
+	}
+	for _, s := range []string{"**t3** Session tokens", "- [high] JWT chosen", "Open question: Refresh lifetime?", "Summary: Schema ready", "- [medium] Users table has email", "Tokens expire after an hour.", "- [critical] CSS bloat (t4)"} {
+		if !strings.Contains(p.Briefing, s) {
+			t.Errorf("briefing lacks %q:\n%s", s, p.Briefing)
+		}
+	}
+	if strings.Contains(p.Briefing, "Old") || strings.Contains(p.Briefing, "Stripe") {
+		t.Errorf("briefing has a superseded handoff or an unrelated finding:\n%s", p.Briefing)
+	}
+	if p.Tokens != EstimateTokens(p.Briefing) || p.Tokens > p.Budget {
+		t.Errorf("tokens = %d for a %d-token briefing, budget %d", p.Tokens, EstimateTokens(p.Briefing), p.Budget)
+	}
+}
+
+func TestPackTaskReportsOmitted(t *testing.T) {
+	mission := writePackMission(t)
+	full, _ := PackTask(mission, "t3", 10000, EstimateTokens)
+
+	// Room for the task and the direct predecessor only
+	budget := full.Included[0].Tokens + full.Included[1].Tokens + 15
+	p, err := PackTask(mission, "t3", budget, EstimateTokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(sources(p.Included), ","); !strings.HasPrefix(got, "task:t3,handoff:t2") || strings.Contains(got, "spec:") {
+		t.Errorf("included = %s, want the task and t2's handoff first", got)
+	}
+	if len(p.Omitted) == 0 || p.Tokens > budget {
+		t.Errorf("omitted %v at %d/%d tokens, want spills within budget", sources(p.Omitted), p.Tokens, budget)
+	}
+
+	again, _ := PackTask(mission, "t3", budget, EstimateTokens)
+	if again.Briefing != p.Briefing {
+		t.Errorf("packing is not deterministic")
+	}
+
+	// The task is kept even when it alone is over budget
+	p, _ = PackTask(mission, "t3", 1, EstimateTokens)
+	if len(p.Included) != 1 || p.Included[0].Kind != KindTask {
+		t.Errorf("tiny budget included %v, want the task only", sources(p.Included))
+	}
+}
+
+func TestPackTaskUnknown(t *testing.T) {
+	mission := writePackMission(t)
+	if _, err := PackTask(mission, "nope", 0, EstimateTokens); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("err = %v, want ErrTaskNotFound", err)
+	}
+}
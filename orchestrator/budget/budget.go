@@ -0,0 +1,310 @@
+// Package budget forecasts a mission's spend against the budget set under
+// "budget" in .mission/config.json:
+//
+//	"budget": {"usd": 500, "tokens": 20000000}
+//
+// Zero or absent means no budget in that unit. The orchestrator records
+// the project's token usage by UTC day in .mission/orchestrator/usage.json.
+// The forecast divides the spend so far by the estimate points of the
+// tasks done to get a cost per point, and projects the end-of-mission
+// spend as the spend so far plus that cost for every point still open.
+// A Monitor recomputes the forecast every TickInterval and reports it
+// through its callback and the budget_forecast_exceeded webhook when the
+// projection first goes over budget.
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+)
+
+// LedgerFile holds the project's recorded usage, in .mission/orchestrator.
+const LedgerFile = "usage.json"
+
+// TickInterval is how often a Monitor recomputes the forecast.
+const TickInterval = time.Minute
+
+// burnDays is the window the burn rate and completion rate average over.
+const burnDays = 7
+
+// Budget is the "budget" section of .mission/config.json.
+type Budget struct {
+	USD    float64 `json:"usd,omitempty"`
+	Tokens int     `json:"tokens,omitempty"`
+}
+
+// Usage is tokens spent and what they cost.
+type Usage struct {
+	Tokens  int     `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// Forecast is the projected end-of-mission spend. Method is "backlog"
+// when some tasks are done to price the open ones by, and "none" when
+// the projection is only the spend so far. Exceeds names the budgets the
+// projection goes over: "usd", "tokens" or both.
+type Forecast struct {
+	Budget          Budget   `json:"budget"`
+	Spent           Usage    `json:"spent"`
+	BurnRate        Usage    `json:"burn_rate_per_day"` // over the last seven days
+	PointsDone      float64  `json:"points_done"`
+	PointsRemaining float64  `json:"points_remaining"`
+	PerPoint        Usage    `json:"per_point"`
+	Projected       Usage    `json:"projected"`
+	DaysRemaining   float64  `json:"days_remaining,omitempty"` // at the last seven days' completion rate
+	Method          string   `json:"method"`
+	OverBudget      bool     `json:"over_budget"`
+	Exceeds         []string `json:"exceeds,omitempty"`
+	GeneratedAt     string   `json:"generated_at"`
+}
+
+type ledger struct {
+	Days map[string]Usage `json:"days"` // by UTC date
+}
+
+// mu serialises read-modify-write cycles on ledgers within a process.
+var mu sync.Mutex
+
+// Load reads the budget from config.json in missionDir.
+func Load(missionDir string) Budget {
+	var cfg struct {
+		Budget Budget `json:"budget"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	return cfg.Budget
+}
+
+// Record adds tokens and their cost to today's usage in missionDir.
+func Record(missionDir string, tokens int, cost float64, now time.Time) error {
+	mu.Lock()
+	defer mu.Unlock()
+	l := loadLedger(missionDir)
+	day := now.UTC().Format("2006-01-02")
+	u := l.Days[day]
+	u.Tokens += tokens
+	u.CostUSD += cost
+	l.Days[day] = u
+	return saveLedger(missionDir, l)
+}
+
+// Days returns the recorded usage in missionDir by UTC date
+// (YYYY-MM-DD).
+func Days(missionDir string) map[string]Usage {
+	mu.Lock()
+	defer mu.Unlock()
+	return loadLedger(missionDir).Days
+}
+
+// Compute forecasts the mission's spend at now.
+func Compute(missionDir string, now time.Time) (Forecast, error) {
+	f := Forecast{
+		Budget:      Load(missionDir),
+		Method:      "none",
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+	}
+
+	mu.Lock()
+	l := loadLedger(missionDir)
+	mu.Unlock()
+	since := now.UTC().AddDate(0, 0, -(burnDays - 1)).Format("2006-01-02")
+	var recent Usage
+	for day, u := range l.Days {
+		f.Spent.Tokens += u.Tokens
+		f.Spent.CostUSD += u.CostUSD
+		if day >= since {
+			recent.Tokens += u.Tokens
+			recent.CostUSD += u.CostUSD
+		}
+	}
+	f.BurnRate = Usage{Tokens: recent.Tokens / burnDays, CostUSD: recent.CostUSD / burnDays}
+
+	recentPoints, err := points(missionDir, now.AddDate(0, 0, -burnDays), &f)
+	if err != nil {
+		return f, err
+	}
+
+	f.Projected = f.Spent
+	if f.PointsDone > 0 {
+		f.Method = "backlog"
+		f.PerPoint = Usage{
+			Tokens:  int(float64(f.Spent.Tokens) / f.PointsDone),
+			CostUSD: f.Spent.CostUSD / f.PointsDone,
+		}
+		f.Projected.Tokens += int(float64(f.Spent.Tokens) / f.PointsDone * f.PointsRemaining)
+		f.Projected.CostUSD += f.PerPoint.CostUSD * f.PointsRemaining
+	}
+	if recentPoints > 0 && f.PointsRemaining > 0 {
+		f.DaysRemaining = f.PointsRemaining / (recentPoints / burnDays)
+	}
+
+	if f.Budget.USD > 0 && f.Projected.CostUSD > f.Budget.USD {
+		f.Exceeds = append(f.Exceeds, "usd")
+	}
+	if f.Budget.Tokens > 0 && f.Projected.Tokens > f.Budget.Tokens {
+		f.Exceeds = append(f.Exceeds, "tokens")
+	}
+	sort.Strings(f.Exceeds)
+	f.OverBudget = len(f.Exceeds) > 0
+	return f, nil
+}
+
+// points totals the estimates of the done and open tasks into f, and
+// returns the points done since recent. A task without a positive
+// estimate counts as one point; archived tasks don't count.
+func points(missionDir string, recent time.Time, f *Forecast) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "tasks.jsonl"))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var recentPoints float64
+	lines, _ := bridge.LatestTaskLines(data)
+	for _, line := range lines {
+		var t struct {
+			Status    string  `json:"status"`
+			Estimate  float64 `json:"estimate"`
+			UpdatedAt string  `json:"updated_at"`
+		}
+		if json.Unmarshal(line, &t) != nil {
+			continue
+		}
+		p := t.Estimate
+		if p <= 0 {
+			p = 1
+		}
+		if t.Status == bridge.TaskStatusArchived {
+			continue
+		}
+		if t.Status != "done" && t.Status != "complete" {
+			f.PointsRemaining += p
+			continue
+		}
+		f.PointsDone += p
+		if at, err := time.Parse(time.RFC3339, t.UpdatedAt); err == nil && at.After(recent) {
+			recentPoints += p
+		}
+	}
+	return recentPoints, nil
+}
+
+// Monitor watches one project's forecast against its budget.
+type Monitor struct {
+	projectDir string
+	onExceeded func(Forecast)
+	done       chan struct{}
+	stopOnce   sync.Once
+
+	over bool // the last forecast went over budget
+}
+
+// Start starts a Monitor for projectDir. onExceeded, if set, is called
+// each time the projection goes over budget.
+func Start(projectDir string, onExceeded func(Forecast)) *Monitor {
+	m := newMonitor(projectDir, onExceeded)
+	go m.run(TickInterval)
+	return m
+}
+
+func newMonitor(projectDir string, onExceeded func(Forecast)) *Monitor {
+	return &Monitor{
+		projectDir: projectDir,
+		onExceeded: onExceeded,
+		done:       make(chan struct{}),
+	}
+}
+
+// Stop stops the monitor.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.done) })
+}
+
+func (m *Monitor) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case now := <-ticker.C:
+			m.tick(now)
+		}
+	}
+}
+
+// tick recomputes the forecast and reports it if the projection has just
+// gone over budget.
+func (m *Monitor) tick(now time.Time) {
+	missionDir := filepath.Join(m.projectDir, ".mission")
+	if b := Load(missionDir); b.USD <= 0 && b.Tokens <= 0 {
+		m.over = false
+		return
+	}
+	f, err := Compute(missionDir, now)
+	if err != nil {
+		log.Printf("budget: forecast: %v", err)
+		return
+	}
+	was := m.over
+	m.over = f.OverBudget
+	if !f.OverBudget || was {
+		return
+	}
+
+	log.Printf("budget: projected spend $%.2f, %d tokens is over budget (%v)", f.Projected.CostUSD, f.Projected.Tokens, f.Exceeds)
+	Notify(m.projectDir, webhook.EventBudgetForecast, f)
+	if m.onExceeded != nil {
+		m.onExceeded(f)
+	}
+}
+
+// Notify sends a budget webhook to the hooks in projectDir's config.json.
+// Delivery is best effort.
+func Notify(projectDir, event string, data interface{}) {
+	cfg, err := bridge.LoadProjectConfig(projectDir)
+	if err != nil || len(cfg.Webhooks) == 0 {
+		return
+	}
+	if err := webhook.Send(context.Background(), cfg.Webhooks, event, data); err != nil {
+		log.Printf("budget: %s webhook: %v", event, err)
+	}
+}
+
+func loadLedger(missionDir string) ledger {
+	var l ledger
+	if data, err := os.ReadFile(filepath.Join(missionDir, "orchestrator", LedgerFile)); err == nil {
+		_ = json.Unmarshal(data, &l)
+	}
+	if l.Days == nil {
+		l.Days = map[string]Usage{}
+	}
+	return l
+}
+
+func saveLedger(missionDir string, l ledger) error {
+	dir := filepath.Join(missionDir, "orchestrator")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, LedgerFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,110 @@
+package budget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComputeProjectsFromBacklog(t *testing.T) {
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	writeFile(t, filepath.Join(mission, "config.json"), `{"budget":{"usd":50,"tokens":100000}}`)
+	writeFile(t, filepath.Join(mission, "state", "tasks.jsonl"), `{"id":"t1","status":"done","estimate":3,"updated_at":"2026-03-09T10:00:00Z"}
+{"id":"t2","status":"in_progress","estimate":2}
+{"id":"t2","status":"done","estimate":2,"updated_at":"2026-01-01T00:00:00Z"}
+{"id":"t3","status":"pending","estimate":5}
+{"id":"t4","status":"pending"}
+{"id":"t5","status":"archived","estimate":8}
+`)
+	// Outside the burn window
+	Record(mission, 10000, 10, now.AddDate(0, 0, -20))
+	Record(mission, 40000, 14, now.AddDate(0, 0, -1))
+	Record(mission, 0, 1, now)
+
+	f, err := Compute(mission, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Spent.Tokens != 50000 || f.Spent.CostUSD != 25 {
+		t.Errorf("spent = %+v", f.Spent)
+	}
+	if f.BurnRate.CostUSD != 15.0/7 {
+		t.Errorf("burn rate = %+v, want $15 over 7 days", f.BurnRate)
+	}
+	if f.PointsDone != 5 || f.PointsRemaining != 6 {
+		t.Errorf("points done %v, remaining %v; want 5 and 6", f.PointsDone, f.PointsRemaining)
+	}
+	// $5 and 10000 tokens a point, 6 points open
+	if f.Method != "backlog" || f.Projected.CostUSD != 55 || f.Projected.Tokens != 110000 {
+		t.Errorf("projected = %+v (%s)", f.Projected, f.Method)
+	}
+	if !f.OverBudget || len(f.Exceeds) != 2 {
+		t.Errorf("expected both budgets exceeded, got %v", f.Exceeds)
+	}
+	// 3 points done in the last seven days
+	if f.DaysRemaining != 14 {
+		t.Errorf("days remaining = %v, want 14", f.DaysRemaining)
+	}
+}
+
+func TestComputeWithoutDoneTasks(t *testing.T) {
+	mission := filepath.Join(t.TempDir(), ".mission")
+	writeFile(t, filepath.Join(mission, "state", "tasks.jsonl"), `{"id":"t1","status":"pending"}`+"\n")
+	now := time.Now()
+	Record(mission, 500, 0.5, now)
+
+	f, err := Compute(mission, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Method != "none" || f.Projected != f.Spent || f.OverBudget {
+		t.Errorf("expected the spend so far as the projection, got %+v", f)
+	}
+}
+
+func TestMonitorReportsOnceOverBudget(t *testing.T) {
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	writeFile(t, filepath.Join(mission, "config.json"), `{"budget":{"usd":5}}`)
+	writeFile(t, filepath.Join(mission, "state", "tasks.jsonl"), `{"id":"t1","status":"done"}
+{"id":"t2","status":"pending"}
+`)
+	now := time.Now()
+	Record(mission, 100, 2, now)
+
+	var got []Forecast
+	m := newMonitor(dir, func(f Forecast) { got = append(got, f) })
+	m.tick(now)
+	if len(got) != 0 {
+		t.Fatalf("a $4 projection is within budget, got %+v", got)
+	}
+
+	Record(mission, 100, 1, now)
+	m.tick(now)
+	m.tick(now)
+	if len(got) != 1 || got[0].Exceeds[0] != "usd" {
+		t.Fatalf("expected one report of the $6 projection, got %+v", got)
+	}
+
+	// Back under budget, then over again
+	writeFile(t, filepath.Join(mission, "config.json"), `{"budget":{"usd":8}}`)
+	m.tick(now)
+	Record(mission, 100, 2, now)
+	m.tick(now)
+	if len(got) != 2 {
+		t.Errorf("expected a second report after recovering, got %d", len(got))
+	}
+}
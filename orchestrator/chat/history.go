@@ -0,0 +1,216 @@
+// Package chat persists chat messages exchanged with OpenClaw and the King
+// to .mission/chat/history.jsonl so conversations survive restarts, and
+// the King's side of them to .mission/king/transcript.jsonl, which
+// checkpoint briefings draw on. Each line is one Message; sequence
+// numbers increase across sessions and are used as pagination cursors.
+package chat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+const (
+	// Dir holds chat state inside .mission/.
+	Dir = "chat"
+	// FileName is the history log inside Dir.
+	FileName = "history.jsonl"
+	// DefaultSession is used for messages that name no session.
+	DefaultSession = "webchat"
+	// TranscriptDir holds the King transcript inside .mission/.
+	TranscriptDir = "king"
+	// TranscriptFile is the King transcript inside TranscriptDir.
+	TranscriptFile = "transcript.jsonl"
+)
+
+// Message is one persisted chat message.
+type Message struct {
+	Seq       int64  `json:"seq"`
+	Session   string `json:"session"`
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	Timestamp string `json:"timestamp"`
+	Source    string `json:"source,omitempty"` // openclaw, king
+	RunID     string `json:"run_id,omitempty"`
+}
+
+// Store appends to and queries a history file. It is safe for concurrent
+// use within a process.
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	lastSeq int64
+	loaded  bool
+}
+
+// NewStore returns a store for the history under missionDir (the .mission
+// directory). Nothing is read or created until first use.
+func NewStore(missionDir string) *Store {
+	return &Store{path: filepath.Join(missionDir, Dir, FileName)}
+}
+
+// NewTranscript returns a store for the King transcript under missionDir.
+func NewTranscript(missionDir string) *Store {
+	return &Store{path: filepath.Join(missionDir, TranscriptDir, TranscriptFile)}
+}
+
+// Path returns the history file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append assigns m the next sequence number, defaults its session and
+// timestamp, and writes it to the history.
+func (s *Store) Append(m Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.loaded {
+		last, err := s.scanLastSeq()
+		if err != nil {
+			return m, err
+		}
+		s.lastSeq, s.loaded = last, true
+	}
+
+	if m.Session == "" {
+		m.Session = DefaultSession
+	}
+	if m.Timestamp == "" {
+		m.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	m.Seq = s.lastSeq + 1
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return m, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return m, err
+	}
+	if err := jsonl.AppendLines(s.path, jsonl.Options{}, data); err != nil {
+		return m, err
+	}
+	s.lastSeq = m.Seq
+	return m, nil
+}
+
+// Query selects messages from the history.
+type Query struct {
+	Session string // "" matches every session
+	Before  int64  // only messages with Seq < Before; 0 means no bound
+	Limit   int    // newest Limit matches; 0 means all
+	Search  string // case-insensitive text the content must contain
+}
+
+// History returns the newest messages matching q, oldest first, and
+// whether older matches remain. Pass the first message's Seq as the next
+// query's Before to page backwards.
+func (s *Store) History(q Query) ([]Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search := strings.ToLower(q.Search)
+	messages := []Message{}
+	err := s.each(func(m Message) {
+		if q.Session != "" && m.Session != q.Session {
+			return
+		}
+		if search != "" && !strings.Contains(strings.ToLower(m.Content), search) {
+			return
+		}
+		if q.Before > 0 && m.Seq >= q.Before {
+			return
+		}
+		messages = append(messages, m)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	more := false
+	if q.Limit > 0 && len(messages) > q.Limit {
+		messages = messages[len(messages)-q.Limit:]
+		more = true
+	}
+	return messages, more, nil
+}
+
+// each calls fn for every well-formed line of the history. A missing file
+// is an empty history.
+func (s *Store) each(fn func(Message)) error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var m Message
+		if json.Unmarshal(scanner.Bytes(), &m) != nil {
+			continue
+		}
+		fn(m)
+	}
+	return scanner.Err()
+}
+
+func (s *Store) scanLastSeq() (int64, error) {
+	var last int64
+	err := s.each(func(m Message) {
+		if m.Seq > last {
+			last = m.Seq
+		}
+	})
+	return last, err
+}
+
+// Markdown renders messages as a transcript with a section per session,
+// sessions in order of first appearance.
+func Markdown(messages []Message) string {
+	var order []string
+	bySession := make(map[string][]Message)
+	for _, m := range messages {
+		if _, ok := bySession[m.Session]; !ok {
+			order = append(order, m.Session)
+		}
+		bySession[m.Session] = append(bySession[m.Session], m)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Chat History\n")
+	if len(messages) == 0 {
+		b.WriteString("\n_No messages._\n")
+	}
+	for _, session := range order {
+		fmt.Fprintf(&b, "\n## Session: %s\n", session)
+		for _, m := range bySession[session] {
+			fmt.Fprintf(&b, "\n### %s — %s\n\n%s\n", roleTitle(m.Role), m.Timestamp, strings.TrimSpace(m.Content))
+		}
+	}
+	return b.String()
+}
+
+func roleTitle(role string) string {
+	switch role {
+	case "user", "human":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "":
+		return "Unknown"
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStoreAppendAndHistory(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+	for i, c := range []string{"one", "two", "three", "four"} {
+		session := "webchat"
+		if i == 1 {
+			session = "king"
+		}
+		if _, err := s.Append(Message{Session: session, Role: "user", Content: c}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	// A fresh store continues the sequence from disk.
+	m, err := NewStore(dir).Append(Message{Role: "assistant", Content: "five"})
+	if err != nil || m.Seq != 5 || m.Session != DefaultSession || m.Timestamp == "" {
+		t.Fatalf("reopened append = %+v, %v", m, err)
+	}
+
+	page, more, err := s.History(Query{Session: "webchat", Limit: 2})
+	if err != nil || !more || len(page) != 2 || page[0].Content != "four" || page[1].Content != "five" {
+		t.Fatalf("first page = %+v more=%v err=%v", page, more, err)
+	}
+	page, more, _ = s.History(Query{Session: "webchat", Before: page[0].Seq, Limit: 2})
+	if more || len(page) != 2 || page[0].Content != "one" || page[1].Content != "three" {
+		t.Errorf("second page = %+v more=%v", page, more)
+	}
+}
+
+func TestTranscriptSearch(t *testing.T) {
+	dir := t.TempDir()
+	s := NewTranscript(dir)
+	for _, c := range []string{"Deploy to staging", "Which database?", "Use Postgres for staging too"} {
+		s.Append(Message{Session: "king", Role: "user", Content: c})
+	}
+	if !strings.HasSuffix(s.Path(), "king/transcript.jsonl") {
+		t.Errorf("path = %s", s.Path())
+	}
+	page, more, err := s.History(Query{Search: "STAGING", Limit: 1})
+	if err != nil || !more || len(page) != 1 || page[0].Seq != 3 {
+		t.Fatalf("search page = %+v more=%v err=%v", page, more, err)
+	}
+}
+
+func TestHistoryMissingFile(t *testing.T) {
+	page, more, err := NewStore(t.TempDir()).History(Query{})
+	if err != nil || more || len(page) != 0 {
+		t.Errorf("missing file: %v %v %v", page, more, err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := Markdown([]Message{
+		{Session: "webchat", Role: "user", Content: "hi", Timestamp: "2026-01-02T15:04:05Z"},
+		{Session: "king", Role: "assistant", Content: "on it"},
+		{Session: "webchat", Role: "assistant", Content: "hello\n"},
+	})
+	if strings.Count(md, "## Session: webchat") != 1 || !strings.Contains(md, "### User — 2026-01-02T15:04:05Z\n\nhi\n") {
+		t.Errorf("markdown:\n%s", md)
+	}
+	if strings.Index(md, "hello") > strings.Index(md, "## Session: king") {
+		t.Errorf("webchat messages not grouped:\n%s", md)
+	}
+}
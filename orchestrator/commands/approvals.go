@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+// ApprovalsFile holds the gate approvals the King requested, in
+// .mission/orchestrator/.
+const ApprovalsFile = "approvals.json"
+
+// Approval statuses
+const (
+	ApprovalPending   = "pending"
+	ApprovalConfirmed = "confirmed"
+	ApprovalDismissed = "dismissed"
+)
+
+var (
+	ErrApprovalNotFound = errors.New("approval request not found")
+	ErrApprovalResolved = errors.New("approval request already resolved")
+)
+
+// Approval is a gate approval the King asked for. It changes nothing until
+// a human confirms it, which approves the gate as mc gate approve does,
+// with the King's note, in the confirming human's name.
+type Approval struct {
+	ID          string     `json:"id"`
+	Stage       string     `json:"stage"`
+	Note        string     `json:"note"`
+	Status      string     `json:"status"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ResolvedBy  string     `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Approvals returns missionDir's approval requests, oldest first.
+func Approvals(missionDir string) ([]Approval, error) {
+	data, err := os.ReadFile(approvalsPath(missionDir))
+	if os.IsNotExist(err) {
+		return []Approval{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeApprovals(data)
+}
+
+func approvalsPath(missionDir string) string {
+	return filepath.Join(missionDir, "orchestrator", ApprovalsFile)
+}
+
+// decodeApprovals parses approvals.json. An empty file, as a first locked
+// update leaves behind until it writes, has no requests.
+func decodeApprovals(data []byte) ([]Approval, error) {
+	var f struct {
+		Approvals []Approval `json:"approvals"`
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", ApprovalsFile, err)
+		}
+	}
+	if f.Approvals == nil {
+		f.Approvals = []Approval{}
+	}
+	return f.Approvals, nil
+}
+
+// Pending returns the approval requests still waiting for a human.
+func Pending(missionDir string) ([]Approval, error) {
+	all, err := Approvals(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	pending := []Approval{}
+	for _, a := range all {
+		if a.Status == ApprovalPending {
+			pending = append(pending, a)
+		}
+	}
+	return pending, nil
+}
+
+// RequestApproval records a pending approval of stage's gate with note.
+// A pending request for the same stage is returned instead of a second
+// one.
+func RequestApproval(missionDir, id, stage, note string) (Approval, error) {
+	var a Approval
+	err := updateApprovals(missionDir, func(all []Approval) ([]Approval, error) {
+		for _, p := range all {
+			if p.Stage == stage && p.Status == ApprovalPending {
+				a = p
+				return all, nil
+			}
+		}
+		a = Approval{ID: id, Stage: stage, Note: note, Status: ApprovalPending, RequestedAt: time.Now().UTC()}
+		return append(all, a), nil
+	})
+	return a, err
+}
+
+// FindApproval returns the approval request with id.
+func FindApproval(missionDir, id string) (Approval, error) {
+	all, err := Approvals(missionDir)
+	if err != nil {
+		return Approval{}, err
+	}
+	for _, a := range all {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return Approval{}, ErrApprovalNotFound
+}
+
+// ResolveApproval marks the pending request with id confirmed or
+// dismissed by who. Confirming doesn't approve the gate; callers do that
+// first.
+func ResolveApproval(missionDir, id, status, who string) (Approval, error) {
+	var a Approval
+	err := updateApprovals(missionDir, func(all []Approval) ([]Approval, error) {
+		for i := range all {
+			if all[i].ID != id {
+				continue
+			}
+			if all[i].Status != ApprovalPending {
+				a = all[i]
+				return nil, ErrApprovalResolved
+			}
+			now := time.Now().UTC()
+			all[i].Status, all[i].ResolvedBy, all[i].ResolvedAt = status, who, &now
+			a = all[i]
+			return all, nil
+		}
+		return nil, ErrApprovalNotFound
+	})
+	return a, err
+}
+
+// updateApprovals replaces missionDir's approval requests with what fn
+// makes of them, under the same file lock the JSONL writers take, so a
+// request and a resolution landing together don't drop one another.
+func updateApprovals(missionDir string, fn func([]Approval) ([]Approval, error)) error {
+	path := approvalsPath(missionDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return jsonl.Update(path, jsonl.Options{}, func(lines [][]byte) ([][]byte, error) {
+		all, err := decodeApprovals(bytes.Join(lines, []byte("\n")))
+		if err != nil {
+			return nil, err
+		}
+		if all, err = fn(all); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{"approvals": all}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Split(data, []byte("\n")), nil
+	})
+}
@@ -0,0 +1,424 @@
+// Package commands carries out orchestration commands from the King as
+// structured requests instead of mc invocations in its own shell, so the
+// orchestrator sees, validates and records each one. The King drops a
+// request in .mission/commands/ or posts it to /api/commands:
+//
+//	{"id": "spawn-auth", "command": "spawn", "args": {"persona": "developer", "task": "Build the login form", "task_id": "t-12"}}
+//
+// A request is validated against its command's arguments, run as the
+// matching mc command on the King's behalf and acknowledged in
+// .mission/commands/acks/<id>.json. Each one is recorded in the audit log
+// as king_command, with the request ID mc also tags its own entries with.
+//
+// gate_request is the exception: the King can't approve a gate, only ask.
+// It records a pending Approval that a human confirms from the dashboard
+// (POST /api/approvals/{id}/confirm) or with mc gate confirm.
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+)
+
+const (
+	// Dir is the request directory inside .mission/.
+	Dir = "commands"
+	// AckDir holds the acknowledgements inside Dir.
+	AckDir = "acks"
+	// Actor is who commands run as, on audit entries.
+	Actor = "king"
+	// PollInterval is how often a Processor looks for new requests.
+	PollInterval = time.Second
+)
+
+// Ack statuses
+const (
+	StatusDone     = "done"     // mc succeeded
+	StatusFailed   = "failed"   // mc exited with an error
+	StatusRejected = "rejected" // the request was invalid; nothing ran
+	StatusPending  = "pending"  // waiting for a human to confirm
+)
+
+// Request is one command from the King. ID defaults to the request file's
+// name.
+type Request struct {
+	ID      string          `json:"id,omitempty"`
+	Command string          `json:"command"`
+	Args    json.RawMessage `json:"args,omitempty"`
+}
+
+// Ack is the outcome of a request.
+type Ack struct {
+	ID          string          `json:"id"`
+	Command     string          `json:"command"`
+	Args        json.RawMessage `json:"args,omitempty"`
+	Status      string          `json:"status"`
+	Output      string          `json:"output,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Approval    *Approval       `json:"approval,omitempty"` // gate_request
+	CompletedAt time.Time       `json:"completed_at"`
+}
+
+// Each command's arguments; required ones are checked in argv.
+type (
+	spawnArgs struct {
+		Persona string `json:"persona"`
+		Task    string `json:"task"`
+		Zone    string `json:"zone,omitempty"`
+		TaskID  string `json:"task_id,omitempty"`
+		Runtime string `json:"runtime,omitempty"`
+	}
+	killArgs struct {
+		WorkerID string `json:"worker_id"`
+	}
+	taskCreateArgs struct {
+		Title     string   `json:"title"`
+		Stage     string   `json:"stage,omitempty"`
+		Zone      string   `json:"zone,omitempty"`
+		Persona   string   `json:"persona,omitempty"`
+		DependsOn []string `json:"depends_on,omitempty"`
+		Labels    []string `json:"labels,omitempty"`
+	}
+	taskUpdateArgs struct {
+		TaskID string `json:"task_id"`
+		Status string `json:"status"`
+	}
+	gateRequestArgs struct {
+		Stage string `json:"stage"`
+		Note  string `json:"note"`
+	}
+	handoffArgs struct {
+		File string `json:"file"` // relative to the project
+	}
+)
+
+// Commands lists the commands the King may send.
+var Commands = []string{"spawn", "kill", "task_create", "task_update", "gate_request", "stage_advance", "handoff"}
+
+// idPattern keeps IDs usable as file names.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// runMC runs mc in dir with env added; a variable so tests can stub it.
+var runMC = func(dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("mc", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// argv validates req and returns the mc arguments that carry it out; for
+// gate_request, those a confirmation runs.
+func argv(req Request) ([]string, error) {
+	if req.Command == "" {
+		return nil, errors.New("command is required")
+	}
+	decode := func(v interface{}) error {
+		if len(req.Args) == 0 {
+			return nil
+		}
+		dec := json.NewDecoder(bytes.NewReader(req.Args))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(v); err != nil {
+			return fmt.Errorf("args: %w", err)
+		}
+		return nil
+	}
+	// required checks positional arguments, which mustn't read as flags
+	required := func(pairs ...string) error {
+		for i := 0; i < len(pairs); i += 2 {
+			if strings.TrimSpace(pairs[i+1]) == "" {
+				return fmt.Errorf("args.%s is required", pairs[i])
+			}
+			if strings.HasPrefix(pairs[i+1], "-") {
+				return fmt.Errorf("args.%s may not start with -", pairs[i])
+			}
+		}
+		return nil
+	}
+	flag := func(args []string, name, value string) []string {
+		if value != "" {
+			args = append(args, "--"+name, value)
+		}
+		return args
+	}
+
+	switch req.Command {
+	case "spawn":
+		var a spawnArgs
+		if err := decode(&a); err != nil {
+			return nil, err
+		}
+		if err := required("persona", a.Persona, "task", a.Task); err != nil {
+			return nil, err
+		}
+		args := []string{"spawn", a.Persona, a.Task}
+		args = flag(args, "zone", a.Zone)
+		args = flag(args, "task-id", a.TaskID)
+		return flag(args, "runtime", a.Runtime), nil
+	case "kill":
+		var a killArgs
+		if err := decode(&a); err != nil {
+			return nil, err
+		}
+		if err := required("worker_id", a.WorkerID); err != nil {
+			return nil, err
+		}
+		return []string{"kill", a.WorkerID}, nil
+	case "task_create":
+		var a taskCreateArgs
+		if err := decode(&a); err != nil {
+			return nil, err
+		}
+		if err := required("title", a.Title); err != nil {
+			return nil, err
+		}
+		args := []string{"task", "create", a.Title}
+		args = flag(args, "stage", a.Stage)
+		args = flag(args, "zone", a.Zone)
+		args = flag(args, "persona", a.Persona)
+		args = flag(args, "depends-on", strings.Join(a.DependsOn, ","))
+		return flag(args, "label", strings.Join(a.Labels, ",")), nil
+	case "task_update":
+		var a taskUpdateArgs
+		if err := decode(&a); err != nil {
+			return nil, err
+		}
+		if err := required("task_id", a.TaskID, "status", a.Status); err != nil {
+			return nil, err
+		}
+		return []string{"task", "update", a.TaskID, "--status", a.Status}, nil
+	case "gate_request":
+		var a gateRequestArgs
+		if err := decode(&a); err != nil {
+			return nil, err
+		}
+		if err := required("stage", a.Stage, "note", a.Note); err != nil {
+			return nil, err
+		}
+		return []string{"gate", "approve", a.Stage, "--note", a.Note}, nil
+	case "stage_advance":
+		if err := decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return []string{"stage", "next"}, nil
+	case "handoff":
+		var a handoffArgs
+		if err := decode(&a); err != nil {
+			return nil, err
+		}
+		if err := required("file", a.File); err != nil {
+			return nil, err
+		}
+		if clean := filepath.Clean(a.File); filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, errors.New("args.file must be inside the project")
+		}
+		return []string{"handoff", filepath.Clean(a.File)}, nil
+	}
+	return nil, fmt.Errorf("unknown command %q (one of %s)", req.Command, strings.Join(Commands, ", "))
+}
+
+// Execute validates req, runs it in projectDir on the King's behalf,
+// records it in the audit log and writes its ack. A request with no ID
+// gets one from its time.
+func Execute(projectDir string, req Request) Ack {
+	return execute(projectDir, req, nil, "")
+}
+
+// ExecuteAs is Execute for a request made through the API by user, whom
+// mc acts for and the audit entry names instead of the King.
+func ExecuteAs(projectDir string, req Request, user string) Ack {
+	if user == "" {
+		user = "api"
+	}
+	return execute(projectDir, req, nil, user)
+}
+
+// execute is Execute for a request that is rejected with invalid if it
+// is set, run as user, or as the King when user is "".
+func execute(projectDir string, req Request, invalid error, user string) Ack {
+	actor := Actor
+	if user != "" {
+		actor = "api"
+	} else {
+		user = Actor
+	}
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("cmd-%d", time.Now().UnixNano())
+	}
+	ack := Ack{ID: req.ID, Command: req.Command, Args: req.Args}
+	args, err := argv(req)
+	if invalid != nil {
+		err = invalid
+	} else if err == nil && !idPattern.MatchString(req.ID) {
+		err = fmt.Errorf("invalid id %q", req.ID)
+	}
+	switch {
+	case err != nil:
+		ack.Status, ack.Error = StatusRejected, err.Error()
+	case req.Command == "gate_request":
+		// The King may only ask; a human approves
+		approval, err := RequestApproval(filepath.Join(projectDir, ".mission"), req.ID, args[2], args[4])
+		if err != nil {
+			ack.Status, ack.Error = StatusFailed, err.Error()
+		} else {
+			ack.Status, ack.Approval = StatusPending, &approval
+			ack.Output = fmt.Sprintf("Approval of the %s gate requested as %s; waiting for a human to confirm", approval.Stage, approval.ID)
+		}
+	default:
+		out, err := runMC(projectDir, []string{identity.EnvUser + "=" + user, "MC_REQUEST_ID=" + req.ID}, args...)
+		ack.Output = strings.TrimSpace(string(out))
+		if err != nil {
+			ack.Status, ack.Error = StatusFailed, err.Error()
+		} else {
+			ack.Status = StatusDone
+		}
+	}
+	ack.CompletedAt = time.Now().UTC()
+
+	missionDir := filepath.Join(projectDir, ".mission")
+	details := map[string]interface{}{
+		"command": ack.Command,
+		"status":  ack.Status,
+	}
+	if len(req.Args) > 0 {
+		details["args"] = json.RawMessage(req.Args)
+	}
+	if ack.Error != "" {
+		details["error"] = ack.Error
+	}
+	if err := audit.Append(missionDir, audit.Entry{
+		Action:    "king_command",
+		Actor:     actor,
+		User:      user,
+		Category:  "king",
+		RequestID: ack.ID,
+		Details:   details,
+	}); err != nil {
+		log.Printf("commands: auditing %s: %v", ack.ID, err)
+	}
+	if idPattern.MatchString(ack.ID) {
+		if err := writeAck(missionDir, ack); err != nil {
+			log.Printf("commands: acknowledging %s: %v", ack.ID, err)
+		}
+	}
+	return ack
+}
+
+func writeAck(missionDir string, ack Ack) error {
+	dir := filepath.Join(missionDir, Dir, AckDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ack, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, ack.ID+".json.tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, ack.ID+".json"))
+}
+
+// Acks returns the acknowledged commands in missionDir, newest first.
+func Acks(missionDir string) ([]Ack, error) {
+	paths, err := filepath.Glob(filepath.Join(missionDir, Dir, AckDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	acks := []Ack{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		var a Ack
+		if json.Unmarshal(data, &a) == nil {
+			acks = append(acks, a)
+		}
+	}
+	sort.SliceStable(acks, func(i, j int) bool { return acks[i].CompletedAt.After(acks[j].CompletedAt) })
+	return acks, nil
+}
+
+// Processor carries out the requests dropped in a project's
+// .mission/commands/, oldest file name first, removing each once it is
+// acknowledged.
+type Processor struct {
+	projectDir string
+	onAck      func(Ack)
+	done       chan struct{}
+	stopOnce   sync.Once
+}
+
+// Start starts a Processor for projectDir. onAck, if set, is called after
+// each request.
+func Start(projectDir string, onAck func(Ack)) *Processor {
+	p := &Processor{projectDir: projectDir, onAck: onAck, done: make(chan struct{})}
+	go p.run(PollInterval)
+	return p
+}
+
+// Stop stops the processor.
+func (p *Processor) Stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+}
+
+func (p *Processor) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.poll()
+		}
+	}
+}
+
+// poll carries out the pending requests.
+func (p *Processor) poll() {
+	paths, _ := filepath.Glob(filepath.Join(p.projectDir, ".mission", Dir, "*.json"))
+	sort.Strings(paths)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var req Request
+		var invalid error
+		if err := json.Unmarshal(data, &req); err != nil {
+			// Possibly still being written; a request that stays
+			// unparsable is rejected once it is two polls old
+			if info, serr := os.Stat(path); serr == nil && time.Since(info.ModTime()) < 2*PollInterval {
+				continue
+			}
+			req, invalid = Request{}, fmt.Errorf("invalid request: %w", err)
+		}
+		if req.ID == "" {
+			req.ID = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		ack := execute(p.projectDir, req, invalid, "")
+		if rerr := os.Remove(path); rerr != nil {
+			log.Printf("commands: removing %s: %v", path, rerr)
+		}
+		if p.onAck != nil {
+			p.onAck(ack)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+)
+
+// stubMC records mc invocations instead of running mc, failing those
+// whose first argument is fail.
+func stubMC(t *testing.T) *[][]string {
+	t.Helper()
+	var calls [][]string
+	orig := runMC
+	runMC = func(dir string, env []string, args ...string) ([]byte, error) {
+		calls = append(calls, append(append([]string{}, env...), args...))
+		if args[0] == "fail" {
+			return []byte("boom"), errors.New("exit status 1")
+		}
+		return []byte("ok\n"), nil
+	}
+	t.Cleanup(func() { runMC = orig })
+	return &calls
+}
+
+func TestArgv(t *testing.T) {
+	tests := []struct {
+		req     string
+		want    []string
+		wantErr string
+	}{
+		{`{"command": "spawn", "args": {"persona": "developer", "task": "Build login", "zone": "frontend"}}`,
+			[]string{"spawn", "developer", "Build login", "--zone", "frontend"}, ""},
+		{`{"command": "task_create", "args": {"title": "Add login", "stage": "implement", "depends_on": ["a", "b"]}}`,
+			[]string{"task", "create", "Add login", "--stage", "implement", "--depends-on", "a,b"}, ""},
+		{`{"command": "gate_request", "args": {"stage": "design", "note": "Specs reviewed"}}`,
+			[]string{"gate", "approve", "design", "--note", "Specs reviewed"}, ""},
+		{`{"command": "stage_advance"}`, []string{"stage", "next"}, ""},
+		{`{"command": "spawn", "args": {"persona": "developer"}}`, nil, "args.task is required"},
+		{`{"command": "task_create", "args": {"title": "--force"}}`, nil, "may not start with -"},
+		{`{"command": "kill", "args": {"worker_id": "w1", "signal": 9}}`, nil, "unknown field"},
+		{`{"command": "handoff", "args": {"file": "../secrets.json"}}`, nil, "inside the project"},
+		{`{"command": "rm"}`, nil, "unknown command"},
+	}
+	for _, tt := range tests {
+		var req Request
+		json.Unmarshal([]byte(tt.req), &req)
+		got, err := argv(req)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.req, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %q, %v; want %q", tt.req, got, err, tt.want)
+		}
+	}
+}
+
+func TestExecuteAcksAndAudits(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	calls := stubMC(t)
+
+	ack := Execute(dir, Request{ID: "kill-w1", Command: "kill", Args: json.RawMessage(`{"worker_id": "w1"}`)})
+	if ack.Status != StatusDone || ack.Output != "ok" {
+		t.Fatalf("ack = %+v", ack)
+	}
+	want := []string{"MC_USER=king", "MC_REQUEST_ID=kill-w1", "kill", "w1"}
+	if len(*calls) != 1 || !reflect.DeepEqual((*calls)[0], want) {
+		t.Errorf("mc calls = %q, want %q", *calls, want)
+	}
+	rejected := Execute(dir, Request{ID: "bad", Command: "rm"})
+	if rejected.Status != StatusRejected || len(*calls) != 1 {
+		t.Errorf("rejected = %+v, mc calls = %d", rejected, len(*calls))
+	}
+
+	acks, _ := Acks(filepath.Join(dir, ".mission"))
+	if len(acks) != 2 || acks[0].ID != "bad" {
+		t.Errorf("acks = %+v", acks)
+	}
+	res, _ := audit.Query(filepath.Join(dir, ".mission"), audit.Filter{Action: "king_command", RequestID: "kill-w1"})
+	if res.Total != 1 {
+		t.Errorf("audit entries for kill-w1 = %d, want 1", res.Total)
+	}
+}
+
+func TestExecuteAsCaller(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	calls := stubMC(t)
+
+	ExecuteAs(dir, Request{ID: "kill-w2", Command: "kill", Args: json.RawMessage(`{"worker_id": "w2"}`)}, "Alice <alice@example.com>")
+	if len(*calls) != 1 || (*calls)[0][0] != "MC_USER=Alice <alice@example.com>" {
+		t.Errorf("mc calls = %q, want run as Alice", *calls)
+	}
+	res, _ := audit.Query(filepath.Join(dir, ".mission"), audit.Filter{Action: "king_command", Actor: "api", User: "Alice <alice@example.com>"})
+	if res.Total != 1 {
+		t.Errorf("expected the command audited as Alice's, got %d entries", res.Total)
+	}
+}
+
+func TestProcessorPoll(t *testing.T) {
+	dir := t.TempDir()
+	stubMC(t)
+	reqDir := filepath.Join(dir, ".mission", Dir)
+	os.MkdirAll(reqDir, 0755)
+	os.WriteFile(filepath.Join(reqDir, "01-advance.json"), []byte(`{"command": "stage_advance"}`), 0644)
+	os.WriteFile(filepath.Join(reqDir, "02-kill.json"), []byte(`{"id": "kill-w2", "command": "kill", "args": {"worker_id": "w2"}}`), 0644)
+
+	var got []Ack
+	p := &Processor{projectDir: dir, onAck: func(a Ack) { got = append(got, a) }}
+	p.poll()
+	if len(got) != 2 || got[0].ID != "01-advance" || got[1].ID != "kill-w2" || got[1].Status != StatusDone {
+		t.Fatalf("acks = %+v", got)
+	}
+	if left, _ := filepath.Glob(filepath.Join(reqDir, "*.json")); len(left) != 0 {
+		t.Errorf("requests left: %v", left)
+	}
+	if _, err := os.Stat(filepath.Join(reqDir, AckDir, "kill-w2.json")); err != nil {
+		t.Errorf("ack file: %v", err)
+	}
+}
+
+func TestGateRequestWaitsForConfirmation(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	calls := stubMC(t)
+	missionDir := filepath.Join(dir, ".mission")
+
+	req := Request{ID: "approve-design", Command: "gate_request", Args: json.RawMessage(`{"stage": "design", "note": "Specs reviewed"}`)}
+	ack := Execute(dir, req)
+	if ack.Status != StatusPending || ack.Approval == nil || ack.Approval.Stage != "design" || len(*calls) != 0 {
+		t.Fatalf("ack = %+v, mc calls = %q", ack, *calls)
+	}
+	// Asking again for the same gate doesn't pile up requests
+	Execute(dir, Request{ID: "approve-design-2", Command: "gate_request", Args: req.Args})
+	if pending, _ := Pending(missionDir); len(pending) != 1 || pending[0].ID != "approve-design" {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	a, err := ResolveApproval(missionDir, "approve-design", ApprovalConfirmed, "Alice")
+	if err != nil || a.Status != ApprovalConfirmed || a.ResolvedBy != "Alice" {
+		t.Fatalf("resolve = %+v, %v", a, err)
+	}
+	if _, err := ResolveApproval(missionDir, "approve-design", ApprovalDismissed, "Bob"); !errors.Is(err, ErrApprovalResolved) {
+		t.Errorf("resolving twice: err = %v", err)
+	}
+	if pending, _ := Pending(missionDir); len(pending) != 0 {
+		t.Errorf("pending after confirming = %+v", pending)
+	}
+}
+
+// Requests landing at once each keep their entry in approvals.json.
+func TestRequestApprovalConcurrent(t *testing.T) {
+	missionDir := filepath.Join(t.TempDir(), ".mission")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stage := fmt.Sprintf("stage-%d", i)
+			if _, err := RequestApproval(missionDir, "approve-"+stage, stage, ""); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if all, err := Approvals(missionDir); err != nil || len(all) != 20 {
+		t.Errorf("got %d approvals, %v; want 20", len(all), err)
+	}
+}
@@ -0,0 +1,270 @@
+// Package conversation parses .mission/conversation.md, the transcript the
+// OpenClaw prompt asks the assistant to append to. Entries look like:
+//
+//	## Human [2026-01-02T15:04:05Z]
+//
+//	message text
+//
+//	---
+//
+//	## Assistant [2026-01-02T15:04:09Z]
+//
+//	response text
+//
+//	---END---
+//
+// A human entry ends at a "---" line; an assistant entry is complete only
+// once its "---END---" line has been written.
+package conversation
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Roles used in entry headers.
+const (
+	RoleHuman     = "human"
+	RoleAssistant = "assistant"
+)
+
+// Markers that close an entry.
+const (
+	EndMarker       = "---END---"
+	separatorMarker = "---"
+)
+
+// FileName is the transcript's name inside .mission/.
+const FileName = "conversation.md"
+
+var headerRe = regexp.MustCompile(`^##\s+(\w+)\s*(?:\[([^\]]*)\])?\s*$`)
+
+// Message is one entry of the transcript.
+type Message struct {
+	ID        int    `json:"id"` // 1-based position in the file
+	Role      string `json:"role"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Content   string `json:"content"`
+	Complete  bool   `json:"complete"`
+
+	end int64 // byte offset just past the closing marker
+}
+
+// Exchange pairs the human messages since the previous exchange with the
+// assistant response that answered them.
+type Exchange struct {
+	ID        int       `json:"id"`
+	Human     []Message `json:"human"`
+	Assistant *Message  `json:"assistant,omitempty"`
+	Complete  bool      `json:"complete"`
+}
+
+// Parse reads a transcript. Text outside entries is ignored; a final
+// assistant entry without its end marker is returned with Complete false.
+func Parse(r io.Reader) ([]Message, error) {
+	return parseFrom(r, 0, 0)
+}
+
+// ParseFile parses the transcript at path. A missing file is an empty
+// transcript.
+func ParseFile(path string) ([]Message, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []Message{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// parseFrom parses r, which starts at byte offset base of the file, and
+// numbers messages after firstID.
+func parseFrom(r io.Reader, base int64, firstID int) ([]Message, error) {
+	messages := []Message{}
+	var cur *Message
+	var body []string
+	offset := base
+
+	flush := func(complete bool, end int64) {
+		if cur == nil {
+			return
+		}
+		cur.Content = strings.TrimSpace(strings.Join(body, "\n"))
+		cur.Complete = complete
+		cur.end = end
+		messages = append(messages, *cur)
+		cur, body = nil, nil
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		raw, err := reader.ReadBytes('\n')
+		if len(raw) == 0 && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		// A trailing line without a newline may still be being written.
+		if err == io.EOF && !bytes.HasSuffix(raw, []byte("\n")) {
+			if cur != nil {
+				body = append(body, strings.TrimRight(string(raw), "\r"))
+			}
+			offset += int64(len(raw))
+			break
+		}
+		offset += int64(len(raw))
+		line := strings.TrimRight(string(raw), "\r\n")
+		trimmed := strings.TrimSpace(line)
+
+		if m := headerRe.FindStringSubmatch(trimmed); m != nil && role(m[1]) != "" {
+			// A new header ends an unterminated entry. Human entries are
+			// complete once written; assistant ones need the end marker.
+			if cur != nil {
+				flush(cur.Role != RoleAssistant, offset-int64(len(raw)))
+			}
+			cur = &Message{
+				ID:        firstID + len(messages) + 1,
+				Role:      role(m[1]),
+				Timestamp: strings.TrimSpace(m[2]),
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case trimmed == EndMarker:
+			flush(true, offset)
+		case trimmed == separatorMarker && cur.Role != RoleAssistant:
+			flush(true, offset)
+		default:
+			body = append(body, line)
+		}
+	}
+	// An entry still open at EOF is being written.
+	flush(false, offset)
+	return messages, nil
+}
+
+// role normalises a header's role name, or returns "" for headings that
+// are not entries.
+func role(s string) string {
+	switch s = strings.ToLower(s); s {
+	case RoleHuman, "user":
+		return RoleHuman
+	case RoleAssistant, "system":
+		return s
+	}
+	return ""
+}
+
+// Exchanges groups messages into exchanges. Messages in other roles are
+// attached to the exchange they fall in, alongside the human ones.
+func Exchanges(messages []Message) []Exchange {
+	exchanges := []Exchange{}
+	var cur *Exchange
+	for i := range messages {
+		m := messages[i]
+		if cur == nil {
+			cur = &Exchange{ID: len(exchanges) + 1, Human: []Message{}}
+		}
+		if m.Role != RoleAssistant {
+			cur.Human = append(cur.Human, m)
+			continue
+		}
+		cur.Assistant = &m
+		cur.Complete = m.Complete
+		exchanges = append(exchanges, *cur)
+		cur = nil
+	}
+	if cur != nil {
+		exchanges = append(exchanges, *cur)
+	}
+	return exchanges
+}
+
+// Tailer follows a transcript as it grows, returning each message once it
+// is complete.
+type Tailer struct {
+	path    string
+	offset  int64 // just past the last complete message
+	lastID  int
+	lastEx  int       // exchanges completed so far
+	pending []Message // human messages awaiting an assistant response
+}
+
+// NewTailer returns a tailer for path, starting at the beginning.
+func NewTailer(path string) *Tailer {
+	return &Tailer{path: path}
+}
+
+// Skip marks everything already complete in the file as seen.
+func (t *Tailer) Skip() error {
+	_, _, _, err := t.Poll()
+	return err
+}
+
+// Poll returns the messages completed since the last call and the
+// exchanges they completed. If the file has shrunk (rewritten or
+// truncated), the tailer starts over from the beginning and reset is true.
+func (t *Tailer) Poll() (messages []Message, exchanges []Exchange, reset bool, err error) {
+	info, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		if t.offset > 0 {
+			t.offset, t.lastID, t.lastEx, t.pending = 0, 0, 0, nil
+			return nil, nil, true, nil
+		}
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if info.Size() < t.offset {
+		t.offset, t.lastID, t.lastEx, t.pending = 0, 0, 0, nil
+		reset = true
+	}
+	if info.Size() == t.offset {
+		return nil, nil, reset, nil
+	}
+
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, nil, reset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, nil, reset, err
+	}
+	parsed, err := parseFrom(f, t.offset, t.lastID)
+	if err != nil {
+		return nil, nil, reset, err
+	}
+
+	for _, m := range parsed {
+		if !m.Complete {
+			break
+		}
+		messages = append(messages, m)
+		t.offset, t.lastID = m.end, m.ID
+		if m.Role != RoleAssistant {
+			t.pending = append(t.pending, m)
+			continue
+		}
+		assistant := m
+		t.lastEx++
+		human := t.pending
+		if human == nil {
+			human = []Message{}
+		}
+		exchanges = append(exchanges, Exchange{ID: t.lastEx, Human: human, Assistant: &assistant, Complete: true})
+		t.pending = nil
+	}
+	return messages, exchanges, reset, nil
+}
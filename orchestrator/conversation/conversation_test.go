@@ -0,0 +1,124 @@
+package conversation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const transcript = `# Conversation
+
+## Human [2026-01-02T15:04:05Z]
+
+Add a login page.
+
+---
+
+## Assistant [2026-01-02T15:04:09Z]
+
+Created task t1.
+
+---
+Spec in specs/login.md.
+
+---END---
+
+## Human
+
+And a logout button.
+
+---
+
+## Assistant
+
+Working on it
+`
+
+func TestParse(t *testing.T) {
+	messages, err := Parse(strings.NewReader(transcript))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d: %+v", len(messages), messages)
+	}
+	first := messages[0]
+	if first.ID != 1 || first.Role != RoleHuman || first.Timestamp != "2026-01-02T15:04:05Z" || first.Content != "Add a login page." || !first.Complete {
+		t.Errorf("first message = %+v", first)
+	}
+	// A "---" inside an assistant response does not end it.
+	if got := messages[1].Content; got != "Created task t1.\n\n---\nSpec in specs/login.md." || !messages[1].Complete {
+		t.Errorf("assistant message = %+v", messages[1])
+	}
+	if last := messages[3]; last.Role != RoleAssistant || last.Complete || last.Content != "Working on it" {
+		t.Errorf("unfinished message = %+v", last)
+	}
+}
+
+func TestExchanges(t *testing.T) {
+	messages, _ := Parse(strings.NewReader(transcript))
+	exchanges := Exchanges(messages)
+	if len(exchanges) != 2 {
+		t.Fatalf("expected 2 exchanges, got %d", len(exchanges))
+	}
+	if !exchanges[0].Complete || len(exchanges[0].Human) != 1 || exchanges[0].Assistant.ID != 2 {
+		t.Errorf("first exchange = %+v", exchanges[0])
+	}
+	if exchanges[1].Complete || exchanges[1].Human[0].Content != "And a logout button." {
+		t.Errorf("second exchange = %+v", exchanges[1])
+	}
+}
+
+func TestParseFileMissing(t *testing.T) {
+	messages, err := ParseFile(filepath.Join(t.TempDir(), FileName))
+	if err != nil || len(messages) != 0 {
+		t.Errorf("missing file: %v, %v", messages, err)
+	}
+}
+
+func TestTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	os.WriteFile(path, []byte("## Human\n\nhello\n\n---\n\n"), 0644)
+
+	tl := NewTailer(path)
+	if err := tl.Skip(); err != nil {
+		t.Fatalf("Skip: %v", err)
+	}
+
+	appendFile := func(s string) {
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(s)
+		f.Close()
+	}
+
+	// A response still being written is not reported.
+	appendFile("## Assistant\n\nhi the")
+	if msgs, exs, _, _ := tl.Poll(); len(msgs) != 0 || len(exs) != 0 {
+		t.Fatalf("partial response reported: %v %v", msgs, exs)
+	}
+
+	appendFile("re\n\n---END---\n")
+	msgs, exs, reset, err := tl.Poll()
+	if err != nil || reset {
+		t.Fatalf("Poll: reset=%v err=%v", reset, err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != 2 || msgs[0].Content != "hi there" {
+		t.Fatalf("messages = %+v", msgs)
+	}
+	// The human message seen before Skip still belongs to the exchange.
+	if len(exs) != 1 || exs[0].ID != 1 || len(exs[0].Human) != 1 || exs[0].Human[0].Content != "hello" {
+		t.Fatalf("exchanges = %+v", exs)
+	}
+
+	if msgs, _, _, _ := tl.Poll(); len(msgs) != 0 {
+		t.Errorf("messages reported twice: %v", msgs)
+	}
+
+	// Truncating the file starts over.
+	os.WriteFile(path, []byte("## Human\n\nnew\n\n---\n"), 0644)
+	msgs, _, reset, _ = tl.Poll()
+	if !reset || len(msgs) != 1 || msgs[0].ID != 1 {
+		t.Errorf("after truncate: reset=%v messages=%+v", reset, msgs)
+	}
+}
@@ -70,12 +70,25 @@ type TokenResult struct {
 // CountTokens counts the tokens in the given text using tiktoken (cl100k_base encoding).
 // It calls mc-core count-tokens - with the text piped to stdin.
 func CountTokens(text string) (int, error) {
+	return countTokens(text)
+}
+
+// CountTokensWithEncoding counts the tokens in text with one of mc-core's
+// tiktoken encodings (EncodingCl100k or EncodingO200k).
+func CountTokensWithEncoding(text, encoding string) (int, error) {
+	if encoding == EncodingLlama {
+		return 0, fmt.Errorf("mc-core has no %s tokenizer", encoding)
+	}
+	return countTokens(text, "--encoding", encoding)
+}
+
+func countTokens(text string, flags ...string) (int, error) {
 	mcCore, err := findMcCore()
 	if err != nil {
 		return 0, err
 	}
 
-	cmd := exec.Command(mcCore, "count-tokens", "-")
+	cmd := exec.Command(mcCore, append([]string{"count-tokens", "-"}, flags...)...)
 	cmd.Stdin = strings.NewReader(text)
 
 	output, err := cmd.Output()
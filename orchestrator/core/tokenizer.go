@@ -0,0 +1,79 @@
+package core
+
+import (
+	"math"
+	"strings"
+)
+
+// Tokenizer encodings. mc-core counts cl100k_base and o200k_base exactly;
+// Llama-family models (Llama, Mistral, Qwen and the other offline models
+// served by Ollama) are estimated.
+const (
+	EncodingCl100k = "cl100k_base"
+	EncodingO200k  = "o200k_base"
+	EncodingLlama  = "llama"
+)
+
+// charsPerToken is the average characters per token of each encoding on
+// English prose and code, used when a count can't be exact.
+var charsPerToken = map[string]float64{
+	EncodingCl100k: 4.0,
+	EncodingO200k:  4.4,
+	EncodingLlama:  3.6,
+}
+
+// o200kPrefixes name the OpenAI models on o200k_base.
+var o200kPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "chatgpt-4o", "o1", "o3", "o4"}
+
+// llamaFamily names model families with SentencePiece or Llama-style
+// tokenizers.
+var llamaFamily = []string{"llama", "mistral", "mixtral", "codestral", "qwen", "deepseek", "gemma", "phi", "yi", "starcoder", "granite"}
+
+// EncodingForModel picks the tokenizer for a worker's model: a Claude
+// tier ("opus"), a model ID ("claude-sonnet-4", "gpt-4o-mini") or an
+// offline model ("ollama:qwen2.5-coder:32b", "llama3.1:8b"). Claude,
+// older GPT and unknown models use cl100k_base.
+func EncodingForModel(model string) string {
+	m := strings.ToLower(strings.TrimSpace(model))
+	// Drop a "<provider>:" prefix as recorded for offline usage
+	for _, provider := range []string{"ollama:", "openai:"} {
+		m = strings.TrimPrefix(m, provider)
+	}
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:] // "meta-llama/Llama-3-8B", "openai/gpt-4o"
+	}
+	for _, p := range o200kPrefixes {
+		if strings.HasPrefix(m, p) {
+			return EncodingO200k
+		}
+	}
+	for _, f := range llamaFamily {
+		if strings.HasPrefix(m, f) || strings.Contains(m, "-"+f) || strings.Contains(m, f+"-") {
+			return EncodingLlama
+		}
+	}
+	return EncodingCl100k
+}
+
+// EstimateTokens approximates the token count of text in encoding from
+// its average characters per token. Unknown encodings count as cl100k_base.
+func EstimateTokens(text, encoding string) int {
+	ratio, ok := charsPerToken[encoding]
+	if !ok {
+		ratio = charsPerToken[EncodingCl100k]
+	}
+	return int(math.Ceil(float64(len(text)) / ratio))
+}
+
+// CountTokensFor counts the tokens of text for model, exactly with
+// mc-core where it has the model's tokenizer and by EstimateTokens
+// otherwise, so it never fails.
+func CountTokensFor(text, model string) int {
+	encoding := EncodingForModel(model)
+	if encoding != EncodingLlama {
+		if n, err := CountTokensWithEncoding(text, encoding); err == nil {
+			return n
+		}
+	}
+	return EstimateTokens(text, encoding)
+}
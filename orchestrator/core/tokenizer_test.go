@@ -0,0 +1,93 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodingForModel(t *testing.T) {
+	tests := map[string]string{
+		"opus":                        EncodingCl100k,
+		"claude-sonnet-4-20250514":    EncodingCl100k,
+		"gpt-4-turbo":                 EncodingCl100k,
+		"gpt-4o-mini":                 EncodingO200k,
+		"openai:gpt-4.1":              EncodingO200k,
+		"o3-mini":                     EncodingO200k,
+		"ollama:qwen2.5-coder:32b":    EncodingLlama,
+		"llama3.1:8b":                 EncodingLlama,
+		"meta-llama/Llama-3.1-70B":    EncodingLlama,
+		"codellama:13b":               EncodingCl100k, // not a known family prefix
+		"mistral-small":               EncodingLlama,
+		"deepseek-coder-v2":           EncodingLlama,
+		"":                            EncodingCl100k,
+		"some-unknown-hosted-model-x": EncodingCl100k,
+	}
+	for model, want := range tests {
+		if got := EncodingForModel(model); got != want {
+			t.Errorf("EncodingForModel(%q) = %s, want %s", model, got, want)
+		}
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	text := strings.Repeat("a", 36)
+	if got := EstimateTokens(text, EncodingCl100k); got != 9 {
+		t.Errorf("cl100k estimate = %d, want 9", got)
+	}
+	if got := EstimateTokens(text, EncodingLlama); got != 10 {
+		t.Errorf("llama estimate = %d, want 10", got)
+	}
+	if got := EstimateTokens(text, EncodingO200k); got != 9 {
+		t.Errorf("o200k estimate = %d, want 9", got)
+	}
+	if got := EstimateTokens(text, "unknown"); got != 9 {
+		t.Errorf("unknown encoding estimate = %d, want the cl100k 9", got)
+	}
+	if got := EstimateTokens("", EncodingLlama); got != 0 {
+		t.Errorf("empty estimate = %d, want 0", got)
+	}
+}
+
+func TestCountTokensFor(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog."
+
+	// Llama-family models are always estimated
+	if got, want := CountTokensFor(text, "llama3.1:8b"), EstimateTokens(text, EncodingLlama); got != want {
+		t.Errorf("llama count = %d, want the estimate %d", got, want)
+	}
+
+	n := CountTokensFor(text, "gpt-4o")
+	if n < 8 || n > 12 {
+		t.Errorf("gpt-4o count = %d, want 8-12", n)
+	}
+	if _, err := findMcCore(); err != nil {
+		if n != EstimateTokens(text, EncodingO200k) {
+			t.Errorf("without mc-core the count = %d, want the estimate", n)
+		}
+	}
+}
+
+var benchText = strings.Repeat("func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) { // 42 tokens or so\n", 100)
+
+func BenchmarkEstimateTokens(b *testing.B) {
+	for _, enc := range []string{EncodingCl100k, EncodingO200k, EncodingLlama} {
+		b.Run(enc, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				EstimateTokens(benchText, enc)
+			}
+		})
+	}
+}
+
+func BenchmarkCountTokensFor(b *testing.B) {
+	if _, err := findMcCore(); err != nil {
+		b.Skip("mc-core not found, only the estimate would be measured")
+	}
+	for _, model := range []string{"claude-sonnet-4", "gpt-4o"} {
+		b.Run(model, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				CountTokensFor(benchText, model)
+			}
+		})
+	}
+}
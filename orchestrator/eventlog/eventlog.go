@@ -0,0 +1,344 @@
+// Package eventlog is a bounded, persistent event queue. Events are
+// appended to a JSONL file with increasing sequence numbers and the last
+// Capacity of them are kept. Consumers read from the sequence they last
+// acknowledged, so a slow or restarted consumer catches up instead of
+// losing events. Appending never blocks on consumers.
+//
+// Acknowledgements are kept next to the log in <path>.acks. A log opened
+// with an empty path lives in memory only.
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity is the number of events kept when none is given.
+const DefaultCapacity = 10000
+
+// Record is one logged event.
+type Record struct {
+	Seq  uint64          `json:"seq"`
+	Type string          `json:"type"`
+	Time string          `json:"time"`
+	Data json.RawMessage `json:"data,omitempty"`
+
+	// Value is the data as passed to Append. It is nil for records
+	// loaded from disk.
+	Value interface{} `json:"-"`
+}
+
+// Log is a bounded event queue, safe for concurrent use.
+type Log struct {
+	path     string
+	capacity int
+
+	mu      sync.Mutex
+	file    *os.File
+	lines   int      // lines in the file, compacted at twice the capacity
+	records []Record // oldest first, at most capacity
+	next    uint64   // sequence of the next record
+	acks    map[string]uint64
+	notify  chan struct{} // closed and replaced on every append
+	closed  bool
+}
+
+// New returns an in-memory log keeping the last capacity events.
+func New(capacity int) *Log {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Log{
+		capacity: capacity,
+		next:     1,
+		acks:     make(map[string]uint64),
+		notify:   make(chan struct{}),
+	}
+}
+
+// Open opens the log at path, creating it if needed, and loads the last
+// capacity events and the consumer acknowledgements. Unreadable lines are
+// skipped.
+func Open(path string, capacity int) (*Log, error) {
+	l := New(capacity)
+	if path == "" {
+		return l, nil
+	}
+	l.path = path
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l.file = f
+	return l, nil
+}
+
+func (l *Log) load() error {
+	f, err := os.Open(l.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for sc.Scan() {
+			l.lines++
+			var rec Record
+			if json.Unmarshal(sc.Bytes(), &rec) != nil || rec.Seq < l.next {
+				continue
+			}
+			l.keep(rec)
+			l.next = rec.Seq + 1
+		}
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("reading %s: %w", l.path, err)
+		}
+	}
+
+	data, err := os.ReadFile(l.ackPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &l.acks); err != nil {
+			return fmt.Errorf("reading %s: %w", l.ackPath(), err)
+		}
+	}
+	return nil
+}
+
+func (l *Log) ackPath() string {
+	return l.path + ".acks"
+}
+
+// keep adds rec to the in-memory window, evicting the oldest record when
+// the log is full.
+func (l *Log) keep(rec Record) {
+	if len(l.records) >= l.capacity {
+		n := copy(l.records, l.records[1:])
+		l.records = l.records[:n]
+	}
+	l.records = append(l.records, rec)
+}
+
+// Append logs an event and wakes any waiting consumers.
+func (l *Log) Append(eventType string, data interface{}) (Record, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Record{}, err
+	}
+	if string(raw) == "null" {
+		raw = nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return Record{}, fmt.Errorf("event log closed")
+	}
+
+	rec := Record{
+		Seq:   l.next,
+		Type:  eventType,
+		Time:  time.Now().UTC().Format(time.RFC3339Nano),
+		Data:  raw,
+		Value: data,
+	}
+	if l.file != nil {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return Record{}, err
+		}
+		if _, err := l.file.Write(append(line, '\n')); err != nil {
+			return Record{}, err
+		}
+		l.lines++
+	}
+	l.next++
+	l.keep(rec)
+	if l.file != nil && l.lines >= 2*l.capacity {
+		if err := l.compact(); err != nil {
+			return rec, err
+		}
+	}
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+	return rec, nil
+}
+
+// compact rewrites the file with only the records still in the window.
+func (l *Log) compact() error {
+	tmp := l.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, rec := range l.records {
+		line, _ := json.Marshal(rec)
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+	l.file.Close()
+	l.file, err = os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0644)
+	l.lines = len(l.records)
+	return err
+}
+
+// Since returns the records after seq, oldest first. missed reports that
+// records after seq were already evicted, so the caller has a gap.
+func (l *Log) Since(seq uint64) (recs []Record, missed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.since(seq)
+}
+
+func (l *Log) since(seq uint64) ([]Record, bool) {
+	if len(l.records) == 0 {
+		return nil, false
+	}
+	first := l.records[0].Seq
+	missed := seq+1 < first
+	i := 0
+	if seq >= first {
+		i = int(seq - first + 1)
+	}
+	if i >= len(l.records) {
+		return nil, missed
+	}
+	return append([]Record(nil), l.records[i:]...), missed
+}
+
+// Pending returns the records consumer has not acknowledged.
+func (l *Log) Pending(consumer string) (recs []Record, missed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.since(l.acks[consumer])
+}
+
+// Acked returns the last sequence consumer acknowledged, 0 if none.
+func (l *Log) Acked(consumer string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acks[consumer]
+}
+
+// Last returns the sequence of the newest record, 0 if none was logged.
+func (l *Log) Last() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.next - 1
+}
+
+// Ack records that consumer has handled every record up to seq. Acks
+// never move a consumer backwards or past the newest record.
+func (l *Log) Ack(consumer string, seq uint64) error {
+	if consumer == "" {
+		return fmt.Errorf("consumer is required")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if seq >= l.next {
+		return fmt.Errorf("sequence %d has not been logged", seq)
+	}
+	if seq <= l.acks[consumer] {
+		return nil
+	}
+	l.acks[consumer] = seq
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(l.acks)
+	if err != nil {
+		return err
+	}
+	tmp := l.ackPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.ackPath())
+}
+
+// Subscribe delivers the records after consumer's last acknowledgement to
+// the returned channel, then every new record as it is appended, until
+// stop is closed or the log is closed. Delivery blocks on the receiver
+// rather than dropping; the receiver acknowledges with Ack.
+func (l *Log) Subscribe(consumer string, stop <-chan struct{}) <-chan Record {
+	out := make(chan Record)
+	go func() {
+		defer close(out)
+		cursor := l.Acked(consumer)
+		for {
+			l.mu.Lock()
+			recs, missed := l.since(cursor)
+			notify, closed := l.notify, l.closed
+			l.mu.Unlock()
+
+			if missed {
+				fmt.Fprintf(os.Stderr, "Warning: event log consumer %s fell behind, events after %d were evicted\n", consumer, cursor)
+			}
+			for _, rec := range recs {
+				select {
+				case out <- rec:
+					cursor = rec.Seq
+				case <-stop:
+					return
+				}
+			}
+			if len(recs) > 0 {
+				continue
+			}
+			if closed {
+				return
+			}
+			select {
+			case <-notify:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Close closes the log file. Subscribers finish once they have delivered
+// the remaining records.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	close(l.notify)
+	l.notify = make(chan struct{})
+	if l.file != nil {
+		return l.file.Close()
+	}
+	return nil
+}
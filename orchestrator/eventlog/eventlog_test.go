@@ -0,0 +1,112 @@
+package eventlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendSinceAndEviction(t *testing.T) {
+	l := New(3)
+	for _, typ := range []string{"a", "b", "c", "d"} {
+		if _, err := l.Append(typ, map[string]string{"t": typ}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.Last() != 4 {
+		t.Errorf("Last = %d, want 4", l.Last())
+	}
+
+	recs, missed := l.Since(2)
+	if missed || len(recs) != 2 || recs[0].Type != "c" || recs[1].Seq != 4 {
+		t.Errorf("Since(2) = %+v, missed=%v", recs, missed)
+	}
+	recs, missed = l.Since(0)
+	if !missed || len(recs) != 3 || recs[0].Type != "b" {
+		t.Errorf("Since(0) = %+v, missed=%v; want b..d with a evicted", recs, missed)
+	}
+	if recs, _ := l.Since(4); len(recs) != 0 {
+		t.Errorf("Since(4) = %+v, want none", recs)
+	}
+}
+
+func TestPersistenceAndAcks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orchestrator", "events.jsonl")
+	l, err := Open(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l.Append("task_updated", map[string]int{"i": i})
+	}
+	if err := l.Ack("hub", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Ack("hub", 1); err != nil || l.Acked("hub") != 2 {
+		t.Errorf("ack moved backwards: %v, acked=%d", err, l.Acked("hub"))
+	}
+	if err := l.Ack("hub", 9); err == nil {
+		t.Error("expected error acking an unlogged sequence")
+	}
+	l.Close()
+
+	l, err = Open(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	recs, _ := l.Pending("hub")
+	if len(recs) != 1 || recs[0].Seq != 3 || string(recs[0].Data) != `{"i":2}` {
+		t.Errorf("pending after reopen = %+v", recs)
+	}
+	if rec, _ := l.Append("task_updated", nil); rec.Seq != 4 {
+		t.Errorf("seq after reopen = %d, want 4", rec.Seq)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	l, err := Open(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 5; i++ {
+		l.Append("e", i)
+	}
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines > 3 {
+		t.Errorf("file has %d lines, want it compacted to the capacity", lines)
+	}
+	if recs, _ := l.Since(0); len(recs) != 2 || recs[1].Seq != 5 {
+		t.Errorf("records = %+v", recs)
+	}
+}
+
+func TestSubscribeDoesNotDrop(t *testing.T) {
+	l := New(0)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for i := 0; i < 500; i++ {
+		l.Append("e", i)
+	}
+	ch := l.Subscribe("ui", stop)
+	go func() {
+		for i := 500; i < 1000; i++ {
+			l.Append("e", i)
+		}
+	}()
+	for want := uint64(1); want <= 1000; want++ {
+		select {
+		case rec := <-ch:
+			if rec.Seq != want {
+				t.Fatalf("got seq %d, want %d", rec.Seq, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for seq %d", want)
+		}
+	}
+}
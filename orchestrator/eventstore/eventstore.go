@@ -0,0 +1,242 @@
+// Package eventstore is the mission's append-only mutation stream,
+// .mission/events/stream.jsonl. mc records every task, gate, stage and
+// checkpoint mutation there as it happens, and Project folds the stream
+// back into state, so a mission can be replayed up to any point.
+//
+// An event's sequence number is its line number in the stream. Lines are
+// only ever appended, one write each, so concurrent mc processes need no
+// lock to agree on the order.
+package eventstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+// Location of the stream inside .mission.
+const (
+	Dir      = "events"
+	FileName = "stream.jsonl"
+)
+
+// Event types
+const (
+	TaskCreated       = "task_created"
+	TaskUpdated       = "task_updated"
+	TaskDeleted       = "task_deleted"
+	GateApproved      = "gate_approved"
+	StageChanged      = "stage_changed"
+	StageRolledBack   = "stage_rolled_back"
+	CheckpointCreated = "checkpoint_created"
+)
+
+// Event is one recorded mutation. Seq is not stored; it is filled in from
+// the event's position when the stream is read.
+type Event struct {
+	Seq       uint64          `json:"seq,omitempty"`
+	Type      string          `json:"type"`
+	Time      string          `json:"time"`
+	User      string          `json:"user,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Payloads of the event types. Task events carry the whole task so a
+// projection needs nothing else.
+type (
+	TaskData struct {
+		ID   string          `json:"id"`
+		Task json.RawMessage `json:"task,omitempty"` // absent for task_deleted
+	}
+	GateData struct {
+		Stage      string `json:"stage"`
+		ApprovedBy string `json:"approved_by,omitempty"`
+		Note       string `json:"note,omitempty"`
+	}
+	StageData struct {
+		From   string `json:"from,omitempty"`
+		To     string `json:"to"`
+		Forced bool   `json:"forced,omitempty"`
+		Reason string `json:"reason,omitempty"`
+	}
+	CheckpointData struct {
+		ID    string `json:"id"`
+		Stage string `json:"stage,omitempty"`
+	}
+)
+
+// Path returns the stream file under missionDir.
+func Path(missionDir string) string {
+	return filepath.Join(missionDir, Dir, FileName)
+}
+
+// Append records e with data as its payload, setting the time if e has
+// none.
+func Append(missionDir string, e Event, data interface{}) error {
+	if e.Time == "" {
+		e.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	e.Seq = 0
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		e.Data = raw
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	path := Path(missionDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return jsonl.AppendLines(path, jsonl.Options{}, line)
+}
+
+// Filter selects events from the stream. Zero values match everything.
+type Filter struct {
+	Since uint64 // only events after this sequence number
+	Until uint64 // only events up to and including this one
+	Type  string
+}
+
+// Read returns the events matching f in order, and the sequence number of
+// the last event in the stream. A missing stream is empty. Unreadable
+// lines keep their sequence number but are skipped.
+func Read(missionDir string, f Filter) ([]Event, uint64, error) {
+	file, err := os.Open(Path(missionDir))
+	if os.IsNotExist(err) {
+		return []Event{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	events := []Event{}
+	var seq uint64
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		seq++
+		if seq <= f.Since || (f.Until > 0 && seq > f.Until) {
+			continue
+		}
+		var e Event
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue
+		}
+		if f.Type != "" && e.Type != f.Type {
+			continue
+		}
+		e.Seq = seq
+		events = append(events, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, fmt.Errorf("reading %s: %w", Path(missionDir), err)
+	}
+	return events, seq, nil
+}
+
+// GateState is a gate as projected from the stream.
+type GateState struct {
+	Status     string `json:"status"`
+	ApprovedAt string `json:"approved_at,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// State is the mission state derived from the stream.
+type State struct {
+	Seq         uint64               `json:"seq"` // last event applied
+	Stage       string               `json:"stage"`
+	StageSince  string               `json:"stage_since,omitempty"`
+	Tasks       []json.RawMessage    `json:"tasks"` // in creation order
+	Gates       map[string]GateState `json:"gates"`
+	Checkpoints []string             `json:"checkpoints"`
+
+	taskIndex map[string]int
+}
+
+// Project folds events into the state they leave the mission in.
+func Project(events []Event) *State {
+	s := &State{
+		Tasks:       []json.RawMessage{},
+		Gates:       map[string]GateState{},
+		Checkpoints: []string{},
+		taskIndex:   map[string]int{},
+	}
+	for _, e := range events {
+		s.Apply(e)
+	}
+	return s
+}
+
+// Apply folds one more event into a state from Project. Events of unknown
+// types only advance Seq.
+func (s *State) Apply(e Event) {
+	s.Seq = e.Seq
+	switch e.Type {
+	case TaskCreated, TaskUpdated:
+		var d TaskData
+		if json.Unmarshal(e.Data, &d) != nil || d.ID == "" {
+			return
+		}
+		if i, ok := s.taskIndex[d.ID]; ok {
+			s.Tasks[i] = d.Task
+			return
+		}
+		s.taskIndex[d.ID] = len(s.Tasks)
+		s.Tasks = append(s.Tasks, d.Task)
+	case TaskDeleted:
+		var d TaskData
+		if json.Unmarshal(e.Data, &d) != nil {
+			return
+		}
+		i, ok := s.taskIndex[d.ID]
+		if !ok {
+			return
+		}
+		s.Tasks = append(s.Tasks[:i], s.Tasks[i+1:]...)
+		delete(s.taskIndex, d.ID)
+		for id, j := range s.taskIndex {
+			if j > i {
+				s.taskIndex[id] = j - 1
+			}
+		}
+	case GateApproved:
+		var d GateData
+		if json.Unmarshal(e.Data, &d) != nil {
+			return
+		}
+		s.Gates[d.Stage] = GateState{Status: "approved", ApprovedAt: e.Time, ApprovedBy: d.ApprovedBy, Note: d.Note}
+	case StageChanged:
+		var d StageData
+		if json.Unmarshal(e.Data, &d) != nil {
+			return
+		}
+		s.Stage, s.StageSince = d.To, e.Time
+	case StageRolledBack:
+		var d StageData
+		if json.Unmarshal(e.Data, &d) != nil {
+			return
+		}
+		s.Stage, s.StageSince = d.To, e.Time
+		s.Gates[d.To] = GateState{Status: "pending"}
+	case CheckpointCreated:
+		var d CheckpointData
+		if json.Unmarshal(e.Data, &d) != nil {
+			return
+		}
+		s.Checkpoints = append(s.Checkpoints, d.ID)
+	}
+}
@@ -0,0 +1,68 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestAppendReadAndProject(t *testing.T) {
+	dir := t.TempDir()
+	appendEvent := func(typ string, data interface{}) {
+		t.Helper()
+		if err := Append(dir, Event{Type: typ, User: "Alice"}, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendEvent(StageChanged, StageData{To: "discovery"})
+	appendEvent(TaskCreated, TaskData{ID: "a", Task: json.RawMessage(`{"id":"a","status":"pending"}`)})
+	appendEvent(TaskCreated, TaskData{ID: "b", Task: json.RawMessage(`{"id":"b","status":"pending"}`)})
+	appendEvent(TaskUpdated, TaskData{ID: "a", Task: json.RawMessage(`{"id":"a","status":"done"}`)})
+	appendEvent(GateApproved, GateData{Stage: "discovery", ApprovedBy: "Alice"})
+	appendEvent(StageChanged, StageData{From: "discovery", To: "goal"})
+	appendEvent(TaskDeleted, TaskData{ID: "b"})
+	appendEvent(CheckpointCreated, CheckpointData{ID: "cp-1", Stage: "goal"})
+	appendEvent(StageRolledBack, StageData{From: "goal", To: "discovery", Reason: "approved too early"})
+
+	events, last, err := Read(dir, Filter{Since: 3, Type: StageChanged})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 9 || len(events) != 1 || events[0].Seq != 6 || events[0].User != "Alice" {
+		t.Errorf("Read = %+v, last %d", events, last)
+	}
+
+	all, _, _ := Read(dir, Filter{})
+	s := Project(all)
+	if s.Seq != 9 || s.Stage != "discovery" || len(s.Tasks) != 1 || string(s.Tasks[0]) != `{"id":"a","status":"done"}` {
+		t.Errorf("state = %+v", s)
+	}
+	if s.Gates["discovery"].Status != "pending" || s.Gates["discovery"].ApprovedBy != "" || len(s.Checkpoints) != 1 {
+		t.Errorf("gates = %+v, checkpoints = %v", s.Gates, s.Checkpoints)
+	}
+
+	upTo, _, _ := Read(dir, Filter{Until: 3})
+	if s := Project(upTo); s.Stage != "discovery" || len(s.Tasks) != 2 || len(s.Gates) != 0 {
+		t.Errorf("state at #3 = %+v", s)
+	}
+}
+
+func TestReadSkipsBadLinesButKeepsSequence(t *testing.T) {
+	dir := t.TempDir()
+	Append(dir, Event{Type: TaskCreated}, TaskData{ID: "a"})
+	f, _ := os.OpenFile(Path(dir), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("not json\n")
+	f.Close()
+	Append(dir, Event{Type: TaskCreated}, TaskData{ID: "b"})
+
+	events, last, err := Read(dir, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 3 || len(events) != 2 || events[1].Seq != 3 {
+		t.Errorf("events = %+v, last %d", events, last)
+	}
+	if events, last, _ := Read(t.TempDir(), Filter{}); len(events) != 0 || last != 0 {
+		t.Errorf("missing stream = %+v, %d", events, last)
+	}
+}
@@ -0,0 +1,327 @@
+// Package experiment runs A/B prompt experiments. An experiment registers
+// two prompt variants, a and b, for a persona; while it runs, mc spawn
+// alternates the persona's workers between them and records which variant
+// each worker was given. Report measures each variant by what came of its
+// spawns (how many handoffs passed validation, how often its tasks had to
+// be retried, the tokens spent and what reviewers found in its work) and
+// recommends a winner once both have enough spawns. The same report backs
+// `mc experiment report` and GET /api/experiments.
+//
+// Experiments are kept in .mission/state/experiments.json and their
+// prompts under .mission/prompts/experiments/<id>/.
+package experiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+// File holds the experiments, in the .mission/state directory.
+const File = "experiments.json"
+
+// MinSpawns is how many spawns each variant needs before Report picks a
+// winner.
+const MinSpawns = 3
+
+// VariantNames are the names of an experiment's two variants, in the order
+// spawns alternate between them.
+var VariantNames = []string{"a", "b"}
+
+// Experiment is one A/B prompt experiment on a persona.
+type Experiment struct {
+	ID        string    `json:"id"`
+	Persona   string    `json:"persona"`
+	Variants  []Variant `json:"variants"`
+	StartedAt string    `json:"started_at"`
+	EndedAt   string    `json:"ended_at,omitempty"`
+	Winner    string    `json:"winner,omitempty"` // variant promoted when it ended
+	Spawns    []Spawn   `json:"spawns,omitempty"`
+}
+
+// Variant is one of an experiment's prompts.
+type Variant struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"` // relative to .mission
+}
+
+// Spawn records the variant a worker was given and how its handoffs fared.
+type Spawn struct {
+	Variant         string `json:"variant"`
+	WorkerID        string `json:"worker_id"`
+	TaskID          string `json:"task_id,omitempty"`
+	At              string `json:"at"`
+	ValidHandoffs   int    `json:"valid_handoffs,omitempty"`
+	InvalidHandoffs int    `json:"invalid_handoffs,omitempty"`
+}
+
+// Running reports whether the experiment is still assigning spawns.
+func (e *Experiment) Running() bool {
+	return e.EndedAt == ""
+}
+
+// Variant returns the variant called name, or nil.
+func (e *Experiment) Variant(name string) *Variant {
+	for i := range e.Variants {
+		if e.Variants[i].Name == name {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+type store struct {
+	Experiments []Experiment `json:"experiments"`
+}
+
+// errUnchanged, returned by an update callback, leaves the store as it
+// is without failing the update.
+var errUnchanged = errors.New("unchanged")
+
+// Load returns the mission's experiments, oldest first.
+func Load(missionDir string) ([]Experiment, error) {
+	s, err := load(missionDir)
+	return s.Experiments, err
+}
+
+// Active returns persona's running experiment, or nil when it has none.
+func Active(missionDir, persona string) (*Experiment, error) {
+	s, err := load(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	if i := s.running(persona); i >= 0 {
+		return &s.Experiments[i], nil
+	}
+	return nil, nil
+}
+
+// Start registers an experiment on persona between prompts a and b. A
+// persona runs one experiment at a time.
+func Start(missionDir, persona string, a, b []byte, now time.Time) (*Experiment, error) {
+	if persona == "" {
+		return nil, fmt.Errorf("persona is required")
+	}
+	if strings.TrimSpace(string(a)) == "" || strings.TrimSpace(string(b)) == "" {
+		return nil, fmt.Errorf("both prompt variants must have content")
+	}
+	if string(a) == string(b) {
+		return nil, fmt.Errorf("the prompt variants are identical")
+	}
+
+	e := Experiment{
+		ID:        persona + "-" + now.UTC().Format("20060102-150405"),
+		Persona:   persona,
+		StartedAt: now.UTC().Format(time.RFC3339),
+	}
+	err := update(missionDir, func(s *store) error {
+		if i := s.running(persona); i >= 0 {
+			return fmt.Errorf("persona %s already has experiment %s running; stop it first", persona, s.Experiments[i].ID)
+		}
+		dir := filepath.Join(missionDir, "prompts", "experiments", e.ID)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		for i, prompt := range [][]byte{a, b} {
+			v := Variant{Name: VariantNames[i], Prompt: filepath.ToSlash(filepath.Join("prompts", "experiments", e.ID, VariantNames[i]+".md"))}
+			if err := os.WriteFile(filepath.Join(missionDir, v.Prompt), prompt, 0644); err != nil {
+				return fmt.Errorf("failed to write variant %s: %w", v.Name, err)
+			}
+			e.Variants = append(e.Variants, v)
+		}
+		s.Experiments = append(s.Experiments, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Assign gives workerID, on taskID, the variant of persona's running
+// experiment it runs with and records the spawn, alternating between the
+// two variants. Picking and recording are one locked step, so workers
+// spawned at the same time still alternate. It returns nils when persona
+// has no experiment running.
+func Assign(missionDir, persona, workerID, taskID string, now time.Time) (*Experiment, *Variant, error) {
+	if _, err := os.Stat(storePath(missionDir)); os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	var e *Experiment
+	var v *Variant
+	err := update(missionDir, func(s *store) error {
+		i := s.running(persona)
+		if i < 0 {
+			return errUnchanged
+		}
+		exp := &s.Experiments[i]
+		variant := exp.Variants[len(exp.Spawns)%len(exp.Variants)]
+		exp.Spawns = append(exp.Spawns, Spawn{
+			Variant:  variant.Name,
+			WorkerID: workerID,
+			TaskID:   taskID,
+			At:       now.UTC().Format(time.RFC3339),
+		})
+		copied := *exp
+		e, v = &copied, &variant
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, v, nil
+}
+
+// Unassign drops workerID's spawn, for a worker that was assigned a
+// variant but never started: only workers that ran count.
+func Unassign(missionDir, workerID string) error {
+	return update(missionDir, func(s *store) error {
+		for i := range s.Experiments {
+			spawns := s.Experiments[i].Spawns
+			for j := range spawns {
+				if spawns[j].WorkerID == workerID {
+					s.Experiments[i].Spawns = append(spawns[:j:j], spawns[j+1:]...)
+					return nil
+				}
+			}
+		}
+		return errUnchanged
+	})
+}
+
+// RecordHandoff counts a handoff from workerID as valid or not against the
+// variant the worker was given. Handoffs from workers outside any
+// experiment are ignored.
+func RecordHandoff(missionDir, workerID string, valid bool) error {
+	if workerID == "" {
+		return nil
+	}
+	if _, err := os.Stat(storePath(missionDir)); os.IsNotExist(err) {
+		return nil
+	}
+	return update(missionDir, func(s *store) error {
+		for i := range s.Experiments {
+			spawns := s.Experiments[i].Spawns
+			for j := range spawns {
+				if spawns[j].WorkerID != workerID {
+					continue
+				}
+				if valid {
+					spawns[j].ValidHandoffs++
+				} else {
+					spawns[j].InvalidHandoffs++
+				}
+				return nil
+			}
+		}
+		return errUnchanged
+	})
+}
+
+// Stop ends persona's running experiment. With a winner, that variant's
+// prompt replaces the persona's prompt in .mission/prompts/<persona>.md.
+func Stop(missionDir, persona, winner string, now time.Time) (*Experiment, error) {
+	var stopped Experiment
+	err := update(missionDir, func(s *store) error {
+		i := s.running(persona)
+		if i < 0 {
+			return fmt.Errorf("persona %s has no experiment running", persona)
+		}
+		e := &s.Experiments[i]
+		if winner != "" {
+			v := e.Variant(winner)
+			if v == nil {
+				return fmt.Errorf("unknown variant %q (valid: %s)", winner, strings.Join(VariantNames, ", "))
+			}
+			data, err := os.ReadFile(filepath.Join(missionDir, v.Prompt))
+			if err != nil {
+				return fmt.Errorf("failed to read variant %s: %w", v.Name, err)
+			}
+			if err := os.WriteFile(filepath.Join(missionDir, "prompts", persona+".md"), data, 0644); err != nil {
+				return fmt.Errorf("failed to promote variant %s: %w", v.Name, err)
+			}
+			e.Winner = winner
+		}
+		e.EndedAt = now.UTC().Format(time.RFC3339)
+		stopped = *e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stopped, nil
+}
+
+// running returns the index of persona's running experiment, -1 if none.
+func (s store) running(persona string) int {
+	for i := range s.Experiments {
+		if s.Experiments[i].Persona == persona && s.Experiments[i].Running() {
+			return i
+		}
+	}
+	return -1
+}
+
+func storePath(missionDir string) string {
+	return filepath.Join(missionDir, "state", File)
+}
+
+func load(missionDir string) (store, error) {
+	data, err := os.ReadFile(storePath(missionDir))
+	if os.IsNotExist(err) {
+		return store{}, nil
+	}
+	if err != nil {
+		return store{}, err
+	}
+	return decode(data)
+}
+
+// decode parses the store. An empty file, as a first locked update
+// leaves behind until it writes, has no experiments.
+func decode(data []byte) (store, error) {
+	var s store
+	if len(bytes.TrimSpace(data)) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("invalid %s: %w", File, err)
+	}
+	return s, nil
+}
+
+// update rewrites the store with what fn makes of it, holding the jsonl
+// lock on the file from the read to the rename, so concurrent mc
+// processes don't lose each other's changes. Nothing is written if fn
+// fails.
+func update(missionDir string, fn func(s *store) error) error {
+	path := storePath(missionDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	err := jsonl.Update(path, jsonl.Options{}, func(lines [][]byte) ([][]byte, error) {
+		s, err := decode(bytes.Join(lines, []byte("\n")))
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(&s); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Split(data, []byte("\n")), nil
+	})
+	if errors.Is(err, errUnchanged) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package experiment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+func TestExperimentLifecycle(t *testing.T) {
+	mission := filepath.Join(t.TempDir(), ".mission")
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	e, err := Start(mission, "developer", []byte("prompt A"), []byte("prompt B"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Start(mission, "developer", []byte("x"), []byte("y"), now); err == nil || !strings.Contains(err.Error(), "already has experiment "+e.ID) {
+		t.Errorf("expected a second experiment refused, got %v", err)
+	}
+	if _, err := Start(mission, "tester", []byte("same"), []byte("same"), now); err == nil {
+		t.Error("expected identical variants refused")
+	}
+
+	var got []string
+	for _, w := range []string{"w1", "w2", "w3"} {
+		_, v, err := Assign(mission, "developer", w, "t-"+w, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(filepath.Join(mission, v.Prompt))
+		got = append(got, v.Name+"="+string(data))
+	}
+	if strings.Join(got, ",") != "a=prompt A,b=prompt B,a=prompt A" {
+		t.Errorf("expected spawns to alternate, got %v", got)
+	}
+	if _, v, err := Assign(mission, "reviewer", "w9", "", now); err != nil || v != nil {
+		t.Errorf("expected no variant outside an experiment, got %v, %v", v, err)
+	}
+
+	// A worker that never started gives its turn back
+	if _, v, _ := Assign(mission, "developer", "w4", "", now); v.Name != "b" {
+		t.Fatalf("w4 got variant %s, want b", v.Name)
+	}
+	if err := Unassign(mission, "w4"); err != nil {
+		t.Fatal(err)
+	}
+	if _, v, _ := Assign(mission, "developer", "w5", "", now); v.Name != "b" {
+		t.Errorf("w5 got variant %s after w4 was dropped, want b", v.Name)
+	}
+
+	if _, err := Stop(mission, "developer", "c", now); err == nil {
+		t.Error("expected an unknown winner refused")
+	}
+	if _, err := Stop(mission, "developer", "b", now); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(mission, "prompts", "developer.md")); string(data) != "prompt B" {
+		t.Errorf("expected variant b promoted, got %q", data)
+	}
+	if active, _ := Active(mission, "developer"); active != nil {
+		t.Errorf("expected the experiment stopped, got %+v", active)
+	}
+}
+
+func TestAssignConcurrent(t *testing.T) {
+	mission := filepath.Join(t.TempDir(), ".mission")
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := Start(mission, "developer", []byte("A"), []byte("B"), now); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, err := Assign(mission, "developer", fmt.Sprintf("w%d", i), "", now); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	e, err := Active(mission, "developer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, sp := range e.Spawns {
+		counts[sp.Variant]++
+	}
+	if len(e.Spawns) != 20 || counts["a"] != 10 || counts["b"] != 10 {
+		t.Errorf("spawns = %d, by variant %v; want 20 split evenly", len(e.Spawns), counts)
+	}
+}
+
+func TestReportPicksWinner(t *testing.T) {
+	mission := filepath.Join(t.TempDir(), ".mission")
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := Start(mission, "developer", []byte("A"), []byte("B"), now); err != nil {
+		t.Fatal(err)
+	}
+
+	// a works t1, t3, then t1 again after its first try; b works t2, t4, t5
+	for _, sp := range [][2]string{{"w1", "t1"}, {"w2", "t2"}, {"w3", "t3"}, {"w4", "t4"}, {"w5", "t1"}, {"w6", "t5"}} {
+		if _, _, err := Assign(mission, "developer", sp[0], sp[1], now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for w, valid := range map[string]bool{"w1": false, "w2": true, "w3": true, "w4": true, "w5": true, "w6": true} {
+		if err := RecordHandoff(mission, w, valid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tasks := `{"id":"t1","stage":"implement","persona":"developer","status":"complete"}
+{"id":"t2","stage":"implement","persona":"developer","status":"complete"}
+{"id":"r1","stage":"verify","persona":"reviewer","status":"complete","depends_on":["t1","t2"]}
+`
+	if err := os.WriteFile(filepath.Join(mission, "state", "tasks.jsonl"), []byte(tasks), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(mission, "findings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	findings := `[{"type":"issue","summary":"x","severity":"high"},{"type":"issue","summary":"y","severity":"low"}]`
+	if err := os.WriteFile(filepath.Join(mission, "findings", "r1.json"), []byte(findings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tok := &tokens.TokenSummary{Sessions: []tokens.SessionTokens{{WorkerID: "w1", TotalTokens: 900}, {WorkerID: "w2", TotalTokens: 300}}}
+	results, err := Report(mission, tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || len(results[0].Variants) != 2 {
+		t.Fatalf("unexpected report %+v", results)
+	}
+	a, b := results[0].Variants[0], results[0].Variants[1]
+	if a.Spawns != 3 || a.Tasks != 2 || a.Retries != 1 || a.ValidHandoffs != 2 || a.InvalidHandoffs != 1 || a.Tokens != 900 {
+		t.Errorf("unexpected variant a %+v", a)
+	}
+	if b.Spawns != 3 || b.Retries != 0 || b.HandoffValidity != 1 || b.ReviewFindings != 2 || b.BlockingFindings != 1 {
+		t.Errorf("unexpected variant b %+v", b)
+	}
+	if a.ReviewFindings != 2 {
+		t.Errorf("expected r1's findings charged to a for t1, got %d", a.ReviewFindings)
+	}
+	if results[0].Winner != "b" || !strings.Contains(results[0].Reason, "higher handoff validity (100% vs 67% for a)") {
+		t.Errorf("expected b to win on validity, got %q: %s", results[0].Winner, results[0].Reason)
+	}
+
+	// Too few spawns to call it
+	if w, reason := pickWinner([]VariantResult{{Name: "a", Spawns: 1}, {Name: "b", Spawns: 5}}); w != "" || !strings.Contains(reason, "needs 3 spawns") {
+		t.Errorf("expected no winner yet, got %q: %s", w, reason)
+	}
+}
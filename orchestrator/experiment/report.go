@@ -0,0 +1,218 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// ReviewPersonas are the personas whose findings on a task count against
+// the variant that worked it.
+var ReviewPersonas = map[string]bool{"reviewer": true, "security": true}
+
+// Result is an experiment with each variant's outcomes and the winner the
+// report recommends.
+type Result struct {
+	ID        string          `json:"id"`
+	Persona   string          `json:"persona"`
+	Running   bool            `json:"running"`
+	StartedAt string          `json:"started_at"`
+	EndedAt   string          `json:"ended_at,omitempty"`
+	Promoted  string          `json:"promoted,omitempty"` // variant made the persona's prompt
+	Variants  []VariantResult `json:"variants"`
+	Winner    string          `json:"winner,omitempty"`
+	Reason    string          `json:"reason"`
+}
+
+// VariantResult is what came of one variant's spawns. HandoffValidity is
+// the share of its workers' handoffs that passed validation; a retry is
+// charged to the variant whose worker the task was spawned again after;
+// review findings are those reviewer and security tasks raised on the
+// tasks it worked, blocking ones being critical or high. Tokens are only
+// known while the orchestrator that ran the workers is up.
+type VariantResult struct {
+	Name             string  `json:"name"`
+	Prompt           string  `json:"prompt"`
+	Spawns           int     `json:"spawns"`
+	Tasks            int     `json:"tasks"`
+	ValidHandoffs    int     `json:"valid_handoffs"`
+	InvalidHandoffs  int     `json:"invalid_handoffs"`
+	HandoffValidity  float64 `json:"handoff_validity"`
+	Retries          int     `json:"retries"`
+	Tokens           int     `json:"tokens"`
+	TokensPerSpawn   float64 `json:"tokens_per_spawn"`
+	ReviewFindings   int     `json:"review_findings"`
+	BlockingFindings int     `json:"blocking_findings"`
+}
+
+type findingRecord struct {
+	Severity string `json:"severity"`
+}
+
+// Report measures the mission's experiments, oldest first. tok may be nil
+// when no live token data is available (e.g. when run from the CLI).
+func Report(missionDir string, tok *tokens.TokenSummary) ([]Result, error) {
+	experiments, err := Load(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	tasks, _, err := bridge.ReadTasks(filepath.Join(missionDir, "state", "tasks.jsonl"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	usage := map[string]int{}
+	if tok != nil {
+		for _, s := range tok.Sessions {
+			usage[s.WorkerID] += s.TotalTokens
+		}
+	}
+
+	results := []Result{}
+	for i := range experiments {
+		results = append(results, measure(missionDir, &experiments[i], tasks, usage))
+	}
+	return results, nil
+}
+
+func measure(missionDir string, e *Experiment, tasks []bridge.Task, usage map[string]int) Result {
+	res := Result{
+		ID:        e.ID,
+		Persona:   e.Persona,
+		Running:   e.Running(),
+		StartedAt: e.StartedAt,
+		EndedAt:   e.EndedAt,
+		Promoted:  e.Winner,
+	}
+	index := map[string]int{}
+	for _, v := range e.Variants {
+		index[v.Name] = len(res.Variants)
+		res.Variants = append(res.Variants, VariantResult{Name: v.Name, Prompt: v.Prompt})
+	}
+
+	// Spawns in order: the last variant on each task is the one whose work
+	// reviewers saw, and each later spawn on a task retries the one before
+	lastOn := map[string]string{}
+	worked := map[string]map[string]bool{}
+	for _, sp := range e.Spawns {
+		i, ok := index[sp.Variant]
+		if !ok {
+			continue
+		}
+		v := &res.Variants[i]
+		v.Spawns++
+		v.ValidHandoffs += sp.ValidHandoffs
+		v.InvalidHandoffs += sp.InvalidHandoffs
+		v.Tokens += usage[sp.WorkerID]
+		if sp.TaskID == "" {
+			continue
+		}
+		if prev, ok := lastOn[sp.TaskID]; ok {
+			res.Variants[index[prev]].Retries++
+		}
+		lastOn[sp.TaskID] = sp.Variant
+		if worked[sp.Variant] == nil {
+			worked[sp.Variant] = map[string]bool{}
+		}
+		worked[sp.Variant][sp.TaskID] = true
+	}
+
+	for _, t := range tasks {
+		if !ReviewPersonas[t.Persona] {
+			continue
+		}
+		var findings []findingRecord
+		if data, err := os.ReadFile(filepath.Join(missionDir, "findings", t.ID+".json")); err != nil || json.Unmarshal(data, &findings) != nil || len(findings) == 0 {
+			continue
+		}
+		charged := map[string]bool{}
+		for _, dep := range t.Deps() {
+			name, ok := lastOn[dep]
+			if !ok || charged[name] {
+				continue
+			}
+			charged[name] = true
+			v := &res.Variants[index[name]]
+			for _, f := range findings {
+				v.ReviewFindings++
+				if sev := strings.ToLower(f.Severity); sev == "critical" || sev == "high" {
+					v.BlockingFindings++
+				}
+			}
+		}
+	}
+
+	for i := range res.Variants {
+		v := &res.Variants[i]
+		v.Tasks = len(worked[v.Name])
+		if n := v.ValidHandoffs + v.InvalidHandoffs; n > 0 {
+			v.HandoffValidity = float64(v.ValidHandoffs) / float64(n)
+		}
+		if v.Spawns > 0 {
+			v.TokensPerSpawn = float64(v.Tokens) / float64(v.Spawns)
+		}
+	}
+	res.Winner, res.Reason = pickWinner(res.Variants)
+	return res
+}
+
+// pickWinner compares two variants on each outcome in turn, the first that
+// differs deciding: handoff validity, retries per spawn, blocking then all
+// review findings per task, and tokens per spawn. Outcomes one variant has
+// no data for are skipped.
+func pickWinner(vs []VariantResult) (string, string) {
+	if len(vs) != 2 {
+		return "", "an experiment needs two variants"
+	}
+	a, b := vs[0], vs[1]
+	if a.Spawns < MinSpawns || b.Spawns < MinSpawns {
+		return "", fmt.Sprintf("each variant needs %d spawns (%s: %d, %s: %d)", MinSpawns, a.Name, a.Spawns, b.Name, b.Spawns)
+	}
+
+	type outcome struct {
+		label        string
+		a, b         float64
+		higherBetter bool
+		known        bool
+		format       func(float64) string
+	}
+	perTask := func(n, tasks int) float64 {
+		if tasks == 0 {
+			return 0
+		}
+		return float64(n) / float64(tasks)
+	}
+	percent := func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) }
+	ratio := func(f float64) string { return fmt.Sprintf("%.2f", f) }
+	count := func(f float64) string { return fmt.Sprintf("%.0f", f) }
+	outcomes := []outcome{
+		{"handoff validity", a.HandoffValidity, b.HandoffValidity, true,
+			a.ValidHandoffs+a.InvalidHandoffs > 0 && b.ValidHandoffs+b.InvalidHandoffs > 0, percent},
+		{"retries per spawn", float64(a.Retries) / float64(a.Spawns), float64(b.Retries) / float64(b.Spawns), false, true, ratio},
+		{"blocking review findings per task", perTask(a.BlockingFindings, a.Tasks), perTask(b.BlockingFindings, b.Tasks), false,
+			a.Tasks > 0 && b.Tasks > 0, ratio},
+		{"review findings per task", perTask(a.ReviewFindings, a.Tasks), perTask(b.ReviewFindings, b.Tasks), false,
+			a.Tasks > 0 && b.Tasks > 0, ratio},
+		{"tokens per spawn", a.TokensPerSpawn, b.TokensPerSpawn, false, a.Tokens > 0 && b.Tokens > 0, count},
+	}
+	for _, o := range outcomes {
+		if !o.known || math.Abs(o.a-o.b) < 1e-9 {
+			continue
+		}
+		winner, loser, wv, lv := a.Name, b.Name, o.a, o.b
+		if (o.a > o.b) != o.higherBetter {
+			winner, loser, wv, lv = b.Name, a.Name, o.b, o.a
+		}
+		better := "fewer"
+		if o.higherBetter {
+			better = "higher"
+		}
+		return winner, fmt.Sprintf("%s: %s %s (%s vs %s for %s)", winner, better, o.label, o.format(wv), o.format(lv), loser)
+	}
+	return "", "no difference between the variants"
+}
@@ -0,0 +1,190 @@
+// Package export writes mission data for tooling outside MissionControl,
+// so finance and compliance can load it without parsing JSONL. Two tables
+// are exported:
+//
+//   - audit: every audit entry, rotated archives included, one row each,
+//     with its details as a JSON string
+//   - usage: the project's token usage ledger, one row per UTC day
+//
+// Each can be written as CSV or Parquet. The same tables back
+// `mc export` and GET /api/export/{audit,usage}.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+)
+
+// Tables that can be exported.
+const (
+	TableAudit = "audit"
+	TableUsage = "usage"
+)
+
+// Formats they can be written in.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Tables and Formats list the valid names, for help and error messages.
+var (
+	Tables  = []string{TableAudit, TableUsage}
+	Formats = []string{FormatCSV, FormatParquet}
+)
+
+// Type is a column's type. Values in a Table's rows are string, int64 or
+// float64 to match.
+type Type int
+
+const (
+	String Type = iota
+	Int64
+	Double
+)
+
+// Column is a named, typed column of a Table.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Table is the rows of an export, each with a value per column.
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// Build returns the named table, limited to rows at or after since (all
+// rows when since is zero).
+func Build(missionDir, table string, since time.Time) (*Table, error) {
+	switch table {
+	case TableAudit:
+		return Audit(missionDir, since)
+	case TableUsage:
+		return Usage(missionDir, since), nil
+	}
+	return nil, fmt.Errorf("unknown table %q (valid: %s)", table, strings.Join(Tables, ", "))
+}
+
+// Audit returns the audit entries since since, oldest first.
+func Audit(missionDir string, since time.Time) (*Table, error) {
+	res, err := audit.Query(missionDir, audit.Filter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{
+		Name: TableAudit,
+		Columns: []Column{
+			{"timestamp", String},
+			{"action", String},
+			{"category", String},
+			{"actor", String},
+			{"user", String},
+			{"request_id", String},
+			{"details", String},
+		},
+	}
+	for _, raw := range res.Entries {
+		var e audit.Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			continue
+		}
+		details := ""
+		if len(e.Details) > 0 {
+			data, _ := json.Marshal(e.Details)
+			details = string(data)
+		}
+		t.Rows = append(t.Rows, []interface{}{e.Timestamp, e.Action, e.CategoryOf(), e.Actor, e.User, e.RequestID, details})
+	}
+	return t, nil
+}
+
+// Usage returns the token usage ledger by day, from the day of since on,
+// oldest first.
+func Usage(missionDir string, since time.Time) *Table {
+	t := &Table{
+		Name: TableUsage,
+		Columns: []Column{
+			{"date", String},
+			{"tokens", Int64},
+			{"cost_usd", Double},
+		},
+	}
+	from := ""
+	if !since.IsZero() {
+		from = since.UTC().Format("2006-01-02")
+	}
+	days := budget.Days(missionDir)
+	dates := make([]string, 0, len(days))
+	for day := range days {
+		if day >= from {
+			dates = append(dates, day)
+		}
+	}
+	sort.Strings(dates)
+	for _, day := range dates {
+		u := days[day]
+		t.Rows = append(t.Rows, []interface{}{day, int64(u.Tokens), u.CostUSD})
+	}
+	return t
+}
+
+// Write writes t to w in format.
+func Write(w io.Writer, t *Table, format string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, t)
+	case FormatParquet:
+		return WriteParquet(w, t)
+	}
+	return fmt.Errorf("unknown format %q (valid: %s)", format, strings.Join(Formats, ", "))
+}
+
+// ContentType is the MIME type of format.
+func ContentType(format string) string {
+	if format == FormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// WriteCSV writes t as CSV with a header row.
+func WriteCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			switch v := v.(type) {
+			case string:
+				record[i] = v
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,252 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+)
+
+func setupMission(t *testing.T) string {
+	t.Helper()
+	mission := filepath.Join(t.TempDir(), ".mission")
+	if err := os.MkdirAll(mission, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lines := `{"timestamp":"2026-03-01T09:00:00Z","action":"task_created","actor":"cli","details":{"task_id":"t1"}}
+{"timestamp":"2026-03-02T09:00:00Z","action":"gate_approved","actor":"api","user":"Alice <alice@example.com>","request_id":"r-1","details":{"stage":"design","note":"ok, \"ship it\""}}
+`
+	if err := os.WriteFile(filepath.Join(mission, audit.FileName), []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []struct {
+		day    string
+		tokens int
+		cost   float64
+	}{{"2026-03-01", 1200, 0.5}, {"2026-03-02", 3000, 1.25}} {
+		at, _ := time.Parse("2006-01-02", u.day)
+		if err := budget.Record(mission, u.tokens, u.cost, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mission
+}
+
+func TestCSV(t *testing.T) {
+	mission := setupMission(t)
+
+	tbl, err := Build(mission, TableAudit, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, tbl, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	want := `timestamp,action,category,actor,user,request_id,details
+2026-03-01T09:00:00Z,task_created,task,cli,,,"{""task_id"":""t1""}"
+2026-03-02T09:00:00Z,gate_approved,gate,api,Alice <alice@example.com>,r-1,"{""note"":""ok, \""ship it\"""",""stage"":""design""}"
+`
+	if buf.String() != want {
+		t.Errorf("unexpected audit CSV:\n%s", buf.String())
+	}
+
+	tbl, err = Build(mission, TableUsage, time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := Write(&buf, tbl, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "date,tokens,cost_usd\n2026-03-02,3000,1.25\n" {
+		t.Errorf("expected usage from the day of since, got:\n%s", buf.String())
+	}
+
+	if _, err := Build(mission, "workers", time.Time{}); err == nil {
+		t.Error("expected an unknown table refused")
+	}
+	if err := Write(&buf, tbl, "xlsx"); err == nil {
+		t.Error("expected an unknown format refused")
+	}
+}
+
+func TestParquet(t *testing.T) {
+	mission := setupMission(t)
+	for _, name := range Tables {
+		t.Run(name, func(t *testing.T) {
+			tbl, err := Build(mission, name, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := WriteParquet(&buf, tbl); err != nil {
+				t.Fatal(err)
+			}
+			rows := readParquet(t, buf.Bytes())
+			if fmt.Sprint(rows) != fmt.Sprint(tbl.Rows) {
+				t.Errorf("read back %v, want %v", rows, tbl.Rows)
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteParquet(&buf, Usage(mission, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+			t.Fatal(err)
+		}
+		if rows := readParquet(t, buf.Bytes()); len(rows) != 0 {
+			t.Errorf("expected no rows, got %v", rows)
+		}
+	})
+}
+
+// readParquet reads back the rows of a file written by WriteParquet,
+// checking its framing and schema on the way.
+func readParquet(t *testing.T, file []byte) [][]interface{} {
+	t.Helper()
+	if !bytes.HasPrefix(file, []byte(parquetMagic)) || !bytes.HasSuffix(file, []byte(parquetMagic)) {
+		t.Fatal("missing PAR1 magic")
+	}
+	n := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-n : len(file)-8]
+	meta := readStruct(t, bytes.NewReader(footer))
+
+	schema := meta[2].([]interface{})
+	if root := schema[0].(map[int16]interface{}); string(root[4].([]byte)) != "schema" || root[5].(int64) != int64(len(schema)-1) {
+		t.Fatalf("unexpected schema root %v", root)
+	}
+	types := make([]int64, 0, len(schema)-1)
+	for _, el := range schema[1:] {
+		el := el.(map[int16]interface{})
+		if el[3].(int64) != parquetRequired {
+			t.Errorf("expected column %s required", el[4])
+		}
+		types = append(types, el[1].(int64))
+	}
+	numRows := int(meta[3].(int64))
+	groups := meta[4].([]interface{})
+	if numRows == 0 {
+		if len(groups) != 0 {
+			t.Errorf("expected no row groups, got %d", len(groups))
+		}
+		return nil
+	}
+
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(types))
+	}
+	columns := groups[0].(map[int16]interface{})[1].([]interface{})
+	for col, cc := range columns {
+		md := cc.(map[int16]interface{})[3].(map[int16]interface{})
+		if md[5].(int64) != int64(numRows) || md[1].(int64) != types[col] {
+			t.Fatalf("unexpected column metadata %v", md)
+		}
+		r := bytes.NewReader(file[md[9].(int64):])
+		page := readStruct(t, r)
+		dph := page[5].(map[int16]interface{})
+		if dph[1].(int64) != int64(numRows) || dph[2].(int64) != parquetPlain {
+			t.Fatalf("unexpected data page header %v", dph)
+		}
+		data := make([]byte, page[3].(int64))
+		r.Read(data)
+		for i := range rows {
+			switch types[col] {
+			case parquetByteArray:
+				l := binary.LittleEndian.Uint32(data)
+				rows[i][col] = string(data[4 : 4+l])
+				data = data[4+l:]
+			case parquetInt64:
+				rows[i][col] = int64(binary.LittleEndian.Uint64(data))
+				data = data[8:]
+			case parquetDouble:
+				rows[i][col] = math.Float64frombits(binary.LittleEndian.Uint64(data))
+				data = data[8:]
+			}
+		}
+		if len(data) != 0 {
+			t.Errorf("column %d: %d bytes left over", col, len(data))
+		}
+	}
+	return rows
+}
+
+// readStruct decodes a Thrift compact struct into its fields by id:
+// integers as int64, binaries as []byte, lists as []interface{} and
+// structs as maps.
+func readStruct(t *testing.T, r *bytes.Reader) map[int16]interface{} {
+	t.Helper()
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b == 0 {
+			return fields
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, _ := binary.ReadUvarint(r)
+			id = int16(unzigzag(v))
+		}
+		last = id
+		fields[id] = readValue(t, r, b&0x0f)
+	}
+}
+
+func readValue(t *testing.T, r *bytes.Reader, typ byte) interface{} {
+	switch typ {
+	case ctI32, ctI64:
+		v, _ := binary.ReadUvarint(r)
+		return unzigzag(v)
+	case ctBinary:
+		n, _ := binary.ReadUvarint(r)
+		b := make([]byte, n)
+		r.Read(b)
+		return b
+	case ctList:
+		h, _ := r.ReadByte()
+		n := uint64(h >> 4)
+		if n == 15 {
+			n, _ = binary.ReadUvarint(r)
+		}
+		list := []interface{}{}
+		for i := uint64(0); i < n; i++ {
+			list = append(list, readValue(t, r, h&0x0f))
+		}
+		return list
+	case ctStruct:
+		return readStruct(t, r)
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// Field 20 and a 20-element list need the long headers.
+func TestCompactLongForms(t *testing.T) {
+	var c compact
+	c.beginStruct(0)
+	c.list(20, ctI32, 20)
+	for i := 0; i < 20; i++ {
+		c.varint(zigzag(int64(-i)))
+	}
+	c.endStruct()
+	got := readStruct(t, bytes.NewReader(c.Bytes()))
+	if list := got[20].([]interface{}); len(list) != 20 || list[19].(int64) != -19 {
+		t.Errorf("unexpected list %v", got)
+	}
+}
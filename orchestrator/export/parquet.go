@@ -0,0 +1,238 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// parquetMagic opens and closes every Parquet file.
+const parquetMagic = "PAR1"
+
+// Parquet enum values, from parquet.thrift.
+const (
+	parquetByteArray = 6 // Type
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetRequired  = 0 // FieldRepetitionType
+	parquetUTF8      = 0 // ConvertedType
+	parquetDataPage  = 0 // PageType
+	parquetPlain     = 0 // Encoding
+	parquetRLE       = 3
+	parquetNoCodec   = 0 // CompressionCodec
+)
+
+// WriteParquet writes t as a Parquet file: one row group holding a single
+// data page per column, PLAIN encoded and uncompressed, with every column
+// required. Strings are UTF8 byte arrays. A table without rows is written
+// with its schema and no row groups.
+func WriteParquet(w io.Writer, t *Table) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	type chunk struct {
+		offset, size int64
+	}
+	var chunks []chunk
+	if len(t.Rows) > 0 {
+		for i := range t.Columns {
+			data := plainValues(t, i)
+			offset := int64(file.Len())
+			file.Write(pageHeader(len(t.Rows), len(data)))
+			file.Write(data)
+			chunks = append(chunks, chunk{offset, int64(file.Len()) - offset})
+		}
+	}
+
+	// FileMetaData
+	var m compact
+	m.beginStruct(0)
+	m.i32(1, 1) // version
+	m.list(2, ctStruct, len(t.Columns)+1)
+	m.beginStruct(0)
+	m.binary(4, "schema")
+	m.i32(5, int32(len(t.Columns)))
+	m.endStruct()
+	for _, c := range t.Columns {
+		m.beginStruct(0)
+		m.i32(1, physicalType(c.Type))
+		m.i32(3, parquetRequired)
+		m.binary(4, c.Name)
+		if c.Type == String {
+			m.i32(6, parquetUTF8)
+		}
+		m.endStruct()
+	}
+	m.i64(3, int64(len(t.Rows)))
+	if len(chunks) == 0 {
+		m.list(4, ctStruct, 0)
+	} else {
+		var total int64
+		m.list(4, ctStruct, 1)
+		m.beginStruct(0)
+		m.list(1, ctStruct, len(chunks))
+		for i, ch := range chunks {
+			m.beginStruct(0)
+			m.i64(2, ch.offset) // file_offset
+			m.beginStruct(3)    // ColumnMetaData
+			m.i32(1, physicalType(t.Columns[i].Type))
+			m.list(2, ctI32, 1)
+			m.varint(zigzag(parquetPlain))
+			m.list(3, ctBinary, 1)
+			m.str(t.Columns[i].Name)
+			m.i32(4, parquetNoCodec)
+			m.i64(5, int64(len(t.Rows)))
+			m.i64(6, ch.size)
+			m.i64(7, ch.size)
+			m.i64(9, ch.offset) // data_page_offset
+			m.endStruct()
+			m.endStruct()
+			total += ch.size
+		}
+		m.i64(2, total)
+		m.i64(3, int64(len(t.Rows)))
+		m.endStruct()
+	}
+	m.binary(6, "MissionControl")
+	m.endStruct()
+
+	file.Write(m.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(m.Len()))
+	file.WriteString(parquetMagic)
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+func physicalType(t Type) int32 {
+	switch t {
+	case Int64:
+		return parquetInt64
+	case Double:
+		return parquetDouble
+	}
+	return parquetByteArray
+}
+
+// pageHeader is the PageHeader of a data page of n PLAIN values taking
+// size bytes.
+func pageHeader(n, size int) []byte {
+	var h compact
+	h.beginStruct(0)
+	h.i32(1, parquetDataPage)
+	h.i32(2, int32(size)) // uncompressed_page_size
+	h.i32(3, int32(size)) // compressed_page_size
+	h.beginStruct(5)      // DataPageHeader
+	h.i32(1, int32(n))
+	h.i32(2, parquetPlain)
+	h.i32(3, parquetRLE) // definition levels, none for required columns
+	h.i32(4, parquetRLE) // repetition levels, none for flat schemas
+	h.endStruct()
+	h.endStruct()
+	return h.Bytes()
+}
+
+// plainValues PLAIN-encodes column i of t's rows.
+func plainValues(t *Table, i int) []byte {
+	var b bytes.Buffer
+	var word [8]byte
+	for _, row := range t.Rows {
+		switch v := row[i].(type) {
+		case string:
+			binary.LittleEndian.PutUint32(word[:4], uint32(len(v)))
+			b.Write(word[:4])
+			b.WriteString(v)
+		case int64:
+			binary.LittleEndian.PutUint64(word[:], uint64(v))
+			b.Write(word[:])
+		case float64:
+			binary.LittleEndian.PutUint64(word[:], math.Float64bits(v))
+			b.Write(word[:])
+		}
+	}
+	return b.Bytes()
+}
+
+// Thrift compact protocol field types.
+const (
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+// compact encodes Thrift structs with the compact protocol, which Parquet
+// uses for its page headers and footer.
+type compact struct {
+	bytes.Buffer
+	last  int16   // id of the last field written in the current struct
+	stack []int16 // last ids of the enclosing structs
+}
+
+func (c *compact) varint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	c.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func (c *compact) field(id int16, typ byte) {
+	if delta := id - c.last; delta > 0 && delta <= 15 {
+		c.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		c.WriteByte(typ)
+		c.varint(zigzag(int64(id)))
+	}
+	c.last = id
+}
+
+func (c *compact) i32(id int16, v int32) {
+	c.field(id, ctI32)
+	c.varint(zigzag(int64(v)))
+}
+
+func (c *compact) i64(id int16, v int64) {
+	c.field(id, ctI64)
+	c.varint(zigzag(v))
+}
+
+func (c *compact) str(s string) {
+	c.varint(uint64(len(s)))
+	c.WriteString(s)
+}
+
+func (c *compact) binary(id int16, s string) {
+	c.field(id, ctBinary)
+	c.str(s)
+}
+
+// list starts field id as a list of n elements of type elem, which follow
+// without field headers.
+func (c *compact) list(id int16, elem byte, n int) {
+	c.field(id, ctList)
+	if n < 15 {
+		c.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	c.WriteByte(0xf0 | elem)
+	c.varint(uint64(n))
+}
+
+// beginStruct starts a struct as field id, or as a list element or the
+// top-level struct when id is 0.
+func (c *compact) beginStruct(id int16) {
+	if id != 0 {
+		c.field(id, ctStruct)
+	}
+	c.stack = append(c.stack, c.last)
+	c.last = 0
+}
+
+func (c *compact) endStruct() {
+	c.WriteByte(0) // stop
+	c.last = c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+}
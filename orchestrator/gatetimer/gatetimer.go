@@ -0,0 +1,328 @@
+// Package gatetimer escalates gates left waiting for approval. A gate is
+// awaiting approval while its stage is the current one, it isn't approved,
+// and mc has marked it ready or every one of its criteria is satisfied.
+// The escalation policy is under "gate_escalation" in
+// .mission/config.json, as Go durations of waiting:
+//
+//	"gate_escalation": {"after": ["4h", "24h", "72h"], "slack_webhook": "secret:SLACK_GATES"}
+//
+// A Monitor checks the current gate every TickInterval and records when it
+// started waiting in .mission/state/gate_timers.json, so the wait outlasts
+// restarts and GET /api/gates can report it. Each time a waiting gate
+// passes one of the "after" durations it escalates a level: the monitor's
+// callback, the gate_escalated webhook and, with slack_webhook set, a
+// Slack message. The last level is critical, the earlier ones warnings.
+// The config is re-read on every tick, and each invalid duration in it is
+// logged once.
+package gatetimer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/logonce"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+)
+
+// TickInterval is how often a Monitor checks the current gate.
+const TickInterval = time.Minute
+
+// File holds the timers, in the .mission/state directory.
+const File = "gate_timers.json"
+
+// Severities of an escalation: every level is a warning but the last.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Policy is the "gate_escalation" section of config.json.
+type Policy struct {
+	After        []string `json:"after,omitempty"`
+	SlackWebhook string   `json:"slack_webhook,omitempty"`
+}
+
+// Timer is a gate awaiting approval: since when, and how many escalation
+// levels it has been through.
+type Timer struct {
+	Stage         string `json:"stage"`
+	AwaitingSince string `json:"awaiting_since"`
+	Level         int    `json:"level,omitempty"`
+	EscalatedAt   string `json:"escalated_at,omitempty"`
+}
+
+// Since returns when the gate started waiting.
+func (t Timer) Since() time.Time {
+	since, _ := time.Parse(time.RFC3339, t.AwaitingSince)
+	return since
+}
+
+// Escalation is a gate that has waited past one of the policy's durations.
+type Escalation struct {
+	Stage         string `json:"stage"`
+	Level         int    `json:"level"`  // 1-based
+	Levels        int    `json:"levels"` // in the policy
+	Severity      string `json:"severity"`
+	After         string `json:"after"`
+	AwaitingSince string `json:"awaiting_since"`
+	PendingMin    int    `json:"pending_min"`
+}
+
+// Monitor watches one project's current gate against its gate_escalation
+// policy.
+type Monitor struct {
+	projectDir   string
+	onEscalation func(Escalation)
+	done         chan struct{}
+	stopOnce     sync.Once
+
+	warn *logonce.Logger // logs each invalid config entry once
+}
+
+// Start starts a Monitor for projectDir. onEscalation, if set, is called
+// for each escalation.
+func Start(projectDir string, onEscalation func(Escalation)) *Monitor {
+	m := newMonitor(projectDir, onEscalation)
+	go m.run(TickInterval)
+	return m
+}
+
+func newMonitor(projectDir string, onEscalation func(Escalation)) *Monitor {
+	return &Monitor{
+		projectDir:   projectDir,
+		onEscalation: onEscalation,
+		done:         make(chan struct{}),
+		warn:         logonce.New("gatetimer: "),
+	}
+}
+
+// Stop stops the monitor.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.done) })
+}
+
+func (m *Monitor) missionDir() string {
+	return filepath.Join(m.projectDir, ".mission")
+}
+
+func (m *Monitor) run(interval time.Duration) {
+	m.tick(time.Now())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case now := <-ticker.C:
+			m.tick(now)
+		}
+	}
+}
+
+// tick starts or clears the current gate's timer and escalates it when it
+// has waited past the next level.
+func (m *Monitor) tick(now time.Time) {
+	missionDir := m.missionDir()
+	stage, awaiting := Awaiting(missionDir)
+	timers := Load(missionDir)
+	if !awaiting {
+		if len(timers) > 0 {
+			m.save(map[string]Timer{})
+		}
+		return
+	}
+
+	// Only the current gate waits; timers of earlier ones are dropped
+	t, ok := timers[stage]
+	if !ok || len(timers) > 1 {
+		if !ok {
+			t = Timer{Stage: stage, AwaitingSince: now.UTC().Format(time.RFC3339)}
+		}
+		timers = map[string]Timer{stage: t}
+		m.save(timers)
+	}
+
+	levels, slack := loadPolicy(missionDir, m.warn)
+	pending := now.Sub(t.Since())
+	level := 0
+	for i, d := range levels {
+		if pending >= d {
+			level = i + 1
+		}
+	}
+	if level <= t.Level {
+		return
+	}
+	// A monitor that was down skips to the level the wait has reached
+	t.Level = level
+	t.EscalatedAt = now.UTC().Format(time.RFC3339)
+	timers[stage] = t
+	m.save(timers)
+
+	e := Escalation{
+		Stage:         stage,
+		Level:         level,
+		Levels:        len(levels),
+		Severity:      SeverityWarning,
+		After:         levels[level-1].String(),
+		AwaitingSince: t.AwaitingSince,
+		PendingMin:    int(pending.Minutes()),
+	}
+	if level == len(levels) {
+		e.Severity = SeverityCritical
+	}
+	log.Printf("gatetimer: %s gate has awaited approval %d minutes (level %d/%d)", e.Stage, e.PendingMin, e.Level, e.Levels)
+	m.notify(e, slack)
+	if m.onEscalation != nil {
+		m.onEscalation(e)
+	}
+}
+
+// notify sends the gate_escalated webhook and the Slack message. Delivery
+// is best effort.
+func (m *Monitor) notify(e Escalation, slack string) {
+	if cfg, err := bridge.LoadProjectConfig(m.projectDir); err == nil && len(cfg.Webhooks) > 0 {
+		if err := webhook.Send(context.Background(), cfg.Webhooks, webhook.EventGateEscalated, e); err != nil {
+			log.Printf("gatetimer: gate_escalated webhook: %v", err)
+		}
+	}
+	if slack != "" {
+		if err := webhook.Slack(context.Background(), slack, Message(e)); err != nil {
+			log.Printf("gatetimer: slack: %v", err)
+		}
+	}
+}
+
+func (m *Monitor) save(timers map[string]Timer) {
+	if err := save(m.missionDir(), timers); err != nil {
+		log.Printf("gatetimer: saving %s: %v", File, err)
+	}
+}
+
+// Message is the Slack text for e.
+func Message(e Escalation) string {
+	prefix := ":warning:"
+	if e.Severity == SeverityCritical {
+		prefix = ":rotating_light:"
+	}
+	return fmt.Sprintf("%s The *%s* gate has been awaiting approval for %dh%02dm (escalation %d of %d). Approve it with `mc gate approve %s`.",
+		prefix, e.Stage, e.PendingMin/60, e.PendingMin%60, e.Level, e.Levels, e.Stage)
+}
+
+// LoadPolicy reads the gate_escalation policy from config.json: its
+// levels, shortest first, and the Slack webhook. Durations that don't
+// parse, or aren't positive, are ignored.
+func LoadPolicy(missionDir string) ([]time.Duration, string) {
+	return loadPolicy(missionDir, nil)
+}
+
+// loadPolicy is LoadPolicy, also logging each duration it ignored to warn.
+func loadPolicy(missionDir string, warn *logonce.Logger) ([]time.Duration, string) {
+	var cfg struct {
+		GateEscalation Policy `json:"gate_escalation"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	var levels []time.Duration
+	for _, s := range cfg.GateEscalation.After {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			warn.Printf("ignoring gate_escalation level: invalid duration %q", s)
+			continue
+		}
+		levels = append(levels, d)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	return levels, cfg.GateEscalation.SlackWebhook
+}
+
+// Load returns the gates awaiting approval, by stage, as the monitor last
+// recorded them.
+func Load(missionDir string) map[string]Timer {
+	var f struct {
+		Gates map[string]Timer `json:"gates"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "state", File)); err == nil {
+		_ = json.Unmarshal(data, &f)
+	}
+	if f.Gates == nil {
+		f.Gates = map[string]Timer{}
+	}
+	return f.Gates
+}
+
+func save(missionDir string, timers map[string]Timer) error {
+	data, err := json.MarshalIndent(map[string]interface{}{"gates": timers}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(missionDir, "state", File)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// criterion is a gate criterion in either gates.json format: a plain
+// string (never satisfied) or {"description", "satisfied"}.
+type criterion struct {
+	Satisfied bool
+}
+
+func (c *criterion) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return nil
+	}
+	var obj struct {
+		Satisfied bool `json:"satisfied"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	c.Satisfied = obj.Satisfied
+	return nil
+}
+
+// Awaiting returns the current stage and whether its gate is awaiting
+// approval.
+func Awaiting(missionDir string) (string, bool) {
+	var stage struct {
+		Current string `json:"current"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "state", "stage.json")); err != nil || json.Unmarshal(data, &stage) != nil || stage.Current == "" {
+		return "", false
+	}
+	var gates struct {
+		Gates map[string]struct {
+			Status   string      `json:"status"`
+			Criteria []criterion `json:"criteria"`
+		} `json:"gates"`
+	}
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "gates.json"))
+	if err != nil || json.Unmarshal(data, &gates) != nil {
+		return stage.Current, false
+	}
+	g, ok := gates.Gates[stage.Current]
+	if !ok || g.Status == "approved" {
+		return stage.Current, false
+	}
+	if g.Status == "ready" {
+		return stage.Current, true
+	}
+	for _, c := range g.Criteria {
+		if !c.Satisfied {
+			return stage.Current, false
+		}
+	}
+	return stage.Current, len(g.Criteria) > 0
+}
@@ -0,0 +1,129 @@
+package gatetimer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTickEscalatesWaitingGate(t *testing.T) {
+	var delivered, slack []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/slack" {
+			var m struct {
+				Text string `json:"text"`
+			}
+			json.Unmarshal(body, &m)
+			slack = append(slack, m.Text)
+			return
+		}
+		var e struct {
+			Event string     `json:"event"`
+			Data  Escalation `json:"data"`
+		}
+		json.Unmarshal(body, &e)
+		delivered = append(delivered, e.Event+":"+e.Data.Severity)
+	}))
+	defer hook.Close()
+
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	writeFile(t, filepath.Join(mission, "config.json"), `{"gate_escalation":{"after":["24h","2h","soon"],"slack_webhook":"`+hook.URL+`/slack"},"webhooks":[{"url":"`+hook.URL+`/hook"}]}`)
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"design"}`)
+	gates := filepath.Join(mission, "state", "gates.json")
+	writeFile(t, gates, `{"gates":{"design":{"criteria":[{"description":"Spec","satisfied":true},{"description":"Review","satisfied":false}]}}}`)
+
+	var got []Escalation
+	m := newMonitor(dir, func(e Escalation) { got = append(got, e) })
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// Not waiting until every criterion is met
+	m.tick(start)
+	if timers := Load(mission); len(timers) != 0 {
+		t.Fatalf("expected no timer for an unready gate, got %+v", timers)
+	}
+	writeFile(t, gates, `{"gates":{"design":{"criteria":[{"description":"Spec","satisfied":true},{"description":"Review","satisfied":true}]}}}`)
+	m.tick(start)
+	if timer := Load(mission)["design"]; timer.AwaitingSince != "2026-03-01T10:00:00Z" {
+		t.Fatalf("expected the wait timed from now, got %+v", timer)
+	}
+
+	m.tick(start.Add(time.Hour))
+	if len(got) != 0 {
+		t.Fatalf("expected no escalation within the first level, got %+v", got)
+	}
+	m.tick(start.Add(3 * time.Hour))
+	m.tick(start.Add(4 * time.Hour))
+	if len(got) != 1 || got[0].Level != 1 || got[0].Levels != 2 || got[0].Severity != SeverityWarning || got[0].PendingMin != 180 {
+		t.Fatalf("expected one warning at level 1, got %+v", got)
+	}
+	m.tick(start.Add(25 * time.Hour))
+	if len(got) != 2 || got[1].Level != 2 || got[1].Severity != SeverityCritical {
+		t.Fatalf("expected a critical escalation at the last level, got %+v", got)
+	}
+	if strings.Join(delivered, ",") != "gate_escalated:warning,gate_escalated:critical" {
+		t.Errorf("expected two gate_escalated webhooks, got %v", delivered)
+	}
+	if len(slack) != 2 || !strings.Contains(slack[1], "*design* gate has been awaiting approval for 25h00m (escalation 2 of 2)") {
+		t.Errorf("unexpected Slack messages %q", slack)
+	}
+
+	// Approval clears the timer
+	writeFile(t, gates, `{"gates":{"design":{"status":"approved","criteria":[]}}}`)
+	m.tick(start.Add(26 * time.Hour))
+	if timers := Load(mission); len(timers) != 0 {
+		t.Errorf("expected the timer cleared on approval, got %+v", timers)
+	}
+}
+
+func TestTickLogsInvalidLevelOnce(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	writeFile(t, filepath.Join(mission, "config.json"), `{"gate_escalation":{"after":["soon"]}}`)
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"design"}`)
+	writeFile(t, filepath.Join(mission, "state", "gates.json"), `{"gates":{"design":{"status":"ready"}}}`)
+
+	m := newMonitor(dir, nil)
+	for i := 0; i < 3; i++ {
+		m.tick(time.Date(2026, 3, 1, 10, i, 0, 0, time.UTC))
+	}
+	if n := strings.Count(logs.String(), `invalid duration "soon"`); n != 1 {
+		t.Errorf("invalid duration logged %d times, want once:\n%s", n, logs.String())
+	}
+}
+
+func TestAwaitingLegacyGates(t *testing.T) {
+	mission := filepath.Join(t.TempDir(), ".mission")
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"verify"}`)
+	writeFile(t, filepath.Join(mission, "state", "gates.json"), `{"gates":{"verify":{"status":"pending","criteria":["Tests pass"]}}}`)
+	if _, ok := Awaiting(mission); ok {
+		t.Error("expected a gate with plain-string criteria not to be awaiting")
+	}
+	writeFile(t, filepath.Join(mission, "state", "gates.json"), `{"gates":{"verify":{"status":"ready","criteria":["Tests pass"]}}}`)
+	if stage, ok := Awaiting(mission); !ok || stage != "verify" {
+		t.Errorf("expected a ready gate to be awaiting, got %q %v", stage, ok)
+	}
+}
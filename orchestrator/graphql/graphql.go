@@ -0,0 +1,488 @@
+// Package graphql executes GraphQL queries against a schema of Go
+// resolvers. It covers what a dashboard needs to fetch nested data in one
+// round trip: queries with arguments, variables, aliases, fragments and
+// @include/@skip. Mutations, subscriptions and introspection beyond
+// __typename are not supported; writes go through the REST API.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Limits on a query, checked before anything runs, so one request can't
+// be made to do unbounded work.
+const (
+	// MaxDepth bounds how deeply a query may nest, so a cycle in the
+	// schema (task → dependencies → task ...) can't be followed forever.
+	MaxDepth = 12
+	// MaxSelections bounds the fields and fragment spreads of a query
+	// with its fragments expanded, so fragments that spread each other
+	// twice over can't blow it up.
+	MaxSelections = 500
+	// MaxAliases bounds the aliased fields, which would otherwise let a
+	// query ask for the same expensive field many times over.
+	MaxAliases = 50
+	// MaxComplexity bounds a query's estimated cost: a field costs 1
+	// plus its selection's cost, ListSize times over for a list.
+	MaxComplexity = 10000
+	// ListSize is how many elements a list field is assumed to return.
+	ListSize = 10
+)
+
+// Schema is a set of object types and the one queries start from.
+type Schema struct {
+	Query string
+	Types map[string]*Object
+}
+
+// Object is an object type.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an object type.
+type Field struct {
+	// Type names the object type the field returns, for the field or each
+	// element of a list; empty for scalars and lists of scalars.
+	Type string
+	// List marks a field returning a list of Type, for the complexity
+	// estimate.
+	List bool
+	// Args are the arguments the field accepts.
+	Args []string
+	// Resolve produces the field's value. Nil reads the field's name from
+	// a map[string]interface{} source.
+	Resolve func(p Params) (interface{}, error)
+}
+
+// Params are passed to a resolver.
+type Params struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+}
+
+// Request is a GraphQL request, as POSTed or sent as GET parameters.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is nil when the request could
+// not be executed at all.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Error is a request or field error.
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Execute parses, validates and runs req. Field errors leave the field
+// null and are listed alongside the data.
+func (s *Schema) Execute(ctx context.Context, req Request) Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	if op.kind != "query" {
+		return Response{Errors: []Error{{Message: op.kind + "s are not supported; use the REST API for writes"}}}
+	}
+	vars, err := coerceVariables(op, req.Variables)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{schema: s, doc: doc, vars: vars, ctx: ctx}
+	root := s.Types[s.Query]
+	if root == nil {
+		return Response{Errors: []Error{{Message: "the schema has no query type"}}}
+	}
+	e.validating = true
+	cost, errs := e.validate(root, op.sel, 1)
+	e.validating = false
+	if len(errs) > 0 {
+		return Response{Errors: errs}
+	}
+	if e.aliases > MaxAliases {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("the query has more than %d aliases", MaxAliases)}}}
+	}
+	if cost > MaxComplexity {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("the query is too complex (%d, at most %d)", cost, MaxComplexity)}}}
+	}
+	data := e.selectionSet(root, nil, op.sel, nil, 1)
+	return Response{Data: data, Errors: e.errors}
+}
+
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, fmt.Errorf("the document has several operations; name one in operationName")
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("no operation named %q", name)
+}
+
+func coerceVariables(op *operation, given map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, v := range op.vars {
+		if val, ok := given[v.name]; ok && val != nil {
+			vars[v.name] = val
+			continue
+		}
+		if v.def != nil {
+			val, err := v.def.resolve(nil)
+			if err != nil {
+				return nil, err
+			}
+			vars[v.name] = val
+			continue
+		}
+		if v.nonNull {
+			return nil, fmt.Errorf("variable $%s is required", v.name)
+		}
+	}
+	return vars, nil
+}
+
+func (v value) resolve(vars map[string]interface{}) (interface{}, error) {
+	switch v.kind {
+	case valVariable:
+		val, ok := vars[v.raw]
+		if !ok {
+			return nil, nil
+		}
+		return val, nil
+	case valInt:
+		n, err := strconv.Atoi(v.raw)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s out of range", v.raw)
+		}
+		return n, nil
+	case valFloat:
+		return strconv.ParseFloat(v.raw, 64)
+	case valString, valEnum:
+		return v.raw, nil
+	case valBool:
+		return v.raw == "true", nil
+	case valList:
+		out := make([]interface{}, 0, len(v.list))
+		for _, item := range v.list {
+			val, err := item.resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, val)
+		}
+		return out, nil
+	case valObject:
+		out := make(map[string]interface{}, len(v.fields))
+		for _, f := range v.fields {
+			val, err := f.val.resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			out[f.name] = val
+		}
+		return out, nil
+	}
+	return nil, nil
+}
+
+type executor struct {
+	schema *Schema
+	doc    *document
+	vars   map[string]interface{}
+	ctx    context.Context
+	errors []Error
+
+	// While validating, the selections collected and aliases seen
+	validating bool
+	selections int
+	aliases    int
+}
+
+// collected is the fields of a selection set under one response key.
+type collected struct {
+	key    string
+	fields []*field
+}
+
+// collect flattens fragments and applies @include/@skip, grouping fields
+// by response key in the order they first appear.
+func (e *executor) collect(typ *Object, sels []selection, out []collected, visited map[string]bool) ([]collected, error) {
+	for _, s := range sels {
+		if e.validating {
+			if e.selections++; e.selections > MaxSelections {
+				return nil, fmt.Errorf("the query selects more than %d fields", MaxSelections)
+			}
+		}
+		include, err := e.included(s.directives)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+		switch {
+		case s.field != nil:
+			key := s.field.key()
+			i := 0
+			for i < len(out) && out[i].key != key {
+				i++
+			}
+			if i == len(out) {
+				out = append(out, collected{key: key})
+			}
+			out[i].fields = append(out[i].fields, s.field)
+		case s.inline:
+			if s.on != "" && s.on != typ.Name {
+				continue
+			}
+			if out, err = e.collect(typ, s.sel, out, visited); err != nil {
+				return nil, err
+			}
+		default:
+			frag, ok := e.doc.fragments[s.spread]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", s.spread)
+			}
+			if visited[s.spread] || frag.on != typ.Name {
+				continue
+			}
+			visited[s.spread] = true
+			out, err = e.collect(typ, frag.sel, out, visited)
+			delete(visited, s.spread)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+func (e *executor) included(dirs []directive) (bool, error) {
+	for _, d := range dirs {
+		if d.name != "include" && d.name != "skip" {
+			return false, fmt.Errorf("unknown directive @%s", d.name)
+		}
+		var cond interface{}
+		for _, a := range d.args {
+			if a.name == "if" {
+				cond, _ = a.val.resolve(e.vars)
+			}
+		}
+		b, ok := cond.(bool)
+		if !ok {
+			return false, fmt.Errorf("@%s needs a boolean 'if'", d.name)
+		}
+		if (d.name == "include") != b {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// validate checks a selection set against typ before anything runs and
+// returns its estimated cost.
+func (e *executor) validate(typ *Object, sels []selection, depth int) (int, []Error) {
+	if depth > MaxDepth {
+		return 0, []Error{{Message: fmt.Sprintf("the query nests deeper than %d levels", MaxDepth)}}
+	}
+	for _, s := range sels {
+		if s.spread != "" {
+			if frag, ok := e.doc.fragments[s.spread]; ok && e.schema.Types[frag.on] == nil {
+				return 0, []Error{{Message: fmt.Sprintf("fragment %q is on unknown type %q", s.spread, frag.on)}}
+			}
+		}
+		if s.inline && s.on != "" && e.schema.Types[s.on] == nil {
+			return 0, []Error{{Message: fmt.Sprintf("unknown type %q", s.on)}}
+		}
+	}
+	fields, err := e.collect(typ, sels, nil, map[string]bool{})
+	if err != nil {
+		return 0, []Error{{Message: err.Error()}}
+	}
+	var errs []Error
+	cost := 0
+	for _, c := range fields {
+		for _, f := range c.fields {
+			cost++
+			if f.alias != "" && f.alias != f.name {
+				e.aliases++
+			}
+			if f.name == "__typename" {
+				if f.sel != nil {
+					errs = append(errs, Error{Message: "__typename has no fields"})
+				}
+				continue
+			}
+			def, ok := typ.Fields[f.name]
+			if !ok {
+				errs = append(errs, Error{Message: fmt.Sprintf("cannot query field %q on type %q", f.name, typ.Name)})
+				continue
+			}
+			for _, a := range f.args {
+				if !contains(def.Args, a.name) {
+					errs = append(errs, Error{Message: fmt.Sprintf("unknown argument %q on field %s.%s", a.name, typ.Name, f.name)})
+				}
+			}
+			switch {
+			case def.Type == "" && f.sel != nil:
+				errs = append(errs, Error{Message: fmt.Sprintf("field %s.%s is a scalar and has no fields", typ.Name, f.name)})
+			case def.Type != "" && f.sel == nil:
+				errs = append(errs, Error{Message: fmt.Sprintf("field %s.%s needs a selection of fields", typ.Name, f.name)})
+			case def.Type != "":
+				next := e.schema.Types[def.Type]
+				if next == nil {
+					errs = append(errs, Error{Message: fmt.Sprintf("field %s.%s has unknown type %q", typ.Name, f.name, def.Type)})
+					continue
+				}
+				sub, subErrs := e.validate(next, f.sel, depth+1)
+				errs = append(errs, subErrs...)
+				if def.List {
+					sub *= ListSize
+				}
+				cost += sub
+			}
+			if e.selections > MaxSelections {
+				return cost, errs
+			}
+		}
+	}
+	return cost, errs
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// selectionSet resolves sels on src, an object of type typ.
+func (e *executor) selectionSet(typ *Object, src interface{}, sels []selection, path []interface{}, depth int) *orderedMap {
+	out := &orderedMap{}
+	fields, _ := e.collect(typ, sels, nil, map[string]bool{})
+	for _, c := range fields {
+		f := c.fields[0]
+		fieldPath := append(append([]interface{}(nil), path...), c.key)
+		if f.name == "__typename" {
+			out.set(c.key, typ.Name)
+			continue
+		}
+		def := typ.Fields[f.name]
+		val, err := e.resolve(def, f, src)
+		if err != nil {
+			e.errors = append(e.errors, Error{Message: err.Error(), Path: fieldPath})
+			out.set(c.key, nil)
+			continue
+		}
+		if def.Type == "" {
+			out.set(c.key, val)
+			continue
+		}
+		var sub []selection
+		for _, f := range c.fields {
+			sub = append(sub, f.sel...)
+		}
+		out.set(c.key, e.complete(e.schema.Types[def.Type], val, sub, fieldPath, depth+1))
+	}
+	return out
+}
+
+func (e *executor) resolve(def *Field, f *field, src interface{}) (interface{}, error) {
+	args := make(map[string]interface{}, len(f.args))
+	for _, a := range f.args {
+		val, err := a.val.resolve(e.vars)
+		if err != nil {
+			return nil, err
+		}
+		if val != nil {
+			args[a.name] = val
+		}
+	}
+	if def.Resolve == nil {
+		m, _ := src.(map[string]interface{})
+		return m[f.name], nil
+	}
+	return def.Resolve(Params{Context: e.ctx, Source: src, Args: args})
+}
+
+// complete resolves the selection on an object value, or on each element
+// of a list of them.
+func (e *executor) complete(typ *Object, val interface{}, sels []selection, path []interface{}, depth int) interface{} {
+	if val == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Map) && rv.IsNil() {
+		return nil
+	}
+	if rv.Kind() != reflect.Slice {
+		return e.selectionSet(typ, val, sels, path, depth)
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = e.complete(typ, rv.Index(i).Interface(), sels, append(append([]interface{}(nil), path...), i), depth)
+	}
+	return out
+}
+
+// orderedMap keeps response keys in selection order, as the spec asks.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, v interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
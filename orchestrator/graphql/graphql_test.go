@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testSchema has people with friends, which makes a cycle.
+func testSchema() *Schema {
+	people := map[string]map[string]interface{}{
+		"ann": {"id": "ann", "name": "Ann", "age": 30, "tags": []string{"a", "b"}},
+		"bob": {"id": "bob", "name": "Bob", "age": 40},
+	}
+	friends := map[string][]string{"ann": {"bob"}, "bob": {"ann"}}
+	return &Schema{
+		Query: "Query",
+		Types: map[string]*Object{
+			"Query": {Name: "Query", Fields: map[string]*Field{
+				"person": {Type: "Person", Args: []string{"id"}, Resolve: func(p Params) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					if id == "err" {
+						return nil, fmt.Errorf("lookup failed")
+					}
+					if person, ok := people[id]; ok {
+						return person, nil
+					}
+					return nil, nil
+				}},
+			}},
+			"Person": {Name: "Person", Fields: map[string]*Field{
+				"id":   {},
+				"name": {},
+				"age":  {},
+				"tags": {},
+				"friends": {Type: "Person", List: true, Resolve: func(p Params) (interface{}, error) {
+					var out []map[string]interface{}
+					for _, id := range friends[p.Source.(map[string]interface{})["id"].(string)] {
+						out = append(out, people[id])
+					}
+					return out, nil
+				}},
+			}},
+		},
+	}
+}
+
+func run(t *testing.T, req Request) string {
+	t.Helper()
+	data, err := json.Marshal(testSchema().Execute(context.Background(), req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{"fields in selection order", Request{Query: `{ person(id: "ann") { name id tags } }`},
+			`{"data":{"person":{"name":"Ann","id":"ann","tags":["a","b"]}}}`},
+		{"aliases and nesting", Request{Query: `{ a: person(id: "ann") { friends { n: name } } b: person(id: "bob") { age } }`},
+			`{"data":{"a":{"friends":[{"n":"Bob"}]},"b":{"age":40}}}`},
+		{"variables", Request{Query: `query Q($id: String = "bob") { person(id: $id) { name } }`, Variables: map[string]interface{}{"id": "ann"}},
+			`{"data":{"person":{"name":"Ann"}}}`},
+		{"variable defaults", Request{Query: `query Q($id: String = "bob") { person(id: $id) { name } }`},
+			`{"data":{"person":{"name":"Bob"}}}`},
+		{"fragments and directives", Request{
+			Query: `query Q($more: Boolean!) { person(id: "ann") { ...P age @include(if: $more) ... on Person { id @skip(if: true) } } }
+				fragment P on Person { name __typename }`,
+			Variables: map[string]interface{}{"more": false}},
+			`{"data":{"person":{"name":"Ann","__typename":"Person"}}}`},
+		{"operation name", Request{Query: `query A { person(id: "ann") { id } } query B { person(id: "bob") { id } }`, OperationName: "B"},
+			`{"data":{"person":{"id":"bob"}}}`},
+		{"null object", Request{Query: `{ person(id: "nobody") { id } }`},
+			`{"data":{"person":null}}`},
+		{"field error", Request{Query: `{ person(id: "err") { id } }`},
+			`{"data":{"person":null},"errors":[{"message":"lookup failed","path":["person"]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(t, tt.req); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejects(t *testing.T) {
+	deep := `{ person(id: "ann") ` + strings.Repeat("{ friends ", MaxDepth) + "{ id }" + strings.Repeat(" }", MaxDepth) + " }"
+	var aliases strings.Builder
+	for i := 0; i <= MaxAliases; i++ {
+		fmt.Fprintf(&aliases, `p%d: person(id: "ann") { id } `, i)
+	}
+	// Each fragment spreads the next twice: 2^20 selections once expanded
+	var bomb strings.Builder
+	bomb.WriteString(`{ person(id: "ann") { ...F0 } } `)
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&bomb, "fragment F%d on Person { ...F%d ...F%d } ", i, i+1, i+1)
+	}
+	bomb.WriteString("fragment F20 on Person { id }")
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"syntax", `{ person(id: "ann") { id }`, "unterminated selection set"},
+		{"unknown field", `{ person(id: "ann") { email } }`, `cannot query field \"email\"`},
+		{"unknown argument", `{ person(name: "ann") { id } }`, `unknown argument \"name\"`},
+		{"scalar with fields", `{ person(id: "ann") { name { x } } }`, "is a scalar"},
+		{"object without fields", `{ person(id: "ann") }`, "needs a selection"},
+		{"mutation", `mutation { person(id: "ann") { id } }`, "mutations are not supported"},
+		{"missing variable", `query Q($id: String!) { person(id: $id) { id } }`, "$id is required"},
+		{"too deep", deep, "nests deeper"},
+		{"too many aliases", "{ " + aliases.String() + "}", "more than 50 aliases"},
+		{"too complex", `{ person(id: "ann") { friends { friends { friends { friends { id name age } } } } } }`, "too complex (31112"},
+		{"fragment bomb", bomb.String(), "selects more than 500 fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := run(t, Request{Query: tt.query})
+			if !strings.HasPrefix(got, `{"errors":`) || !strings.Contains(got, tt.want) {
+				t.Errorf("expected an error containing %q, got %s", tt.want, got)
+			}
+		})
+	}
+}
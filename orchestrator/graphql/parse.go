@@ -0,0 +1,633 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// lex splits a GraphQL document into tokens. Commas, whitespace and
+// comments are insignificant and dropped.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case strings.HasPrefix(src[i:], "..."):
+			toks = append(toks, token{tokPunct, "...", i})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			toks = append(toks, token{tokPunct, string(c), i})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			toks = append(toks, token{tokName, src[start:i], start})
+		case c == '-' || isDigit(c):
+			start := i
+			kind := tokInt
+			if c == '-' {
+				i++
+			}
+			digits := i
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			if i == digits {
+				return nil, syntaxError(start, "expected a digit after '-'")
+			}
+			if i < len(src) && src[i] == '.' {
+				kind = tokFloat
+				i++
+				for i < len(src) && isDigit(src[i]) {
+					i++
+				}
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				kind = tokFloat
+				i++
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				for i < len(src) && isDigit(src[i]) {
+					i++
+				}
+			}
+			toks = append(toks, token{kind, src[start:i], start})
+		case strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(src[i+3:], `"""`)
+			if end < 0 {
+				return nil, syntaxError(i, "unterminated block string")
+			}
+			toks = append(toks, token{tokString, blockString(src[i+3 : i+3+end]), i})
+			i += 3 + end + 3
+		case c == '"':
+			s, n, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokString, s, i})
+			i += n
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, syntaxError(i, fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// lexString reads the quoted string at src[start], returning its value and
+// length in src.
+func lexString(src string, start int) (string, int, error) {
+	var b strings.Builder
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '"':
+			return b.String(), i + 1 - start, nil
+		case c == '\n' || c == '\r':
+			return "", 0, syntaxError(i, "unterminated string")
+		case c == '\\':
+			if i+1 >= len(src) {
+				return "", 0, syntaxError(i, "unterminated string")
+			}
+			i++
+			switch src[i] {
+			case '"', '\\', '/':
+				b.WriteByte(src[i])
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+5 > len(src) {
+					return "", 0, syntaxError(i, "bad unicode escape")
+				}
+				n, err := strconv.ParseUint(src[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", 0, syntaxError(i, "bad unicode escape")
+				}
+				b.WriteRune(rune(n))
+				i += 4
+			default:
+				return "", 0, syntaxError(i, fmt.Sprintf("bad escape \\%c", src[i]))
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, syntaxError(start, "unterminated string")
+}
+
+// blockString strips the common indentation and blank first and last
+// lines of a """block string""".
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+	indent := -1
+	for _, l := range lines[1:] {
+		trimmed := strings.TrimLeft(l, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(l) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// --- AST ---
+
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind string // query, mutation or subscription
+	name string
+	vars []varDef
+	sel  []selection
+}
+
+type varDef struct {
+	name    string
+	nonNull bool
+	def     *value
+}
+
+type fragment struct {
+	name string
+	on   string
+	sel  []selection
+}
+
+// selection is a field, a fragment spread or an inline fragment.
+type selection struct {
+	field      *field
+	spread     string
+	inline     bool
+	on         string // inline fragment type condition, if any
+	sel        []selection
+	directives []directive
+}
+
+type field struct {
+	alias string
+	name  string
+	args  []argument
+	sel   []selection
+	pos   int
+}
+
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type argument struct {
+	name string
+	val  value
+}
+
+type directive struct {
+	name string
+	args []argument
+}
+
+type valueKind int
+
+const (
+	valVariable valueKind = iota
+	valInt
+	valFloat
+	valString
+	valBool
+	valNull
+	valEnum
+	valList
+	valObject
+)
+
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []value
+	fields []argument
+}
+
+// --- Parser ---
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func parse(src string) (*document, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	doc := &document{fragments: map[string]*fragment{}}
+	for p.peek().kind != tokEOF {
+		t := p.peek()
+		switch {
+		case t.kind == tokPunct && t.value == "{":
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", sel: sel})
+		case t.kind == tokName && (t.value == "query" || t.value == "mutation" || t.value == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case t.kind == tokName && t.value == "fragment":
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.fragments[f.name]; dup {
+				return nil, syntaxError(t.pos, fmt.Sprintf("fragment %q is defined twice", f.name))
+			}
+			doc.fragments[f.name] = f
+		default:
+			return nil, syntaxError(t.pos, fmt.Sprintf("unexpected %q", t.value))
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document has no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) isPunct(v string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.value == v
+}
+
+func (p *parser) expect(v string) error {
+	t := p.next()
+	if t.kind != tokPunct || t.value != v {
+		return syntaxError(t.pos, fmt.Sprintf("expected %q, found %q", v, describe(t)))
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokName {
+		return "", syntaxError(t.pos, fmt.Sprintf("expected a name, found %q", describe(t)))
+	}
+	return t.value, nil
+}
+
+func describe(t token) string {
+	if t.kind == tokEOF {
+		return "end of document"
+	}
+	return t.value
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.next().value}
+	if p.peek().kind == tokName {
+		op.name = p.next().value
+	}
+	if p.isPunct("(") {
+		p.next()
+		for !p.isPunct(")") {
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			nonNull, err := p.typeRef()
+			if err != nil {
+				return nil, err
+			}
+			v := varDef{name: name, nonNull: nonNull}
+			if p.isPunct("=") {
+				p.next()
+				def, err := p.value(true)
+				if err != nil {
+					return nil, err
+				}
+				v.def = &def
+			}
+			op.vars = append(op.vars, v)
+		}
+		p.next()
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.sel = sel
+	return op, nil
+}
+
+// typeRef skips a variable's type, reporting whether it is non-null.
+func (p *parser) typeRef() (bool, error) {
+	if p.isPunct("[") {
+		p.next()
+		if _, err := p.typeRef(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.name(); err != nil {
+		return false, err
+	}
+	if p.isPunct("!") {
+		p.next()
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	p.next()
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if on, err := p.name(); err != nil || on != "on" {
+		return nil, syntaxError(p.peek().pos, "expected 'on' after the fragment name")
+	}
+	typ, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, on: typ, sel: sel}, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []selection
+	for !p.isPunct("}") {
+		if p.peek().kind == tokEOF {
+			return nil, syntaxError(p.peek().pos, "unterminated selection set")
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, s)
+	}
+	p.next()
+	if len(sels) == 0 {
+		return nil, syntaxError(p.toks[p.i-1].pos, "empty selection set")
+	}
+	return sels, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	if p.isPunct("...") {
+		p.next()
+		var s selection
+		if t := p.peek(); t.kind == tokName && t.value != "on" {
+			s.spread = p.next().value
+		} else {
+			s.inline = true
+			if t.kind == tokName {
+				p.next()
+				on, err := p.name()
+				if err != nil {
+					return s, err
+				}
+				s.on = on
+			}
+		}
+		dirs, err := p.directives()
+		if err != nil {
+			return s, err
+		}
+		s.directives = dirs
+		if s.inline {
+			if s.sel, err = p.selectionSet(); err != nil {
+				return s, err
+			}
+		}
+		return s, nil
+	}
+
+	f := &field{pos: p.peek().pos}
+	name, err := p.name()
+	if err != nil {
+		return selection{}, err
+	}
+	if p.isPunct(":") {
+		p.next()
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return selection{}, err
+		}
+	}
+	f.name = name
+	if f.args, err = p.arguments(false); err != nil {
+		return selection{}, err
+	}
+	dirs, err := p.directives()
+	if err != nil {
+		return selection{}, err
+	}
+	if p.isPunct("{") {
+		if f.sel, err = p.selectionSet(); err != nil {
+			return selection{}, err
+		}
+	}
+	return selection{field: f, directives: dirs}, nil
+}
+
+func (p *parser) arguments(constant bool) ([]argument, error) {
+	if !p.isPunct("(") {
+		return nil, nil
+	}
+	p.next()
+	var args []argument
+	for !p.isPunct(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, argument{name: name, val: v})
+	}
+	p.next()
+	return args, nil
+}
+
+func (p *parser) directives() ([]directive, error) {
+	var dirs []directive
+	for p.isPunct("@") {
+		p.next()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, directive{name: name, args: args})
+	}
+	return dirs, nil
+}
+
+func (p *parser) value(constant bool) (value, error) {
+	t := p.next()
+	switch t.kind {
+	case tokInt:
+		return value{kind: valInt, raw: t.value}, nil
+	case tokFloat:
+		return value{kind: valFloat, raw: t.value}, nil
+	case tokString:
+		return value{kind: valString, raw: t.value}, nil
+	case tokName:
+		switch t.value {
+		case "true", "false":
+			return value{kind: valBool, raw: t.value}, nil
+		case "null":
+			return value{kind: valNull}, nil
+		}
+		return value{kind: valEnum, raw: t.value}, nil
+	case tokPunct:
+		switch t.value {
+		case "$":
+			if constant {
+				return value{}, syntaxError(t.pos, "variables are not allowed here")
+			}
+			name, err := p.name()
+			return value{kind: valVariable, raw: name}, err
+		case "[":
+			v := value{kind: valList}
+			for !p.isPunct("]") {
+				if p.peek().kind == tokEOF {
+					return value{}, syntaxError(t.pos, "unterminated list")
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return value{}, err
+				}
+				v.list = append(v.list, item)
+			}
+			p.next()
+			return v, nil
+		case "{":
+			v := value{kind: valObject}
+			for !p.isPunct("}") {
+				name, err := p.name()
+				if err != nil {
+					return value{}, err
+				}
+				if err := p.expect(":"); err != nil {
+					return value{}, err
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return value{}, err
+				}
+				v.fields = append(v.fields, argument{name: name, val: item})
+			}
+			p.next()
+			return v, nil
+		}
+	}
+	return value{}, syntaxError(t.pos, fmt.Sprintf("expected a value, found %q", describe(t)))
+}
+
+func syntaxError(pos int, msg string) error {
+	return fmt.Errorf("syntax error at offset %d: %s", pos, msg)
+}
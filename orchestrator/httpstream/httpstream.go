@@ -0,0 +1,16 @@
+// Package httpstream holds helpers shared by the api and openclaw handlers
+// that hold a response open (SSE, chunked log tails, chat streams).
+package httpstream
+
+import (
+	"net/http"
+	"time"
+)
+
+// Begin marks w as a long-lived stream by lifting the server's
+// WriteTimeout for this response, which would otherwise cut the stream
+// off mid-flight. Writers that cannot set a deadline are left as they
+// are.
+func Begin(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
@@ -0,0 +1,127 @@
+// Package identity names the people behind mutations. The API maps bearer
+// tokens to users and passes the user to the mc commands it runs; mc
+// records it on audit entries and gate approvals. Roles configured per
+// project decide what each user may do through the API.
+//
+// Per-user tokens are configured in MC_API_TOKENS as comma-separated
+// user=token pairs:
+//
+//	MC_API_TOKENS="Alice <alice@example.com>=tok1,bob=tok2"
+//
+// The single shared MC_API_TOKEN still works and identifies no one in
+// particular.
+package identity
+
+import (
+	"crypto/subtle"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Environment variables
+const (
+	EnvUser   = "MC_USER"       // who mc acts for; set by the API on the commands it runs
+	EnvToken  = "MC_API_TOKEN"  // shared API token
+	EnvTokens = "MC_API_TOKENS" // per-user API tokens
+)
+
+// User is the person behind a request or command. Either field may be
+// empty.
+type User struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// Parse reads a user written as "Name <email>", a bare email or a bare
+// name.
+func Parse(s string) User {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "<"); i >= 0 && strings.HasSuffix(s, ">") {
+		return User{Name: strings.TrimSpace(s[:i]), Email: strings.TrimSpace(s[i+1 : len(s)-1])}
+	}
+	if strings.Contains(s, "@") && !strings.ContainsAny(s, " \t") {
+		return User{Email: s}
+	}
+	return User{Name: s}
+}
+
+// IsZero reports whether u names no one.
+func (u User) IsZero() bool {
+	return u.Name == "" && u.Email == ""
+}
+
+// String renders u as Parse reads it.
+func (u User) String() string {
+	switch {
+	case u.Name != "" && u.Email != "":
+		return u.Name + " <" + u.Email + ">"
+	case u.Email != "":
+		return u.Email
+	default:
+		return u.Name
+	}
+}
+
+// Token is one configured API token. Shared is set for MC_API_TOKEN, which
+// belongs to no particular user.
+type Token struct {
+	Value  string
+	User   User
+	Shared bool
+}
+
+// Tokens returns the API tokens configured in the environment. None means
+// auth is disabled.
+func Tokens() []Token {
+	var tokens []Token
+	if t := os.Getenv(EnvToken); t != "" {
+		tokens = append(tokens, Token{Value: t, Shared: true})
+	}
+	for _, pair := range strings.Split(os.Getenv(EnvTokens), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		user, value := Parse(name), strings.TrimSpace(value)
+		if value == "" || user.IsZero() {
+			continue
+		}
+		tokens = append(tokens, Token{Value: value, User: user})
+	}
+	return tokens
+}
+
+// Lookup returns the configured token matching value.
+func Lookup(tokens []Token, value string) (Token, bool) {
+	if value == "" {
+		return Token{}, false
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Value), []byte(value)) == 1 {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+// Current returns who a local mc command acts for: MC_USER when set, else
+// the git user.name and user.email, else the login name.
+func Current() User {
+	if s := os.Getenv(EnvUser); s != "" {
+		return Parse(s)
+	}
+	u := User{Name: gitConfig("user.name"), Email: gitConfig("user.email")}
+	if u.IsZero() {
+		u.Name = os.Getenv("USER")
+	}
+	return u
+}
+
+func gitConfig(key string) string {
+	out, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,90 @@
+package identity
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := map[string]User{
+		"Alice <alice@example.com>": {Name: "Alice", Email: "alice@example.com"},
+		"alice@example.com":         {Email: "alice@example.com"},
+		"Alice Smith":               {Name: "Alice Smith"},
+		"":                          {},
+	}
+	for in, want := range cases {
+		got := Parse(in)
+		if got != want {
+			t.Errorf("Parse(%q) = %+v, want %+v", in, got, want)
+		}
+		if got.String() != in {
+			t.Errorf("Parse(%q).String() = %q", in, got.String())
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	t.Setenv(EnvToken, "shared")
+	t.Setenv(EnvTokens, "Alice <alice@example.com>=a=1, bob=b2,broken,carol=")
+
+	tokens := Tokens()
+	if len(tokens) != 3 {
+		t.Fatalf("tokens = %+v, want 3", tokens)
+	}
+	if tok, ok := Lookup(tokens, "shared"); !ok || !tok.Shared {
+		t.Errorf("shared token = %+v, %v", tok, ok)
+	}
+	if tok, ok := Lookup(tokens, "a=1"); !ok || tok.User.Email != "alice@example.com" {
+		t.Errorf("alice's token = %+v, %v", tok, ok)
+	}
+	if tok, ok := Lookup(tokens, "b2"); !ok || tok.User.Name != "bob" || tok.Shared {
+		t.Errorf("bob's token = %+v, %v", tok, ok)
+	}
+	if _, ok := Lookup(tokens, ""); ok {
+		t.Error("empty token matched")
+	}
+}
+
+func TestCurrentPrefersEnv(t *testing.T) {
+	t.Setenv(EnvUser, "Alice <alice@example.com>")
+	if got := Current(); got.Email != "alice@example.com" {
+		t.Errorf("Current() = %+v", got)
+	}
+}
+
+func TestRolesOf(t *testing.T) {
+	roles := Roles{"ALICE@example.com": RoleAdmin, "Bob": RoleApprover, "*": RoleContributor}
+	cases := []struct {
+		user User
+		want string
+	}{
+		{User{Name: "Alice", Email: "alice@example.com"}, RoleAdmin},
+		{User{Name: "Bob"}, RoleApprover},
+		{User{Name: "carol"}, RoleContributor},
+		{User{}, RoleContributor},
+	}
+	for _, c := range cases {
+		if got := roles.Of(c.user); got != c.want {
+			t.Errorf("Of(%+v) = %q, want %q", c.user, got, c.want)
+		}
+	}
+
+	// The most specific key wins, whatever the map order
+	both := Roles{"Alice": RoleViewer, "alice@example.com": RoleApprover, "Alice <alice@example.com>": RoleAdmin}
+	for i := 0; i < 20; i++ {
+		if got := both.Of(User{Name: "Alice", Email: "alice@example.com"}); got != RoleAdmin {
+			t.Fatalf("Of with several matching keys = %q, want admin", got)
+		}
+	}
+	delete(both, "Alice <alice@example.com>")
+	if got := both.Of(User{Name: "Alice", Email: "alice@example.com"}); got != RoleApprover {
+		t.Errorf("email should outrank name, got %q", got)
+	}
+
+	if got := (Roles{"Bob": RoleApprover}).Of(User{Name: "carol"}); got != RoleViewer {
+		t.Errorf("unlisted user without \"*\" = %q, want viewer", got)
+	}
+	if got := (Roles{}).Of(User{}); got != RoleAdmin {
+		t.Errorf("no roles configured = %q, want admin", got)
+	}
+	if !Allows(RoleAdmin, RoleApprover) || Allows(RoleContributor, RoleApprover) || Allows("", RoleViewer) {
+		t.Error("Allows doesn't follow the role order")
+	}
+}
@@ -0,0 +1,75 @@
+package identity
+
+import (
+	"sort"
+	"strings"
+)
+
+// Roles, from least to most privileged. Each role can do everything the
+// ones before it can.
+const (
+	RoleViewer      = "viewer"      // read-only
+	RoleContributor = "contributor" // create and edit tasks, zones, workers
+	RoleApprover    = "approver"    // approve and reject gates
+	RoleAdmin       = "admin"       // switch projects, override stages, edit prompts
+)
+
+var roleRank = map[string]int{
+	RoleViewer:      1,
+	RoleContributor: 2,
+	RoleApprover:    3,
+	RoleAdmin:       4,
+}
+
+// ValidRole reports whether role is one of the four roles.
+func ValidRole(role string) bool {
+	return roleRank[role] > 0
+}
+
+// Allows reports whether role grants need.
+func Allows(role, need string) bool {
+	return roleRank[role] >= roleRank[need]
+}
+
+// Roles assigns users roles in a project, configured under "roles" in
+// .mission/config.json:
+//
+//	"roles": {"alice@example.com": "admin", "Bob": "approver", "*": "contributor"}
+//
+// Keys match a user's email (case-insensitively), name or "Name <email>";
+// "*" covers everyone else. With no roles configured everyone is an admin;
+// an unknown role grants nothing.
+type Roles map[string]string
+
+// Of returns u's role. The most specific key wins: "Name <email>", then
+// the email, then the name. Users matching no key, including the zero
+// User, get the "*" role, or viewer without one.
+func (r Roles) Of(u User) string {
+	if len(r) == 0 {
+		return RoleAdmin
+	}
+	keys := make([]string, 0, len(r))
+	for key := range r {
+		if key != "*" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if !u.IsZero() {
+		for _, match := range []func(key string) bool{
+			func(key string) bool { return key == u.String() },
+			func(key string) bool { return u.Email != "" && strings.EqualFold(key, u.Email) },
+			func(key string) bool { return u.Name != "" && key == u.Name },
+		} {
+			for _, key := range keys {
+				if match(key) {
+					return r[key]
+				}
+			}
+		}
+	}
+	if role, ok := r["*"]; ok {
+		return role
+	}
+	return RoleViewer
+}
@@ -0,0 +1,298 @@
+// Package jsonl reads and writes the JSON Lines files under .mission
+// safely across goroutines and processes.
+//
+// Writers take an exclusive lock on the file itself (flock on Unix) before
+// appending or rewriting it, so lines from concurrent writers never
+// interleave and an append never lands in a file that is being replaced.
+// A writer that waited on a file which was renamed or replaced meanwhile,
+// e.g. by audit rotation or a tasks.jsonl rewrite, opens the new file and
+// locks that instead. Appends start on a fresh line even when the file
+// ends in a torn write, so one crash costs one line and not two.
+//
+// Readers don't lock. Read hands every well-formed line to its callback
+// and reports the others as Skipped with their line numbers, and Repair
+// rewrites a file without them.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Options tune a write.
+type Options struct {
+	// Sync flushes the file to disk before the write returns, and for
+	// rewrites the directory entry too.
+	Sync bool
+}
+
+// Skipped is a line Read couldn't use.
+type Skipped struct {
+	Line int    `json:"line"` // 1-based
+	Err  string `json:"error"`
+	Text string `json:"text"`
+}
+
+// Append marshals each value onto its own line at the end of path,
+// creating the file if needed. All values are written at once.
+func Append(path string, opts Options, values ...interface{}) error {
+	lines := make([][]byte, 0, len(values))
+	for _, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, data)
+	}
+	return AppendLines(path, opts, lines...)
+}
+
+// AppendLines appends already-encoded lines to path. A line must not
+// contain a newline.
+func AppendLines(path string, opts Options, lines ...[]byte) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if bytes.IndexByte(line, '\n') >= 0 {
+			return fmt.Errorf("jsonl: line contains a newline")
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	f, err := openLocked(path, os.O_RDWR|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer closeLocked(f)
+
+	// Start on a fresh line if the last write was torn
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if opts.Sync {
+		return f.Sync()
+	}
+	return nil
+}
+
+// Rewrite replaces path with lines, atomically and under the same lock
+// appends take, so no append is lost to the rename.
+func Rewrite(path string, opts Options, lines [][]byte) error {
+	f, err := openLocked(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer closeLocked(f)
+	return replace(path, opts, lines)
+}
+
+// Update rewrites path with what fn makes of its current lines, holding
+// the lock from the read to the rename so no writer gets in between.
+// fn gets every line as it is, malformed and blank ones included.
+func Update(path string, opts Options, fn func(lines [][]byte) ([][]byte, error)) error {
+	f, err := openLocked(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer closeLocked(f)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var lines [][]byte
+	if len(data) > 0 {
+		lines = bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	}
+	out, err := fn(lines)
+	if err != nil {
+		return err
+	}
+	return replace(path, opts, out)
+}
+
+// replace writes lines to a temporary file beside path and renames it
+// over path. The caller holds the lock.
+func replace(path string, opts Options, lines [][]byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, line := range lines {
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if opts.Sync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	if opts.Sync {
+		if d, err := os.Open(filepath.Dir(path)); err == nil {
+			d.Sync()
+			d.Close()
+		}
+	}
+	return nil
+}
+
+// Read calls fn with each well-formed JSON line of path, in order, and
+// returns the lines it skipped. Blank lines are ignored. A missing file
+// has no lines. An error from fn skips that line too.
+func Read(path string, fn func(line []byte) error) ([]Skipped, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var skipped []Skipped
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		raw, err := r.ReadBytes('\n')
+		if len(raw) > 0 {
+			line := bytes.TrimSpace(raw)
+			switch {
+			case len(line) == 0:
+			case !json.Valid(line):
+				skipped = append(skipped, Skipped{Line: n, Err: "invalid JSON", Text: string(line)})
+			default:
+				if ferr := fn(line); ferr != nil {
+					skipped = append(skipped, Skipped{Line: n, Err: ferr.Error(), Text: string(line)})
+				}
+			}
+		}
+		if err == io.EOF {
+			return skipped, nil
+		}
+		if err != nil {
+			return skipped, err
+		}
+	}
+}
+
+// Report is what Repair found in a file.
+type Report struct {
+	Path    string    `json:"path"`
+	Lines   int       `json:"lines"` // well-formed lines kept
+	Skipped []Skipped `json:"skipped,omitempty"`
+	Backup  string    `json:"backup,omitempty"` // where the skipped lines went
+}
+
+// Check reports the malformed lines in path without changing it. A
+// missing file is clean.
+func Check(path string) (Report, error) {
+	rep := Report{Path: path}
+	skipped, err := Read(path, func([]byte) error { rep.Lines++; return nil })
+	rep.Skipped = skipped
+	return rep, err
+}
+
+// Repair rewrites path without its malformed lines. The lines dropped are
+// appended to path.corrupt, with the time and their line numbers, so
+// nothing is lost for good. A file with nothing to drop is left alone.
+func Repair(path string, opts Options, now time.Time) (Report, error) {
+	if rep, err := Check(path); err != nil || len(rep.Skipped) == 0 {
+		return rep, err
+	}
+	rep := Report{Path: path}
+	err := Update(path, opts, func(lines [][]byte) ([][]byte, error) {
+		rep.Lines, rep.Skipped = 0, nil
+		kept := lines[:0:0]
+		for i, line := range lines {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			if !json.Valid(line) {
+				rep.Skipped = append(rep.Skipped, Skipped{Line: i + 1, Err: "invalid JSON", Text: string(line)})
+				continue
+			}
+			kept = append(kept, line)
+		}
+		rep.Lines = len(kept)
+		if len(rep.Skipped) == 0 {
+			return lines, nil
+		}
+
+		rep.Backup = path + ".corrupt"
+		var dropped [][]byte
+		for _, s := range rep.Skipped {
+			data, _ := json.Marshal(struct {
+				RepairedAt string `json:"repaired_at"`
+				Skipped
+			}{now.UTC().Format(time.RFC3339), s})
+			dropped = append(dropped, data)
+		}
+		if err := AppendLines(rep.Backup, opts, dropped...); err != nil {
+			return nil, err
+		}
+		return kept, nil
+	})
+	return rep, err
+}
+
+// openLocked opens path with flag and takes the exclusive lock on it. If
+// the file was replaced while waiting, the lock is on a file nobody will
+// read again, so it starts over with the file now at path.
+func openLocked(path string, flag int) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(path, flag, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := lock(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		held, err := f.Stat()
+		if err != nil {
+			closeLocked(f)
+			return nil, err
+		}
+		if now, err := os.Stat(path); err == nil && os.SameFile(held, now) {
+			return f, nil
+		}
+		closeLocked(f)
+	}
+}
+
+func closeLocked(f *os.File) error {
+	unlock(f)
+	return f.Close()
+}
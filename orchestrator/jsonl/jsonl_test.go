@@ -0,0 +1,177 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, path string) ([]map[string]interface{}, []Skipped) {
+	t.Helper()
+	var out []map[string]interface{}
+	skipped, err := Read(path, func(line []byte) error {
+		var m map[string]interface{}
+		if err := json.Unmarshal(line, &m); err != nil {
+			return err
+		}
+		out = append(out, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out, skipped
+}
+
+func TestConcurrentAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	pad := strings.Repeat("x", 8192) // well past one pipe-sized write
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				if err := Append(path, Options{}, map[string]interface{}{"w": w, "i": i, "pad": pad}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	lines, skipped := readAll(t, path)
+	if len(skipped) != 0 {
+		t.Fatalf("expected no torn lines, got %d", len(skipped))
+	}
+	if len(lines) != 200 {
+		t.Fatalf("expected 200 lines, got %d", len(lines))
+	}
+}
+
+func TestAppendAfterTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	os.WriteFile(path, []byte("{\"a\":1}\n{\"a\":"), 0644)
+
+	if err := Append(path, Options{Sync: true}, map[string]int{"a": 2}); err != nil {
+		t.Fatal(err)
+	}
+	lines, skipped := readAll(t, path)
+	if len(lines) != 2 || lines[1]["a"] != float64(2) {
+		t.Fatalf("expected the new line intact, got %v", lines)
+	}
+	if len(skipped) != 1 || skipped[0].Line != 2 || skipped[0].Text != `{"a":` {
+		t.Fatalf("expected line 2 skipped, got %+v", skipped)
+	}
+}
+
+func TestAppendLinesRejectsNewlines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := AppendLines(path, Options{}, []byte("{}\n{}")); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected nothing written")
+	}
+}
+
+func TestReadMissingFile(t *testing.T) {
+	skipped, err := Read(filepath.Join(t.TempDir(), "none.jsonl"), func([]byte) error { return nil })
+	if err != nil || skipped != nil {
+		t.Fatalf("expected an empty read, got %v, %v", skipped, err)
+	}
+}
+
+func TestReadReportsCallbackErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	os.WriteFile(path, []byte("{\"id\":\"a\"}\n\n{}\n"), 0644)
+
+	skipped, err := Read(path, func(line []byte) error {
+		if string(line) == "{}" {
+			return fmt.Errorf("id is required")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0].Line != 3 || skipped[0].Err != "id is required" {
+		t.Fatalf("expected line 3 skipped, got %+v", skipped)
+	}
+}
+
+func TestUpdateHoldsOffAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	Append(path, Options{}, map[string]int{"n": 0})
+
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		<-started
+		done <- Append(path, Options{}, map[string]int{"n": 2})
+	}()
+	err := Update(path, Options{}, func(lines [][]byte) ([][]byte, error) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return append(lines, []byte(`{"n":1}`)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	lines, _ := readAll(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("expected the append to land after the rewrite, got %v", lines)
+	}
+	for i, l := range lines {
+		if l["n"] != float64(i) {
+			t.Fatalf("expected lines in order, got %v", lines)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	os.WriteFile(path, []byte("{\"a\":1}\n<<<<<<< HEAD\n\n{\"a\":2}\n{\"a\":"), 0644)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	rep, err := Check(path)
+	if err != nil || rep.Lines != 2 || len(rep.Skipped) != 2 {
+		t.Fatalf("unexpected check: %+v, %v", rep, err)
+	}
+
+	rep, err = Repair(path, Options{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Lines != 2 || len(rep.Skipped) != 2 || rep.Skipped[0].Line != 2 || rep.Skipped[1].Line != 5 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\"a\":1}\n{\"a\":2}\n" {
+		t.Fatalf("unexpected repaired file: %q", data)
+	}
+	backup, _ := os.ReadFile(rep.Backup)
+	if !strings.Contains(string(backup), `"line":2`) || !strings.Contains(string(backup), `"repaired_at":"2026-03-01T12:00:00Z"`) {
+		t.Fatalf("expected the dropped lines kept, got %s", backup)
+	}
+
+	// A clean file is left alone
+	before, _ := os.Stat(path)
+	rep, err = Repair(path, Options{}, now)
+	if err != nil || len(rep.Skipped) != 0 || rep.Backup != "" {
+		t.Fatalf("unexpected report: %+v, %v", rep, err)
+	}
+	if after, _ := os.Stat(path); !os.SameFile(before, after) {
+		t.Fatal("expected a clean file not to be rewritten")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !unix
+
+package jsonl
+
+import (
+	"os"
+	"sync"
+)
+
+// Without flock, writers are only serialised within the process, by path.
+var (
+	locksMu sync.Mutex
+	locks   = map[string]*sync.Mutex{}
+)
+
+func pathLock(f *os.File) *sync.Mutex {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+	m, ok := locks[f.Name()]
+	if !ok {
+		m = &sync.Mutex{}
+		locks[f.Name()] = m
+	}
+	return m
+}
+
+func lock(f *os.File) error {
+	pathLock(f).Lock()
+	return nil
+}
+
+func unlock(f *os.File) {
+	pathLock(f).Unlock()
+}
@@ -0,0 +1,40 @@
+// Package logonce logs each distinct message once, for monitors that
+// re-read their config on every tick and would otherwise repeat the same
+// warning about a bad entry each time.
+package logonce
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Logger logs a message the first time it is seen.
+type Logger struct {
+	prefix string
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// New returns a Logger whose lines start with prefix, such as
+// "stagesla: ".
+func New(prefix string) *Logger {
+	return &Logger{prefix: prefix, seen: map[string]bool{}}
+}
+
+// Printf logs the formatted message unless l has already logged it. A nil
+// Logger logs nothing.
+func (l *Logger) Printf(format string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seen[msg] {
+		return
+	}
+	l.seen[msg] = true
+	log.Print(l.prefix + msg)
+}
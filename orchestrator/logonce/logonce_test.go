@@ -0,0 +1,30 @@
+package logonce
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestPrintfLogsEachMessageOnce(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	l := New("test: ")
+	for i := 0; i < 3; i++ {
+		l.Printf("bad %q", "a")
+		l.Printf("bad %q", "b")
+	}
+	var none *Logger
+	none.Printf("ignored")
+
+	if got, want := logs.String(), "test: bad \"a\"\ntest: bad \"b\"\n"; got != want {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
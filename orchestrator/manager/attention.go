@@ -0,0 +1,157 @@
+package manager
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Attention kinds: what an agent is waiting on a human for.
+const (
+	AttentionQuestion     = "question"      // a question in its output
+	AttentionPermission   = "permission"    // asks to use a tool
+	AttentionPlanApproval = "plan_approval" // presents a plan to approve
+)
+
+// attentionEvents are the event types emitted for each kind.
+var attentionEvents = map[string]string{
+	AttentionQuestion:     "agent_question",
+	AttentionPermission:   "agent_permission_request",
+	AttentionPlanApproval: "agent_plan_approval",
+}
+
+// Attention is a request for a human that an agent's output contains.
+type Attention struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Tool    string `json:"tool,omitempty"`    // permission: the tool asked for
+	Command string `json:"command,omitempty"` // permission: its command, if shown
+	Since   int64  `json:"since"`             // unix ms
+}
+
+var (
+	// Claude Code's tool result when a tool was not allowed in print mode
+	deniedPattern = regexp.MustCompile(`requested permissions to (?:use|write to|read from) (\w+)`)
+	// Interactive prompts: "Allow Bash command?", "Do you want to allow Edit?"
+	allowPattern = regexp.MustCompile(`(?i)\b(?:allow|approve|permit)\s+(?:the\s+)?(\w+)(?:\s+(?:command|tool|call|edit))?(?:\s*[:(]\s*(.+?)\)?)?\s*\?`)
+	planPattern  = regexp.MustCompile(`(?i)\b(?:approve|proceed with|accept)\s+(?:this|the)\s+plan\b|\bplan\b.*\b(?:ready to code|would you like to proceed)\?`)
+)
+
+// classifyOutput finds the attention request in one output event, if it
+// has one: a Claude Code stream-json event or a {"text": ...} line.
+func classifyOutput(params json.RawMessage) *Attention {
+	var ev struct {
+		Type    string `json:"type"`
+		Text    string `json:"text"`
+		Result  string `json:"result"`
+		Message struct {
+			Content []struct {
+				Type    string          `json:"type"`
+				Text    string          `json:"text"`
+				Name    string          `json:"name"`
+				Input   json.RawMessage `json:"input"`
+				Content json.RawMessage `json:"content"`
+				IsError bool            `json:"is_error"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if json.Unmarshal(params, &ev) != nil {
+		return nil
+	}
+	switch ev.Type {
+	case "assistant":
+		for _, c := range ev.Message.Content {
+			if c.Type == "tool_use" && c.Name == "ExitPlanMode" {
+				var in struct {
+					Plan string `json:"plan"`
+				}
+				json.Unmarshal(c.Input, &in)
+				return newAttention(AttentionPlanApproval, firstLine(in.Plan, "Plan ready for approval"), "", "")
+			}
+		}
+		return nil
+	case "user":
+		for _, c := range ev.Message.Content {
+			if c.Type != "tool_result" || !c.IsError {
+				continue
+			}
+			var text string
+			if json.Unmarshal(c.Content, &text) != nil {
+				text = string(c.Content)
+			}
+			if m := deniedPattern.FindStringSubmatch(text); m != nil {
+				return newAttention(AttentionPermission, text, m[1], "")
+			}
+		}
+		return nil
+	case "result":
+		return classifyText(ev.Result)
+	case "":
+		return classifyText(ev.Text)
+	}
+	return nil
+}
+
+// classifyText tells a permission prompt or plan approval from a plain
+// question in a line of text. Text that isn't asking anything is nil.
+func classifyText(text string) *Attention {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if planPattern.MatchString(text) {
+		return newAttention(AttentionPlanApproval, text, "", "")
+	}
+	if m := allowPattern.FindStringSubmatch(text); m != nil && isToolName(m[1]) {
+		return newAttention(AttentionPermission, text, m[1], strings.TrimSpace(m[2]))
+	}
+	if m := deniedPattern.FindStringSubmatch(text); m != nil {
+		return newAttention(AttentionPermission, text, m[1], "")
+	}
+	if strings.HasSuffix(text, "?") {
+		return newAttention(AttentionQuestion, text, "", "")
+	}
+	return nil
+}
+
+// isToolName reports whether word names a tool: capitalized, as Claude
+// Code's tools (Bash, Edit, WebFetch) and MCP tools (mcp__...) are.
+func isToolName(word string) bool {
+	return strings.HasPrefix(word, "mcp__") || (word != "" && word[0] >= 'A' && word[0] <= 'Z' && word != "This" && word != "The")
+}
+
+func newAttention(kind, message, tool, command string) *Attention {
+	return &Attention{Type: kind, Message: message, Tool: tool, Command: command, Since: time.Now().UnixMilli()}
+}
+
+func firstLine(s, def string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def
+	}
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimLeft(line, "# ")
+}
+
+// checkAttention emits the attention request in an agent's output event,
+// if it has one. A permission request for a tool the permissions policy
+// auto-approves is answered "y" instead, where the agent takes messages,
+// and emitted as agent_permission_approved.
+func (m *Manager) checkAttention(agent *Agent, params json.RawMessage) {
+	a := classifyOutput(params)
+	if a == nil {
+		return
+	}
+	if a.Type == AttentionPermission && m.permissions.Allows(a.Tool, a.Command) {
+		if err := sendMessage(agent, "y"); err == nil {
+			m.emitEvent("agent_permission_approved", agent.ID, map[string]interface{}{
+				"tool":    a.Tool,
+				"command": a.Command,
+				"auto":    true,
+			})
+			return
+		}
+	}
+	m.emitEvent(attentionEvents[a.Type], agent.ID, map[string]interface{}{"attention": a})
+}
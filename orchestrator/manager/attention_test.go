@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+func TestClassifyOutput(t *testing.T) {
+	tests := []struct {
+		line string
+		kind string
+		tool string
+	}{
+		{`{"text": "Allow Bash command? (y/n)"}`, AttentionPermission, "Bash"},
+		{`{"text": "Do you want to allow Edit: src/main.go?"}`, AttentionPermission, "Edit"},
+		{`{"text": "Which database should I use?"}`, AttentionQuestion, ""},
+		{`{"text": "Should I allow users to sign up?"}`, AttentionQuestion, ""},
+		{`{"text": "Do you approve this plan?"}`, AttentionPlanApproval, ""},
+		{`{"text": "Reading files"}`, "", ""},
+		{`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"ExitPlanMode","input":{"plan":"# Add login\n1. Form"}}]}}`, AttentionPlanApproval, ""},
+		{`{"type":"assistant","message":{"content":[{"type":"text","text":"Is this right?"}]}}`, "", ""},
+		{`{"type":"user","message":{"content":[{"type":"tool_result","is_error":true,"content":"Claude requested permissions to use Bash, but you haven't granted it yet."}]}}`, AttentionPermission, "Bash"},
+		{`{"type":"result","result":"Done. Want me to open a PR?"}`, AttentionQuestion, ""},
+	}
+	for _, tt := range tests {
+		a := classifyOutput(json.RawMessage(tt.line))
+		kind, tool := "", ""
+		if a != nil {
+			kind, tool = a.Type, a.Tool
+		}
+		if kind != tt.kind || tool != tt.tool {
+			t.Errorf("%s: got %q %q, want %q %q", tt.line, kind, tool, tt.kind, tt.tool)
+		}
+	}
+}
+
+type bufferCloser struct{ strings.Builder }
+
+func (*bufferCloser) Close() error { return nil }
+
+func TestPermissionRequestsAutoApproved(t *testing.T) {
+	m := NewManager(t.TempDir())
+	m.SetPermissions(&bridge.Permissions{AutoApprove: []string{"Bash"}})
+	stdin := &bufferCloser{}
+	agent := &Agent{ID: "a1", stdin: stdin}
+
+	m.checkAttention(agent, json.RawMessage(`{"text": "Allow Bash command? (y/n)"}`))
+	m.checkAttention(agent, json.RawMessage(`{"text": "Allow WebFetch tool?"}`))
+	if stdin.String() != "y\n" {
+		t.Errorf("stdin = %q, want one y", stdin.String())
+	}
+
+	var got []string
+	deadline := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case ev := <-m.Events():
+			var data struct {
+				Tool      string    `json:"tool"`
+				Attention Attention `json:"attention"`
+			}
+			json.Unmarshal(ev.Data, &data)
+			got = append(got, ev.Type+" "+data.Tool+data.Attention.Tool)
+		case <-deadline:
+			t.Fatalf("events = %v", got)
+		}
+	}
+	if got[0] != "agent_permission_approved Bash" || got[1] != "agent_permission_request WebFetch" {
+		t.Errorf("events = %v", got)
+	}
+}
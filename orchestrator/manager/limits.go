@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+// Limits an agent can hit
+const (
+	limitTimeout = "timeout"
+	limitMemory  = "memory"
+)
+
+// MemoryPollInterval is how often an agent's resident memory is checked
+// against its limit.
+var MemoryPollInterval = 5 * time.Second
+
+// KillGrace is how long a terminated agent gets between SIGTERM and
+// SIGKILL.
+var KillGrace = 5 * time.Second
+
+// enforceLimits terminates agent once it runs past its timeout or its
+// resident memory passes its limit, emitting agent_limit_exceeded. It
+// returns when the process exits. Memory is read from /proc, so the
+// memory limit only applies where procfs exists (Linux).
+func (m *Manager) enforceLimits(agent *Agent, limits bridge.ResourceLimits) {
+	var deadline <-chan time.Time
+	if d := limits.TimeoutDuration(); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var poll <-chan time.Time
+	if limits.MaxMemoryMB > 0 {
+		ticker := time.NewTicker(MemoryPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-agent.exited:
+			return
+		case <-deadline:
+			m.terminate(agent, limitTimeout, map[string]interface{}{
+				"limit":   limitTimeout,
+				"timeout": limits.Timeout,
+			})
+			return
+		case <-poll:
+			rss, ok := processRSS(agent.PID)
+			if !ok || rss <= int64(limits.MaxMemoryMB)<<20 {
+				continue
+			}
+			m.terminate(agent, limitMemory, map[string]interface{}{
+				"limit":         limitMemory,
+				"max_memory_mb": limits.MaxMemoryMB,
+				"memory_mb":     rss >> 20,
+			})
+			return
+		}
+	}
+}
+
+// terminate records the limit agent hit, announces it and stops the
+// process: SIGTERM, then SIGKILL if it is still running after KillGrace.
+func (m *Manager) terminate(agent *Agent, limit string, details map[string]interface{}) {
+	m.mu.Lock()
+	agent.limitHit = limit
+	m.mu.Unlock()
+	m.emitEvent("agent_limit_exceeded", agent.ID, details)
+	fmt.Printf("Agent %s (%s) hit its %s limit, terminating\n", agent.Name, agent.ID, limit)
+
+	proc := agent.cmd.Process
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		_ = proc.Kill()
+		return
+	}
+	select {
+	case <-agent.exited:
+	case <-time.After(KillGrace):
+		_ = proc.Kill()
+	}
+}
+
+// processRSS reads a process's resident memory in bytes from
+// /proc/<pid>/status. ok is false where procfs is unavailable.
+func processRSS(pid int) (int64, bool) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+		fields := strings.Fields(line) // "VmRSS:", "12345", "kB"
+		if len(fields) < 2 {
+			return 0, false
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb << 10, true
+	}
+	return 0, false
+}
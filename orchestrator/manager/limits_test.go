@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+func TestTimeoutTerminatesAgent(t *testing.T) {
+	RegisterRuntime("test-hang", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		return exec.Command("sleep", "30"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetResources(&bridge.ResourceConfig{
+		Default:  bridge.ResourceLimits{Timeout: "1h"},
+		Personas: map[string]bridge.ResourceLimits{"tester": {Timeout: "100ms"}},
+	})
+	agent, err := m.Spawn(SpawnRequest{Type: "test-hang", Task: "hang", Persona: "tester"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if agent.Limits == nil || agent.Limits.Timeout != "100ms" {
+		t.Fatalf("agent limits = %+v, want the tester timeout", agent.Limits)
+	}
+
+	var exceeded bool
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case ev := <-m.Events():
+			if ev.AgentID != agent.ID {
+				continue
+			}
+			if ev.Type == "agent_limit_exceeded" {
+				exceeded = true
+			}
+			if ev.Type == "agent_stopped" {
+				var data struct{ Status AgentStatus }
+				json.Unmarshal(ev.Data, &data)
+				if !exceeded {
+					t.Error("agent stopped without agent_limit_exceeded")
+				}
+				if data.Status != StatusTimedOut {
+					t.Errorf("status = %s, want %s", data.Status, StatusTimedOut)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("agent was not terminated at its timeout")
+		}
+	}
+}
+
+func TestProcessRSS(t *testing.T) {
+	if _, err := os.Stat("/proc/self/status"); err != nil {
+		t.Skip("no procfs")
+	}
+	rss, ok := processRSS(os.Getpid())
+	if !ok || rss <= 0 {
+		t.Errorf("processRSS(self) = %d, %v", rss, ok)
+	}
+	if _, ok := processRSS(-1); ok {
+		t.Error("processRSS of a missing process succeeded")
+	}
+}
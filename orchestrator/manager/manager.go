@@ -2,7 +2,9 @@ package manager
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,7 +12,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/briefing"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
 	"github.com/MikeSquared-Agency/MissionControl/hashid"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+	"github.com/MikeSquared-Agency/MissionControl/tracker"
 	"github.com/google/uuid"
 )
 
@@ -23,40 +32,61 @@ const (
 	AgentTypeClaudeCode AgentType = "claude-code" // Default agent type
 )
 
+// Other runtimes (codex, gemini) are in runtime.go.
+
 // AgentStatus represents the current status of an agent
 type AgentStatus string
 
 const (
-	StatusStarting AgentStatus = "starting"
-	StatusWorking  AgentStatus = "working"
-	StatusIdle     AgentStatus = "idle"
-	StatusWaiting  AgentStatus = "waiting"
-	StatusError    AgentStatus = "error"
-	StatusStopped  AgentStatus = "stopped"
+	StatusStarting   AgentStatus = "starting"
+	StatusWorking    AgentStatus = "working"
+	StatusIdle       AgentStatus = "idle"
+	StatusWaiting    AgentStatus = "waiting"
+	StatusError      AgentStatus = "error"
+	StatusStopped    AgentStatus = "stopped"
+	StatusQueued     AgentStatus = "queued"     // waiting for a slot under the concurrency limits
+	StatusTimedOut   AgentStatus = "timed_out"  // killed at its resources timeout
+	StatusRestarting AgentStatus = "restarting" // waiting out its restart backoff
 )
 
 // Agent represents a running agent process
 type Agent struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Type        AgentType   `json:"type"`
-	Task        string      `json:"task"`
-	Persona     string      `json:"persona,omitempty"`
-	Zone        string      `json:"zone"`
-	WorkingDir  string      `json:"workingDir"`
-	Status      AgentStatus `json:"status"`
-	PID         int         `json:"pid"`
-	Tokens      int         `json:"tokens"`
-	Cost        float64     `json:"cost"`
-	CreatedAt   time.Time   `json:"created_at"`
-	Error       string      `json:"error,omitempty"`
-	OfflineMode bool        `json:"offlineMode"`
-	Model       string      `json:"model,omitempty"`
-
-	cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout io.ReadCloser
-	stderr io.ReadCloser
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Type        AgentType              `json:"type"`
+	Task        string                 `json:"task"`
+	Persona     string                 `json:"persona,omitempty"`
+	Zone        string                 `json:"zone"`
+	WorkingDir  string                 `json:"workingDir"`
+	Status      AgentStatus            `json:"status"`
+	PID         int                    `json:"pid"`
+	Tokens      int                    `json:"tokens"`
+	Cost        float64                `json:"cost"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Error       string                 `json:"error,omitempty"`
+	OfflineMode bool                   `json:"offlineMode"`
+	Model       string                 `json:"model,omitempty"`
+	Protocol    Protocol               `json:"protocol,omitempty"`   // stdio protocol, once known
+	Env         map[string]string      `json:"env,omitempty"`        // env profile variables, secrets masked
+	Limits      *bridge.ResourceLimits `json:"limits,omitempty"`     // timeout and memory bounds, nil = none
+	Restarts    int                    `json:"restarts,omitempty"`   // times the restart policy restarted it
+	CrashLoop   bool                   `json:"crash_loop,omitempty"` // restarted repeatedly without a stable run
+
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	stdout   io.ReadCloser
+	stderr   io.ReadCloser
+	readers  sync.WaitGroup // stdout and stderr readers; Wait after them
+	rpc      *rpcConn       // stdin of a JSON-RPC agent, nil for line-based agents
+	exited   chan struct{}  // closed once the process has been waited for
+	limitHit string         // "timeout" or "memory" once a limit killed it
+
+	runtime   Runtime       // builds the command again on restart
+	launch    Launch        // what the runtime was asked to run
+	startedAt time.Time     // when the current process started
+	attempts  int           // consecutive restarts without a stable run
+	killed    bool          // Kill was called; never restart
+	cancel    chan struct{} // closed by Kill during a restart backoff
 }
 
 // Zone represents an agent grouping
@@ -69,6 +99,7 @@ type Zone struct {
 
 // Event represents a normalized event from an agent
 type Event struct {
+	Seq     uint64          `json:"seq,omitempty"` // position in the event log, for Ack
 	Type    string          `json:"type"`
 	AgentID string          `json:"agent_id"`
 	Data    json.RawMessage `json:"data,omitempty"`
@@ -80,7 +111,23 @@ type Manager struct {
 	zones      map[string]*Zone
 	mu         sync.RWMutex
 	eventsChan chan Event
+	eventsOnce sync.Once
+	eventLog   *eventlog.Log // emitted events, read by Events
 	agentsDir  string
+	transcript *chat.Store            // King transcript, nil if not kept
+	runtimes   *bridge.RuntimeConfig  // worker CLI per persona/zone, nil = Claude Code
+	limits     *bridge.LimitsConfig   // concurrent agents, nil = unlimited
+	env        bridge.EnvProfiles     // extra worker environment per zone
+	resources  *bridge.ResourceConfig // time and memory bounds per persona
+	models     *bridge.ModelConfig    // model per persona, nil = the runtime's default
+	restart    *bridge.RestartConfig  // restart policy per agent type, nil = never
+	logs       *tracker.LogStore      // agent output, nil = not kept
+	queue      []*Agent               // queued agents, oldest first
+	handlers   map[string]RPCHandler  // requests JSON-RPC agents may make
+	kingPrompt func() (briefing.KingPrompt, error)
+
+	// Tools agents may use without asking, nil = skip permission checks
+	permissions *bridge.Permissions
 }
 
 // NewManager creates a new agent manager
@@ -88,7 +135,8 @@ func NewManager(agentsDir string) *Manager {
 	m := &Manager{
 		agents:     make(map[string]*Agent),
 		zones:      make(map[string]*Zone),
-		eventsChan: make(chan Event, 100),
+		eventsChan: make(chan Event),
+		eventLog:   eventlog.New(0),
 		agentsDir:  agentsDir,
 	}
 
@@ -103,26 +151,167 @@ func NewManager(agentsDir string) *Manager {
 	return m
 }
 
-// Events returns the channel for agent events
+// SetTranscript records messages to and from the King in store, the
+// searchable King transcript.
+func (m *Manager) SetTranscript(store *chat.Store) {
+	m.transcript = store
+}
+
+// SetKingPrompt gives a newly spawned King the prompt fn returns, as a
+// system prompt or ahead of its first message.
+func (m *Manager) SetKingPrompt(fn func() (briefing.KingPrompt, error)) {
+	m.kingPrompt = fn
+}
+
+// SetRuntimes picks the worker CLI for spawn requests that give no type.
+func (m *Manager) SetRuntimes(cfg *bridge.RuntimeConfig) {
+	m.runtimes = cfg
+}
+
+// SetEnvProfiles adds each zone's environment profile to the agents
+// spawned in it.
+func (m *Manager) SetEnvProfiles(p bridge.EnvProfiles) {
+	m.env = p
+}
+
+// SetResources bounds the agents spawned for each persona: an agent over
+// its timeout or memory limit is terminated.
+func (m *Manager) SetResources(cfg *bridge.ResourceConfig) {
+	m.resources = cfg
+}
+
+// SetModels picks the model agents run on by persona, online and
+// offline.
+func (m *Manager) SetModels(cfg *bridge.ModelConfig) {
+	m.models = cfg
+}
+
+// SetRestartPolicy sets how agents that exit are restarted, per agent
+// type.
+func (m *Manager) SetRestartPolicy(cfg *bridge.RestartConfig) {
+	m.restart = cfg
+}
+
+// SetPermissions makes Claude Code agents ask before using tools, except
+// those cfg auto-approves; without it they skip permission checks.
+// Agents that take messages get "y" for permission requests for
+// auto-approved tools.
+func (m *Manager) SetPermissions(cfg *bridge.Permissions) {
+	m.permissions = cfg
+}
+
+// SetLogs keeps each agent's stdout and stderr in store, persisted when
+// the agent exits.
+func (m *Manager) SetLogs(store *tracker.LogStore) {
+	m.logs = store
+}
+
+// HandleRPC answers method when a JSON-RPC agent requests it. Requests
+// for methods without a handler get a "method not found" error.
+func (m *Manager) HandleRPC(method string, h RPCHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]RPCHandler)
+	}
+	m.handlers[method] = h
+}
+
+// SetLimits caps concurrent agents in total and per zone. Agents spawned
+// over a limit are queued until a slot frees up.
+func (m *Manager) SetLimits(cfg *bridge.LimitsConfig) {
+	m.mu.Lock()
+	m.limits = cfg
+	m.mu.Unlock()
+	m.dispatch()
+}
+
+// recordKing appends a King message to the transcript, if it is kept.
+func (m *Manager) recordKing(role, content string) {
+	if m.transcript != nil {
+		m.transcript.Append(chat.Message{Session: "king", Role: role, Content: content, Source: "king"})
+	}
+}
+
+// EventsConsumer is the event log consumer that Events reads for.
+const EventsConsumer = "manager"
+
+// SetEventLog records agent and King events in log instead of the default
+// in-memory one, so events not yet acknowledged survive a restart. Call
+// it before Events.
+func (m *Manager) SetEventLog(log *eventlog.Log) {
+	m.eventLog = log
+}
+
+// Events returns the channel for agent events. It starts with the events
+// logged after the last Ack and never drops any; a slow reader only
+// delays delivery.
 func (m *Manager) Events() <-chan Event {
+	m.eventsOnce.Do(func() {
+		go func() {
+			for rec := range m.eventLog.Subscribe(EventsConsumer, nil) {
+				e, ok := rec.Value.(Event)
+				if !ok && json.Unmarshal(rec.Data, &e) != nil {
+					continue
+				}
+				e.Seq = rec.Seq
+				m.eventsChan <- e
+			}
+		}()
+	})
 	return m.eventsChan
 }
 
+// Ack acknowledges every event up to seq read from Events, so they are
+// not delivered again after a restart.
+func (m *Manager) Ack(seq uint64) error {
+	return m.eventLog.Ack(EventsConsumer, seq)
+}
+
 // SpawnRequest represents a request to spawn an agent
 type SpawnRequest struct {
-	Type        AgentType `json:"type"`
-	Name        string    `json:"name"`
-	Task        string    `json:"task"`
-	Persona     string    `json:"persona"`
-	Zone        string    `json:"zone"`
-	WorkingDir  string    `json:"workingDir"`
-	Agent       string    `json:"agent"`       // For python type: v0_minimal, v1_basic, etc.
-	OfflineMode bool      `json:"offlineMode"` // Use Ollama instead of Anthropic API
-	OllamaModel string    `json:"ollamaModel"` // Model to use in offline mode, e.g., "qwen3-coder"
-}
-
-// Spawn creates and starts a new agent
+	Type        AgentType            `json:"type"`
+	Name        string               `json:"name"`
+	Task        string               `json:"task"`
+	Persona     string               `json:"persona"`
+	Zone        string               `json:"zone"`
+	WorkingDir  string               `json:"workingDir"`
+	Agent       string               `json:"agent"`       // For python type: v0_minimal, v1_basic, etc.
+	OfflineMode bool                 `json:"offlineMode"` // Use an offline provider instead of Anthropic API
+	Provider    string               `json:"provider"`    // "ollama" (default) or "openai"
+	OllamaModel string               `json:"ollamaModel"` // Model to use in offline mode, e.g., "qwen3-coder"
+	OllamaURL   string               `json:"ollamaURL"`   // Ollama server in offline mode; default localhost:11434
+	OpenAI      *bridge.OpenAIConfig `json:"openai"`      // OpenAI-compatible server for provider "openai"
+	Protocol    Protocol             `json:"protocol"`    // stdio protocol; empty = detect
+	System      string               `json:"system"`      // appended to the runtime's system prompt
+}
+
+// offlineConfig is the request's offline settings, with the manager's
+// models, as a project config.
+func (req SpawnRequest) offlineConfig(models *bridge.ModelConfig) *bridge.ProjectConfig {
+	cfg := &bridge.ProjectConfig{
+		Provider:    req.Provider,
+		OllamaModel: req.OllamaModel,
+		OllamaURL:   req.OllamaURL,
+		OpenAI:      req.OpenAI,
+		Models:      models,
+	}
+	if req.OfflineMode {
+		cfg.Mode = "offline"
+	}
+	return cfg
+}
+
+// Spawn creates and starts a new agent. If its zone or the manager is at
+// the concurrency limit, the agent is queued (agent_queued) and started
+// when a slot frees up.
 func (m *Manager) Spawn(req SpawnRequest) (*Agent, error) {
+	if req.Persona == bridge.PersonaManual {
+		return nil, fmt.Errorf("persona %q is for human-assigned tasks: no agent is spawned", bridge.PersonaManual)
+	}
+	if err := spend.Check(spend.DefaultDir(), time.Now()); err != nil {
+		return nil, err
+	}
 	id := hashid.Generate("agent", req.Task, string(req.Type), req.Zone, req.Persona)
 
 	// Use provided name or generate from ID
@@ -150,123 +339,321 @@ func (m *Manager) Spawn(req SpawnRequest) (*Agent, error) {
 		Cost:        0,
 		CreatedAt:   time.Now(),
 		OfflineMode: req.OfflineMode,
-		Model:       req.OllamaModel,
+		Protocol:    req.Protocol,
+	}
+	if limits := m.resources.For(req.Persona); !limits.IsZero() {
+		agent.Limits = &limits
+	}
+
+	// Pick the worker CLI: the request's type, else the configured runtime
+	// for the persona/zone, else Claude Code
+	if agent.Type == "" {
+		agent.Type = AgentType(m.runtimes.For(req.Persona, zone))
+	}
+	if agent.Type == "" {
+		agent.Type = AgentTypeClaudeCode
+	}
+	agent.Model = req.offlineConfig(m.models).WorkerModel(req.Persona, string(agent.Type))
+	rt, err := RuntimeFor(agent.Type)
+	if err != nil {
+		return nil, err
 	}
 
-	// All agents now use Claude Code (Python agents have been deprecated)
-	// Use --dangerously-skip-permissions for headless execution
-	// In production, consider using --permission-mode with more granular control
-	args := []string{"-p", req.Task, "--output-format", "stream-json", "--dangerously-skip-permissions"}
+	launch := Launch{Task: req.Task, System: req.System, Stream: true}
+	if m.permissions != nil {
+		launch.Allowed = append([]string{}, m.permissions.AutoApprove...)
+	}
+	// The persona's model, or in offline mode the provider's, which the
+	// worker is pointed at
+	launch.Model = agent.Model
+	if req.OfflineMode {
+		offline := req.offlineConfig(m.models)
+		launch.Env = offline.WorkerEnv(os.Getenv)
+		fmt.Printf("Agent %s running in offline mode with %s (model: %s)\n", id, offline.ProviderName(), agent.Model)
+	}
 
-	// Add model flag for offline mode
-	if req.OfflineMode && req.OllamaModel != "" {
-		args = append(args, "--model", req.OllamaModel)
+	// The command inherits our environment (includes ANTHROPIC_API_KEY)
+	// plus the stored secrets and the zone's env profile, which wins
+	profileEnv, record, err := m.env.For(zone)
+	if err != nil {
+		return nil, err
+	}
+	agent.Env = record
+	launch.Env = append(launch.Env, secrets.Environ()...)
+	launch.Env = append(launch.Env, profileEnv...)
+	agent.runtime = rt
+	agent.launch = launch
+	if err := prepare(agent); err != nil {
+		return nil, err
 	}
 
-	cmd := exec.Command("claude", args...)
-	fmt.Printf("Spawning Claude Code agent: claude %v\n", args)
+	// Store agent; a starting agent holds its slot
+	m.mu.Lock()
+	if !m.hasSlot(agent.Zone) {
+		agent.Status = StatusQueued
+		m.agents[agent.ID] = agent
+		m.queue = append(m.queue, agent)
+		position := len(m.queue)
+		m.mu.Unlock()
+		m.emitEvent("agent_queued", agent.ID, map[string]interface{}{
+			"zone":     agent.Zone,
+			"position": position,
+		})
+		return agent, nil
+	}
+	m.agents[agent.ID] = agent
+	m.mu.Unlock()
 
-	if req.WorkingDir != "" {
-		cmd.Dir = req.WorkingDir
+	if err := m.start(agent); err != nil {
+		m.mu.Lock()
+		delete(m.agents, agent.ID)
+		m.mu.Unlock()
+		return nil, err
 	}
+	return agent, nil
+}
 
-	// Pass through environment variables (includes ANTHROPIC_API_KEY)
-	cmd.Env = os.Environ()
+// prepare builds the agent's command and its pipes, for the first start
+// and each restart.
+func prepare(agent *Agent) error {
+	cmd, err := agent.runtime.Command(agent.launch)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Spawning %s agent: %v\n", agent.Type, cmd.Args)
 
-	// For offline mode, override environment to point to Ollama
-	if req.OfflineMode {
-		cmd.Env = append(cmd.Env,
-			"ANTHROPIC_BASE_URL=http://localhost:11434",
-			"ANTHROPIC_AUTH_TOKEN=ollama",
-			"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC=1",
-		)
-		fmt.Printf("Agent %s running in offline mode with Ollama (model: %s)\n", id, req.OllamaModel)
+	if agent.WorkingDir != "" {
+		cmd.Dir = agent.WorkingDir
 	}
 
 	// Set up pipes
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
 
 	agent.cmd = cmd
 	agent.stdout = stdout
 	agent.stderr = stderr
 	agent.stdin = stdin
+	return nil
+}
 
-	// Start the process
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start agent: %w", err)
+// start runs a prepared agent's process and begins reading its output.
+func (m *Manager) start(agent *Agent) error {
+	if err := agent.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
 	}
 
-	agent.PID = cmd.Process.Pid
-	agent.Status = StatusWorking
-
-	// Store agent
 	m.mu.Lock()
-	m.agents[agent.ID] = agent
+	agent.PID = agent.cmd.Process.Pid
+	agent.Status = StatusWorking
+	agent.startedAt = time.Now()
+	agent.exited = make(chan struct{})
 	m.mu.Unlock()
 
 	// Emit spawn event
 	m.emitEvent("agent_spawned", agent.ID, agent)
 
-	// For non-interactive prompts (-p flag), close stdin to signal EOF
-	if req.Type == AgentTypeClaudeCode {
-		stdin.Close()
+	if agent.Limits != nil {
+		go m.enforceLimits(agent, *agent.Limits)
+	}
+
+	// Runtimes take the task on the command line; close stdin to signal
+	// EOF (python agents, deprecated, kept it open for SendMessage).
+	// JSON-RPC agents keep it for requests and messages.
+	switch {
+	case agent.Protocol == ProtocolJSONRPC:
+		agent.rpc = newRPCConn(agent.stdin)
+	case agent.Type != AgentTypePython:
+		agent.stdin.Close()
 		agent.stdin = nil
 	}
 
 	// Start reading output
-	go m.readOutput(agent)
-	go m.readStderr(agent)
+	agent.readers.Add(2)
+	go func() {
+		defer agent.readers.Done()
+		m.readOutput(agent)
+	}()
+	go func() {
+		defer agent.readers.Done()
+		m.readStderr(agent)
+	}()
 
 	// Wait for process to complete
 	go m.waitForCompletion(agent)
 
-	return agent, nil
+	return nil
 }
 
-// readOutput reads stdout from the agent and emits events
-func (m *Manager) readOutput(agent *Agent) {
-	// Use larger buffer for JSON output (1MB)
-	scanner := bufio.NewScanner(agent.stdout)
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 1024*1024)
+// hasSlot reports whether the limits allow one more agent in zone.
+// Callers hold m.mu.
+func (m *Manager) hasSlot(zone string) bool {
+	if m.limits == nil {
+		return true
+	}
+	running, inZone := 0, 0
+	for _, a := range m.agents {
+		if a.Status == StatusQueued || a.Status == StatusStopped || a.Status == StatusError || a.Status == StatusTimedOut {
+			continue
+		}
+		running++
+		if a.Zone == zone {
+			inZone++
+		}
+	}
+	return m.limits.Allows(zone, running, inZone)
+}
+
+// releaseSlot announces that an agent in zone finished (slot_freed) and
+// starts the queued agents that now fit.
+func (m *Manager) releaseSlot(zone string) {
+	m.mu.RLock()
+	limited := m.limits != nil
+	queued := len(m.queue)
+	m.mu.RUnlock()
+	if !limited {
+		return
+	}
+	m.emitEvent("slot_freed", "", map[string]interface{}{
+		"zone":   zone,
+		"queued": queued,
+	})
+	m.dispatch()
+}
 
-	fmt.Printf("Agent %s: Starting to read output...\n", agent.ID)
+// dispatch starts queued agents, oldest first, while slots are free.
+func (m *Manager) dispatch() {
+	for {
+		m.mu.Lock()
+		var next *Agent
+		for i, a := range m.queue {
+			if m.hasSlot(a.Zone) {
+				next = a
+				m.queue = append(m.queue[:i:i], m.queue[i+1:]...)
+				next.Status = StatusStarting
+				break
+			}
+		}
+		m.mu.Unlock()
+		if next == nil {
+			return
+		}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		fmt.Printf("Agent %s output: %s\n", agent.ID, truncate(line, 200))
+		if err := m.start(next); err != nil {
+			m.mu.Lock()
+			next.Status = StatusError
+			next.Error = err.Error()
+			m.mu.Unlock()
+			m.emitEvent("agent_stopped", next.ID, map[string]interface{}{
+				"status": next.Status,
+				"error":  next.Error,
+			})
+		}
+	}
+}
 
-		// Try to parse as JSON event
-		var event map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &event); err == nil {
-			// It's JSON - emit as-is
-			m.emitEvent("agent_output", agent.ID, event)
-		} else {
-			// Plain text output
-			m.emitEvent("agent_output", agent.ID, map[string]string{"text": line})
+// readOutput reads messages from the agent's stdout: JSON-RPC frames, or
+// lines through the legacy shim. "output" events are emitted as
+// agent_output, other events as agent_event; requests go to the RPC
+// handlers and responses to the pending Call.
+func (m *Manager) readOutput(agent *Agent) {
+	reader, protocol := NewMessageReader(agent.stdout, agent.Protocol)
+	m.mu.Lock()
+	agent.Protocol = protocol
+	m.mu.Unlock()
+
+	fmt.Printf("Agent %s: Starting to read output (%s)...\n", agent.ID, protocol)
+
+	var err error
+	for {
+		var msg *RPCMessage
+		msg, err = reader.ReadMessage()
+		if errors.Is(err, ErrBadMessage) {
+			fmt.Printf("Agent %s: Skipping message: %v\n", agent.ID, err)
+			continue
 		}
+		if err != nil {
+			break
+		}
+		m.handleMessage(agent, msg)
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Printf("Agent %s: Scanner error: %v\n", agent.ID, err)
+	if err != io.EOF {
+		fmt.Printf("Agent %s: Read error: %v\n", agent.ID, err)
+	}
+	if agent.rpc != nil {
+		agent.rpc.close(fmt.Errorf("agent %s exited", agent.ID))
 	}
 
 	fmt.Printf("Agent %s: Finished reading output\n", agent.ID)
 }
 
+// handleMessage acts on one message from an agent.
+func (m *Manager) handleMessage(agent *Agent, msg *RPCMessage) {
+	switch msg.Kind() {
+	case KindEvent:
+		text := string(msg.Params)
+		if msg.Method == "output" {
+			var plain struct {
+				Text *string `json:"text"`
+			}
+			if json.Unmarshal(msg.Params, &plain) == nil && plain.Text != nil {
+				text = *plain.Text
+			}
+			m.emitEvent("agent_output", agent.ID, msg.Params)
+			m.checkAttention(agent, msg.Params)
+		} else {
+			text = msg.Method + " " + text
+			m.emitEvent("agent_event", agent.ID, map[string]interface{}{
+				"method": msg.Method,
+				"params": msg.Params,
+			})
+		}
+		fmt.Printf("Agent %s output: %s\n", agent.ID, truncate(text, 200))
+		if m.logs != nil {
+			m.logs.Append(agent.ID, "stdout", text)
+		}
+
+	case KindRequest:
+		if agent.rpc == nil {
+			return // stdin is closed: nowhere to answer
+		}
+		m.mu.RLock()
+		h, ok := m.handlers[msg.Method]
+		m.mu.RUnlock()
+		var result interface{}
+		var err error
+		if ok {
+			result, err = h(agent, msg.Params)
+		} else {
+			err = &RPCError{Code: RPCMethodNotFound, Message: "method not found: " + msg.Method}
+		}
+		if err := agent.rpc.respond(msg.ID, result, err); err != nil {
+			fmt.Printf("Agent %s: Failed to respond to %s: %v\n", agent.ID, msg.Method, err)
+		}
+
+	case KindResponse:
+		if agent.rpc == nil || !agent.rpc.resolve(msg) {
+			fmt.Printf("Agent %s: Unexpected response %s\n", agent.ID, msg.ID)
+		}
+
+	default:
+		fmt.Printf("Agent %s: Ignoring message with neither method nor id\n", agent.ID)
+	}
+}
+
 // truncate truncates a string for logging
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -281,32 +668,60 @@ func (m *Manager) readStderr(agent *Agent) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		fmt.Printf("Agent %s stderr: %s\n", agent.ID, line)
+		if m.logs != nil {
+			m.logs.Append(agent.ID, "stderr", line)
+		}
 		m.emitEvent("agent_error", agent.ID, map[string]string{"text": line})
 	}
 }
 
 // waitForCompletion waits for the agent process to finish
 func (m *Manager) waitForCompletion(agent *Agent) {
+	// Wait closes the pipes, so let the readers drain them first
+	agent.readers.Wait()
 	err := agent.cmd.Wait()
+	close(agent.exited)
 
 	m.mu.Lock()
-	if err != nil {
+	switch {
+	case agent.limitHit == limitTimeout:
+		agent.Status = StatusTimedOut
+		agent.Error = fmt.Sprintf("exceeded its %s timeout", agent.Limits.Timeout)
+		fmt.Printf("Agent %s (%s) timed out\n", agent.Name, agent.ID)
+	case agent.limitHit == limitMemory:
+		agent.Status = StatusError
+		agent.Error = fmt.Sprintf("exceeded its %d MB memory limit", agent.Limits.MaxMemoryMB)
+		fmt.Printf("Agent %s (%s) exceeded its memory limit\n", agent.Name, agent.ID)
+	case err != nil:
 		agent.Status = StatusError
 		agent.Error = err.Error()
 		fmt.Printf("Agent %s (%s) exited with error: %v\n", agent.Name, agent.ID, err)
-	} else {
+	default:
 		agent.Status = StatusStopped
 		fmt.Printf("Agent %s (%s) completed successfully\n", agent.Name, agent.ID)
 	}
 	m.mu.Unlock()
 
+	if m.scheduleRestart(agent) {
+		return
+	}
 	m.emitEvent("agent_stopped", agent.ID, map[string]interface{}{
 		"status": agent.Status,
 		"error":  agent.Error,
 	})
+	m.finish(agent)
+}
+
+// finish releases an agent that won't run again: its log is persisted and
+// its slot freed.
+func (m *Manager) finish(agent *Agent) {
+	if m.logs != nil {
+		m.logs.Finish(agent.ID)
+	}
+	m.releaseSlot(agent.Zone)
 }
 
-// emitEvent sends an event to the events channel
+// emitEvent records an event in the event log for Events to deliver.
 func (m *Manager) emitEvent(eventType string, agentID string, data interface{}) {
 	dataBytes, _ := json.Marshal(data)
 	event := Event{
@@ -315,11 +730,8 @@ func (m *Manager) emitEvent(eventType string, agentID string, data interface{})
 		Data:    dataBytes,
 	}
 
-	select {
-	case m.eventsChan <- event:
-	default:
-		// Channel full, drop event
-		fmt.Fprintf(os.Stderr, "Warning: event channel full, dropping event\n")
+	if _, err := m.eventLog.Append(eventType, event); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging event %s: %v\n", eventType, err)
 	}
 }
 
@@ -352,8 +764,20 @@ func (m *Manager) Kill(id string) error {
 		return fmt.Errorf("agent not found: %s", id)
 	}
 
-	// If already stopped, just remove from map
-	if agent.Status == StatusStopped || agent.Status == StatusError {
+	agent.killed = true
+
+	// If already stopped, still queued or waiting to restart, just remove
+	// from map
+	if agent.Status == StatusRestarting {
+		close(agent.cancel)
+	}
+	if agent.Status == StatusQueued {
+		m.dequeue(id)
+		agent.stdin.Close()
+		agent.stdout.Close()
+		agent.stderr.Close()
+	}
+	if agent.Status == StatusStopped || agent.Status == StatusError || agent.Status == StatusTimedOut || agent.Status == StatusQueued || agent.Status == StatusRestarting {
 		delete(m.agents, id)
 		m.mu.Unlock()
 		m.emitEvent("agent_removed", id, nil)
@@ -375,6 +799,16 @@ func (m *Manager) Kill(id string) error {
 	return nil
 }
 
+// dequeue removes an agent from the queue. Callers hold m.mu.
+func (m *Manager) dequeue(id string) {
+	for i, a := range m.queue {
+		if a.ID == id {
+			m.queue = append(m.queue[:i:i], m.queue[i+1:]...)
+			return
+		}
+	}
+}
+
 // SendMessage sends a message to an agent's stdin
 func (m *Manager) SendMessage(id string, message string) error {
 	m.mu.RLock()
@@ -385,17 +819,44 @@ func (m *Manager) SendMessage(id string, message string) error {
 		return fmt.Errorf("agent not found: %s", id)
 	}
 
+	return sendMessage(agent, message)
+}
+
+// sendMessage writes a message to the agent: a "message" event for
+// JSON-RPC agents, else a line on stdin.
+func sendMessage(agent *Agent, message string) error {
+	if agent.rpc != nil {
+		return agent.rpc.notify("message", map[string]string{"text": message})
+	}
 	if agent.stdin == nil {
 		return fmt.Errorf("agent stdin not available")
 	}
-
 	_, err := fmt.Fprintln(agent.stdin, message)
 	return err
 }
 
+// Call sends a request to a JSON-RPC agent and waits for the result.
+func (m *Manager) Call(ctx context.Context, id, method string, params interface{}) (json.RawMessage, error) {
+	m.mu.RLock()
+	agent, ok := m.agents[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("agent not found: %s", id)
+	}
+	if agent.rpc == nil {
+		return nil, fmt.Errorf("agent %s does not speak JSON-RPC", id)
+	}
+	return agent.rpc.call(ctx, method, params)
+}
+
 // SendKingMessage sends a message to the King orchestrator
 // The King is a special Claude Code agent that manages other agents
 func (m *Manager) SendKingMessage(message string) error {
+	if err := spend.Check(spend.DefaultDir(), time.Now()); err != nil {
+		return err
+	}
+	m.recordKing("user", message)
+
 	m.mu.RLock()
 	// Look for an agent named "king" or with persona "king"
 	var kingAgent *Agent
@@ -416,30 +877,38 @@ func (m *Manager) SendKingMessage(message string) error {
 			Persona: "king",
 			Zone:    "default",
 		}
+		if m.kingPrompt != nil {
+			prompt, err := m.kingPrompt()
+			if err != nil {
+				return fmt.Errorf("king prompt: %w", err)
+			}
+			if prompt.Inject == briefing.InjectMessage && prompt.Text != "" {
+				req.Task = prompt.Text + "\n\n" + message
+			} else {
+				req.System = prompt.Text
+			}
+		}
 		agent, err := m.Spawn(req)
 		if err != nil {
 			return fmt.Errorf("failed to spawn king agent: %w", err)
 		}
 
 		// Emit king response event (the agent will send responses via WebSocket)
+		reply := "I'm analyzing your request and will coordinate the team to accomplish this goal..."
 		m.emitEvent("king_response", agent.ID, map[string]interface{}{
 			"message": map[string]interface{}{
 				"role":      "assistant",
-				"content":   "I'm analyzing your request and will coordinate the team to accomplish this goal...",
+				"content":   reply,
 				"timestamp": time.Now().UnixMilli(),
 			},
 		})
+		m.recordKing("assistant", reply)
 		return nil
 	}
 
 	// Send message to existing king agent
-	if kingAgent.stdin == nil {
-		return fmt.Errorf("king agent stdin not available")
-	}
-
-	_, err := fmt.Fprintln(kingAgent.stdin, message)
-	if err != nil {
-		return err
+	if err := sendMessage(kingAgent, message); err != nil {
+		return fmt.Errorf("king agent: %w", err)
 	}
 
 	return nil
@@ -1,8 +1,11 @@
 package manager
 
 import (
+	"os/exec"
 	"testing"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
 )
 
 func TestNewManager(t *testing.T) {
@@ -275,6 +278,27 @@ func TestEventsChannel(t *testing.T) {
 	}
 }
 
+func TestEventsNotDroppedWhenReaderIsSlow(t *testing.T) {
+	m := NewManager("/tmp/agents")
+
+	for i := 0; i < 500; i++ {
+		m.emitEvent("king_response", "king", map[string]int{"i": i})
+	}
+	for want := uint64(1); want <= 500; want++ {
+		select {
+		case event := <-m.Events():
+			if event.Seq != want {
+				t.Fatalf("Expected seq %d, got %d", want, event.Seq)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for event %d", want)
+		}
+	}
+	if err := m.Ack(500); err != nil {
+		t.Errorf("Ack failed: %v", err)
+	}
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -345,3 +369,96 @@ func TestAgentType(t *testing.T) {
 		t.Errorf("Expected AgentTypeClaudeCode to be 'claude-code', got %s", AgentTypeClaudeCode)
 	}
 }
+
+func TestConcurrencyLimitsQueueAgents(t *testing.T) {
+	RegisterRuntime("test-sleep", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		return exec.Command("sleep", "30"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetLimits(&bridge.LimitsConfig{MaxWorkers: 2, Zones: map[string]int{"backend": 1}})
+	spawn := func(task, zone string) *Agent {
+		t.Helper()
+		a, err := m.Spawn(SpawnRequest{Type: "test-sleep", Task: task, Zone: zone})
+		if err != nil {
+			t.Fatalf("Spawn(%s): %v", task, err)
+		}
+		return a
+	}
+	first := spawn("first", "backend")
+	second := spawn("second", "backend")
+	third := spawn("third", "frontend")
+	fourth := spawn("fourth", "frontend")
+	defer func() {
+		for _, a := range m.List() {
+			m.Kill(a.ID)
+		}
+	}()
+
+	status := func(a *Agent) AgentStatus {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return a.Status
+	}
+	if status(first) != StatusWorking || status(third) != StatusWorking {
+		t.Fatalf("first %s, third %s: want both working", status(first), status(third))
+	}
+	if status(second) != StatusQueued {
+		t.Fatalf("second = %s, want queued by the backend limit", status(second))
+	}
+	if status(fourth) != StatusQueued {
+		t.Fatalf("fourth = %s, want queued by the global limit", status(fourth))
+	}
+
+	// Killing the first frees a backend slot for the second, which was
+	// queued before the fourth
+	if err := m.Kill(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	var freed bool
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-m.Events():
+			if ev.Type == "slot_freed" {
+				freed = true
+			}
+			if ev.Type == "agent_spawned" && ev.AgentID == second.ID {
+				if !freed {
+					t.Error("agent started before slot_freed")
+				}
+				if status(fourth) != StatusQueued {
+					t.Errorf("fourth = %s, want still queued", status(fourth))
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("queued agent did not start when a slot freed up")
+		}
+	}
+}
+
+func TestKillQueuedAgent(t *testing.T) {
+	RegisterRuntime("test-sleep", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		return exec.Command("sleep", "30"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetLimits(&bridge.LimitsConfig{MaxWorkers: 1})
+	running, _ := m.Spawn(SpawnRequest{Type: "test-sleep", Task: "running"})
+	defer m.Kill(running.ID)
+	queued, err := m.Spawn(SpawnRequest{Type: "test-sleep", Task: "queued"})
+	if err != nil || queued.Status != StatusQueued {
+		t.Fatalf("Spawn: %v, status %s; want queued", err, queued.Status)
+	}
+
+	if err := m.Kill(queued.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Get(queued.ID); ok {
+		t.Error("killed queued agent still listed")
+	}
+	if len(m.queue) != 0 {
+		t.Errorf("queue = %d agents, want empty", len(m.queue))
+	}
+}
@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+)
+
+// StableAfter is how long an agent must run for its restarts to count as
+// recovered: the backoff and the attempt count start over.
+var StableAfter = 10 * time.Minute
+
+// CrashLoopRestarts is how many consecutive restarts without a stable run
+// mark an agent as crash-looping.
+const CrashLoopRestarts = 3
+
+// scheduleRestart restarts an agent that exited if its type's restart
+// policy says so, after the policy's backoff. It emits agent_restarted
+// and reports whether a restart was scheduled; when MaxAttempts is used
+// up the agent's error says so and it stays stopped.
+func (m *Manager) scheduleRestart(agent *Agent) bool {
+	m.mu.Lock()
+	policy := m.restart.For(string(agent.Type))
+	if agent.killed || !policy.Restarts(agent.Status != StatusStopped) {
+		m.mu.Unlock()
+		return false
+	}
+	if time.Since(agent.startedAt) >= StableAfter {
+		agent.attempts = 0
+		agent.CrashLoop = false
+	}
+	if policy.MaxAttempts > 0 && agent.attempts >= policy.MaxAttempts {
+		if agent.Error == "" {
+			agent.Error = fmt.Sprintf("gave up after %d restarts", agent.attempts)
+		} else {
+			agent.Error = fmt.Sprintf("%s (gave up after %d restarts)", agent.Error, agent.attempts)
+		}
+		m.mu.Unlock()
+		return false
+	}
+
+	exitStatus, exitError := agent.Status, agent.Error
+	agent.attempts++
+	agent.Restarts++
+	agent.CrashLoop = agent.attempts >= CrashLoopRestarts
+	agent.Status = StatusRestarting
+	agent.cancel = make(chan struct{})
+	attempt := agent.attempts
+	delay := policy.Delay(attempt)
+	data := map[string]interface{}{
+		"attempt":     attempt,
+		"restarts":    agent.Restarts,
+		"delay":       delay.String(),
+		"exit_status": exitStatus,
+		"error":       exitError,
+		"crash_loop":  agent.CrashLoop,
+	}
+	cancel := agent.cancel
+	m.mu.Unlock()
+
+	fmt.Printf("Agent %s (%s) restarting in %s (attempt %d)\n", agent.Name, agent.ID, delay, attempt)
+	m.emitEvent("agent_restarted", agent.ID, data)
+	go m.restartAfter(agent, delay, cancel)
+	return true
+}
+
+// restartAfter starts the agent again once delay has passed, unless Kill
+// cancels the restart first. It keeps the agent's slot meanwhile.
+func (m *Manager) restartAfter(agent *Agent, delay time.Duration, cancel <-chan struct{}) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-cancel:
+		m.finish(agent)
+		return
+	}
+
+	m.mu.Lock()
+	if agent.killed {
+		m.mu.Unlock()
+		m.finish(agent)
+		return
+	}
+	agent.Status = StatusStarting
+	agent.Error = ""
+	agent.limitHit = ""
+	m.mu.Unlock()
+
+	err := prepare(agent)
+	if err == nil {
+		err = m.start(agent)
+	}
+	if err != nil {
+		m.mu.Lock()
+		agent.Status = StatusError
+		agent.Error = err.Error()
+		m.mu.Unlock()
+		m.emitEvent("agent_stopped", agent.ID, map[string]interface{}{
+			"status": agent.Status,
+			"error":  agent.Error,
+		})
+		m.finish(agent)
+	}
+}
@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+func TestRestartOnFailureGivesUp(t *testing.T) {
+	RegisterRuntime("test-crash", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		return exec.Command("sh", "-c", "exit 1"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetRestartPolicy(&bridge.RestartConfig{Types: map[string]bridge.RestartPolicy{
+		"test-crash": {Policy: bridge.RestartOnFailure, MaxAttempts: 3, Backoff: "10ms"},
+	}})
+	agent, err := m.Spawn(SpawnRequest{Type: "test-crash", Task: "crash"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+
+	var attempts []int
+	var crashLoop bool
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case ev := <-m.Events():
+			switch ev.Type {
+			case "agent_restarted":
+				var data struct {
+					Attempt   int  `json:"attempt"`
+					CrashLoop bool `json:"crash_loop"`
+				}
+				json.Unmarshal(ev.Data, &data)
+				attempts = append(attempts, data.Attempt)
+				crashLoop = data.CrashLoop
+			case "agent_stopped":
+				if len(attempts) != 3 || attempts[2] != 3 {
+					t.Errorf("restart attempts = %v, want 1, 2, 3", attempts)
+				}
+				if !crashLoop {
+					t.Error("third restart in a row not flagged as a crash loop")
+				}
+				m.mu.RLock()
+				defer m.mu.RUnlock()
+				if agent.Status != StatusError || agent.Restarts != 3 || !strings.Contains(agent.Error, "gave up after 3 restarts") {
+					t.Errorf("agent = %s, %d restarts, %q", agent.Status, agent.Restarts, agent.Error)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatalf("agent did not stop restarting; attempts %v", attempts)
+		}
+	}
+}
+
+func TestKillCancelsRestart(t *testing.T) {
+	RegisterRuntime("test-exit", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		return exec.Command("true"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetRestartPolicy(&bridge.RestartConfig{Default: bridge.RestartPolicy{Policy: bridge.RestartAlways, Backoff: "1h"}})
+	agent, err := m.Spawn(SpawnRequest{Type: "test-exit", Task: "exit"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case ev := <-m.Events():
+			if ev.Type == "agent_stopped" {
+				t.Fatal("a successful exit stopped the agent under an always policy")
+			}
+			if ev.Type != "agent_restarted" {
+				continue
+			}
+			if err := m.Kill(agent.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := m.Get(agent.ID); ok {
+				t.Error("killed agent still listed during its backoff")
+			}
+			return
+		case <-deadline:
+			t.Fatal("agent was not restarted")
+		}
+	}
+}
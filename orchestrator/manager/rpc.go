@@ -0,0 +1,338 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Protocol is how an agent talks to the manager over stdio.
+type Protocol string
+
+const (
+	ProtocolAuto    Protocol = ""        // detected from the agent's first output
+	ProtocolLines   Protocol = "lines"   // legacy: one event per stdout line, JSON or text
+	ProtocolJSONRPC Protocol = "jsonrpc" // JSON-RPC 2.0 in Content-Length frames
+)
+
+// JSON-RPC error codes
+const (
+	RPCMethodNotFound = -32601
+	RPCInternalError  = -32603
+)
+
+// maxFrameSize bounds one framed message
+const maxFrameSize = 16 * 1024 * 1024
+
+// contentLength is the header that starts every frame
+const contentLength = "Content-Length:"
+
+// RPCMessage is a JSON-RPC 2.0 request, response or event (notification).
+//
+// Framed messages are sent as
+//
+//	Content-Length: <bytes>\r\n
+//	\r\n
+//	<JSON body>
+//
+// so a body may span lines and output from several writers cannot split
+// a message.
+type RPCMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// MessageKind is what an RPCMessage is
+type MessageKind int
+
+const (
+	KindEvent    MessageKind = iota // method, no id: no reply expected
+	KindRequest                     // method and id: expects a response
+	KindResponse                    // id, no method: answers a request
+	KindInvalid
+)
+
+// Kind classifies the message.
+func (msg *RPCMessage) Kind() MessageKind {
+	hasID := len(msg.ID) > 0 && string(msg.ID) != "null"
+	switch {
+	case msg.Method != "" && hasID:
+		return KindRequest
+	case msg.Method != "":
+		return KindEvent
+	case hasID:
+		return KindResponse
+	}
+	return KindInvalid
+}
+
+// RPCError is the error member of a JSON-RPC response
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// ErrBadMessage is returned for a frame whose body is not a JSON-RPC
+// message. The frame has been consumed, so reading can go on.
+var ErrBadMessage = errors.New("bad message")
+
+// MessageReader reads messages from an agent's stdout.
+type MessageReader interface {
+	ReadMessage() (*RPCMessage, error)
+}
+
+// NewMessageReader reads r with protocol p. With ProtocolAuto it waits
+// for the first bytes of output: a Content-Length header selects JSON-RPC
+// framing, anything else the line shim. It returns the protocol in use.
+func NewMessageReader(r io.Reader, p Protocol) (MessageReader, Protocol) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	if p == ProtocolAuto {
+		p = detectProtocol(br)
+	}
+	if p == ProtocolJSONRPC {
+		return &frameReader{r: br}, p
+	}
+	return &lineReader{r: br}, ProtocolLines
+}
+
+// detectProtocol peeks at the start of the output one byte at a time, so
+// a legacy agent's short first line is not held up.
+func detectProtocol(br *bufio.Reader) Protocol {
+	for n := 1; n <= len(contentLength); n++ {
+		b, err := br.Peek(n)
+		if err != nil || !strings.EqualFold(string(b), contentLength[:n]) {
+			return ProtocolLines
+		}
+	}
+	return ProtocolJSONRPC
+}
+
+// frameReader reads Content-Length framed messages
+type frameReader struct {
+	r *bufio.Reader
+}
+
+func (f *frameReader) ReadMessage() (*RPCMessage, error) {
+	length := -1
+	for {
+		line, err := f.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read frame header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if length < 0 {
+				continue // blank lines between frames
+			}
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed frame header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 || n > maxFrameSize {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			length = n
+		}
+		// other headers (Content-Type) are ignored
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(f.r, body); err != nil {
+		return nil, fmt.Errorf("failed to read frame body: %w", err)
+	}
+	var msg RPCMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadMessage, err)
+	}
+	return &msg, nil
+}
+
+// lineReader is the shim for legacy agents: each stdout line becomes an
+// "output" event whose params are the line if it is a JSON object, else
+// {"text": line}.
+type lineReader struct {
+	r *bufio.Reader
+}
+
+func (l *lineReader) ReadMessage() (*RPCMessage, error) {
+	line, err := l.r.ReadString('\n')
+	if line == "" && err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	var event map[string]interface{}
+	params := json.RawMessage(line)
+	if json.Unmarshal(params, &event) != nil {
+		params, _ = json.Marshal(map[string]string{"text": line})
+	}
+	return &RPCMessage{JSONRPC: "2.0", Method: "output", Params: params}, nil
+}
+
+// WriteMessage writes msg to w as one frame.
+func WriteMessage(w io.Writer, msg *RPCMessage) error {
+	if msg.JSONRPC == "" {
+		msg.JSONRPC = "2.0"
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(body))
+	buf.Write(body)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// RPCHandler answers a request from an agent. The result is marshaled
+// into the response; an *RPCError is sent as is.
+type RPCHandler func(agent *Agent, params json.RawMessage) (interface{}, error)
+
+// rpcConn is the manager's end of a JSON-RPC agent's stdin: it sends
+// requests and events and matches responses to calls.
+type rpcConn struct {
+	w       io.Writer
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[string]chan *RPCMessage
+	err     error // set once the agent's output ends
+}
+
+func newRPCConn(w io.Writer) *rpcConn {
+	return &rpcConn{w: w, pending: make(map[string]chan *RPCMessage)}
+}
+
+func (c *rpcConn) send(msg *RPCMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return WriteMessage(c.w, msg)
+}
+
+// notify sends an event.
+func (c *rpcConn) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.send(&RPCMessage{Method: method, Params: raw})
+}
+
+// call sends a request and waits for its response.
+func (c *rpcConn) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := json.RawMessage(strconv.FormatInt(c.nextID, 10))
+	ch := make(chan *RPCMessage, 1)
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, string(id))
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(&RPCMessage{ID: id, Method: method, Params: raw}); err != nil {
+		return nil, err
+	}
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, c.closedErr()
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// respond answers a request from the agent.
+func (c *rpcConn) respond(id json.RawMessage, result interface{}, err error) error {
+	resp := &RPCMessage{ID: id}
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: RPCInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else {
+		raw, mErr := json.Marshal(result)
+		if mErr != nil {
+			resp.Error = &RPCError{Code: RPCInternalError, Message: mErr.Error()}
+		} else {
+			resp.Result = raw
+		}
+	}
+	return c.send(resp)
+}
+
+// resolve hands a response to its waiting call, reporting whether one was
+// waiting.
+func (c *rpcConn) resolve(msg *RPCMessage) bool {
+	c.mu.Lock()
+	ch, ok := c.pending[string(msg.ID)]
+	delete(c.pending, string(msg.ID))
+	c.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+	return ok
+}
+
+// close fails the pending calls and any later ones with err.
+func (c *rpcConn) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+func (c *rpcConn) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
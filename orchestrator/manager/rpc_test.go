@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFramedMessages(t *testing.T) {
+	var buf bytes.Buffer
+	WriteMessage(&buf, &RPCMessage{Method: "output", Params: json.RawMessage(`{"text":"line one\nline two"}`)})
+	WriteMessage(&buf, &RPCMessage{ID: json.RawMessage(`7`), Method: "ping"})
+	WriteMessage(&buf, &RPCMessage{ID: json.RawMessage(`"a"`), Result: json.RawMessage(`true`)})
+
+	r, protocol := NewMessageReader(&buf, ProtocolAuto)
+	if protocol != ProtocolJSONRPC {
+		t.Fatalf("protocol = %q, want jsonrpc", protocol)
+	}
+	for _, want := range []MessageKind{KindEvent, KindRequest, KindResponse} {
+		msg, err := r.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Kind() != want {
+			t.Errorf("kind = %d, want %d (%+v)", msg.Kind(), want, msg)
+		}
+		if want == KindEvent && !strings.Contains(string(msg.Params), `line one\nline two`) {
+			t.Errorf("params = %s", msg.Params)
+		}
+	}
+	if _, err := r.ReadMessage(); err != io.EOF {
+		t.Errorf("err = %v, want EOF", err)
+	}
+}
+
+func TestBadFrameIsSkipped(t *testing.T) {
+	input := "Content-Length: 5\r\n\r\nnope!Content-Length: 40\r\n\r\n" + `{"jsonrpc":"2.0","method":"done"}`
+	r, _ := NewMessageReader(strings.NewReader(input), ProtocolJSONRPC)
+	if _, err := r.ReadMessage(); !errors.Is(err, ErrBadMessage) {
+		t.Fatalf("err = %v, want ErrBadMessage", err)
+	}
+	if _, err := r.ReadMessage(); err == nil {
+		t.Fatal("expected an error for a truncated body")
+	}
+
+	r, _ = NewMessageReader(strings.NewReader("Content-Length: huge\r\n\r\n"), ProtocolJSONRPC)
+	if _, err := r.ReadMessage(); err == nil || errors.Is(err, ErrBadMessage) {
+		t.Errorf("err = %v, want a framing error", err)
+	}
+}
+
+func TestLineShim(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte("ok\n"))
+
+	// A short first line must not wait for more output
+	done := make(chan *RPCMessage)
+	go func() {
+		r, protocol := NewMessageReader(pr, ProtocolAuto)
+		if protocol != ProtocolLines {
+			t.Errorf("protocol = %q, want lines", protocol)
+		}
+		msg, _ := r.ReadMessage()
+		done <- msg
+	}()
+	select {
+	case msg := <-done:
+		if msg.Method != "output" || string(msg.Params) != `{"text":"ok"}` {
+			t.Errorf("message = %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("detection blocked on a short line")
+	}
+
+	r, _ := NewMessageReader(strings.NewReader("{\"type\":\"result\"}\n[1]\nlast"), ProtocolLines)
+	for _, want := range []string{`{"type":"result"}`, `{"text":"[1]"}`, `{"text":"last"}`} {
+		msg, err := r.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg.Params) != want {
+			t.Errorf("params = %s, want %s", msg.Params, want)
+		}
+	}
+}
+
+// TestHelperRPCAgent is not a test: it is the JSON-RPC agent that
+// TestJSONRPCAgent spawns.
+func TestHelperRPCAgent(t *testing.T) {
+	if os.Getenv("MC_TEST_RPC_AGENT") != "1" {
+		t.Skip("helper process")
+	}
+	WriteMessage(os.Stdout, &RPCMessage{Method: "output", Params: json.RawMessage(`{"text":"line one\nline two"}`)})
+	WriteMessage(os.Stdout, &RPCMessage{ID: json.RawMessage(`"p1"`), Method: "ping"})
+
+	r, _ := NewMessageReader(os.Stdin, ProtocolJSONRPC)
+	for {
+		msg, err := r.ReadMessage()
+		if err != nil {
+			os.Exit(1)
+		}
+		switch msg.Kind() {
+		case KindResponse:
+			WriteMessage(os.Stdout, &RPCMessage{Method: "pinged", Params: msg.Result})
+		case KindRequest:
+			WriteMessage(os.Stdout, &RPCMessage{ID: msg.ID, Result: msg.Params})
+		case KindEvent:
+			WriteMessage(os.Stdout, &RPCMessage{Method: "output", Params: msg.Params})
+			os.Exit(0)
+		}
+	}
+}
+
+func TestJSONRPCAgent(t *testing.T) {
+	RegisterRuntime("test-rpc", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperRPCAgent$")
+		cmd.Env = append(os.Environ(), "MC_TEST_RPC_AGENT=1")
+		return cmd, nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.HandleRPC("ping", func(a *Agent, params json.RawMessage) (interface{}, error) {
+		return "pong", nil
+	})
+	agent, err := m.Spawn(SpawnRequest{Type: "test-rpc", Task: "rpc", Protocol: ProtocolJSONRPC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Kill(agent.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := m.Call(ctx, agent.ID, "echo", map[string]int{"x": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != `{"x":1}` {
+		t.Errorf("echo = %s", result)
+	}
+
+	next := func(eventType string) json.RawMessage {
+		t.Helper()
+		for {
+			select {
+			case ev := <-m.Events():
+				if ev.Type == eventType {
+					return ev.Data
+				}
+			case <-ctx.Done():
+				t.Fatalf("no %s event", eventType)
+			}
+		}
+	}
+	if data := next("agent_output"); string(data) != `{"text":"line one\nline two"}` {
+		t.Errorf("first output = %s", data)
+	}
+	if data := next("agent_event"); string(data) != `{"method":"pinged","params":"pong"}` {
+		t.Errorf("agent_event = %s", data)
+	}
+
+	if err := m.SendMessage(agent.ID, "bye"); err != nil {
+		t.Fatal(err)
+	}
+	if data := next("agent_output"); string(data) != `{"text":"bye"}` {
+		t.Errorf("reply = %s", data)
+	}
+	next("agent_stopped")
+	if _, err := m.Call(ctx, agent.ID, "echo", nil); err == nil {
+		t.Error("Call after exit should fail")
+	}
+}
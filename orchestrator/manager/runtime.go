@@ -0,0 +1,196 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Worker runtimes beyond Claude Code
+const (
+	AgentTypeCodex  AgentType = "codex"  // OpenAI Codex CLI
+	AgentTypeGemini AgentType = "gemini" // Google Gemini CLI
+	AgentTypeAider  AgentType = "aider"  // aider, which commits its own edits
+)
+
+// Launch is what a runtime needs to start a worker.
+type Launch struct {
+	Task       string
+	PromptFile string   // persona instructions, or empty
+	System     string   // extra system prompt, e.g. the King's, or empty
+	Model      string   // empty = the runtime's default
+	Stream     bool     // machine-readable event output on stdout
+	Env        []string // added to the orchestrator's environment
+	ReadFiles  []string // read-only context, e.g. the briefing and specs (aider)
+	Files      []string // files to edit, e.g. the task's scope paths (aider)
+	Allowed    []string // tools Claude Code may use without asking; nil skips permission checks
+}
+
+// Runtime builds the command for one worker CLI. Workers run
+// non-interactively: the task is given on the command line.
+type Runtime interface {
+	Command(l Launch) (*exec.Cmd, error)
+}
+
+// RuntimeFunc adapts a function to Runtime.
+type RuntimeFunc func(l Launch) (*exec.Cmd, error)
+
+func (f RuntimeFunc) Command(l Launch) (*exec.Cmd, error) { return f(l) }
+
+var (
+	runtimesMu sync.RWMutex
+	runtimes   = map[AgentType]Runtime{
+		AgentTypeClaudeCode: RuntimeFunc(claudeCommand),
+		AgentTypePython:     RuntimeFunc(claudeCommand), // Deprecated: python agents run Claude Code
+		AgentTypeCodex:      RuntimeFunc(codexCommand),
+		AgentTypeGemini:     RuntimeFunc(geminiCommand),
+		AgentTypeAider:      RuntimeFunc(aiderCommand),
+	}
+)
+
+// RegisterRuntime adds or replaces the runtime for t.
+func RegisterRuntime(t AgentType, rt Runtime) {
+	runtimesMu.Lock()
+	defer runtimesMu.Unlock()
+	runtimes[t] = rt
+}
+
+// RuntimeFor returns the runtime for t; empty means Claude Code.
+func RuntimeFor(t AgentType) (Runtime, error) {
+	if t == "" {
+		t = AgentTypeClaudeCode
+	}
+	runtimesMu.RLock()
+	defer runtimesMu.RUnlock()
+	rt, ok := runtimes[t]
+	if !ok {
+		return nil, fmt.Errorf("unknown worker runtime %q (known: %v)", t, runtimeNames())
+	}
+	return rt, nil
+}
+
+// runtimeNames lists the registered runtimes; callers hold runtimesMu.
+func runtimeNames() []string {
+	names := make([]string, 0, len(runtimes))
+	for t := range runtimes {
+		if t != AgentTypePython {
+			names = append(names, string(t))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// command builds an exec.Cmd with the orchestrator's environment plus env.
+func command(bin string, args, env []string) *exec.Cmd {
+	cmd := exec.Command(bin, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd
+}
+
+// withInstructions prefixes the task with the persona prompt and the
+// system prompt, for CLIs that have no separate system prompt.
+func withInstructions(l Launch) (string, error) {
+	instructions := l.System
+	if l.PromptFile != "" {
+		data, err := os.ReadFile(l.PromptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt: %w", err)
+		}
+		instructions = strings.TrimSpace(string(data) + "\n\n" + instructions)
+	}
+	if instructions == "" {
+		return l.Task, nil
+	}
+	return instructions + "\n\n## Task\n\n" + l.Task, nil
+}
+
+// claudeCommand runs Claude Code in print mode. The persona prompt is
+// passed in CLAUDE_SYSTEM_PROMPT and the system prompt with
+// --append-system-prompt. With Allowed other tools are denied, and
+// the denials are reported as permission requests.
+func claudeCommand(l Launch) (*exec.Cmd, error) {
+	args := []string{"-p", l.Task}
+	if l.System != "" {
+		args = append(args, "--append-system-prompt", l.System)
+	}
+	if l.Stream {
+		args = append(args, "--output-format", "stream-json")
+		if l.Allowed == nil {
+			// Headless: nobody is there to answer permission prompts
+			args = append(args, "--dangerously-skip-permissions")
+		}
+	}
+	if l.Allowed != nil {
+		args = append(args, "--allowedTools", strings.Join(l.Allowed, ","))
+	}
+	if l.Model != "" {
+		args = append(args, "--model", l.Model)
+	}
+	env := l.Env
+	if l.PromptFile != "" {
+		env = append(env, "CLAUDE_SYSTEM_PROMPT="+l.PromptFile)
+	}
+	return command("claude", args, env), nil
+}
+
+// codexCommand runs `codex exec`, which works in the current directory
+// without asking for approval and, with --json, prints JSONL events.
+func codexCommand(l Launch) (*exec.Cmd, error) {
+	prompt, err := withInstructions(l)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"exec", "--full-auto", "--skip-git-repo-check"}
+	if l.Stream {
+		args = append(args, "--json")
+	}
+	if l.Model != "" {
+		args = append(args, "--model", l.Model)
+	}
+	args = append(args, prompt)
+	return command("codex", args, l.Env), nil
+}
+
+// geminiCommand runs the Gemini CLI non-interactively, accepting all tool
+// calls (--yolo).
+func geminiCommand(l Launch) (*exec.Cmd, error) {
+	prompt, err := withInstructions(l)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"--prompt", prompt, "--yolo"}
+	if l.Stream {
+		args = append(args, "--output-format", "stream-json")
+	}
+	if l.Model != "" {
+		args = append(args, "--model", l.Model)
+	}
+	return command("gemini", args, l.Env), nil
+}
+
+// aiderCommand runs aider for a single message, answering yes to its
+// questions. The persona prompt and ReadFiles are added read-only, the
+// system prompt precedes the task; aider commits its edits to the git
+// repository it runs in.
+func aiderCommand(l Launch) (*exec.Cmd, error) {
+	message := l.Task
+	if l.System != "" {
+		message = l.System + "\n\n## Task\n\n" + l.Task
+	}
+	args := []string{"--message", message, "--yes-always", "--no-pretty", "--no-stream", "--no-check-update"}
+	if l.Model != "" {
+		args = append(args, "--model", l.Model)
+	}
+	if l.PromptFile != "" {
+		args = append(args, "--read", l.PromptFile)
+	}
+	for _, f := range l.ReadFiles {
+		args = append(args, "--read", f)
+	}
+	args = append(args, l.Files...)
+	return command("aider", args, l.Env), nil
+}
@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+)
+
+func TestRuntimeCommands(t *testing.T) {
+	prompt := filepath.Join(t.TempDir(), "developer.md")
+	os.WriteFile(prompt, []byte("You are a developer."), 0644)
+	launch := Launch{Task: "Fix the bug", PromptFile: prompt, Model: "m1", Stream: true, Env: []string{"EXTRA=1"},
+		ReadFiles: []string{"spec.md"}, Files: []string{"main.go"}}
+
+	tests := []struct {
+		runtime AgentType
+		want    string
+	}{
+		{AgentTypeClaudeCode, "claude -p Fix the bug --output-format stream-json --dangerously-skip-permissions --model m1"},
+		{AgentTypeCodex, "codex exec --full-auto --skip-git-repo-check --json --model m1 You are a developer.\n\n## Task\n\nFix the bug"},
+		{AgentTypeGemini, "gemini --prompt You are a developer.\n\n## Task\n\nFix the bug --yolo --output-format stream-json --model m1"},
+		{AgentTypeAider, "aider --message Fix the bug --yes-always --no-pretty --no-stream --no-check-update --model m1 --read " + prompt + " --read spec.md main.go"},
+	}
+	for _, tt := range tests {
+		rt, err := RuntimeFor(tt.runtime)
+		if err != nil {
+			t.Fatalf("RuntimeFor(%s): %v", tt.runtime, err)
+		}
+		cmd, err := rt.Command(launch)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.runtime, err)
+		}
+		if got := strings.Join(cmd.Args, " "); got != tt.want {
+			t.Errorf("%s args = %q, want %q", tt.runtime, got, tt.want)
+		}
+		env := strings.Join(cmd.Env, "\n")
+		if !strings.Contains(env, "EXTRA=1") {
+			t.Errorf("%s: launch env not passed", tt.runtime)
+		}
+		if tt.runtime == AgentTypeClaudeCode && !strings.Contains(env, "CLAUDE_SYSTEM_PROMPT="+prompt) {
+			t.Errorf("claude: persona prompt not passed")
+		}
+	}
+
+	// A system prompt is appended to Claude Code's and precedes the task
+	// for the others
+	launch = Launch{Task: "Plan", System: "Stage: design"}
+	for runtime, want := range map[AgentType]string{
+		AgentTypeClaudeCode: "claude -p Plan --append-system-prompt Stage: design",
+		AgentTypeCodex:      "codex exec --full-auto --skip-git-repo-check Stage: design\n\n## Task\n\nPlan",
+		AgentTypeAider:      "aider --message Stage: design\n\n## Task\n\nPlan --yes-always --no-pretty --no-stream --no-check-update",
+	} {
+		rt, _ := RuntimeFor(runtime)
+		cmd, err := rt.Command(launch)
+		if err != nil {
+			t.Fatalf("%s: %v", runtime, err)
+		}
+		if got := strings.Join(cmd.Args, " "); got != want {
+			t.Errorf("%s with system prompt = %q, want %q", runtime, got, want)
+		}
+	}
+
+	// With a permissions policy Claude Code asks before other tools
+	cmd, _ := claudeCommand(Launch{Task: "Fix", Stream: true, Allowed: []string{"Read", "Bash(go test:*)"}})
+	if got := strings.Join(cmd.Args, " "); got != "claude -p Fix --output-format stream-json --allowedTools Read,Bash(go test:*)" {
+		t.Errorf("claude with allowed tools = %q", got)
+	}
+
+	if _, err := RuntimeFor("cursor"); err == nil || !strings.Contains(err.Error(), "codex") {
+		t.Errorf("expected unknown runtime error listing runtimes, got %v", err)
+	}
+}
+
+func TestSpawnUsesConfiguredRuntime(t *testing.T) {
+	var got Launch
+	RegisterRuntime("test-echo", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		got = l
+		return exec.Command("echo", l.Task), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetRuntimes(&bridge.RuntimeConfig{Personas: map[string]string{"researcher": "test-echo"}})
+
+	agent, err := m.Spawn(SpawnRequest{Task: "look around", Persona: "researcher"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if agent.Type != "test-echo" || got.Task != "look around" || !got.Stream {
+		t.Errorf("agent type %q, launch %+v", agent.Type, got)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-m.Events():
+			if ev.Type == "agent_stopped" {
+				if !strings.Contains(string(ev.Data), `"status":"stopped"`) {
+					t.Errorf("runtime's command failed: %s", ev.Data)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("runtime's command did not finish")
+		}
+	}
+}
+
+func TestSpawnInjectsEnvProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no secrets store
+	var got Launch
+	RegisterRuntime("test-env", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		got = l
+		return exec.Command("true"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetEnvProfiles(bridge.EnvProfiles{
+		"*":       {"LOG_LEVEL": "info"},
+		"backend": {"DATABASE_URL": "postgres://localhost/app"},
+	})
+
+	agent, err := m.Spawn(SpawnRequest{Type: "test-env", Task: "migrate", Zone: "backend"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	env := strings.Join(got.Env, " ")
+	if !strings.Contains(env, "DATABASE_URL=postgres://localhost/app") || !strings.Contains(env, "LOG_LEVEL=info") {
+		t.Errorf("launch env = %v, want the backend and * profiles", got.Env)
+	}
+	if agent.Env["DATABASE_URL"] != "postgres://localhost/app" || agent.Env["LOG_LEVEL"] != "info" {
+		t.Errorf("agent env record = %v", agent.Env)
+	}
+
+	m.SetEnvProfiles(bridge.EnvProfiles{"backend": {"TOKEN": "secret:NOT_STORED"}})
+	if _, err := m.Spawn(SpawnRequest{Type: "test-env", Task: "again", Zone: "backend"}); err == nil {
+		t.Errorf("spawned without its secret")
+	}
+}
+
+func TestSpawnUsesPersonaModel(t *testing.T) {
+	var got Launch
+	RegisterRuntime("test-model", RuntimeFunc(func(l Launch) (*exec.Cmd, error) {
+		got = l
+		return exec.Command("true"), nil
+	}))
+
+	m := NewManager(t.TempDir())
+	m.SetModels(&bridge.ModelConfig{
+		Default:  bridge.ModelSettings{OfflineModel: "qwen3-coder"},
+		Personas: map[string]bridge.ModelSettings{"architect": {Model: "opus", Runtimes: map[string]string{"test-model": "o4-mini"}}},
+	})
+
+	agent, err := m.Spawn(SpawnRequest{Type: "test-model", Task: "design it", Persona: "architect"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if agent.Model != "o4-mini" || got.Model != "o4-mini" {
+		t.Errorf("agent model %q, launch model %q, want the runtime's o4-mini", agent.Model, got.Model)
+	}
+
+	agent, err = m.Spawn(SpawnRequest{Type: "test-model", Task: "review it", Persona: "reviewer"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if agent.Model != "" || got.Model != "" {
+		t.Errorf("agent model %q, launch model %q, want the runtime's default", agent.Model, got.Model)
+	}
+
+	agent, err = m.Spawn(SpawnRequest{Type: "test-model", Task: "design offline", Persona: "architect", OfflineMode: true, OllamaModel: "llama3"})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	if agent.Model != "qwen3-coder" || got.Model != "qwen3-coder" {
+		t.Errorf("offline agent model %q, launch model %q, want qwen3-coder", agent.Model, got.Model)
+	}
+}
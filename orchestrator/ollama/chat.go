@@ -0,0 +1,123 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Message is one turn of a chat conversation
+type Message struct {
+	Role    string `json:"role"` // "system", "user", "assistant" or "tool"
+	Content string `json:"content"`
+}
+
+// ChatRequest is the body of POST /api/chat
+type ChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []Message              `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// ChatResponse is one line of a /api/chat response. Streaming responses
+// send a chunk per piece of output; the last has Done set and carries the
+// token counts.
+type ChatResponse struct {
+	Model           string  `json:"model"`
+	CreatedAt       string  `json:"created_at,omitempty"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason,omitempty"`
+	TotalDuration   int64   `json:"total_duration,omitempty"`
+	PromptEvalCount int     `json:"prompt_eval_count,omitempty"`
+	EvalCount       int     `json:"eval_count,omitempty"`
+}
+
+// Chat sends a chat request and calls fn with each response chunk as it
+// arrives. It returns the final chunk, whose Message holds the whole reply.
+// Generation can take minutes, so only ctx bounds the request.
+func (c *Client) Chat(ctx context.Context, req ChatRequest, fn func(ChatResponse) error) (*ChatResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			return nil, fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, e.Error)
+		}
+		return nil, fmt.Errorf("Ollama returned status %d", resp.StatusCode)
+	}
+
+	var reply bytes.Buffer
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var chunk ChatResponse
+		if err := json.Unmarshal(sc.Bytes(), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if fn != nil {
+			if err := fn(chunk); err != nil {
+				return nil, err
+			}
+		}
+		reply.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			chunk.Message.Role = "assistant"
+			chunk.Message.Content = reply.String()
+			return &chunk, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil, fmt.Errorf("Ollama response ended before completion")
+}
+
+// Warm loads model into memory, so the first real request does not pay
+// for it, and returns how long Ollama took to answer.
+func (c *Client) Warm(ctx context.Context, model string) (time.Duration, error) {
+	body, _ := json.Marshal(map[string]interface{}{"model": model, "stream": false})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Ollama returned status %d", resp.StatusCode)
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,72 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("expected path /api/chat, got %s", r.URL.Path)
+		}
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "qwen3-coder" || !req.Stream || len(req.Messages) != 1 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		fmt.Fprintln(w, `{"model":"qwen3-coder","message":{"role":"assistant","content":"Hel"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"qwen3-coder","message":{"role":"assistant","content":"lo"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"qwen3-coder","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":3}`)
+	}))
+	defer server.Close()
+
+	var chunks int
+	final, err := NewClient(server.URL).Chat(context.Background(), ChatRequest{
+		Model:    "qwen3-coder",
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Stream:   true,
+	}, func(ChatResponse) error {
+		chunks++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunks != 3 {
+		t.Errorf("expected 3 chunks, got %d", chunks)
+	}
+	if final.Message.Content != "Hello" || final.PromptEvalCount != 12 || final.EvalCount != 3 {
+		t.Errorf("unexpected final response %+v", final)
+	}
+}
+
+func TestChatErrors(t *testing.T) {
+	t.Run("reports Ollama's error message", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"nope\" not found"}`))
+		}))
+		defer server.Close()
+
+		_, err := NewClient(server.URL).Chat(context.Background(), ChatRequest{Model: "nope"}, nil)
+		if err == nil || err.Error() != `Ollama returned status 404: model "nope" not found` {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("fails on truncated stream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, `{"message":{"content":"Hel"},"done":false}`)
+		}))
+		defer server.Close()
+
+		if _, err := NewClient(server.URL).Chat(context.Background(), ChatRequest{}, nil); err == nil {
+			t.Error("expected error for a stream without a done chunk")
+		}
+	})
+}
@@ -40,12 +40,8 @@ func NewClient(baseURL string) *Client {
 
 // IsRunning checks if Ollama is accessible
 func (c *Client) IsRunning() bool {
-	resp, err := c.httpClient.Get(c.baseURL)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-	return resp.StatusCode == http.StatusOK
+	_, err := c.Ping()
+	return err == nil
 }
 
 // ListModels returns all available models from Ollama
@@ -94,3 +90,22 @@ func (c *Client) GetModelNames() ([]string, error) {
 	}
 	return names, nil
 }
+
+// BaseURL returns the Ollama server the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Ping checks that Ollama is accessible and returns the round-trip time
+func (c *Client) Ping() (time.Duration, error) {
+	start := time.Now()
+	resp, err := c.httpClient.Get(c.baseURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Ollama returned status %d", resp.StatusCode)
+	}
+	return time.Since(start), nil
+}
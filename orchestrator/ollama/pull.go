@@ -0,0 +1,78 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// PullProgress is one line of a /api/pull response: a status such as
+// "pulling manifest", "pulling <digest>" with byte counts, or "success".
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Pull downloads a model, calling fn with each progress line. It returns
+// once Ollama reports success, or the first error. Downloads can take a
+// long time, so only ctx bounds the request.
+func (c *Client) Pull(ctx context.Context, model string, fn func(PullProgress)) error {
+	body, _ := json.Marshal(map[string]interface{}{"model": model, "stream": true})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var p PullProgress
+		if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if p.Error != "" {
+			return fmt.Errorf("pull %s: %s", model, p.Error)
+		}
+		if fn != nil {
+			fn(p)
+		}
+		if p.Status == "success" {
+			return nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama returned status %d", resp.StatusCode)
+	}
+	return fmt.Errorf("pull %s: response ended before success", model)
+}
+
+// DiskUsage returns the total size in bytes of the installed models.
+func (c *Client) DiskUsage() (int64, error) {
+	models, err := c.ListModels()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, m := range models {
+		total += m.Size
+	}
+	return total, nil
+}
@@ -0,0 +1,58 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPull(t *testing.T) {
+	t.Run("reports progress until success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/pull" {
+				t.Errorf("expected path /api/pull, got %s", r.URL.Path)
+			}
+			fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+			fmt.Fprintln(w, `{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":40}`)
+			fmt.Fprintln(w, `{"status":"success"}`)
+		}))
+		defer server.Close()
+
+		var statuses []string
+		err := NewClient(server.URL).Pull(context.Background(), "qwen3-coder", func(p PullProgress) {
+			statuses = append(statuses, p.Status)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(statuses) != 3 || statuses[2] != "success" {
+			t.Errorf("unexpected progress %v", statuses)
+		}
+	})
+
+	t.Run("returns Ollama's error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, `{"error":"pull model manifest: file does not exist"}`)
+		}))
+		defer server.Close()
+
+		err := NewClient(server.URL).Pull(context.Background(), "nope", nil)
+		if err == nil || err.Error() != "pull nope: pull model manifest: file does not exist" {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestDiskUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"a","size":100},{"name":"b","size":250}]}`)
+	}))
+	defer server.Close()
+
+	usage, err := NewClient(server.URL).DiskUsage()
+	if err != nil || usage != 350 {
+		t.Errorf("DiskUsage = %d, %v", usage, err)
+	}
+}
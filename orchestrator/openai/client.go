@@ -0,0 +1,155 @@
+// Package openai is a minimal client for OpenAI-compatible chat servers
+// (LM Studio, vLLM, OpenRouter, ...), used for offline mode when a project
+// does not run Ollama.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client talks to one OpenAI-compatible server
+type Client struct {
+	baseURL string // up to and including /v1
+	apiKey  string
+}
+
+// NewClient creates a client for baseURL, e.g. "http://localhost:1234/v1".
+// apiKey may be empty for servers that do not check it.
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey}
+}
+
+// BaseURL returns the server the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Message is one turn of a chat conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Completion is a finished chat reply with its token usage
+type Completion struct {
+	Model            string `json:"model"`
+	Content          string `json:"content"`
+	FinishReason     string `json:"finish_reason,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// streamChunk is one server-sent event of a streamed chat completion
+type streamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Chat streams a chat completion, calling fn with each piece of the reply,
+// and returns the whole reply with its usage. options are merged into the
+// request body (temperature, max_tokens, ...). Servers that do not report
+// usage leave the token counts at zero.
+func (c *Client) Chat(ctx context.Context, model string, messages []Message, options map[string]interface{}, fn func(delta string) error) (*Completion, error) {
+	body := make(map[string]interface{}, len(options)+4)
+	for k, v := range options {
+		body[k] = v
+	}
+	body["model"] = model
+	body["messages"] = messages
+	body["stream"] = true
+	body["stream_options"] = map[string]bool{"include_usage": true}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &e) == nil && e.Error.Message != "" {
+			return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, e.Error.Message)
+		}
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	out := &Completion{Model: model}
+	var reply strings.Builder
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		payload, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue
+		}
+		payload = strings.TrimSpace(payload)
+		if payload == "[DONE]" {
+			out.Content = reply.String()
+			return out, nil
+		}
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.PromptTokens = chunk.Usage.PromptTokens
+			out.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != nil {
+				out.FinishReason = *choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			reply.WriteString(choice.Delta.Content)
+			if fn != nil {
+				if err := fn(choice.Delta.Content); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil, fmt.Errorf("response ended before completion")
+}
@@ -0,0 +1,63 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("expected path /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer sk-test" {
+			t.Errorf("expected bearer key, got %q", auth)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["model"] != "qwen" || body["temperature"] != 0.2 || body["stream"] != true {
+			t.Errorf("unexpected body %v", body)
+		}
+		fmt.Fprint(w, "data: {\"model\":\"qwen-32b\",\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":9,\"completion_tokens\":2}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var deltas []string
+	c, err := NewClient(server.URL+"/v1/", "sk-test").Chat(context.Background(), "qwen",
+		[]Message{{Role: "user", Content: "hi"}}, map[string]interface{}{"temperature": 0.2},
+		func(d string) error {
+			deltas = append(deltas, d)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("unexpected deltas %v", deltas)
+	}
+	if c.Content != "Hello" || c.Model != "qwen-32b" || c.FinishReason != "stop" || c.PromptTokens != 9 || c.CompletionTokens != 2 {
+		t.Errorf("unexpected completion %+v", c)
+	}
+}
+
+func TestChatError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"invalid api key"}}`)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "").Chat(context.Background(), "qwen", nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	mathrand "math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,9 +27,20 @@ const (
 	StateDisconnected State = "disconnected"
 	StateConnecting   State = "connecting"
 	StateConnected    State = "connected"
+	StateReconnecting State = "reconnecting"
 	StateError        State = "error"
 )
 
+// Reconnect and send-buffer defaults. After a drop the bridge redials
+// with jittered exponential backoff; sends made meanwhile are queued, up
+// to maxQueuedSends, and written once the connection is back.
+const (
+	minBackoff     = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+	maxQueuedSends = 64
+	sendTimeout    = 30 * time.Second
+)
+
 // Frame is a generic gateway protocol frame.
 type Frame struct {
 	Type    string          `json:"type"`
@@ -43,10 +55,15 @@ type Frame struct {
 
 // StatusInfo is returned by the /api/openclaw/status endpoint.
 type StatusInfo struct {
-	State       State      `json:"state"`
-	GatewayURL  string     `json:"gatewayUrl"`
-	Error       string     `json:"error,omitempty"`
-	ConnectedAt *time.Time `json:"connectedAt,omitempty"`
+	Gateway           string     `json:"gateway,omitempty"` // configured gateway name
+	State             State      `json:"state"`
+	GatewayURL        string     `json:"gatewayUrl"`
+	Error             string     `json:"error,omitempty"`
+	ConnectedAt       *time.Time `json:"connectedAt,omitempty"`
+	DisconnectedAt    *time.Time `json:"disconnectedAt,omitempty"`
+	ReconnectAttempts int        `json:"reconnectAttempts,omitempty"`
+	NextRetryAt       *time.Time `json:"nextRetryAt,omitempty"`
+	QueuedSends       int        `json:"queuedSends,omitempty"`
 }
 
 // deviceIdentity holds the Ed25519 keypair for device auth.
@@ -66,14 +83,30 @@ type Bridge struct {
 	conn        *websocket.Conn
 	connectedAt *time.Time
 	device      *deviceIdentity
-	stopCh      chan struct{}
+	stopCh      chan struct{} // closed when the current connection ends
 	tickMs      int
+	writeMu     sync.Mutex // gorilla connections allow one writer at a time
+
+	// Reconnection state
+	closed         bool
+	closeCh        chan struct{} // closed by Close; aborts reconnect waits
+	reconnecting   bool
+	attempts       int
+	disconnectedAt *time.Time
+	nextRetryAt    *time.Time
+	queue          []Frame // sends waiting for the connection to return
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
 
 	pending   map[string]chan *Frame
 	pendingMu sync.Mutex
 
 	// EventHandler is called for inbound events (optional).
 	EventHandler func(event string, payload json.RawMessage)
+
+	// StateHandler is called when the connection comes up or drops
+	// (optional).
+	StateHandler func(status StatusInfo)
 }
 
 // NewBridge creates a new OpenClaw bridge (does not connect yet).
@@ -86,6 +119,9 @@ func NewBridge(gatewayURL, token string) *Bridge {
 		device:     device,
 		pending:    make(map[string]chan *Frame),
 		tickMs:     15000,
+		closeCh:    make(chan struct{}),
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
 	}
 }
 
@@ -93,12 +129,31 @@ func NewBridge(gatewayURL, token string) *Bridge {
 func (b *Bridge) Status() StatusInfo {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
+	return b.statusLocked()
+}
+
+func (b *Bridge) statusLocked() StatusInfo {
 	return StatusInfo{
-		State:       b.state,
-		GatewayURL:  b.gatewayURL,
-		Error:       b.err,
-		ConnectedAt: b.connectedAt,
+		State:             b.state,
+		GatewayURL:        b.gatewayURL,
+		Error:             b.err,
+		ConnectedAt:       b.connectedAt,
+		DisconnectedAt:    b.disconnectedAt,
+		ReconnectAttempts: b.attempts,
+		NextRetryAt:       b.nextRetryAt,
+		QueuedSends:       len(b.queue),
+	}
+}
+
+// Start connects to the gateway. If the first attempt fails the bridge
+// keeps retrying in the background, as it does after a dropped
+// connection; the error is returned so the caller can report it.
+func (b *Bridge) Start() error {
+	err := b.Connect()
+	if err != nil {
+		b.scheduleReconnect()
 	}
+	return err
 }
 
 // Connect dials the gateway and performs the protocol handshake.
@@ -218,70 +273,132 @@ func (b *Bridge) Connect() error {
 	}
 
 	now := time.Now()
+	stop := make(chan struct{})
 	b.mu.Lock()
 	b.state = StateConnected
 	b.connectedAt = &now
+	b.attempts = 0
+	b.nextRetryAt = nil
+	b.stopCh = stop
+	queued := b.queue
+	b.queue = nil
+	status := b.statusLocked()
 	b.mu.Unlock()
 
 	log.Printf("[openclaw] connected to %s (device=%s, tick=%dms)", b.gatewayURL, b.device.DeviceID, b.tickMs)
 
-	b.stopCh = make(chan struct{})
-	go b.readLoop()
-	go b.tickLoop()
+	go b.readLoop(conn, stop)
+	go b.tickLoop(conn, stop)
+
+	// Deliver sends made while disconnected
+	for _, frame := range queued {
+		if err := b.write(conn, frame); err != nil {
+			log.Printf("[openclaw] failed to flush queued %s: %v", frame.Method, err)
+		}
+	}
+	if len(queued) > 0 {
+		log.Printf("[openclaw] flushed %d queued sends", len(queued))
+	}
 
+	if b.StateHandler != nil {
+		b.StateHandler(status)
+	}
 	return nil
 }
 
-// Send sends a request and waits for a response (up to 30s).
+// Send sends a request and waits for a response (up to 30s). While the
+// bridge is reconnecting the request is queued and written once the
+// connection is back; it fails at once if the queue is full.
 func (b *Bridge) Send(method string, params interface{}) (*Frame, error) {
-	b.mu.RLock()
-	conn := b.conn
-	state := b.state
-	b.mu.RUnlock()
-
-	if state != StateConnected || conn == nil {
-		return nil, fmt.Errorf("not connected (state=%s)", state)
-	}
-
 	reqID := randomID()
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
 		return nil, err
 	}
+	frame := Frame{
+		Type:   "req",
+		ID:     reqID,
+		Method: method,
+		Params: paramsJSON,
+	}
 
 	ch := make(chan *Frame, 1)
 	b.pendingMu.Lock()
 	b.pending[reqID] = ch
 	b.pendingMu.Unlock()
 
-	frame := Frame{
-		Type:   "req",
-		ID:     reqID,
-		Method: method,
-		Params: paramsJSON,
+	b.mu.Lock()
+	conn := b.conn
+	state := b.state
+	queued := false
+	if state != StateConnected || conn == nil {
+		switch {
+		case !b.reconnecting:
+			err = fmt.Errorf("not connected (state=%s)", state)
+		case len(b.queue) >= maxQueuedSends:
+			err = fmt.Errorf("not connected (state=%s) and %d sends already queued", state, len(b.queue))
+		default:
+			b.queue = append(b.queue, frame)
+			queued = true
+		}
+	}
+	b.mu.Unlock()
+
+	if err == nil && !queued {
+		err = b.write(conn, frame)
 	}
-	if err := conn.WriteJSON(frame); err != nil {
-		b.pendingMu.Lock()
-		delete(b.pending, reqID)
-		b.pendingMu.Unlock()
+	if err != nil {
+		b.dropPending(reqID)
 		return nil, err
 	}
 
 	select {
 	case resp := <-ch:
 		return resp, nil
-	case <-time.After(30 * time.Second):
-		b.pendingMu.Lock()
-		delete(b.pending, reqID)
-		b.pendingMu.Unlock()
+	case <-time.After(sendTimeout):
+		b.dropPending(reqID)
+		if queued {
+			b.dequeue(reqID)
+			return nil, fmt.Errorf("timeout waiting for the gateway to reconnect for %s", method)
+		}
 		return nil, fmt.Errorf("timeout waiting for response to %s", method)
 	}
 }
 
-// Close shuts down the bridge.
-func (b *Bridge) Close() {
+// write sends one frame on conn.
+func (b *Bridge) write(conn *websocket.Conn, frame Frame) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	return conn.WriteJSON(frame)
+}
+
+func (b *Bridge) dropPending(reqID string) {
+	b.pendingMu.Lock()
+	delete(b.pending, reqID)
+	b.pendingMu.Unlock()
+}
+
+// dequeue removes a queued send that timed out before it was written.
+func (b *Bridge) dequeue(reqID string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	for i, f := range b.queue {
+		if f.ID == reqID {
+			b.queue = append(b.queue[:i], b.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close shuts down the bridge and stops reconnecting. Queued sends fail.
+func (b *Bridge) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		if b.closeCh != nil {
+			close(b.closeCh)
+		}
+	}
 	if b.stopCh != nil {
 		close(b.stopCh)
 		b.stopCh = nil
@@ -291,19 +408,48 @@ func (b *Bridge) Close() {
 		b.conn = nil
 	}
 	b.state = StateDisconnected
+	queued := queuedIDs(b.queue)
+	b.queue = nil
+	b.mu.Unlock()
+
+	b.failPending(func(id string) bool { return queued[id] }, "bridge closed")
+}
+
+// queuedIDs returns the request IDs of queue.
+func queuedIDs(queue []Frame) map[string]bool {
+	ids := make(map[string]bool, len(queue))
+	for _, f := range queue {
+		ids[f.ID] = true
+	}
+	return ids
+}
+
+// failPending answers the pending requests match picks with an error
+// response carrying msg, so their senders return at once.
+func (b *Bridge) failPending(match func(id string) bool, msg string) {
+	notOK := false
+	errJSON, _ := json.Marshal(msg)
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	for id, ch := range b.pending {
+		if match(id) {
+			ch <- &Frame{Type: "res", ID: id, OK: &notOK, Error: errJSON}
+			delete(b.pending, id)
+		}
+	}
 }
 
-func (b *Bridge) readLoop() {
+func (b *Bridge) readLoop(conn *websocket.Conn, stop chan struct{}) {
 	for {
 		var frame Frame
-		if err := b.conn.ReadJSON(&frame); err != nil {
+		if err := conn.ReadJSON(&frame); err != nil {
 			select {
-			case <-b.stopCh:
+			case <-stop:
 				return
 			default:
 			}
 			log.Printf("[openclaw] read error: %v", err)
-			b.setError(fmt.Sprintf("read: %v", err))
+			b.dropped(stop, fmt.Sprintf("read: %v", err))
 			return
 		}
 
@@ -323,27 +469,126 @@ func (b *Bridge) readLoop() {
 	}
 }
 
-func (b *Bridge) tickLoop() {
+func (b *Bridge) tickLoop(conn *websocket.Conn, stop chan struct{}) {
 	ticker := time.NewTicker(time.Duration(b.tickMs) * time.Millisecond)
 	defer ticker.Stop()
 	for {
 		select {
-		case <-b.stopCh:
+		case <-stop:
 			return
 		case <-ticker.C:
-			b.mu.RLock()
-			conn := b.conn
-			b.mu.RUnlock()
-			if conn != nil {
-				tick := Frame{Type: "req", ID: randomID(), Method: "tick"}
-				if err := conn.WriteJSON(tick); err != nil {
-					log.Printf("[openclaw] tick error: %v", err)
-				}
+			tick := Frame{Type: "req", ID: randomID(), Method: "tick"}
+			if err := b.write(conn, tick); err != nil {
+				log.Printf("[openclaw] tick error: %v", err)
 			}
 		}
 	}
 }
 
+// dropped handles the loss of the connection whose loops watch stop: it
+// closes the connection, fails the requests already written on it,
+// records the error, reports the disconnect and starts reconnecting.
+// Queued sends wait for the new connection.
+func (b *Bridge) dropped(stop chan struct{}, msg string) {
+	b.mu.Lock()
+	if b.closed || b.stopCh != stop {
+		b.mu.Unlock()
+		return
+	}
+	close(stop)
+	b.stopCh = nil
+	conn := b.conn
+	b.conn = nil
+	queued := queuedIDs(b.queue)
+	b.failPending(func(id string) bool { return !queued[id] }, "connection lost")
+	now := time.Now()
+	b.disconnectedAt = &now
+	// Mark reconnecting before the state changes so sends made from now
+	// on are queued rather than refused.
+	start := !b.reconnecting
+	b.reconnecting = true
+	b.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+	b.setError(msg)
+	if b.StateHandler != nil {
+		b.StateHandler(b.Status())
+	}
+	if start {
+		go b.reconnectLoop()
+	}
+}
+
+// scheduleReconnect starts the reconnect loop unless one is running.
+func (b *Bridge) scheduleReconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || b.reconnecting {
+		return
+	}
+	b.reconnecting = true
+	go b.reconnectLoop()
+}
+
+// reconnectLoop redials with jittered exponential backoff until a
+// connection succeeds or the bridge is closed.
+func (b *Bridge) reconnectLoop() {
+	defer func() {
+		b.mu.Lock()
+		b.reconnecting = false
+		b.mu.Unlock()
+	}()
+
+	for attempt := 1; ; attempt++ {
+		delay := b.backoff(attempt)
+		next := time.Now().Add(delay)
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return
+		}
+		b.state = StateReconnecting
+		b.attempts = attempt
+		b.nextRetryAt = &next
+		b.mu.Unlock()
+
+		select {
+		case <-b.closeCh:
+			return
+		case <-time.After(delay):
+		}
+
+		log.Printf("[openclaw] reconnecting to %s (attempt %d)", b.gatewayURL, attempt)
+		if err := b.Connect(); err == nil {
+			return
+		}
+	}
+}
+
+// backoff returns the wait before the given attempt: doubling from
+// minBackoff up to maxBackoff, with the upper half randomised so
+// orchestrators that lost the gateway together do not redial in step.
+func (b *Bridge) backoff(attempt int) time.Duration {
+	lo, hi := b.minBackoff, b.maxBackoff
+	if lo <= 0 {
+		lo = minBackoff
+	}
+	if hi < lo {
+		hi = lo
+	}
+	d := lo
+	for i := 1; i < attempt && d < hi; i++ {
+		d *= 2
+	}
+	if d > hi {
+		d = hi
+	}
+	half := d / 2
+	return half + time.Duration(mathrand.Int63n(int64(half)+1))
+}
+
 func (b *Bridge) setError(msg string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -0,0 +1,151 @@
+package openclaw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestBackoffGrowsWithJitter(t *testing.T) {
+	b := &Bridge{minBackoff: time.Second, maxBackoff: 8 * time.Second}
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 10: 8 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := b.backoff(attempt); d < max/2 || d > max {
+				t.Fatalf("attempt %d: backoff %v outside [%v, %v]", attempt, d, max/2, max)
+			}
+		}
+	}
+}
+
+func TestBridgeReconnects(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// The first connection is dropped after the handshake; later ones
+	// answer echo requests.
+	var conns int32
+	drop := make(chan struct{})
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&conns, 1)
+		conn.WriteJSON(Frame{Type: "event", Event: "connect.challenge", Payload: json.RawMessage(`{}`)})
+		ok := true
+		var req Frame
+		if conn.ReadJSON(&req) != nil {
+			return
+		}
+		conn.WriteJSON(Frame{Type: "res", ID: req.ID, OK: &ok, Payload: json.RawMessage(`{}`)})
+		if n == 1 {
+			<-drop
+			return
+		}
+		for conn.ReadJSON(&req) == nil {
+			conn.WriteJSON(Frame{Type: "res", ID: req.ID, OK: &ok, Payload: json.RawMessage(`"` + req.Method + `"`)})
+		}
+	}))
+	defer gw.Close()
+
+	bridge := NewBridge("ws"+strings.TrimPrefix(gw.URL, "http"), "token")
+	bridge.minBackoff, bridge.maxBackoff = 20*time.Millisecond, 40*time.Millisecond
+	hub := &mockBroadcaster{}
+	NewHandler(bridge, hub)
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer bridge.Close()
+
+	close(drop)
+	deadline := time.Now().Add(2 * time.Second)
+	for bridge.Status().State == StateConnected && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Sent while down: queued, then answered on the new connection.
+	resp, err := bridge.Send("echo", nil)
+	if err != nil || string(resp.Payload) != `"echo"` {
+		t.Fatalf("Send during reconnect = %v, %v", resp, err)
+	}
+	if st := bridge.Status(); st.State != StateConnected || st.DisconnectedAt == nil || st.ReconnectAttempts != 0 {
+		t.Errorf("status after reconnect = %+v", st)
+	}
+
+	var types []string
+	for _, ev := range hub.getEvents() {
+		if ev.Topic == "openclaw" {
+			types = append(types, ev.EventType)
+		}
+	}
+	want := "openclaw_connected,openclaw_disconnected,openclaw_connected"
+	if strings.Join(types, ",") != want {
+		t.Errorf("state events = %v, want %s", types, want)
+	}
+}
+
+func TestSendNotConnectedWithoutReconnect(t *testing.T) {
+	b := &Bridge{state: StateDisconnected, pending: make(map[string]chan *Frame)}
+	if _, err := b.Send("echo", nil); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+	if len(b.pending) != 0 {
+		t.Errorf("pending request leaked")
+	}
+}
+
+func TestSendFailsWhenConnectionDrops(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// The gateway completes the handshake, reads one request and hangs up
+	// without answering it.
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteJSON(Frame{Type: "event", Event: "connect.challenge", Payload: json.RawMessage(`{}`)})
+		ok := true
+		var req Frame
+		if conn.ReadJSON(&req) != nil {
+			return
+		}
+		conn.WriteJSON(Frame{Type: "res", ID: req.ID, OK: &ok, Payload: json.RawMessage(`{}`)})
+		conn.ReadJSON(&req)
+	}))
+	defer gw.Close()
+
+	bridge := NewBridge("ws"+strings.TrimPrefix(gw.URL, "http"), "token")
+	bridge.minBackoff, bridge.maxBackoff = time.Minute, time.Minute
+	NewHandler(bridge, &mockBroadcaster{})
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer bridge.Close()
+
+	start := time.Now()
+	resp, err := bridge.Send("echo", nil)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.OK == nil || *resp.OK || !strings.Contains(string(resp.Error), "connection lost") {
+		t.Errorf("response = %+v, want a connection lost failure", resp)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Send took %v to fail", d)
+	}
+	bridge.pendingMu.Lock()
+	defer bridge.pendingMu.Unlock()
+	if len(bridge.pending) != 0 {
+		t.Errorf("pending requests left after the drop: %d", len(bridge.pending))
+	}
+}
@@ -0,0 +1,249 @@
+package openclaw
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+// DefaultGateway names the bridge passed to NewHandler, and the gateway
+// configured by OPENCLAW_GATEWAY/OPENCLAW_TOKEN.
+const DefaultGateway = "default"
+
+// GatewayConfig is one named gateway in .mission/config.json. The token
+// is never written there, as config.json may be shared through mc sync:
+// it comes from the token_env variable or a "secret:NAME" reference to
+// the secrets store.
+type GatewayConfig struct {
+	URL      string `json:"url"`
+	TokenEnv string `json:"token_env,omitempty"`
+	TokenRef string `json:"token,omitempty"` // "secret:NAME"; plain tokens are refused
+	Token    string `json:"-"`               // set by Resolve
+}
+
+// GatewaysConfig is the "openclaw" section of .mission/config.json:
+//
+//	"openclaw": {
+//	  "default": "local",
+//	  "gateways": {
+//	    "local":  {"url": "ws://127.0.0.1:18789", "token_env": "OPENCLAW_TOKEN"},
+//	    "remote": {"url": "wss://gpu.example.com/gw", "token_env": "OPENCLAW_REMOTE_TOKEN"}
+//	  },
+//	  "routes": {"architect": "remote", "researcher": "remote"}
+//	}
+//
+// Routes send workers of a persona to a gateway; other personas, and the
+// King's chat, use the default.
+type GatewaysConfig struct {
+	Default  string                   `json:"default,omitempty"`
+	Gateways map[string]GatewayConfig `json:"gateways,omitempty"`
+	Routes   map[string]string        `json:"routes,omitempty"`
+}
+
+// LoadGatewaysConfig reads the openclaw section of config.json in
+// missionDir (the .mission directory). A missing file or section yields
+// an empty config.
+func LoadGatewaysConfig(missionDir string) (GatewaysConfig, error) {
+	var file struct {
+		OpenClaw GatewaysConfig `json:"openclaw"`
+	}
+	data, err := os.ReadFile(filepath.Join(missionDir, "config.json"))
+	if os.IsNotExist(err) {
+		return GatewaysConfig{}, nil
+	}
+	if err != nil {
+		return GatewaysConfig{}, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return GatewaysConfig{}, fmt.Errorf("config.json: %w", err)
+	}
+	return file.OpenClaw, nil
+}
+
+// Resolve reads tokens from secret references and token_env variables,
+// falls back to OPENCLAW_GATEWAY and OPENCLAW_TOKEN when no usable gateway
+// is configured, picks the default and keeps the routes that name a
+// gateway. Gateways without a url or a token, or with a token given
+// inline, and routes to unknown gateways, are skipped and returned as
+// problems for the caller to log; the rest keep working.
+func (c GatewaysConfig) Resolve(getenv func(string) string) (GatewaysConfig, []error) {
+	out := GatewaysConfig{
+		Default:  c.Default,
+		Gateways: make(map[string]GatewayConfig),
+		Routes:   make(map[string]string),
+	}
+	var problems []error
+	for _, name := range sortedNames(c.Gateways) {
+		g := c.Gateways[name]
+		if g.TokenRef != "" {
+			if !strings.HasPrefix(g.TokenRef, secrets.RefPrefix) {
+				problems = append(problems, fmt.Errorf("openclaw gateway %q skipped: its token must be a %sNAME reference or token_env, not the token itself", name, secrets.RefPrefix))
+				continue
+			}
+			token, err := secrets.Resolve(g.TokenRef)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("openclaw gateway %q skipped: %w", name, err))
+				continue
+			}
+			g.Token = token
+		} else if g.TokenEnv != "" {
+			g.Token = getenv(g.TokenEnv)
+		}
+		if g.URL == "" || g.Token == "" {
+			problems = append(problems, fmt.Errorf("openclaw gateway %q skipped: it needs a url and a token from token_env", name))
+			continue
+		}
+		out.Gateways[name] = g
+	}
+	if len(out.Gateways) == 0 {
+		url, token := getenv("OPENCLAW_GATEWAY"), getenv("OPENCLAW_TOKEN")
+		if url == "" || token == "" {
+			out.Default = ""
+			return out, problems
+		}
+		out.Gateways[DefaultGateway] = GatewayConfig{URL: url, Token: token}
+	}
+
+	if _, ok := out.Gateways[out.Default]; !ok && out.Default != "" {
+		problems = append(problems, fmt.Errorf("openclaw default gateway %q is not configured", out.Default))
+		out.Default = ""
+	}
+	if out.Default == "" {
+		names := sortedNames(out.Gateways)
+		out.Default = names[0]
+		if _, ok := out.Gateways[DefaultGateway]; ok {
+			out.Default = DefaultGateway
+		} else if len(names) > 1 {
+			problems = append(problems, fmt.Errorf("openclaw has no default gateway; using %q", out.Default))
+		}
+	}
+	for persona, name := range c.Routes {
+		if _, ok := out.Gateways[name]; !ok {
+			problems = append(problems, fmt.Errorf("openclaw route for persona %q skipped: unknown gateway %q", persona, name))
+			continue
+		}
+		out.Routes[persona] = name
+	}
+	return out, problems
+}
+
+func sortedNames(gateways map[string]GatewayConfig) []string {
+	names := make([]string, 0, len(gateways))
+	for name := range gateways {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetGateways makes the handler route across several gateways. bridges
+// must include the handler's own bridge under defaultName; the others are
+// attached so their events are handled too.
+func (h *Handler) SetGateways(defaultName string, bridges map[string]*Bridge, routes map[string]string) error {
+	def, ok := bridges[defaultName]
+	if !ok {
+		return fmt.Errorf("default gateway %q has no bridge", defaultName)
+	}
+	if def != h.bridge {
+		return fmt.Errorf("gateway %q is not the handler's bridge", defaultName)
+	}
+	for persona, name := range routes {
+		if _, ok := bridges[name]; !ok {
+			return fmt.Errorf("route for persona %q names unknown gateway %q", persona, name)
+		}
+	}
+
+	h.gatewaysMu.Lock()
+	h.gateways = make(map[string]*Bridge, len(bridges))
+	for name, b := range bridges {
+		h.gateways[name] = b
+	}
+	h.defaultGateway = defaultName
+	h.routes = routes
+	h.gatewaysMu.Unlock()
+
+	for _, b := range bridges {
+		if b != def {
+			h.attach(b)
+		}
+	}
+	return nil
+}
+
+// gatewayFor picks the gateway for a worker: the one requested, else the
+// persona's route, else the default. An unknown requested gateway is an
+// error.
+func (h *Handler) gatewayFor(requested, persona string) (string, *Bridge, error) {
+	h.gatewaysMu.RLock()
+	defer h.gatewaysMu.RUnlock()
+	name := requested
+	if name == "" {
+		name = h.routes[persona]
+	}
+	if name == "" {
+		name = h.defaultGateway
+	}
+	b, ok := h.gateways[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown gateway %q", name)
+	}
+	return name, b, nil
+}
+
+// bridgeFor returns a worker's gateway, falling back to the default.
+func (h *Handler) bridgeFor(name string) *Bridge {
+	h.gatewaysMu.RLock()
+	defer h.gatewaysMu.RUnlock()
+	if b, ok := h.gateways[name]; ok {
+		return b
+	}
+	return h.bridge
+}
+
+// gatewayName returns the configured name of b.
+func (h *Handler) gatewayName(b *Bridge) string {
+	h.gatewaysMu.RLock()
+	defer h.gatewaysMu.RUnlock()
+	for name, gb := range h.gateways {
+		if gb == b {
+			return name
+		}
+	}
+	return ""
+}
+
+// gatewayStatus is /api/openclaw/status: the default gateway's status at
+// the top level, as before, plus every gateway and the persona routes when
+// more than one is configured.
+type gatewayStatus struct {
+	StatusInfo
+	Gateways []StatusInfo      `json:"gateways,omitempty"`
+	Routes   map[string]string `json:"routes,omitempty"`
+}
+
+func (h *Handler) status() gatewayStatus {
+	h.gatewaysMu.RLock()
+	defer h.gatewaysMu.RUnlock()
+
+	st := gatewayStatus{StatusInfo: h.bridge.Status()}
+	st.Gateway = h.defaultGateway
+	if len(h.gateways) > 1 {
+		names := make([]string, 0, len(h.gateways))
+		for name := range h.gateways {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			gs := h.gateways[name].Status()
+			gs.Gateway = name
+			st.Gateways = append(st.Gateways, gs)
+		}
+		st.Routes = h.routes
+	}
+	return st
+}
@@ -0,0 +1,162 @@
+package openclaw
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func TestResolveGateways(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{
+		"mode": "online",
+		"openclaw": {
+			"default": "local",
+			"gateways": {
+				"local":  {"url": "ws://127.0.0.1:18789", "token_env": "LOCAL_TOKEN"},
+				"remote": {"url": "wss://gpu.example.com", "token_env": "REMOTE_TOKEN"}
+			},
+			"routes": {"architect": "remote"}
+		}
+	}`), 0644)
+
+	cfg, err := LoadGatewaysConfig(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got, problems := cfg.Resolve(env(map[string]string{"LOCAL_TOKEN": "tok", "REMOTE_TOKEN": "rtok"}))
+	if len(problems) != 0 {
+		t.Fatalf("Resolve: %v", problems)
+	}
+	if got.Default != "local" || got.Gateways["local"].Token != "tok" || got.Gateways["remote"].Token != "rtok" || got.Routes["architect"] != "remote" {
+		t.Errorf("resolved = %+v", got)
+	}
+
+	// A gateway without a token is skipped, along with its routes
+	got, problems = cfg.Resolve(env(map[string]string{"LOCAL_TOKEN": "tok"}))
+	if len(problems) != 2 || len(got.Gateways) != 1 || got.Default != "local" || len(got.Routes) != 0 {
+		t.Errorf("resolved = %+v, problems = %v", got, problems)
+	}
+	cfg.Routes["tester"] = "nowhere"
+	got, problems = cfg.Resolve(env(map[string]string{"LOCAL_TOKEN": "tok", "REMOTE_TOKEN": "rtok"}))
+	if len(problems) != 1 || got.Routes["architect"] != "remote" || got.Routes["tester"] != "" {
+		t.Errorf("resolved = %+v, problems = %v", got, problems)
+	}
+}
+
+func TestResolveGatewaysFromEnv(t *testing.T) {
+	cfg, err := LoadGatewaysConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got, problems := cfg.Resolve(env(map[string]string{"OPENCLAW_GATEWAY": "ws://gw", "OPENCLAW_TOKEN": "t"}))
+	if len(problems) != 0 || got.Default != DefaultGateway || got.Gateways[DefaultGateway].URL != "ws://gw" {
+		t.Errorf("from env = %+v, %v", got, problems)
+	}
+	if got, _ := cfg.Resolve(env(nil)); len(got.Gateways) != 0 {
+		t.Errorf("expected no gateways, got %+v", got)
+	}
+}
+
+func TestResolveGatewaysKeepsEnvWhenConfigIsBad(t *testing.T) {
+	vars := env(map[string]string{"OPENCLAW_GATEWAY": "ws://gw", "OPENCLAW_TOKEN": "t"})
+
+	// Every configured gateway unusable: inline tokens aren't read
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"openclaw": {"default": "local", "gateways": {"local": {"url": "ws://127.0.0.1:18789", "token": "plain"}}}}`), 0644)
+	cfg, err := LoadGatewaysConfig(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got, problems := cfg.Resolve(vars)
+	if len(problems) != 2 || got.Default != DefaultGateway || got.Gateways[DefaultGateway].URL != "ws://gw" {
+		t.Errorf("resolved = %+v, problems = %v", got, problems)
+	}
+
+	// config.json that doesn't parse
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"openclaw": `), 0644)
+	cfg, err = LoadGatewaysConfig(dir)
+	if err == nil {
+		t.Fatal("expected a parse error")
+	}
+	if got, _ := cfg.Resolve(vars); got.Gateways[DefaultGateway].URL != "ws://gw" {
+		t.Errorf("resolved = %+v", got)
+	}
+}
+
+func TestWorkerRegisterRoutesByPersona(t *testing.T) {
+	local := &Bridge{gatewayURL: "ws://local", state: StateDisconnected, pending: make(map[string]chan *Frame)}
+	remote := &Bridge{gatewayURL: "wss://remote", state: StateDisconnected, pending: make(map[string]chan *Frame)}
+	h := NewHandler(local, nil)
+	if err := h.SetGateways("local", map[string]*Bridge{"local": local, "remote": remote}, map[string]string{"architect": "remote"}); err != nil {
+		t.Fatalf("SetGateways: %v", err)
+	}
+	mux := http.NewServeMux()
+	h.RegisterMCRoutes(mux)
+	h.RegisterRoutes(mux)
+
+	register := func(body string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/mc/worker/register", bytes.NewReader([]byte(body))))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	if _, resp := register(`{"label":"w1","persona":"architect"}`); resp["gateway"] != "remote" || resp["gateway_url"] != "wss://remote" {
+		t.Errorf("architect routed to %v", resp)
+	}
+	if _, resp := register(`{"label":"w2","persona":"developer"}`); resp["gateway"] != "local" {
+		t.Errorf("developer routed to %v", resp)
+	}
+	if _, resp := register(`{"label":"w3","persona":"architect","gateway":"local"}`); resp["gateway"] != "local" {
+		t.Errorf("explicit gateway ignored: %v", resp)
+	}
+	if code, _ := register(`{"label":"w4","gateway":"mars"}`); code != http.StatusBadRequest {
+		t.Errorf("unknown gateway: expected 400, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openclaw/status", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `"gateway":"local"`) || !strings.Contains(body, `"gatewayUrl":"wss://remote"`) || !strings.Contains(body, `"routes":{"architect":"remote"}`) {
+		t.Errorf("status = %s", body)
+	}
+}
+
+func TestResolveGatewayTokenFromSecret(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	store, err := secrets.Open(filepath.Join(home, ".mission-control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("GW_TOKEN", "tok-from-store"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := GatewaysConfig{Gateways: map[string]GatewayConfig{
+		"local":   {URL: "ws://127.0.0.1:18789", TokenRef: "secret:GW_TOKEN"},
+		"missing": {URL: "ws://127.0.0.1:18790", TokenRef: "secret:NOPE"},
+	}}
+	got, problems := cfg.Resolve(env(nil))
+	if got.Gateways["local"].Token != "tok-from-store" {
+		t.Errorf("resolved = %+v", got)
+	}
+	if _, ok := got.Gateways["missing"]; ok || len(problems) != 1 {
+		t.Errorf("expected the unknown secret skipped, got %+v, %v", got, problems)
+	}
+}
@@ -2,6 +2,7 @@ package openclaw
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"regexp"
@@ -10,6 +11,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/spend"
 	"github.com/MikeSquared-Agency/MissionControl/tracker"
 )
 
@@ -28,19 +32,35 @@ type WorkerMeta struct {
 	Persona      string    `json:"persona"`
 	Zone         string    `json:"zone"`
 	Model        string    `json:"model"`
+	Gateway      string    `json:"gateway,omitempty"`
 	RegisteredAt time.Time `json:"registered_at"`
 }
 
 // Handler exposes REST endpoints for the OpenClaw bridge.
 type Handler struct {
-	bridge  *Bridge
-	hub     Broadcaster
-	tracker *tracker.Tracker
+	bridge     *Bridge // default gateway
+	hub        Broadcaster
+	tracker    *tracker.Tracker
+	history    *chat.Store // nil: chat is not persisted
+	transcript *chat.Store // King transcript; nil: not kept
+
+	// Model for workers registered without one, by persona and zone; nil: none
+	models func(persona, zone string) string
+
+	// Named gateways (including the default) and persona → gateway routes
+	gateways       map[string]*Bridge
+	defaultGateway string
+	routes         map[string]string
+	gatewaysMu     sync.RWMutex
 
 	// Pending chat responses keyed by runId
 	chatWaiters   map[string]chan string
 	chatWaitersMu sync.Mutex
 
+	// Streaming chat responses keyed by runId
+	chatStreams   map[string]chan chatUpdate
+	chatStreamsMu sync.Mutex
+
 	// Worker registry: label → WorkerMeta (registered before spawn)
 	workerRegistry   map[string]*WorkerMeta
 	workerRegistryMu sync.RWMutex
@@ -77,6 +97,7 @@ func NewHandler(bridge *Bridge, hub Broadcaster, trk ...*tracker.Tracker) *Handl
 		bridge:          bridge,
 		hub:             hub,
 		chatWaiters:     make(map[string]chan string),
+		chatStreams:     make(map[string]chan chatUpdate),
 		workerRegistry:  make(map[string]*WorkerMeta),
 		sessionToLabel:  make(map[string]string),
 		runToSession:    make(map[string]string),
@@ -84,11 +105,25 @@ func NewHandler(bridge *Bridge, hub Broadcaster, trk ...*tracker.Tracker) *Handl
 		pendingEnds:     make(map[string]*agentEventPayload),
 		processedEvents: make(map[string]bool),
 		stopCh:          make(chan struct{}),
+		gateways:        map[string]*Bridge{DefaultGateway: bridge},
+		defaultGateway:  DefaultGateway,
 	}
 	if len(trk) > 0 && trk[0] != nil {
 		h.tracker = trk[0]
+		h.tracker.OnReap(h.handleWorkerReaped)
 	}
 
+	h.attach(bridge)
+
+	// Start cleanup goroutine for stale pending starts
+	go h.cleanupPendingStarts()
+
+	return h
+}
+
+// attach routes a gateway's events through the handler: chat replies,
+// partial output, sub-agent lifecycle and connection state.
+func (h *Handler) attach(bridge *Bridge) {
 	// Listen for events from the bridge to capture chat responses
 	prevHandler := bridge.EventHandler
 	bridge.EventHandler = func(event string, payload json.RawMessage) {
@@ -126,20 +161,24 @@ func NewHandler(bridge *Bridge, hub Broadcaster, trk ...*tracker.Tracker) *Handl
 				if text != "" {
 					// Try to parse token usage from subagent sessions
 					h.tryParseTokens(msg.SessionKey, text)
+					h.appendWorkerLog(msg.SessionKey, text)
 
 					// Broadcast to WebSocket hub for real-time UI
+					ts := time.Now().UTC().Format(time.RFC3339)
 					if h.hub != nil {
 						h.hub.BroadcastRaw("chat", "chat_message", map[string]interface{}{
 							"id":        msg.RunID,
 							"role":      "assistant",
 							"content":   text,
-							"timestamp": time.Now().UTC().Format(time.RFC3339),
+							"timestamp": ts,
 							"event":     event,
 						})
 					}
+					h.recordChat(msg.SessionKey, "assistant", text, ts, msg.RunID)
 
-					// Resolve pending HTTP waiter
+					// Resolve pending HTTP waiter or stream
 					if msg.RunID != "" {
+						h.sendStreamUpdate(msg.RunID, chatUpdate{Text: text, Final: true})
 						h.chatWaitersMu.Lock()
 						if ch, ok := h.chatWaiters[msg.RunID]; ok {
 							select {
@@ -153,6 +192,16 @@ func NewHandler(bridge *Bridge, hub Broadcaster, trk ...*tracker.Tracker) *Handl
 			}
 		}
 
+		// Forward partial output to streaming chats
+		if event == "chat" || event == "agent" {
+			h.forwardPartial(event, payload)
+		}
+
+		// Any sub-agent traffic is a keepalive
+		if event == "chat" || event == "agent" {
+			h.keepalive(payload)
+		}
+
 		// Handle lifecycle events from sub-agents
 		if event == "agent" {
 			h.handleLifecycleEvent(payload)
@@ -164,10 +213,21 @@ func NewHandler(bridge *Bridge, hub Broadcaster, trk ...*tracker.Tracker) *Handl
 		}
 	}
 
-	// Start cleanup goroutine for stale pending starts
-	go h.cleanupPendingStarts()
-
-	return h
+	// Broadcast connection state changes
+	prevState := bridge.StateHandler
+	bridge.StateHandler = func(status StatusInfo) {
+		if h.hub != nil {
+			eventType := "openclaw_disconnected"
+			if status.State == StateConnected {
+				eventType = "openclaw_connected"
+			}
+			status.Gateway = h.gatewayName(bridge)
+			h.hub.BroadcastRaw("openclaw", eventType, status)
+		}
+		if prevState != nil {
+			prevState(status)
+		}
+	}
 }
 
 // agentEventPayload represents a lifecycle event from the gateway.
@@ -374,6 +434,45 @@ func (h *Handler) cleanupPendingStarts() {
 	}
 }
 
+// SetHistory persists chat messages to store as they are broadcast.
+func (h *Handler) SetHistory(store *chat.Store) {
+	h.history = store
+}
+
+// SetTranscript also records chat messages in store, the King
+// transcript: OpenClaw's agent is the King.
+func (h *Handler) SetTranscript(store *chat.Store) {
+	h.transcript = store
+}
+
+// SetModels gives workers registered without a model the one fn returns
+// for their persona and zone, which the register response passes on to
+// the caller spawning them.
+func (h *Handler) SetModels(fn func(persona, zone string) string) {
+	h.models = fn
+}
+
+// recordChat appends a message to the chat history and the transcript,
+// where they are set.
+func (h *Handler) recordChat(sessionKey, role, content, timestamp, runID string) {
+	msg := chat.Message{
+		Session:   sessionKey,
+		Role:      role,
+		Content:   content,
+		Timestamp: timestamp,
+		Source:    "openclaw",
+		RunID:     runID,
+	}
+	for _, store := range []*chat.Store{h.history, h.transcript} {
+		if store == nil {
+			continue
+		}
+		if _, err := store.Append(msg); err != nil {
+			log.Printf("[openclaw] failed to persist chat message: %v", err)
+		}
+	}
+}
+
 // RegisterRoutes registers /api/openclaw/* routes on the given mux.
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/openclaw/status", h.handleStatus)
@@ -386,6 +485,7 @@ func (h *Handler) RegisterMCRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/mc/worker/register", h.handleWorkerRegister)
 	mux.HandleFunc("/api/mc/worker/link", h.handleWorkerLink)
 	mux.HandleFunc("/api/mc/workers", h.handleWorkersList)
+	mux.HandleFunc("/api/mc/worker/", h.handleWorkerRouter)
 }
 
 // RegisterChatAlias registers /api/chat as an alias for /api/openclaw/chat.
@@ -395,11 +495,11 @@ func (h *Handler) RegisterChatAlias(mux *http.ServeMux) {
 
 func (h *Handler) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(h.bridge.Status())
+	json.NewEncoder(w).Encode(h.status())
 }
 
 // SendRequest is the JSON body for POST /api/openclaw/send.
@@ -409,9 +509,12 @@ type SendRequest struct {
 }
 
 // ChatRequest is the JSON body for POST /api/openclaw/chat.
+// Stream (or ?stream=1, or Accept: text/event-stream) answers with
+// Server-Sent Events carrying partial output instead of one JSON reply.
 type ChatRequest struct {
 	Message    string `json:"message"`
 	SessionKey string `json:"sessionKey,omitempty"`
+	Stream     bool   `json:"stream,omitempty"`
 }
 
 // ChatResponse is the JSON response from POST /api/openclaw/chat.
@@ -424,34 +527,41 @@ type ChatResponse struct {
 
 func (h *Handler) handleChat(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		problem.InvalidBody(w, err)
 		return
 	}
 	if req.Message == "" {
-		http.Error(w, "message is required", http.StatusBadRequest)
+		problem.Validation(w, "message is required")
 		return
 	}
+	// Past the spend limits the King gets no more messages
+	if err := spend.Check(spend.DefaultDir(), time.Now()); err != nil {
+		problem.Write(w, http.StatusTooManyRequests, problem.CodeSpendLimit, err.Error(), nil)
+		return
+	}
+
+	sessionKey := req.SessionKey
+	if sessionKey == "" {
+		sessionKey = "webchat"
+	}
 
 	// Broadcast user message to hub
+	ts := time.Now().UTC().Format(time.RFC3339)
 	if h.hub != nil {
 		h.hub.BroadcastRaw("chat", "chat_message", map[string]interface{}{
 			"id":        randomID(),
 			"role":      "user",
 			"content":   req.Message,
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"timestamp": ts,
 		})
 	}
-
-	sessionKey := req.SessionKey
-	if sessionKey == "" {
-		sessionKey = "webchat"
-	}
+	h.recordChat(sessionKey, "user", req.Message, ts, "")
 	idempotencyKey := randomID()
 	params := map[string]interface{}{
 		"message":        req.Message,
@@ -459,6 +569,13 @@ func (h *Handler) handleChat(w http.ResponseWriter, r *http.Request) {
 		"idempotencyKey": idempotencyKey,
 	}
 
+	if wantsStream(r, req) {
+		h.streamChat(w, r, idempotencyKey, func() (*Frame, error) {
+			return h.bridge.Send("chat.send", params)
+		})
+		return
+	}
+
 	resp, err := h.bridge.Send("chat.send", params)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -513,27 +630,60 @@ func (h *Handler) handleChat(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(ChatResponse{OK: true, Payload: resp.Payload})
 }
 
+// Brief sends message to the King's chat session on the default gateway
+// without waiting for a reply, recording it like a chat from the
+// dashboard. An empty sessionKey means the dashboard's "webchat" session.
+// Nothing is sent while the spend limits' breaker is tripped.
+func (h *Handler) Brief(sessionKey, message string) error {
+	if err := spend.Check(spend.DefaultDir(), time.Now()); err != nil {
+		return err
+	}
+	if sessionKey == "" {
+		sessionKey = "webchat"
+	}
+	ts := time.Now().UTC().Format(time.RFC3339)
+	if h.hub != nil {
+		h.hub.BroadcastRaw("chat", "chat_message", map[string]interface{}{
+			"id":        randomID(),
+			"role":      "user",
+			"content":   message,
+			"timestamp": ts,
+		})
+	}
+	h.recordChat(sessionKey, "user", message, ts, "")
+	resp, err := h.bridge.Send("chat.send", map[string]interface{}{
+		"message":        message,
+		"sessionKey":     sessionKey,
+		"idempotencyKey": randomID(),
+	})
+	if err != nil {
+		return err
+	}
+	if resp.OK != nil && !*resp.OK {
+		return fmt.Errorf("chat.send failed: %s", string(resp.Error))
+	}
+	return nil
+}
+
 func (h *Handler) handleSend(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
 	var req SendRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		problem.InvalidBody(w, err)
 		return
 	}
 	if req.Method == "" {
-		http.Error(w, "method is required", http.StatusBadRequest)
+		problem.Validation(w, "method is required")
 		return
 	}
 
 	resp, err := h.bridge.Send(req.Method, req.Params)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		problem.Write(w, http.StatusBadGateway, problem.CodeUpstream, err.Error(), nil)
 		return
 	}
 
@@ -549,6 +699,7 @@ type workerRegisterRequest struct {
 	Persona    string `json:"persona"`
 	Zone       string `json:"zone"`
 	Model      string `json:"model"`
+	Gateway    string `json:"gateway,omitempty"` // overrides the persona's route
 }
 
 // workerLinkRequest is the JSON body for POST /api/mc/worker/link.
@@ -559,19 +710,27 @@ type workerLinkRequest struct {
 
 func (h *Handler) handleWorkerRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
 	var req workerRegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		problem.InvalidBody(w, err)
 		return
 	}
 	if req.Label == "" {
-		http.Error(w, "label is required", http.StatusBadRequest)
+		problem.Validation(w, "label is required")
 		return
 	}
+	gateway, bridge, err := h.gatewayFor(req.Gateway, req.Persona)
+	if err != nil {
+		problem.Validation(w, err.Error())
+		return
+	}
+	if req.Model == "" && h.models != nil {
+		req.Model = h.models(req.Persona, req.Zone)
+	}
 
 	meta := &WorkerMeta{
 		Label:        req.Label,
@@ -579,6 +738,7 @@ func (h *Handler) handleWorkerRegister(w http.ResponseWriter, r *http.Request) {
 		Persona:      req.Persona,
 		Zone:         req.Zone,
 		Model:        req.Model,
+		Gateway:      gateway,
 		RegisteredAt: time.Now(),
 	}
 
@@ -610,23 +770,29 @@ func (h *Handler) handleWorkerRegister(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[openclaw] registered worker %s (task=%s), awaiting link", req.Label, req.TaskID)
 	}
 
+	// The caller spawns the worker on the gateway chosen here
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":          true,
+		"gateway":     gateway,
+		"gateway_url": bridge.gatewayURL,
+		"model":       req.Model,
+	})
 }
 
 func (h *Handler) handleWorkerLink(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
 	var req workerLinkRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		problem.InvalidBody(w, err)
 		return
 	}
 	if req.Label == "" || req.SessionKey == "" {
-		http.Error(w, "label and session_key are required", http.StatusBadRequest)
+		problem.Validation(w, "label and session_key are required")
 		return
 	}
 
@@ -635,7 +801,7 @@ func (h *Handler) handleWorkerLink(w http.ResponseWriter, r *http.Request) {
 	_, ok := h.workerRegistry[req.Label]
 	h.workerRegistryMu.RUnlock()
 	if !ok {
-		http.Error(w, "label not registered", http.StatusNotFound)
+		problem.Error(w, http.StatusNotFound, "label not registered")
 		return
 	}
 
@@ -675,6 +841,8 @@ func (h *Handler) tryParseTokens(sessionKey, text string) {
 
 	totalK, _ := strconv.ParseFloat(matches[1], 64)
 	totalTokens := int(totalK * 1000)
+	input, _ := strconv.Atoi(matches[2])
+	output, _ := strconv.Atoi(matches[3])
 
 	h.sessionToLabelMu.RLock()
 	label, ok := h.sessionToLabel[sessionKey]
@@ -683,17 +851,211 @@ func (h *Handler) tryParseTokens(sessionKey, text string) {
 		return
 	}
 
-	cost := totalK * 0.01 // placeholder
-
+	var cost float64
 	if h.tracker != nil {
-		h.tracker.UpdateTokens(label, totalTokens, cost)
+		cost = h.tracker.UpdateUsage(label, totalTokens, input, output)
 	}
 	log.Printf("[openclaw] tokens for %s: %d (cost $%.4f)", label, totalTokens, cost)
 }
 
+// handleWorkerRouter dispatches /api/mc/worker/{label}/{action}.
+func (h *Handler) handleWorkerRouter(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/mc/worker/"), "/")
+	if len(parts) != 2 || parts[0] == "" || (parts[1] != "cancel" && parts[1] != "heartbeat") {
+		problem.NotFound(w, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		problem.MethodNotAllowed(w)
+		return
+	}
+	if parts[1] == "heartbeat" {
+		h.handleWorkerHeartbeat(w, parts[0])
+		return
+	}
+	h.handleWorkerCancel(w, parts[0])
+}
+
+// handleWorkerHeartbeat records that a registered worker is alive, so the
+// tracker does not mark it stale. Workers should send one well inside
+// the stale timeout (tracker.DefaultStaleness).
+func (h *Handler) handleWorkerHeartbeat(w http.ResponseWriter, label string) {
+	h.workerRegistryMu.RLock()
+	_, ok := h.workerRegistry[label]
+	h.workerRegistryMu.RUnlock()
+	if !ok {
+		problem.NotFound(w, "worker "+label+" not registered")
+		return
+	}
+
+	// Before lifecycle/start the worker is registered but not yet tracked
+	tracked := h.tracker != nil && h.tracker.Touch(label)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":        true,
+		"worker_id": label,
+		"tracked":   tracked,
+	})
+}
+
+// keepalive treats gateway traffic for a linked sub-agent session as a
+// heartbeat from its worker.
+func (h *Handler) keepalive(payload json.RawMessage) {
+	if h.tracker == nil {
+		return
+	}
+	var ev struct {
+		SessionKey string `json:"sessionKey"`
+	}
+	if json.Unmarshal(payload, &ev) != nil || ev.SessionKey == "" {
+		return
+	}
+	h.sessionToLabelMu.RLock()
+	label, ok := h.sessionToLabel[ev.SessionKey]
+	h.sessionToLabelMu.RUnlock()
+	if ok {
+		h.tracker.Touch(label)
+	}
+}
+
+// appendWorkerLog adds a sub-agent's chat output to its worker's log.
+func (h *Handler) appendWorkerLog(sessionKey, text string) {
+	if h.tracker == nil {
+		return
+	}
+	h.sessionToLabelMu.RLock()
+	label, ok := h.sessionToLabel[sessionKey]
+	h.sessionToLabelMu.RUnlock()
+	if ok {
+		h.tracker.Logs().Append(label, "stdout", text)
+	}
+}
+
+// handleWorkerReaped forgets a worker the tracker gave up on, so a late
+// lifecycle/end or cancel for it is ignored.
+func (h *Handler) handleWorkerReaped(p *tracker.TrackedProcess) {
+	h.workerRegistryMu.RLock()
+	_, ok := h.workerRegistry[p.WorkerID]
+	h.workerRegistryMu.RUnlock()
+	if !ok {
+		return
+	}
+	sessionKey, runID := h.workerSession(p.WorkerID)
+	h.forgetWorker(p.WorkerID, sessionKey, runID)
+	log.Printf("[openclaw] worker reaped after missing heartbeats: %s (task=%s)", p.WorkerID, p.TaskID)
+}
+
+// workerSession returns the gateway session and run of a worker, if linked
+// and started.
+func (h *Handler) workerSession(label string) (sessionKey, runID string) {
+	h.sessionToLabelMu.RLock()
+	for sk, l := range h.sessionToLabel {
+		if l == label {
+			sessionKey = sk
+			break
+		}
+	}
+	h.sessionToLabelMu.RUnlock()
+	if sessionKey != "" {
+		h.runToSessionMu.RLock()
+		for rid, sk := range h.runToSession {
+			if sk == sessionKey {
+				runID = rid
+				break
+			}
+		}
+		h.runToSessionMu.RUnlock()
+	}
+	return sessionKey, runID
+}
+
+// forgetWorker drops a worker from the registry and session indexes.
+func (h *Handler) forgetWorker(label, sessionKey, runID string) {
+	h.workerRegistryMu.Lock()
+	delete(h.workerRegistry, label)
+	h.workerRegistryMu.Unlock()
+	if sessionKey != "" {
+		h.sessionToLabelMu.Lock()
+		delete(h.sessionToLabel, sessionKey)
+		h.sessionToLabelMu.Unlock()
+		h.pendingStartsMu.Lock()
+		delete(h.pendingStarts, sessionKey)
+		h.pendingStartsMu.Unlock()
+	}
+	if runID != "" {
+		h.runToSessionMu.Lock()
+		delete(h.runToSession, runID)
+		h.runToSessionMu.Unlock()
+	}
+}
+
+// cancelMethod is the gateway method that aborts a run.
+const cancelMethod = "chat.abort"
+
+// handleWorkerCancel stops a sub-agent: it aborts the worker's run on the
+// gateway (if it has started), forgets the worker so its lifecycle/end is
+// ignored, deregisters it from the tracker and broadcasts worker_cancelled.
+func (h *Handler) handleWorkerCancel(w http.ResponseWriter, label string) {
+	h.workerRegistryMu.RLock()
+	meta, ok := h.workerRegistry[label]
+	h.workerRegistryMu.RUnlock()
+	if !ok {
+		problem.NotFound(w, "worker "+label+" not registered")
+		return
+	}
+
+	sessionKey, runID := h.workerSession(label)
+
+	// Abort the run first; if the gateway refuses, the worker is still
+	// running and stays registered.
+	if runID != "" {
+		resp, err := h.bridgeFor(meta.Gateway).Send(cancelMethod, map[string]string{
+			"sessionKey": sessionKey,
+			"runId":      runID,
+		})
+		if err != nil {
+			problem.Write(w, http.StatusBadGateway, problem.CodeUpstream, err.Error(), nil)
+			return
+		}
+		if resp.OK != nil && !*resp.OK {
+			problem.Write(w, http.StatusBadGateway, problem.CodeUpstream, "gateway refused "+cancelMethod,
+				map[string]interface{}{"error": string(resp.Error)})
+			return
+		}
+	}
+
+	h.forgetWorker(label, sessionKey, runID)
+
+	if h.tracker != nil {
+		h.tracker.Deregister(meta.Label, tracker.StatusKilled)
+	}
+
+	cancelledAt := time.Now().UTC().Format(time.RFC3339)
+	if h.hub != nil {
+		h.hub.BroadcastRaw("workers", "worker_cancelled", map[string]interface{}{
+			"worker_id":    meta.Label,
+			"task_id":      meta.TaskID,
+			"run_id":       runID,
+			"status":       string(tracker.StatusKilled),
+			"cancelled_at": cancelledAt,
+		})
+	}
+
+	log.Printf("[openclaw] worker cancelled: %s (task=%s, run=%s)", meta.Label, meta.TaskID, runID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":        true,
+		"worker_id": meta.Label,
+		"run_id":    runID,
+		"aborted":   runID != "",
+	})
+}
+
 func (h *Handler) handleWorkersList(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.MethodNotAllowed(w)
 		return
 	}
 
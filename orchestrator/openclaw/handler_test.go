@@ -3,12 +3,18 @@ package openclaw
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/spend"
 	"github.com/MikeSquared-Agency/MissionControl/tracker"
 )
 
@@ -441,8 +447,9 @@ func TestTokenParsing(t *testing.T) {
 	if p.TokenCount != 12500 {
 		t.Fatalf("expected 12500 tokens, got %d", p.TokenCount)
 	}
-	if p.CostUSD == 0 {
-		t.Fatalf("expected non-zero cost")
+	// Priced at sonnet's $3/$15 per million tokens
+	if math.Abs(p.CostUSD-0.0855) > 1e-9 {
+		t.Fatalf("expected cost $0.0855, got $%f", p.CostUSD)
 	}
 }
 
@@ -510,3 +517,233 @@ func TestWorkersListWithoutTracker(t *testing.T) {
 		t.Fatalf("expected empty list without tracker")
 	}
 }
+
+func TestWorkerCancel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gw, seen := fakeGateway(t, nil)
+	defer gw.Close()
+	bridge := NewBridge("ws"+strings.TrimPrefix(gw.URL, "http"), "token")
+	if err := bridge.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer bridge.Close()
+
+	hub := &mockBroadcaster{}
+	trk := tracker.NewTracker(t.TempDir(), nil)
+	h := NewHandler(bridge, hub, trk)
+	mux := http.NewServeMux()
+	h.RegisterMCRoutes(mux)
+
+	sessionKey := "agent:main:subagent:cancel1"
+	registerWorker(t, mux, sessionKey, "worker-9", "task-9", "coder", "backend", "claude-4")
+	simulateLifecycleEvent(h, sessionKey, "run-009", "start")
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/mc/worker/worker-9/cancel", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case req := <-seen:
+		if req.Method != cancelMethod || !strings.Contains(string(req.Params), `"runId":"run-009"`) {
+			t.Errorf("gateway got %s %s", req.Method, req.Params)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("gateway never received the cancel")
+	}
+	if len(trk.List()) != 0 {
+		t.Errorf("worker still tracked after cancel")
+	}
+	found := false
+	for _, e := range hub.getEvents() {
+		if e.EventType == "worker_cancelled" {
+			found = e.Data.(map[string]interface{})["run_id"] == "run-009"
+		}
+	}
+	if !found {
+		t.Errorf("expected worker_cancelled broadcast, got: %+v", hub.getEvents())
+	}
+
+	// The run's lifecycle/end is now ignored, and the label is gone.
+	simulateLifecycleEvent(h, sessionKey, "run-009", "end")
+	for _, e := range hub.getEvents() {
+		if e.EventType == "worker_stopped" {
+			t.Errorf("unexpected worker_stopped after cancel")
+		}
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/mc/worker/worker-9/cancel", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second cancel: expected 404, got %d", w.Code)
+	}
+}
+
+func TestWorkerCancelBeforeStart(t *testing.T) {
+	hub := &mockBroadcaster{}
+	h := newTestHandler(t, hub, nil)
+	mux := http.NewServeMux()
+	h.RegisterMCRoutes(mux)
+	registerWorkerOnly(t, mux, "worker-10", "task-10", "coder", "backend", "claude-4")
+
+	// No run yet, so nothing is sent to the (disconnected) gateway.
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/mc/worker/worker-10/cancel", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"aborted":false`) {
+		t.Fatalf("cancel: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/mc/worker/worker-10/cancel", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET cancel: expected 405, got %d", w.Code)
+	}
+}
+
+func TestWorkerHeartbeat(t *testing.T) {
+	trk := tracker.NewTracker(t.TempDir(), nil)
+	h := newTestHandler(t, &mockBroadcaster{}, trk)
+	mux := http.NewServeMux()
+	h.RegisterMCRoutes(mux)
+
+	heartbeat := func(label string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, "/api/mc/worker/"+label+"/heartbeat", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	if code, _ := heartbeat("nobody"); code != http.StatusNotFound {
+		t.Fatalf("unregistered worker: expected 404, got %d", code)
+	}
+
+	sessionKey := "agent:main:subagent:hb1"
+	registerWorker(t, mux, sessionKey, "worker-hb", "task-1", "coder", "", "")
+	if code, resp := heartbeat("worker-hb"); code != http.StatusOK || resp["tracked"] != false {
+		t.Fatalf("before start: got %d %v, want 200 untracked", code, resp)
+	}
+
+	simulateLifecycleEvent(h, sessionKey, "run-hb", "start")
+	if code, resp := heartbeat("worker-hb"); code != http.StatusOK || resp["tracked"] != true {
+		t.Fatalf("after start: got %d %v, want 200 tracked", code, resp)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/mc/worker/worker-hb/heartbeat", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET heartbeat: expected 405, got %d", w.Code)
+	}
+}
+
+func TestReapedWorkerForgotten(t *testing.T) {
+	hub := &mockBroadcaster{}
+	trk := tracker.NewTracker(t.TempDir(), nil)
+	h := newTestHandler(t, hub, trk)
+	mux := http.NewServeMux()
+	h.RegisterMCRoutes(mux)
+
+	sessionKey := "agent:main:subagent:reap1"
+	registerWorker(t, mux, sessionKey, "worker-reap", "task-1", "coder", "", "")
+	simulateLifecycleEvent(h, sessionKey, "run-reap", "start")
+
+	p, _ := trk.Get("worker-reap")
+	h.handleWorkerReaped(p)
+
+	h.workerRegistryMu.RLock()
+	_, registered := h.workerRegistry["worker-reap"]
+	h.workerRegistryMu.RUnlock()
+	if registered {
+		t.Fatal("reaped worker should be dropped from the registry")
+	}
+
+	// A late lifecycle/end no longer reports the worker as stopped
+	simulateLifecycleEvent(h, sessionKey, "run-reap", "end")
+	for _, e := range hub.getEvents() {
+		if e.EventType == "worker_stopped" {
+			t.Fatalf("unexpected worker_stopped for reaped worker: %+v", e)
+		}
+	}
+}
+
+func TestBrief(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gw, seen := fakeGateway(t, nil)
+	defer gw.Close()
+	bridge := NewBridge("ws"+strings.TrimPrefix(gw.URL, "http"), "token")
+	if err := bridge.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer bridge.Close()
+
+	hub := &mockBroadcaster{}
+	h := NewHandler(bridge, hub, nil)
+	if err := h.Brief("", "Resume from checkpoint cp-1"); err != nil {
+		t.Fatalf("Brief: %v", err)
+	}
+
+	select {
+	case req := <-seen:
+		if req.Method != "chat.send" || !strings.Contains(string(req.Params), `"sessionKey":"webchat"`) || !strings.Contains(string(req.Params), "cp-1") {
+			t.Errorf("gateway got %s %s", req.Method, req.Params)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("gateway never received the briefing")
+	}
+	events := hub.getEvents()
+	if len(events) != 1 || events[0].EventType != "chat_message" {
+		t.Errorf("expected the briefing broadcast as a chat message, got: %+v", events)
+	}
+}
+
+func TestChatPausedPastSpendLimit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".mission-control"), 0755)
+	os.WriteFile(filepath.Join(home, ".mission-control", "config.json"), []byte(`{"spendLimits":{"dailyTokens":100}}`), 0644)
+	if _, tripped, _ := spend.Record(spend.DefaultDir(), 150, 0.5, time.Now()); !tripped {
+		t.Fatal("expected the breaker to trip")
+	}
+
+	hub := &mockBroadcaster{}
+	h := newTestHandler(t, hub, nil)
+	w := httptest.NewRecorder()
+	h.handleChat(w, httptest.NewRequest(http.MethodPost, "/api/openclaw/chat", strings.NewReader(`{"message":"hi"}`)))
+	if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "spend_limit_exceeded") {
+		t.Errorf("chat: expected 429 spend_limit_exceeded, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := h.Brief("", "Resume"); !errors.Is(err, spend.ErrLimitExceeded) {
+		t.Errorf("Brief: expected ErrLimitExceeded, got %v", err)
+	}
+	if events := hub.getEvents(); len(events) != 0 {
+		t.Errorf("expected nothing broadcast, got %+v", events)
+	}
+}
+
+func TestWorkerRegisterDefaultsModel(t *testing.T) {
+	h := newTestHandler(t, nil, nil)
+	h.SetModels(func(persona, zone string) string {
+		if persona == "researcher" {
+			return "haiku"
+		}
+		return ""
+	})
+	mux := http.NewServeMux()
+	h.RegisterMCRoutes(mux)
+
+	register := func(body string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/mc/worker/register", bytes.NewReader([]byte(body))))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+	if resp := register(`{"label":"w1","persona":"researcher"}`); resp["model"] != "haiku" || h.workerRegistry["w1"].Model != "haiku" {
+		t.Errorf("researcher registered with %v", resp)
+	}
+	if resp := register(`{"label":"w2","persona":"researcher","model":"opus"}`); resp["model"] != "opus" {
+		t.Errorf("explicit model replaced: %v", resp)
+	}
+}
@@ -0,0 +1,233 @@
+package openclaw
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/httpstream"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// chatStreamIdle is how long a streaming chat waits for the next piece of
+// output before giving up.
+const chatStreamIdle = 60 * time.Second
+
+// chatUpdate is one piece of a run's output, forwarded to a streaming chat.
+type chatUpdate struct {
+	Text       string
+	Cumulative bool   // Text is the whole reply so far, not a chunk
+	Final      bool   // the reply is complete; Text is the whole reply
+	Err        string // the run failed or was aborted
+}
+
+// partialPayload covers the gateway events that carry partial output:
+// "chat" (state delta/final/error/aborted, cumulative message) and "agent"
+// on the assistant stream (data.delta chunks or cumulative data.text).
+type partialPayload struct {
+	RunID        string `json:"runId"`
+	State        string `json:"state"`
+	Stream       string `json:"stream"`
+	ErrorMessage string `json:"errorMessage"`
+	Message      struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+	Data struct {
+		Text  string `json:"text"`
+		Delta string `json:"delta"`
+	} `json:"data"`
+}
+
+// forwardPartial passes partial output from a gateway event to the
+// streaming chat waiting on its run, if any.
+func (h *Handler) forwardPartial(event string, payload json.RawMessage) {
+	var p partialPayload
+	if json.Unmarshal(payload, &p) != nil || p.RunID == "" {
+		return
+	}
+
+	var u chatUpdate
+	switch event {
+	case "chat":
+		var text strings.Builder
+		for _, c := range p.Message.Content {
+			if c.Type == "text" {
+				text.WriteString(c.Text)
+			}
+		}
+		switch p.State {
+		case "delta":
+			u = chatUpdate{Text: text.String(), Cumulative: true}
+		case "final":
+			u = chatUpdate{Text: text.String(), Final: true}
+		case "error", "aborted":
+			u = chatUpdate{Err: p.State}
+			if p.ErrorMessage != "" {
+				u.Err = p.ErrorMessage
+			}
+		default:
+			return
+		}
+	case "agent":
+		if p.Stream != "assistant" {
+			return
+		}
+		if p.Data.Delta != "" {
+			u = chatUpdate{Text: p.Data.Delta}
+		} else if p.Data.Text != "" {
+			u = chatUpdate{Text: p.Data.Text, Cumulative: true}
+		} else {
+			return
+		}
+	default:
+		return
+	}
+	h.sendStreamUpdate(p.RunID, u)
+}
+
+// sendStreamUpdate delivers u to the streaming chat for runID. Updates are
+// dropped rather than blocking the bridge when the client falls behind; the
+// final reply is always complete, so nothing is lost for good.
+func (h *Handler) sendStreamUpdate(runID string, u chatUpdate) {
+	h.chatStreamsMu.Lock()
+	defer h.chatStreamsMu.Unlock()
+	if ch, ok := h.chatStreams[runID]; ok {
+		select {
+		case ch <- u:
+		default:
+		}
+	}
+}
+
+// streamChat answers a chat as Server-Sent Events:
+//
+//	event: start  {"runId"}
+//	event: delta  {"text"}              new output; "replace": true if the
+//	                                    gateway rewrote earlier text
+//	event: done   {"reply"}             the complete reply; ends the stream
+//	event: error  {"error"}             the run failed or went quiet
+//
+// runID is the idempotency key sent with the chat, which the gateway uses
+// as the run ID. The stream is registered under it before send is called,
+// so output the gateway sends ahead of its chat.send response isn't lost.
+// send is called once the headers are written and returns the gateway's
+// chat.send response.
+func (h *Handler) streamChat(w http.ResponseWriter, r *http.Request, runID string, send func() (*Frame, error)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "streaming not supported", nil)
+		return
+	}
+
+	httpstream.Begin(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	emit := func(event string, data interface{}) {
+		b, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+		flusher.Flush()
+	}
+
+	ch := make(chan chatUpdate, 256)
+	runIDs := []string{runID}
+	h.chatStreamsMu.Lock()
+	h.chatStreams[runID] = ch
+	h.chatStreamsMu.Unlock()
+	defer func() {
+		h.chatStreamsMu.Lock()
+		for _, id := range runIDs {
+			delete(h.chatStreams, id)
+		}
+		h.chatStreamsMu.Unlock()
+	}()
+
+	resp, err := send()
+	if err != nil {
+		emit("error", map[string]string{"error": err.Error()})
+		return
+	}
+	if resp.OK != nil && !*resp.OK {
+		emit("error", map[string]string{"error": string(resp.Error)})
+		return
+	}
+
+	var started struct {
+		RunID string `json:"runId"`
+	}
+	if resp.Payload != nil {
+		json.Unmarshal(resp.Payload, &started)
+	}
+	if started.RunID == "" {
+		emit("done", map[string]interface{}{"reply": "", "payload": resp.Payload})
+		return
+	}
+
+	// A gateway that picked its own run ID streams under that one too
+	if started.RunID != runID {
+		h.chatStreamsMu.Lock()
+		h.chatStreams[started.RunID] = ch
+		runIDs = append(runIDs, started.RunID)
+		h.chatStreamsMu.Unlock()
+	}
+
+	emit("start", map[string]string{"runId": started.RunID})
+
+	var reply string // output streamed so far
+	idle := time.NewTimer(chatStreamIdle)
+	defer idle.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-idle.C:
+			emit("error", map[string]string{"error": "timed out waiting for the agent", "runId": started.RunID})
+			return
+		case u := <-ch:
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(chatStreamIdle)
+
+			if u.Err != "" {
+				emit("error", map[string]string{"error": u.Err, "runId": started.RunID})
+				return
+			}
+			if !u.Cumulative && !u.Final {
+				reply += u.Text
+				emit("delta", map[string]string{"text": u.Text})
+				continue
+			}
+			if strings.HasPrefix(u.Text, reply) {
+				if rest := u.Text[len(reply):]; rest != "" {
+					emit("delta", map[string]string{"text": rest})
+				}
+			} else if u.Text != "" {
+				emit("delta", map[string]interface{}{"text": u.Text, "replace": true})
+			}
+			if u.Text != "" || !u.Final {
+				reply = u.Text
+			}
+			if u.Final {
+				emit("done", map[string]string{"reply": reply, "runId": started.RunID})
+				return
+			}
+		}
+	}
+}
+
+// wantsStream reports whether a chat request asked for an SSE reply.
+func wantsStream(r *http.Request, req ChatRequest) bool {
+	return req.Stream || r.URL.Query().Get("stream") == "1" ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -0,0 +1,152 @@
+package openclaw
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeGateway accepts the connect handshake and answers chat.send with the
+// request's idempotency key as its runId, as the gateway does. The events
+// are played first, their runId "r1" standing for that key, so a stream
+// registered only once chat.send returns would miss them. Other requests
+// are acknowledged and passed to seen.
+func fakeGateway(t *testing.T, events []Frame) (gw *httptest.Server, seen chan Frame) {
+	t.Helper()
+	seen = make(chan Frame, 16)
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	gw = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteJSON(Frame{Type: "event", Event: "connect.challenge", Payload: json.RawMessage(`{"nonce":"n"}`)})
+
+		ok := true
+		for {
+			var req Frame
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			switch req.Method {
+			case "connect":
+				conn.WriteJSON(Frame{Type: "res", ID: req.ID, OK: &ok, Payload: json.RawMessage(`{}`)})
+			case "chat.send":
+				var params struct {
+					IdempotencyKey string `json:"idempotencyKey"`
+				}
+				json.Unmarshal(req.Params, &params)
+				runID, _ := json.Marshal(params.IdempotencyKey)
+				for _, ev := range events {
+					ev.Payload = json.RawMessage(strings.ReplaceAll(string(ev.Payload), `"r1"`, string(runID)))
+					conn.WriteJSON(ev)
+				}
+				conn.WriteJSON(Frame{Type: "res", ID: req.ID, OK: &ok, Payload: json.RawMessage(`{"runId":` + string(runID) + `,"status":"started"}`)})
+				seen <- req
+			default:
+				conn.WriteJSON(Frame{Type: "res", ID: req.ID, OK: &ok, Payload: json.RawMessage(`{}`)})
+				seen <- req
+			}
+		}
+	}))
+	return gw, seen
+}
+
+func chatEvent(event, payload string) Frame {
+	return Frame{Type: "event", Event: event, Payload: json.RawMessage(payload)}
+}
+
+// readSSE returns the event names and data lines of an SSE body.
+func readSSE(t *testing.T, body string) (names, data []string) {
+	t.Helper()
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := sc.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			names = append(names, name)
+		} else if d, ok := strings.CutPrefix(line, "data: "); ok {
+			data = append(data, d)
+		}
+	}
+	return names, data
+}
+
+func TestChatStreaming(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gw, _ := fakeGateway(t, []Frame{
+		chatEvent("chat", `{"runId":"r1","state":"delta","message":{"content":[{"type":"text","text":"Hel"}]}}`),
+		chatEvent("agent", `{"runId":"r1","stream":"assistant","data":{"delta":"lo"}}`),
+		chatEvent("chat", `{"runId":"other","state":"delta","message":{"content":[{"type":"text","text":"ignored"}]}}`),
+		chatEvent("chat", `{"runId":"r1","state":"final","message":{"content":[{"type":"text","text":"Hello world"}]}}`),
+	})
+	defer gw.Close()
+
+	bridge := NewBridge("ws"+strings.TrimPrefix(gw.URL, "http"), "token")
+	if err := bridge.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer bridge.Close()
+
+	h := NewHandler(bridge, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/openclaw/chat", "application/json",
+		strings.NewReader(`{"message":"hi","stream":true}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	var body strings.Builder
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		body.WriteString(sc.Text() + "\n")
+	}
+
+	names, data := readSSE(t, body.String())
+	want := []string{"start", "delta", "delta", "delta", "done"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v\n%s", names, want, body.String())
+	}
+	if data[1] != `{"text":"Hel"}` || data[2] != `{"text":"lo"}` || data[3] != `{"text":" world"}` {
+		t.Errorf("deltas = %v", data[1:4])
+	}
+	if !strings.Contains(data[4], `"reply":"Hello world"`) {
+		t.Errorf("done = %s", data[4])
+	}
+}
+
+func TestChatStreamingError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	gw, _ := fakeGateway(t, []Frame{
+		chatEvent("chat", `{"runId":"r1","state":"error","errorMessage":"model overloaded"}`),
+	})
+	defer gw.Close()
+
+	bridge := NewBridge("ws"+strings.TrimPrefix(gw.URL, "http"), "token")
+	if err := bridge.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer bridge.Close()
+
+	h := NewHandler(bridge, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/openclaw/chat?stream=1", strings.NewReader(`{"message":"hi"}`))
+	w := httptest.NewRecorder()
+	h.handleChat(w, req)
+
+	names, data := readSSE(t, w.Body.String())
+	if len(names) != 2 || names[1] != "error" || !strings.Contains(data[1], "model overloaded") {
+		t.Errorf("events = %v %v", names, data)
+	}
+}
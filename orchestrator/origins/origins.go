@@ -0,0 +1,131 @@
+// Package origins decides which browser origins may call the API and open
+// WebSocket connections. The same policy backs CORS in the api package and
+// the upgrade check in the ws hub.
+package origins
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// EnvVar lists allowed origins, comma-separated, when no flag is given.
+const EnvVar = "MC_ALLOWED_ORIGINS"
+
+// DefaultPatterns allows the hosted dashboard and localhost on any port.
+var DefaultPatterns = []string{
+	"https://darlington.dev",
+	"https://www.darlington.dev",
+	"http://localhost:*",
+	"http://127.0.0.1:*",
+	"http://[::1]:*",
+}
+
+// Policy is a set of allowed origin patterns. "*" allows any origin;
+// otherwise patterns take these forms:
+//
+//	https://app.example.com  exact origin
+//	http://localhost:*       any port (or none) on that scheme and host
+//	https://*.example.com    any subdomain
+type Policy struct {
+	any      bool
+	patterns []string
+}
+
+// New builds a policy from patterns. Empty entries are ignored.
+func New(patterns []string) *Policy {
+	p := &Policy{}
+	for _, pat := range patterns {
+		pat = normalize(pat)
+		switch pat {
+		case "":
+		case "*":
+			p.any = true
+		default:
+			p.patterns = append(p.patterns, pat)
+		}
+	}
+	return p
+}
+
+// Default returns the policy for DefaultPatterns.
+func Default() *Policy {
+	return New(DefaultPatterns)
+}
+
+// Resolve returns the policy for explicit patterns (e.g. --allow-origin),
+// falling back to $MC_ALLOWED_ORIGINS and then to the defaults.
+func Resolve(patterns []string) *Policy {
+	if len(patterns) > 0 {
+		return New(patterns)
+	}
+	if env := os.Getenv(EnvVar); env != "" {
+		return New(strings.Split(env, ","))
+	}
+	return Default()
+}
+
+// Patterns returns the allowed patterns, for logging.
+func (p *Policy) Patterns() []string {
+	if p.any {
+		return []string{"*"}
+	}
+	return append([]string(nil), p.patterns...)
+}
+
+// Allowed reports whether a non-empty Origin header value is permitted.
+func (p *Policy) Allowed(origin string) bool {
+	origin = normalize(origin)
+	if origin == "" || origin == "null" {
+		return false
+	}
+	if p.any {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, pat := range p.patterns {
+		if matches(pat, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowRequest reports whether r may proceed. Requests without an Origin
+// header (CLI tools, server-to-server) and same-host requests (the
+// dashboard served by the orchestrator itself) are always allowed.
+func (p *Policy) AllowRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return p.Allowed(origin)
+}
+
+func matches(pattern string, u *url.URL) bool {
+	pu, err := url.Parse(strings.Replace(pattern, ":*", "", 1))
+	if err != nil || pu.Scheme != u.Scheme {
+		return false
+	}
+	anyPort := strings.HasSuffix(pattern, ":*")
+	host, port := u.Hostname(), u.Port()
+	phost, pport := pu.Hostname(), pu.Port()
+	if !anyPort && port != pport {
+		return false
+	}
+	if sub, ok := strings.CutPrefix(phost, "*."); ok {
+		return strings.HasSuffix(host, "."+sub)
+	}
+	return host == phost
+}
+
+func normalize(s string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "/")
+}
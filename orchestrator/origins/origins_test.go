@@ -0,0 +1,79 @@
+package origins
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	p := New([]string{"https://app.example.com/", "http://localhost:*", "https://*.corp.dev"})
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"http://localhost", true},
+		{"http://localhost:5173", true},
+		{"https://localhost:5173", false},
+		{"https://dash.corp.dev", true},
+		{"https://corp.dev", false},
+		{"https://evilcorp.dev", false},
+		{"null", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := p.Allowed(tt.origin); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if !New([]string{"*"}).Allowed("https://anything.example") {
+		t.Error("wildcard policy should allow any origin")
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	p := Default()
+	for _, o := range []string{"http://localhost:3000", "http://127.0.0.1:8080", "http://[::1]:5173", "https://darlington.dev"} {
+		if !p.Allowed(o) {
+			t.Errorf("default policy should allow %s", o)
+		}
+	}
+	if p.Allowed("https://evil.com") {
+		t.Error("default policy should reject other origins")
+	}
+}
+
+func TestAllowRequest(t *testing.T) {
+	p := New([]string{"http://localhost:*"})
+
+	r := httptest.NewRequest("GET", "http://mc.internal:8080/ws", nil)
+	if !p.AllowRequest(r) {
+		t.Error("requests without Origin should be allowed")
+	}
+	r.Header.Set("Origin", "http://mc.internal:8080")
+	if !p.AllowRequest(r) {
+		t.Error("same-host requests should be allowed")
+	}
+	r.Header.Set("Origin", "https://evil.com")
+	if p.AllowRequest(r) {
+		t.Error("foreign origin should be rejected")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv(EnvVar, "https://a.example, https://b.example")
+	if p := Resolve(nil); !p.Allowed("https://b.example") || p.Allowed("http://localhost:3000") {
+		t.Errorf("env policy = %v", p.Patterns())
+	}
+	if p := Resolve([]string{"https://c.example"}); !p.Allowed("https://c.example") || p.Allowed("https://a.example") {
+		t.Errorf("flag policy = %v", p.Patterns())
+	}
+	t.Setenv(EnvVar, "")
+	if p := Resolve(nil); !p.Allowed("http://localhost:3000") {
+		t.Errorf("default policy = %v", p.Patterns())
+	}
+}
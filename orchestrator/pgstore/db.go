@@ -0,0 +1,198 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// driverName is the database/sql driver used for postgres:// URLs. It is
+// registered by driver_pgx.go, built with -tags postgres.
+const driverName = "pgx"
+
+// schema is applied on Open. mc_audit, mc_chat and mc_usage are views
+// for querying the audit log, chat history and token usage in SQL.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS mc_files (
+		project  TEXT NOT NULL,
+		path     TEXT NOT NULL,
+		data     BYTEA NOT NULL,
+		mod_time TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (project, path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS mc_lines (
+		project TEXT NOT NULL,
+		path    TEXT NOT NULL,
+		line    INTEGER NOT NULL,
+		data    JSONB NOT NULL,
+		PRIMARY KEY (project, path, line)
+	)`,
+	`CREATE OR REPLACE VIEW mc_audit AS
+		SELECT project, line AS seq, data FROM mc_lines WHERE path = 'audit.jsonl'`,
+	`CREATE OR REPLACE VIEW mc_chat AS
+		SELECT project, line AS seq, data FROM mc_lines WHERE path = 'chat/history.jsonl'`,
+	`CREATE OR REPLACE VIEW mc_usage AS
+		SELECT f.project, d.key AS day, (d.value->>'tokens')::BIGINT AS tokens, (d.value->>'cost_usd')::NUMERIC AS cost_usd
+		FROM mc_files f, jsonb_each(convert_from(f.data, 'UTF8')::jsonb->'days') d
+		WHERE f.path = 'orchestrator/usage.json'`,
+}
+
+// DB is a Backend on a Postgres database.
+type DB struct {
+	db *sql.DB
+}
+
+// Open connects to the Postgres database at url and creates the tables it
+// needs.
+func Open(ctx context.Context, url string) (*DB, error) {
+	if !hasDriver() {
+		return nil, errNoDriver
+	}
+	db, err := sql.Open(driverName, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to Postgres: %w", err)
+	}
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("creating the schema: %w", err)
+		}
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the connection pool.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Load returns every file stored for project.
+func (d *DB) Load(ctx context.Context, project string) ([]File, error) {
+	var files []File
+	rows, err := d.db.QueryContext(ctx, `SELECT path, data, mod_time FROM mc_files WHERE project = $1 ORDER BY path`, project)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var f File
+		if err := rows.Scan(&f.Path, &f.Data, &f.ModTime); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	lines, err := d.db.QueryContext(ctx, `SELECT path, data::TEXT FROM mc_lines WHERE project = $1 ORDER BY path, line`, project)
+	if err != nil {
+		return nil, err
+	}
+	defer lines.Close()
+	index := map[string]int{}
+	for i, f := range files {
+		index[f.Path] = i
+	}
+	for lines.Next() {
+		var path, line string
+		if err := lines.Scan(&path, &line); err != nil {
+			return nil, err
+		}
+		i, ok := index[path]
+		if !ok {
+			i = len(files)
+			index[path] = i
+			files = append(files, File{Path: path})
+		}
+		files[i].Lines = append(files[i].Lines, []byte(line))
+	}
+	return files, lines.Err()
+}
+
+// Put stores f in place of any file at its path.
+func (d *DB) Put(ctx context.Context, project string, f File) error {
+	return d.tx(ctx, func(tx *sql.Tx) error {
+		if err := deleteFile(ctx, tx, project, f.Path); err != nil {
+			return err
+		}
+		if f.Lines != nil || strings.HasSuffix(f.Path, ".jsonl") {
+			// An empty JSONL file still needs a row to be restored
+			if _, err := tx.ExecContext(ctx, `INSERT INTO mc_files (project, path, data, mod_time) VALUES ($1, $2, '', $3)`,
+				project, f.Path, modTime(f)); err != nil {
+				return err
+			}
+			return insertLines(ctx, tx, project, f.Path, 0, f.Lines)
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO mc_files (project, path, data, mod_time) VALUES ($1, $2, $3, $4)`,
+			project, f.Path, f.Data, modTime(f))
+		return err
+	})
+}
+
+// Append adds lines to a JSONL file, numbered on from first.
+func (d *DB) Append(ctx context.Context, project, path string, first int, lines [][]byte) error {
+	return d.tx(ctx, func(tx *sql.Tx) error {
+		return insertLines(ctx, tx, project, path, first, lines)
+	})
+}
+
+// Delete removes the file at path.
+func (d *DB) Delete(ctx context.Context, project, path string) error {
+	return d.tx(ctx, func(tx *sql.Tx) error {
+		return deleteFile(ctx, tx, project, path)
+	})
+}
+
+func (d *DB) tx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteFile(ctx context.Context, tx *sql.Tx, project, path string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mc_files WHERE project = $1 AND path = $2`, project, path); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM mc_lines WHERE project = $1 AND path = $2`, project, path)
+	return err
+}
+
+func insertLines(ctx context.Context, tx *sql.Tx, project, path string, first int, lines [][]byte) error {
+	for i, line := range lines {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO mc_lines (project, path, line, data) VALUES ($1, $2, $3, $4)`,
+			project, path, first+i, string(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func modTime(f File) time.Time {
+	if f.ModTime.IsZero() {
+		return time.Now()
+	}
+	return f.ModTime
+}
+
+func hasDriver() bool {
+	for _, name := range sql.Drivers() {
+		if name == driverName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+//go:build postgres
+
+package pgstore
+
+// The pgx driver registers itself as "pgx". It is left out of default
+// builds so the orchestrator builds without network access to fetch it.
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,282 @@
+// Package pgstore keeps a mission's state in Postgres for team server
+// mode. The orchestrator and mc go on working on the .mission directory,
+// which becomes a working copy: Restore fills it from the database when
+// the server starts and a Mirror writes every change back, so the database
+// is the system of record while the REST and WebSocket APIs stay as they
+// are.
+//
+// JSONL files (tasks, audit log, chat history, events) are stored a line
+// per row, so appends cost one insert and the audit log and chat can be
+// queried in SQL; other files are stored whole.
+package pgstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often a running Mirror looks for changes.
+const DefaultInterval = time.Second
+
+// localDirs hold per-machine state that is not mirrored: mc sync's cache
+// and worker process logs.
+var localDirs = map[string]bool{"cache": true, "logs": true}
+
+// File is a stored file, by slash-separated path inside .mission. A JSONL
+// file comes with its lines instead of its data.
+type File struct {
+	Path    string
+	Data    []byte
+	Lines   [][]byte
+	ModTime time.Time
+}
+
+// Backend stores the files of projects.
+type Backend interface {
+	// Load returns every file stored for project.
+	Load(ctx context.Context, project string) ([]File, error)
+	// Put stores f in place of any file at its path.
+	Put(ctx context.Context, project string, f File) error
+	// Append adds lines to a JSONL file, numbered on from first.
+	Append(ctx context.Context, project, path string, first int, lines [][]byte) error
+	// Delete removes the file at path.
+	Delete(ctx context.Context, project, path string) error
+}
+
+// mirrored is what a Mirror last wrote for a file.
+type mirrored struct {
+	info   os.FileInfo
+	offset int64 // JSONL: bytes up to the end of the last stored line
+	lines  int   // JSONL: lines stored
+}
+
+// Mirror copies one project's .mission directory to a Backend.
+type Mirror struct {
+	backend    Backend
+	project    string
+	missionDir string
+
+	mu   sync.Mutex
+	seen map[string]mirrored
+}
+
+// NewMirror returns a Mirror of missionDir stored under project.
+func NewMirror(b Backend, project, missionDir string) *Mirror {
+	return &Mirror{backend: b, project: project, missionDir: missionDir, seen: map[string]mirrored{}}
+}
+
+// Restore writes the stored files into the mission directory, replacing
+// the copies there. With nothing stored yet, the directory is imported
+// instead, so a project moves to the database on its first start.
+func (m *Mirror) Restore(ctx context.Context) error {
+	files, err := m.backend.Load(ctx, m.project)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return m.Sync(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range files {
+		path := filepath.Join(m.missionDir, filepath.FromSlash(f.Path))
+		data := f.Data
+		if isJSONL(f.Path) {
+			data = joinLines(f.Lines)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		if !f.ModTime.IsZero() {
+			_ = os.Chtimes(path, f.ModTime, f.ModTime)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		m.seen[f.Path] = mirrored{info: info, offset: int64(len(data)), lines: len(f.Lines)}
+	}
+	return nil
+}
+
+// Sync stores every file changed since the last Sync and deletes the
+// files that are gone.
+func (m *Mirror) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	present := map[string]bool{}
+	err := filepath.WalkDir(m.missionDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(m.missionDir, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if localDirs[rel] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		present[rel] = true
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		prev, ok := m.seen[rel]
+		if ok && info.Size() == prev.info.Size() && info.ModTime().Equal(prev.info.ModTime()) {
+			return nil
+		}
+		return m.store(ctx, rel, path, info, prev, ok)
+	})
+	if err != nil {
+		return err
+	}
+
+	var gone []string
+	for rel := range m.seen {
+		if !present[rel] {
+			gone = append(gone, rel)
+		}
+	}
+	sort.Strings(gone)
+	for _, rel := range gone {
+		if err := m.backend.Delete(ctx, m.project, rel); err != nil {
+			return err
+		}
+		delete(m.seen, rel)
+	}
+	return nil
+}
+
+// store writes one changed file. A JSONL file still the same file and no
+// shorter than what was stored has been appended to, so only its new
+// lines go; one that was replaced (the jsonl package rewrites by rename)
+// or truncated is stored afresh.
+func (m *Mirror) store(ctx context.Context, rel, path string, info os.FileInfo, prev mirrored, seen bool) error {
+	if !isJSONL(rel) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := m.backend.Put(ctx, m.project, File{Path: rel, Data: data, ModTime: info.ModTime()}); err != nil {
+			return err
+		}
+		m.seen[rel] = mirrored{info: info}
+		return nil
+	}
+
+	appended := seen && os.SameFile(info, prev.info) && info.Size() >= prev.offset
+	var offset int64
+	if appended {
+		offset = prev.offset
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	// Only whole lines: a writer may be mid-append
+	end := bytes.LastIndexByte(data, '\n') + 1
+	lines := splitLines(rel, data[:end])
+
+	if appended {
+		if len(lines) > 0 {
+			if err := m.backend.Append(ctx, m.project, rel, prev.lines, lines); err != nil {
+				return err
+			}
+		}
+		m.seen[rel] = mirrored{info: info, offset: offset + int64(end), lines: prev.lines + len(lines)}
+		return nil
+	}
+	if err := m.backend.Put(ctx, m.project, File{Path: rel, Lines: lines, ModTime: info.ModTime()}); err != nil {
+		return err
+	}
+	m.seen[rel] = mirrored{info: info, offset: int64(end), lines: len(lines)}
+	return nil
+}
+
+// Run syncs every interval until ctx is done, then once more so the last
+// writes are kept.
+func (m *Mirror) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := m.Sync(final); err != nil {
+				log.Printf("Warning: final state sync to Postgres: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := m.Sync(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Warning: state sync to Postgres: %v", err)
+			}
+		}
+	}
+}
+
+func isJSONL(path string) bool {
+	return strings.HasSuffix(path, ".jsonl")
+}
+
+// splitLines splits whole-line JSONL data. Blank lines are dropped, and so
+// are lines that aren't JSON, which the database would refuse; mc doctor
+// reports those.
+func splitLines(path string, data []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			log.Printf("Warning: %s: not storing a line that isn't JSON (see mc doctor)", path)
+			continue
+		}
+		lines = append(lines, append([]byte(nil), line...))
+	}
+	return lines
+}
+
+func joinLines(lines [][]byte) []byte {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// errNoDriver explains a build without the Postgres driver.
+var errNoDriver = fmt.Errorf("this build has no Postgres driver; build with -tags postgres (after 'go get github.com/jackc/pgx/v5')")
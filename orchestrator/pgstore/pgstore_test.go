@@ -0,0 +1,171 @@
+package pgstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
+)
+
+// memBackend is a Backend in memory, counting the calls it gets.
+type memBackend struct {
+	files   map[string]File
+	puts    int
+	appends int
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{files: map[string]File{}}
+}
+
+func (b *memBackend) Load(ctx context.Context, project string) ([]File, error) {
+	var files []File
+	for _, f := range b.files {
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (b *memBackend) Put(ctx context.Context, project string, f File) error {
+	b.puts++
+	b.files[f.Path] = f
+	return nil
+}
+
+func (b *memBackend) Append(ctx context.Context, project, path string, first int, lines [][]byte) error {
+	b.appends++
+	f := b.files[path]
+	if len(f.Lines) != first {
+		return os.ErrInvalid
+	}
+	f.Lines = append(f.Lines, lines...)
+	b.files[path] = f
+	return nil
+}
+
+func (b *memBackend) Delete(ctx context.Context, project, path string) error {
+	delete(b.files, path)
+	return nil
+}
+
+func lineStrings(f File) []string {
+	var out []string
+	for _, l := range f.Lines {
+		out = append(out, string(l))
+	}
+	return out
+}
+
+func TestMirrorSync(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "state"), 0755)
+	os.MkdirAll(filepath.Join(dir, "cache"), 0755)
+	os.WriteFile(filepath.Join(dir, "state", "stage.json"), []byte(`{"current":"design"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "cache", "runtime.json"), []byte(`{}`), 0644)
+	audit := filepath.Join(dir, "audit.jsonl")
+	jsonl.AppendLines(audit, jsonl.Options{}, []byte(`{"action":"a"}`), []byte(`{"action":"b"}`))
+
+	b := newMemBackend()
+	m := NewMirror(b, "demo", dir)
+	if err := m.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if string(b.files["state/stage.json"].Data) != `{"current":"design"}` {
+		t.Errorf("expected stage.json stored, got %+v", b.files["state/stage.json"])
+	}
+	if _, ok := b.files["cache/runtime.json"]; ok {
+		t.Error("expected the cache left out")
+	}
+	if got := lineStrings(b.files["audit.jsonl"]); len(got) != 2 {
+		t.Fatalf("expected two audit lines, got %v", got)
+	}
+
+	// Appends go as new lines; a torn last line waits for its newline
+	puts := b.puts
+	jsonl.AppendLines(audit, jsonl.Options{}, []byte(`{"action":"c"}`))
+	f, _ := os.OpenFile(audit, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"action":`)
+	f.Close()
+	if err := m.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if b.puts != puts || b.appends != 1 {
+		t.Errorf("expected one append and no rewrite, got %d puts, %d appends", b.puts-puts, b.appends)
+	}
+	if got := lineStrings(b.files["audit.jsonl"]); len(got) != 3 || got[2] != `{"action":"c"}` {
+		t.Errorf("unexpected lines %v", got)
+	}
+
+	// A rewrite replaces the lines; a deleted file is deleted
+	if err := jsonl.Rewrite(audit, jsonl.Options{}, [][]byte{[]byte(`{"action":"z"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "state", "stage.json"))
+	if err := m.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := lineStrings(b.files["audit.jsonl"]); len(got) != 1 || got[0] != `{"action":"z"}` {
+		t.Errorf("expected the rewritten lines, got %v", got)
+	}
+	if _, ok := b.files["state/stage.json"]; ok {
+		t.Error("expected stage.json deleted")
+	}
+}
+
+func TestMirrorRestore(t *testing.T) {
+	ctx := context.Background()
+	b := newMemBackend()
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	b.files["state/gates.json"] = File{Path: "state/gates.json", Data: []byte(`{}`), ModTime: stamp}
+	b.files["chat/history.jsonl"] = File{Path: "chat/history.jsonl", Lines: [][]byte{[]byte(`{"seq":1}`), []byte(`{"seq":2}`)}}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "state"), 0755)
+	os.WriteFile(filepath.Join(dir, "state", "gates.json"), []byte(`{"stale":true}`), 0644)
+	m := NewMirror(b, "demo", dir)
+	if err := m.Restore(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "state", "gates.json")); string(data) != `{}` {
+		t.Errorf("expected the stored gates.json, got %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "chat", "history.jsonl")); string(data) != "{\"seq\":1}\n{\"seq\":2}\n" {
+		t.Errorf("unexpected history %q", data)
+	}
+
+	// Restored files aren't written back; new lines append after them
+	puts := b.puts
+	jsonl.AppendLines(filepath.Join(dir, "chat", "history.jsonl"), jsonl.Options{}, []byte(`{"seq":3}`))
+	if err := m.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if b.puts != puts {
+		t.Errorf("expected no rewrites after a restore, got %d", b.puts-puts)
+	}
+	if got := lineStrings(b.files["chat/history.jsonl"]); len(got) != 3 {
+		t.Errorf("expected the new line appended, got %v", got)
+	}
+
+	// An empty database is seeded from the directory instead
+	empty := newMemBackend()
+	if err := NewMirror(empty, "demo", dir).Restore(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(empty.files["state/gates.json"].Data), "{}") {
+		t.Errorf("expected the directory imported, got %v", empty.files)
+	}
+}
+
+func TestOpenWithoutDriver(t *testing.T) {
+	if hasDriver() {
+		t.Skip("built with the Postgres driver")
+	}
+	if _, err := Open(context.Background(), "postgres://localhost/mc"); err != errNoDriver {
+		t.Errorf("expected errNoDriver, got %v", err)
+	}
+}
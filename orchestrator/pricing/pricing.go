@@ -0,0 +1,161 @@
+// Package pricing holds the per-model token prices used to estimate
+// costs. Embedded defaults cover the Claude tiers and the OpenAI models
+// workers run on; ~/.mission-control/pricing.json overrides or adds
+// models without a rebuild:
+//
+//	{
+//	  "version": 1,
+//	  "updated": "2026-10-01",
+//	  "models": {
+//	    "sonnet": {"input_per_mtok": 3, "output_per_mtok": 15, "cache_read_per_mtok": 0.3}
+//	  }
+//	}
+//
+// Prices are USD per million tokens. Models missing from the table, such
+// as offline Ollama models, cost nothing.
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Version is the newest pricing file format this build reads.
+const Version = 1
+
+//go:embed defaults.json
+var defaultsJSON []byte
+
+// Price is a model's cost in USD per million tokens.
+type Price struct {
+	InputPerMTok     float64 `json:"input_per_mtok"`
+	OutputPerMTok    float64 `json:"output_per_mtok"`
+	CacheReadPerMTok float64 `json:"cache_read_per_mtok,omitempty"`
+}
+
+// Cost prices input, output and cache-read token counts.
+func (p Price) Cost(input, output, cacheRead int) float64 {
+	return (float64(input)*p.InputPerMTok +
+		float64(output)*p.OutputPerMTok +
+		float64(cacheRead)*p.CacheReadPerMTok) / 1_000_000
+}
+
+// Table is a versioned set of model prices.
+type Table struct {
+	Version int              `json:"version"`
+	Updated string           `json:"updated,omitempty"`
+	Models  map[string]Price `json:"models"`
+}
+
+// Defaults returns a copy of the embedded price table.
+func Defaults() *Table {
+	t, err := parse(defaultsJSON)
+	if err != nil {
+		panic("pricing: bad embedded defaults: " + err.Error())
+	}
+	return t
+}
+
+func parse(data []byte) (*Table, error) {
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	if t.Version > Version {
+		return nil, fmt.Errorf("pricing version %d is newer than supported version %d", t.Version, Version)
+	}
+	if t.Models == nil {
+		t.Models = map[string]Price{}
+	}
+	return &t, nil
+}
+
+// Path is ~/.mission-control/pricing.json.
+func Path() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".mission-control", "pricing.json")
+}
+
+// Load reads the pricing file at path over the embedded defaults: its
+// models replace or extend the default ones. A missing file yields the
+// defaults.
+func Load(path string) (*Table, error) {
+	t := Defaults()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+	file, err := parse(data)
+	if err != nil {
+		return t, fmt.Errorf("%s: %w", path, err)
+	}
+	t.Version = file.Version
+	if file.Updated != "" {
+		t.Updated = file.Updated
+	}
+	for model, price := range file.Models {
+		t.Models[strings.ToLower(model)] = price
+	}
+	return t, nil
+}
+
+var (
+	currentOnce sync.Once
+	current     *Table
+)
+
+// Current is the table loaded from Path on first use. A broken file is
+// logged and the defaults are used instead.
+func Current() *Table {
+	currentOnce.Do(func() {
+		t, err := Load(Path())
+		if err != nil {
+			log.Printf("[pricing] %v; using built-in prices", err)
+		}
+		current = t
+	})
+	return current
+}
+
+// Lookup finds the price of model: a Claude tier ("sonnet"), a model ID
+// ("claude-sonnet-4-20250514", "gpt-4o-mini-2024-07-18") or a
+// "<provider>:<name>" ledger model. IDs match the longest table entry
+// they start with or, for Claude IDs, the tier they name. Ollama models
+// are never priced.
+func (t *Table) Lookup(model string) (Price, bool) {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if m == "" || strings.HasPrefix(m, "ollama:") {
+		return Price{}, false
+	}
+	for _, provider := range []string{"anthropic:", "openai:"} {
+		m = strings.TrimPrefix(m, provider)
+	}
+	if p, ok := t.Models[m]; ok {
+		return p, true
+	}
+	best := ""
+	for key := range t.Models {
+		if len(key) > len(best) && (strings.HasPrefix(m, key) || strings.Contains(m, "-"+key)) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t.Models[best], true
+}
+
+// Cost prices token counts for model; unknown models cost nothing.
+func (t *Table) Cost(model string, input, output, cacheRead int) float64 {
+	p, _ := t.Lookup(model)
+	return p.Cost(input, output, cacheRead)
+}
@@ -0,0 +1,73 @@
+package pricing
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	table := Defaults()
+	tests := []struct {
+		model string
+		want  string
+		ok    bool
+	}{
+		{"sonnet", "sonnet", true},
+		{"claude-sonnet-4-20250514", "sonnet", true},
+		{"claude-3-5-haiku", "haiku", true},
+		{"anthropic:claude-opus-4", "opus", true},
+		{"gpt-4o-mini-2024-07-18", "gpt-4o-mini", true},
+		{"openai:gpt-4o", "gpt-4o", true},
+		{"ollama:llama3.1:8b", "", false},
+		{"mystery-model", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := table.Lookup(tt.model)
+		if ok != tt.ok || (ok && got != table.Models[tt.want]) {
+			t.Errorf("Lookup(%q) = %+v, %v; want %s's price", tt.model, got, ok, tt.want)
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	table := Defaults()
+	// 1M input at $3 + 100K output at $15 + 1M cache reads at $0.30
+	if got := table.Cost("sonnet", 1_000_000, 100_000, 1_000_000); math.Abs(got-4.8) > 1e-9 {
+		t.Errorf("sonnet cost = %f, want 4.8", got)
+	}
+	if got := table.Cost("ollama:qwen2.5", 1_000_000, 1_000_000, 0); got != 0 {
+		t.Errorf("offline cost = %f, want 0", got)
+	}
+}
+
+func TestLoadOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.json")
+
+	table, err := Load(path)
+	if err != nil || table.Models["opus"].InputPerMTok != 15 {
+		t.Fatalf("missing file: %+v, %v; want the defaults", table, err)
+	}
+
+	os.WriteFile(path, []byte(`{"version":1,"updated":"2026-11-01","models":{"Sonnet":{"input_per_mtok":2,"output_per_mtok":10},"my-model":{"input_per_mtok":1,"output_per_mtok":1}}}`), 0644)
+	table, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Models["sonnet"].InputPerMTok != 2 || table.Updated != "2026-11-01" {
+		t.Errorf("sonnet = %+v (updated %s), want the file's price", table.Models["sonnet"], table.Updated)
+	}
+	if _, ok := table.Lookup("my-model-v2"); !ok {
+		t.Errorf("added model not found")
+	}
+	if table.Models["haiku"].InputPerMTok != 0.25 {
+		t.Errorf("haiku = %+v, want the default kept", table.Models["haiku"])
+	}
+
+	os.WriteFile(path, []byte(`{"version":2,"models":{}}`), 0644)
+	if table, err = Load(path); err == nil || table.Models["sonnet"].InputPerMTok != 3 {
+		t.Errorf("newer version: err %v, want an error and the defaults", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package problem writes standardized application/problem+json error
+// responses (RFC 9457) with machine-readable codes, shared by the api,
+// openclaw and projects handlers so clients can branch on Code instead of
+// parsing messages.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// Machine-readable error codes.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidBody      = "invalid_body"
+	CodeValidation       = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeRateLimited      = "rate_limited"
+	CodeSpendLimit       = "spend_limit_exceeded" // the global spend limits' breaker is tripped
+	CodeInternal         = "internal_error"
+	CodeNotImplemented   = "not_implemented"
+	CodeUnavailable      = "service_unavailable"
+	CodeCommandFailed    = "command_failed"     // an mc subprocess exited non-zero
+	CodeUpstream         = "upstream_error"     // a gateway or proxied service failed
+	CodeResponseTooLarge = "response_too_large" // a file over the response cap; fetch it in ranges
+)
+
+// Problem is the error envelope.
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+
+	// Error mirrors Message for clients written against the old
+	// {"error": "..."} responses.
+	Error string `json:"error"`
+}
+
+// New builds a Problem for the given status, code and message.
+func New(status int, code, message string) *Problem {
+	if code == "" {
+		code = CodeForStatus(status)
+	}
+	return &Problem{
+		Type:    "urn:missioncontrol:problem:" + code,
+		Title:   http.StatusText(status),
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Error:   message,
+	}
+}
+
+// WithDetails attaches structured details and returns p.
+func (p *Problem) WithDetails(details map[string]interface{}) *Problem {
+	p.Details = details
+	return p
+}
+
+// Write sends p. The request ID is taken from the X-Request-ID response
+// header when the request ID middleware has set one.
+func (p *Problem) Write(w http.ResponseWriter) {
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get("X-Request-ID")
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Write sends a problem response with the given code (derived from status
+// when empty) and optional details.
+func Write(w http.ResponseWriter, status int, code, message string, details map[string]interface{}) {
+	New(status, code, message).WithDetails(details).Write(w)
+}
+
+// Error sends a problem response whose code is derived from status.
+func Error(w http.ResponseWriter, status int, message string) {
+	New(status, "", message).Write(w)
+}
+
+// MethodNotAllowed sends the standard 405 problem.
+func MethodNotAllowed(w http.ResponseWriter) {
+	New(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed").Write(w)
+}
+
+// NotFound sends a 404 problem.
+func NotFound(w http.ResponseWriter, message string) {
+	New(http.StatusNotFound, CodeNotFound, message).Write(w)
+}
+
+// InvalidBody sends a 400 problem for an undecodable request body.
+func InvalidBody(w http.ResponseWriter, err error) {
+	msg := "invalid request body"
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	New(http.StatusBadRequest, CodeInvalidBody, msg).Write(w)
+}
+
+// Validation sends a 400 problem for a well-formed body that fails
+// validation (e.g. a missing required field).
+func Validation(w http.ResponseWriter, message string) {
+	New(http.StatusBadRequest, CodeValidation, message).Write(w)
+}
+
+// CodeForStatus returns the default code for an HTTP status.
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
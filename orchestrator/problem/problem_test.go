@@ -0,0 +1,93 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("Content-Type = %q, want %q", ct, ContentType)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	return p
+}
+
+func TestErrorDerivesCode(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusBadRequest, CodeBadRequest},
+		{http.StatusUnauthorized, CodeUnauthorized},
+		{http.StatusNotFound, CodeNotFound},
+		{http.StatusConflict, CodeConflict},
+		{http.StatusBadGateway, CodeUpstream},
+		{http.StatusInternalServerError, CodeInternal},
+		{http.StatusInsufficientStorage, CodeInternal},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		Error(w, tt.status, "boom")
+		p := decode(t, w)
+		if w.Code != tt.status || p.Status != tt.status {
+			t.Errorf("status = %d/%d, want %d", w.Code, p.Status, tt.status)
+		}
+		if p.Code != tt.code {
+			t.Errorf("status %d: code = %q, want %q", tt.status, p.Code, tt.code)
+		}
+		if p.Message != "boom" || p.Error != "boom" {
+			t.Errorf("message/error = %q/%q, want boom", p.Message, p.Error)
+		}
+		if p.Title != http.StatusText(tt.status) {
+			t.Errorf("title = %q", p.Title)
+		}
+	}
+}
+
+func TestWriteDetailsAndRequestID(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("X-Request-ID", "req-1")
+	Write(w, http.StatusInternalServerError, CodeCommandFailed, "mc failed", map[string]interface{}{"output": "nope"})
+
+	p := decode(t, w)
+	if p.Code != CodeCommandFailed {
+		t.Errorf("code = %q", p.Code)
+	}
+	if p.Type != "urn:missioncontrol:problem:command_failed" {
+		t.Errorf("type = %q", p.Type)
+	}
+	if p.RequestID != "req-1" {
+		t.Errorf("request_id = %q, want req-1", p.RequestID)
+	}
+	if p.Details["output"] != "nope" {
+		t.Errorf("details = %v", p.Details)
+	}
+}
+
+func TestHelpers(t *testing.T) {
+	w := httptest.NewRecorder()
+	MethodNotAllowed(w)
+	if p := decode(t, w); w.Code != http.StatusMethodNotAllowed || p.Code != CodeMethodNotAllowed {
+		t.Errorf("MethodNotAllowed: %d %q", w.Code, p.Code)
+	}
+
+	w = httptest.NewRecorder()
+	InvalidBody(w, errors.New("unexpected EOF"))
+	if p := decode(t, w); p.Code != CodeInvalidBody || p.Message != "invalid request body: unexpected EOF" {
+		t.Errorf("InvalidBody: %q %q", p.Code, p.Message)
+	}
+
+	w = httptest.NewRecorder()
+	Validation(w, "title is required")
+	if p := decode(t, w); w.Code != http.StatusBadRequest || p.Code != CodeValidation {
+		t.Errorf("Validation: %d %q", w.Code, p.Code)
+	}
+}
@@ -0,0 +1,261 @@
+// Package recording writes worker output as asciicast v2 recordings in
+// .mission/recordings/<worker-id>.cast, so reviewers can replay what a
+// worker printed with its original timing (asciinema play, the
+// asciinema web player, or GET /api/recordings/{id}/play).
+//
+// A recording is a JSON header line followed by one event per line:
+//
+//	{"version": 2, "width": 120, "height": 40, "timestamp": 1760000000, "title": "w-1a2b"}
+//	[0.52, "o", "Reading internal/auth...\r\n"]
+//
+// Worker output is line-based, so each line is one "o" event; stderr is
+// shown in red. Secret values are masked before anything is written.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+// Ext is the recording file extension.
+const Ext = ".cast"
+
+// Terminal size written to the header; workers have no PTY, so this only
+// sizes the player.
+const (
+	Width  = 120
+	Height = 40
+)
+
+// Header is the first line of a recording.
+type Header struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title,omitempty"`
+}
+
+// Event is one recorded chunk of output, Time seconds after the start.
+type Event struct {
+	Time float64
+	Data string
+}
+
+// Info describes a recording on disk.
+type Info struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title,omitempty"`
+	Started  time.Time `json:"started"`
+	Duration float64   `json:"duration"` // seconds, to the last event
+	Events   int       `json:"events"`
+	Size     int64     `json:"size"`
+}
+
+// ErrNotFound is returned for a recording that doesn't exist.
+var ErrNotFound = errors.New("recording not found")
+
+// Path is where the recording for id is kept in dir.
+func Path(dir, id string) string {
+	return filepath.Join(dir, filepath.Base(id)+Ext)
+}
+
+type session struct {
+	f     *os.File
+	start time.Time
+}
+
+// Recorder records the output of each worker to its own file in dir. It
+// is safe for concurrent use; a nil Recorder records nothing.
+type Recorder struct {
+	dir string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewRecorder records into dir (e.g. .mission/recordings).
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir, sessions: make(map[string]*session)}
+}
+
+// Dir returns where recordings are written.
+func (r *Recorder) Dir() string {
+	return r.dir
+}
+
+// Write records one line of output from id's stream ("stdout" or
+// "stderr"), starting the recording on the first line. Errors are
+// logged to stderr; recording never fails the worker.
+func (r *Recorder) Write(id, stream, line string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		var err error
+		if s, err = r.open(id); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: recording %s: %v\n", id, err)
+			return
+		}
+		r.sessions[id] = s
+	}
+	line = secrets.Redact(line)
+	if stream == "stderr" {
+		line = "\x1b[31m" + line + "\x1b[0m"
+	}
+	event, _ := json.Marshal([]interface{}{
+		round(time.Since(s.start).Seconds()),
+		"o",
+		line + "\r\n",
+	})
+	s.f.Write(append(event, '\n'))
+}
+
+// Finish closes id's recording.
+func (r *Recorder) Finish(id string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok {
+		s.f.Close()
+		delete(r.sessions, id)
+	}
+}
+
+// open creates id's recording, replacing an earlier one for the same ID.
+func (r *Recorder) open(id string) (*session, error) {
+	if err := ensureDir(r.dir); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(Path(r.dir, id), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	header, _ := json.Marshal(Header{Version: 2, Width: Width, Height: Height, Timestamp: start.Unix(), Title: id})
+	if _, err := f.Write(append(header, '\n')); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &session{f: f, start: start}, nil
+}
+
+// ensureDir creates dir with a .gitignore, so auto-commits of .mission/
+// leave recordings out.
+func ensureDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		return os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	return nil
+}
+
+// round keeps event times to the millisecond.
+func round(seconds float64) float64 {
+	return float64(int64(seconds*1000)) / 1000
+}
+
+// Read parses the recording at path.
+func Read(path string) (Header, []Event, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Header{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return Header{}, nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a recording: the header line, then its "o" events. Other
+// event types and malformed lines, such as a partial last line of a
+// recording still being written, are skipped.
+func Parse(rd io.Reader) (Header, []Event, error) {
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	var h Header
+	if !sc.Scan() {
+		return h, nil, fmt.Errorf("empty recording")
+	}
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil || h.Version != 2 {
+		return h, nil, fmt.Errorf("not an asciicast v2 recording")
+	}
+	events := []Event{}
+	for sc.Scan() {
+		var raw []interface{}
+		if json.Unmarshal(sc.Bytes(), &raw) != nil || len(raw) != 3 {
+			continue
+		}
+		t, ok1 := raw[0].(float64)
+		kind, ok2 := raw[1].(string)
+		data, ok3 := raw[2].(string)
+		if !ok1 || !ok2 || !ok3 || kind != "o" {
+			continue
+		}
+		events = append(events, Event{Time: t, Data: data})
+	}
+	return h, events, sc.Err()
+}
+
+// List describes the recordings in dir, newest first. A missing dir has
+// none.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Info{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	infos := []Info{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), Ext) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		h, events, err := Read(path)
+		if err != nil {
+			continue
+		}
+		info := Info{
+			ID:      strings.TrimSuffix(e.Name(), Ext),
+			Title:   h.Title,
+			Started: time.Unix(h.Timestamp, 0).UTC(),
+			Events:  len(events),
+		}
+		if n := len(events); n > 0 {
+			info.Duration = events[n-1].Time
+		}
+		if fi, err := e.Info(); err == nil {
+			info.Size = fi.Size()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].Started.Equal(infos[j].Started) {
+			return infos[i].Started.After(infos[j].Started)
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos, nil
+}
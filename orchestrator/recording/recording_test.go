@@ -0,0 +1,70 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no secrets store
+	dir := filepath.Join(t.TempDir(), "recordings")
+	r := NewRecorder(dir)
+	r.Write("w-1", "stdout", "Reading auth.go")
+	r.Write("w-1", "stderr", "warning: deprecated")
+	r.Write("w-2", "stdout", "other worker")
+	r.Finish("w-1")
+	r.Finish("w-2")
+	r.Write("w-1", "stdout", "after finish starts over")
+	r.Finish("w-1")
+
+	h, events, err := Read(Path(dir, "w-2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Version != 2 || h.Title != "w-2" || h.Width != Width {
+		t.Errorf("header = %+v", h)
+	}
+	if len(events) != 1 || events[0].Data != "other worker\r\n" {
+		t.Errorf("w-2 events = %+v", events)
+	}
+
+	// A worker that records again replaces its recording
+	_, events, _ = Read(Path(dir, "w-1"))
+	if len(events) != 1 || !strings.HasPrefix(events[0].Data, "after finish") {
+		t.Errorf("w-1 events = %+v", events)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err != nil {
+		t.Errorf("recordings dir has no .gitignore: %v", err)
+	}
+	infos, err := List(dir)
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("List = %+v, %v", infos, err)
+	}
+	if _, _, err := Read(Path(dir, "missing")); err != ErrNotFound {
+		t.Errorf("missing recording: err %v, want ErrNotFound", err)
+	}
+	var nilRecorder *Recorder
+	nilRecorder.Write("w-3", "stdout", "ignored")
+	nilRecorder.Finish("w-3")
+}
+
+func TestParse(t *testing.T) {
+	cast := `{"version": 2, "width": 80, "height": 24, "timestamp": 1700000000}
+[0.5, "o", "first\r\n"]
+[0.7, "i", "typed"]
+[1.25, "o", "\u001b[31merror\u001b[0m\r\n"]
+[1.3, "o", "partial`
+	_, events, err := Parse(strings.NewReader(cast))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Time != 1.25 || events[1].Data != "\x1b[31merror\x1b[0m\r\n" {
+		t.Errorf("events = %+v", events)
+	}
+	if _, _, err := Parse(strings.NewReader(`{"version": 1}`)); err == nil {
+		t.Error("asciicast v1 accepted")
+	}
+}
@@ -0,0 +1,313 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/specs"
+)
+
+// ErrUnknownTag is returned for a since tag the repository doesn't have.
+var ErrUnknownTag = errors.New("unknown tag")
+
+// decisionTypes are the finding types that record a decision.
+var decisionTypes = map[string]bool{"decision": true, "design_decision": true}
+
+// ReleaseNotes trace what a release delivers back to the specs it
+// implements: the tasks completed since the previous release tag, the
+// specs they are linked to, the decisions they recorded and the gate
+// approvals given along the way.
+type ReleaseNotes struct {
+	Project      string               `json:"project"`
+	Version      string               `json:"version,omitempty"` // the tag being cut
+	Since        string               `json:"since,omitempty"`   // the previous release tag
+	SinceDate    string               `json:"since_date,omitempty"`
+	GeneratedAt  string               `json:"generated_at"`
+	Stage        string               `json:"stage"`
+	Requirements []ReleaseRequirement `json:"requirements"`
+	Tasks        []ReleaseTask        `json:"tasks"`
+	Decisions    []Finding            `json:"decisions"`
+	Approvals    []GateApproval       `json:"approvals"`
+}
+
+// ReleaseTask is a task completed in the release.
+type ReleaseTask struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Stage       string   `json:"stage"`
+	Zone        string   `json:"zone,omitempty"`
+	Spec        string   `json:"spec,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	CompletedAt string   `json:"completed_at"`
+}
+
+// ReleaseRequirement is a spec the release's tasks implement.
+type ReleaseRequirement struct {
+	Spec    string   `json:"spec"`
+	Title   string   `json:"title"`
+	Status  string   `json:"status"`
+	Version int      `json:"version,omitempty"` // approved spec version
+	Tasks   []string `json:"tasks"`
+}
+
+// GateApproval is a gate approved in the release.
+type GateApproval struct {
+	Stage      string `json:"stage"`
+	ApprovedAt string `json:"approved_at"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// LastTag returns the most recent tag reachable from HEAD in dir's git
+// repository, or "" if there is none.
+func LastTag(dir string) string {
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// tagDate returns when tag's commit was made.
+func tagDate(dir, tag string) (time.Time, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%cI", tag+"^{commit}", "--")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w %q", ErrUnknownTag, tag)
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+}
+
+// BuildReleaseNotes compiles the notes for version (may be empty) from the
+// .mission/ directory, covering what happened after the since tag. An
+// empty since means the last tag in the project's git history; a project
+// without tags gets everything.
+func BuildReleaseNotes(missionDir, since, version string) (*ReleaseNotes, error) {
+	if _, err := os.Stat(missionDir); err != nil {
+		return nil, fmt.Errorf("mission directory not accessible: %w", err)
+	}
+	projectDir := filepath.Dir(missionDir)
+	if since == "" {
+		since = LastTag(projectDir)
+	}
+	var from time.Time
+	if since != "" {
+		if strings.HasPrefix(since, "-") {
+			return nil, fmt.Errorf("%w %q", ErrUnknownTag, since)
+		}
+		t, err := tagDate(projectDir, since)
+		if err != nil {
+			return nil, err
+		}
+		from = t
+	}
+
+	n := &ReleaseNotes{
+		Project:      filepath.Base(projectDir),
+		Version:      version,
+		Since:        since,
+		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+		Requirements: []ReleaseRequirement{},
+		Tasks:        []ReleaseTask{},
+		Decisions:    []Finding{},
+		Approvals:    []GateApproval{},
+	}
+	if !from.IsZero() {
+		n.SinceDate = from.UTC().Format(time.RFC3339)
+	}
+	after := func(ts string) bool {
+		if from.IsZero() {
+			return true
+		}
+		t, err := time.Parse(time.RFC3339, ts)
+		return err == nil && t.After(from)
+	}
+
+	var stage struct {
+		Current string `json:"current"`
+	}
+	_ = readJSON(filepath.Join(missionDir, "state", "stage.json"), &stage)
+	n.Stage = stage.Current
+
+	// tasks.jsonl: the last line for a task is current
+	byID := map[string]ReleaseTask{}
+	var order []string
+	_ = eachJSONL(filepath.Join(missionDir, "state", "tasks.jsonl"), func(line []byte) {
+		var t struct {
+			ReleaseTask
+			Status    string `json:"status"`
+			UpdatedAt string `json:"updated_at"`
+		}
+		if json.Unmarshal(line, &t) != nil || t.ID == "" {
+			return
+		}
+		if _, seen := byID[t.ID]; !seen {
+			order = append(order, t.ID)
+		}
+		if (t.Status == "done" || t.Status == "complete") && after(t.UpdatedAt) {
+			t.CompletedAt = t.UpdatedAt
+			byID[t.ID] = t.ReleaseTask
+		} else {
+			byID[t.ID] = ReleaseTask{}
+		}
+	})
+	released := map[string]bool{}
+	for _, id := range order {
+		if t := byID[id]; t.ID != "" {
+			n.Tasks = append(n.Tasks, t)
+			released[id] = true
+		}
+	}
+	sort.SliceStable(n.Tasks, func(i, j int) bool {
+		return indexOf(Stages, n.Tasks[i].Stage) < indexOf(Stages, n.Tasks[j].Stage)
+	})
+
+	// Requirements: the specs the released tasks implement
+	states, _ := specs.Load(missionDir)
+	reqs := map[string]*ReleaseRequirement{}
+	var specIDs []string
+	for _, t := range n.Tasks {
+		if t.Spec == "" {
+			continue
+		}
+		req, ok := reqs[t.Spec]
+		if !ok {
+			st := states[t.Spec]
+			if st.Status == "" {
+				st.Status = specs.StatusDraft
+			}
+			req = &ReleaseRequirement{Spec: t.Spec, Title: specTitle(missionDir, t.Spec), Status: st.Status, Version: st.Version}
+			reqs[t.Spec] = req
+			specIDs = append(specIDs, t.Spec)
+		}
+		req.Tasks = append(req.Tasks, t.ID)
+	}
+	sort.Strings(specIDs)
+	for _, id := range specIDs {
+		n.Requirements = append(n.Requirements, *reqs[id])
+	}
+
+	for _, g := range Findings(missionDir) {
+		for _, f := range g.Findings {
+			if released[f.TaskID] && decisionTypes[f.Type] {
+				n.Decisions = append(n.Decisions, f)
+			}
+		}
+	}
+
+	var gates struct {
+		Gates map[string]gateRecord `json:"gates"`
+	}
+	_ = readJSON(filepath.Join(missionDir, "state", "gates.json"), &gates)
+	for _, name := range Stages {
+		g, ok := gates.Gates[name]
+		if ok && g.Status == "approved" && after(g.ApprovedAt) {
+			n.Approvals = append(n.Approvals, GateApproval{Stage: name, ApprovedAt: g.ApprovedAt, ApprovedBy: g.ApprovedBy, Note: g.ApprovalNote})
+		}
+	}
+	return n, nil
+}
+
+// specTitle is the first "# " heading of spec id, or its ID.
+func specTitle(missionDir, id string) string {
+	data, err := os.ReadFile(specs.Path(missionDir, id))
+	if err != nil {
+		return id
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		}
+	}
+	return id
+}
+
+// Markdown renders the release notes as a Markdown document.
+func (n *ReleaseNotes) Markdown() string {
+	var b strings.Builder
+
+	title := n.Version
+	if title == "" {
+		title = "Unreleased"
+	}
+	fmt.Fprintf(&b, "# %s: %s\n\n", n.Project, title)
+	if n.Since != "" {
+		fmt.Fprintf(&b, "_Changes since %s (%s), generated %s_\n\n", n.Since, n.SinceDate, n.GeneratedAt)
+	} else {
+		fmt.Fprintf(&b, "_First release, generated %s_\n\n", n.GeneratedAt)
+	}
+
+	b.WriteString("## Requirements\n\n")
+	if len(n.Requirements) == 0 {
+		b.WriteString("No completed task is linked to a spec.\n")
+	}
+	for _, r := range n.Requirements {
+		fmt.Fprintf(&b, "- **%s** (`%s`, %s", r.Title, r.Spec, r.Status)
+		if r.Version > 0 {
+			fmt.Fprintf(&b, " v%d", r.Version)
+		}
+		fmt.Fprintf(&b, "): %s\n", strings.Join(r.Tasks, ", "))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Completed Tasks\n\n")
+	if len(n.Tasks) == 0 {
+		b.WriteString("No tasks completed.\n\n")
+	}
+	stage := ""
+	for _, t := range n.Tasks {
+		if t.Stage != stage {
+			if stage != "" {
+				b.WriteString("\n")
+			}
+			stage = t.Stage
+			fmt.Fprintf(&b, "### %s\n\n", capitalize(stage))
+		}
+		fmt.Fprintf(&b, "- `%s` %s", t.ID, t.Name)
+		if t.Spec != "" {
+			fmt.Fprintf(&b, " (spec `%s`)", t.Spec)
+		}
+		if len(t.Labels) > 0 {
+			fmt.Fprintf(&b, " _%s_", strings.Join(t.Labels, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if stage != "" {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Decisions\n\n")
+	if len(n.Decisions) == 0 {
+		b.WriteString("No decisions recorded.\n")
+	}
+	for _, d := range n.Decisions {
+		fmt.Fprintf(&b, "- `%s` %s\n", d.TaskID, d.Summary)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Gate Approvals\n\n")
+	if len(n.Approvals) == 0 {
+		b.WriteString("No gates approved.\n")
+	}
+	for _, a := range n.Approvals {
+		fmt.Fprintf(&b, "- **%s** (%s)", a.Stage, a.ApprovedAt)
+		if a.ApprovedBy != "" {
+			fmt.Fprintf(&b, " by %s", a.ApprovedBy)
+		}
+		if a.Note != "" {
+			fmt.Fprintf(&b, " — %s", a.Note)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// git runs a git command in dir with a fixed identity and commit date.
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_AUTHOR_DATE=2026-02-01T00:00:00Z", "GIT_COMMITTER_DATE=2026-02-01T00:00:00Z")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// newReleaseMission is a mission tagged v1.0.0 on 2026-02-01 with work
+// done before and after the tag.
+func newReleaseMission(t *testing.T) string {
+	t.Helper()
+	mission := newMission(t)
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"release"}`)
+	writeFile(t, filepath.Join(mission, "state", "tasks.jsonl"),
+		`{"id":"t1","name":"Research","stage":"discovery","status":"done","updated_at":"2026-01-15T00:00:00Z"}`+"\n"+
+			`{"id":"t2","name":"Login form","stage":"implement","status":"in_progress","spec":"login","updated_at":"2026-02-03T00:00:00Z"}`+"\n"+
+			`{"id":"t3","name":"Login design","stage":"design","status":"complete","spec":"login","updated_at":"2026-02-02T00:00:00Z"}`+"\n"+
+			`{"id":"t4","name":"Pending","stage":"implement","status":"pending","updated_at":"2026-02-02T00:00:00Z"}`+"\n"+
+			`{"id":"t2","name":"Login form","stage":"implement","status":"complete","spec":"login","labels":["security"],"updated_at":"2026-02-04T00:00:00Z"}`+"\n")
+	writeFile(t, filepath.Join(mission, "state", "gates.json"), `{"gates":{
+		"discovery":{"status":"approved","approved_at":"2026-01-01T00:00:00Z","approval_note":"scope agreed"},
+		"design":{"status":"approved","approved_at":"2026-02-05T00:00:00Z","approval_note":"mockups signed off","approved_by":"Bob"}}}`)
+	writeFile(t, filepath.Join(mission, "state", "specs.json"), `{"specs":{"login":{"status":"approved","version":2,"hash":"x"}}}`)
+	writeFile(t, filepath.Join(mission, "specs", "login.md"), "# Login\n\nUsers sign in.\n")
+	writeFile(t, filepath.Join(mission, "findings", "t2.json"), `[{"type":"decision","summary":"Sessions expire after 1h"},{"type":"risk","summary":"Rate limiting missing","severity":"high"}]`)
+	writeFile(t, filepath.Join(mission, "findings", "t4.json"), `[{"type":"decision","summary":"Not released yet"}]`)
+
+	project := filepath.Dir(mission)
+	git(t, project, "init", "-q")
+	git(t, project, "add", "-A")
+	git(t, project, "commit", "-q", "-m", "v1")
+	git(t, project, "tag", "v1.0.0")
+	return mission
+}
+
+func TestBuildReleaseNotesSinceLastTag(t *testing.T) {
+	mission := newReleaseMission(t)
+	n, err := BuildReleaseNotes(mission, "", "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n.Since != "v1.0.0" || n.SinceDate != "2026-02-01T00:00:00Z" {
+		t.Errorf("expected since v1.0.0 at 2026-02-01, got %q at %q", n.Since, n.SinceDate)
+	}
+	if len(n.Tasks) != 2 || n.Tasks[0].ID != "t3" || n.Tasks[1].ID != "t2" {
+		t.Fatalf("expected t3 then t2 in stage order, got %+v", n.Tasks)
+	}
+	if len(n.Requirements) != 1 {
+		t.Fatalf("expected 1 requirement, got %+v", n.Requirements)
+	}
+	r := n.Requirements[0]
+	if r.Spec != "login" || r.Title != "Login" || r.Version != 2 || strings.Join(r.Tasks, ",") != "t3,t2" {
+		t.Errorf("unexpected requirement: %+v", r)
+	}
+	if len(n.Decisions) != 1 || n.Decisions[0].Summary != "Sessions expire after 1h" {
+		t.Errorf("expected only t2's decision, got %+v", n.Decisions)
+	}
+	if len(n.Approvals) != 1 || n.Approvals[0].Stage != "design" || n.Approvals[0].Note != "mockups signed off" {
+		t.Errorf("expected only the design approval, got %+v", n.Approvals)
+	}
+
+	md := n.Markdown()
+	for _, want := range []string{"# demo: v1.1.0", "since v1.0.0", "**Login** (`login`, approved v2): t3, t2", "### Implement", "- `t2` Login form (spec `login`) _security_", "Sessions expire after 1h", "**design** (2026-02-05T00:00:00Z) by Bob — mockups signed off"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestBuildReleaseNotesWithoutTags(t *testing.T) {
+	mission := newMission(t)
+	n, err := BuildReleaseNotes(mission, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Since != "" || len(n.Approvals) != 2 {
+		t.Errorf("expected every approval without a tag, got since %q and %+v", n.Since, n.Approvals)
+	}
+	if !strings.Contains(n.Markdown(), "# demo: Unreleased") {
+		t.Error("expected an Unreleased title")
+	}
+	if _, err := BuildReleaseNotes(mission, "v9.9.9", ""); err == nil {
+		t.Error("expected error for unknown since tag")
+	}
+}
@@ -0,0 +1,176 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Markdown renders the report as a Markdown document.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Mission Report: %s\n\n", r.Project)
+	fmt.Fprintf(&b, "_Generated %s_\n\n", r.GeneratedAt)
+	if r.CurrentStage != "" {
+		fmt.Fprintf(&b, "**Current stage:** %s\n\n", r.CurrentStage)
+	}
+
+	b.WriteString("## Stages\n\n")
+	b.WriteString("| Stage | Status | Tasks | Gate | Approved |\n")
+	b.WriteString("|-------|--------|-------|------|----------|\n")
+	for _, s := range r.Stages {
+		fmt.Fprintf(&b, "| %s | %s | %d/%d | %s | %s |\n",
+			s.Name, s.Status, s.TasksDone, s.TasksTotal, s.GateStatus, mdCell(s.ApprovedAt))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Gate Approvals\n\n")
+	approvals := 0
+	for _, s := range r.Stages {
+		if s.GateStatus != "approved" {
+			continue
+		}
+		approvals++
+		fmt.Fprintf(&b, "- **%s** (%s)", s.Name, s.ApprovedAt)
+		if s.ApprovedBy != "" {
+			fmt.Fprintf(&b, " by %s", s.ApprovedBy)
+		}
+		if s.ApprovalNote != "" {
+			fmt.Fprintf(&b, " — %s", s.ApprovalNote)
+		}
+		b.WriteString("\n")
+	}
+	if approvals == 0 {
+		b.WriteString("No gates approved yet.\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Key Decisions\n\n")
+	if len(r.Decisions) == 0 {
+		b.WriteString("No decisions recorded.\n")
+	}
+	for _, d := range r.Decisions {
+		fmt.Fprintf(&b, "- %s\n", d)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Findings\n\n")
+	if len(r.Findings) == 0 {
+		b.WriteString("No findings recorded.\n\n")
+	}
+	for _, g := range r.Findings {
+		fmt.Fprintf(&b, "### %s (%d)\n\n", capitalize(g.Severity), len(g.Findings))
+		for _, f := range g.Findings {
+			fmt.Fprintf(&b, "- `%s` %s", f.TaskID, f.Summary)
+			if f.Type != "" {
+				fmt.Fprintf(&b, " _(%s)_", f.Type)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Token Spend\n\n")
+	if r.Tokens == nil {
+		b.WriteString("No token data available (start `mc serve` to track live usage).\n\n")
+	} else {
+		fmt.Fprintf(&b, "**Total:** %d tokens (~$%.2f)\n\n", r.Tokens.TotalTokens, r.Tokens.TotalCost)
+		if len(r.Tokens.ByPersona) > 0 {
+			b.WriteString("| Persona | Tokens | Cost (USD) |\n")
+			b.WriteString("|---------|--------|------------|\n")
+			for _, p := range r.Tokens.ByPersona {
+				fmt.Fprintf(&b, "| %s | %d | %.2f |\n", p.Persona, p.Tokens, p.Cost)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("## Timeline\n\n")
+	if len(r.Timeline) == 0 {
+		b.WriteString("No milestones recorded.\n")
+	}
+	for _, e := range r.Timeline {
+		fmt.Fprintf(&b, "- `%s` **%s** by %s", e.Timestamp, e.Action, e.Actor)
+		if e.Detail != "" {
+			fmt.Fprintf(&b, " — %s", e.Detail)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// HTML renders the report as a standalone HTML document.
+func (r *Report) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func mdCell(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"upper": strings.ToUpper,
+	"cost":  func(f float64) string { return fmt.Sprintf("%.2f", f) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Mission Report: {{.Project}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 960px; margin: 2rem auto; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #d0d7de; padding: 6px 10px; text-align: left; }
+th { background: #f6f8fa; }
+.complete { color: #1a7f37; } .current { color: #9a6700; font-weight: 600; } .upcoming { color: #656d76; }
+.sev { font-size: 0.8rem; padding: 2px 6px; border-radius: 4px; background: #eaeef2; }
+.sev-critical, .sev-high { background: #ffebe9; color: #cf222e; }
+.sev-medium { background: #fff8c5; color: #9a6700; }
+code { background: #f6f8fa; padding: 1px 4px; border-radius: 4px; }
+</style>
+</head>
+<body>
+<h1>Mission Report: {{.Project}}</h1>
+<p><em>Generated {{.GeneratedAt}}</em>{{if .CurrentStage}} · Current stage: <strong>{{.CurrentStage}}</strong>{{end}}</p>
+
+<h2>Stages</h2>
+<table>
+<tr><th>Stage</th><th>Status</th><th>Tasks</th><th>Gate</th><th>Approved</th><th>Note</th></tr>
+{{range .Stages}}<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.TasksDone}}/{{.TasksTotal}}</td><td>{{.GateStatus}}</td><td>{{.ApprovedAt}}{{if .ApprovedBy}} by {{.ApprovedBy}}{{end}}</td><td>{{.ApprovalNote}}</td></tr>
+{{end}}</table>
+
+<h2>Key Decisions</h2>
+{{if .Decisions}}<ul>{{range .Decisions}}<li>{{.}}</li>{{end}}</ul>{{else}}<p>No decisions recorded.</p>{{end}}
+
+<h2>Findings</h2>
+{{if .Findings}}{{range .Findings}}<h3><span class="sev sev-{{.Severity}}">{{upper .Severity}}</span> {{len .Findings}}</h3>
+<ul>{{range .Findings}}<li><code>{{.TaskID}}</code> {{.Summary}}{{if .Type}} <em>({{.Type}})</em>{{end}}</li>{{end}}</ul>
+{{end}}{{else}}<p>No findings recorded.</p>{{end}}
+
+<h2>Token Spend</h2>
+{{with .Tokens}}<p><strong>Total:</strong> {{.TotalTokens}} tokens (~${{cost .TotalCost}})</p>
+{{if .ByPersona}}<table><tr><th>Persona</th><th>Tokens</th><th>Cost (USD)</th></tr>
+{{range .ByPersona}}<tr><td>{{.Persona}}</td><td>{{.Tokens}}</td><td>{{cost .Cost}}</td></tr>
+{{end}}</table>{{end}}{{else}}<p>No token data available.</p>{{end}}
+
+<h2>Timeline</h2>
+{{if .Timeline}}<ul>{{range .Timeline}}<li><code>{{.Timestamp}}</code> <strong>{{.Action}}</strong> by {{.Actor}}{{if .Detail}} — {{.Detail}}{{end}}</li>{{end}}</ul>{{else}}<p>No milestones recorded.</p>{{end}}
+</body>
+</html>
+`))
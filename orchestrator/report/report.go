@@ -0,0 +1,342 @@
+// Package report compiles a stakeholder-facing mission report from the
+// .mission/ state directory. The same report backs `mc report` and
+// GET /api/report.
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// Stages is the canonical 10-stage workflow order.
+var Stages = []string{"discovery", "goal", "requirements", "planning", "design", "implement", "verify", "validate", "document", "release"}
+
+// SeverityOrder lists finding severities from most to least severe.
+// Findings without a recognised severity are grouped under "unrated".
+var SeverityOrder = []string{"critical", "high", "medium", "low", "info", "unrated"}
+
+// timelineActions are the audit actions worth showing to stakeholders.
+var timelineActions = map[string]bool{
+	"project_initialized": true,
+	"stage_advanced":      true,
+	"stage_set":           true,
+	"gate_approved":       true,
+	"checkpoint_created":  true,
+	"task_completed":      true,
+	"worker_spawned":      true,
+	"worker_completed":    true,
+}
+
+// Report is a compiled mission report.
+type Report struct {
+	Project      string          `json:"project"`
+	GeneratedAt  string          `json:"generated_at"`
+	CurrentStage string          `json:"current_stage"`
+	Stages       []StageSummary  `json:"stages"`
+	Decisions    []string        `json:"decisions"`
+	Findings     []SeverityGroup `json:"findings"`
+	Tokens       *TokenSpend     `json:"tokens,omitempty"`
+	Timeline     []TimelineEntry `json:"timeline"`
+}
+
+// StageSummary describes one workflow stage and its gate.
+type StageSummary struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"` // complete, current, upcoming
+	TasksTotal   int    `json:"tasks_total"`
+	TasksDone    int    `json:"tasks_done"`
+	GateStatus   string `json:"gate_status"`
+	ApprovedAt   string `json:"approved_at,omitempty"`
+	ApprovalNote string `json:"approval_note,omitempty"`
+	ApprovedBy   string `json:"approved_by,omitempty"`
+}
+
+// Finding is a single finding attributed to a task.
+type Finding struct {
+	TaskID   string `json:"task_id"`
+	Type     string `json:"type"`
+	Summary  string `json:"summary"`
+	Severity string `json:"severity"`
+}
+
+// SeverityGroup holds all findings of one severity.
+type SeverityGroup struct {
+	Severity string    `json:"severity"`
+	Findings []Finding `json:"findings"`
+}
+
+// TokenSpend summarises token usage for the report.
+type TokenSpend struct {
+	TotalTokens int            `json:"total_tokens"`
+	TotalCost   float64        `json:"total_cost_usd"`
+	ByPersona   []PersonaSpend `json:"by_persona"`
+}
+
+// PersonaSpend is token usage aggregated per persona.
+type PersonaSpend struct {
+	Persona string  `json:"persona"`
+	Tokens  int     `json:"tokens"`
+	Cost    float64 `json:"cost_usd"`
+}
+
+// TimelineEntry is a milestone from the audit trail.
+type TimelineEntry struct {
+	Timestamp string `json:"timestamp"`
+	Action    string `json:"action"`
+	Actor     string `json:"actor"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+type gateRecord struct {
+	Status       string `json:"status"`
+	ApprovedAt   string `json:"approved_at"`
+	ApprovalNote string `json:"approval_note"`
+	ApprovedBy   string `json:"approved_by"`
+}
+
+type taskRecord struct {
+	Stage  string `json:"stage"`
+	Status string `json:"status"`
+}
+
+type auditRecord struct {
+	Timestamp string                 `json:"timestamp"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	Details   map[string]interface{} `json:"details"`
+}
+
+// Build compiles a report from the given .mission/ directory. tok may be nil
+// when no live token data is available (e.g. when run from the CLI).
+func Build(missionDir string, tok *tokens.TokenSummary) (*Report, error) {
+	if _, err := os.Stat(missionDir); err != nil {
+		return nil, fmt.Errorf("mission directory not accessible: %w", err)
+	}
+
+	r := &Report{
+		Project:     filepath.Base(filepath.Dir(missionDir)),
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Decisions:   []string{},
+		Findings:    []SeverityGroup{},
+		Timeline:    []TimelineEntry{},
+	}
+
+	var stage struct {
+		Current string `json:"current"`
+	}
+	_ = readJSON(filepath.Join(missionDir, "state", "stage.json"), &stage)
+	r.CurrentStage = stage.Current
+
+	var gates struct {
+		Gates map[string]gateRecord `json:"gates"`
+	}
+	_ = readJSON(filepath.Join(missionDir, "state", "gates.json"), &gates)
+
+	counts := map[string][2]int{}
+	_ = eachJSONL(filepath.Join(missionDir, "state", "tasks.jsonl"), func(line []byte) {
+		var t taskRecord
+		if json.Unmarshal(line, &t) != nil {
+			return
+		}
+		c := counts[t.Stage]
+		c[0]++
+		if t.Status == "done" || t.Status == "complete" {
+			c[1]++
+		}
+		counts[t.Stage] = c
+	})
+
+	currentIdx := indexOf(Stages, r.CurrentStage)
+	for i, name := range Stages {
+		s := StageSummary{Name: name, Status: "upcoming", GateStatus: "pending"}
+		switch {
+		case currentIdx < 0:
+		case i < currentIdx:
+			s.Status = "complete"
+		case i == currentIdx:
+			s.Status = "current"
+		}
+		if g, ok := gates.Gates[name]; ok {
+			if g.Status != "" {
+				s.GateStatus = g.Status
+			}
+			s.ApprovedAt = g.ApprovedAt
+			s.ApprovalNote = g.ApprovalNote
+			s.ApprovedBy = g.ApprovedBy
+		}
+		s.TasksTotal = counts[name][0]
+		s.TasksDone = counts[name][1]
+		r.Stages = append(r.Stages, s)
+	}
+
+	var decisions []string
+	if readJSON(filepath.Join(missionDir, "orchestrator", "decisions.json"), &decisions) == nil {
+		r.Decisions = append(r.Decisions, decisions...)
+	}
+
+	r.Findings = Findings(missionDir)
+
+	if tok != nil {
+		r.Tokens = summariseTokens(tok)
+	}
+
+	_ = eachJSONL(filepath.Join(missionDir, "audit.jsonl"), func(line []byte) {
+		var a auditRecord
+		if json.Unmarshal(line, &a) != nil || !timelineActions[a.Action] {
+			return
+		}
+		r.Timeline = append(r.Timeline, TimelineEntry{
+			Timestamp: a.Timestamp,
+			Action:    a.Action,
+			Actor:     a.Actor,
+			Detail:    formatDetails(a.Details),
+		})
+	})
+
+	return r, nil
+}
+
+// Findings returns the findings recorded under missionDir, grouped by
+// severity from most to least severe.
+func Findings(missionDir string) []SeverityGroup {
+	return loadFindings(filepath.Join(missionDir, "findings"))
+}
+
+// loadFindings reads .mission/findings/*.json (arrays written by mc handoff)
+// and groups them by severity.
+func loadFindings(dir string) []SeverityGroup {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []SeverityGroup{}
+	}
+
+	grouped := map[string][]Finding{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		var raw []Finding
+		if readJSON(filepath.Join(dir, e.Name()), &raw) != nil {
+			continue
+		}
+		taskID := strings.TrimSuffix(e.Name(), ".json")
+		for _, f := range raw {
+			f.TaskID = taskID
+			f.Severity = NormaliseSeverity(f.Severity)
+			grouped[f.Severity] = append(grouped[f.Severity], f)
+		}
+	}
+
+	groups := []SeverityGroup{}
+	for _, sev := range SeverityOrder {
+		if fs := grouped[sev]; len(fs) > 0 {
+			groups = append(groups, SeverityGroup{Severity: sev, Findings: fs})
+		}
+	}
+	return groups
+}
+
+// NormaliseSeverity lower-cases a finding severity; unknown ones are
+// "unrated".
+func NormaliseSeverity(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, known := range SeverityOrder {
+		if s == known {
+			return s
+		}
+	}
+	return "unrated"
+}
+
+func summariseTokens(tok *tokens.TokenSummary) *TokenSpend {
+	byPersona := map[string]*PersonaSpend{}
+	for _, s := range tok.Sessions {
+		p := s.Persona
+		if p == "" {
+			p = "unknown"
+		}
+		ps, ok := byPersona[p]
+		if !ok {
+			ps = &PersonaSpend{Persona: p}
+			byPersona[p] = ps
+		}
+		ps.Tokens += s.TotalTokens
+		ps.Cost += s.EstimatedCost
+	}
+
+	spend := &TokenSpend{
+		TotalTokens: tok.TotalTokens,
+		TotalCost:   tok.TotalCost,
+		ByPersona:   make([]PersonaSpend, 0, len(byPersona)),
+	}
+	for _, ps := range byPersona {
+		spend.ByPersona = append(spend.ByPersona, *ps)
+	}
+	sort.Slice(spend.ByPersona, func(i, j int) bool {
+		if spend.ByPersona[i].Tokens != spend.ByPersona[j].Tokens {
+			return spend.ByPersona[i].Tokens > spend.ByPersona[j].Tokens
+		}
+		return spend.ByPersona[i].Persona < spend.ByPersona[j].Persona
+	})
+	return spend
+}
+
+func formatDetails(details map[string]interface{}) string {
+	if len(details) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, details[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func eachJSONL(path string, fn func(line []byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newMission(t *testing.T) string {
+	t.Helper()
+	mission := filepath.Join(t.TempDir(), "demo", ".mission")
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"design"}`)
+	writeFile(t, filepath.Join(mission, "state", "gates.json"), `{"gates":{
+		"discovery":{"stage":"discovery","status":"approved","approved_at":"2026-01-01T00:00:00Z","approval_note":"scope agreed","approved_by":"Alice <alice@example.com>"},
+		"goal":{"stage":"goal","status":"approved","approved_at":"2026-01-02T00:00:00Z","approval_note":"goal signed off"}}}`)
+	writeFile(t, filepath.Join(mission, "state", "tasks.jsonl"),
+		`{"id":"t1","stage":"discovery","status":"done"}`+"\n"+
+			`{"id":"t2","stage":"design","status":"pending"}`+"\n")
+	writeFile(t, filepath.Join(mission, "orchestrator", "decisions.json"), `["Use JSONL for tasks"]`)
+	writeFile(t, filepath.Join(mission, "findings", "t1.json"),
+		`[{"type":"risk","summary":"SQL injection in login","severity":"Critical"},{"type":"note","summary":"Docs stale"}]`)
+	writeFile(t, filepath.Join(mission, "audit.jsonl"),
+		`{"timestamp":"2026-01-01T00:00:00Z","action":"gate_approved","actor":"cli","details":{"stage":"discovery"}}`+"\n"+
+			`{"timestamp":"2026-01-01T00:00:01Z","action":"task_updated","actor":"cli"}`+"\n")
+	return mission
+}
+
+func TestBuildCollectsMissionState(t *testing.T) {
+	mission := newMission(t)
+	rep, err := Build(mission, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rep.Project != "demo" {
+		t.Errorf("expected project 'demo', got %q", rep.Project)
+	}
+	if len(rep.Stages) != len(Stages) {
+		t.Fatalf("expected %d stages, got %d", len(Stages), len(rep.Stages))
+	}
+	if rep.Stages[0].Status != "complete" || rep.Stages[4].Status != "current" || rep.Stages[5].Status != "upcoming" {
+		t.Errorf("unexpected stage statuses: %+v", rep.Stages[:6])
+	}
+	if rep.Stages[0].TasksDone != 1 || rep.Stages[0].ApprovalNote != "scope agreed" || rep.Stages[0].ApprovedBy != "Alice <alice@example.com>" {
+		t.Errorf("unexpected discovery summary: %+v", rep.Stages[0])
+	}
+	if len(rep.Decisions) != 1 {
+		t.Errorf("expected 1 decision, got %d", len(rep.Decisions))
+	}
+	if len(rep.Findings) != 2 || rep.Findings[0].Severity != "critical" || rep.Findings[1].Severity != "unrated" {
+		t.Errorf("unexpected findings grouping: %+v", rep.Findings)
+	}
+	if len(rep.Timeline) != 1 || rep.Timeline[0].Action != "gate_approved" {
+		t.Errorf("expected only milestone audit entries, got %+v", rep.Timeline)
+	}
+	if rep.Tokens != nil {
+		t.Error("expected no token section without a summary")
+	}
+}
+
+func TestBuildAggregatesTokensByPersona(t *testing.T) {
+	mission := newMission(t)
+	rep, err := Build(mission, &tokens.TokenSummary{
+		TotalTokens: 600,
+		TotalCost:   1.5,
+		Sessions: []tokens.SessionTokens{
+			{WorkerID: "w1", Persona: "developer", TotalTokens: 200, EstimatedCost: 0.5},
+			{WorkerID: "w2", Persona: "developer", TotalTokens: 300, EstimatedCost: 0.75},
+			{WorkerID: "w3", Persona: "reviewer", TotalTokens: 100, EstimatedCost: 0.25},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Tokens == nil || len(rep.Tokens.ByPersona) != 2 {
+		t.Fatalf("expected 2 persona rows, got %+v", rep.Tokens)
+	}
+	if rep.Tokens.ByPersona[0].Persona != "developer" || rep.Tokens.ByPersona[0].Tokens != 500 {
+		t.Errorf("expected developer first with 500 tokens, got %+v", rep.Tokens.ByPersona[0])
+	}
+}
+
+func TestRenderMarkdownAndHTML(t *testing.T) {
+	mission := newMission(t)
+	writeFile(t, filepath.Join(mission, "orchestrator", "decisions.json"), `["<script>alert(1)</script>"]`)
+	rep, err := Build(mission, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := rep.Markdown()
+	for _, want := range []string{"# Mission Report: demo", "## Gate Approvals", "by Alice <alice@example.com>", "scope agreed", "### Critical (1)", "## Timeline"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+
+	html, err := rep.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "<h1>Mission Report: demo</h1>") {
+		t.Error("html missing title")
+	}
+	if strings.Contains(html, "<script>alert(1)</script>") {
+		t.Error("html must escape decision text")
+	}
+}
+
+func TestBuildMissingDir(t *testing.T) {
+	if _, err := Build(filepath.Join(t.TempDir(), "nope"), nil); err == nil {
+		t.Error("expected error for missing mission dir")
+	}
+}
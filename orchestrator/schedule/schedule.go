@@ -0,0 +1,306 @@
+// Package schedule runs recurring work configured under "schedules" in
+// .mission/config.json:
+//
+//	"schedules": [
+//	  {"name": "nightly-deps", "cron": "0 2 * * *", "task": "Audit dependencies", "zone": "backend", "persona": "security", "labels": ["security"]},
+//	  {"name": "weekly-scan", "cron": "@weekly", "task": "Security scan", "persona": "security", "spawn": true}
+//	]
+//
+// A Scheduler checks the schedules every TickInterval, in local time. When
+// one fires it creates the task with mc task create as Actor and, with
+// "spawn", starts a worker for it with mc spawn. Runs missed while the
+// orchestrator was down are not caught up. The last run of each schedule
+// is kept in .mission/orchestrator/schedules.json, so a restart within the
+// same minute doesn't run it twice. The config is re-read on every tick.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+)
+
+// TickInterval is how often a Scheduler checks the schedules.
+const TickInterval = 15 * time.Second
+
+// StateFile keeps each schedule's last run, in .mission/orchestrator/.
+const StateFile = "schedules.json"
+
+// Actor is the user scheduled tasks are created as.
+const Actor = "scheduler"
+
+// Schedule is one entry of the "schedules" section of config.json.
+type Schedule struct {
+	Name    string   `json:"name"`
+	Cron    string   `json:"cron"`
+	Task    string   `json:"task"`            // name of the task to create
+	Stage   string   `json:"stage,omitempty"` // default: the current stage
+	Zone    string   `json:"zone,omitempty"`
+	Persona string   `json:"persona,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	Spawn   bool     `json:"spawn,omitempty"` // start a worker for the task
+}
+
+// LastRun is the outcome of a schedule's last run.
+type LastRun struct {
+	At     string `json:"at"`
+	TaskID string `json:"task_id,omitempty"`
+	Worker string `json:"worker_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Run is a schedule firing.
+type Run struct {
+	Schedule string `json:"schedule"`
+	LastRun
+}
+
+// Status is a schedule with when it runs next and how its last run went.
+// Error explains a schedule that can't run.
+type Status struct {
+	Schedule
+	NextRun string   `json:"next_run,omitempty"`
+	LastRun *LastRun `json:"last_run,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Load reads the schedules from config.json.
+func Load(missionDir string) []Schedule {
+	var cfg struct {
+		Schedules []Schedule `json:"schedules"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	return cfg.Schedules
+}
+
+// validate parses s's cron expression and checks the rest of it.
+func validate(s Schedule, seen map[string]bool) (*Cron, error) {
+	switch {
+	case s.Name == "":
+		return nil, fmt.Errorf("schedule has no name")
+	case seen[s.Name]:
+		return nil, fmt.Errorf("duplicate schedule name %q", s.Name)
+	case strings.TrimSpace(s.Task) == "":
+		return nil, fmt.Errorf("schedule %s has no task", s.Name)
+	case s.Spawn && s.Persona == "":
+		return nil, fmt.Errorf("schedule %s spawns a worker but names no persona", s.Name)
+	}
+	seen[s.Name] = true
+	return Parse(s.Cron)
+}
+
+// List returns every configured schedule with its next run after now.
+func List(missionDir string, now time.Time) []Status {
+	state := loadState(missionDir)
+	seen := map[string]bool{}
+	list := []Status{}
+	for _, s := range Load(missionDir) {
+		st := Status{Schedule: s}
+		if c, err := validate(s, seen); err != nil {
+			st.Error = err.Error()
+		} else if next := c.Next(now); !next.IsZero() {
+			st.NextRun = next.Format(time.RFC3339)
+		}
+		if last, ok := state[s.Name]; ok {
+			st.LastRun = &last
+		}
+		list = append(list, st)
+	}
+	return list
+}
+
+// Scheduler fires one project's schedules.
+type Scheduler struct {
+	projectDir string
+	onRun      func(Run)
+	done       chan struct{}
+	stopOnce   sync.Once
+
+	warned map[string]bool // invalid schedules already logged
+}
+
+// Start starts a Scheduler for projectDir. onRun, if set, is called after
+// each run, failed or not.
+func Start(projectDir string, onRun func(Run)) *Scheduler {
+	s := newScheduler(projectDir, onRun)
+	go s.run(TickInterval)
+	return s
+}
+
+func newScheduler(projectDir string, onRun func(Run)) *Scheduler {
+	return &Scheduler{
+		projectDir: projectDir,
+		onRun:      onRun,
+		done:       make(chan struct{}),
+		warned:     map[string]bool{},
+	}
+}
+
+// Stop stops the scheduler.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+func (s *Scheduler) missionDir() string {
+	return filepath.Join(s.projectDir, ".mission")
+}
+
+func (s *Scheduler) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+// tick runs the schedules that fire in now's minute and haven't run in it.
+func (s *Scheduler) tick(now time.Time) {
+	schedules := Load(s.missionDir())
+	if len(schedules) == 0 {
+		return
+	}
+	minute := now.Truncate(time.Minute)
+	state := loadState(s.missionDir())
+	seen := map[string]bool{}
+	for _, sched := range schedules {
+		c, err := validate(sched, seen)
+		if err != nil {
+			if msg := err.Error(); !s.warned[msg] {
+				s.warned[msg] = true
+				log.Printf("schedule: ignoring schedule in %s: %v", s.projectDir, err)
+			}
+			continue
+		}
+		if !c.Matches(minute) {
+			continue
+		}
+		if last, ok := state[sched.Name]; ok {
+			if t, err := time.Parse(time.RFC3339, last.At); err == nil && !t.Before(minute) {
+				continue
+			}
+		}
+
+		r := Run{Schedule: sched.Name, LastRun: s.fire(sched)}
+		r.At = minute.UTC().Format(time.RFC3339)
+		state[sched.Name] = r.LastRun
+		if err := saveState(s.missionDir(), state); err != nil {
+			log.Printf("schedule: saving %s: %v", StateFile, err)
+		}
+		if r.Error != "" {
+			log.Printf("schedule: %s failed: %s", sched.Name, r.Error)
+		} else {
+			log.Printf("schedule: %s created task %s", sched.Name, r.TaskID)
+		}
+		if s.onRun != nil {
+			s.onRun(r)
+		}
+	}
+}
+
+// fire creates sched's task and, with Spawn, its worker.
+func (s *Scheduler) fire(sched Schedule) LastRun {
+	args := []string{"task", "create", sched.Task}
+	if sched.Stage != "" {
+		// Scheduled work runs whatever stage the mission is in
+		args = append(args, "--stage", sched.Stage, "--force")
+	}
+	if sched.Zone != "" {
+		args = append(args, "--zone", sched.Zone)
+	}
+	if sched.Persona != "" {
+		args = append(args, "--persona", sched.Persona)
+	}
+	if len(sched.Labels) > 0 {
+		args = append(args, "--label", strings.Join(sched.Labels, ","))
+	}
+	var task struct {
+		ID string `json:"id"`
+	}
+	if err := s.mc(&task, args...); err != nil {
+		return LastRun{Error: "creating task: " + err.Error()}
+	}
+	run := LastRun{TaskID: task.ID}
+	if !sched.Spawn {
+		return run
+	}
+
+	args = []string{"spawn", sched.Persona, sched.Task, "--task-id", task.ID}
+	if sched.Zone != "" {
+		args = append(args, "--zone", sched.Zone)
+	}
+	var worker struct {
+		ID string `json:"id"`
+	}
+	if err := s.mc(&worker, args...); err != nil {
+		run.Error = "spawning worker: " + err.Error()
+		return run
+	}
+	run.Worker = worker.ID
+	return run
+}
+
+// mc runs mc as Actor with args in the project and decodes its JSON output
+// into v.
+func (s *Scheduler) mc(v interface{}, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "mc", args...)
+	cmd.Dir = s.projectDir
+	cmd.Env = append(os.Environ(), identity.EnvUser+"="+Actor)
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return fmt.Errorf("%s", strings.TrimSpace(string(exit.Stderr)))
+		}
+		return err
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("unexpected mc output: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func loadState(missionDir string) map[string]LastRun {
+	var f struct {
+		Schedules map[string]LastRun `json:"schedules"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "orchestrator", StateFile)); err == nil {
+		_ = json.Unmarshal(data, &f)
+	}
+	if f.Schedules == nil {
+		f.Schedules = map[string]LastRun{}
+	}
+	return f.Schedules
+}
+
+func saveState(missionDir string, state map[string]LastRun) error {
+	dir := filepath.Join(missionDir, "orchestrator")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{"schedules": state}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, StateFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Sunday 2026-03-01 10:30 UTC
+	from := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	for _, tc := range []struct{ expr, want string }{
+		{"0 2 * * *", "2026-03-02T02:00:00Z"},
+		{"*/15 * * * *", "2026-03-01T10:45:00Z"},
+		{"30 10 * * *", "2026-03-02T10:30:00Z"},
+		{"0 9 * * 1-5", "2026-03-02T09:00:00Z"},
+		{"0 0 * * 7", "2026-03-08T00:00:00Z"},
+		{"@monthly", "2026-04-01T00:00:00Z"},
+		{"0 0 15 * 3", "2026-03-04T00:00:00Z"}, // day of month or Wednesday
+		{"0 12 29 2 *", "2028-02-29T12:00:00Z"},
+	} {
+		c, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if got := c.Next(from).Format(time.RFC3339); got != tc.want {
+			t.Errorf("%s: next = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+
+	c, _ := Parse("0 0 30 2 *")
+	if next := c.Next(from); !next.IsZero() {
+		t.Errorf("expected no run on February 30th, got %v", next)
+	}
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+// fakeMC puts an mc on PATH that logs its arguments and MC_USER to the
+// returned file and prints a task or worker.
+func fakeMC(t *testing.T, dir string) string {
+	t.Helper()
+	bin := t.TempDir()
+	argsFile := filepath.Join(dir, "mc-args")
+	script := `#!/bin/sh
+echo "$MC_USER: $*" >> ` + argsFile + `
+case "$1" in
+task) echo '{"id": "task-1"}' ;;
+spawn) echo '{"id": "worker-1"}' ;;
+esac
+`
+	writeFile(t, filepath.Join(bin, "mc"), script)
+	os.Chmod(filepath.Join(bin, "mc"), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func TestTickFiresDueSchedulesOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mission", "config.json"), `{"schedules":[
+		{"name":"nightly-deps","cron":"0 2 * * *","task":"Audit dependencies","zone":"backend","persona":"security","labels":["security","deps"],"spawn":true},
+		{"name":"half-past","cron":"30 * * * *","task":"Tidy"},
+		{"name":"broken","cron":"0 2 * *","task":"Never"}]}`)
+	argsFile := fakeMC(t, dir)
+
+	var runs []Run
+	s := newScheduler(dir, func(r Run) { runs = append(runs, r) })
+	now := time.Date(2026, 3, 1, 2, 0, 20, 0, time.Local)
+	s.tick(now)
+
+	if len(runs) != 1 || runs[0].Schedule != "nightly-deps" || runs[0].TaskID != "task-1" || runs[0].Worker != "worker-1" || runs[0].Error != "" {
+		t.Fatalf("expected nightly-deps to run, got %+v", runs)
+	}
+	data, _ := os.ReadFile(argsFile)
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{
+		"scheduler: task create Audit dependencies --zone backend --persona security --label security,deps",
+		"scheduler: spawn security Audit dependencies --task-id task-1 --zone backend",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("mc calls = %q, want %q", calls, want)
+	}
+
+	// Again in the same minute, e.g. after a restart
+	s = newScheduler(dir, func(r Run) { runs = append(runs, r) })
+	s.tick(now.Add(30 * time.Second))
+	if len(runs) != 1 {
+		t.Errorf("expected no second run in the same minute, got %+v", runs)
+	}
+
+	list := List(filepath.Join(dir, ".mission"), now)
+	if len(list) != 3 {
+		t.Fatalf("expected 3 schedules, got %+v", list)
+	}
+	if list[0].LastRun == nil || list[0].LastRun.TaskID != "task-1" || list[0].NextRun != time.Date(2026, 3, 2, 2, 0, 0, 0, time.Local).Format(time.RFC3339) {
+		t.Errorf("unexpected nightly-deps status: %+v", list[0])
+	}
+	if list[2].Error == "" || list[2].NextRun != "" {
+		t.Errorf("expected the broken schedule to report its error, got %+v", list[2])
+	}
+}
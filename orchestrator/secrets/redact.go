@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mask replaces a secret value in redacted text.
+const Mask = "[REDACTED]"
+
+// minRedactLen skips values too short to mask without mangling ordinary
+// text.
+const minRedactLen = 4
+
+// Redactor masks a fixed set of secret values.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// NewRedactor masks values, longest first so a value containing another
+// is masked whole.
+func NewRedactor(values []string) *Redactor {
+	vs := make([]string, 0, len(values))
+	for _, v := range values {
+		if len(v) >= minRedactLen {
+			vs = append(vs, v)
+		}
+	}
+	sort.Slice(vs, func(i, j int) bool { return len(vs[i]) > len(vs[j]) })
+	pairs := make([]string, 0, 2*len(vs))
+	for _, v := range vs {
+		pairs = append(pairs, v, Mask)
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Redact masks every secret value in text.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	return r.replacer.Replace(text)
+}
+
+var (
+	redactMu      sync.Mutex
+	redactor      *Redactor
+	redactModTime time.Time
+)
+
+// current is the redactor for the default store, rebuilt whenever the
+// store file changes so a long-running server picks up new secrets.
+func current() *Redactor {
+	info, err := os.Stat(filepath.Join(DefaultDir(), FileName))
+	if err != nil {
+		return nil
+	}
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	if redactor != nil && info.ModTime().Equal(redactModTime) {
+		return redactor
+	}
+	s := Default()
+	if s == nil {
+		return nil
+	}
+	all, err := s.All()
+	if err != nil {
+		return nil
+	}
+	values := make([]string, 0, len(all))
+	for _, v := range all {
+		values = append(values, v)
+	}
+	redactor, redactModTime = NewRedactor(values), info.ModTime()
+	return redactor
+}
+
+// Redact masks the default store's secret values in text.
+func Redact(text string) string {
+	return current().Redact(text)
+}
+
+// RedactMap masks secret values in the strings of a details map, such as
+// an audit entry's, recursing into nested maps and slices. The map is
+// copied, not changed.
+func RedactMap(m map[string]interface{}) map[string]interface{} {
+	r := current()
+	if r == nil || m == nil {
+		return m
+	}
+	return redactValue(r, m).(map[string]interface{})
+}
+
+func redactValue(r *Redactor, v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.Redact(v)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = r.Redact(s)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = redactValue(r, e)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = redactValue(r, e)
+		}
+		return out
+	}
+	return v
+}
+
+// redactWriter masks secrets in each write; the log package writes a
+// whole line per call.
+type redactWriter struct {
+	w io.Writer
+}
+
+// Writer wraps w so everything written through it is redacted, for
+// log.SetOutput.
+func Writer(w io.Writer) io.Writer {
+	return redactWriter{w: w}
+}
+
+func (rw redactWriter) Write(p []byte) (int, error) {
+	r := current()
+	if r == nil {
+		return rw.w.Write(p)
+	}
+	if _, err := io.WriteString(rw.w, r.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
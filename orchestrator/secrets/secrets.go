@@ -0,0 +1,376 @@
+// Package secrets keeps provider keys and webhook secrets out of plaintext
+// config. Values are sealed with AES-256-GCM in
+// ~/.mission-control/secrets.json; the key comes from MC_SECRETS_KEY
+// (base64, 32 bytes), else the macOS keychain, else a 0600 key file
+// beside the store:
+//
+//	mc secret set GITHUB_TOKEN          # value read from stdin
+//	"webhooks": [{"url": "...", "secret": "secret:SLACK_SIGNING"}]
+//
+// Every secret is injected into the environment of spawned workers under
+// its name, config values of the form "secret:NAME" resolve through the
+// store, and Redact masks secret values in logs, exports and audit
+// entries.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// File names in the store directory
+const (
+	FileName    = "secrets.json"
+	KeyFileName = "secrets.key"
+)
+
+// EnvKey overrides where the store key comes from.
+const EnvKey = "MC_SECRETS_KEY"
+
+// RefPrefix marks a config value that names a secret: "secret:NAME".
+const RefPrefix = "secret:"
+
+// keychainService and keychainAccount locate the key in the macOS keychain.
+const (
+	keychainService = "mission-control"
+	keychainAccount = "secrets-key"
+)
+
+// useKeychain keeps the key in the macOS keychain rather than a key file.
+var useKeychain = runtime.GOOS == "darwin"
+
+// ErrNotFound is returned for a secret that isn't in the store.
+var ErrNotFound = errors.New("secret not found")
+
+// namePattern keeps names usable as environment variables.
+var namePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// file is the on-disk form: each value sealed separately so names can be
+// listed without the key.
+type file struct {
+	Version int               `json:"version"`
+	Secrets map[string]string `json:"secrets"`
+}
+
+// Store is an encrypted name/value store.
+type Store struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// DefaultDir is ~/.mission-control.
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".mission-control")
+}
+
+// Open opens the store in dir, creating its key on first use.
+func Open(dir string) (*Store, error) {
+	key, err := loadKey(dir)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, aead: aead}, nil
+}
+
+// ValidName reports whether name can be stored and injected as an
+// environment variable.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.dir, FileName)
+}
+
+func (s *Store) load() (*file, error) {
+	f := &file{Version: 1, Secrets: map[string]string{}}
+	data, err := os.ReadFile(s.path())
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path(), err)
+	}
+	if f.Secrets == nil {
+		f.Secrets = map[string]string{}
+	}
+	return f, nil
+}
+
+func (s *Store) save(f *file) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path() + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path())
+}
+
+// Set stores value under name, replacing any previous value.
+func (s *Store) Set(name, value string) error {
+	if !ValidName(name) {
+		return fmt.Errorf("invalid secret name %q (use A-Z, 0-9 and _)", name)
+	}
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// The name is authenticated so a value can't be moved to another name
+	sealed := s.aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	f.Secrets[name] = base64.StdEncoding.EncodeToString(sealed)
+	return s.save(f)
+}
+
+// Get returns the value stored under name.
+func (s *Store) Get(name string) (string, error) {
+	f, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	enc, ok := f.Secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return s.open(name, enc)
+}
+
+func (s *Store) open(name, enc string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", fmt.Errorf("secret %s is corrupt", name)
+	}
+	n := s.aead.NonceSize()
+	plain, err := s.aead.Open(nil, sealed[:n], sealed[n:], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("secret %s can't be decrypted with this key", name)
+	}
+	return string(plain), nil
+}
+
+// Delete removes name. Deleting a missing secret is ErrNotFound.
+func (s *Store) Delete(name string) error {
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(f.Secrets, name)
+	return s.save(f)
+}
+
+// Names lists the stored names, sorted.
+func (s *Store) Names() ([]string, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(f.Secrets))
+	for name := range f.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// All decrypts every secret.
+func (s *Store) All() (map[string]string, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(f.Secrets))
+	for name, enc := range f.Secrets {
+		v, err := s.open(name, enc)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// Environ returns the secrets as sorted NAME=value pairs for a worker's
+// environment.
+func (s *Store) Environ() ([]string, error) {
+	all, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+	env := make([]string, 0, len(all))
+	for name, v := range all {
+		env = append(env, name+"="+v)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// Resolve returns the secret a "secret:NAME" value refers to; any other
+// value is returned as is.
+func (s *Store) Resolve(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, RefPrefix)
+	if !ok {
+		return value, nil
+	}
+	return s.Get(name)
+}
+
+// loadKey finds the store key: MC_SECRETS_KEY, the macOS keychain, or the
+// key file in dir, generating one when there is none yet.
+func loadKey(dir string) ([]byte, error) {
+	if v := os.Getenv(EnvKey); v != "" {
+		return decodeKey(v, EnvKey)
+	}
+	if useKeychain {
+		if _, err := exec.LookPath("security"); err == nil {
+			return keychainKey()
+		}
+	}
+	return fileKey(filepath.Join(dir, KeyFileName))
+}
+
+func decodeKey(v, source string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s must hold a base64 32-byte key", source)
+	}
+	return key, nil
+}
+
+func newKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func keychainKey() ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w").Output()
+	if err == nil {
+		return decodeKey(string(out), "keychain item "+keychainService)
+	}
+	enc, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := keychainAdd(enc).Run(); err != nil {
+		return nil, fmt.Errorf("failed to store key in keychain: %w", err)
+	}
+	return decodeKey(enc, "keychain item "+keychainService)
+}
+
+// keychainAdd stores enc in the keychain. The command goes to `security
+// -i` on stdin, so the key never appears in the process list.
+func keychainAdd(enc string) *exec.Cmd {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %q -a %q -w %q\n", keychainService, keychainAccount, enc))
+	return cmd
+}
+
+func fileKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return decodeKey(string(data), path)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	enc, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(enc+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	return decodeKey(enc, path)
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultStore *Store
+)
+
+// Default opens the store in DefaultDir, or returns nil when no secrets
+// have been stored there, so callers without secrets never create a key.
+// The store is reopened if DefaultDir changes.
+func Default() *Store {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	dir := DefaultDir()
+	if defaultStore != nil && defaultStore.dir == dir {
+		return defaultStore
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+		return nil
+	}
+	s, err := Open(dir)
+	if err != nil {
+		return nil
+	}
+	defaultStore = s
+	return s
+}
+
+// Environ is the default store's worker environment; nil without a
+// store or when it can't be read.
+func Environ() []string {
+	s := Default()
+	if s == nil {
+		return nil
+	}
+	env, _ := s.Environ()
+	return env
+}
+
+// Resolve resolves a "secret:NAME" config value through the default
+// store. Plain values are returned as is.
+func Resolve(value string) (string, error) {
+	if !strings.HasPrefix(value, RefPrefix) {
+		return value, nil
+	}
+	s := Default()
+	if s == nil {
+		return "", fmt.Errorf("%s: %w (no secrets stored)", value, ErrNotFound)
+	}
+	return s.Resolve(value)
+}
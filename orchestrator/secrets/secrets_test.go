@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useHome points DefaultDir at a temp home and forgets cached state.
+func useHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvKey, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	reset := func() {
+		defaultMu.Lock()
+		defaultStore = nil
+		defaultMu.Unlock()
+		redactMu.Lock()
+		redactor = nil
+		redactMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+	return filepath.Join(home, ".mission-control")
+}
+
+func TestMain(m *testing.M) {
+	useKeychain = false
+	os.Exit(m.Run())
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("GITHUB_TOKEN", "ghp_supersecret"); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("SLACK_WEBHOOK", "https://hooks.slack.com/x")
+
+	if v, err := s.Get("GITHUB_TOKEN"); err != nil || v != "ghp_supersecret" {
+		t.Errorf("Get = %q, %v", v, err)
+	}
+	if _, err := s.Get("MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing secret: err %v, want ErrNotFound", err)
+	}
+	if names, _ := s.Names(); strings.Join(names, ",") != "GITHUB_TOKEN,SLACK_WEBHOOK" {
+		t.Errorf("names = %v", names)
+	}
+	if env, _ := s.Environ(); strings.Join(env, " ") != "GITHUB_TOKEN=ghp_supersecret SLACK_WEBHOOK=https://hooks.slack.com/x" {
+		t.Errorf("environ = %v", env)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, FileName))
+	if strings.Contains(string(data), "supersecret") {
+		t.Errorf("store holds plaintext:\n%s", data)
+	}
+	for _, name := range []string{FileName, KeyFileName} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, %v; want 0600", name, info.Mode().Perm(), err)
+		}
+	}
+
+	// The key file is reused, so a new Store reads the same secrets
+	again, _ := Open(dir)
+	if v, _ := again.Get("GITHUB_TOKEN"); v != "ghp_supersecret" {
+		t.Errorf("reopened Get = %q", v)
+	}
+
+	if err := s.Delete("GITHUB_TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("GITHUB_TOKEN"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second delete: err %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreRejects(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open(dir)
+	for _, name := range []string{"", "lower", "1ST", "WITH-DASH"} {
+		if err := s.Set(name, "value"); err == nil {
+			t.Errorf("Set(%q) succeeded, want an invalid name error", name)
+		}
+	}
+
+	s.Set("API_KEY", "value")
+	t.Setenv(EnvKey, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	other, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Get("API_KEY"); err == nil {
+		t.Errorf("a different key decrypted the secret")
+	}
+
+	t.Setenv(EnvKey, "short")
+	if _, err := Open(dir); err == nil {
+		t.Errorf("a malformed %s was accepted", EnvKey)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := useHome(t)
+	if v, err := Resolve("plain"); err != nil || v != "plain" {
+		t.Errorf("plain value = %q, %v", v, err)
+	}
+	if _, err := Resolve("secret:SIGNING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("no store: err %v, want ErrNotFound", err)
+	}
+
+	s, _ := Open(dir)
+	s.Set("SIGNING", "hmac-key")
+	if v, err := Resolve("secret:SIGNING"); err != nil || v != "hmac-key" {
+		t.Errorf("Resolve = %q, %v", v, err)
+	}
+	if env := Environ(); len(env) != 1 || env[0] != "SIGNING=hmac-key" {
+		t.Errorf("Environ = %v", env)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor([]string{"abc", "token-123", "token-123-extended"})
+	got := r.Redact("use token-123-extended or token-123, not abc")
+	if want := "use [REDACTED] or [REDACTED], not abc"; got != want {
+		t.Errorf("Redact = %q, want %q", got, want)
+	}
+	var nilRedactor *Redactor
+	if nilRedactor.Redact("x") != "x" {
+		t.Errorf("nil redactor changed text")
+	}
+}
+
+func TestDefaultRedaction(t *testing.T) {
+	dir := useHome(t)
+	if Redact("nothing stored") != "nothing stored" {
+		t.Errorf("redacted without a store")
+	}
+
+	s, _ := Open(dir)
+	s.Set("ANTHROPIC_API_KEY", "sk-ant-12345")
+
+	if got := Redact("key=sk-ant-12345"); got != "key="+Mask {
+		t.Errorf("Redact = %q", got)
+	}
+	details := RedactMap(map[string]interface{}{
+		"cmd":  "curl -H sk-ant-12345",
+		"args": []interface{}{"sk-ant-12345", 3},
+		"n":    1,
+	})
+	if details["cmd"] != "curl -H "+Mask || details["args"].([]interface{})[0] != Mask || details["n"] != 1 {
+		t.Errorf("RedactMap = %v", details)
+	}
+
+	var buf bytes.Buffer
+	logger := log.New(Writer(&buf), "", 0)
+	logger.Printf("spawning with %s", "sk-ant-12345")
+	if strings.Contains(buf.String(), "sk-ant") {
+		t.Errorf("log line not redacted: %q", buf.String())
+	}
+}
+
+func TestKeychainAddKeepsKeyOffArgv(t *testing.T) {
+	cmd := keychainAdd("a2V5LWJ5dGVz")
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, "a2V5LWJ5dGVz") {
+			t.Fatalf("key on the command line: %v", cmd.Args)
+		}
+	}
+	in, _ := io.ReadAll(cmd.Stdin)
+	if want := `add-generic-password -s "mission-control" -a "secrets-key" -w "a2V5LWJ5dGVz"` + "\n"; string(in) != want {
+		t.Errorf("stdin = %q, want %q", in, want)
+	}
+}
@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+// Cache lifetimes for dashboard files. Vite fingerprints everything under
+// assets/, so those never change; index.html and other unhashed files are
+// revalidated on every load so a new build shows up at once.
+const (
+	dashboardImmutable   = "public, max-age=31536000, immutable"
+	dashboardRevalidated = "no-cache"
+)
+
+// dashboardHandler serves the web dashboard build in assets: files by path,
+// and index.html for any other page path so client-side routes survive a
+// reload. Paths that look like files (with an extension) but don't exist
+// get 404 rather than the page, so a missing script fails loudly.
+func dashboardHandler(assets fs.FS) http.Handler {
+	// The embedded files are fixed for the life of the binary, so their
+	// ETags are hashed once up front.
+	etags := map[string]string{}
+	fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if data, err := fs.ReadFile(assets, name); err == nil {
+			sum := sha256.Sum256(data)
+			etags[name] = `"` + hex.EncodeToString(sum[:8]) + `"`
+		}
+		return nil
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			problem.MethodNotAllowed(w)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		data, err := fs.ReadFile(assets, name)
+		if name == "" || err != nil {
+			if name != "" && path.Ext(name) != "" {
+				http.NotFound(w, r)
+				return
+			}
+			name = "index.html"
+			if data, err = fs.ReadFile(assets, name); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		if strings.HasPrefix(name, "assets/") {
+			w.Header().Set("Cache-Control", dashboardImmutable)
+		} else {
+			w.Header().Set("Cache-Control", dashboardRevalidated)
+		}
+		if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+			w.Header().Set("Content-Type", ctype)
+		}
+		if etag, ok := etags[name]; ok {
+			w.Header().Set("ETag", etag)
+		}
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+	})
+}
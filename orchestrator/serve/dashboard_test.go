@@ -0,0 +1,65 @@
+package serve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
+
+func TestDashboardHandler(t *testing.T) {
+	h := dashboardHandler(fstest.MapFS{
+		"index.html":         {Data: []byte("<!doctype html><div id=root></div>")},
+		"assets/index-a1.js": {Data: []byte("console.log(1)")},
+		"favicon.svg":        {Data: []byte("<svg/>")},
+	})
+
+	tests := []struct {
+		path, body, cache string
+		status            int
+	}{
+		{"/", "id=root", dashboardRevalidated, http.StatusOK},
+		{"/tasks/abc", "id=root", dashboardRevalidated, http.StatusOK}, // client-side route
+		{"/assets/index-a1.js", "console.log", dashboardImmutable, http.StatusOK},
+		{"/favicon.svg", "<svg/>", dashboardRevalidated, http.StatusOK},
+		{"/assets/missing.js", "", "", http.StatusNotFound},
+		{"/../../etc/passwd", "id=root", dashboardRevalidated, http.StatusOK}, // confined to the build
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("GET %s: expected %d, got %d", tt.path, tt.status, w.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("GET %s: body %q missing %q", tt.path, w.Body.String(), tt.body)
+		}
+		if got := w.Header().Get("Cache-Control"); got != tt.cache {
+			t.Errorf("GET %s: Cache-Control %q, want %q", tt.path, got, tt.cache)
+		}
+	}
+
+	// Revalidation by ETag
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Content-Type") != problem.ContentType {
+		t.Errorf("expected a 405 problem for POST, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,219 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/watcher"
+)
+
+// RejectedDir, under handoffs/, holds dropped handoffs mc refused, each
+// next to a <name>.error.txt report of why.
+const RejectedDir = "rejected"
+
+// inboxActor is who ingested handoffs are recorded as acting for.
+const inboxActor = "handoff-inbox"
+
+// inboxSettle is how often dropped handoffs are checked. A file is
+// ingested once its size and modification time held across a check, so
+// one a worker is still writing isn't read half-written.
+const inboxSettle = 500 * time.Millisecond
+
+// ingestResult is the outcome of ingesting one dropped handoff.
+type ingestResult struct {
+	File     string `json:"file"`
+	Ingested bool   `json:"ingested"`
+	Rejected string `json:"rejected,omitempty"` // where the file was moved
+	Error    string `json:"error,omitempty"`
+}
+
+// ingestDroppedHandoff stores a handoff a worker dropped in
+// handoffs/incoming/ by running mc handoff on it, as the worker should
+// have. An ingested file is removed, mc having stored its own copy; one
+// mc refuses is moved to handoffs/rejected/ with an error report. A file
+// already gone (ingested after a restart) is skipped, and when mc can't be
+// run at all the file is left for the next start.
+func ingestDroppedHandoff(missionDir, path string) (*ingestResult, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	name := filepath.Base(path)
+	cmd := exec.Command("mc", "handoff", path)
+	cmd.Dir = filepath.Dir(missionDir)
+	cmd.Env = append(os.Environ(), identity.EnvUser+"="+inboxActor)
+	out, err := cmd.CombinedOutput()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		return nil, fmt.Errorf("running mc handoff: %w", err)
+	}
+	if err == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return &ingestResult{File: name, Ingested: true}, nil
+	}
+
+	reason := strings.TrimSpace(string(out))
+	rejected, err := rejectHandoff(missionDir, path, reason)
+	if err != nil {
+		return nil, err
+	}
+	return &ingestResult{File: name, Rejected: rejected, Error: reason}, nil
+}
+
+// rejectHandoff moves path to handoffs/rejected/, next to a report of
+// reason, and returns where it went. A name already taken there gets a
+// timestamp.
+func rejectHandoff(missionDir, path, reason string) (string, error) {
+	dir := filepath.Join(missionDir, "handoffs", RejectedDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := filepath.Base(path)
+	dest := filepath.Join(dir, name)
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + "-" + time.Now().UTC().Format("20060102-150405") + ext
+		dest = filepath.Join(dir, name)
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", err
+	}
+	report := fmt.Sprintf("Rejected %s at %s\n\n%s\n\nFix the handoff and move it back to handoffs/%s/, or run mc handoff on it.\n",
+		name, time.Now().UTC().Format(time.RFC3339), reason, watcher.HandoffInbox)
+	if err := os.WriteFile(dest+".error.txt", []byte(report), 0644); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// handleHandoffDropped ingests the file a handoff_dropped event names and
+// returns the event to broadcast: handoff_ingested or handoff_rejected.
+func handleHandoffDropped(missionDir string, data interface{}) (string, *ingestResult) {
+	m, _ := data.(map[string]interface{})
+	path, _ := m["path"].(string)
+	if path == "" {
+		return "", nil
+	}
+	res, err := ingestDroppedHandoff(missionDir, path)
+	if err != nil {
+		log.Printf("handoff inbox: %s: %v", filepath.Base(path), err)
+		return "", nil
+	}
+	if res == nil {
+		return "", nil
+	}
+	if res.Ingested {
+		log.Printf("handoff inbox: ingested %s", res.File)
+		return "handoff_ingested", res
+	}
+	log.Printf("handoff inbox: rejected %s: %s", res.File, res.Error)
+	return "handoff_rejected", res
+}
+
+// handoffInbox ingests dropped handoffs off the watcher's event loop, each
+// once it stopped changing. Writing <name>.json.tmp and renaming it to
+// <name>.json avoids the wait for a partial file: the watcher ignores
+// files without a .json extension.
+type handoffInbox struct {
+	missionDir string
+	report     func(typ string, res *ingestResult)
+
+	mu      sync.Mutex
+	pending map[string]fileStamp // path → size and mtime at the last check
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// fileStamp is what a check saw of a dropped file; the zero stamp is a
+// file not checked yet.
+type fileStamp struct {
+	size int64
+	mod  time.Time
+}
+
+// startHandoffInbox starts ingesting the handoffs passed to Drop, calling
+// report with the event to broadcast for each.
+func startHandoffInbox(missionDir string, report func(typ string, res *ingestResult)) *handoffInbox {
+	in := &handoffInbox{
+		missionDir: missionDir,
+		report:     report,
+		pending:    map[string]fileStamp{},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go in.run()
+	return in
+}
+
+// Drop queues the file a handoff_dropped event names.
+func (in *handoffInbox) Drop(data interface{}) {
+	m, _ := data.(map[string]interface{})
+	path, _ := m["path"].(string)
+	if path == "" {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if _, ok := in.pending[path]; !ok {
+		in.pending[path] = fileStamp{}
+	}
+}
+
+// Stop ends ingestion, waiting for a handoff being ingested. Files still
+// pending are ingested on the next start, which emits them again.
+func (in *handoffInbox) Stop() {
+	close(in.stop)
+	<-in.done
+}
+
+func (in *handoffInbox) run() {
+	defer close(in.done)
+	ticker := time.NewTicker(inboxSettle)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, path := range in.settled() {
+				if typ, res := handleHandoffDropped(in.missionDir, map[string]interface{}{"path": path}); res != nil {
+					in.report(typ, res)
+				}
+			}
+		case <-in.stop:
+			return
+		}
+	}
+}
+
+// settled returns the pending files unchanged since the last check, in
+// name order, and forgets them and the files that are gone.
+func (in *handoffInbox) settled() []string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var ready []string
+	for path, last := range in.pending {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(in.pending, path)
+			continue
+		}
+		now := fileStamp{size: info.Size(), mod: info.ModTime()}
+		if last.mod.IsZero() || now.size != last.size || !now.mod.Equal(last.mod) {
+			in.pending[path] = now
+			continue
+		}
+		delete(in.pending, path)
+		ready = append(ready, path)
+	}
+	sort.Strings(ready)
+	return ready
+}
@@ -0,0 +1,111 @@
+package serve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMC puts an mc on PATH that accepts handoffs containing "complete"
+// and refuses the rest.
+func fakeMC(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\nif grep -q complete \"$2\"; then echo stored; else echo 'validation failed:' >&2; echo '  - line 1, col 1: status: is required' >&2; exit 1; fi\n"
+	if err := os.WriteFile(filepath.Join(bin, "mc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestIngestDroppedHandoff(t *testing.T) {
+	fakeMC(t)
+	missionDir := filepath.Join(t.TempDir(), ".mission")
+	inbox := filepath.Join(missionDir, "handoffs", "incoming")
+	os.MkdirAll(inbox, 0755)
+
+	good := filepath.Join(inbox, "good.json")
+	os.WriteFile(good, []byte(`{"status":"complete"}`), 0644)
+	typ, res := handleHandoffDropped(missionDir, map[string]interface{}{"path": good})
+	if typ != "handoff_ingested" || res == nil || !res.Ingested {
+		t.Fatalf("expected good.json ingested, got %s %+v", typ, res)
+	}
+	if _, err := os.Stat(good); !os.IsNotExist(err) {
+		t.Error("expected the ingested file removed from the inbox")
+	}
+
+	// Refused twice under the same name: both kept, each with a report
+	for i := 0; i < 2; i++ {
+		bad := filepath.Join(inbox, "bad.json")
+		os.WriteFile(bad, []byte(`{}`), 0644)
+		typ, res = handleHandoffDropped(missionDir, map[string]interface{}{"path": bad})
+		if typ != "handoff_rejected" || res == nil || !strings.Contains(res.Error, "status: is required") {
+			t.Fatalf("expected bad.json rejected, got %s %+v", typ, res)
+		}
+		if _, err := os.Stat(bad); !os.IsNotExist(err) {
+			t.Error("expected the rejected file moved out of the inbox")
+		}
+		report, err := os.ReadFile(res.Rejected + ".error.txt")
+		if err != nil || !strings.Contains(string(report), "line 1, col 1: status: is required") {
+			t.Errorf("expected an error report, got %q (%v)", report, err)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Join(missionDir, "handoffs", RejectedDir))
+	if len(entries) != 4 {
+		t.Errorf("expected two rejected files and two reports, got %d entries", len(entries))
+	}
+
+	// Already handled (say, before a restart): nothing to do
+	if typ, res := handleHandoffDropped(missionDir, map[string]interface{}{"path": good}); typ != "" || res != nil {
+		t.Errorf("expected a missing file skipped, got %s %+v", typ, res)
+	}
+}
+
+func TestHandoffInboxWaitsForWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "h.json")
+	os.WriteFile(path, []byte(`{"status":`), 0644)
+
+	in := &handoffInbox{pending: map[string]fileStamp{}}
+	in.Drop(map[string]interface{}{"path": path})
+	in.Drop(map[string]interface{}{"path": filepath.Join(dir, "gone.json")})
+	if ready := in.settled(); len(ready) != 0 {
+		t.Fatalf("ingested %v on first sight", ready)
+	}
+	os.WriteFile(path, []byte(`{"status":"complete"}`), 0644)
+	if ready := in.settled(); len(ready) != 0 {
+		t.Fatalf("ingested %v while it was being written", ready)
+	}
+	if ready := in.settled(); len(ready) != 1 || ready[0] != path {
+		t.Fatalf("settled = %v, want %s", ready, path)
+	}
+	if len(in.pending) != 0 {
+		t.Errorf("still pending: %v", in.pending)
+	}
+}
+
+func TestHandoffInboxIngests(t *testing.T) {
+	fakeMC(t)
+	missionDir := filepath.Join(t.TempDir(), ".mission")
+	inbox := filepath.Join(missionDir, "handoffs", "incoming")
+	os.MkdirAll(inbox, 0755)
+	good := filepath.Join(inbox, "good.json")
+	os.WriteFile(good, []byte(`{"status":"complete"}`), 0644)
+
+	reports := make(chan string, 1)
+	in := startHandoffInbox(missionDir, func(typ string, res *ingestResult) {
+		reports <- typ + " " + res.File
+	})
+	defer in.Stop()
+	in.Drop(map[string]interface{}{"path": good})
+	select {
+	case got := <-reports:
+		if got != "handoff_ingested good.json" {
+			t.Errorf("report = %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dropped handoff never ingested")
+	}
+}
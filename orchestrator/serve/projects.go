@@ -0,0 +1,407 @@
+package serve
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/alerts"
+	"github.com/MikeSquared-Agency/MissionControl/api"
+	"github.com/MikeSquared-Agency/MissionControl/autocheckpoint"
+	"github.com/MikeSquared-Agency/MissionControl/autopilot"
+	"github.com/MikeSquared-Agency/MissionControl/autorestart"
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/budget"
+	"github.com/MikeSquared-Agency/MissionControl/commands"
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
+	"github.com/MikeSquared-Agency/MissionControl/gatetimer"
+	"github.com/MikeSquared-Agency/MissionControl/origins"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/recording"
+	"github.com/MikeSquared-Agency/MissionControl/schedule"
+	"github.com/MikeSquared-Agency/MissionControl/spend"
+	"github.com/MikeSquared-Agency/MissionControl/stagesla"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+	"github.com/MikeSquared-Agency/MissionControl/tracker"
+	"github.com/MikeSquared-Agency/MissionControl/watcher"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+	"github.com/MikeSquared-Agency/MissionControl/ws"
+)
+
+// defaultProjectID addresses the project the orchestrator was started in.
+const defaultProjectID = "default"
+
+// eventLogFile is the watcher's event log under .mission/orchestrator.
+const eventLogFile = "events.jsonl"
+
+// tasksCompactInterval is how often a watched project's tasks.jsonl is
+// compacted.
+const tasksCompactInterval = time.Hour
+
+// project is one mission directory's runtime: its own hub, watcher,
+// tracker, token accumulator and API server.
+type project struct {
+	dir    string
+	hub    *ws.Hub
+	trk    *tracker.Tracker
+	acc    *tokens.Accumulator
+	api    *api.Server
+	routes http.Handler
+	alerts *alerts.Store
+	stops  []func()
+
+	restarter *autorestart.Restarter // nil with --api-only
+}
+
+// startProject builds and starts the runtime for dir. With watch false the
+// file watcher and tracker are not started (--api-only).
+func startProject(dir string, watch bool, policy *origins.Policy, staleness tracker.Staleness) *project {
+	p := &project{dir: dir, hub: ws.NewHub()}
+	p.hub.SetOriginPolicy(policy)
+	go p.hub.Run()
+
+	hub := p.hub
+
+	// Notification center: alerts outlive the WebSocket events that raise
+	// them, and their unread counts are pushed on the "alerts" topic
+	p.alerts = openAlerts(dir)
+	p.alerts.OnAdd = func(a alerts.Alert, c alerts.Counts) {
+		hub.BroadcastRaw("alerts", "alert_created", map[string]interface{}{
+			"alert":   a,
+			"unread":  c.Unread,
+			"by_kind": c.ByKind,
+		})
+	}
+	p.alerts.OnChange = func(c alerts.Counts) {
+		hub.BroadcastRaw("alerts", "alert_badge", c)
+	}
+
+	missionDir := filepath.Join(dir, ".mission")
+	p.acc = tokens.NewAccumulator(budget.Load(missionDir).Tokens, func(workerID string, limit, used, remaining int) {
+		data := map[string]interface{}{
+			"worker_id": workerID,
+			"budget":    limit,
+			"used":      used,
+			"remaining": remaining,
+		}
+		hub.BroadcastRaw("token", "budget_warning", data)
+		p.alerts.Budget(workerID, limit, used, remaining)
+		event := webhook.EventBudgetWarning
+		if remaining <= 0 {
+			event = webhook.EventBudgetCritical
+		}
+		go budget.Notify(dir, event, data)
+	})
+	// Usage counts against the mission budget and the global spend
+	// limits, across projects
+	p.acc.SetOnRecord(func(n int, cost float64) {
+		now := time.Now()
+		if err := budget.Record(missionDir, n, cost, now); err != nil {
+			log.Printf("Warning: recording usage: %v", err)
+		}
+		st, tripped, err := spend.Record(spend.DefaultDir(), n, cost, now)
+		if err != nil {
+			log.Printf("Warning: recording spend: %v", err)
+		}
+		if tripped {
+			log.Printf("Spend limit reached (%s): new workers and King messages are paused", strings.Join(st.Exceeded, ", "))
+			hub.BroadcastRaw("token", "spend_limit_exceeded", st)
+			p.alerts.SpendLimit(st.Exceeded, st.Today.CostUSD, st.Today.Tokens)
+		}
+	})
+
+	p.trk = tracker.NewTracker(dir, func(eventType string, proc *tracker.TrackedProcess) {
+		hub.BroadcastRaw("worker", eventType, proc)
+		switch eventType {
+		case "error":
+			p.alerts.WorkerFailed(proc.WorkerID, proc.TaskID, "process exited")
+			go dispatchQueuedWorkers(dir)
+		case "worker_reaped":
+			p.alerts.WorkerFailed(proc.WorkerID, proc.TaskID, "no heartbeat")
+			go dispatchQueuedWorkers(dir)
+		}
+	})
+	p.trk.SetStaleness(staleness)
+	if cfg, err := bridge.LoadProjectConfig(dir); err == nil && cfg.Recordings {
+		p.trk.Logs().SetRecorder(recording.NewRecorder(filepath.Join(dir, ".mission", "recordings")))
+	}
+
+	// State provider for initial sync
+	hub.SetStateProvider(func() interface{} {
+		state := buildState(dir, p.trk, p.acc)
+		state["alerts"] = p.alerts.Counts()
+		return state
+	})
+
+	// The task graph, kept up to date from the watcher
+	graph := api.NewGraphCache()
+
+	// File watcher → hub bridge, through the persistent event log
+	var events *eventlog.Log
+	if watch {
+		w := watcher.NewWatcher(filepath.Join(dir, ".mission"))
+		if l, err := eventlog.Open(filepath.Join(dir, ".mission", "orchestrator", eventLogFile), eventlog.DefaultCapacity); err != nil {
+			log.Printf("Warning: event log unavailable for %s, events are kept in memory: %v", dir, err)
+		} else {
+			w.SetEventLog(l)
+			p.stops = append(p.stops, func() { l.Close() })
+		}
+		events = w.EventLog()
+
+		// Auto-mode gate approval, re-evaluated on every watcher event
+		auto := autopilot.Start(dir, func(d autopilot.Decision) {
+			hub.BroadcastRaw("gate", "gate_auto_approved", map[string]string{
+				"stage":       d.Stage,
+				"approved_by": autopilot.Approver,
+				"reason":      d.Reason,
+			})
+		})
+		p.stops = append(p.stops, auto.Stop)
+
+		if err := w.Start(); err != nil {
+			log.Printf("Warning: file watcher failed to start for %s: %v", dir, err)
+		} else {
+			go bridgeWatcherToHub(w, hub, p.alerts, func() {
+				auto.Trigger()
+				graph.Refresh(dir)
+			})
+			p.stops = append(p.stops, w.Stop)
+		}
+
+		p.trk.Start()
+		p.stops = append(p.stops, p.trk.Stop)
+
+		// Scheduled checkpoints from the auto_checkpoint policy
+		checkpoints := autocheckpoint.Start(dir, p.acc, func(cp autocheckpoint.Checkpoint) {
+			hub.BroadcastRaw("checkpoint", "checkpoint_auto_created", cp)
+		})
+		p.stops = append(p.stops, checkpoints.Stop)
+
+		// King session restarts from the auto_restart policy
+		p.restarter = autorestart.Start(dir, func(r autorestart.Restart) {
+			hub.BroadcastRaw("session", "session_auto_restarted", r)
+		})
+		p.stops = append(p.stops, p.restarter.Stop)
+
+		// Stage duration warnings from the stage_sla policy
+		sla := stagesla.Start(dir, func(o stagesla.Overrun) {
+			hub.BroadcastRaw("stage", "stage_overrun", o)
+			p.alerts.StageOverrun(o.Stage, o.SLA, o.ElapsedMin)
+		})
+		p.stops = append(p.stops, sla.Stop)
+
+		// Escalations of a gate left awaiting approval, from the
+		// gate_escalation policy
+		gates := gatetimer.Start(dir, func(e gatetimer.Escalation) {
+			hub.BroadcastRaw("gates", "gate_escalated", e)
+			p.alerts.GateEscalated(e.Stage, e.Level, e.Levels, e.Severity, e.PendingMin)
+		})
+		p.stops = append(p.stops, gates.Stop)
+
+		// Projected spend against the budget in config.json
+		forecast := budget.Start(dir, func(f budget.Forecast) {
+			hub.BroadcastRaw("token", "budget_forecast_exceeded", f)
+			p.alerts.BudgetForecast(f.Exceeds, f.Projected.CostUSD, f.Projected.Tokens)
+		})
+		p.stops = append(p.stops, forecast.Stop)
+
+		// Recurring tasks from the schedules in config.json
+		sched := schedule.Start(dir, func(r schedule.Run) {
+			hub.BroadcastRaw("schedule", "schedule_ran", r)
+		})
+		p.stops = append(p.stops, sched.Stop)
+
+		// Structured commands the King drops in .mission/commands/
+		cmds := commands.Start(dir, func(ack commands.Ack) {
+			hub.BroadcastRaw("commands", "command_executed", ack)
+			if a := ack.Approval; a != nil && a.ID == ack.ID {
+				hub.BroadcastRaw("gates", "gate_approval_requested", a)
+				p.alerts.ApprovalRequested(a.ID, a.Stage, a.Note)
+			}
+		})
+		p.stops = append(p.stops, cmds.Stop)
+
+		p.stops = append(p.stops, compactTasksEvery(filepath.Join(dir, ".mission", "state", "tasks.jsonl"), tasksCompactInterval))
+	}
+
+	p.api = api.NewServer(dir, hub, p.trk, p.acc)
+	if events != nil {
+		p.api.SetEventLog(events)
+	}
+	p.api.SetAlerts(p.alerts)
+	p.api.SetGraphCache(graph)
+	p.routes = p.api.Routes()
+	return p
+}
+
+// openAlerts opens dir's alert store. One that can't be read is logged
+// and replaced by an empty store, so alerts still reach the UI.
+func openAlerts(dir string) *alerts.Store {
+	path := filepath.Join(dir, ".mission", "orchestrator", alerts.FileName)
+	store, err := alerts.Open(path)
+	if err != nil {
+		log.Printf("Warning: alerts unreadable for %s, starting empty: %v", dir, err)
+		store, _ = alerts.Open("")
+	}
+	return store
+}
+
+// compactTasksEvery drops superseded entries from the tasks.jsonl at path
+// every interval until the returned stop func is called.
+func compactTasksEvery(path string, interval time.Duration) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if n, err := bridge.CompactTasks(path); err != nil {
+					log.Printf("Warning: compacting %s: %v", path, err)
+				} else if n > 0 {
+					log.Printf("Compacted %s: dropped %d superseded entries", path, n)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// stop shuts down the watcher, tracker and compaction. The hub has no shutdown; its
+// clients are closed with the HTTP server.
+func (p *project) stop() {
+	for i := len(p.stops) - 1; i >= 0; i-- {
+		p.stops[i]()
+	}
+}
+
+// projectSet holds the running project runtimes, keyed by project
+// directory. The startup project is always present; registered projects
+// (~/.mc/projects.json) are started on first use.
+type projectSet struct {
+	watch     bool
+	policy    *origins.Policy
+	staleness tracker.Staleness
+	registry  func() (map[string]string, error)
+
+	maxResponse int64 // file response cap for each project's API
+
+	mu       sync.Mutex
+	def      *project
+	projects map[string]*project
+}
+
+func newProjectSet(defaultDir string, watch bool, policy *origins.Policy, staleness tracker.Staleness) *projectSet {
+	ps := &projectSet{
+		watch:     watch,
+		policy:    policy,
+		staleness: staleness,
+		registry:  api.LoadProjectRegistry,
+		projects:  make(map[string]*project),
+	}
+	ps.def = startProject(defaultDir, watch, policy, staleness)
+	ps.projects[api.ProjectDir(defaultDir)] = ps.def
+	return ps
+}
+
+// get returns the runtime for id: a registered project name, or "default"
+// (or empty) for the startup project.
+func (ps *projectSet) get(id string) (*project, error) {
+	if id == "" || id == defaultProjectID {
+		return ps.def, nil
+	}
+	registry, err := ps.registry()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read project registry: %w", err)
+	}
+	path, ok := registry[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown project %q", id)
+	}
+	dir := api.ProjectDir(path)
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if p, ok := ps.projects[dir]; ok {
+		return p, nil
+	}
+	log.Printf("Starting project %s (%s)", id, dir)
+	p := startProject(dir, ps.watch, ps.policy, ps.staleness)
+	p.api.PinProject()
+	p.api.SetMaxResponseBytes(ps.maxResponse)
+	ps.projects[dir] = p
+	return p, nil
+}
+
+// setMaxResponseBytes caps file responses in every project's API, started
+// now or later.
+func (ps *projectSet) setMaxResponseBytes(n int64) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.maxResponse = n
+	for _, p := range ps.projects {
+		p.api.SetMaxResponseBytes(n)
+	}
+}
+
+// stopAll stops every running project.
+func (ps *projectSet) stopAll() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, p := range ps.projects {
+		p.stop()
+	}
+}
+
+// handleAPI serves /api/p/{id}/... by rewriting the path to /api/... and
+// dispatching to that project's API server.
+func (ps *projectSet) handleAPI(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), "/api/p/")
+	escaped, _, _ := strings.Cut(rest, "/")
+	id, err := url.PathUnescape(escaped)
+	if err != nil || id == "" {
+		problem.NotFound(w, "project id is required")
+		return
+	}
+	tail := strings.TrimPrefix(r.URL.Path, "/api/p/"+id)
+	p, err := ps.get(id)
+	if err != nil {
+		problem.NotFound(w, err.Error())
+		return
+	}
+
+	r2 := r.Clone(r.Context())
+	r2.URL.Path = "/api" + tail
+	r2.URL.RawPath = ""
+	p.routes.ServeHTTP(w, r2)
+}
+
+// handleWebSocket upgrades onto the hub of ?project={id}, or the startup
+// project's hub when no project is given.
+func (ps *projectSet) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	p, err := ps.get(r.URL.Query().Get("project"))
+	if err != nil {
+		problem.NotFound(w, err.Error())
+		return
+	}
+	p.hub.HandleWebSocket(w, r)
+}
+
+// dispatchQueuedWorkers runs mc workers dispatch in a project once one of
+// its workers has died, so mc marks it failed and hands its concurrency
+// slot to a queued worker.
+func dispatchQueuedWorkers(dir string) {
+	cmd := exec.Command("mc", "workers", "dispatch")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("Warning: dispatching queued workers in %s: %v: %s", dir, err, strings.TrimSpace(string(out)))
+	}
+}
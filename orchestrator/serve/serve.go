@@ -3,9 +3,11 @@
 package serve
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
@@ -15,8 +17,15 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/alerts"
 	"github.com/MikeSquared-Agency/MissionControl/api"
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/chat"
+	"github.com/MikeSquared-Agency/MissionControl/jsonl"
 	"github.com/MikeSquared-Agency/MissionControl/openclaw"
+	"github.com/MikeSquared-Agency/MissionControl/origins"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
 	"github.com/MikeSquared-Agency/MissionControl/tokens"
 	"github.com/MikeSquared-Agency/MissionControl/tracker"
 	"github.com/MikeSquared-Agency/MissionControl/watcher"
@@ -27,19 +36,61 @@ import (
 type Config struct {
 	Port       int
 	MissionDir string
-	APIOnly    bool // --api-only: disable file watcher + tracker (just serve API)
-	Headless   bool // --headless: no dashboard, API only
+	APIOnly    bool   // --api-only: disable file watcher + tracker (just serve API)
+	Headless   bool   // --headless: no dashboard, API only
+	Dashboard  fs.FS  // web dashboard build served at /; nil serves none
+	Listen     string // --listen: host:port or unix:/path (overrides Port)
+	TLSCert    string // --tls-cert: PEM certificate; requires TLSKey
+	TLSKey     string // --tls-key: PEM private key; requires TLSCert
+
+	// AllowedOrigins (--allow-origin) may call the API and open WebSockets.
+	// Empty falls back to $MC_ALLOWED_ORIGINS, then localhost + dashboard.
+	AllowedOrigins []string
+
+	// RateLimit is the per-client mutation rate in requests/second
+	// (--rate-limit, 0 disables); RateBurst is the bucket size.
+	RateLimit float64
+	RateBurst int
+
+	// Debug (--debug) serves pprof under /debug/pprof/ and a goroutine
+	// summary at /api/debug/goroutines, over the Unix socket or to
+	// token-authenticated admins only.
+	Debug bool
+
+	// ReadOnly (--read-only) starts with mutations refused; POST
+	// /api/readonly toggles it at runtime.
+	ReadOnly bool
+
+	// WorkerStaleAfter (--worker-stale-after) marks a worker without a
+	// heartbeat stale; WorkerGrace (--worker-grace) later reaps it. Zero
+	// means tracker.DefaultStaleness; a negative WorkerStaleAfter disables.
+	WorkerStaleAfter time.Duration
+	WorkerGrace      time.Duration
+
+	// MaxResponseBytes (--max-response-size) caps a findings, briefing or
+	// checkpoint file served whole; zero is api.DefaultMaxResponseBytes
+	// and a negative value lifts the cap.
+	MaxResponseBytes int64
+
+	// Store (--store) is a Postgres URL to keep the startup project's
+	// state in; empty falls back to $MC_STORE, then the filesystem alone.
+	// StoreProject (--store-project) names the project in the database
+	// and defaults to the project directory's name.
+	Store        string
+	StoreProject string
 }
 
 // topicMap maps watcher event types to hub topics.
 var topicMap = map[string]string{
 	"stage_changed":         "stage",
+	"stage_rolled_back":     "stage",
 	"task_created":          "task",
 	"task_updated":          "task",
 	"task_deleted":          "task",
 	"worker_spawned":        "worker",
 	"worker_completed":      "worker",
 	"worker_status_changed": "worker",
+	"worker_dequeued":       "worker",
 	"gate_approved":         "gate",
 	"gate_ready":            "gate",
 	"zone_activity":         "zone",
@@ -48,6 +99,25 @@ var topicMap = map[string]string{
 	"findings_ready":        "task",
 	"handoff_created":       "task",
 	"memory_updated":        "memory",
+	"spec_added":            "spec",
+	"spec_updated":          "spec",
+	"spec_removed":          "spec",
+	"spec_changed":          "spec",
+	"finding_added":         "findings",
+	"finding_updated":       "findings",
+	"finding_removed":       "findings",
+	"handoff_added":         "handoff",
+	"handoff_updated":       "handoff",
+	"handoff_removed":       "handoff",
+	"handoff_dropped":       "handoff",
+	"handoff_ingested":      "handoff",
+	"handoff_rejected":      "handoff",
+	"prompt_added":          "prompt",
+	"prompt_updated":        "prompt",
+	"prompt_removed":        "prompt",
+	"conversation_message":  "chat",
+	"exchange_completed":    "chat",
+	"conversation_reset":    "chat",
 }
 
 // Run starts the orchestrator server.
@@ -55,107 +125,179 @@ func Run(cfg Config) error {
 	if cfg.Port == 0 {
 		cfg.Port = 8080
 	}
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	useTLS := cfg.TLSCert != ""
+	network, addr := listenAddr(cfg.Listen, cfg.Port)
 
 	missionDir := cfg.MissionDir
 	if missionDir == "" {
 		missionDir = findMissionDir()
 	}
 
-	log.Printf("MissionControl orchestrator starting on :%d", cfg.Port)
-	log.Printf("Mission directory: %s", missionDir)
-
-	// --- Core components ---
-	hub := ws.NewHub()
-	go hub.Run()
-
-	acc := tokens.NewAccumulator(0, func(workerID string, budget, used, remaining int) {
-		hub.BroadcastRaw("token", "budget_warning", map[string]interface{}{
-			"worker_id": workerID,
-			"budget":    budget,
-			"used":      used,
-			"remaining": remaining,
-		})
-	})
+	// Stored secrets never reach the log
+	log.SetOutput(secrets.Writer(log.Writer()))
 
-	trk := tracker.NewTracker(missionDir, func(eventType string, proc *tracker.TrackedProcess) {
-		topic := "worker"
-		hub.BroadcastRaw(topic, eventType, proc)
-	})
+	log.Printf("MissionControl orchestrator starting on %s", addr)
+	log.Printf("Mission directory: %s", missionDir)
 
-	// --- State provider for initial sync ---
-	hub.SetStateProvider(func() interface{} {
-		return buildState(missionDir, trk, acc)
-	})
+	originPolicy := origins.Resolve(cfg.AllowedOrigins)
+	log.Printf("Allowed origins: %s", strings.Join(originPolicy.Patterns(), ", "))
 
-	// --- File watcher → hub bridge ---
-	if !cfg.APIOnly {
-		w := watcher.NewWatcher(filepath.Join(missionDir, ".mission"))
-		if err := w.Start(); err != nil {
-			log.Printf("Warning: file watcher failed to start: %v", err)
-		} else {
-			go bridgeWatcherToHub(w, hub)
-			defer w.Stop()
+	// --- Team server: state in Postgres ---
+	// Restored before the projects start, so their watchers and caches see
+	// the database's state rather than what was left on disk.
+	if cfg.Store == "" {
+		cfg.Store = os.Getenv(EnvStore)
+	}
+	if cfg.Store != "" {
+		stopStore, err := startStore(cfg.Store, cfg.StoreProject, missionDir)
+		if err != nil {
+			return err
 		}
-
-		trk.Start()
-		defer trk.Stop()
+		defer stopStore()
 	}
 
-	// --- HTTP routes ---
+	// --- Projects ---
+	// The startup project is served at /api/ and /ws as before; registered
+	// projects get their own watcher, hub and API under /api/p/{id}/ and
+	// /ws?project={id}, so tabs on different projects don't interfere.
+	projects := newProjectSet(missionDir, !cfg.APIOnly, originPolicy, workerStaleness(cfg))
+	defer projects.stopAll()
+	projects.setMaxResponseBytes(cfg.MaxResponseBytes)
+	def := projects.def
+	hub, trk := def.hub, def.trk
 
-	// Create API server (replaces all inline /api/* handlers)
-	apiServer := api.NewServer(missionDir, hub, trk, acc)
-	apiRoutes := apiServer.Routes()
+	// --- HTTP routes ---
 
 	// Outer mux for WebSocket + OpenClaw (non-api.Server routes)
 	mux := http.NewServeMux()
 
 	// WebSocket
-	mux.HandleFunc("/ws", hub.HandleWebSocket)
+	mux.HandleFunc("/ws", projects.handleWebSocket)
 
-	// Delegate all /api/ routes to api.Server
-	mux.Handle("/api/", apiRoutes)
+	// Delegate all /api/ routes to the startup project's api.Server
+	mux.Handle("/api/", def.routes)
+	mux.HandleFunc("/api/p/", projects.handleAPI)
+
+	// Dashboard: the embedded web build, with client-side routes falling
+	// back to index.html
+	if cfg.Dashboard != nil && !cfg.Headless {
+		mux.Handle("/", dashboardHandler(cfg.Dashboard))
+	}
+
+	// OpenClaw and Ollama routes go on their own mux, mounted behind the
+	// api.Server's role check
+	guarded := http.NewServeMux()
 
 	// --- OpenClaw bridge ---
-	gatewayURL := os.Getenv("OPENCLAW_GATEWAY")
-	gatewayToken := os.Getenv("OPENCLAW_TOKEN")
-	bridgeConnected := false
-	if gatewayURL != "" && gatewayToken != "" {
-		bridge := openclaw.NewBridge(gatewayURL, gatewayToken)
-		if err := bridge.Connect(); err != nil {
-			log.Printf("Warning: OpenClaw bridge failed to connect: %v", err)
-		} else {
-			log.Printf("OpenClaw bridge connected to %s", gatewayURL)
+	// Gateways come from the "openclaw" section of .mission/config.json,
+	// or OPENCLAW_GATEWAY/OPENCLAW_TOKEN. Once configured, bridges
+	// reconnect on their own, so the routes are registered even if a
+	// gateway is down at startup.
+	gateways, err := openclaw.LoadGatewaysConfig(filepath.Join(missionDir, ".mission"))
+	if err != nil {
+		log.Printf("Warning: OpenClaw gateways in config.json ignored: %v", err)
+	}
+	gateways, problems := gateways.Resolve(os.Getenv)
+	for _, p := range problems {
+		log.Printf("Warning: %v", p)
+	}
+	if len(gateways.Gateways) > 0 {
+		bridges := make(map[string]*openclaw.Bridge, len(gateways.Gateways))
+		for name, g := range gateways.Gateways {
+			bridges[name] = openclaw.NewBridge(g.URL, g.Token)
+		}
+		ocHandler := openclaw.NewHandler(bridges[gateways.Default], hub, trk)
+		if err := ocHandler.SetGateways(gateways.Default, bridges, gateways.Routes); err != nil {
+			return err
+		}
+		ocHandler.SetHistory(chat.NewStore(filepath.Join(missionDir, ".mission")))
+		ocHandler.SetTranscript(chat.NewTranscript(filepath.Join(missionDir, ".mission")))
+		ocHandler.SetModels(func(persona, zone string) string {
+			cfg, err := bridge.LoadProjectConfig(missionDir)
+			if err != nil {
+				return ""
+			}
+			return cfg.WorkerModel(persona, cfg.Runtimes.For(persona, zone))
+		})
+		if def.restarter != nil {
+			def.restarter.SetBriefer(ocHandler.Brief)
+		}
+		def.api.SetKing(func(message string) error {
+			return ocHandler.Brief("", message)
+		})
+		for name, bridge := range bridges {
+			if err := bridge.Start(); err != nil {
+				log.Printf("Warning: OpenClaw gateway %s failed to connect, retrying in background: %v", name, err)
+			} else {
+				log.Printf("OpenClaw gateway %s connected to %s", name, gateways.Gateways[name].URL)
+			}
 			defer bridge.Close()
-			bridgeConnected = true
-
-			ocHandler := openclaw.NewHandler(bridge, hub, trk)
-			ocHandler.RegisterRoutes(mux)
-			ocHandler.RegisterChatAlias(mux)
-			ocHandler.RegisterMCRoutes(mux)
 		}
-	}
-	if !bridgeConnected {
-		mux.HandleFunc("/api/openclaw/status", func(w http.ResponseWriter, r *http.Request) {
+		for persona, name := range gateways.Routes {
+			log.Printf("OpenClaw route: %s workers -> %s", persona, name)
+		}
+
+		ocHandler.RegisterRoutes(guarded)
+		ocHandler.RegisterChatAlias(guarded)
+		ocHandler.RegisterMCRoutes(guarded)
+	} else {
+		guarded.HandleFunc("/api/openclaw/status", func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(200)
 			json.NewEncoder(w).Encode(map[string]interface{}{"connected": false})
 		})
-		mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(501)
-			json.NewEncoder(w).Encode(map[string]string{"error": "OpenClaw bridge not configured. Set OPENCLAW_GATEWAY and OPENCLAW_TOKEN."})
+		guarded.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+			problem.Error(w, http.StatusNotImplemented, "OpenClaw bridge not configured. Set OPENCLAW_GATEWAY and OPENCLAW_TOKEN.")
 		})
 	}
 
+	// --- Ollama (offline mode) ---
+	// Chats use the project's ollamaModel unless a model is given, and
+	// their tokens go to the startup project's ledger; model pulls report
+	// progress on the "ollama" topic.
+	ollamaHandler := api.NewOllamaHandler()
+	ollamaHandler.SetProject(missionDir)
+	ollamaHandler.SetUsage(def.acc)
+	ollamaHandler.SetHub(hub)
+	ollamaHandler.RegisterRoutes(guarded)
+	mountGuarded(mux, guarded, def.api.ProjectDir)
+
+	// Rate limiting for mutating requests
+	limiter := api.NewRateLimiter(cfg.RateLimit, cfg.RateBurst)
+	mux.HandleFunc("/api/ratelimit", limiter.StatsHandler())
+	if limiter.Enabled() {
+		log.Printf("Rate limit: %.4g req/s per client, burst %d", cfg.RateLimit, cfg.RateBurst)
+	}
+
+	if cfg.Debug {
+		api.RegisterDebugRoutes(mux, missionDir)
+		log.Printf("Debug endpoints enabled: /debug/pprof/, /api/debug/goroutines")
+	}
+
+	// Maintenance switch: mutations get 503 while it is on
+	readOnly := api.NewReadOnly(cfg.ReadOnly, "started with --read-only")
+	mux.HandleFunc(api.ReadOnlyPath, readOnly.Handler(missionDir, hub))
+	if readOnly.Enabled() {
+		log.Printf("Read-only mode: mutations are refused until POST %s {\"enabled\": false}", api.ReadOnlyPath)
+	}
+
+	// Retried POSTs with an Idempotency-Key replay the first response
+	idempotency := api.NewIdempotencyCache(api.DefaultIdempotencyTTL)
+
 	// Apply middleware
-	handler := api.Chain(mux, api.CORSMiddleware, api.AuthMiddleware)
+	handler := api.Chain(mux, api.RequestIDMiddleware, api.GzipMiddleware, api.CORS(originPolicy), api.AuthMiddleware, readOnly.Middleware, limiter.Middleware, idempotency.Middleware)
+
+	server := newHTTPServer(addr, handler)
 
-	addr := fmt.Sprintf(":%d", cfg.Port)
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           handler,
-		ReadHeaderTimeout: 10 * time.Second,
+	ln, err := listen(network, addr)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		defer os.Remove(addr)
 	}
 
 	// Graceful shutdown
@@ -165,15 +307,57 @@ func Run(cfg Config) error {
 	go func() {
 		<-stop
 		log.Println("Shutting down...")
-		server.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+		}
 	}()
 
-	log.Printf("Listening on %s", addr)
-	return server.ListenAndServe()
+	httpURL, wsURL := endpointURLs(network, addr, useTLS)
+	log.Printf("Listening on %s", httpURL)
+	if cfg.Dashboard != nil && !cfg.Headless {
+		log.Printf("Dashboard: %s/", httpURL)
+	}
+	log.Printf("WebSocket: %s", wsURL)
+
+	ln = newLimitListener(ln, maxConns)
+	if useTLS {
+		err = server.ServeTLS(ln, cfg.TLSCert, cfg.TLSKey)
+	} else {
+		err = server.Serve(ln)
+	}
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// workerStaleness resolves the heartbeat timeouts from cfg.
+func workerStaleness(cfg Config) tracker.Staleness {
+	s := tracker.DefaultStaleness
+	if cfg.WorkerStaleAfter < 0 {
+		return tracker.Staleness{}
+	}
+	if cfg.WorkerStaleAfter > 0 {
+		s.After = cfg.WorkerStaleAfter
+	}
+	if cfg.WorkerGrace > 0 {
+		s.Grace = cfg.WorkerGrace
+	}
+	return s
 }
 
-// bridgeWatcherToHub reads watcher events and broadcasts them on the hub.
-func bridgeWatcherToHub(w *watcher.Watcher, hub *ws.Hub) {
+// bridgeWatcherToHub reads watcher events and broadcasts them on the hub,
+// raising any alert they call for (notices may be nil) and calling changed
+// after each one.
+func bridgeWatcherToHub(w *watcher.Watcher, hub *ws.Hub, notices *alerts.Store, changed func()) {
+	inbox := startHandoffInbox(w.MissionDir(), func(typ string, res *ingestResult) {
+		if data, err := json.Marshal(res); err == nil {
+			hub.Broadcast(ws.Event{Topic: "handoff", Type: typ, Data: data})
+		}
+	})
+	defer inbox.Stop()
 	for event := range w.Events() {
 		topic, ok := topicMap[event.Type]
 		if !ok {
@@ -181,7 +365,13 @@ func bridgeWatcherToHub(w *watcher.Watcher, hub *ws.Hub) {
 			parts := strings.SplitN(event.Type, ".", 2)
 			topic = parts[0]
 		}
-		hub.BroadcastRaw(topic, event.Type, event.Data)
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			log.Printf("watcher event %s: %v", event.Type, err)
+		} else {
+			hub.Broadcast(ws.Event{Topic: topic, Type: event.Type, Data: data, Seq: event.Seq})
+		}
+		notices.Observe(event.Type, event.Data)
 
 		// Handle findings_ready: mark the corresponding task as done
 		if event.Type == "findings_ready" {
@@ -191,6 +381,17 @@ func bridgeWatcherToHub(w *watcher.Watcher, hub *ws.Hub) {
 				}
 			}
 		}
+		// Handle handoff_dropped: ingest it once it is fully written, and
+		// report how that went
+		if event.Type == "handoff_dropped" {
+			inbox.Drop(event.Data)
+		}
+		if err := w.Ack(event.Seq); err != nil {
+			log.Printf("watcher event %d: ack: %v", event.Seq, err)
+		}
+		if changed != nil {
+			changed()
+		}
 	}
 }
 
@@ -232,99 +433,33 @@ func handleFindingsReady(w *watcher.Watcher, taskID string) {
 	log.Printf("findings_ready: marked task %s as complete", taskID)
 }
 
-// taskEntry is a minimal task struct for JSONL read/write in the serve package.
-type taskEntry struct {
-	ID        string   `json:"id"`
-	Name      string   `json:"name"`
-	Stage     string   `json:"stage"`
-	Zone      string   `json:"zone"`
-	Persona   string   `json:"persona"`
-	Status    string   `json:"status"`
-	DependsOn []string `json:"depends_on,omitempty"`
-	WorkerID  string   `json:"worker_id,omitempty"`
-	CreatedAt string   `json:"created_at"`
-	UpdatedAt string   `json:"updated_at"`
-}
-
-// markTaskComplete reads tasks.jsonl, sets the matching task to "complete", and writes back atomically.
+// markTaskComplete sets the matching task in tasks.jsonl to "complete", rewriting the file atomically under its lock.
+// Tasks are kept as raw JSON so fields the serve package doesn't know about survive the rewrite.
 func markTaskComplete(tasksPath, taskID string) error {
-	f, err := os.Open(tasksPath)
-	if err != nil {
+	if _, err := os.Stat(tasksPath); err != nil {
 		return err
 	}
-
-	var tasks []taskEntry
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-		var t taskEntry
-		if err := json.Unmarshal(line, &t); err != nil {
-			continue
-		}
-		tasks = append(tasks, t)
-	}
-	if err := scanner.Err(); err != nil {
-		f.Close()
-		return err
-	}
-	f.Close()
-
-	found := false
-	for i := range tasks {
-		if tasks[i].ID == taskID {
-			if tasks[i].Status == "complete" {
-				return nil // idempotent
+	return jsonl.Update(tasksPath, jsonl.Options{}, func(raw [][]byte) ([][]byte, error) {
+		lines, _ := bridge.LatestTaskLines(bytes.Join(raw, []byte("\n")))
+		for i, line := range lines {
+			var t map[string]interface{}
+			if json.Unmarshal(line, &t) != nil || t["id"] != taskID {
+				continue
 			}
-			tasks[i].Status = "complete"
-			tasks[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-			found = true
-			break
-		}
-	}
-	if !found {
-		return fmt.Errorf("task %s not found in tasks.jsonl", taskID)
-	}
-
-	// Atomic write via temp file + rename
-	tmp, err := os.CreateTemp(filepath.Dir(tasksPath), ".tasks-*.jsonl")
-	if err != nil {
-		return err
-	}
-	tmpPath := tmp.Name()
-
-	w := bufio.NewWriter(tmp)
-	for _, t := range tasks {
-		data, err := json.Marshal(t)
-		if err != nil {
-			tmp.Close()
-			os.Remove(tmpPath)
-			return err
-		}
-		if _, err := w.Write(data); err != nil {
-			tmp.Close()
-			os.Remove(tmpPath)
-			return err
-		}
-		if err := w.WriteByte('\n'); err != nil {
-			tmp.Close()
-			os.Remove(tmpPath)
-			return err
+			if t["status"] == "complete" {
+				return raw, nil // idempotent
+			}
+			t["status"] = "complete"
+			t["updated_at"] = time.Now().UTC().Format(time.RFC3339)
+			data, err := json.Marshal(t)
+			if err != nil {
+				return nil, err
+			}
+			lines[i] = data
+			return lines, nil
 		}
-	}
-	if err := w.Flush(); err != nil {
-		tmp.Close()
-		os.Remove(tmpPath)
-		return err
-	}
-	if err := tmp.Close(); err != nil {
-		os.Remove(tmpPath)
-		return err
-	}
-	return os.Rename(tmpPath, tasksPath)
+		return nil, fmt.Errorf("task %s not found in tasks.jsonl", taskID)
+	})
 }
 
 // buildState returns a full mission state snapshot.
@@ -356,28 +491,25 @@ func buildState(missionDir string, trk *tracker.Tracker, acc *tokens.Accumulator
 		}
 	}
 
-	// Tasks — try JSONL first, then JSON
-	// File may be {"tasks": [...]} (wrapped) or bare JSONL
-	if tasks, err := readJSONL(filepath.Join(missionPath, "tasks.jsonl")); err == nil && len(tasks) > 0 {
-		state["tasks"] = tasks
-	}
-	if _, ok := state["tasks"]; !ok {
+	// Tasks — tasks.jsonl, or the older tasks.json, either {"tasks": [...]}
+	// or a bare array
+	tasks, _, err := bridge.ReadTasks(filepath.Join(missionPath, "tasks.jsonl"))
+	if err != nil || len(tasks) == 0 {
+		tasks = nil
 		if data, err := os.ReadFile(filepath.Join(missionPath, "tasks.json")); err == nil {
-			var wrapper map[string]interface{}
-			if json.Unmarshal(data, &wrapper) == nil {
-				if inner, ok := wrapper["tasks"]; ok {
-					state["tasks"] = inner
-				}
+			var wrapper struct {
+				Tasks []bridge.Task `json:"tasks"`
 			}
-			// If not wrapped, try as array
-			if _, ok := state["tasks"]; !ok {
-				var arr []interface{}
-				if json.Unmarshal(data, &arr) == nil {
-					state["tasks"] = arr
-				}
+			if json.Unmarshal(data, &wrapper) == nil && wrapper.Tasks != nil {
+				tasks = wrapper.Tasks
+			} else {
+				_ = json.Unmarshal(data, &tasks)
 			}
 		}
 	}
+	if tasks != nil {
+		state["tasks"] = tasks
+	}
 
 	// Checkpoints
 	cpDir := filepath.Join(missionDir, ".mission", "orchestrator", "checkpoints")
@@ -426,14 +558,9 @@ func buildState(missionDir string, trk *tracker.Tracker, acc *tokens.Accumulator
 	}
 
 	// Graph — compute from tasks for initial sync
-	if rawTasks, ok := state["tasks"].([]interface{}); ok {
-		taskMaps := make([]map[string]interface{}, 0, len(rawTasks))
-		for _, rt := range rawTasks {
-			if m, ok := rt.(map[string]interface{}); ok {
-				taskMaps = append(taskMaps, m)
-			}
-		}
-		state["graph"] = api.BuildGraph(taskMaps)
+	if tasks != nil {
+		zones, _ := bridge.LoadZones(filepath.Join(missionDir, ".mission", "state", "zones.json"))
+		state["graph"] = api.BuildGraph(tasks, zones)
 	}
 
 	return state
@@ -470,3 +597,17 @@ func findMissionDir() string {
 	home, _ := os.UserHomeDir()
 	return home
 }
+
+// guardedPaths are the paths served from outside the api.Server: the
+// OpenClaw bridge's and Ollama's.
+var guardedPaths = []string{"/api/openclaw/", "/api/chat", "/api/mc/", "/api/ollama/"}
+
+// mountGuarded serves guarded's routes on mux with the same role check as
+// the api.Server's, so a viewer of the project projectDir returns can
+// only look.
+func mountGuarded(mux, guarded *http.ServeMux, projectDir func() string) {
+	h := api.Authorize(projectDir, guarded)
+	for _, path := range guardedPaths {
+		mux.Handle(path, h)
+	}
+}
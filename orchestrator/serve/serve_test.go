@@ -1,15 +1,29 @@
 package serve
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/MikeSquared-Agency/MissionControl/api"
+	"github.com/MikeSquared-Agency/MissionControl/openclaw"
+	"github.com/MikeSquared-Agency/MissionControl/origins"
 	"github.com/MikeSquared-Agency/MissionControl/tokens"
 	"github.com/MikeSquared-Agency/MissionControl/tracker"
 	"github.com/gorilla/websocket"
@@ -404,3 +418,318 @@ func newTestTracker(dir string) *tracker.Tracker {
 func newTestAccumulator() *tokens.Accumulator {
 	return tokens.NewAccumulator(0, func(string, int, int, int) {})
 }
+
+func TestLimitListenerCapsConnections(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := newLimitListener(inner, 1)
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- c
+		}
+	}()
+
+	c1, _ := net.Dial("tcp", inner.Addr().String())
+	defer c1.Close()
+	first := <-accepted
+
+	c2, _ := net.Dial("tcp", inner.Addr().String())
+	defer c2.Close()
+	select {
+	case <-accepted:
+		t.Fatal("second connection accepted while at the limit")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	first.Close()
+	select {
+	case c := <-accepted:
+		c.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("second connection not accepted after slot freed")
+	}
+}
+
+func TestNewHTTPServerLimits(t *testing.T) {
+	srv := newHTTPServer(":0", http.NotFoundHandler())
+	if srv.ReadHeaderTimeout == 0 || srv.ReadTimeout == 0 || srv.WriteTimeout == 0 || srv.IdleTimeout == 0 {
+		t.Errorf("timeouts not configured: %+v", srv)
+	}
+	if srv.MaxHeaderBytes != maxHeaderBytes {
+		t.Errorf("MaxHeaderBytes = %d", srv.MaxHeaderBytes)
+	}
+}
+
+func TestListenAddrAndEndpointURLs(t *testing.T) {
+	tests := []struct {
+		listen         string
+		port           int
+		tls            bool
+		network, addr  string
+		httpURL, wsURL string
+	}{
+		{"", 8080, false, "tcp", ":8080", "http://localhost:8080", "ws://localhost:8080/ws"},
+		{"127.0.0.1:9000", 8080, true, "tcp", "127.0.0.1:9000", "https://127.0.0.1:9000", "wss://127.0.0.1:9000/ws"},
+		{"[::]:9000", 0, false, "tcp", "[::]:9000", "http://localhost:9000", "ws://localhost:9000/ws"},
+		{"unix:/run/mc.sock", 8080, false, "unix", "/run/mc.sock", "unix:/run/mc.sock", "ws+unix:/run/mc.sock:/ws"},
+	}
+	for _, tt := range tests {
+		network, addr := listenAddr(tt.listen, tt.port)
+		if network != tt.network || addr != tt.addr {
+			t.Errorf("listenAddr(%q) = %s %s, want %s %s", tt.listen, network, addr, tt.network, tt.addr)
+		}
+		httpURL, wsURL := endpointURLs(network, addr, tt.tls)
+		if httpURL != tt.httpURL || wsURL != tt.wsURL {
+			t.Errorf("endpointURLs(%q) = %s %s, want %s %s", tt.listen, httpURL, wsURL, tt.httpURL, tt.wsURL)
+		}
+	}
+}
+
+func TestRunRequiresCertAndKeyTogether(t *testing.T) {
+	err := Run(Config{MissionDir: t.TempDir(), APIOnly: true, TLSCert: "cert.pem"})
+	if err == nil || !strings.Contains(err.Error(), "--tls-key") {
+		t.Errorf("expected cert/key pairing error, got %v", err)
+	}
+}
+
+func TestServeOnUnixSocket(t *testing.T) {
+	dir := createTestMission(t)
+	// Socket paths are length-limited; keep it short.
+	sockDir, err := os.MkdirTemp("", "mc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sockDir)
+	sock := filepath.Join(sockDir, "mc.sock")
+
+	// A stale socket from a previous run must not block startup.
+	stale, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	go Run(Config{MissionDir: dir, APIOnly: true, Listen: "unix:" + sock})
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	var resp *http.Response
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if resp, err = client.Get("http://unix/api/health"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if info, err := os.Stat(sock); err != nil || info.Mode().Perm() != 0660 {
+		t.Errorf("socket mode = %v, %v", info.Mode().Perm(), err)
+	}
+
+	// A live socket is not stolen by a second instance.
+	if err := Run(Config{MissionDir: dir, APIOnly: true, Listen: "unix:" + sock}); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("expected in-use error, got %v", err)
+	}
+}
+
+func TestServeTLS(t *testing.T) {
+	dir := createTestMission(t)
+	certFile, keyFile := writeSelfSignedCert(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	go Run(Config{MissionDir: dir, APIOnly: true, Listen: addr, TLSCert: certFile, TLSKey: keyFile})
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	var resp *http.Response
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if resp, err = client.Get("https://" + addr + "/api/health"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET over TLS: %v", err)
+	}
+	resp.Body.Close()
+	if resp.TLS == nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 over TLS, got %d (tls=%v)", resp.StatusCode, resp.TLS != nil)
+	}
+}
+
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestProjectSetRouting(t *testing.T) {
+	alpha := createTestMission(t)
+	beta := t.TempDir()
+	betaState := filepath.Join(beta, ".mission", "state")
+	if err := os.MkdirAll(betaState, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(betaState, "tasks.jsonl"), []byte(`{"id":"t2","name":"Beta task","status":"pending"}`+"\n"), 0644)
+
+	ps := newProjectSet(alpha, false, origins.Default(), tracker.DefaultStaleness)
+	ps.registry = func() (map[string]string, error) {
+		return map[string]string{"beta": filepath.Join(beta, ".mission")}, nil
+	}
+	defer ps.stopAll()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", ps.handleWebSocket)
+	mux.Handle("/api/", ps.def.routes)
+	mux.HandleFunc("/api/p/", ps.handleAPI)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	firstTaskID := func(path string) interface{} {
+		tasks := getJSONArray(t, srv.URL+path)
+		if len(tasks) != 1 {
+			t.Fatalf("GET %s: expected 1 task, got %v", path, tasks)
+		}
+		return tasks[0].(map[string]interface{})["id"]
+	}
+	if id := firstTaskID("/api/tasks"); id != "t1" {
+		t.Errorf("/api/tasks: expected t1, got %v", id)
+	}
+	if id := firstTaskID("/api/p/default/tasks"); id != "t1" {
+		t.Errorf("/api/p/default/tasks: expected t1, got %v", id)
+	}
+	if id := firstTaskID("/api/p/beta/tasks"); id != "t2" {
+		t.Errorf("/api/p/beta/tasks: expected t2, got %v", id)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/p/nope/tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown project: expected 404, got %d", resp.StatusCode)
+	}
+
+	// Project-scoped servers refuse to be switched to another project.
+	body := strings.NewReader(fmt.Sprintf(`{"path":%q}`, alpha))
+	resp, err = http.Post(srv.URL+"/api/p/beta/projects", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("switch on pinned project: expected 409, got %d", resp.StatusCode)
+	}
+
+	// Runtimes are started once and reused.
+	p1, _ := ps.get("beta")
+	p2, _ := ps.get("beta")
+	if p1 != p2 || p1 == ps.def {
+		t.Error("expected one dedicated runtime for beta")
+	}
+
+	// The WebSocket initial state comes from the selected project.
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+srv.URL[4:]+"/ws?project=beta", nil)
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event struct {
+		Type string `json:"type"`
+		Data struct {
+			Tasks []map[string]interface{} `json:"tasks"`
+		} `json:"data"`
+	}
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("read ws: %v", err)
+	}
+	if event.Type != "initial_state" || len(event.Data.Tasks) != 1 || event.Data.Tasks[0]["id"] != "t2" {
+		t.Errorf("expected beta initial state, got %+v", event)
+	}
+}
+
+func TestGuardedRoutesRefuseViewers(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".mission"), 0755)
+	os.WriteFile(filepath.Join(dir, ".mission", "config.json"), []byte(`{"roles": {"*": "viewer"}}`), 0644)
+
+	guarded := http.NewServeMux()
+	oc := openclaw.NewHandler(openclaw.NewBridge("ws://127.0.0.1:0", "token"), nil)
+	oc.RegisterRoutes(guarded)
+	oc.RegisterChatAlias(guarded)
+	oc.RegisterMCRoutes(guarded)
+	api.NewOllamaHandler().RegisterRoutes(guarded)
+	mux := http.NewServeMux()
+	mountGuarded(mux, guarded, func() string { return dir })
+
+	for _, path := range []string{
+		"/api/chat",
+		"/api/openclaw/send",
+		"/api/openclaw/chat",
+		"/api/mc/worker/register",
+		"/api/mc/worker/w1/cancel",
+		"/api/ollama/models/pull",
+		"/api/ollama/chat",
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(`{}`)))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("POST %s as a viewer: %d, want 403", path, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/mc/workers", nil))
+	if w.Code == http.StatusForbidden {
+		t.Errorf("GET /api/mc/workers as a viewer: 403, want it allowed")
+	}
+}
@@ -0,0 +1,128 @@
+package serve
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP server limits. WriteTimeout bounds ordinary responses; streaming
+// handlers clear their own write deadline and WebSocket connections are
+// hijacked, which clears both deadlines.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 60 * time.Second
+	idleTimeout       = 120 * time.Second
+	maxHeaderBytes    = 64 << 10
+	maxConns          = 512
+	shutdownTimeout   = 10 * time.Second
+)
+
+// newHTTPServer returns the orchestrator's configured http.Server.
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+}
+
+// limitListener caps the number of simultaneously open connections; Accept
+// blocks until a slot is free, so excess clients wait in the kernel backlog.
+type limitListener struct {
+	net.Listener
+	sem chan struct{}
+}
+
+func newLimitListener(l net.Listener, n int) net.Listener {
+	return &limitListener{Listener: l, sem: make(chan struct{}, n)}
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	l.sem <- struct{}{}
+	c, err := l.Listener.Accept()
+	if err != nil {
+		<-l.sem
+		return nil, err
+	}
+	return &limitConn{Conn: c, release: func() { <-l.sem }}, nil
+}
+
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+// listenAddr resolves the --listen value into a network and address.
+// "unix:/path/to/mc.sock" selects a Unix socket; anything else is TCP.
+func listenAddr(listen string, port int) (network, addr string) {
+	if listen == "" {
+		return "tcp", fmt.Sprintf(":%d", port)
+	}
+	if path, ok := strings.CutPrefix(listen, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", listen
+}
+
+// listen opens the listener. A stale Unix socket left by a previous run is
+// removed first; the new socket is restricted to the owner and group.
+func listen(network, addr string) (net.Listener, error) {
+	if network != "unix" {
+		return net.Listen(network, addr)
+	}
+	if info, err := os.Lstat(addr); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", addr)
+		}
+		if c, err := net.Dial("unix", addr); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("%s is already in use", addr)
+		}
+		os.Remove(addr)
+	}
+	ln, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(addr, 0660); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// endpointURLs returns the HTTP and WebSocket URLs clients should use.
+func endpointURLs(network, addr string, tls bool) (httpURL, wsURL string) {
+	if network == "unix" {
+		return "unix:" + addr, "ws+unix:" + addr + ":/ws"
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, ""
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	hostport := net.JoinHostPort(host, port)
+	if tls {
+		return "https://" + hostport, "wss://" + hostport + "/ws"
+	}
+	return "http://" + hostport, "ws://" + hostport + "/ws"
+}
@@ -0,0 +1,50 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/MikeSquared-Agency/MissionControl/pgstore"
+)
+
+// EnvStore is the Postgres URL used when --store isn't given.
+const EnvStore = "MC_STORE"
+
+// startStore restores the project's state from the Postgres database at
+// url and mirrors changes back until the returned stop is called, which
+// waits for a last sync. The URL may carry a password, so it isn't logged.
+func startStore(url, project, dir string) (stop func(), err error) {
+	if project == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		project = filepath.Base(abs)
+	}
+
+	ctx := context.Background()
+	db, err := pgstore.Open(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("--store: %w", err)
+	}
+	m := pgstore.NewMirror(db, project, filepath.Join(dir, ".mission"))
+	if err := m.Restore(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("restoring state from Postgres: %w", err)
+	}
+	log.Printf("State store: Postgres, project %q", project)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		m.Run(runCtx, pgstore.DefaultInterval)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+		db.Close()
+	}, nil
+}
@@ -0,0 +1,359 @@
+// Package simulate dry-runs a mission: it schedules the unfinished tasks
+// stage by stage under the configured concurrency limits, without
+// spawning anything, and projects how long the rest of the mission takes,
+// what it costs in tokens and where work queues up. The same simulation
+// backs `mc simulate` and GET /api/simulate.
+//
+// Time is measured in the unit of the task estimates (points or hours);
+// a task without an estimate takes 1. Each stage starts when the previous
+// one is finished, as its gate would, and within a stage a task starts
+// once its dependencies are done and a worker slot is free.
+package simulate
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/report"
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
+)
+
+// DefaultTokensPerUnit is the token spend assumed per unit of estimate.
+const DefaultTokensPerUnit = 50000
+
+// outputShare is the share of a task's tokens assumed to be output, for
+// pricing.
+const outputShare = 0.2
+
+// Options adjust a simulation. Zero values use the mission's settings.
+type Options struct {
+	MaxWorkers    int // overrides limits.maxWorkers when positive
+	TokensPerUnit int
+}
+
+// Result is a simulated schedule of the mission's remaining work.
+type Result struct {
+	Duration    float64              `json:"duration"` // in estimate units
+	Tokens      int                  `json:"tokens"`
+	Cost        float64              `json:"cost_usd"`
+	MaxParallel int                  `json:"max_parallel"`
+	Limits      *bridge.LimitsConfig `json:"limits,omitempty"`
+	Stages      []Stage              `json:"stages"`
+	Tasks       []Task               `json:"tasks"`
+	Bottlenecks []Bottleneck         `json:"bottlenecks"`
+	Unscheduled []string             `json:"unscheduled"` // blocked by cycles or later stages
+}
+
+// Stage is one stage's share of the schedule.
+type Stage struct {
+	Name     string  `json:"name"`
+	Tasks    int     `json:"tasks"`
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Duration float64 `json:"duration"`
+	Tokens   int     `json:"tokens"`
+	Cost     float64 `json:"cost_usd"`
+}
+
+// Task is one scheduled task. Wait is the time it was ready but had no
+// worker slot.
+type Task struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Stage   string  `json:"stage"`
+	Zone    string  `json:"zone,omitempty"`
+	Persona string  `json:"persona,omitempty"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Wait    float64 `json:"wait,omitempty"`
+	Tokens  int     `json:"tokens"`
+	Cost    float64 `json:"cost_usd"`
+}
+
+// Bottleneck is a concurrency limit tasks queued behind: "workers" for
+// maxWorkers, or "zone:<name>". Wait is the total time tasks spent
+// queued.
+type Bottleneck struct {
+	Resource string  `json:"resource"`
+	Limit    int     `json:"limit"`
+	Tasks    int     `json:"tasks"`
+	Wait     float64 `json:"wait"`
+}
+
+// task is a task as read from tasks.jsonl.
+type task struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Stage     string   `json:"stage"`
+	Zone      string   `json:"zone"`
+	Persona   string   `json:"persona"`
+	Status    string   `json:"status"`
+	DependsOn []string `json:"depends_on"`
+	Estimate  float64  `json:"estimate"`
+}
+
+func (t task) finished() bool {
+	return t.Status == "complete" || t.Status == "done" || t.Status == "archived"
+}
+
+func (t task) estimate() float64 {
+	if t.Estimate > 0 {
+		return t.Estimate
+	}
+	return 1
+}
+
+// Run simulates the remaining work of the mission in missionDir (.mission).
+func Run(missionDir string, opts Options) (*Result, error) {
+	tasks, err := loadTasks(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := bridge.LoadProjectConfig(filepath.Dir(missionDir))
+	if err != nil {
+		return nil, err
+	}
+	zones, err := bridge.LoadZones(filepath.Join(missionDir, "state", "zones.json"))
+	if err != nil {
+		return nil, err
+	}
+	limits := cfg.Limits.WithZones(zones)
+	if opts.MaxWorkers > 0 {
+		if limits == nil {
+			limits = &bridge.LimitsConfig{}
+		}
+		limits.MaxWorkers = opts.MaxWorkers
+	}
+	if opts.TokensPerUnit <= 0 {
+		opts.TokensPerUnit = DefaultTokensPerUnit
+	}
+	return schedule(tasks, limits, opts.TokensPerUnit, personaModels(cfg)), nil
+}
+
+// personaModels prices each persona's tasks as the model its workers run
+// on, or the built-in persona default when none is configured. Offline
+// models have no price.
+func personaModels(cfg *bridge.ProjectConfig) func(persona string) tokens.ModelTier {
+	return func(persona string) tokens.ModelTier {
+		if model := cfg.WorkerModel(persona, cfg.Runtimes.For(persona, "")); model != "" {
+			return tokens.ModelTier(model)
+		}
+		return tokens.ModelForPersona(persona)
+	}
+}
+
+// loadTasks reads the latest entry of every task in tasks.jsonl.
+func loadTasks(missionDir string) ([]task, error) {
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "tasks.jsonl"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	lines, _ := bridge.LatestTaskLines(data)
+	tasks := make([]task, 0, len(lines))
+	for _, line := range lines {
+		var t task
+		if json.Unmarshal(line, &t) == nil && t.ID != "" {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+// stageIndex orders stages as the workflow does; unknown stages go last.
+func stageIndex(stage string) int {
+	for i, s := range report.Stages {
+		if s == stage {
+			return i
+		}
+	}
+	return len(report.Stages)
+}
+
+// schedule runs the simulation over tasks.
+func schedule(tasks []task, limits *bridge.LimitsConfig, tokensPerUnit int, modelFor func(persona string) tokens.ModelTier) *Result {
+	res := &Result{
+		Limits:      limits,
+		Stages:      []Stage{},
+		Tasks:       []Task{},
+		Bottlenecks: []Bottleneck{},
+		Unscheduled: []string{},
+	}
+
+	done := map[string]bool{}
+	known := map[string]bool{}
+	byStage := map[string][]task{}
+	var stages []string
+	for _, t := range tasks {
+		known[t.ID] = true
+		if t.finished() {
+			done[t.ID] = true
+			continue
+		}
+		if _, ok := byStage[t.Stage]; !ok {
+			stages = append(stages, t.Stage)
+		}
+		byStage[t.Stage] = append(byStage[t.Stage], t)
+	}
+	sort.SliceStable(stages, func(i, j int) bool { return stageIndex(stages[i]) < stageIndex(stages[j]) })
+
+	waits := map[string]*Bottleneck{}
+	now := 0.0
+	for _, name := range stages {
+		st := Stage{Name: name, Start: now}
+		scheduled := runStage(byStage[name], now, limits, done, known, waits, res)
+		for _, t := range scheduled {
+			t.Tokens = int(math.Round(float64(tokensPerUnit) * (t.End - t.Start)))
+			out := int(float64(t.Tokens) * outputShare)
+			t.Cost = tokens.EstimateCost(modelFor(t.Persona), t.Tokens-out, out)
+			st.Tasks++
+			st.End = math.Max(st.End, t.End)
+			st.Tokens += t.Tokens
+			st.Cost += t.Cost
+			res.Tasks = append(res.Tasks, t)
+		}
+		if st.Tasks == 0 {
+			continue
+		}
+		st.Duration = st.End - st.Start
+		now = st.End
+		res.Tokens += st.Tokens
+		res.Cost += st.Cost
+		res.Stages = append(res.Stages, st)
+	}
+	res.Duration = now
+
+	for _, b := range waits {
+		res.Bottlenecks = append(res.Bottlenecks, *b)
+	}
+	sort.Slice(res.Bottlenecks, func(i, j int) bool {
+		if res.Bottlenecks[i].Wait != res.Bottlenecks[j].Wait {
+			return res.Bottlenecks[i].Wait > res.Bottlenecks[j].Wait
+		}
+		return res.Bottlenecks[i].Resource < res.Bottlenecks[j].Resource
+	})
+	return res
+}
+
+// runStage schedules one stage's tasks from start, in task order as slots
+// and dependencies allow. Finished tasks are added to done; tasks that can
+// never start are added to res.Unscheduled.
+func runStage(pending []task, start float64, limits *bridge.LimitsConfig, done, known map[string]bool, waits map[string]*Bottleneck, res *Result) []Task {
+	type run struct {
+		task  task
+		start float64
+		end   float64
+	}
+	var running []run
+	var out []Task
+	readyAt := map[string]float64{}
+	blockedBy := map[string]string{}
+	now := start
+
+	for len(pending) > 0 || len(running) > 0 {
+		// Start every ready task a slot is free for
+		inZone := map[string]int{}
+		for _, r := range running {
+			inZone[r.task.Zone]++
+		}
+		var waiting []task
+		for _, t := range pending {
+			if !depsDone(t, done, known) {
+				waiting = append(waiting, t)
+				continue
+			}
+			if _, ok := readyAt[t.ID]; !ok {
+				readyAt[t.ID] = now
+			}
+			if !limits.Allows(t.Zone, len(running), inZone[t.Zone]) {
+				blockedBy[t.ID] = limitHit(limits, t.Zone, len(running))
+				waiting = append(waiting, t)
+				continue
+			}
+			running = append(running, run{task: t, start: now, end: now + t.estimate()})
+			inZone[t.Zone]++
+		}
+		pending = waiting
+		if len(running) > res.MaxParallel {
+			res.MaxParallel = len(running)
+		}
+		if len(running) == 0 {
+			// Nothing runs and nothing can start: the rest wait on cycles
+			// or on tasks of later stages
+			for _, t := range pending {
+				res.Unscheduled = append(res.Unscheduled, t.ID)
+			}
+			break
+		}
+
+		// Advance to the next finish
+		next := math.Inf(1)
+		for _, r := range running {
+			next = math.Min(next, r.end)
+		}
+		now = next
+		var still []run
+		for _, r := range running {
+			if r.end > now {
+				still = append(still, r)
+				continue
+			}
+			done[r.task.ID] = true
+			t := Task{
+				ID:      r.task.ID,
+				Name:    r.task.Name,
+				Stage:   r.task.Stage,
+				Zone:    r.task.Zone,
+				Persona: r.task.Persona,
+				Start:   r.start,
+				End:     r.end,
+				Wait:    r.start - readyAt[r.task.ID],
+			}
+			if resource, ok := blockedBy[t.ID]; ok && t.Wait > 0 {
+				b := waits[resource]
+				if b == nil {
+					b = &Bottleneck{Resource: resource, Limit: limitOf(limits, resource)}
+					waits[resource] = b
+				}
+				b.Tasks++
+				b.Wait += t.Wait
+			}
+			out = append(out, t)
+		}
+		running = still
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
+
+// depsDone reports whether t's dependencies are finished. Dependencies on
+// tasks that no longer exist don't hold anything up.
+func depsDone(t task, done, known map[string]bool) bool {
+	for _, d := range t.DependsOn {
+		if known[d] && !done[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// limitHit names the limit that kept a task in zone from starting.
+func limitHit(limits *bridge.LimitsConfig, zone string, running int) string {
+	if limits.MaxWorkers > 0 && running >= limits.MaxWorkers {
+		return "workers"
+	}
+	return "zone:" + zone
+}
+
+func limitOf(limits *bridge.LimitsConfig, resource string) int {
+	if resource == "workers" {
+		return limits.MaxWorkers
+	}
+	return limits.Zones[resource[len("zone:"):]]
+}
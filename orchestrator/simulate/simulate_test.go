@@ -0,0 +1,137 @@
+package simulate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeMission(t *testing.T, config string, tasks ...string) string {
+	t.Helper()
+	mission := filepath.Join(t.TempDir(), ".mission")
+	if err := os.MkdirAll(filepath.Join(mission, "state"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mission, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mission, "state", "tasks.jsonl"), []byte(strings.Join(tasks, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return mission
+}
+
+func TestRunSchedulesStagesUnderLimits(t *testing.T) {
+	mission := writeMission(t, `{"limits":{"maxWorkers":2,"zones":{"backend":1}}}`,
+		`{"id":"d1","name":"Done","stage":"design","status":"complete","estimate":5}`,
+		`{"id":"d2","name":"API design","stage":"design","zone":"backend","persona":"architect","status":"pending","estimate":2}`,
+		`{"id":"i1","name":"Handlers","stage":"implement","zone":"backend","persona":"developer","status":"pending","estimate":3,"depends_on":["d1"]}`,
+		`{"id":"i2","name":"Store","stage":"implement","zone":"backend","persona":"developer","status":"pending","estimate":1}`,
+		`{"id":"i3","name":"UI","stage":"implement","zone":"frontend","persona":"developer","status":"pending","estimate":2}`,
+		`{"id":"i4","name":"Wire up","stage":"implement","zone":"frontend","persona":"developer","status":"pending","depends_on":["i3","i2"]}`,
+		`{"id":"v1","name":"Review","stage":"verify","persona":"reviewer","status":"pending","depends_on":["v2"]}`,
+		`{"id":"v2","name":"Loop","stage":"verify","persona":"reviewer","status":"pending","depends_on":["v1"]}`,
+	)
+
+	res, err := Run(mission, Options{TokensPerUnit: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// design: d2 0→2. implement from 2: i1 and i3 start, i2 queues for the
+	// backend slot until i1 ends at 5, i4 follows i2 at 6 and ends at 7.
+	starts := map[string][2]float64{}
+	for _, tk := range res.Tasks {
+		starts[tk.ID] = [2]float64{tk.Start, tk.End}
+	}
+	want := map[string][2]float64{
+		"d2": {0, 2},
+		"i1": {2, 5},
+		"i3": {2, 4},
+		"i2": {5, 6},
+		"i4": {6, 7},
+	}
+	if !reflect.DeepEqual(starts, want) {
+		t.Errorf("schedule = %v, want %v", starts, want)
+	}
+	if res.Duration != 7 {
+		t.Errorf("duration = %v, want 7", res.Duration)
+	}
+	if len(res.Stages) != 2 || res.Stages[1].Name != "implement" || res.Stages[1].Duration != 5 {
+		t.Errorf("stages = %+v, want design then implement lasting 5", res.Stages)
+	}
+	if res.Tokens != 9000 {
+		t.Errorf("tokens = %d, want 9000 (9 units at 1000)", res.Tokens)
+	}
+	if res.Cost <= 0 {
+		t.Errorf("cost = %v, want a positive estimate", res.Cost)
+	}
+	if res.MaxParallel != 2 {
+		t.Errorf("max parallel = %d, want 2", res.MaxParallel)
+	}
+	if len(res.Bottlenecks) != 1 || res.Bottlenecks[0].Resource != "zone:backend" || res.Bottlenecks[0].Limit != 1 || res.Bottlenecks[0].Wait != 3 {
+		t.Errorf("bottlenecks = %+v, want zone:backend (limit 1) with 3 waited", res.Bottlenecks)
+	}
+	if !reflect.DeepEqual(res.Unscheduled, []string{"v1", "v2"}) {
+		t.Errorf("unscheduled = %v, want the v1/v2 cycle", res.Unscheduled)
+	}
+}
+
+func TestRunMaxWorkersOverride(t *testing.T) {
+	mission := writeMission(t, `{}`,
+		`{"id":"a","stage":"implement","status":"pending","estimate":2}`,
+		`{"id":"b","stage":"implement","status":"pending","estimate":2}`,
+		`{"id":"c","stage":"implement","status":"pending","estimate":2}`,
+	)
+
+	res, err := Run(mission, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Duration != 2 || len(res.Bottlenecks) != 0 {
+		t.Errorf("unlimited: duration %v, bottlenecks %+v; want 2 and none", res.Duration, res.Bottlenecks)
+	}
+
+	res, err = Run(mission, Options{MaxWorkers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Duration != 6 {
+		t.Errorf("one worker: duration %v, want 6", res.Duration)
+	}
+	if len(res.Bottlenecks) != 1 || res.Bottlenecks[0].Resource != "workers" || res.Bottlenecks[0].Tasks != 2 || res.Bottlenecks[0].Wait != 6 {
+		t.Errorf("one worker: bottlenecks %+v, want workers with 2 tasks waiting 6", res.Bottlenecks)
+	}
+}
+
+func TestRunWithoutTasks(t *testing.T) {
+	mission := filepath.Join(t.TempDir(), ".mission")
+	res, err := Run(mission, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Duration != 0 || len(res.Tasks) != 0 || res.Tasks == nil {
+		t.Errorf("empty mission = %+v, want an empty schedule", res)
+	}
+}
+
+func TestRunPricesConfiguredModels(t *testing.T) {
+	task := `{"id":"r","stage":"discovery","persona":"researcher","status":"pending"}`
+	price := func(config string) float64 {
+		res, err := Run(writeMission(t, config, task), Options{TokensPerUnit: 1000000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Cost
+	}
+	sonnet := price(`{}`)
+	haiku := price(`{"models":{"personas":{"researcher":{"model":"haiku"}}}}`)
+	if haiku <= 0 || haiku >= sonnet {
+		t.Errorf("researcher on haiku costs %v, want less than the sonnet default %v", haiku, sonnet)
+	}
+	if offline := price(`{"mode":"offline","models":{"default":{"offlineModel":"qwen3-coder"}}}`); offline != 0 {
+		t.Errorf("offline model costs %v, want nothing", offline)
+	}
+}
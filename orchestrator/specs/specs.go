@@ -0,0 +1,240 @@
+// Package specs tracks the approval state of the specs in .mission/specs/.
+// A spec moves draft → review → approved → superseded; approving it locks
+// a version by recording the hash of its content, so later edits show up
+// as changes to an approved spec instead of going unnoticed.
+package specs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StateFile holds the spec states, in .mission/state/.
+const StateFile = "specs.json"
+
+// Spec statuses
+const (
+	StatusDraft      = "draft"
+	StatusReview     = "review"
+	StatusApproved   = "approved"
+	StatusSuperseded = "superseded"
+)
+
+var (
+	ErrNotFound   = errors.New("spec not found")
+	ErrTransition = errors.New("invalid spec transition")
+)
+
+// transitions are the allowed moves between statuses. Review can go back
+// to draft when changes are requested, or be submitted again after an
+// edit, and an approved spec goes back to review to approve a new
+// version.
+var transitions = map[string][]string{
+	StatusDraft:    {StatusReview},
+	StatusReview:   {StatusDraft, StatusReview, StatusApproved},
+	StatusApproved: {StatusReview, StatusSuperseded},
+}
+
+var validID = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Record is the approval state of one spec. Hash and Version belong to the
+// last approved version; a spec never approved has neither. ReviewHash is
+// the hash of the content submitted for review, the only content that
+// can be approved.
+type Record struct {
+	Status       string     `json:"status"`
+	Version      int        `json:"version,omitempty"`
+	Hash         string     `json:"hash,omitempty"`
+	ReviewHash   string     `json:"spec_hash,omitempty"`
+	ApprovedBy   string     `json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time `json:"approved_at,omitempty"`
+	SupersededBy string     `json:"superseded_by,omitempty"`
+	Note         string     `json:"note,omitempty"` // of the last transition
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Spec is a spec file with its state.
+type Spec struct {
+	ID string `json:"id"`
+	Record
+	CurrentHash string `json:"current_hash"`
+	Modified    bool   `json:"modified"` // edited since its approved version
+}
+
+// Hash returns the content hash recorded at approval.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Path returns the file of spec id.
+func Path(missionDir, id string) string {
+	return filepath.Join(missionDir, "specs", id+".md")
+}
+
+// CurrentHash returns the hash of spec id's content as it is now.
+func CurrentHash(missionDir, id string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("invalid spec ID %q", id)
+	}
+	data, err := os.ReadFile(Path(missionDir, id))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	return Hash(data), nil
+}
+
+// Load returns the recorded states by spec ID. Specs without a record are
+// drafts.
+func Load(missionDir string) (map[string]Record, error) {
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", StateFile))
+	if os.IsNotExist(err) {
+		return map[string]Record{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Specs map[string]Record `json:"specs"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", StateFile, err)
+	}
+	if f.Specs == nil {
+		f.Specs = map[string]Record{}
+	}
+	return f.Specs, nil
+}
+
+// Get returns spec id with its state.
+func Get(missionDir, id string) (Spec, error) {
+	hash, err := CurrentHash(missionDir, id)
+	if err != nil {
+		return Spec{}, err
+	}
+	records, err := Load(missionDir)
+	if err != nil {
+		return Spec{}, err
+	}
+	return newSpec(id, records[id], hash), nil
+}
+
+// List returns every spec in .mission/specs/ with its state, in file name
+// order.
+func List(missionDir string) ([]Spec, error) {
+	files, _ := filepath.Glob(filepath.Join(missionDir, "specs", "*.md"))
+	records, err := Load(missionDir)
+	if err != nil {
+		return nil, err
+	}
+	list := []Spec{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(filepath.Base(file), ".md")
+		list = append(list, newSpec(id, records[id], Hash(data)))
+	}
+	return list, nil
+}
+
+func newSpec(id string, r Record, hash string) Spec {
+	if r.Status == "" {
+		r.Status = StatusDraft
+	}
+	return Spec{ID: id, Record: r, CurrentHash: hash, Modified: r.Hash != "" && r.Hash != hash}
+}
+
+// Changed reports whether spec id's content differs from hash, the hash a
+// task recorded when it was created. A spec that no longer exists has
+// changed.
+func Changed(missionDir, id, hash string) bool {
+	current, err := CurrentHash(missionDir, id)
+	return err != nil || current != hash
+}
+
+// Transition moves spec id to status as who, with an optional note.
+// Submitting for review records the content hash, and approving refuses
+// a spec edited since then; approval records the hash and bumps the
+// version. supersededBy names the spec that replaces it, if any.
+func Transition(missionDir, id, status, who, note, supersededBy string) (Spec, error) {
+	hash, err := CurrentHash(missionDir, id)
+	if err != nil {
+		return Spec{}, err
+	}
+	records, err := Load(missionDir)
+	if err != nil {
+		return Spec{}, err
+	}
+	r := records[id]
+	if r.Status == "" {
+		r.Status = StatusDraft
+	}
+	if !allowed(r.Status, status) {
+		return newSpec(id, r, hash), fmt.Errorf("%w: %s is %s, cannot move to %s", ErrTransition, id, r.Status, status)
+	}
+	if status == StatusReview && r.Status == StatusApproved && r.Hash == hash {
+		return newSpec(id, r, hash), fmt.Errorf("%w: %s is unchanged since version %d", ErrTransition, id, r.Version)
+	}
+	if status == StatusApproved && r.ReviewHash != hash {
+		return newSpec(id, r, hash), fmt.Errorf("%w: %s changed since it was submitted for review; submit it again", ErrTransition, id)
+	}
+	if supersededBy != "" {
+		if _, err := CurrentHash(missionDir, supersededBy); err != nil {
+			return Spec{}, err
+		}
+	}
+
+	now := time.Now().UTC()
+	r.Status, r.Note, r.UpdatedAt = status, note, now
+	switch status {
+	case StatusReview:
+		r.ReviewHash = hash
+	case StatusDraft:
+		r.ReviewHash = ""
+	case StatusApproved:
+		r.Version++
+		r.Hash, r.ApprovedBy, r.ApprovedAt = hash, who, &now
+	case StatusSuperseded:
+		r.SupersededBy = supersededBy
+	}
+	records[id] = r
+	return newSpec(id, r, hash), save(missionDir, records)
+}
+
+func allowed(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func save(missionDir string, records map[string]Record) error {
+	dir := filepath.Join(missionDir, "state")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{"specs": records}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, StateFile+".tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, StateFile))
+}
@@ -0,0 +1,96 @@
+package specs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSpec(t *testing.T, missionDir, id, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(missionDir, "specs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(missionDir, id), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	writeSpec(t, dir, "auth", "# Auth\n\nv1\n")
+
+	s, err := Get(dir, "auth")
+	if err != nil || s.Status != StatusDraft || s.Version != 0 {
+		t.Fatalf("new spec = %+v, %v", s, err)
+	}
+	if _, err := Transition(dir, "auth", StatusApproved, "alice", "", ""); !errors.Is(err, ErrTransition) {
+		t.Fatalf("draft → approved: err = %v, want ErrTransition", err)
+	}
+	if s, err = Transition(dir, "auth", StatusReview, "bob", "", ""); err != nil || s.ReviewHash != s.CurrentHash {
+		t.Fatalf("in review = %+v, %v", s, err)
+	}
+
+	// Only the content submitted for review can be approved
+	writeSpec(t, dir, "auth", "# Auth\n\nv1, edited in review\n")
+	if _, err := Transition(dir, "auth", StatusApproved, "alice", "", ""); !errors.Is(err, ErrTransition) {
+		t.Fatalf("approving an edit made during review: err = %v, want ErrTransition", err)
+	}
+	writeSpec(t, dir, "auth", "# Auth\n\nv1\n")
+	if _, err := Transition(dir, "auth", StatusReview, "bob", "", ""); err != nil {
+		t.Fatalf("resubmitting: %v", err)
+	}
+	s, err = Transition(dir, "auth", StatusApproved, "alice", "LGTM", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != 1 || s.Hash != s.CurrentHash || s.ApprovedBy != "alice" || s.Modified {
+		t.Fatalf("approved = %+v", s)
+	}
+	if _, err := Transition(dir, "auth", StatusReview, "bob", "", ""); !errors.Is(err, ErrTransition) {
+		t.Errorf("resubmitting an unchanged spec: err = %v", err)
+	}
+
+	// Editing an approved spec leaves it approved but modified
+	writeSpec(t, dir, "auth", "# Auth\n\nv2\n")
+	if s, _ = Get(dir, "auth"); s.Status != StatusApproved || !s.Modified {
+		t.Fatalf("edited = %+v", s)
+	}
+	if !Changed(dir, "auth", s.Hash) {
+		t.Error("Changed = false for the v1 hash")
+	}
+	Transition(dir, "auth", StatusReview, "bob", "", "")
+	if s, _ = Transition(dir, "auth", StatusApproved, "alice", "", ""); s.Version != 2 || s.Modified {
+		t.Fatalf("v2 = %+v", s)
+	}
+
+	writeSpec(t, dir, "auth-v2", "# Auth v2\n")
+	if _, err := Transition(dir, "auth", StatusSuperseded, "alice", "", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("superseded by a missing spec: err = %v", err)
+	}
+	if s, err = Transition(dir, "auth", StatusSuperseded, "alice", "", "auth-v2"); err != nil || s.SupersededBy != "auth-v2" {
+		t.Fatalf("superseded = %+v, %v", s, err)
+	}
+	if _, err := Transition(dir, "auth", StatusReview, "bob", "", ""); !errors.Is(err, ErrTransition) {
+		t.Errorf("superseded is final: err = %v", err)
+	}
+
+	list, err := List(dir)
+	if err != nil || len(list) != 2 || list[0].ID != "auth-v2" || list[0].Status != StatusDraft || list[1].Status != StatusSuperseded {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+}
+
+func TestMissingSpec(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Get(dir, "nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get: err = %v", err)
+	}
+	if _, err := Get(dir, "../x"); err == nil {
+		t.Error("Get accepted a path")
+	}
+	if !Changed(dir, "nope", "abc") {
+		t.Error("a removed spec should count as changed")
+	}
+}
@@ -0,0 +1,227 @@
+// Package spend enforces organization-wide token spend limits, set under
+// "spendLimits" in ~/.mission-control/config.json:
+//
+//	"spendLimits": {"dailyUSD": 50, "weeklyUSD": 200, "dailyTokens": 0, "weeklyTokens": 0}
+//
+// Zero or absent means no limit. The orchestrator records the token usage
+// of every project it runs in ~/.mission-control/spend.json, by UTC day.
+// Once today's usage or the last seven days' reaches a limit the breaker
+// trips: no new workers are spawned and messages are not forwarded to the
+// King until the day rolls over, a limit is raised, or someone sets an
+// override.
+package spend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LedgerFile holds the recorded usage and any override, in the
+// ~/.mission-control directory.
+const LedgerFile = "spend.json"
+
+// keepDays is how many days of usage the ledger keeps.
+const keepDays = 14
+
+// ErrLimitExceeded is returned by Check while the breaker is tripped.
+var ErrLimitExceeded = errors.New("spend limit exceeded")
+
+// Limits is the "spendLimits" section of the global config.json.
+type Limits struct {
+	DailyUSD     float64 `json:"dailyUSD,omitempty"`
+	WeeklyUSD    float64 `json:"weeklyUSD,omitempty"`
+	DailyTokens  int     `json:"dailyTokens,omitempty"`
+	WeeklyTokens int     `json:"weeklyTokens,omitempty"`
+}
+
+// Usage is tokens spent and what they cost.
+type Usage struct {
+	Tokens  int     `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// Override lets spending continue past the limits until Until.
+type Override struct {
+	By     string `json:"by"`
+	Reason string `json:"reason"`
+	At     string `json:"at"`
+	Until  string `json:"until"`
+}
+
+// Status is the breaker's state. Exceeded names the limits reached, e.g.
+// "daily_usd"; the breaker is Tripped when some are and no override is
+// in force.
+type Status struct {
+	Limits   Limits    `json:"limits"`
+	Today    Usage     `json:"today"`
+	Week     Usage     `json:"week"` // the last seven days, today included
+	Exceeded []string  `json:"exceeded,omitempty"`
+	Tripped  bool      `json:"tripped"`
+	Override *Override `json:"override,omitempty"`
+}
+
+type ledger struct {
+	Days     map[string]Usage `json:"days"` // by UTC date
+	Override *Override        `json:"override,omitempty"`
+}
+
+// mu serialises read-modify-write cycles on the ledger within a process.
+var mu sync.Mutex
+
+// DefaultDir is ~/.mission-control.
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".mission-control")
+}
+
+// LoadLimits reads the spend limits from dir's config.json.
+func LoadLimits(dir string) Limits {
+	var cfg struct {
+		SpendLimits Limits `json:"spendLimits"`
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	return cfg.SpendLimits
+}
+
+// Current returns the breaker's state at now.
+func Current(dir string, now time.Time) Status {
+	mu.Lock()
+	defer mu.Unlock()
+	return status(LoadLimits(dir), load(dir), now)
+}
+
+// Check returns an error wrapping ErrLimitExceeded if the breaker is
+// tripped at now.
+func Check(dir string, now time.Time) error {
+	st := Current(dir, now)
+	if !st.Tripped {
+		return nil
+	}
+	return fmt.Errorf("%w (%s): spend today $%.2f, %d tokens; last 7 days $%.2f, %d tokens. Raise spendLimits in %s or override with mc spend override",
+		ErrLimitExceeded, strings.Join(st.Exceeded, ", "), st.Today.CostUSD, st.Today.Tokens, st.Week.CostUSD, st.Week.Tokens,
+		filepath.Join(dir, "config.json"))
+}
+
+// Record adds tokens and their cost to today's usage. tripped reports
+// whether this usage tripped the breaker.
+func Record(dir string, tokens int, cost float64, now time.Time) (st Status, tripped bool, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	limits := LoadLimits(dir)
+	l := load(dir)
+	before := status(limits, l, now)
+
+	day := now.UTC().Format("2006-01-02")
+	u := l.Days[day]
+	u.Tokens += tokens
+	u.CostUSD += cost
+	l.Days[day] = u
+	cutoff := now.UTC().AddDate(0, 0, -keepDays).Format("2006-01-02")
+	for d := range l.Days {
+		if d < cutoff {
+			delete(l.Days, d)
+		}
+	}
+
+	st = status(limits, l, now)
+	return st, st.Tripped && !before.Tripped, save(dir, l)
+}
+
+// SetOverride lets spending continue past the limits until until.
+func SetOverride(dir, by, reason string, until, now time.Time) (Status, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	l := load(dir)
+	l.Override = &Override{
+		By:     by,
+		Reason: reason,
+		At:     now.UTC().Format(time.RFC3339),
+		Until:  until.UTC().Format(time.RFC3339),
+	}
+	return status(LoadLimits(dir), l, now), save(dir, l)
+}
+
+// ClearOverride removes the override, if any.
+func ClearOverride(dir string, now time.Time) (Status, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	l := load(dir)
+	l.Override = nil
+	return status(LoadLimits(dir), l, now), save(dir, l)
+}
+
+// EndOfDay is when now's UTC day ends, the default end of an override.
+func EndOfDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+func status(limits Limits, l ledger, now time.Time) Status {
+	st := Status{Limits: limits}
+	today := now.UTC().Format("2006-01-02")
+	weekStart := now.UTC().AddDate(0, 0, -6).Format("2006-01-02")
+	for day, u := range l.Days {
+		if day == today {
+			st.Today = u
+		}
+		if day >= weekStart && day <= today {
+			st.Week.Tokens += u.Tokens
+			st.Week.CostUSD += u.CostUSD
+		}
+	}
+
+	for name, over := range map[string]bool{
+		"daily_usd":     limits.DailyUSD > 0 && st.Today.CostUSD >= limits.DailyUSD,
+		"weekly_usd":    limits.WeeklyUSD > 0 && st.Week.CostUSD >= limits.WeeklyUSD,
+		"daily_tokens":  limits.DailyTokens > 0 && st.Today.Tokens >= limits.DailyTokens,
+		"weekly_tokens": limits.WeeklyTokens > 0 && st.Week.Tokens >= limits.WeeklyTokens,
+	} {
+		if over {
+			st.Exceeded = append(st.Exceeded, name)
+		}
+	}
+	sort.Strings(st.Exceeded)
+
+	if o := l.Override; o != nil {
+		if until, err := time.Parse(time.RFC3339, o.Until); err == nil && now.Before(until) {
+			st.Override = o
+		}
+	}
+	st.Tripped = len(st.Exceeded) > 0 && st.Override == nil
+	return st
+}
+
+func load(dir string) ledger {
+	var l ledger
+	if data, err := os.ReadFile(filepath.Join(dir, LedgerFile)); err == nil {
+		_ = json.Unmarshal(data, &l)
+	}
+	if l.Days == nil {
+		l.Days = map[string]Usage{}
+	}
+	return l
+}
+
+func save(dir string, l ledger) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, LedgerFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
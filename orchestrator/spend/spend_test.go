@@ -0,0 +1,68 @@
+package spend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordTripsBreaker(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"projects":[],"spendLimits":{"dailyUSD":10,"weeklyTokens":1000}}`), 0644)
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	if _, tripped, err := Record(dir, 100, 4, now.AddDate(0, 0, -3)); err != nil || tripped {
+		t.Fatalf("expected three days ago to stay under the limits, got tripped=%v err=%v", tripped, err)
+	}
+	st, tripped, _ := Record(dir, 100, 5, now)
+	if tripped || st.Today.CostUSD != 5 || st.Week.Tokens != 200 {
+		t.Fatalf("unexpected status under the limits: %+v", st)
+	}
+	if err := Check(dir, now); err != nil {
+		t.Fatalf("expected no error under the limits, got %v", err)
+	}
+
+	st, tripped, _ = Record(dir, 50, 5, now)
+	if !tripped || !st.Tripped || len(st.Exceeded) != 1 || st.Exceeded[0] != "daily_usd" {
+		t.Fatalf("expected the daily limit to trip the breaker, got tripped=%v %+v", tripped, st)
+	}
+	if _, tripped, _ = Record(dir, 1, 0, now); tripped {
+		t.Error("expected the breaker to report tripping only once")
+	}
+	if err := Check(dir, now); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	// The next day only the weekly token limit counts
+	if st := Current(dir, now.AddDate(0, 0, 1)); st.Tripped {
+		t.Errorf("expected the breaker to reset with the day, got %+v", st)
+	}
+	Record(dir, 800, 0, now.AddDate(0, 0, 1))
+	if st := Current(dir, now.AddDate(0, 0, 1)); !st.Tripped || st.Exceeded[0] != "weekly_tokens" {
+		t.Errorf("expected the weekly token limit to trip, got %+v", st)
+	}
+}
+
+func TestOverrideLiftsBreaker(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"spendLimits":{"dailyTokens":10}}`), 0644)
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	Record(dir, 10, 0, now)
+
+	st, err := SetOverride(dir, "Alice", "release day", EndOfDay(now), now)
+	if err != nil || st.Tripped || st.Override == nil || st.Override.Until != "2026-03-05T00:00:00Z" {
+		t.Fatalf("expected the override to lift the breaker until midnight, got %+v %v", st, err)
+	}
+	if err := Check(dir, now); err != nil {
+		t.Errorf("expected no error under the override, got %v", err)
+	}
+	if st := Current(dir, now.Add(13*time.Hour)); st.Override != nil {
+		t.Errorf("expected the override to expire, got %+v", st)
+	}
+
+	if st, _ := ClearOverride(dir, now); !st.Tripped {
+		t.Errorf("expected the breaker tripped again after clearing, got %+v", st)
+	}
+}
@@ -0,0 +1,268 @@
+// Package stagesla warns when a stage runs past its expected duration,
+// configured under "stage_sla" in .mission/config.json as Go durations:
+//
+//	"stage_sla": {"design": "24h", "implement": "72h"}
+//
+// A Monitor checks the current stage every TickInterval. Its elapsed time
+// runs from the audit entry that moved the mission into it (stage_advanced,
+// stage_set or stage_rolled_back), or from stage.json's updated_at when the
+// active audit log has none. Once that exceeds the stage's SLA, the
+// monitor reports a stage_overrun through its callback and the
+// stage_overrun webhook, once per visit to the stage. The last overrun of
+// each stage is kept in .mission/state/stage_overruns.json, so a restart
+// doesn't report a visit again, and in memory, so a failure to write that
+// file doesn't either. The config is re-read on every tick, and
+// each invalid duration in it is logged once.
+package stagesla
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/audit"
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/logonce"
+	"github.com/MikeSquared-Agency/MissionControl/webhook"
+)
+
+// TickInterval is how often a Monitor checks the current stage.
+const TickInterval = time.Minute
+
+// File holds the reported overruns, in the .mission/state directory.
+const File = "stage_overruns.json"
+
+// Overrun is a stage that has run past its SLA.
+type Overrun struct {
+	Stage      string `json:"stage"`
+	SLA        string `json:"sla"`
+	EnteredAt  string `json:"entered_at"`
+	ElapsedMin int    `json:"elapsed_min"`
+}
+
+// Monitor watches one project's current stage against its stage_sla
+// policy.
+type Monitor struct {
+	projectDir string
+	onOverrun  func(Overrun)
+	done       chan struct{}
+	stopOnce   sync.Once
+
+	reported map[string]string // stage -> EnteredAt of its last reported overrun
+	warn     *logonce.Logger   // logs each invalid config entry once
+}
+
+// Start starts a Monitor for projectDir. onOverrun, if set, is called for
+// each overrun.
+func Start(projectDir string, onOverrun func(Overrun)) *Monitor {
+	m := newMonitor(projectDir, onOverrun)
+	go m.run(TickInterval)
+	return m
+}
+
+func newMonitor(projectDir string, onOverrun func(Overrun)) *Monitor {
+	return &Monitor{
+		projectDir: projectDir,
+		onOverrun:  onOverrun,
+		done:       make(chan struct{}),
+		reported:   map[string]string{},
+		warn:       logonce.New("stagesla: "),
+	}
+}
+
+// Stop stops the monitor.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.done) })
+}
+
+func (m *Monitor) missionDir() string {
+	return filepath.Join(m.projectDir, ".mission")
+}
+
+func (m *Monitor) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case now := <-ticker.C:
+			m.tick(now)
+		}
+	}
+}
+
+// tick reports the current stage if it has run past its SLA.
+func (m *Monitor) tick(now time.Time) {
+	slas := loadSLAs(m.missionDir(), m.warn)
+	if len(slas) == 0 {
+		return
+	}
+	stage := currentStage(m.missionDir())
+	sla, ok := slas[stage]
+	if !ok {
+		return
+	}
+	entered, ok := EnteredAt(m.missionDir(), stage)
+	if !ok {
+		return
+	}
+	elapsed := now.Sub(entered)
+	if elapsed <= sla {
+		return
+	}
+	overruns := Load(m.missionDir())
+	o := Overrun{
+		Stage:      stage,
+		SLA:        sla.String(),
+		EnteredAt:  entered.UTC().Format(time.RFC3339),
+		ElapsedMin: int(elapsed.Minutes()),
+	}
+	if m.reported[stage] == o.EnteredAt || overruns[stage].EnteredAt == o.EnteredAt {
+		return
+	}
+	m.reported[stage] = o.EnteredAt
+	overruns[stage] = o
+	if err := save(m.missionDir(), overruns); err != nil {
+		log.Printf("stagesla: saving %s: %v", File, err)
+	}
+	log.Printf("stagesla: %s has run %d minutes, over its %s SLA", o.Stage, o.ElapsedMin, o.SLA)
+	m.notify(o)
+	if m.onOverrun != nil {
+		m.onOverrun(o)
+	}
+}
+
+// notify sends the stage_overrun webhook. Delivery is best effort.
+func (m *Monitor) notify(o Overrun) {
+	cfg, err := bridge.LoadProjectConfig(m.projectDir)
+	if err != nil || len(cfg.Webhooks) == 0 {
+		return
+	}
+	if err := webhook.Send(context.Background(), cfg.Webhooks, webhook.EventStageOverrun, o); err != nil {
+		log.Printf("stagesla: stage_overrun webhook: %v", err)
+	}
+}
+
+// LoadSLAs reads the stage_sla policy from config.json. Durations that
+// don't parse, or aren't positive, are ignored.
+func LoadSLAs(missionDir string) map[string]time.Duration {
+	return loadSLAs(missionDir, nil)
+}
+
+// loadSLAs is LoadSLAs, also logging each duration it ignored to warn.
+func loadSLAs(missionDir string, warn *logonce.Logger) map[string]time.Duration {
+	var cfg struct {
+		StageSLA map[string]string `json:"stage_sla"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "config.json")); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	stages := make([]string, 0, len(cfg.StageSLA))
+	for stage := range cfg.StageSLA {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	slas := map[string]time.Duration{}
+	for _, stage := range stages {
+		s := cfg.StageSLA[stage]
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			warn.Printf("ignoring stage_sla %s: invalid duration %q", stage, s)
+			continue
+		}
+		slas[stage] = d
+	}
+	return slas
+}
+
+// Load returns the last overrun reported for each stage, by stage.
+func Load(missionDir string) map[string]Overrun {
+	var f struct {
+		Stages map[string]Overrun `json:"stages"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "state", File)); err == nil {
+		_ = json.Unmarshal(data, &f)
+	}
+	if f.Stages == nil {
+		f.Stages = map[string]Overrun{}
+	}
+	return f.Stages
+}
+
+func save(missionDir string, overruns map[string]Overrun) error {
+	data, err := json.MarshalIndent(map[string]interface{}{"stages": overruns}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(missionDir, "state", File)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// EnteredAt returns when the mission last entered stage: the time of the
+// last audit entry moving it there, or stage.json's updated_at.
+func EnteredAt(missionDir, stage string) (time.Time, bool) {
+	var entered time.Time
+	if f, err := os.Open(filepath.Join(missionDir, audit.FileName)); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var e audit.Entry
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue
+			}
+			if t := e.Time(); !t.IsZero() && movedTo(e) == stage {
+				entered = t
+			}
+		}
+		f.Close()
+	}
+	if !entered.IsZero() {
+		return entered, true
+	}
+
+	var state struct {
+		Current   string `json:"current"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	data, err := os.ReadFile(filepath.Join(missionDir, "state", "stage.json"))
+	if err != nil || json.Unmarshal(data, &state) != nil || state.Current != stage {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, state.UpdatedAt)
+	return t, err == nil
+}
+
+// movedTo returns the stage a stage-change audit entry moved to, or "".
+func movedTo(e audit.Entry) string {
+	var key string
+	switch e.Action {
+	case "stage_advanced", "stage_rolled_back":
+		key = "to_stage"
+	case "stage_set":
+		key = "stage"
+	default:
+		return ""
+	}
+	s, _ := e.Details[key].(string)
+	return s
+}
+
+func currentStage(missionDir string) string {
+	var state struct {
+		Current string `json:"current"`
+	}
+	if data, err := os.ReadFile(filepath.Join(missionDir, "state", "stage.json")); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state.Current
+}
@@ -0,0 +1,143 @@
+package stagesla
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTickReportsOverrunOncePerVisit(t *testing.T) {
+	var delivered []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var e struct {
+			Event string  `json:"event"`
+			Data  Overrun `json:"data"`
+		}
+		json.Unmarshal(body, &e)
+		delivered = append(delivered, e.Event+":"+e.Data.Stage)
+	}))
+	defer hook.Close()
+
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	writeFile(t, filepath.Join(mission, "config.json"), `{"stage_sla":{"implement":"2h","design":"bogus"},"webhooks":[{"url":"`+hook.URL+`"}]}`)
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"implement","updated_at":"2026-03-01T11:00:00Z"}`)
+	writeFile(t, filepath.Join(mission, "audit.jsonl"),
+		`{"timestamp":"2026-02-01T00:00:00Z","action":"stage_advanced","details":{"from_stage":"design","to_stage":"implement"}}`+"\n"+
+			`{"timestamp":"2026-02-20T00:00:00Z","action":"stage_rolled_back","details":{"from_stage":"implement","to_stage":"design"}}`+"\n"+
+			`{"timestamp":"2026-03-01T10:00:00Z","action":"stage_advanced","details":{"from_stage":"design","to_stage":"implement"}}`+"\n")
+
+	var got []Overrun
+	m := newMonitor(dir, func(o Overrun) { got = append(got, o) })
+
+	m.tick(time.Date(2026, 3, 1, 11, 59, 0, 0, time.UTC))
+	if len(got) != 0 {
+		t.Fatalf("expected no overrun within the SLA, got %+v", got)
+	}
+
+	m.tick(time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC))
+	if len(got) != 1 {
+		t.Fatalf("expected one overrun, got %+v", got)
+	}
+	if o := got[0]; o.Stage != "implement" || o.SLA != "2h0m0s" || o.EnteredAt != "2026-03-01T10:00:00Z" || o.ElapsedMin != 150 {
+		t.Errorf("unexpected overrun: %+v", o)
+	}
+	if len(delivered) != 1 || delivered[0] != "stage_overrun:implement" {
+		t.Errorf("expected the stage_overrun webhook, got %v", delivered)
+	}
+
+	m.tick(time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC))
+	if len(got) != 1 {
+		t.Errorf("expected the visit to be reported once, got %d", len(got))
+	}
+
+	// A restarted monitor remembers the visit was reported
+	m = newMonitor(dir, func(o Overrun) { got = append(got, o) })
+	m.tick(time.Date(2026, 3, 1, 13, 30, 0, 0, time.UTC))
+	if len(got) != 1 {
+		t.Errorf("expected no report after a restart, got %d", len(got))
+	}
+	if o := Load(mission)["implement"]; o.EnteredAt != "2026-03-01T10:00:00Z" {
+		t.Errorf("persisted overrun = %+v", o)
+	}
+
+	// A new visit to the stage is timed afresh
+	f, _ := os.OpenFile(filepath.Join(mission, "audit.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"timestamp":"2026-03-02T00:00:00Z","action":"stage_set","details":{"stage":"implement"}}` + "\n")
+	f.Close()
+	m.tick(time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC))
+	if len(got) != 2 || got[1].EnteredAt != "2026-03-02T00:00:00Z" {
+		t.Errorf("expected a second overrun for the new visit, got %+v", got)
+	}
+}
+
+func TestTickReportsOnceWhenSaveFails(t *testing.T) {
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	writeFile(t, filepath.Join(mission, "config.json"), `{"stage_sla":{"implement":"2h"}}`)
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"implement","updated_at":"2026-03-01T10:00:00Z"}`)
+	// A directory in the way of the temp file makes every save fail
+	if err := os.MkdirAll(filepath.Join(mission, "state", File+".tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Overrun
+	m := newMonitor(dir, func(o Overrun) { got = append(got, o) })
+	for i := 0; i < 3; i++ {
+		m.tick(time.Date(2026, 3, 1, 13, i, 0, 0, time.UTC))
+	}
+	if len(got) != 1 {
+		t.Errorf("expected one report despite the failed save, got %d", len(got))
+	}
+}
+
+func TestTickLogsInvalidSLAOnce(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	dir := t.TempDir()
+	mission := filepath.Join(dir, ".mission")
+	writeFile(t, filepath.Join(mission, "config.json"), `{"stage_sla":{"design":"bogus"}}`)
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"design","updated_at":"2026-03-01T11:00:00Z"}`)
+
+	m := newMonitor(dir, nil)
+	for i := 0; i < 3; i++ {
+		m.tick(time.Date(2026, 3, 1, 12, i, 0, 0, time.UTC))
+	}
+	if n := strings.Count(logs.String(), `invalid duration "bogus"`); n != 1 {
+		t.Errorf("invalid duration logged %d times, want once:\n%s", n, logs.String())
+	}
+}
+
+func TestEnteredAtFallsBackToStageState(t *testing.T) {
+	mission := t.TempDir()
+	writeFile(t, filepath.Join(mission, "state", "stage.json"), `{"current":"discovery","updated_at":"2026-03-01T09:00:00Z"}`)
+
+	entered, ok := EnteredAt(mission, "discovery")
+	if !ok || !entered.Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected stage.json's updated_at, got %v %v", entered, ok)
+	}
+	if _, ok := EnteredAt(mission, "design"); ok {
+		t.Error("expected no entry time for a stage the mission isn't in")
+	}
+}
@@ -1,7 +1,11 @@
 package tokens
 
 import (
+	"strings"
 	"sync"
+
+	"github.com/MikeSquared-Agency/MissionControl/core"
+	"github.com/MikeSquared-Agency/MissionControl/pricing"
 )
 
 type ModelTier string
@@ -12,18 +16,6 @@ const (
 	ModelHaiku  ModelTier = "haiku"
 )
 
-// Cost per million tokens (input/output) in USD
-type ModelCost struct {
-	InputPerMTok  float64
-	OutputPerMTok float64
-}
-
-var ModelCosts = map[ModelTier]ModelCost{
-	ModelOpus:   {15.0, 75.0},
-	ModelSonnet: {3.0, 15.0},
-	ModelHaiku:  {0.25, 1.25},
-}
-
 // Default model for each persona
 var PersonaModels = map[string]ModelTier{
 	"king":         ModelOpus,
@@ -68,6 +60,7 @@ type Accumulator struct {
 	total    SessionTokens
 	budget   int // 0 = no budget
 	callback BudgetWarningCallback
+	onRecord func(tokens int, cost float64)
 	mu       sync.RWMutex
 }
 
@@ -79,10 +72,19 @@ func NewAccumulator(budget int, callback BudgetWarningCallback) *Accumulator {
 	}
 }
 
-func (a *Accumulator) Record(workerID, persona string, model ModelTier, inputTokens, outputTokens int) {
+// SetOnRecord sets fn to be called with the tokens and cost of every
+// recorded usage, e.g. to charge them against the global spend limits.
+func (a *Accumulator) SetOnRecord(fn func(tokens int, cost float64)) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	a.onRecord = fn
+}
 
+// Record adds the usage to workerID's session and the total. The
+// OnRecord hook and budget warnings run after the lock is released, so
+// the file I/O they do doesn't hold up readers or other workers.
+func (a *Accumulator) Record(workerID, persona string, model ModelTier, inputTokens, outputTokens int) {
+	a.mu.Lock()
 	cost := EstimateCost(model, inputTokens, outputTokens)
 
 	sess, ok := a.sessions[workerID]
@@ -105,26 +107,34 @@ func (a *Accumulator) Record(workerID, persona string, model ModelTier, inputTok
 	a.total.TotalTokens = a.total.InputTokens + a.total.OutputTokens
 	a.total.EstimatedCost += cost
 
+	onRecord, callback, limit, used := a.onRecord, a.callback, a.budget, a.total.TotalTokens
+	a.mu.Unlock()
+
+	if onRecord != nil {
+		onRecord(inputTokens+outputTokens, cost)
+	}
+
 	// Budget warnings
-	if a.budget > 0 && a.callback != nil {
-		used := a.total.TotalTokens
-		remaining := a.budget - used
+	if limit > 0 && callback != nil {
+		remaining := limit - used
 		if remaining < 0 {
 			remaining = 0
 		}
-		threshold80 := int(float64(a.budget) * 0.8)
+		threshold80 := int(float64(limit) * 0.8)
 		prev := used - inputTokens - outputTokens
 		if prev < threshold80 && used >= threshold80 {
-			a.callback(workerID, a.budget, used, remaining)
+			callback(workerID, limit, used, remaining)
 		}
-		if prev < a.budget && used >= a.budget {
-			a.callback(workerID, a.budget, used, remaining)
+		if prev < limit && used >= limit {
+			callback(workerID, limit, used, remaining)
 		}
 	}
 }
 
+// RecordText records text as input tokens, counted with the tokenizer of
+// the worker's model.
 func (a *Accumulator) RecordText(workerID, persona string, model ModelTier, text string) {
-	tokens := len(text) / 4
+	tokens := core.CountTokensFor(text, string(model))
 	a.Record(workerID, persona, model, tokens, 0)
 }
 
@@ -178,11 +188,22 @@ func ModelForPersona(persona string) ModelTier {
 	return ModelSonnet // default
 }
 
-func EstimateCost(model ModelTier, inputTokens, outputTokens int) float64 {
-	cost, ok := ModelCosts[model]
-	if !ok {
-		return 0
+// PricedModel returns the model to price a worker's usage with: model if
+// the pricing table has it, or persona's default tier otherwise, so an
+// unknown model ID is charged rather than counted free. Ollama models
+// ("ollama:<name>") are kept as they are and cost nothing.
+func PricedModel(model, persona string) ModelTier {
+	if strings.HasPrefix(strings.ToLower(model), "ollama:") {
+		return ModelTier(model)
 	}
-	return (float64(inputTokens)/1_000_000)*cost.InputPerMTok +
-		(float64(outputTokens)/1_000_000)*cost.OutputPerMTok
+	if _, ok := pricing.Current().Lookup(model); ok {
+		return ModelTier(model)
+	}
+	return ModelForPersona(persona)
+}
+
+// EstimateCost prices token counts for model from the pricing table.
+// Models without a price, such as offline ones, cost nothing.
+func EstimateCost(model ModelTier, inputTokens, outputTokens int) float64 {
+	return pricing.Current().Cost(string(model), inputTokens, outputTokens, 0)
 }
@@ -4,6 +4,8 @@ import (
 	"math"
 	"sync"
 	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/core"
 )
 
 func TestRecordUpdatesSessionAndTotal(t *testing.T) {
@@ -155,14 +157,28 @@ func TestMultipleSessionsAccumulate(t *testing.T) {
 
 func TestRecordText(t *testing.T) {
 	acc := NewAccumulator(0, nil)
-	text := "hello world!!!!!" // 16 chars -> 4 tokens
+	text := "hello world!!!!!" // 16 chars -> 4 tokens estimated
 	acc.RecordText("w1", "developer", ModelSonnet, text)
 
 	sess, ok := acc.GetSession("w1")
 	if !ok {
 		t.Fatal("expected session")
 	}
-	if sess.InputTokens != 4 {
-		t.Errorf("expected 4 input tokens, got %d", sess.InputTokens)
+	if want := core.CountTokensFor(text, "sonnet"); sess.InputTokens != want {
+		t.Errorf("expected %d input tokens, got %d", want, sess.InputTokens)
+	}
+}
+
+// The hook runs outside the lock, so it can read the accumulator and a
+// slow ledger write doesn't block Summary.
+func TestOnRecordRunsUnlocked(t *testing.T) {
+	acc := NewAccumulator(0, nil)
+	var seen int
+	acc.SetOnRecord(func(tokens int, cost float64) {
+		seen = acc.Summary().TotalTokens
+	})
+	acc.Record("w1", "developer", ModelSonnet, 100, 50)
+	if seen != 150 {
+		t.Errorf("hook saw %d tokens, want 150", seen)
 	}
 }
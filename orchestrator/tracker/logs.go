@@ -1,8 +1,14 @@
 package tracker
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/recording"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
 )
 
 // LogLine represents a single line of output from a worker process.
@@ -13,6 +19,7 @@ type LogLine struct {
 }
 
 // LogBuffer is a bounded ring buffer of log lines for a single worker.
+// It keeps lines as given: LogStore redacts them before they get here.
 type LogBuffer struct {
 	lines    []LogLine
 	maxLines int
@@ -68,3 +75,184 @@ func (b *LogBuffer) Clear() {
 	defer b.mu.Unlock()
 	b.lines = b.lines[:0]
 }
+
+// DefaultPersistBytes is how much of a worker's log is kept on disk.
+const DefaultPersistBytes = 64 * 1024
+
+// LogStore keeps a LogBuffer per worker, lets readers follow new lines,
+// and writes the tail of a finished worker's log to dir/<worker-id>.log.
+type LogStore struct {
+	dir          string
+	maxLines     int
+	persistBytes int
+
+	mu        sync.Mutex
+	buffers   map[string]*LogBuffer
+	followers map[string]map[chan LogLine]struct{}
+	recorder  *recording.Recorder // nil = output not recorded
+}
+
+// NewLogStore creates a store persisting to dir (e.g. .mission/logs),
+// keeping maxLines per worker in memory (0 = LogBuffer's default).
+func NewLogStore(dir string, maxLines int) *LogStore {
+	return &LogStore{
+		dir:          dir,
+		maxLines:     maxLines,
+		persistBytes: DefaultPersistBytes,
+		buffers:      make(map[string]*LogBuffer),
+		followers:    make(map[string]map[chan LogLine]struct{}),
+	}
+}
+
+// Dir returns where logs are persisted.
+func (s *LogStore) Dir() string {
+	return s.dir
+}
+
+// SetRecorder also records every worker's output with rec, as a
+// replayable asciicast.
+func (s *LogStore) SetRecorder(rec *recording.Recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = rec
+}
+
+// Recorder returns the recorder set by SetRecorder, or nil.
+func (s *LogStore) Recorder() *recording.Recorder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recorder
+}
+
+// Append adds a line of output from a worker. Multi-line content is split.
+// Secret values are masked before the output is buffered, recorded,
+// followed or persisted.
+func (s *LogStore) Append(workerID, stream, content string) {
+	now := time.Now()
+	content = secrets.Redact(content)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf, ok := s.buffers[workerID]
+	if !ok {
+		buf = NewLogBuffer(s.maxLines)
+		s.buffers[workerID] = buf
+	}
+	for _, text := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		line := LogLine{Timestamp: now, Content: text, Stream: stream}
+		buf.Append(line)
+		s.recorder.Write(workerID, stream, text)
+		for ch := range s.followers[workerID] {
+			select {
+			case ch <- line:
+			default:
+				// A reader that falls this far behind misses lines
+			}
+		}
+	}
+}
+
+// Lines returns a worker's buffered lines, and whether it has any.
+func (s *LogStore) Lines(workerID string) ([]LogLine, bool) {
+	s.mu.Lock()
+	buf, ok := s.buffers[workerID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return buf.Lines(), true
+}
+
+// Follow returns the lines buffered so far and a channel of the lines
+// that follow. The channel is closed when the worker finishes; call stop
+// to unsubscribe earlier.
+func (s *LogStore) Follow(workerID string) (backlog []LogLine, lines <-chan LogLine, stop func()) {
+	ch := make(chan LogLine, 256)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if buf, ok := s.buffers[workerID]; ok {
+		backlog = buf.Lines()
+	}
+	if s.followers[workerID] == nil {
+		s.followers[workerID] = make(map[chan LogLine]struct{})
+	}
+	s.followers[workerID][ch] = struct{}{}
+	return backlog, ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.followers[workerID][ch]; ok {
+			delete(s.followers[workerID], ch)
+			close(ch)
+		}
+	}
+}
+
+// Finish persists a worker's log, ends its followers and frees its buffer.
+func (s *LogStore) Finish(workerID string) error {
+	s.mu.Lock()
+	buf, ok := s.buffers[workerID]
+	delete(s.buffers, workerID)
+	for ch := range s.followers[workerID] {
+		close(ch)
+	}
+	delete(s.followers, workerID)
+	s.recorder.Finish(workerID)
+	s.mu.Unlock()
+	if !ok || s.dir == "" {
+		return nil
+	}
+	return s.persist(workerID, buf.Lines())
+}
+
+// persist writes the last persistBytes of lines to dir/<worker-id>.log.
+func (s *LogStore) persist(workerID string, lines []LogLine) error {
+	var out []byte
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i].Content + "\n"
+		if len(out)+len(line) > s.persistBytes {
+			break
+		}
+		out = append([]byte(line), out...)
+	}
+	if err := EnsureLogDir(s.dir); err != nil {
+		return err
+	}
+	return os.WriteFile(LogPath(s.dir, workerID), out, 0644)
+}
+
+// LogPath is where a worker's log is persisted in dir.
+func LogPath(dir, workerID string) string {
+	return filepath.Join(dir, filepath.Base(workerID)+".log")
+}
+
+// EnsureLogDir creates dir with a .gitignore, so auto-commits of
+// .mission/ leave worker logs out.
+func EnsureLogDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		return os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	return nil
+}
+
+// TrimLog cuts the log at path down to its last max bytes, starting at a
+// line boundary. Missing files are left alone.
+func TrimLog(path string, max int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(data) <= max {
+		return nil
+	}
+	data = data[len(data)-max:]
+	if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/tokens"
 )
 
 // ProcessStatus represents the lifecycle state of a tracked worker.
@@ -18,8 +21,20 @@ const (
 	StatusComplete ProcessStatus = "complete"
 	StatusError    ProcessStatus = "error"
 	StatusKilled   ProcessStatus = "killed"
+	StatusStale    ProcessStatus = "stale" // no heartbeat within Staleness.After
 )
 
+// Staleness says when a worker that stops sending heartbeats is marked
+// stale, and how long after that it is reaped. Local workers are kept
+// fresh by their PID; gateway workers by heartbeats and gateway events.
+type Staleness struct {
+	After time.Duration // zero disables stale detection
+	Grace time.Duration // from stale to reaped
+}
+
+// DefaultStaleness is used unless SetStaleness says otherwise.
+var DefaultStaleness = Staleness{After: 2 * time.Minute, Grace: 5 * time.Minute}
+
 // TrackedProcess holds runtime state for a single worker process.
 type TrackedProcess struct {
 	WorkerID   string        `json:"worker_id"`
@@ -32,6 +47,7 @@ type TrackedProcess struct {
 	StartedAt  time.Time     `json:"started_at"`
 	TokenCount int           `json:"token_count"`
 	CostUSD    float64       `json:"cost_usd"`
+	LastSeen   time.Time     `json:"last_seen"`
 }
 
 // EventCallback is invoked when process state changes.
@@ -44,6 +60,12 @@ type Tracker struct {
 	missionDir string
 	callback   EventCallback
 	stopCh     chan struct{}
+
+	staleness Staleness
+	reaped    map[string]bool // not rediscovered from workers.json
+	onReap    []func(*TrackedProcess)
+
+	logs *LogStore // worker output, persisted to .mission/logs
 }
 
 // workerEntry mirrors the JSON shape inside workers.json.
@@ -64,7 +86,56 @@ func NewTracker(missionDir string, callback EventCallback) *Tracker {
 		missionDir: missionDir,
 		callback:   callback,
 		stopCh:     make(chan struct{}),
+		staleness:  DefaultStaleness,
+		reaped:     make(map[string]bool),
+		logs:       NewLogStore(filepath.Join(missionDir, ".mission", "logs"), 0),
+	}
+}
+
+// Logs returns the workers' output store.
+func (t *Tracker) Logs() *LogStore {
+	return t.logs
+}
+
+// finished reports whether status is final; the worker's log is then
+// persisted.
+func finished(status ProcessStatus) bool {
+	return status == StatusComplete || status == StatusError || status == StatusKilled
+}
+
+// SetStaleness changes when silent workers are marked stale and reaped.
+func (t *Tracker) SetStaleness(s Staleness) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.staleness = s
+}
+
+// OnReap adds fn to the functions called with each reaped worker, e.g. to
+// drop it from a gateway's registry.
+func (t *Tracker) OnReap(fn func(*TrackedProcess)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onReap = append(t.onReap, fn)
+}
+
+// Touch records a heartbeat from a worker; a stale worker is running
+// again. It reports whether the worker is tracked.
+func (t *Tracker) Touch(workerID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.processes[workerID]
+	if !ok {
+		return false
+	}
+	p.LastSeen = time.Now()
+	if p.Status == StatusStale {
+		p.Status = StatusRunning
+		if t.callback != nil {
+			cp := *p
+			t.callback("status_changed", &cp)
+		}
 	}
+	return true
 }
 
 // Start begins background polling. Call Stop to terminate.
@@ -118,9 +189,10 @@ func (t *Tracker) Kill(workerID string) error {
 		return fmt.Errorf("find process %d: %w", pid, err)
 	}
 
-	// SIGTERM
+	// SIGTERM; Windows has no signals, so terminate it there
 	if err := proc.Signal(syscall.SIGTERM); err != nil {
 		// Process may already be dead — still mark killed.
+		_ = proc.Kill()
 		t.setStatus(workerID, StatusKilled)
 		return nil
 	}
@@ -157,6 +229,7 @@ func (t *Tracker) Reset() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.processes = make(map[string]*TrackedProcess)
+	t.reaped = make(map[string]bool)
 }
 
 // UpdateTokens updates the token count and cost for a worker.
@@ -169,6 +242,22 @@ func (t *Tracker) UpdateTokens(workerID string, tokens int, cost float64) {
 	}
 }
 
+// UpdateUsage sets a worker's token count and prices its input and output
+// tokens with the pricing table for the worker's model, or its persona's
+// default model when none was recorded or the table doesn't know it. It
+// returns the cost.
+func (t *Tracker) UpdateUsage(workerID string, total, input, output int) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.processes[workerID]
+	if !ok {
+		return 0
+	}
+	p.TokenCount = total
+	p.CostUSD = tokens.EstimateCost(tokens.PricedModel(p.Model, p.Persona), input, output)
+	return p.CostUSD
+}
+
 // Register adds a worker to the tracker programmatically (no PID/file needed).
 // Used for gateway-based workers that don't have a local PID.
 func (t *Tracker) Register(workerID, taskID, persona, zone, model string) *TrackedProcess {
@@ -184,8 +273,10 @@ func (t *Tracker) Register(workerID, taskID, persona, zone, model string) *Track
 		PID:       0, // no local PID for gateway workers
 		Status:    StatusRunning,
 		StartedAt: time.Now(),
+		LastSeen:  time.Now(),
 	}
 	t.processes[workerID] = p
+	delete(t.reaped, workerID)
 
 	if t.callback != nil {
 		cp := *p
@@ -209,6 +300,7 @@ func (t *Tracker) Deregister(workerID string, status ProcessStatus) {
 		t.callback("status_changed", &cp)
 	}
 	delete(t.processes, workerID)
+	t.logs.Finish(workerID)
 }
 
 // --- internal helpers ---
@@ -222,6 +314,9 @@ func (t *Tracker) setStatus(workerID string, status ProcessStatus) {
 			cp := *p
 			t.callback("status_changed", &cp)
 		}
+		if finished(status) {
+			t.logs.Finish(workerID)
+		}
 	}
 }
 
@@ -251,6 +346,7 @@ func (t *Tracker) heartbeatLoop() {
 			return
 		case <-ticker.C:
 			t.emitHeartbeats()
+			t.reapStale(time.Now())
 		}
 	}
 }
@@ -269,6 +365,48 @@ func (t *Tracker) emitHeartbeats() {
 	}
 }
 
+// reapStale marks running workers not seen for Staleness.After as stale
+// (worker_stale), and removes stale workers once Grace has also passed
+// (worker_reaped).
+func (t *Tracker) reapStale(now time.Time) {
+	t.mu.Lock()
+	s := t.staleness
+	if s.After <= 0 {
+		t.mu.Unlock()
+		return
+	}
+	var reaped []*TrackedProcess
+	for id, p := range t.processes {
+		idle := now.Sub(p.LastSeen)
+		switch {
+		case p.Status == StatusRunning && idle > s.After:
+			p.Status = StatusStale
+			if t.callback != nil {
+				cp := *p
+				t.callback("worker_stale", &cp)
+			}
+		case p.Status == StatusStale && idle > s.After+s.Grace:
+			delete(t.processes, id)
+			t.reaped[id] = true
+			t.logs.Finish(id)
+			if t.callback != nil {
+				cp := *p
+				t.callback("worker_reaped", &cp)
+			}
+			cp := *p
+			reaped = append(reaped, &cp)
+		}
+	}
+	hooks := t.onReap
+	t.mu.Unlock()
+
+	for _, p := range reaped {
+		for _, fn := range hooks {
+			fn(p)
+		}
+	}
+}
+
 func (t *Tracker) poll() {
 	data, err := os.ReadFile(t.workersPath())
 	if err != nil {
@@ -289,6 +427,9 @@ func (t *Tracker) poll() {
 		seen[e.WorkerID] = true
 		existing, tracked := t.processes[e.WorkerID]
 
+		if !tracked && t.reaped[e.WorkerID] {
+			continue
+		}
 		if !tracked {
 			// New worker discovered.
 			p := &TrackedProcess{
@@ -300,6 +441,7 @@ func (t *Tracker) poll() {
 				PID:       e.PID,
 				Status:    ProcessStatus(e.Status),
 				StartedAt: time.Now(),
+				LastSeen:  time.Now(),
 			}
 			t.processes[e.WorkerID] = p
 			if t.callback != nil {
@@ -311,31 +453,47 @@ func (t *Tracker) poll() {
 
 		// Status change in workers.json?
 		newStatus := ProcessStatus(e.Status)
+		if existing.Status == StatusStale && newStatus == StatusRunning {
+			continue // only a heartbeat revives a stale worker
+		}
 		if existing.Status != newStatus {
 			existing.Status = newStatus
 			if t.callback != nil {
 				cp := *existing
 				t.callback("status_changed", &cp)
 			}
+			if finished(newStatus) {
+				t.logs.Finish(e.WorkerID)
+			}
 			continue
 		}
 
 		// PID health check for running processes (skip gateway workers with PID <= 0).
-		if existing.Status == StatusRunning && existing.PID > 0 && !isAlive(existing.PID) {
+		// A live PID counts as a heartbeat.
+		if existing.Status == StatusRunning && existing.PID > 0 {
+			if isAlive(existing.PID) {
+				existing.LastSeen = time.Now()
+				continue
+			}
 			existing.Status = StatusError
 			if t.callback != nil {
 				cp := *existing
 				t.callback("error", &cp)
 			}
+			t.logs.Finish(e.WorkerID)
 		}
 	}
 }
 
-// isAlive checks whether a PID is still running via signal 0.
+// isAlive checks whether a PID is still running via signal 0. On Windows
+// finding the process is the check: it fails once the process exits.
 func isAlive(pid int) bool {
 	proc, err := os.FindProcess(pid)
 	if err != nil {
 		return false
 	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
 	return proc.Signal(syscall.Signal(0)) == nil
 }
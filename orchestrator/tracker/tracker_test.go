@@ -1,8 +1,15 @@
 package tracker
 
 import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/recording"
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
 )
 
 func TestNewTrackerEmpty(t *testing.T) {
@@ -103,6 +110,73 @@ func TestUpdateTokens(t *testing.T) {
 
 // --- LogBuffer tests ---
 
+func TestStaleWorkerReaped(t *testing.T) {
+	var events []string
+	tr := NewTracker("/tmp/test", func(eventType string, p *TrackedProcess) {
+		events = append(events, eventType+":"+string(p.Status))
+	})
+	tr.SetStaleness(Staleness{After: time.Minute, Grace: time.Minute})
+	var reaped []string
+	tr.OnReap(func(p *TrackedProcess) { reaped = append(reaped, p.WorkerID) })
+
+	tr.Register("w1", "t1", "coder", "backend", "sonnet")
+	now := time.Now()
+
+	tr.reapStale(now.Add(30 * time.Second))
+	if p, _ := tr.Get("w1"); p.Status != StatusRunning {
+		t.Fatalf("status = %s before timeout, want running", p.Status)
+	}
+
+	tr.reapStale(now.Add(90 * time.Second))
+	if p, _ := tr.Get("w1"); p.Status != StatusStale {
+		t.Fatalf("status = %s after timeout, want stale", p.Status)
+	}
+
+	tr.reapStale(now.Add(3 * time.Minute))
+	if _, ok := tr.Get("w1"); ok {
+		t.Fatal("stale worker should be reaped after the grace period")
+	}
+	if len(reaped) != 1 || reaped[0] != "w1" {
+		t.Fatalf("reap hooks got %v, want [w1]", reaped)
+	}
+	want := []string{"spawned:running", "worker_stale:stale", "worker_reaped:stale"}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+}
+
+func TestTouchRevivesStaleWorker(t *testing.T) {
+	tr := NewTracker("/tmp/test", nil)
+	tr.SetStaleness(Staleness{After: time.Minute, Grace: time.Minute})
+	tr.Register("w1", "t1", "coder", "", "")
+
+	tr.reapStale(time.Now().Add(90 * time.Second))
+	if !tr.Touch("w1") {
+		t.Fatal("Touch should report w1 as tracked")
+	}
+	if p, _ := tr.Get("w1"); p.Status != StatusRunning {
+		t.Fatalf("status = %s after heartbeat, want running", p.Status)
+	}
+	// The heartbeat restarts the clock
+	tr.reapStale(time.Now().Add(30 * time.Second))
+	if p, _ := tr.Get("w1"); p.Status != StatusRunning {
+		t.Fatalf("status = %s, want running", p.Status)
+	}
+	if tr.Touch("nope") {
+		t.Fatal("Touch should report unknown workers as untracked")
+	}
+}
+
+func TestStalenessDisabled(t *testing.T) {
+	tr := NewTracker("/tmp/test", nil)
+	tr.SetStaleness(Staleness{})
+	tr.Register("w1", "t1", "coder", "", "")
+	tr.reapStale(time.Now().Add(24 * time.Hour))
+	if p, _ := tr.Get("w1"); p.Status != StatusRunning {
+		t.Fatalf("status = %s, want running", p.Status)
+	}
+}
+
 func TestLogBufferAppendAndLines(t *testing.T) {
 	buf := NewLogBuffer(5)
 	for i := 0; i < 3; i++ {
@@ -157,3 +231,148 @@ func TestLogBufferDefaultMaxLines(t *testing.T) {
 		t.Fatalf("expected default 200, got %d", buf.maxLines)
 	}
 }
+
+// --- LogStore tests ---
+
+func TestLogStoreFollowAndFinish(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	s := NewLogStore(dir, 10)
+
+	s.Append("w1", "stdout", "one\ntwo")
+	backlog, lines, stop := s.Follow("w1")
+	defer stop()
+	if len(backlog) != 2 || backlog[1].Content != "two" {
+		t.Fatalf("backlog = %+v, want one, two", backlog)
+	}
+
+	s.Append("w1", "stderr", "three")
+	if line := <-lines; line.Content != "three" || line.Stream != "stderr" {
+		t.Fatalf("followed line = %+v", line)
+	}
+
+	if err := s.Finish("w1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-lines; ok {
+		t.Fatal("Finish should close followers")
+	}
+	if _, ok := s.Lines("w1"); ok {
+		t.Fatal("Finish should free the buffer")
+	}
+	data, err := os.ReadFile(LogPath(dir, "w1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one\ntwo\nthree\n" {
+		t.Fatalf("persisted log = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err != nil {
+		t.Errorf("log dir has no .gitignore: %v", err)
+	}
+}
+
+func TestLogStorePersistsTail(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLogStore(dir, 0)
+	s.persistBytes = 10
+	s.Append("w1", "stdout", "aaaa\nbbbb\ncccc")
+	if err := s.Finish("w1"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(LogPath(dir, "w1"))
+	if string(data) != "bbbb\ncccc\n" {
+		t.Fatalf("persisted log = %q, want the last 10 bytes", data)
+	}
+}
+
+func TestLogStoreRedactsSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	store, err := secrets.Open(filepath.Join(home, ".mission-control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("API_TOKEN", "tok-12345"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	s := NewLogStore(dir, 0)
+	_, follow, stop := s.Follow("w1")
+	defer stop()
+	s.Append("w1", "stdout", "using tok-12345")
+	if line := <-follow; line.Content != "using "+secrets.Mask {
+		t.Errorf("followed line = %q", line.Content)
+	}
+	if lines, _ := s.Lines("w1"); lines[0].Content != "using "+secrets.Mask {
+		t.Errorf("buffered line = %q", lines[0].Content)
+	}
+	if err := s.Finish("w1"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(LogPath(dir, "w1")); strings.Contains(string(data), "tok-12345") {
+		t.Errorf("persisted log holds the secret: %q", data)
+	}
+}
+
+func TestTrimLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "w1.log")
+	os.WriteFile(path, []byte("first line\nsecond\nthird\n"), 0644)
+	if err := TrimLog(path, 12); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "third\n" {
+		t.Fatalf("trimmed log = %q", data)
+	}
+	if err := TrimLog(filepath.Join(t.TempDir(), "missing.log"), 12); err != nil {
+		t.Fatalf("missing log: %v", err)
+	}
+}
+
+func TestUpdateUsagePricesByModel(t *testing.T) {
+	tr := NewTracker("/tmp/test", nil)
+	tr.mu.Lock()
+	tr.processes["w1"] = &TrackedProcess{WorkerID: "w1", Model: "claude-opus-4"}
+	tr.processes["w2"] = &TrackedProcess{WorkerID: "w2", Persona: "tester"}
+	tr.processes["w3"] = &TrackedProcess{WorkerID: "w3", Persona: "tester", Model: "claude-4"}
+	tr.processes["w4"] = &TrackedProcess{WorkerID: "w4", Persona: "tester", Model: "ollama:llama3"}
+	tr.mu.Unlock()
+
+	// opus: 1M input at $15; tester defaults to haiku: 1M output at $1.25
+	if cost := tr.UpdateUsage("w1", 1_000_000, 1_000_000, 0); cost != 15 {
+		t.Errorf("opus cost = %f, want 15", cost)
+	}
+	tr.UpdateUsage("w2", 1_000_000, 0, 1_000_000)
+	if p, _ := tr.Get("w2"); p.CostUSD != 1.25 || p.TokenCount != 1_000_000 {
+		t.Errorf("w2 = %d tokens / $%f, want 1000000 / $1.25", p.TokenCount, p.CostUSD)
+	}
+	// A model the table doesn't know is priced as the persona's default
+	if cost := tr.UpdateUsage("w3", 1_000_000, 0, 1_000_000); cost != 1.25 {
+		t.Errorf("unknown model cost = %f, want 1.25", cost)
+	}
+	if cost := tr.UpdateUsage("w4", 1_000_000, 0, 1_000_000); cost != 0 {
+		t.Errorf("ollama cost = %f, want 0", cost)
+	}
+	if cost := tr.UpdateUsage("missing", 10, 5, 5); cost != 0 {
+		t.Errorf("unknown worker cost = %f, want 0", cost)
+	}
+}
+
+func TestLogStoreRecords(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no secrets store
+	dir := t.TempDir()
+	store := NewLogStore(filepath.Join(dir, "logs"), 0)
+	store.SetRecorder(recording.NewRecorder(filepath.Join(dir, "recordings")))
+	store.Append("w1", "stdout", "one\ntwo")
+	store.Finish("w1")
+
+	_, events, err := recording.Read(recording.Path(filepath.Join(dir, "recordings"), "w1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Data != "two\r\n" {
+		t.Errorf("recorded events = %+v", events)
+	}
+}
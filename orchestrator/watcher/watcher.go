@@ -1,41 +1,73 @@
 package watcher
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/bridge"
+	"github.com/MikeSquared-Agency/MissionControl/conversation"
+	"github.com/MikeSquared-Agency/MissionControl/eventlog"
+)
+
+// Polling and debounce defaults. A burst of writes (mc rewriting several
+// state files, a worker dropping findings) is diffed once it has been quiet
+// for DefaultDebounce, or after maxDebounceDelay at the latest.
+const (
+	DefaultPollInterval = 250 * time.Millisecond
+	DefaultDebounce     = 150 * time.Millisecond
+	maxDebounceDelay    = 2 * time.Second
+)
+
+// watchedTrees are the .mission subdirectories watched recursively, with
+// the entity name used in their events (spec_added, finding_removed, ...).
+var watchedTrees = []struct{ dir, entity string }{
+	{"specs", "spec"},
+	{"findings", "finding"},
+	{"handoffs", "handoff"},
+	{"prompts", "prompt"},
+}
+
+// HandoffInbox is the drop directory, under handoffs/, for handoffs a
+// worker wrote without running mc handoff. Files added there emit
+// handoff_dropped for the server to ingest.
+const HandoffInbox = "incoming"
+
+// Entity event actions.
+const (
+	ActionAdded   = "added"
+	ActionUpdated = "updated"
+	ActionRemoved = "removed"
 )
 
 // Event represents a state change event
 type Event struct {
+	Seq  uint64      `json:"seq,omitempty"` // position in the event log, for Ack
 	Type string      `json:"type"`
 	Data interface{} `json:"data,omitempty"`
 }
 
+// EventsConsumer is the event log consumer that Events reads for.
+const EventsConsumer = "watcher"
+
 // StageState represents the stage.json structure
 type StageState struct {
-	Current   string `json:"current"`
-	UpdatedAt string `json:"updated_at"`
+	Current        string `json:"current"`
+	UpdatedAt      string `json:"updated_at"`
+	RolledBackFrom string `json:"rolled_back_from,omitempty"` // set by mc stage rollback
 }
 
-// Task represents a task from tasks.json
-type Task struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Stage     string `json:"stage"`
-	Zone      string `json:"zone"`
-	Persona   string `json:"persona"`
-	Status    string `json:"status"`
-	WorkerID  string `json:"worker_id,omitempty"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
-}
+// Task is a task from tasks.jsonl
+type Task = bridge.Task
 
 // Worker represents a worker from workers.json
 type Worker struct {
@@ -59,6 +91,7 @@ type Gate struct {
 	Status     string   `json:"status"`
 	Criteria   []string `json:"criteria"`
 	ApprovedAt string   `json:"approved_at,omitempty"`
+	ApprovedBy string   `json:"approved_by,omitempty"`
 }
 
 // GatesState represents the gates.json structure
@@ -66,41 +99,103 @@ type GatesState struct {
 	Gates map[string]Gate `json:"gates"`
 }
 
-// Watcher watches the .mission/state/ directory for changes
+// Watcher watches .mission/ for changes: the state/ files, conversation.md,
+// and specs/, findings/, handoffs/ and prompts/ recursively. Changes are
+// debounced and reported per entity (task t3 updated, spec auth-api added).
 type Watcher struct {
-	missionDir string
-	events     chan Event
-	stopCh     chan struct{}
-	mu         sync.RWMutex
+	missionDir   string
+	log          *eventlog.Log
+	events       chan Event
+	eventsOnce   sync.Once
+	stopCh       chan struct{}
+	mu           sync.RWMutex
+	pollInterval time.Duration
+	debounce     time.Duration
 
 	// Last known state for diffing
-	lastStage     StageState
-	lastTasks     map[string]Task
-	lastWorkers   map[string]Worker
-	lastGates     map[string]Gate
-	knownFindings map[string]bool
-	knownHandoffs map[string]bool
+	lastStage   StageState
+	lastTasks   map[string]Task
+	lastWorkers map[string]Worker
+	lastGates   map[string]Gate
+	trees       map[string]map[string]fileStat // watched dir → relative path → stat
+	convo       *conversation.Tailer
+
+	// Debounce state, owned by the poll goroutine
+	seen       uint64 // fingerprint of the last poll
+	pending    bool
+	dirtySince time.Time
+	lastChange time.Time
+}
+
+// fileStat is what the watcher compares to decide a file changed.
+type fileStat struct {
+	size    int64
+	modTime time.Time
 }
 
 // NewWatcher creates a new state watcher
 func NewWatcher(missionDir string) *Watcher {
 	return &Watcher{
-		missionDir:    missionDir,
-		events:        make(chan Event, 100),
-		stopCh:        make(chan struct{}),
-		lastTasks:     make(map[string]Task),
-		lastWorkers:   make(map[string]Worker),
-		lastGates:     make(map[string]Gate),
-		knownFindings: make(map[string]bool),
-		knownHandoffs: make(map[string]bool),
+		missionDir:   missionDir,
+		log:          eventlog.New(0),
+		events:       make(chan Event),
+		stopCh:       make(chan struct{}),
+		pollInterval: DefaultPollInterval,
+		debounce:     DefaultDebounce,
+		lastTasks:    make(map[string]Task),
+		lastWorkers:  make(map[string]Worker),
+		lastGates:    make(map[string]Gate),
+		trees:        make(map[string]map[string]fileStat),
+		convo:        conversation.NewTailer(filepath.Join(missionDir, conversation.FileName)),
 	}
 }
 
-// Events returns the channel for state change events
+// SetTiming overrides the poll interval and debounce window. Call it
+// before Start.
+func (w *Watcher) SetTiming(poll, debounce time.Duration) {
+	w.pollInterval = poll
+	w.debounce = debounce
+}
+
+// SetEventLog records events in log instead of the default in-memory one,
+// so events not yet acknowledged survive a restart. Call it before Start.
+func (w *Watcher) SetEventLog(log *eventlog.Log) {
+	w.log = log
+}
+
+// EventLog returns the log events are recorded in.
+func (w *Watcher) EventLog() *eventlog.Log {
+	return w.log
+}
+
+// Events returns the channel for state change events. It starts with the
+// events logged after the last Ack and never drops any; a slow reader
+// only delays delivery.
 func (w *Watcher) Events() <-chan Event {
+	w.eventsOnce.Do(func() {
+		go func() {
+			for rec := range w.log.Subscribe(EventsConsumer, w.stopCh) {
+				event := Event{Seq: rec.Seq, Type: rec.Type, Data: rec.Value}
+				if event.Data == nil && len(rec.Data) > 0 {
+					json.Unmarshal(rec.Data, &event.Data)
+				}
+				select {
+				case w.events <- event:
+				case <-w.stopCh:
+					return
+				}
+			}
+		}()
+	})
 	return w.events
 }
 
+// Ack acknowledges every event up to seq read from Events, so they are
+// not delivered again after a restart.
+func (w *Watcher) Ack(seq uint64) error {
+	return w.log.Ack(EventsConsumer, seq)
+}
+
 // MissionDir returns the .mission directory path this watcher monitors.
 func (w *Watcher) MissionDir() string {
 	return w.missionDir
@@ -130,21 +225,93 @@ func (w *Watcher) Stop() {
 	close(w.stopCh)
 }
 
-// poll checks for file changes periodically
+// poll checks for file changes periodically. A change is only diffed once
+// the files have stopped changing for the debounce window, so a burst of
+// writes produces one set of events.
 func (w *Watcher) poll() {
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-w.stopCh:
 			return
-		case <-ticker.C:
-			w.checkForChanges()
+		case now := <-ticker.C:
+			if fp := w.fingerprint(); fp != w.seen {
+				w.seen = fp
+				w.lastChange = now
+				if !w.pending {
+					w.pending = true
+					w.dirtySince = now
+				}
+			}
+			if w.pending && (now.Sub(w.lastChange) >= w.debounce || now.Sub(w.dirtySince) >= maxDebounceDelay) {
+				w.pending = false
+				w.checkForChanges()
+			}
 		}
 	}
 }
 
+// fingerprint hashes the size and mtime of every watched file.
+func (w *Watcher) fingerprint() uint64 {
+	h := fnv.New64a()
+	add := func(path string, info fs.FileInfo) {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+	stateDir := filepath.Join(w.missionDir, "state")
+	for _, name := range []string{"stage.json", "tasks.jsonl", "workers.json", "gates.json"} {
+		if info, err := os.Stat(filepath.Join(stateDir, name)); err == nil {
+			add(name, info)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(w.missionDir, conversation.FileName)); err == nil {
+		add(conversation.FileName, info)
+	}
+	for _, t := range watchedTrees {
+		walkTree(filepath.Join(w.missionDir, t.dir), func(rel string, info fs.FileInfo) {
+			add(t.dir+"/"+rel, info)
+		})
+	}
+	return h.Sum64()
+}
+
+// walkTree calls fn for every regular file under root, skipping hidden
+// files and directories (editor swap files, atomic-write temp files).
+func walkTree(root string, fn func(rel string, info fs.FileInfo)) {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~")) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		fn(filepath.ToSlash(rel), info)
+		return nil
+	})
+}
+
+// scanTree snapshots the files under root.
+func scanTree(root string) map[string]fileStat {
+	files := make(map[string]fileStat)
+	walkTree(root, func(rel string, info fs.FileInfo) {
+		files[rel] = fileStat{size: info.Size(), modTime: info.ModTime()}
+	})
+	return files
+}
+
 // loadInitialState loads the current state files
 func (w *Watcher) loadInitialState() {
 	w.mu.Lock()
@@ -180,23 +347,26 @@ func (w *Watcher) loadInitialState() {
 		w.lastGates = gatesState.Gates
 	}
 
-	// Snapshot existing findings
-	if entries, err := os.ReadDir(filepath.Join(w.missionDir, "findings")); err == nil {
-		for _, e := range entries {
-			if !e.IsDir() {
-				w.knownFindings[e.Name()] = true
-			}
-		}
+	// Snapshot the watched trees so existing files are not reported, except
+	// handoffs dropped while nothing was watching, which still need ingesting
+	for _, t := range watchedTrees {
+		w.trees[t.dir] = scanTree(filepath.Join(w.missionDir, t.dir))
 	}
-
-	// Snapshot existing handoffs
-	if entries, err := os.ReadDir(filepath.Join(w.missionDir, "handoffs")); err == nil {
-		for _, e := range entries {
-			if !e.IsDir() {
-				w.knownHandoffs[e.Name()] = true
-			}
+	var dropped []string
+	for rel := range w.trees["handoffs"] {
+		if isDroppedHandoff(rel) {
+			dropped = append(dropped, rel)
 		}
 	}
+	sort.Strings(dropped)
+	for _, rel := range dropped {
+		w.emitDropped(rel)
+	}
+	// Only exchanges completed from now on are reported
+	if err := w.convo.Skip(); err != nil {
+		log.Printf("Watcher: cannot read %s: %v", conversation.FileName, err)
+	}
+	w.seen = w.fingerprint()
 }
 
 // checkForChanges compares current state with last known state
@@ -209,7 +379,11 @@ func (w *Watcher) checkForChanges() {
 		_ = json.Unmarshal(data, &currentStage)
 		w.mu.Lock()
 		if currentStage.Current != w.lastStage.Current {
-			w.emitEvent("stage_changed", map[string]interface{}{
+			eventType := "stage_changed"
+			if currentStage.RolledBackFrom != "" && currentStage.RolledBackFrom == w.lastStage.Current {
+				eventType = "stage_rolled_back"
+			}
+			w.emitEvent(eventType, map[string]interface{}{
 				"previous": w.lastStage.Current,
 				"current":  currentStage.Current,
 			})
@@ -228,14 +402,33 @@ func (w *Watcher) checkForChanges() {
 			// Check if new or updated
 			if lastTask, exists := w.lastTasks[t.ID]; !exists {
 				w.emitEvent("task_created", t)
-			} else if t.Status != lastTask.Status || t.UpdatedAt != lastTask.UpdatedAt {
+			} else if changed := taskChanges(lastTask, t); len(changed) > 0 {
 				w.emitEvent("task_updated", map[string]interface{}{
+					"entity":  "task",
+					"id":      t.ID,
+					"action":  ActionUpdated,
 					"task_id": t.ID,
 					"status":  t.Status,
+					"changed": changed,
 					"task":    t,
 				})
 			}
 		}
+		var deleted []string
+		for id := range w.lastTasks {
+			if _, ok := currentTasks[id]; !ok {
+				deleted = append(deleted, id)
+			}
+		}
+		sort.Strings(deleted)
+		for _, id := range deleted {
+			w.emitEvent("task_deleted", map[string]interface{}{
+				"entity":  "task",
+				"id":      id,
+				"action":  ActionRemoved,
+				"task_id": id,
+			})
+		}
 		w.lastTasks = currentTasks
 		w.mu.Unlock()
 	}
@@ -264,6 +457,13 @@ func (w *Watcher) checkForChanges() {
 						"worker_id": wr.ID,
 						"task_id":   wr.TaskID,
 					})
+				} else if lastWorker.Status == "queued" && wr.Status == "running" {
+					// A slot freed up under the concurrency limits
+					w.emitEvent("worker_dequeued", map[string]interface{}{
+						"worker_id": wr.ID,
+						"task_id":   wr.TaskID,
+						"zone":      wr.Zone,
+					})
 				} else {
 					w.emitEvent("worker_status_changed", map[string]interface{}{
 						"worker_id": wr.ID,
@@ -289,6 +489,7 @@ func (w *Watcher) checkForChanges() {
 						w.emitEvent("gate_approved", map[string]interface{}{
 							"stage":       stage,
 							"approved_at": gate.ApprovedAt,
+							"approved_by": gate.ApprovedBy,
 						})
 					} else if gate.Status == "ready" {
 						w.emitEvent("gate_ready", map[string]interface{}{
@@ -303,69 +504,164 @@ func (w *Watcher) checkForChanges() {
 		w.mu.Unlock()
 	}
 
-	// Check for new findings and handoffs
-	w.checkFindings()
-	w.checkHandoffs()
+	// Check specs, findings, handoffs and prompts
+	w.checkTrees()
+	w.checkConversation()
 }
 
-// checkFindings checks for new finding files
-func (w *Watcher) checkFindings() {
-	findingsDir := filepath.Join(w.missionDir, "findings")
+// checkConversation reports messages appended to conversation.md. Each
+// complete message emits conversation_message; an assistant response
+// closed with ---END--- also emits exchange_completed.
+func (w *Watcher) checkConversation() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	entries, err := os.ReadDir(findingsDir)
+	messages, exchanges, reset, err := w.convo.Poll()
 	if err != nil {
+		log.Printf("Watcher: cannot read %s: %v", conversation.FileName, err)
 		return
 	}
+	if reset {
+		w.emitEvent("conversation_reset", nil)
+	}
+	for _, m := range messages {
+		w.emitEvent("conversation_message", m)
+	}
+	for _, ex := range exchanges {
+		w.emitEvent("exchange_completed", ex)
+	}
+}
 
+// taskChanges lists the fields that differ between two versions of a task.
+func taskChanges(old, cur Task) []string {
+	var changed []string
+	fields := []struct {
+		name     string
+		old, cur string
+	}{
+		{"name", old.Name, cur.Name},
+		{"stage", old.Stage, cur.Stage},
+		{"zone", old.Zone, cur.Zone},
+		{"persona", old.Persona, cur.Persona},
+		{"status", old.Status, cur.Status},
+		{"worker_id", old.WorkerID, cur.WorkerID},
+		{"updated_at", old.UpdatedAt, cur.UpdatedAt},
+	}
+	for _, f := range fields {
+		if f.old != f.cur {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+// checkTrees diffs the watched directories and emits <entity>_added,
+// _updated and _removed events. The entity id is the path relative to the
+// directory without its extension ("auth-api", "t3/notes"). New top-level
+// findings and handoffs also emit the findings_ready and handoff_created
+// events the orchestrator acts on, and a changed or removed spec emits
+// spec_changed with the open tasks linked to it.
+func (w *Watcher) checkTrees() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	for _, t := range watchedTrees {
+		dir := filepath.Join(w.missionDir, t.dir)
+		current := scanTree(dir)
+		previous := w.trees[t.dir]
+		w.trees[t.dir] = current
+
+		var paths []string
+		for rel := range current {
+			paths = append(paths, rel)
 		}
-		name := entry.Name()
-		if w.knownFindings[name] {
-			continue
+		for rel := range previous {
+			if _, ok := current[rel]; !ok {
+				paths = append(paths, rel)
+			}
 		}
-		w.knownFindings[name] = true
-		taskID := stripExt(name)
-		w.emitEvent("findings_ready", map[string]interface{}{
-			"task_id": taskID,
-			"path":    filepath.Join(findingsDir, name),
-		})
-	}
-}
+		sort.Strings(paths)
+
+		for _, rel := range paths {
+			cur, exists := current[rel]
+			old, existed := previous[rel]
+			var action string
+			switch {
+			case exists && !existed:
+				action = ActionAdded
+			case !exists && existed:
+				action = ActionRemoved
+			case cur.size != old.size || !cur.modTime.Equal(old.modTime):
+				action = ActionUpdated
+			default:
+				continue
+			}
 
-// checkHandoffs checks for new handoff briefing files
-func (w *Watcher) checkHandoffs() {
-	handoffsDir := filepath.Join(w.missionDir, "handoffs")
+			path := filepath.Join(dir, filepath.FromSlash(rel))
+			w.emitEvent(t.entity+"_"+action, map[string]interface{}{
+				"entity": t.entity,
+				"id":     stripExt(rel),
+				"action": action,
+				"path":   path,
+			})
 
-	entries, err := os.ReadDir(handoffsDir)
-	if err != nil {
-		return
+			if t.dir == "specs" && action != ActionAdded {
+				w.emitEvent("spec_changed", map[string]interface{}{
+					"id":           stripExt(rel),
+					"action":       action,
+					"path":         path,
+					"linked_tasks": w.openTasksFor(stripExt(rel)),
+				})
+			}
+			if t.dir == "handoffs" && action == ActionAdded && isDroppedHandoff(rel) {
+				w.emitDropped(rel)
+			}
+			if action != ActionAdded || strings.Contains(rel, "/") {
+				continue
+			}
+			switch t.dir {
+			case "findings":
+				w.emitEvent("findings_ready", map[string]interface{}{
+					"task_id": stripExt(rel),
+					"path":    path,
+				})
+			case "handoffs":
+				// Strip "-briefing" suffix if present (e.g. "abc123-briefing" → "abc123")
+				w.emitEvent("handoff_created", map[string]interface{}{
+					"task_id": strings.TrimSuffix(stripExt(rel), "-briefing"),
+					"path":    path,
+				})
+			}
+		}
 	}
+}
 
-	w.mu.Lock()
-	defer w.mu.Unlock()
+// isDroppedHandoff reports whether rel, relative to handoffs/, is a JSON
+// file directly in the inbox.
+func isDroppedHandoff(rel string) bool {
+	dir, name := path.Split(rel)
+	return dir == HandoffInbox+"/" && strings.HasSuffix(name, ".json")
+}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if w.knownHandoffs[name] {
-			continue
+// emitDropped emits handoff_dropped for the inbox file rel.
+func (w *Watcher) emitDropped(rel string) {
+	w.emitEvent("handoff_dropped", map[string]interface{}{
+		"file": path.Base(rel),
+		"path": filepath.Join(w.missionDir, "handoffs", filepath.FromSlash(rel)),
+	})
+}
+
+// openTasksFor returns the IDs of the unfinished tasks linked to spec id,
+// which were planned against its previous content. Callers hold w.mu.
+func (w *Watcher) openTasksFor(id string) []string {
+	ids := []string{}
+	for _, t := range w.lastTasks {
+		if t.Spec == id && t.Status != "done" && t.Status != "complete" && t.Status != "archived" {
+			ids = append(ids, t.ID)
 		}
-		w.knownHandoffs[name] = true
-		taskID := stripExt(name)
-		// Strip "-briefing" suffix if present (e.g. "abc123-briefing" → "abc123")
-		taskID = strings.TrimSuffix(taskID, "-briefing")
-		w.emitEvent("handoff_created", map[string]interface{}{
-			"task_id": taskID,
-			"path":    filepath.Join(handoffsDir, name),
-		})
 	}
+	sort.Strings(ids)
+	return ids
 }
 
 // stripExt removes the file extension from a filename.
@@ -377,17 +673,10 @@ func stripExt(name string) string {
 	return name
 }
 
-// emitEvent sends an event to the events channel
+// emitEvent records an event in the event log for Events to deliver.
 func (w *Watcher) emitEvent(eventType string, data interface{}) {
-	event := Event{
-		Type: eventType,
-		Data: data,
-	}
-
-	select {
-	case w.events <- event:
-	default:
-		log.Printf("Watcher: event channel full, dropping event: %s", eventType)
+	if _, err := w.log.Append(eventType, data); err != nil {
+		log.Printf("Watcher: logging event %s: %v", eventType, err)
 	}
 }
 
@@ -415,26 +704,11 @@ func (w *Watcher) GetCurrentState() map[string]interface{} {
 }
 
 // readTasksJSONLFile reads tasks from a JSONL file (one JSON task per line).
+// Lines that don't decode as a Task are logged and skipped.
 func readTasksJSONLFile(path string) ([]Task, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var tasks []Task
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-		var task Task
-		if err := json.Unmarshal(line, &task); err != nil {
-			continue // skip malformed lines in watcher
-		}
-		tasks = append(tasks, task)
+	tasks, invalid, err := bridge.ReadTasks(path)
+	for _, e := range invalid {
+		log.Printf("Watcher: skipping task in %s: %v", filepath.Base(path), e)
 	}
-	return tasks, scanner.Err()
+	return tasks, err
 }
@@ -83,6 +83,32 @@ func TestDetectsStageChange(t *testing.T) {
 	}
 }
 
+func TestDetectsStageRollback(t *testing.T) {
+	dir := createTestDir(t)
+	w := NewWatcher(dir)
+	w.SetTiming(20*time.Millisecond, 20*time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	var current StageState
+	data, _ := os.ReadFile(filepath.Join(dir, "state", "stage.json"))
+	json.Unmarshal(data, &current)
+	os.WriteFile(filepath.Join(dir, "state", "stage.json"),
+		[]byte(`{"current":"goal","updated_at":"2026-01-02T00:00:00Z","rolled_back_from":"`+current.Current+`"}`), 0644)
+
+	for _, ev := range collectEvents(w, 300*time.Millisecond) {
+		if ev.Type == "stage_changed" {
+			t.Fatal("expected stage_rolled_back instead of stage_changed")
+		}
+		if ev.Type == "stage_rolled_back" {
+			return
+		}
+	}
+	t.Fatal("no stage_rolled_back event")
+}
+
 func TestDetectsNewTask(t *testing.T) {
 	dir := createTestDir(t)
 	w := NewWatcher(dir)
@@ -111,6 +137,33 @@ func TestDetectsNewTask(t *testing.T) {
 	}
 }
 
+func TestDetectsDequeuedWorker(t *testing.T) {
+	dir := createTestDir(t)
+	workersPath := filepath.Join(dir, "state", "workers.json")
+	os.WriteFile(workersPath, []byte(`{"workers":[{"id":"w1","zone":"backend","status":"queued"}]}`), 0644)
+	w := NewWatcher(dir)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	time.Sleep(600 * time.Millisecond)
+
+	os.WriteFile(workersPath, []byte(`{"workers":[{"id":"w1","zone":"backend","status":"running","pid":42}]}`), 0644)
+
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case event := <-w.Events():
+			if event.Type == "worker_dequeued" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for worker_dequeued event")
+		}
+	}
+}
+
 func TestGetCurrentState(t *testing.T) {
 	dir := createTestDir(t)
 	w := NewWatcher(dir)
@@ -268,3 +321,222 @@ func TestIgnoresExistingFindings(t *testing.T) {
 		}
 	}
 }
+
+// collectEvents gathers events until none arrive for quiet.
+func collectEvents(w *Watcher, quiet time.Duration) []Event {
+	var events []Event
+	for {
+		select {
+		case ev := <-w.Events():
+			events = append(events, ev)
+		case <-time.After(quiet):
+			return events
+		}
+	}
+}
+
+func TestDebounceCoalescesBursts(t *testing.T) {
+	dir := createTestDir(t)
+	w := NewWatcher(dir)
+	w.SetTiming(20*time.Millisecond, 150*time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	tasksPath := filepath.Join(dir, "state", "tasks.jsonl")
+	for i, status := range []string{"in_progress", "blocked", "complete"} {
+		line := `{"id":"t1","name":"Test","status":"` + status + `","created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-0` + string(rune('2'+i)) + `T00:00:00Z"}` + "\n"
+		os.WriteFile(tasksPath, []byte(line), 0644)
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	var updates []map[string]interface{}
+	for _, ev := range collectEvents(w, 600*time.Millisecond) {
+		if ev.Type == "task_updated" {
+			updates = append(updates, ev.Data.(map[string]interface{}))
+		}
+	}
+	if len(updates) != 1 {
+		t.Fatalf("expected one coalesced task_updated, got %d: %v", len(updates), updates)
+	}
+	if updates[0]["id"] != "t1" || updates[0]["status"] != "complete" {
+		t.Errorf("expected t1 complete, got %v", updates[0])
+	}
+	changed, _ := updates[0]["changed"].([]string)
+	if len(changed) != 2 || changed[0] != "status" || changed[1] != "updated_at" {
+		t.Errorf("expected changed [status updated_at], got %v", updates[0]["changed"])
+	}
+}
+
+func TestEntityEventsForTrees(t *testing.T) {
+	dir := createTestDir(t)
+	os.MkdirAll(filepath.Join(dir, "specs"), 0755)
+	os.WriteFile(filepath.Join(dir, "specs", "old.md"), []byte("# Old"), 0644)
+
+	w := NewWatcher(dir)
+	w.SetTiming(20*time.Millisecond, 40*time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	os.MkdirAll(filepath.Join(dir, "specs", "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "specs", "api", "auth-api.md"), []byte("# Auth"), 0644)
+	os.WriteFile(filepath.Join(dir, "specs", ".auth-api.md.swp"), []byte("x"), 0644)
+	os.Remove(filepath.Join(dir, "specs", "old.md"))
+	os.MkdirAll(filepath.Join(dir, "prompts"), 0755)
+	os.WriteFile(filepath.Join(dir, "prompts", "developer.md"), []byte("prompt"), 0644)
+	os.WriteFile(filepath.Join(dir, "state", "tasks.jsonl"), nil, 0644)
+
+	got := map[string]map[string]interface{}{}
+	for _, ev := range collectEvents(w, 400*time.Millisecond) {
+		if m, ok := ev.Data.(map[string]interface{}); ok {
+			got[ev.Type+" "+m["id"].(string)] = m
+		}
+	}
+	for _, key := range []string{"spec_added api/auth-api", "spec_removed old", "prompt_added developer", "task_deleted t1"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing event %q; got %v", key, keys(got))
+		}
+	}
+	for key := range got {
+		if key == "spec_added .auth-api.md" {
+			t.Error("hidden files should be ignored")
+		}
+	}
+	if m := got["spec_added api/auth-api"]; m != nil && (m["entity"] != "spec" || m["action"] != ActionAdded) {
+		t.Errorf("unexpected spec event payload: %v", m)
+	}
+
+	// Rewriting a spec reports it as updated.
+	time.Sleep(20 * time.Millisecond)
+	os.WriteFile(filepath.Join(dir, "specs", "api", "auth-api.md"), []byte("# Auth v2"), 0644)
+	found := false
+	for _, ev := range collectEvents(w, 400*time.Millisecond) {
+		if ev.Type == "spec_updated" && ev.Data.(map[string]interface{})["id"] == "api/auth-api" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected spec_updated for api/auth-api")
+	}
+}
+
+func TestSpecChangedListsOpenTasks(t *testing.T) {
+	dir := createTestDir(t)
+	os.MkdirAll(filepath.Join(dir, "specs"), 0755)
+	os.WriteFile(filepath.Join(dir, "specs", "login.md"), []byte("# Login"), 0644)
+	os.WriteFile(filepath.Join(dir, "state", "tasks.jsonl"), []byte(
+		`{"id":"t1","name":"form","status":"in_progress","spec":"login"}`+"\n"+
+			`{"id":"t2","name":"api","status":"done","spec":"login"}`+"\n"+
+			`{"id":"t3","name":"other","status":"pending","spec":"signup"}`+"\n"), 0644)
+
+	w := NewWatcher(dir)
+	w.SetTiming(20*time.Millisecond, 40*time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	os.WriteFile(filepath.Join(dir, "specs", "login.md"), []byte("# Login v2"), 0644)
+	for _, ev := range collectEvents(w, 400*time.Millisecond) {
+		if ev.Type != "spec_changed" {
+			continue
+		}
+		m := ev.Data.(map[string]interface{})
+		tasks, _ := m["linked_tasks"].([]string)
+		if m["id"] != "login" || m["action"] != ActionUpdated || len(tasks) != 1 || tasks[0] != "t1" {
+			t.Errorf("spec_changed = %v", m)
+		}
+		return
+	}
+	t.Error("expected spec_changed for login")
+}
+
+func keys(m map[string]map[string]interface{}) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestConversationEvents(t *testing.T) {
+	dir := createTestDir(t)
+	path := filepath.Join(dir, "conversation.md")
+	os.WriteFile(path, []byte("## Human\n\nold question\n\n---\n\n## Assistant\n\nold answer\n\n---END---\n"), 0644)
+
+	w := NewWatcher(dir)
+	w.SetTiming(20*time.Millisecond, 40*time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("\n## Human\n\nnew question\n\n---\n\n## Assistant\n\nnew answer\n\n---END---\n")
+	f.Close()
+
+	var messages, exchanges int
+	for _, ev := range collectEvents(w, 400*time.Millisecond) {
+		switch ev.Type {
+		case "conversation_message":
+			messages++
+		case "exchange_completed":
+			exchanges++
+		}
+	}
+	if messages != 2 || exchanges != 1 {
+		t.Errorf("expected 2 messages and 1 exchange, got %d and %d", messages, exchanges)
+	}
+}
+
+func TestDetectsDroppedHandoffs(t *testing.T) {
+	dir := createTestDir(t)
+	inbox := filepath.Join(dir, "handoffs", HandoffInbox)
+	os.MkdirAll(inbox, 0755)
+	// Dropped before the watcher started: still reported
+	os.WriteFile(filepath.Join(inbox, "early.json"), []byte(`{}`), 0644)
+
+	w := NewWatcher(dir)
+	w.SetTiming(20*time.Millisecond, 20*time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	os.WriteFile(filepath.Join(inbox, "late.json"), []byte(`{}`), 0644)
+	os.WriteFile(filepath.Join(inbox, "notes.txt"), []byte(`x`), 0644)
+	os.MkdirAll(filepath.Join(dir, "handoffs", "rejected"), 0755)
+	os.WriteFile(filepath.Join(dir, "handoffs", "rejected", "old.json"), []byte(`{}`), 0644)
+
+	var files []string
+	timeout := time.After(3 * time.Second)
+	for len(files) < 2 {
+		select {
+		case event := <-w.Events():
+			if event.Type != "handoff_dropped" {
+				continue
+			}
+			m := event.Data.(map[string]interface{})
+			files = append(files, m["file"].(string))
+			if m["path"] != filepath.Join(inbox, m["file"].(string)) {
+				t.Errorf("unexpected path %v", m["path"])
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for handoff_dropped, got %v", files)
+		}
+	}
+	if files[0] != "early.json" || files[1] != "late.json" {
+		t.Errorf("expected early.json then late.json, got %v", files)
+	}
+	select {
+	case event := <-w.Events():
+		if event.Type == "handoff_dropped" {
+			t.Errorf("unexpected %v", event.Data)
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+}
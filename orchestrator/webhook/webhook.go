@@ -0,0 +1,162 @@
+// Package webhook posts mission events to the HTTP endpoints configured
+// under "webhooks" in .mission/config.json:
+//
+//	"webhooks": [{"url": "https://hooks.example.com/mc", "events": ["task_assigned"], "secret": "s3cret"}]
+//
+// Each delivery is a JSON Event. With a secret, the body is signed with
+// HMAC-SHA256 in the X-MC-Signature header as "sha256=<hex>". A secret of
+// "secret:NAME" is read from the secrets store (mc secret set NAME).
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+// Events
+const (
+	EventTaskAssigned     = "task_assigned"
+	EventSessionRestarted = "session_restarted"
+	EventStageOverrun     = "stage_overrun"
+	EventBudgetWarning    = "budget_warning"
+	EventBudgetCritical   = "budget_critical"
+	EventBudgetForecast   = "budget_forecast_exceeded"
+	EventGateEscalated    = "gate_escalated"
+)
+
+// Headers set on every delivery
+const (
+	EventHeader     = "X-MC-Event"
+	SignatureHeader = "X-MC-Signature"
+)
+
+// Timeout bounds each delivery.
+const Timeout = 5 * time.Second
+
+// Hook is one configured endpoint. An empty Events list receives every
+// event.
+type Hook struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// Wants reports whether the hook subscribes to event.
+func (h Hook) Wants(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body of a delivery.
+type Event struct {
+	Event     string      `json:"event"`
+	Timestamp string      `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Sign returns the X-MC-Signature value for body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send delivers event with data to every hook that wants it, one after
+// another, and returns the failures joined. A hook failing does not stop
+// delivery to the rest.
+func Send(ctx context.Context, hooks []Hook, event string, data interface{}) error {
+	body, err := json.Marshal(Event{
+		Event:     event,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, h := range hooks {
+		if h.URL == "" || !h.Wants(event) {
+			continue
+		}
+		if err := deliver(ctx, h, event, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", h.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Slack posts text to a Slack incoming webhook URL, which may be
+// "secret:NAME" like a hook's secret.
+func Slack(ctx context.Context, url, text string) error {
+	url, err := secrets.Resolve(url)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func deliver(ctx context.Context, h Hook, event string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if h.Secret != "" {
+		secret, err := secrets.Resolve(h.Secret)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MikeSquared-Agency/MissionControl/secrets"
+)
+
+func TestSend(t *testing.T) {
+	var got []Event
+	var signatures []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(SignatureHeader); sig != "" && sig != Sign("s3cret", body) {
+			t.Errorf("bad signature %q", sig)
+		}
+		signatures = append(signatures, r.Header.Get(SignatureHeader))
+		var e Event
+		json.Unmarshal(body, &e)
+		got = append(got, e)
+	}))
+	defer srv.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	hooks := []Hook{
+		{URL: srv.URL, Secret: "s3cret"},
+		{URL: srv.URL, Events: []string{"gate_approved"}},
+		{URL: failing.URL, Events: []string{EventTaskAssigned}},
+		{URL: srv.URL, Events: []string{EventTaskAssigned}},
+	}
+	err := Send(context.Background(), hooks, EventTaskAssigned, map[string]string{"task_id": "a"})
+	if err == nil || !strings.Contains(err.Error(), failing.URL) {
+		t.Errorf("err = %v, want the failing hook named", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("delivered %d events, want 2", len(got))
+	}
+	if got[0].Event != EventTaskAssigned || got[0].Timestamp == "" {
+		t.Errorf("event = %+v", got[0])
+	}
+	if signatures[0] == "" || signatures[1] != "" {
+		t.Errorf("signatures = %q, want only the first signed", signatures)
+	}
+}
+
+func TestSendResolvesSecretRef(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secrets.EnvKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	store, err := secrets.Open(filepath.Join(home, ".mission-control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("SIGNING_KEY", "s3cret")
+
+	var sig string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("s3cret", body) {
+			t.Errorf("signature not made with the stored secret")
+		}
+		sig = r.Header.Get(SignatureHeader)
+	}))
+	defer srv.Close()
+
+	if err := Send(context.Background(), []Hook{{URL: srv.URL, Secret: "secret:SIGNING_KEY"}}, EventTaskAssigned, nil); err != nil {
+		t.Fatal(err)
+	}
+	if sig == "" {
+		t.Errorf("delivery was not signed")
+	}
+	if err := Send(context.Background(), []Hook{{URL: srv.URL, Secret: "secret:MISSING"}}, EventTaskAssigned, nil); err == nil {
+		t.Errorf("a missing secret should fail the delivery")
+	}
+}
@@ -0,0 +1,63 @@
+package workspace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatrixRefusal starts the error Check returns, so callers that only see
+// mc's output (the API) can tell it apart from other failures.
+const MatrixRefusal = "not enabled by the matrix"
+
+// Matrix is a project's stage matrix, as stored under "matrix" in
+// .mission/config.json.
+type Matrix []MatrixCell
+
+// Enabled returns the personas the matrix enables for stage in zone. A
+// zone without cells of its own (one added after the matrix was drawn, or
+// none given) gets the personas enabled for stage in any zone.
+func (m Matrix) Enabled(stage, zone string) []string {
+	hasZone := false
+	for _, c := range m {
+		if c.Zone == zone {
+			hasZone = true
+			break
+		}
+	}
+	seen := map[string]bool{}
+	var personas []string
+	for _, c := range m {
+		if c.Stage != stage || !c.Enabled || (hasZone && c.Zone != zone) || seen[c.Persona] {
+			continue
+		}
+		seen[c.Persona] = true
+		personas = append(personas, c.Persona)
+	}
+	sort.Strings(personas)
+	return personas
+}
+
+// Check returns an error when the matrix doesn't enable persona for stage
+// in zone. An empty matrix, from a project set up without one, allows
+// everything, as does an empty stage or persona.
+func (m Matrix) Check(stage, zone, persona string) error {
+	if len(m) == 0 || stage == "" || persona == "" {
+		return nil
+	}
+	enabled := m.Enabled(stage, zone)
+	for _, p := range enabled {
+		if p == persona {
+			return nil
+		}
+	}
+	where := "the " + stage + " stage"
+	if zone != "" {
+		where += " in zone " + zone
+	}
+	allowed := "none"
+	if len(enabled) > 0 {
+		allowed = strings.Join(enabled, ", ")
+	}
+	return fmt.Errorf("persona %s is %s for %s (enabled: %s)", persona, MatrixRefusal, where, allowed)
+}
@@ -0,0 +1,472 @@
+// Package workspace inspects an existing repository and proposes a
+// MissionControl setup for it: zones mapped to directories, a stage matrix
+// and a spec skeleton. It backs `mc init --analyze` and POST /api/projects
+// with "analyze": true.
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Zone names.
+const (
+	ZoneFrontend = "frontend"
+	ZoneBackend  = "backend"
+	ZoneDatabase = "database"
+	ZoneInfra    = "infra"
+	ZoneShared   = "shared"
+)
+
+// DefaultZones are used when nothing in the repository suggests otherwise,
+// and give the order in which detected zones are listed.
+var DefaultZones = []string{ZoneFrontend, ZoneBackend, ZoneDatabase, ZoneInfra, ZoneShared}
+
+// Stages lists the workflow stages in order.
+var Stages = []string{
+	"discovery", "goal", "requirements", "planning", "design",
+	"implement", "verify", "validate", "document", "release",
+}
+
+// StagePersonas maps each stage to its personas (mirrors the dashboard
+// wizard).
+var StagePersonas = map[string][]string{
+	"discovery":    {"researcher"},
+	"goal":         {"analyst"},
+	"requirements": {"requirements-engineer"},
+	"planning":     {"architect"},
+	"design":       {"designer"},
+	"implement":    {"developer", "debugger"},
+	"verify":       {"reviewer", "security", "tester"},
+	"validate":     {"qa"},
+	"document":     {"docs"},
+	"release":      {"devops"},
+}
+
+// Analysis is what Analyze found and what it proposes.
+type Analysis struct {
+	Root       string       `json:"root"`
+	Name       string       `json:"name"`
+	Languages  []string     `json:"languages"`
+	Frameworks []string     `json:"frameworks"`
+	Signals    []Signal     `json:"signals"`
+	Zones      []Zone       `json:"zones"`
+	Matrix     []MatrixCell `json:"matrix"`
+	Spec       string       `json:"spec"`
+}
+
+// Signal is one piece of evidence, e.g. a manifest or directory.
+type Signal struct {
+	Path   string `json:"path"`
+	Detail string `json:"detail"`
+}
+
+// Zone is a proposed zone and the directories that belong to it.
+type Zone struct {
+	Name   string   `json:"name"`
+	Paths  []string `json:"paths"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// MatrixCell enables or disables a persona for a stage in a zone. It has
+// the shape of the dashboard wizard's matrix and of api.MatrixCell.
+type MatrixCell struct {
+	Stage   string `json:"stage"`
+	Zone    string `json:"zone"`
+	Persona string `json:"persona"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ZoneNames returns the proposed zone names in order.
+func (a *Analysis) ZoneNames() []string {
+	names := make([]string, len(a.Zones))
+	for i, z := range a.Zones {
+		names[i] = z.Name
+	}
+	return names
+}
+
+// Detected reports whether the analysis found anything to go on.
+func (a *Analysis) Detected() bool {
+	return len(a.Signals) > 0
+}
+
+// dirZones maps well-known top-level directory names to zones.
+var dirZones = map[string]string{
+	"web": ZoneFrontend, "frontend": ZoneFrontend, "client": ZoneFrontend,
+	"ui": ZoneFrontend, "app": ZoneFrontend, "site": ZoneFrontend,
+	"api": ZoneBackend, "server": ZoneBackend, "backend": ZoneBackend,
+	"cmd": ZoneBackend, "internal": ZoneBackend, "pkg": ZoneBackend, "services": ZoneBackend,
+	"db": ZoneDatabase, "database": ZoneDatabase, "migrations": ZoneDatabase,
+	"prisma": ZoneDatabase, "sql": ZoneDatabase, "schema": ZoneDatabase,
+	"infra": ZoneInfra, "deploy": ZoneInfra, "deployments": ZoneInfra,
+	"terraform": ZoneInfra, "k8s": ZoneInfra, "helm": ZoneInfra, "ops": ZoneInfra,
+	"shared": ZoneShared, "common": ZoneShared, "lib": ZoneShared, "packages": ZoneShared,
+}
+
+// npm dependencies that identify a frontend or backend package.
+var (
+	frontendDeps = map[string]string{
+		"react": "React", "vue": "Vue", "svelte": "Svelte", "next": "Next.js",
+		"@angular/core": "Angular", "vite": "Vite", "solid-js": "Solid",
+	}
+	backendDeps = map[string]string{
+		"express": "Express", "fastify": "Fastify", "koa": "Koa",
+		"@nestjs/core": "NestJS", "hono": "Hono",
+	}
+	databaseDeps = map[string]string{
+		"prisma": "Prisma", "@prisma/client": "Prisma", "pg": "PostgreSQL",
+		"mongoose": "MongoDB", "typeorm": "TypeORM", "drizzle-orm": "Drizzle",
+	}
+)
+
+// Compose images that indicate a database service.
+var databaseImages = []string{"postgres", "mysql", "mariadb", "mongo", "redis", "sqlite", "cockroach"}
+
+// analyzer accumulates findings while walking the repository.
+type analyzer struct {
+	a          *Analysis
+	zones      map[string]*Zone
+	languages  map[string]bool
+	frameworks map[string]bool
+}
+
+// Analyze inspects root (package.json, go.mod, docker-compose files and
+// the top-level directory layout, plus manifests one level down for
+// monorepos) and returns a proposal. It only reads files.
+func Analyze(root string) (*Analysis, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	z := &analyzer{
+		a:          &Analysis{Root: root, Name: filepath.Base(root)},
+		zones:      make(map[string]*Zone),
+		languages:  make(map[string]bool),
+		frameworks: make(map[string]bool),
+	}
+
+	z.manifests(root, ".")
+
+	entries, _ := os.ReadDir(root)
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" {
+			continue
+		}
+		if zone, ok := dirZones[strings.ToLower(name)]; ok {
+			z.signal(name+"/", zone+" directory")
+			z.addZone(zone, name+"/", "directory "+name+"/")
+		}
+		z.manifests(filepath.Join(root, name), name)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".github", "workflows")); err == nil {
+		z.signal(".github/workflows/", "CI workflows")
+		z.addZone(ZoneInfra, ".github/", "CI workflows")
+	}
+
+	z.finish()
+	return z.a, nil
+}
+
+// manifests looks for known manifest files in dir (rel is its path
+// relative to the root, "." for the root itself).
+func (z *analyzer) manifests(dir, rel string) {
+	zonePath := rel + "/"
+	if rel == "." {
+		zonePath = "."
+	}
+	at := func(name string) string {
+		if rel == "." {
+			return name
+		}
+		return rel + "/" + name
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		z.packageJSON(data, at("package.json"), zonePath)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		module := ""
+		for _, line := range strings.Split(string(data), "\n") {
+			if m, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+				module = strings.TrimSpace(m)
+				break
+			}
+		}
+		z.language("Go")
+		z.signal(at("go.mod"), "Go module "+module)
+		z.addZone(ZoneBackend, zonePath, "Go module")
+		if rel == "." && module != "" {
+			z.a.Name = filepath.Base(module)
+		}
+	}
+	for _, name := range []string{"pyproject.toml", "requirements.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			z.language("Python")
+			z.signal(at(name), "Python project")
+			z.addZone(ZoneBackend, zonePath, "Python project")
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Cargo.toml")); err == nil {
+		z.language("Rust")
+		z.signal(at("Cargo.toml"), "Rust crate")
+		z.addZone(ZoneBackend, zonePath, "Rust crate")
+	}
+	for _, name := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		if f, err := os.Open(filepath.Join(dir, name)); err == nil {
+			z.compose(f, at(name))
+			f.Close()
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err == nil {
+		z.signal(at("Dockerfile"), "container build")
+		z.addZone(ZoneInfra, at("Dockerfile"), "Dockerfile")
+	}
+}
+
+func (z *analyzer) packageJSON(data []byte, path, zonePath string) {
+	var pkg struct {
+		Name            string            `json:"name"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+		Workspaces      json.RawMessage   `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		z.signal(path, "unreadable package.json")
+		return
+	}
+	z.language("JavaScript/TypeScript")
+	if zonePath == "." && pkg.Name != "" {
+		z.a.Name = pkg.Name
+	}
+
+	deps := make(map[string]bool)
+	for d := range pkg.Dependencies {
+		deps[d] = true
+	}
+	for d := range pkg.DevDependencies {
+		deps[d] = true
+	}
+
+	kinds := []struct {
+		zone string
+		deps map[string]string
+	}{
+		{ZoneFrontend, frontendDeps},
+		{ZoneBackend, backendDeps},
+		{ZoneDatabase, databaseDeps},
+	}
+	var found []string
+	for _, k := range kinds {
+		for dep, framework := range k.deps {
+			if deps[dep] {
+				z.framework(framework)
+				z.addZone(k.zone, zonePath, framework)
+				found = append(found, framework)
+			}
+		}
+	}
+	sort.Strings(found)
+
+	detail := "npm package"
+	if pkg.Name != "" {
+		detail += " " + pkg.Name
+	}
+	if len(found) > 0 {
+		detail += " (" + strings.Join(dedupe(found), ", ") + ")"
+	}
+	if len(pkg.Workspaces) > 0 && string(pkg.Workspaces) != "null" {
+		detail += ", workspaces"
+		z.addZone(ZoneShared, zonePath, "npm workspaces")
+	}
+	z.signal(path, detail)
+}
+
+// compose reads service names and images from a compose file. It is a
+// line scanner, not a YAML parser: services are the keys indented one
+// level under "services:".
+func (z *analyzer) compose(f *os.File, path string) {
+	var services []string
+	inServices := false
+	indent := -1
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " \t"))
+		if depth == 0 {
+			inServices = trimmed == "services:"
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if indent < 0 {
+			indent = depth
+		}
+		if depth == indent && strings.HasSuffix(trimmed, ":") {
+			current = strings.TrimSuffix(trimmed, ":")
+			services = append(services, current)
+			if isDatabase(current) {
+				z.addZone(ZoneDatabase, path, "compose service "+current)
+			}
+			continue
+		}
+		if image, ok := strings.CutPrefix(trimmed, "image:"); ok && isDatabase(image) {
+			z.addZone(ZoneDatabase, path, "compose service "+current)
+		}
+	}
+	z.signal(path, "compose services: "+strings.Join(services, ", "))
+	z.addZone(ZoneInfra, path, "docker compose")
+}
+
+func isDatabase(s string) bool {
+	s = strings.ToLower(strings.Trim(strings.TrimSpace(s), `"'`))
+	for _, db := range databaseImages {
+		if strings.HasPrefix(s, db) || strings.Contains(s, "/"+db) {
+			return true
+		}
+	}
+	return false
+}
+
+func (z *analyzer) signal(path, detail string) {
+	z.a.Signals = append(z.a.Signals, Signal{Path: path, Detail: detail})
+}
+
+func (z *analyzer) language(l string) { z.languages[l] = true }
+
+func (z *analyzer) framework(f string) { z.frameworks[f] = true }
+
+func (z *analyzer) addZone(name, path, reason string) {
+	zone, ok := z.zones[name]
+	if !ok {
+		zone = &Zone{Name: name, Reason: reason}
+		z.zones[name] = zone
+	}
+	for _, p := range zone.Paths {
+		if p == path {
+			return
+		}
+	}
+	zone.Paths = append(zone.Paths, path)
+}
+
+// finish orders the findings and derives the matrix and spec.
+func (z *analyzer) finish() {
+	a := z.a
+	a.Languages = sortedKeys(z.languages)
+	a.Frameworks = sortedKeys(z.frameworks)
+
+	if len(z.zones) == 0 {
+		for _, name := range DefaultZones {
+			a.Zones = append(a.Zones, Zone{Name: name, Paths: []string{}})
+		}
+	} else {
+		// Always offer a shared zone for cross-cutting work.
+		if _, ok := z.zones[ZoneShared]; !ok {
+			z.zones[ZoneShared] = &Zone{Name: ZoneShared, Paths: []string{}}
+		}
+		for _, name := range DefaultZones {
+			if zone, ok := z.zones[name]; ok {
+				sort.Strings(zone.Paths)
+				a.Zones = append(a.Zones, *zone)
+			}
+		}
+	}
+	if a.Signals == nil {
+		a.Signals = []Signal{}
+	}
+	a.Matrix = DefaultMatrix(a.ZoneNames(), z.zones[ZoneInfra] != nil)
+	a.Spec = specSkeleton(a)
+}
+
+// DefaultMatrix enables every stage persona in every zone, except
+// security, qa and devops, which start disabled as for a personal
+// project. devops is enabled when the repository has infrastructure.
+func DefaultMatrix(zones []string, hasInfra bool) []MatrixCell {
+	var cells []MatrixCell
+	for _, stage := range Stages {
+		for _, zone := range zones {
+			for _, persona := range StagePersonas[stage] {
+				enabled := true
+				switch persona {
+				case "security", "qa":
+					enabled = false
+				case "devops":
+					enabled = hasInfra
+				}
+				cells = append(cells, MatrixCell{Stage: stage, Zone: zone, Persona: persona, Enabled: enabled})
+			}
+		}
+	}
+	return cells
+}
+
+// specSkeleton drafts .mission/specs/overview.md from the findings.
+func specSkeleton(a *Analysis) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", a.Name)
+	b.WriteString("## Overview\n\n_What does this project do, and for whom?_\n\n")
+
+	b.WriteString("## Stack\n\n")
+	if len(a.Languages) == 0 && len(a.Frameworks) == 0 {
+		b.WriteString("_Not detected._\n")
+	}
+	for _, l := range a.Languages {
+		fmt.Fprintf(&b, "- %s\n", l)
+	}
+	for _, f := range a.Frameworks {
+		fmt.Fprintf(&b, "- %s\n", f)
+	}
+
+	b.WriteString("\n## Zones\n\n")
+	for _, zone := range a.Zones {
+		if len(zone.Paths) == 0 {
+			fmt.Fprintf(&b, "- **%s**\n", zone.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "- **%s**: %s\n", zone.Name, strings.Join(zone.Paths, ", "))
+	}
+
+	b.WriteString("\n## Goals\n\n- \n\n")
+	b.WriteString("## Requirements\n\n- \n\n")
+	b.WriteString("## Acceptance Criteria\n\n- \n\n")
+	b.WriteString("## Open Questions\n\n- \n")
+	return b.String()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dedupe(s []string) []string {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeMonorepo(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module github.com/acme/widgets\n\ngo 1.22\n")
+	writeFile(t, root, "web/package.json", `{"name":"widgets-web","dependencies":{"react":"^18"},"devDependencies":{"vite":"^5"}}`)
+	writeFile(t, root, "docker-compose.yml", "services:\n  api:\n    build: .\n  db:\n    image: postgres:16\n")
+	writeFile(t, root, "migrations/001.sql", "create table t();")
+	writeFile(t, root, "node_modules/react/package.json", `{"name":"react"}`)
+
+	a, err := Analyze(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "widgets" {
+		t.Errorf("name = %q, want widgets", a.Name)
+	}
+	if want := []string{"Go", "JavaScript/TypeScript"}; !reflect.DeepEqual(a.Languages, want) {
+		t.Errorf("languages = %v, want %v", a.Languages, want)
+	}
+	if want := []string{"React", "Vite"}; !reflect.DeepEqual(a.Frameworks, want) {
+		t.Errorf("frameworks = %v, want %v", a.Frameworks, want)
+	}
+	if want := []string{"frontend", "backend", "database", "infra", "shared"}; !reflect.DeepEqual(a.ZoneNames(), want) {
+		t.Errorf("zones = %v, want %v", a.ZoneNames(), want)
+	}
+	paths := map[string][]string{}
+	for _, z := range a.Zones {
+		paths[z.Name] = z.Paths
+	}
+	if want := []string{"web/"}; !reflect.DeepEqual(paths["frontend"], want) {
+		t.Errorf("frontend paths = %v, want %v", paths["frontend"], want)
+	}
+	if want := []string{"docker-compose.yml", "migrations/"}; !reflect.DeepEqual(paths["database"], want) {
+		t.Errorf("database paths = %v, want %v", paths["database"], want)
+	}
+
+	var devops, security int
+	for _, c := range a.Matrix {
+		if c.Persona == "devops" && c.Enabled {
+			devops++
+		}
+		if c.Persona == "security" && c.Enabled {
+			security++
+		}
+	}
+	if devops != len(a.Zones) || security != 0 {
+		t.Errorf("expected devops enabled in every zone and security disabled, got devops=%d security=%d", devops, security)
+	}
+
+	for _, want := range []string{"# widgets", "- React", "**frontend**: web/", "## Open Questions"} {
+		if !strings.Contains(a.Spec, want) {
+			t.Errorf("spec missing %q:\n%s", want, a.Spec)
+		}
+	}
+}
+
+func TestAnalyzeEmptyRepoFallsBackToDefaults(t *testing.T) {
+	root := t.TempDir()
+	a, err := Analyze(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Detected() {
+		t.Errorf("expected nothing detected, got %v", a.Signals)
+	}
+	if !reflect.DeepEqual(a.ZoneNames(), DefaultZones) {
+		t.Errorf("zones = %v, want defaults", a.ZoneNames())
+	}
+	for _, c := range a.Matrix {
+		if c.Persona == "devops" && c.Enabled {
+			t.Fatal("devops should be disabled without infrastructure")
+		}
+	}
+	if _, err := Analyze(filepath.Join(root, "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestMatrixCheck(t *testing.T) {
+	m := Matrix(DefaultMatrix([]string{ZoneBackend, ZoneFrontend}, false))
+	m = append(m, MatrixCell{Stage: "verify", Zone: ZoneBackend, Persona: "security", Enabled: true})
+
+	if err := m.Check("implement", ZoneBackend, "developer"); err != nil {
+		t.Errorf("developer in implement: %v", err)
+	}
+	err := m.Check("discovery", ZoneBackend, "developer")
+	if err == nil || !strings.Contains(err.Error(), "discovery stage in zone backend (enabled: researcher)") {
+		t.Errorf("developer in discovery: err = %v", err)
+	}
+	// Enabled in one zone only
+	if err := m.Check("verify", ZoneBackend, "security"); err != nil {
+		t.Errorf("security in backend: %v", err)
+	}
+	if err := m.Check("verify", ZoneFrontend, "security"); err == nil {
+		t.Error("security allowed in frontend, where it is disabled")
+	}
+	// A zone the matrix doesn't know, or none, goes by the stage
+	if err := m.Check("verify", "mobile", "security"); err != nil {
+		t.Errorf("security in an unknown zone: %v", err)
+	}
+	if err := m.Check("verify", "", "qa"); err == nil {
+		t.Error("qa allowed in verify")
+	}
+	if err := Matrix(nil).Check("discovery", "", "developer"); err != nil {
+		t.Errorf("an empty matrix refused: %v", err)
+	}
+}
@@ -4,21 +4,23 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"os"
+	"strings"
 	"sync"
 
 	"github.com/gorilla/websocket"
-)
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
-}
+	"github.com/MikeSquared-Agency/MissionControl/identity"
+	"github.com/MikeSquared-Agency/MissionControl/origins"
+	"github.com/MikeSquared-Agency/MissionControl/problem"
+)
 
 // Event is the core message type broadcast through the hub.
 type Event struct {
-	Topic string          `json:"topic"`
-	Type  string          `json:"type"`
-	Data  json.RawMessage `json:"data"`
+	Topic     string          `json:"topic"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	RequestID string          `json:"request_id,omitempty"` // API request that triggered the event, if any
+	Seq       uint64          `json:"seq,omitempty"`        // event log position, replayable from /api/notifications
 }
 
 // clientCommand represents a command sent from the client.
@@ -45,6 +47,7 @@ type Hub struct {
 	mu         sync.RWMutex
 
 	stateProvider func() interface{}
+	upgrader      websocket.Upgrader
 }
 
 // NewHub creates a new Hub.
@@ -54,6 +57,7 @@ func NewHub() *Hub {
 		broadcast:  make(chan Event, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		upgrader:   websocket.Upgrader{CheckOrigin: origins.Default().AllowRequest},
 	}
 }
 
@@ -114,6 +118,17 @@ func (h *Hub) BroadcastRaw(topic, eventType string, data interface{}) {
 	h.Broadcast(Event{Topic: topic, Type: eventType, Data: raw})
 }
 
+// BroadcastRawWithRequestID is BroadcastRaw tagged with the API request ID
+// that caused the event, so clients can correlate it with their request.
+func (h *Hub) BroadcastRawWithRequestID(requestID, topic, eventType string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[ws] BroadcastRaw marshal error: %v", err)
+		return
+	}
+	h.Broadcast(Event{Topic: topic, Type: eventType, Data: raw, RequestID: requestID})
+}
+
 // SetStateProvider sets the function used for initial state sync.
 func (h *Hub) SetStateProvider(fn func() interface{}) {
 	h.mu.Lock()
@@ -121,6 +136,14 @@ func (h *Hub) SetStateProvider(fn func() interface{}) {
 	h.mu.Unlock()
 }
 
+// SetOriginPolicy restricts which browser origins may open WebSocket
+// connections. The default allows localhost and the hosted dashboard.
+func (h *Hub) SetOriginPolicy(p *origins.Policy) {
+	h.mu.Lock()
+	h.upgrader.CheckOrigin = p.AllowRequest
+	h.mu.Unlock()
+}
+
 // ClientCount returns the number of connected clients.
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
@@ -131,10 +154,14 @@ func (h *Hub) ClientCount() int {
 // HandleWebSocket upgrades the HTTP connection and registers the client.
 func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	if !h.checkAuth(r) {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Error(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
+	h.mu.RLock()
+	upgrader := h.upgrader
+	h.mu.RUnlock()
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("[ws] upgrade error: %v", err)
@@ -152,21 +179,21 @@ func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
-// checkAuth validates the request against MC_API_TOKEN.
+// checkAuth validates the request against MC_API_TOKEN and MC_API_TOKENS.
 func (h *Hub) checkAuth(r *http.Request) bool {
-	token := os.Getenv("MC_API_TOKEN")
-	if token == "" {
+	tokens := identity.Tokens()
+	if len(tokens) == 0 {
 		return true // auth disabled in dev mode
 	}
 	// Check Authorization header (Bearer token)
-	if auth := r.Header.Get("Authorization"); auth == "Bearer "+token {
-		return true
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if _, ok := identity.Lookup(tokens, strings.TrimPrefix(auth, "Bearer ")); ok {
+			return true
+		}
 	}
 	// Check query param
-	if r.URL.Query().Get("token") == token {
-		return true
-	}
-	return false
+	_, ok := identity.Lookup(tokens, r.URL.Query().Get("token"))
+	return ok
 }
 
 // sendInitialState sends state sync to a newly connected client.
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/MikeSquared-Agency/MissionControl/origins"
 )
 
 func setupHub(t *testing.T) (*Hub, *httptest.Server) {
@@ -257,3 +259,51 @@ func TestRequestSync(t *testing.T) {
 		t.Fatalf("expected sync, got %s/%s", ev.Topic, ev.Type)
 	}
 }
+
+func TestBroadcastRawWithRequestID(t *testing.T) {
+	hub := NewHub()
+	hub.BroadcastRawWithRequestID("req-1", "gates", "gate_approved", map[string]string{"stage": "verify"})
+
+	select {
+	case ev := <-hub.broadcast:
+		if ev.RequestID != "req-1" || ev.Topic != "gates" || ev.Type != "gate_approved" {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+		data, _ := json.Marshal(ev)
+		if !strings.Contains(string(data), `"request_id":"req-1"`) {
+			t.Errorf("expected request_id in wire format, got %s", data)
+		}
+	default:
+		t.Fatal("expected event on broadcast channel")
+	}
+}
+
+func TestOriginPolicy(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	hub.SetOriginPolicy(origins.New([]string{"https://mc.example.com"}))
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleWebSocket))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.com"}})
+	if err == nil {
+		t.Fatal("expected foreign origin to be rejected")
+	}
+	if resp != nil && resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", resp.StatusCode)
+	}
+
+	for _, origin := range []string{"https://mc.example.com", server.URL, ""} {
+		h := http.Header{}
+		if origin != "" {
+			h.Set("Origin", origin)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(url, h)
+		if err != nil {
+			t.Errorf("origin %q: %v", origin, err)
+			continue
+		}
+		conn.Close()
+	}
+}
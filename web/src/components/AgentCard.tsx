@@ -82,6 +82,16 @@ export function AgentCard({ agent, selected, onSelect, onMove, onShare }: AgentC
           </span>
         )}
 
+        {/* Restart count, red while crash-looping */}
+        {agent.restarts ? (
+          <span
+            className={`px-1.5 py-0.5 text-[10px] font-mono rounded ${agent.crashLoop ? 'text-red-400 bg-red-500/20' : 'text-gray-400 bg-gray-800'}`}
+            title={`Restarted ${agent.restarts} time${agent.restarts === 1 ? '' : 's'}`}
+          >
+            ↻{agent.restarts}
+          </span>
+        ) : null}
+
         {/* Type badge */}
         <span className="px-1.5 py-0.5 text-[10px] font-mono text-gray-500 bg-gray-800 rounded">
           {agent.type === 'claude-code' ? 'CC' : 'PY'}
@@ -172,6 +182,7 @@ export function AgentCard({ agent, selected, onSelect, onMove, onShare }: AgentC
             <span className="text-amber-500">
               {agent.attention?.type === 'question' && '❓'}
               {agent.attention?.type === 'permission' && '🔐'}
+              {agent.attention?.type === 'plan_approval' && '📋'}
               {agent.attention?.type === 'error' && '❌'}
               {agent.attention?.type === 'complete' && '✅'}
             </span>
@@ -11,6 +11,7 @@ export function AttentionAlert({ attention, onRespond }: AttentionAlertProps) {
   const icons: Record<string, string> = {
     question: '❓',
     permission: '🔐',
+    plan_approval: '📋',
     error: '❌',
     complete: '✅'
   }
@@ -18,6 +19,7 @@ export function AttentionAlert({ attention, onRespond }: AttentionAlertProps) {
   const titles: Record<string, string> = {
     question: 'Agent has a question',
     permission: 'Permission required',
+    plan_approval: 'Plan awaiting approval',
     error: 'Error occurred',
     complete: 'Task complete'
   }
@@ -25,6 +27,7 @@ export function AttentionAlert({ attention, onRespond }: AttentionAlertProps) {
   const bgColors: Record<string, string> = {
     question: 'bg-amber-500/10 border-amber-500/20',
     permission: 'bg-amber-500/10 border-amber-500/20',
+    plan_approval: 'bg-blue-500/10 border-blue-500/20',
     error: 'bg-red-500/10 border-red-500/20',
     complete: 'bg-green-500/10 border-green-500/20'
   }
@@ -32,6 +35,7 @@ export function AttentionAlert({ attention, onRespond }: AttentionAlertProps) {
   const textColors: Record<string, string> = {
     question: 'text-amber-400',
     permission: 'text-amber-400',
+    plan_approval: 'text-blue-400',
     error: 'text-red-400',
     complete: 'text-green-400'
   }
@@ -59,7 +63,7 @@ export function AttentionAlert({ attention, onRespond }: AttentionAlertProps) {
           )}
 
           {/* Quick actions */}
-          {(type === 'question' || type === 'permission') && onRespond && (
+          {(type === 'question' || type === 'permission' || type === 'plan_approval') && onRespond && (
             <div className="mt-2 flex items-center gap-2">
               {type === 'permission' ? (
                 <>
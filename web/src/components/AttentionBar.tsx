@@ -14,6 +14,7 @@ export function AttentionBar({ onRespond }: AttentionBarProps) {
   // Group by attention type
   const questions = agentsNeedingAttention.filter((a) => a.attention?.type === 'question')
   const permissions = agentsNeedingAttention.filter((a) => a.attention?.type === 'permission')
+  const plans = agentsNeedingAttention.filter((a) => a.attention?.type === 'plan_approval')
   const errors = agentsNeedingAttention.filter((a) => a.attention?.type === 'error')
   const complete = agentsNeedingAttention.filter((a) => a.attention?.type === 'complete')
 
@@ -61,6 +62,18 @@ export function AttentionBar({ onRespond }: AttentionBarProps) {
             />
           ))}
 
+          {/* Plans awaiting approval */}
+          {plans.map((agent) => (
+            <AttentionPill
+              key={agent.id}
+              agent={agent}
+              icon="📋"
+              color="blue"
+              onClick={() => handleAgentClick(agent)}
+              onRespond={onRespond}
+            />
+          ))}
+
           {/* Errors */}
           {errors.map((agent) => (
             <AttentionPill
@@ -93,7 +106,7 @@ export function AttentionBar({ onRespond }: AttentionBarProps) {
 interface AttentionPillProps {
   agent: Agent
   icon: string
-  color: 'amber' | 'red' | 'green'
+  color: 'amber' | 'red' | 'green' | 'blue'
   onClick: () => void
   onRespond: (agentId: string, response: string) => void
 }
@@ -102,7 +115,8 @@ function AttentionPill({ agent, icon, color, onClick, onRespond }: AttentionPill
   const colorStyles = {
     amber: 'bg-amber-500/20 border-amber-500/30 text-amber-300 hover:bg-amber-500/30',
     red: 'bg-red-500/20 border-red-500/30 text-red-300 hover:bg-red-500/30',
-    green: 'bg-green-500/20 border-green-500/30 text-green-300 hover:bg-green-500/30'
+    green: 'bg-green-500/20 border-green-500/30 text-green-300 hover:bg-green-500/30',
+    blue: 'bg-blue-500/20 border-blue-500/30 text-blue-300 hover:bg-blue-500/30'
   }
 
   const attention = agent.attention
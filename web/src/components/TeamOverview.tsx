@@ -107,6 +107,7 @@ export function TeamOverview({ onAgentClick }: TeamOverviewProps) {
                       <span className="text-amber-500 text-[10px]">
                         {agent.attention.type === 'question' && '❓'}
                         {agent.attention.type === 'permission' && '🔐'}
+                        {agent.attention.type === 'plan_approval' && '📋'}
                         {agent.attention.type === 'error' && '❌'}
                       </span>
                     )}
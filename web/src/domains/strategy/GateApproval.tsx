@@ -4,9 +4,11 @@ import {
   useCurrentStage,
   useStages,
   fetchGate,
-  approveGate
+  approveGate,
+  fetchApprovalRequests,
+  resolveApprovalRequest
 } from '../../stores/useWorkflowStore'
-import type { Stage, Gate, GateCriterion } from '../../types/workflow'
+import type { Stage, Gate, GateCriterion, KingApprovalRequest } from '../../types/workflow'
 import {
   ALL_STAGES,
   getStageLabel,
@@ -20,6 +22,7 @@ export function GateApproval() {
   const gates = useWorkflowStore((s) => s.gates)
   const setGate = useWorkflowStore((s) => s.setGate)
   const [loading, setLoading] = useState(true)
+  const [requests, setRequests] = useState<KingApprovalRequest[]>([])
 
   // Load all gates on mount
   useEffect(() => {
@@ -41,8 +44,21 @@ export function GateApproval() {
       }
     }
     loadGates()
+    fetchApprovalRequests().then(setRequests).catch(() => setRequests([]))
   }, [setGate])
 
+  const handleRequest = async (id: string, action: 'confirm' | 'dismiss') => {
+    try {
+      const resolved = await resolveApprovalRequest(id, action)
+      setRequests((rs) => rs.filter((r) => r.id !== id))
+      if (action === 'confirm') {
+        setGate(resolved.stage, await fetchGate(resolved.stage))
+      }
+    } catch (err) {
+      console.error(`Failed to ${action} approval request:`, err)
+    }
+  }
+
   if (loading) {
     return (
       <div className="flex items-center justify-center h-full text-gray-500 text-sm">
@@ -76,6 +92,30 @@ export function GateApproval() {
             </span>
           </div>
         )}
+
+        {/* Approvals the King asked for */}
+        {requests.map((req) => (
+          <div key={req.id} className="mt-2 px-2 py-1.5 rounded bg-blue-500/10 border border-blue-500/20">
+            <div className="text-xs text-blue-300">
+              King asks to approve the {getStageLabel(req.stage)} gate
+            </div>
+            {req.note && <div className="text-[10px] text-gray-400 mt-0.5">{req.note}</div>}
+            <div className="flex gap-2 mt-1.5">
+              <button
+                onClick={() => handleRequest(req.id, 'confirm')}
+                className="px-2 py-0.5 text-[10px] font-medium bg-green-500/20 text-green-400 rounded hover:bg-green-500/30"
+              >
+                Confirm
+              </button>
+              <button
+                onClick={() => handleRequest(req.id, 'dismiss')}
+                className="px-2 py-0.5 text-[10px] font-medium bg-gray-700 text-gray-400 rounded hover:bg-gray-600"
+              >
+                Dismiss
+              </button>
+            </div>
+          </div>
+        ))}
       </div>
 
       {/* Gates list */}
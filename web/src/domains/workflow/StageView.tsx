@@ -9,9 +9,10 @@ import {
   fetchTasks,
   fetchGate,
   approveGate,
-  updateTaskStatus
+  updateTaskStatus,
+  fetchMockups
 } from '../../stores/useWorkflowStore'
-import type { Stage, Task, Gate, TaskStatus } from '../../types/workflow'
+import type { Stage, Task, Gate, TaskStatus, Mockup } from '../../types/workflow'
 import {
   ALL_STAGES,
   getStageLabel,
@@ -185,6 +186,49 @@ function StageDetail({ stage }: StageDetailProps) {
           </div>
         )}
       </div>
+
+      {/* Designer output */}
+      {stage === 'design' && <MockupGallery tasks={tasks} />}
+    </div>
+  )
+}
+
+function MockupGallery({ tasks }: { tasks: Task[] }) {
+  const [mockups, setMockups] = useState<Mockup[]>([])
+
+  useEffect(() => {
+    fetchMockups().then(setMockups).catch(() => setMockups([]))
+  }, [])
+
+  if (mockups.length === 0) {
+    return null
+  }
+
+  const taskName = (id?: string) => tasks.find((t) => t.id === id)?.name ?? id
+
+  return (
+    <div className="space-y-2">
+      <h3 className="text-xs font-medium text-gray-500 uppercase tracking-wider">
+        Mockups
+      </h3>
+      <div className="grid grid-cols-3 gap-2">
+        {mockups.map((m) => (
+          <a
+            key={m.name}
+            href={m.url}
+            target="_blank"
+            rel="noreferrer"
+            className="block rounded border border-gray-800 hover:border-gray-700 bg-gray-800/30 overflow-hidden"
+          >
+            <img src={m.url} alt={m.name} className="w-full h-24 object-cover bg-gray-900" />
+            <div className="px-2 py-1 text-[10px] text-gray-400 truncate">
+              {m.name}
+              {m.task && <span className="text-gray-600"> · {taskName(m.task)}</span>}
+              {m.spec && <span className="text-gray-600"> · {m.spec}</span>}
+            </div>
+          </a>
+        ))}
+      </div>
     </div>
   )
 }
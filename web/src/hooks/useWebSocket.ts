@@ -92,7 +92,46 @@ export function useWebSocket() {
         // Handle both formats: {agent: {...}} and {data: {agent: {...}}} (KingEvent format)
         const spawnedAgentData = data.agent || (data.data as Record<string, unknown> | undefined)?.agent
         if (spawnedAgentData) {
-          addAgent(normalizeAgent(spawnedAgentData as Record<string, unknown>))
+          const spawned = normalizeAgent(spawnedAgentData as Record<string, unknown>)
+          // A restarted agent is spawned again under the same ID
+          if (useStore.getState().agents.some((a) => a.id === spawned.id)) {
+            updateAgent(spawned.id, { status: spawned.status, pid: spawned.pid, error: undefined })
+          } else {
+            addAgent(spawned)
+          }
+        }
+        break
+
+      // Agent exited and its restart policy restarts it after a backoff
+      case 'agent_restarted':
+        if (data.agent_id) {
+          const restartData = typeof data.data === 'object' ? data.data as Record<string, unknown> : {}
+          updateAgent(data.agent_id, {
+            status: 'starting',
+            error: restartData.error as string | undefined,
+            restarts: restartData.restarts as number | undefined,
+            crashLoop: Boolean(restartData.crash_loop)
+          })
+          if (restartData.crash_loop) {
+            setAgentAttention(data.agent_id, {
+              type: 'error',
+              message: `Crash loop: restarted ${restartData.restarts} times, next in ${restartData.delay}`,
+              since: Date.now()
+            })
+          }
+        }
+        break
+
+      // Agent waiting on a question, a tool permission or a plan approval
+      case 'agent_question':
+      case 'agent_permission_request':
+      case 'agent_plan_approval':
+        if (data.agent_id) {
+          const attentionData = typeof data.data === 'object' ? data.data as Record<string, unknown> : {}
+          const attention = attentionData.attention as Agent['attention'] | undefined
+          if (attention) {
+            setAgentAttention(data.agent_id, attention)
+          }
         }
         break
 
@@ -546,7 +585,9 @@ function normalizeAgent(backendAgent: Record<string, unknown>): Agent {
     conversation: [],
     created_at: (backendAgent.created_at as string) || new Date().toISOString(),
     error: backendAgent.error as string | undefined,
-    pid: backendAgent.pid as number | undefined
+    pid: backendAgent.pid as number | undefined,
+    restarts: backendAgent.restarts as number | undefined,
+    crashLoop: backendAgent.crash_loop as boolean | undefined
   }
 }
 
@@ -559,6 +600,8 @@ function normalizeStatus(status: string | undefined): Agent['status'] {
   if (!status) return 'idle'
   const statusMap: Record<string, Agent['status']> = {
     'starting': 'starting',
+    'restarting': 'starting',
+    'timed_out': 'error',
     'running': 'working',
     'working': 'working',
     'idle': 'idle',
@@ -1,5 +1,5 @@
 import { create } from 'zustand'
-import type { KingQuestion } from '../types'
+import type { KingAttachment, KingQuestion } from '../types'
 
 export interface MissionWorker {
   id: string
@@ -278,11 +278,11 @@ export async function stopKing(): Promise<void> {
   }
 }
 
-export async function sendKingMessage(message: string): Promise<void> {
+export async function sendKingMessage(message: string, attachments?: KingAttachment[]): Promise<void> {
   const res = await fetch(`${API_BASE}/king/message`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify({ content: message })
+    body: JSON.stringify({ content: message, attachments })
   })
   if (!res.ok) {
     throw new Error(await res.text())
@@ -5,6 +5,7 @@ import type {
   Zone,
   Persona,
   KingMessage,
+  KingAttachment,
   ConversationMessage,
   ConnectionStatus,
   Settings,
@@ -358,11 +359,11 @@ export async function deleteZone(id: string): Promise<void> {
   }
 }
 
-export async function sendKingMessage(content: string): Promise<void> {
+export async function sendKingMessage(content: string, attachments?: KingAttachment[]): Promise<void> {
   const res = await fetch(`${API_BASE}/king/message`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify({ content })
+    body: JSON.stringify({ content, attachments })
   })
 
   if (!res.ok) {
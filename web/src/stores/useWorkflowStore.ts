@@ -9,6 +9,8 @@ import type {
   StagesResponse,
   TasksResponse,
   GateApprovalResponse,
+  KingApprovalRequest,
+  Mockup,
   WorkflowEvent
 } from '../types/workflow'
 
@@ -221,6 +223,41 @@ export async function approveGate(
   return res.json()
 }
 
+export async function fetchApprovalRequests(): Promise<KingApprovalRequest[]> {
+  const res = await fetch(`${API_BASE}/approvals?status=pending`)
+  if (!res.ok) {
+    throw new Error(await res.text())
+  }
+  const data = await res.json()
+  return data.approvals || []
+}
+
+// Confirming approves the gate in the caller's name, as approveGate does
+export async function resolveApprovalRequest(
+  id: string,
+  action: 'confirm' | 'dismiss'
+): Promise<KingApprovalRequest> {
+  const res = await fetch(`${API_BASE}/approvals/${id}/${action}`, { method: 'POST' })
+  if (!res.ok) {
+    throw new Error(await res.text())
+  }
+  const data = await res.json()
+  return data.approval
+}
+
+export async function fetchMockups(filter: { task?: string; spec?: string } = {}): Promise<Mockup[]> {
+  const params = new URLSearchParams()
+  if (filter.task) params.set('task', filter.task)
+  if (filter.spec) params.set('spec', filter.spec)
+  const query = params.toString()
+  const res = await fetch(`${API_BASE}/mockups${query ? `?${query}` : ''}`)
+  if (!res.ok) {
+    throw new Error(await res.text())
+  }
+  const data = await res.json()
+  return data.mockups || []
+}
+
 export async function fetchCheckpoints(): Promise<CheckpointSummary[]> {
   const res = await fetch(`${API_BASE}/checkpoints`)
   if (!res.ok) {
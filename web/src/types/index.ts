@@ -27,13 +27,16 @@ export interface Agent {
   pid?: number
   offlineMode?: boolean
   model?: string
+  restarts?: number // times the restart policy restarted it
+  crashLoop?: boolean // restarted repeatedly without a stable run
 }
 
 // Attention types
 export interface AttentionRequest {
-  type: 'question' | 'permission' | 'error' | 'complete'
+  type: 'question' | 'permission' | 'plan_approval' | 'error' | 'complete'
   message: string
   since: number
+  tool?: string // permission: the tool asked for
   retryable?: boolean
   retryIn?: number
 }
@@ -96,6 +99,12 @@ export interface KingAction {
   content?: string
 }
 
+// A project file, or with diff its uncommitted changes, shared with the King
+export interface KingAttachment {
+  path: string
+  diff?: boolean
+}
+
 export interface KingQuestion {
   question: string
   options: string[]
@@ -216,6 +216,29 @@ export interface GateApprovalResponse {
   can_proceed: boolean
 }
 
+// GET /api/approvals: gate approvals the King asked for, confirmed by a human
+export interface KingApprovalRequest {
+  id: string
+  stage: Stage
+  note: string
+  status: 'pending' | 'confirmed' | 'dismissed'
+  requested_at: string
+  resolved_by?: string
+  resolved_at?: string
+}
+
+// GET /api/mockups: images in .mission/mockups/, linked to a task or spec
+export interface Mockup {
+  name: string
+  content_type: string
+  bytes: number
+  task?: string
+  spec?: string
+  uploaded_by?: string
+  uploaded_at: string
+  url: string
+}
+
 // ============================================================================
 // WebSocket Event Types
 // ============================================================================